@@ -17,11 +17,15 @@ var jonSnowToken, _ = jwt.Encode(&models.OAuthClaims{
 })
 
 var services = &app.Services{
-	Tenants:       &inmemory.TenantStorage{},
-	Users:         &inmemory.UserStorage{},
-	Ideas:         inmemory.NewIdeaStorage(),
-	Tags:          inmemory.NewTagStorage(),
-	Notifications: inmemory.NewNotificationStorage(),
+	Tenants:        &inmemory.TenantStorage{},
+	Users:          &inmemory.UserStorage{},
+	Ideas:          inmemory.NewIdeaStorage(),
+	Tags:           inmemory.NewTagStorage(),
+	Notifications:  inmemory.NewNotificationStorage(),
+	EmailTemplates: inmemory.NewEmailTemplateStorage(),
+	Pages:          inmemory.NewPageStorage(),
+	EmailLog:       inmemory.NewEmailLogStorage(),
+	Sessions:       inmemory.NewSessionStorage(),
 }
 
 func ExpectFailed(result *validate.Result, fields ...string) {
@@ -0,0 +1,215 @@
+package actions
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/safehttp"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+// SaveEmailTemplate is used to customize an email template
+type SaveEmailTemplate struct {
+	Model   *models.SaveEmailTemplate
+	Preview *email.Message
+}
+
+// Initialize the model
+func (input *SaveEmailTemplate) Initialize() interface{} {
+	input.Model = new(models.SaveEmailTemplate)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *SaveEmailTemplate) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *SaveEmailTemplate) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if !email.IsCustomizable(input.Model.Name) {
+		result.AddFieldFailuref("name", "'{name}' is not a valid email template.", i18n.Params{"name": input.Model.Name})
+	}
+
+	if localeResult := validate.Locale(input.Model.Locale); !localeResult.Ok {
+		result.AddFieldMessages("locale", localeResult.Messages...)
+	}
+
+	if strings.TrimSpace(input.Model.Subject) == "" {
+		result.AddFieldFailure("subject", "Subject is required.")
+	} else if len(input.Model.Subject) > 200 {
+		result.AddFieldFailure("subject", "Subject must have less than 200 characters.")
+	} else if strings.ContainsAny(input.Model.Subject, "\r\n") {
+		result.AddFieldFailure("subject", "Subject must be a single line.")
+	}
+
+	if strings.TrimSpace(input.Model.Body) == "" {
+		result.AddFieldFailure("body", "Body is required.")
+	}
+
+	if result.Ok {
+		preview, err := email.RenderSample(input.Model.Name, input.Model.Subject, input.Model.Body)
+		if err != nil {
+			result.AddFieldFailuref("body", "Template is invalid: {error}", i18n.Params{"error": errors.Cause(err).Error()})
+		}
+		input.Preview = preview
+	}
+
+	return result
+}
+
+// DeleteEmailTemplate is used to restore the built-in version of an email template
+type DeleteEmailTemplate struct {
+	Model *models.DeleteEmailTemplate
+}
+
+// Initialize the model
+func (input *DeleteEmailTemplate) Initialize() interface{} {
+	input.Model = new(models.DeleteEmailTemplate)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *DeleteEmailTemplate) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *DeleteEmailTemplate) Validate(user *models.User, services *app.Services) *validate.Result {
+	_, err := services.EmailTemplates.Get(input.Model.Name, input.Model.Locale)
+	if err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			result := validate.Success()
+			result.AddFieldFailure("name", "Email template has not been customized.")
+			return result
+		}
+		return validate.Error(err)
+	}
+	return validate.Success()
+}
+
+// RemoveEmailSuppression is used to allow emails to be sent to a suppressed address again
+type RemoveEmailSuppression struct {
+	Model *models.RemoveEmailSuppression
+}
+
+// Initialize the model
+func (input *RemoveEmailSuppression) Initialize() interface{} {
+	input.Model = new(models.RemoveEmailSuppression)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *RemoveEmailSuppression) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *RemoveEmailSuppression) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if input.Model.Address == "" {
+		result.AddFieldFailure("address", "Address is required.")
+		return result
+	}
+
+	suppressed, err := services.EmailLog.IsSuppressed(input.Model.Address)
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	if !suppressed {
+		result.AddFieldFailure("address", "Address is not on the suppression list.")
+	}
+
+	return result
+}
+
+var dkimSelectorRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// UpdateTenantEmailSettings is used to change the sender identity and delivery settings of current tenant
+type UpdateTenantEmailSettings struct {
+	Model *models.UpdateTenantEmailSettings
+}
+
+// Initialize the model
+func (input *UpdateTenantEmailSettings) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantEmailSettings)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantEmailSettings) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantEmailSettings) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	for field, address := range map[string]string{
+		"fromAddress": input.Model.FromAddress,
+		"replyTo":     input.Model.ReplyTo,
+	} {
+		if address == "" {
+			continue
+		}
+		if len(address) > 200 {
+			result.AddFieldFailure(field, "Email must be less than 200 characters.")
+		} else if emailResult := validate.Email(address); !emailResult.Ok {
+			result.AddFieldMessages(field, emailResult.Messages...)
+		}
+	}
+
+	if input.Model.SMTPHost != "" {
+		if strings.ContainsAny(input.Model.SMTPHost, " :/") {
+			result.AddFieldFailure("smtpHost", "Host must be a hostname or IP address, without port.")
+		} else if !env.AllowPrivateSMTPHosts() && !isPublicHost(input.Model.SMTPHost) {
+			result.AddFieldFailure("smtpHost", "Host must be a public address.")
+		}
+		if port, err := strconv.Atoi(input.Model.SMTPPort); err != nil || port <= 0 || port > 65535 {
+			result.AddFieldFailure("smtpPort", "Port must be a number between 1 and 65535.")
+		}
+		if input.Model.FromAddress == "" {
+			result.AddFieldFailure("fromAddress", "From address is required when using your own SMTP server.")
+		}
+	}
+
+	if input.Model.DKIMSelector != "" && !dkimSelectorRegex.MatchString(input.Model.DKIMSelector) {
+		result.AddFieldFailure("dkimSelector", "Selector can only have letters, numbers, dots, dashes and underscores.")
+	}
+
+	if input.Model.DKIMPrivateKey != "" {
+		if input.Model.DKIMSelector == "" {
+			result.AddFieldFailure("dkimSelector", "Selector is required when using a DKIM key.")
+		}
+		if _, err := email.ParseDKIMKey(input.Model.DKIMPrivateKey); err != nil {
+			result.AddFieldFailure("dkimPrivateKey", "Private key must be a PEM encoded RSA key.")
+		}
+	}
+
+	return result
+}
+
+//isPublicHost returns false for hosts that obviously point to the server or its private networks.
+//Hostnames are only resolved when sending, so that the check can't be bypassed by changing DNS records.
+func isPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return safehttp.IsPublicIP(ip)
+	}
+	return true
+}
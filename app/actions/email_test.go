@@ -0,0 +1,115 @@
+package actions_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestSaveEmailTemplate_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	for _, testCase := range []struct {
+		model    *models.SaveEmailTemplate
+		failures []string
+	}{
+		{&models.SaveEmailTemplate{}, []string{"name", "locale", "subject", "body"}},
+		{&models.SaveEmailTemplate{Name: "echo_test", Locale: "en", Subject: "Hi", Body: "Hello"}, []string{"name"}},
+		{&models.SaveEmailTemplate{Name: "new_idea", Locale: "english", Subject: "Hi", Body: "Hello"}, []string{"locale"}},
+		{&models.SaveEmailTemplate{Name: "new_idea", Locale: "en", Subject: "Hi\nthere", Body: "Hello"}, []string{"subject"}},
+		{&models.SaveEmailTemplate{Name: "new_idea", Locale: "en", Subject: "{{ .title }}", Body: "{{ .content "}, []string{"body"}},
+		{&models.SaveEmailTemplate{Name: "new_idea", Locale: "en", Subject: "{{ .title }}", Body: "{{ upper .content }}"}, []string{"body"}},
+	} {
+		action := &actions.SaveEmailTemplate{Model: testCase.model}
+		result := action.Validate(nil, services)
+		ExpectFailed(result, testCase.failures...)
+	}
+}
+
+func TestSaveEmailTemplate_Valid(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.SaveEmailTemplate{Model: &models.SaveEmailTemplate{
+		Name:    "new_idea",
+		Locale:  "pt-BR",
+		Subject: "{{ .title }}",
+		Body:    "<p>Nova ideia</p>{{ .content }}",
+	}}
+	result := action.Validate(nil, services)
+	ExpectSuccess(result)
+	Expect(action.Preview.Subject).Equals("[Demonstration] Add support for dark mode")
+	Expect(action.Preview.Body).Equals("<p>Nova ideia</p><p>It would be great to have a dark theme.</p>")
+}
+
+func TestSaveEmailTemplate_IsAuthorized(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.SaveEmailTemplate{}
+	Expect(action.IsAuthorized(&models.User{Role: models.RoleAdministrator}, nil)).IsTrue()
+	Expect(action.IsAuthorized(&models.User{Role: models.RoleCollaborator}, nil)).IsFalse()
+	Expect(action.IsAuthorized(nil, nil)).IsFalse()
+}
+
+func TestDeleteEmailTemplate(t *testing.T) {
+	RegisterT(t)
+
+	services.EmailTemplates.SetCurrentTenant(&models.Tenant{ID: 1})
+
+	action := &actions.DeleteEmailTemplate{Model: &models.DeleteEmailTemplate{Name: "signup_email", Locale: "en"}}
+	ExpectFailed(action.Validate(nil, services), "name")
+
+	services.EmailTemplates.Save(&models.SaveEmailTemplate{Name: "signup_email", Locale: "en", Subject: "Hi", Body: "Hello"})
+	ExpectSuccess(action.Validate(nil, services))
+}
+
+func TestRemoveEmailSuppression(t *testing.T) {
+	RegisterT(t)
+
+	services.EmailLog.SetCurrentTenant(&models.Tenant{ID: 1})
+	services.EmailLog.Suppress("jon.snow@got.com", "bounce")
+
+	action := &actions.RemoveEmailSuppression{Model: &models.RemoveEmailSuppression{}}
+	ExpectFailed(action.Validate(nil, services), "address")
+
+	action.Model.Address = "arya.stark@got.com"
+	ExpectFailed(action.Validate(nil, services), "address")
+
+	action.Model.Address = "Jon.Snow@got.com"
+	ExpectSuccess(action.Validate(nil, services))
+}
+
+func TestUpdateTenantEmailSettings_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	for _, testCase := range []struct {
+		model    *models.UpdateTenantEmailSettings
+		failures []string
+	}{
+		{&models.UpdateTenantEmailSettings{FromAddress: "feedback", ReplyTo: "support@"}, []string{"fromAddress", "replyTo"}},
+		{&models.UpdateTenantEmailSettings{SMTPHost: "smtp.demo.com:25", SMTPPort: "abc"}, []string{"smtpHost", "smtpPort", "fromAddress"}},
+		{&models.UpdateTenantEmailSettings{FromAddress: "feedback@demo.com", SMTPHost: "smtp.demo.com", SMTPPort: "70000"}, []string{"smtpPort"}},
+		{&models.UpdateTenantEmailSettings{FromAddress: "feedback@demo.com", SMTPHost: "localhost", SMTPPort: "25"}, []string{"smtpHost"}},
+		{&models.UpdateTenantEmailSettings{FromAddress: "feedback@demo.com", SMTPHost: "10.0.0.5", SMTPPort: "25"}, []string{"smtpHost"}},
+		{&models.UpdateTenantEmailSettings{FromAddress: "feedback@demo.com", SMTPHost: "169.254.169.254", SMTPPort: "25"}, []string{"smtpHost"}},
+		{&models.UpdateTenantEmailSettings{DKIMSelector: "fider selector"}, []string{"dkimSelector"}},
+		{&models.UpdateTenantEmailSettings{DKIMPrivateKey: "not a key"}, []string{"dkimSelector", "dkimPrivateKey"}},
+	} {
+		action := &actions.UpdateTenantEmailSettings{Model: testCase.model}
+		ExpectFailed(action.Validate(nil, services), testCase.failures...)
+	}
+}
+
+func TestUpdateTenantEmailSettings_Valid(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.UpdateTenantEmailSettings{Model: &models.UpdateTenantEmailSettings{
+		FromAddress:  "feedback@demo.com",
+		ReplyTo:      "support@demo.com",
+		SMTPHost:     "smtp.demo.com",
+		SMTPPort:     "587",
+		DKIMSelector: "fider",
+	}}
+	ExpectSuccess(action.Validate(nil, services))
+}
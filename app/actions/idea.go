@@ -8,6 +8,7 @@ import (
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
@@ -47,12 +48,30 @@ func (input *CreateNewIdea) Validate(user *models.User, services *app.Services)
 	if err != nil && errors.Cause(err) != app.ErrNotFound {
 		return validate.Error(err)
 	} else if idea != nil {
-		result.AddFieldFailure("title", "This has already been posted before.")
+		result.AddFieldFailure("title", duplicateTitleMessage(idea, user))
+	}
+
+	usage, err := services.Tenants.GetUsage()
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	if !usage.CanAddIdea() {
+		msg := "This site has reached the limit of {max} ideas of its current plan."
+		result.AddFailuref(msg, i18n.Params{"max": usage.Plan.MaxIdeas})
 	}
 
 	return result
 }
 
+//duplicateTitleMessage explains why a title is taken without revealing private ideas the user can't see
+func duplicateTitleMessage(idea *models.Idea, user *models.User) string {
+	if idea.IsVisibleTo(user) {
+		return "This has already been posted before."
+	}
+	return "This title can't be used. Please choose a different one."
+}
+
 // UpdateIdea is used to edit an existing new idea
 type UpdateIdea struct {
 	Model *models.UpdateIdea
@@ -67,7 +86,7 @@ func (input *UpdateIdea) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *UpdateIdea) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsCollaborator()
+	return user != nil && user.Can(models.PermissionEditIdeas)
 }
 
 // Validate is current model is valid
@@ -95,7 +114,7 @@ func (input *UpdateIdea) Validate(user *models.User, services *app.Services) *va
 	if err != nil && errors.Cause(err) != app.ErrNotFound {
 		return validate.Error(err)
 	} else if another != nil && another.ID != idea.ID {
-		result.AddFieldFailure("title", "This has already been posted before.")
+		result.AddFieldFailure("title", duplicateTitleMessage(another, user))
 	}
 
 	input.Idea = idea
@@ -144,7 +163,7 @@ func (input *SetResponse) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *SetResponse) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsCollaborator()
+	return user != nil && user.Can(models.PermissionRespond)
 }
 
 // Validate is current model is valid
@@ -169,6 +188,15 @@ func (input *SetResponse) Validate(user *models.User, services *app.Services) *v
 			}
 		}
 		if original != nil {
+			if original.IsPrivate {
+				idea, err := services.Ideas.GetByNumber(input.Model.Number)
+				if err != nil {
+					return validate.Error(err)
+				}
+				if !idea.IsPrivate {
+					result.AddFieldFailure("originalNumber", "A public idea cannot be merged into a private one.")
+				}
+			}
 			input.Original = original
 		}
 	} else if input.Model.Status != models.IdeaOpen && input.Model.Text == "" {
@@ -178,6 +206,43 @@ func (input *SetResponse) Validate(user *models.User, services *app.Services) *v
 	return result
 }
 
+// SetIdeaPrivacy represents the action of making an idea private or public
+type SetIdeaPrivacy struct {
+	Model *models.SetIdeaPrivacy
+	Idea  *models.Idea
+}
+
+// Initialize the model
+func (input *SetIdeaPrivacy) Initialize() interface{} {
+	input.Model = new(models.SetIdeaPrivacy)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *SetIdeaPrivacy) IsAuthorized(user *models.User, services *app.Services) bool {
+	if user == nil {
+		return false
+	}
+
+	idea, err := services.Ideas.GetByNumber(input.Model.Number)
+	if err != nil {
+		return false
+	}
+
+	return user.ID == idea.User.ID || user.Can(models.PermissionEditIdeas)
+}
+
+// Validate if current model is valid
+func (input *SetIdeaPrivacy) Validate(user *models.User, services *app.Services) *validate.Result {
+	idea, err := services.Ideas.GetByNumber(input.Model.Number)
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	input.Idea = idea
+	return validate.Success()
+}
+
 // DeleteIdea represents the action of an administrator deleting an existing Idea
 type DeleteIdea struct {
 	Model *models.DeleteIdea
@@ -192,7 +257,7 @@ func (input *DeleteIdea) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *DeleteIdea) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsAdministrator()
+	return user != nil && user.Can(models.PermissionDeleteIdeas)
 }
 
 // Validate if current model is valid
@@ -236,7 +301,7 @@ func (input *EditComment) IsAuthorized(user *models.User, services *app.Services
 		return false
 	}
 
-	return user.ID == comment.User.ID || user.IsCollaborator()
+	return user.ID == comment.User.ID || user.Can(models.PermissionModerateComments)
 }
 
 // Validate if current model is valid
@@ -249,3 +314,28 @@ func (input *EditComment) Validate(user *models.User, services *app.Services) *v
 
 	return result
 }
+
+// DeleteComment represents the action of a moderator deleting an existing comment
+type DeleteComment struct {
+	Model *models.DeleteComment
+}
+
+// Initialize the model
+func (input *DeleteComment) Initialize() interface{} {
+	input.Model = new(models.DeleteComment)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *DeleteComment) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionModerateComments)
+}
+
+// Validate if current model is valid
+func (input *DeleteComment) Validate(user *models.User, services *app.Services) *validate.Result {
+	_, err := services.Ideas.GetCommentByID(input.Model.ID)
+	if err != nil {
+		return validate.Error(err)
+	}
+	return validate.Success()
+}
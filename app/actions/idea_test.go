@@ -75,3 +75,23 @@ func TestDeleteIdea_WhenIsBeingReferenced(t *testing.T) {
 	model.Number = idea1.Number
 	ExpectFailed(action.Validate(nil, services))
 }
+
+func TestSetResponse_PublicIntoPrivate(t *testing.T) {
+	RegisterT(t)
+
+	services.SetCurrentUser(&models.User{ID: 1, Role: models.RoleAdministrator})
+	idea1, _ := services.Ideas.Add("Public Idea", "")
+	idea2, _ := services.Ideas.Add("Private Idea", "")
+	services.Ideas.SetPrivacy(idea2, true)
+
+	action := &actions.SetResponse{Model: &models.SetResponse{
+		Number:         idea1.Number,
+		Status:         models.IdeaDuplicate,
+		OriginalNumber: idea2.Number,
+	}}
+	ExpectFailed(action.Validate(nil, services), "originalNumber")
+
+	action.Model.Number = idea2.Number
+	action.Model.OriginalNumber = idea1.Number
+	ExpectSuccess(action.Validate(nil, services))
+}
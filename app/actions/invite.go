@@ -1,12 +1,14 @@
 package actions
 
 import (
-	"fmt"
+	"encoding/csv"
+	"io"
 	"strings"
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
@@ -25,25 +27,13 @@ func (input *InviteUsers) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *InviteUsers) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsCollaborator()
+	return user != nil && user.Can(models.PermissionInvite)
 }
 
 // Validate is current model is valid
 func (input *InviteUsers) Validate(user *models.User, services *app.Services) *validate.Result {
 	result := validate.Success()
-
-	if input.Model.Subject == "" {
-		result.AddFieldFailure("subject", "Subject is required.")
-	} else if len(input.Model.Subject) > 70 {
-		result.AddFieldFailure("subject", "Subject must be less than 70 characters.")
-	}
-
-	if input.Model.Message == "" {
-		result.AddFieldFailure("message", "Message is required.")
-	} else if !strings.Contains(input.Model.Message, app.InvitePlaceholder) {
-		msg := fmt.Sprintf("Your message is missing the invitation link placeholder. Please add '%s' to your message.", app.InvitePlaceholder)
-		result.AddFieldFailure("message", msg)
-	}
+	validateInviteMessage(result, input.Model.Subject, input.Model.Message)
 
 	//When it's a sample invite, we skip recipients validation
 	if !input.IsSampleInvite {
@@ -58,7 +48,7 @@ func (input *InviteUsers) Validate(user *models.User, services *app.Services) *v
 			if email != "" {
 				emailResult := validate.Email(email)
 				if !emailResult.Ok {
-					result.AddFieldFailure("recipients", emailResult.Messages...)
+					result.AddFieldMessages("recipients", emailResult.Messages...)
 				}
 			}
 		}
@@ -79,10 +69,143 @@ func (input *InviteUsers) Validate(user *models.User, services *app.Services) *v
 
 			if len(input.Invitations) == 0 {
 				result.AddFieldFailure("recipients", "All these addresses have already been registered on this site.")
+			} else {
+				usage, err := services.Tenants.GetUsage()
+				if err != nil {
+					return validate.Error(err)
+				}
+
+				if !usage.CanAddUsers(len(input.Invitations)) {
+					msg := "This site can only have {max} users on its current plan and already has {count}."
+					result.AddFieldFailuref("recipients", msg, i18n.Params{"max": usage.Plan.MaxUsers, "count": usage.Users})
+				}
+			}
+		}
+
+	}
+
+	return result
+}
+
+func validateInviteMessage(result *validate.Result, subject, message string) {
+	if subject == "" {
+		result.AddFieldFailure("subject", "Subject is required.")
+	} else if len(subject) > 70 {
+		result.AddFieldFailure("subject", "Subject must be less than 70 characters.")
+	}
+
+	if message == "" {
+		result.AddFieldFailure("message", "Message is required.")
+	} else if !strings.Contains(message, app.InvitePlaceholder) {
+		msg := "Your message is missing the invitation link placeholder. Please add '{placeholder}' to your message."
+		result.AddFieldFailuref("message", msg, i18n.Params{"placeholder": app.InvitePlaceholder})
+	}
+}
+
+// ImportInvitations is used to invite users listed on a CSV file
+type ImportInvitations struct {
+	Model       *models.ImportInvitations
+	Invitations []*models.UserInvitation
+}
+
+// Initialize the model
+func (input *ImportInvitations) Initialize() interface{} {
+	input.Model = new(models.ImportInvitations)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *ImportInvitations) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionInvite)
+}
+
+// Validate is current model is valid
+func (input *ImportInvitations) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+	validateInviteMessage(result, input.Model.Subject, input.Model.Message)
+
+	reader := csv.NewReader(strings.NewReader(input.Model.CSV))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	input.Invitations = make([]*models.UserInvitation, 0)
+	seen := make(map[string]bool)
+	invalid := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.AddFieldFailuref("csv", "Could not read the file: {error}", i18n.Params{"error": err.Error()})
+			return result
+		}
+
+		email := strings.ToLower(strings.TrimSpace(record[0]))
+		if email == "" || seen[email] {
+			continue
+		}
+
+		if !validate.Email(email).Ok {
+			//First line is allowed to be a header, e.g. 'email,name'
+			if line > 1 {
+				invalid++
+				if invalid <= 10 {
+					result.AddFieldFailuref("csv", "Line {line}: '{email}' is not a valid email address.", i18n.Params{"line": line, "email": email})
+				}
 			}
+			continue
+		}
+
+		name := ""
+		if len(record) > 1 {
+			name = strings.TrimSpace(record[1])
+		}
+		if len(name) > 50 {
+			result.AddFieldFailuref("csv", "Line {line}: name must be less than 50 characters.", i18n.Params{"line": line})
+			continue
 		}
 
+		seen[email] = true
+		input.Invitations = append(input.Invitations, &models.UserInvitation{
+			Email:           email,
+			Name:            name,
+			VerificationKey: models.GenerateVerificationKey(),
+		})
+	}
+
+	if invalid > 10 {
+		result.AddFieldFailuref("csv", "And {count} other invalid email addresses.", i18n.Params{"count": invalid - 10})
 	}
 
+	if len(input.Invitations) == 0 {
+		result.AddFieldFailure("csv", "The file must have at least one email address.")
+	} else if len(input.Invitations) > 10000 {
+		result.AddFieldFailure("csv", "Too many recipients. We limit at 10000 recipients per import.")
+	}
+
+	return result
+}
+
+// ResendInvitation is used to send a new link to an invited user
+type ResendInvitation struct {
+	Model *models.ResendInvitation
+}
+
+// Initialize the model
+func (input *ResendInvitation) Initialize() interface{} {
+	input.Model = new(models.ResendInvitation)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *ResendInvitation) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionInvite)
+}
+
+// Validate is current model is valid
+func (input *ResendInvitation) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+	validateInviteMessage(result, input.Model.Subject, input.Model.Message)
 	return result
 }
@@ -167,3 +167,53 @@ func TestInviteUsers_SampleInvite_IgnoreRecipients(t *testing.T) {
 
 	ExpectSuccess(action.Validate(nil, services))
 }
+
+func TestImportInvitations_Valid(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.ImportInvitations{Model: &models.ImportInvitations{
+		Subject: "Share your feedback.",
+		Message: "Use this link to join our community: %invite%",
+		CSV:     "email,name\nJon.Snow@got.com, Jon Snow\narya.stark@got.com\n\njon.snow@got.com,Duplicate\n",
+	}}
+	result := action.Validate(nil, services)
+	ExpectSuccess(result)
+	Expect(action.Invitations).HasLen(2)
+	Expect(action.Invitations[0].Email).Equals("jon.snow@got.com")
+	Expect(action.Invitations[0].Name).Equals("Jon Snow")
+	Expect(action.Invitations[0].VerificationKey).IsNotEmpty()
+	Expect(action.Invitations[1].Email).Equals("arya.stark@got.com")
+	Expect(action.Invitations[1].Name).Equals("")
+}
+
+func TestImportInvitations_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.ImportInvitations{Model: &models.ImportInvitations{
+		Subject: "Share your feedback.",
+		Message: "Use this link to join our community: %invite%",
+		CSV:     "jon.snow@got.com\nnot an email\n",
+	}}
+	result := action.Validate(nil, services)
+	ExpectFailed(result, "csv")
+
+	action = &actions.ImportInvitations{Model: &models.ImportInvitations{}}
+	result = action.Validate(nil, services)
+	ExpectFailed(result, "subject", "message", "csv")
+}
+
+func TestResendInvitation(t *testing.T) {
+	RegisterT(t)
+
+	action := &actions.ResendInvitation{Model: &models.ResendInvitation{ID: 1, Message: "Please!"}}
+	result := action.Validate(nil, services)
+	ExpectFailed(result, "subject", "message")
+
+	action = &actions.ResendInvitation{Model: &models.ResendInvitation{
+		ID:      1,
+		Subject: "Share your feedback.",
+		Message: "Use this link to join our community: %invite%",
+	}}
+	result = action.Validate(nil, services)
+	ExpectSuccess(result)
+}
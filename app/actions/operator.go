@@ -0,0 +1,169 @@
+package actions
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
+)
+
+//OperatorSignIn is used by operators to sign in on the super-admin console
+type OperatorSignIn struct {
+	Model *models.OperatorSignIn
+}
+
+// Initialize the model
+func (input *OperatorSignIn) Initialize() interface{} {
+	input.Model = new(models.OperatorSignIn)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *OperatorSignIn) IsAuthorized(user *models.User, services *app.Services) bool {
+	return true
+}
+
+// Validate is current model is valid
+func (input *OperatorSignIn) Validate(user *models.User, services *app.Services) *validate.Result {
+	input.Model.Email = strings.ToLower(strings.TrimSpace(input.Model.Email))
+	secret := env.Operators()[input.Model.Email]
+	if secret == "" || subtle.ConstantTimeCompare([]byte(input.Model.Secret), []byte(secret)) != 1 {
+		return validate.Failed([]string{"Invalid email or secret."})
+	}
+	return validate.Success()
+}
+
+//ChangeTenantSubdomain is used by operators to move a tenant to another subdomain
+type ChangeTenantSubdomain struct {
+	Model  *models.ChangeTenantSubdomain
+	Tenant *models.Tenant
+}
+
+// Initialize the model
+func (input *ChangeTenantSubdomain) Initialize() interface{} {
+	input.Model = new(models.ChangeTenantSubdomain)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+// Operators are not users of any tenant, so they are verified by the IsOperator middleware
+func (input *ChangeTenantSubdomain) IsAuthorized(user *models.User, services *app.Services) bool {
+	return true
+}
+
+// Validate is current model is valid
+func (input *ChangeTenantSubdomain) Validate(user *models.User, services *app.Services) *validate.Result {
+	tenant, err := services.Tenants.GetByID(input.Model.TenantID)
+	if err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return validate.Failed([]string{"Tenant not found."})
+		}
+		return validate.Error(err)
+	}
+	input.Tenant = tenant
+
+	//Tenants can go back to one of their own aliases
+	services.SetCurrentTenant(tenant)
+	result := validate.Success()
+	subdomainResult := validate.Subdomain(services.Tenants, input.Model.Subdomain)
+	if !subdomainResult.Ok {
+		result.AddFieldMessages("subdomain", subdomainResult.Messages...)
+	}
+	return result
+}
+
+//ImpersonateTenantUser is used by operators to sign in as an administrator of a tenant for support
+type ImpersonateTenantUser struct {
+	Model  *models.ImpersonateTenantUser
+	Tenant *models.Tenant
+	User   *models.User
+}
+
+// Initialize the model
+func (input *ImpersonateTenantUser) Initialize() interface{} {
+	input.Model = new(models.ImpersonateTenantUser)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+// Operators are not users of any tenant, so they are verified by the IsOperator middleware
+func (input *ImpersonateTenantUser) IsAuthorized(user *models.User, services *app.Services) bool {
+	return true
+}
+
+// Validate is current model is valid
+func (input *ImpersonateTenantUser) Validate(user *models.User, services *app.Services) *validate.Result {
+	tenant, err := services.Tenants.GetByID(input.Model.TenantID)
+	if err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return validate.Failed([]string{"Tenant not found."})
+		}
+		return validate.Error(err)
+	}
+
+	if tenant.Status != models.TenantActive {
+		return validate.Failed([]string{"Only active tenants can be impersonated."})
+	}
+	input.Tenant = tenant
+
+	//Services are scoped to the impersonated tenant from now on
+	services.SetCurrentTenant(tenant)
+	users, err := services.Users.GetAll()
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	for _, u := range users {
+		if u.IsAdministrator() && (input.Model.UserID == 0 || input.Model.UserID == u.ID) {
+			if input.User == nil || u.ID < input.User.ID {
+				input.User = u
+			}
+		}
+	}
+
+	if input.User == nil {
+		return validate.Failed([]string{"Tenant doesn't have an administrator with given id."})
+	}
+
+	return validate.Success()
+}
+
+//ChangeTenantPlan is used by operators to move a tenant to another plan
+type ChangeTenantPlan struct {
+	Model  *models.ChangeTenantPlan
+	Tenant *models.Tenant
+}
+
+// Initialize the model
+func (input *ChangeTenantPlan) Initialize() interface{} {
+	input.Model = new(models.ChangeTenantPlan)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+// Operators are not users of any tenant, so they are verified by the IsOperator middleware
+func (input *ChangeTenantPlan) IsAuthorized(user *models.User, services *app.Services) bool {
+	return true
+}
+
+// Validate is current model is valid
+func (input *ChangeTenantPlan) Validate(user *models.User, services *app.Services) *validate.Result {
+	tenant, err := services.Tenants.GetByID(input.Model.TenantID)
+	if err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return validate.Failed([]string{"Tenant not found."})
+		}
+		return validate.Error(err)
+	}
+	input.Tenant = tenant
+
+	result := validate.Success()
+	if models.GetPlan(input.Model.Plan) == nil {
+		result.AddFieldFailure("plan", "Unknown plan.")
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package actions
+
+import (
+	"regexp"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/gosimple/slug"
+)
+
+var pageSlugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// SavePage is used to create a new page or edit an existing one
+type SavePage struct {
+	Page  *models.Page
+	Model *models.SavePage
+}
+
+// Initialize the model
+func (input *SavePage) Initialize() interface{} {
+	input.Model = new(models.SavePage)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *SavePage) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *SavePage) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+	if input.Model.CurrentSlug != "" {
+		page, err := services.Pages.GetBySlug(input.Model.CurrentSlug)
+		if err != nil {
+			return validate.Error(err)
+		}
+		input.Page = page
+	}
+
+	if input.Model.Title == "" {
+		result.AddFieldFailure("title", "Title is required.")
+	} else if len(input.Model.Title) > 100 {
+		result.AddFieldFailure("title", "Title must be less than 100 characters.")
+	}
+
+	//Slug is optional and generated from the title when not given
+	if input.Model.Slug == "" {
+		input.Model.Slug = slug.Make(input.Model.Title)
+	}
+
+	if len(input.Model.Slug) > 60 {
+		result.AddFieldFailure("slug", "Slug must be less than 60 characters.")
+	} else if !pageSlugRegex.MatchString(input.Model.Slug) {
+		result.AddFieldFailure("slug", "Slug can only have lowercase letters, numbers and dashes.")
+	} else {
+		duplicate, err := services.Pages.GetBySlug(input.Model.Slug)
+		if err != nil && errors.Cause(err) != app.ErrNotFound {
+			return validate.Error(err)
+		} else if err == nil && (input.Page == nil || input.Page.ID != duplicate.ID) {
+			result.AddFieldFailure("slug", "This slug is already in use by another page.")
+		}
+	}
+
+	if len(input.Model.Content) > 100000 {
+		result.AddFieldFailure("content", "Content must be smaller than 100KB.")
+	}
+
+	if input.Model.Visibility != models.PageVisibilityPublic &&
+		input.Model.Visibility != models.PageVisibilityMembers &&
+		input.Model.Visibility != models.PageVisibilityDraft {
+		result.AddFieldFailure("visibility", "Visibility is invalid.")
+	}
+
+	if input.Model.Placement != models.PagePlacementNone &&
+		input.Model.Placement != models.PagePlacementHeader &&
+		input.Model.Placement != models.PagePlacementFooter {
+		result.AddFieldFailure("placement", "Placement is invalid.")
+	}
+
+	return result
+}
+
+// DeletePage is used to delete an existing page
+type DeletePage struct {
+	Page  *models.Page
+	Model *models.DeletePage
+}
+
+// Initialize the model
+func (input *DeletePage) Initialize() interface{} {
+	input.Model = new(models.DeletePage)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *DeletePage) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *DeletePage) Validate(user *models.User, services *app.Services) *validate.Result {
+	page, err := services.Pages.GetBySlug(input.Model.Slug)
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	input.Page = page
+	return validate.Success()
+}
@@ -1,10 +1,9 @@
 package actions
 
 import (
-	"fmt"
-
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
@@ -36,6 +35,13 @@ func (input *UpdateUserSettings) Validate(user *models.User, services *app.Servi
 		result.AddFieldFailure("name", "Name must be less than 50 characters.")
 	}
 
+	//Users without a locale read the site on the locale of their tenant
+	if input.Model.Locale != "" {
+		if localeResult := validate.Locale(input.Model.Locale); !localeResult.Ok {
+			result.AddFieldMessages("locale", localeResult.Messages...)
+		}
+	}
+
 	if input.Model.Settings != nil {
 		for k, v := range input.Model.Settings {
 			ok := false
@@ -43,12 +49,12 @@ func (input *UpdateUserSettings) Validate(user *models.User, services *app.Servi
 				if e.UserSettingsKeyName == k {
 					ok = true
 					if !e.Validate(v) {
-						result.AddFieldFailure("settings", fmt.Sprintf("Settings %s has an invalid value %s.", k, v))
+						result.AddFieldFailuref("settings", "Settings {name} has an invalid value {value}.", i18n.Params{"name": k, "value": v})
 					}
 				}
 			}
 			if ok == false {
-				result.AddFieldFailure("settings", fmt.Sprintf("Unknown settings named %s.", k))
+				result.AddFieldFailuref("settings", "Unknown settings named {name}.", i18n.Params{"name": k})
 			}
 		}
 	}
@@ -38,7 +38,7 @@ func (input *SignInByEmail) Validate(user *models.User, services *app.Services)
 
 	emailResult := validate.Email(input.Model.Email)
 	if !emailResult.Ok {
-		result.AddFieldFailure("email", emailResult.Messages...)
+		result.AddFieldMessages("email", emailResult.Messages...)
 	}
 
 	return result
@@ -79,8 +79,10 @@ func (input *CompleteProfile) Validate(user *models.User, services *app.Services
 		request2, err2 := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, input.Model.Key)
 		if err1 == nil {
 			input.Model.Email = request1.Email
+			input.Model.Kind = request1.Kind
 		} else if err2 == nil {
 			input.Model.Email = request2.Email
+			input.Model.Kind = request2.Kind
 		} else {
 			result.AddFieldFailure("key", "Key is invalid.")
 		}
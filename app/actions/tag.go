@@ -26,7 +26,7 @@ func (input *CreateEditTag) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *CreateEditTag) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsAdministrator()
+	return user != nil && user.Can(models.PermissionManageTags)
 }
 
 // Validate is current model is valid
@@ -78,7 +78,7 @@ func (input *DeleteTag) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *DeleteTag) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsAdministrator()
+	return user != nil && user.Can(models.PermissionManageTags)
 }
 
 // Validate is current model is valid
@@ -107,7 +107,7 @@ func (input *AssignUnassignTag) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *AssignUnassignTag) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.IsCollaborator()
+	return user != nil && user.Can(models.PermissionAssignTags)
 }
 
 // Validate is current model is valid
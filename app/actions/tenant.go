@@ -1,11 +1,16 @@
 package actions
 
 import (
+	"strings"
+
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/css"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/img"
 	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/saml"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
@@ -44,7 +49,7 @@ func (input *CreateTenant) Validate(user *models.User, services *app.Services) *
 			result.AddFieldFailure("email", "Email is required.")
 		} else {
 			if emailResult := validate.Email(input.Model.Email); !emailResult.Ok {
-				result.AddFieldFailure("email", emailResult.Messages...)
+				result.AddFieldMessages("email", emailResult.Messages...)
 			}
 		}
 
@@ -66,7 +71,7 @@ func (input *CreateTenant) Validate(user *models.User, services *app.Services) *
 
 	subdomainResult := validate.Subdomain(services.Tenants, input.Model.Subdomain)
 	if !subdomainResult.Ok {
-		result.AddFieldFailure("subdomain", subdomainResult.Messages...)
+		result.AddFieldMessages("subdomain", subdomainResult.Messages...)
 	}
 
 	return result
@@ -85,7 +90,7 @@ func (input *UpdateTenantSettings) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *UpdateTenantSettings) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.Role == models.RoleAdministrator
+	return user != nil && user.Can(models.PermissionManageSettings)
 }
 
 // Validate is current model is valid
@@ -115,6 +120,15 @@ func (input *UpdateTenantSettings) Validate(user *models.User, services *app.Ser
 		}
 	}
 
+	usage, err := services.Tenants.GetUsage()
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	if input.Model.Logo != nil && input.Model.Logo.Upload != nil && !usage.CanStore(int64(len(input.Model.Logo.Upload.Content))) {
+		result.AddFieldFailure("logo", "This site has reached the storage limit of its plan.")
+	}
+
 	if input.Model.Title == "" {
 		result.AddFieldFailure("title", "Title is required.")
 	}
@@ -127,9 +141,126 @@ func (input *UpdateTenantSettings) Validate(user *models.User, services *app.Ser
 		result.AddFieldFailure("invitation", "Invitation must have less than 60 characters.")
 	}
 
+	if env.IsSingleHostMode() {
+		input.Model.Subdomain = ""
+	}
+
+	if input.Model.Subdomain != "" {
+		if subdomainResult := validate.Subdomain(services.Tenants, input.Model.Subdomain); !subdomainResult.Ok {
+			result.AddFieldMessages("subdomain", subdomainResult.Messages...)
+		}
+	}
+
 	if input.Model.CNAME != "" {
 		if cnameResult := validate.CNAME(services.Tenants, input.Model.CNAME); !cnameResult.Ok {
-			result.AddFieldFailure("cname", cnameResult.Messages...)
+			result.AddFieldMessages("cname", cnameResult.Messages...)
+		} else if !usage.Plan.AllowCustomDomain {
+			//Tenants that moved to a plan without custom domains can still keep the one they have
+			tenant, err := services.Tenants.GetByDomain(input.Model.CNAME)
+			if err != nil && errors.Cause(err) != app.ErrNotFound {
+				return validate.Error(err)
+			}
+			if tenant == nil || tenant.ID != user.Tenant.ID {
+				result.AddFieldFailure("cname", "Custom domains are not available on the current plan.")
+			}
+		}
+	}
+
+	if input.Model.Locale != "" {
+		if localeResult := validate.Locale(input.Model.Locale); !localeResult.Ok {
+			result.AddFieldMessages("locale", localeResult.Messages...)
+		}
+	}
+
+	return result
+}
+
+//UpdateTenantTheme is the input model used to update the theme of a tenant
+type UpdateTenantTheme struct {
+	Model *models.UpdateTenantTheme
+}
+
+// Initialize the model
+func (input *UpdateTenantTheme) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantTheme)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantTheme) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantTheme) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if input.Model.PrimaryColor != "" && !colorRegex.MatchString(input.Model.PrimaryColor) {
+		result.AddFieldFailure("primaryColor", "Primary color is invalid.")
+	}
+
+	if input.Model.AccentColor != "" && !colorRegex.MatchString(input.Model.AccentColor) {
+		result.AddFieldFailure("accentColor", "Accent color is invalid.")
+	}
+
+	if len(input.Model.CustomCSS) > 20000 {
+		result.AddFieldFailure("customCSS", "Custom CSS must be smaller than 20KB.")
+	}
+	input.Model.CustomCSS = css.Sanitize(input.Model.CustomCSS)
+
+	var size int64
+	if input.Model.Favicon != nil && input.Model.Favicon.Upload != nil && len(input.Model.Favicon.Upload.Content) > 0 {
+		favicon, err := img.Parse(input.Model.Favicon.Upload.Content)
+		if err != nil {
+			if err == img.ErrNotSupported {
+				result.AddFieldFailure("favicon", "This file format not supported.")
+			} else {
+				return validate.Error(err)
+			}
+		} else {
+			if favicon.Width < 32 || favicon.Height < 32 {
+				result.AddFieldFailure("favicon", "The image must have minimum dimensions of 32x32 pixels.")
+			}
+
+			if favicon.Width != favicon.Height {
+				result.AddFieldFailure("favicon", "The image must have an aspect ratio of 1:1.")
+			}
+
+			if favicon.Size > 51200 {
+				result.AddFieldFailure("favicon", "The image size must be smaller than 50KB.")
+			}
+		}
+		size += int64(len(input.Model.Favicon.Upload.Content))
+	}
+
+	if input.Model.Header != nil && input.Model.Header.Upload != nil && len(input.Model.Header.Upload.Content) > 0 {
+		header, err := img.Parse(input.Model.Header.Upload.Content)
+		if err != nil {
+			if err == img.ErrNotSupported {
+				result.AddFieldFailure("header", "This file format not supported.")
+			} else {
+				return validate.Error(err)
+			}
+		} else {
+			if header.Width < 600 {
+				result.AddFieldFailure("header", "The image must be at least 600 pixels wide.")
+			}
+
+			if header.Size > 512000 {
+				result.AddFieldFailure("header", "The image size must be smaller than 500KB.")
+			}
+		}
+		size += int64(len(input.Model.Header.Upload.Content))
+	}
+
+	if size > 0 {
+		usage, err := services.Tenants.GetUsage()
+		if err != nil {
+			return validate.Error(err)
+		}
+
+		if !usage.CanStore(size) {
+			return validate.Failed([]string{"This site has reached the storage limit of its plan."})
 		}
 	}
 
@@ -149,10 +280,402 @@ func (input *UpdateTenantPrivacy) Initialize() interface{} {
 
 // IsAuthorized returns true if current user is authorized to perform this action
 func (input *UpdateTenantPrivacy) IsAuthorized(user *models.User, services *app.Services) bool {
-	return user != nil && user.Role == models.RoleAdministrator
+	return user != nil && user.Can(models.PermissionManageSettings)
 }
 
 // Validate is current model is valid
 func (input *UpdateTenantPrivacy) Validate(user *models.User, services *app.Services) *validate.Result {
+	if input.Model.IsPrivate {
+		usage, err := services.Tenants.GetUsage()
+		if err != nil {
+			return validate.Error(err)
+		}
+
+		if !usage.Plan.AllowPrivate {
+			return validate.Failed([]string{"Private sites are not available on the current plan."})
+		}
+	}
+	return validate.Success()
+}
+
+//UpdateTenantTwoFactorSettings is the input model used to require two-factor authentication from staff members
+type UpdateTenantTwoFactorSettings struct {
+	Model *models.UpdateTenantTwoFactorSettings
+}
+
+// Initialize the model
+func (input *UpdateTenantTwoFactorSettings) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantTwoFactorSettings)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantTwoFactorSettings) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantTwoFactorSettings) Validate(user *models.User, services *app.Services) *validate.Result {
 	return validate.Success()
 }
+
+//UpdateTenantAutoJoinSettings is the input model used to configure which email domains can join without an invitation
+type UpdateTenantAutoJoinSettings struct {
+	Model *models.UpdateTenantAutoJoinSettings
+}
+
+// Initialize the model
+func (input *UpdateTenantAutoJoinSettings) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantAutoJoinSettings)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantAutoJoinSettings) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantAutoJoinSettings) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if input.Model.Role == 0 {
+		input.Model.Role = models.RoleVisitor
+	}
+
+	//Administrators must always be chosen explicitly
+	if input.Model.Role != models.RoleVisitor && input.Model.Role != models.RoleCollaborator {
+		result.AddFieldFailure("role", "Role must be either visitor or collaborator.")
+	}
+
+	if len(input.Model.Domains) > 20 {
+		result.AddFieldFailure("domains", "A maximum of 20 domains are allowed.")
+		return result
+	}
+
+	domains := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, domain := range input.Model.Domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain == "" || seen[domain] {
+			continue
+		}
+
+		domainResult := validate.Domain(domain)
+		if !domainResult.Ok {
+			result.AddFieldMessages("domains", domainResult.Messages...)
+			continue
+		}
+
+		seen[domain] = true
+		domains = append(domains, domain)
+	}
+	input.Model.Domains = domains
+
+	return result
+}
+
+//UpdateTenantEmbedSettings is the input model used to configure which websites can embed the feedback widget
+type UpdateTenantEmbedSettings struct {
+	Model *models.UpdateTenantEmbedSettings
+}
+
+// Initialize the model
+func (input *UpdateTenantEmbedSettings) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantEmbedSettings)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantEmbedSettings) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantEmbedSettings) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if len(input.Model.Origins) > 20 {
+		result.AddFieldFailure("origins", "A maximum of 20 websites are allowed.")
+		return result
+	}
+
+	origins := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, origin := range input.Model.Origins {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+
+		originResult := validate.Origin(origin)
+		if !originResult.Ok {
+			result.AddFieldMessages("origins", originResult.Messages...)
+			continue
+		}
+
+		origin = strings.ToLower(strings.TrimRight(origin, "/"))
+		if !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+	input.Model.Origins = origins
+
+	return result
+}
+
+//validateProviderURL only accepts HTTPS addresses, as they are requested by the server on behalf of the tenant
+func validateProviderURL(rawurl string) *validate.Result {
+	result := validate.URL(rawurl)
+	if result.Ok && !strings.HasPrefix(strings.ToLower(rawurl), "https://") {
+		return validate.Failed([]string{"URL must use HTTPS."})
+	}
+	return result
+}
+
+//CreateEditOAuthConfig is used to register or change a custom OAuth provider
+type CreateEditOAuthConfig struct {
+	Model *models.CreateEditOAuthConfig
+}
+
+// Initialize the model
+func (input *CreateEditOAuthConfig) Initialize() interface{} {
+	input.Model = new(models.CreateEditOAuthConfig)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *CreateEditOAuthConfig) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *CreateEditOAuthConfig) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	isNew := input.Model.Provider == ""
+	if isNew {
+		input.Model.Provider = "_" + models.GenerateVerificationKey()[:10]
+	} else {
+		_, err := services.Tenants.GetOAuthConfigByProvider(input.Model.Provider)
+		if err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				result.AddFieldFailure("provider", "Unknown provider.")
+				return result
+			}
+			return validate.Error(err)
+		}
+	}
+
+	if input.Model.DiscoveryURL != "" {
+		if urlResult := validateProviderURL(input.Model.DiscoveryURL); !urlResult.Ok {
+			result.AddFieldMessages("discoveryUrl", urlResult.Messages...)
+		} else if discovery, err := services.OAuth.Discover(input.Model.DiscoveryURL); err != nil {
+			result.AddFieldFailure("discoveryUrl", "Could not read the OpenID Connect configuration from this URL.")
+		} else {
+			//Explicit endpoints take precedence over the discovered ones
+			if input.Model.AuthorizeURL == "" {
+				input.Model.AuthorizeURL = discovery.AuthorizationEndpoint
+			}
+			if input.Model.TokenURL == "" {
+				input.Model.TokenURL = discovery.TokenEndpoint
+			}
+			if input.Model.ProfileURL == "" {
+				input.Model.ProfileURL = discovery.UserinfoEndpoint
+			}
+			if input.Model.Scope == "" {
+				input.Model.Scope = "openid profile email"
+			}
+			if input.Model.JSONUserIDPath == "" {
+				input.Model.JSONUserIDPath = "sub"
+			}
+			if input.Model.JSONUserNamePath == "" {
+				input.Model.JSONUserNamePath = "name"
+			}
+			if input.Model.JSONUserEmailPath == "" {
+				input.Model.JSONUserEmailPath = "email"
+			}
+		}
+	}
+
+	if input.Model.DisplayName == "" {
+		result.AddFieldFailure("displayName", "Display name is required.")
+	} else if len(input.Model.DisplayName) > 50 {
+		result.AddFieldFailure("displayName", "Display name must have less than 50 characters.")
+	}
+
+	if input.Model.Status != models.OAuthConfigEnabled && input.Model.Status != models.OAuthConfigDisabled {
+		result.AddFieldFailure("status", "Invalid status.")
+	}
+
+	if input.Model.ClientID == "" {
+		result.AddFieldFailure("clientId", "Client ID is required.")
+	} else if len(input.Model.ClientID) > 100 {
+		result.AddFieldFailure("clientId", "Client ID must have less than 100 characters.")
+	}
+
+	if isNew && input.Model.ClientSecret == "" {
+		result.AddFieldFailure("clientSecret", "Client Secret is required.")
+	} else if len(input.Model.ClientSecret) > 500 {
+		result.AddFieldFailure("clientSecret", "Client Secret must have less than 500 characters.")
+	}
+
+	for field, value := range map[string]string{
+		"authorizeUrl": input.Model.AuthorizeURL,
+		"tokenUrl":     input.Model.TokenURL,
+		"profileUrl":   input.Model.ProfileURL,
+	} {
+		if value == "" {
+			result.AddFieldFailure(field, "URL is required.")
+		} else if urlResult := validateProviderURL(value); !urlResult.Ok {
+			result.AddFieldMessages(field, urlResult.Messages...)
+		}
+	}
+
+	if len(input.Model.Scope) > 100 {
+		result.AddFieldFailure("scope", "Scope must have less than 100 characters.")
+	}
+
+	if input.Model.JSONUserIDPath == "" {
+		result.AddFieldFailure("jsonUserIdPath", "JSON Path for User ID is required.")
+	}
+
+	for field, value := range map[string]string{
+		"jsonUserIdPath":    input.Model.JSONUserIDPath,
+		"jsonUserNamePath":  input.Model.JSONUserNamePath,
+		"jsonUserEmailPath": input.Model.JSONUserEmailPath,
+	} {
+		if len(value) > 100 {
+			result.AddFieldFailure(field, "JSON Path must have less than 100 characters.")
+		}
+	}
+
+	return result
+}
+
+//UpdateTenantSAMLSettings is used to configure SAML single sign-on of current tenant
+type UpdateTenantSAMLSettings struct {
+	Model *models.UpdateTenantSAMLSettings
+}
+
+// Initialize the model
+func (input *UpdateTenantSAMLSettings) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantSAMLSettings)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantSAMLSettings) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantSAMLSettings) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if strings.TrimSpace(input.Model.Metadata) != "" {
+		metadata, err := saml.ParseMetadata([]byte(input.Model.Metadata))
+		if err != nil {
+			result.AddFieldFailure("metadata", "Metadata is not a valid SAML IdP metadata document.")
+			return result
+		}
+
+		//Explicit values take precedence over the ones read from metadata
+		if input.Model.IdPEntityID == "" {
+			input.Model.IdPEntityID = metadata.EntityID
+		}
+		if input.Model.IdPSSOURL == "" {
+			input.Model.IdPSSOURL = metadata.SSOURL
+		}
+		if input.Model.IdPCertificate == "" {
+			input.Model.IdPCertificate = metadata.Certificate
+		}
+	}
+
+	input.Model.IdPCertificate = strings.TrimSpace(input.Model.IdPCertificate)
+
+	if input.Model.IsEnforced && !input.Model.IsEnabled {
+		result.AddFieldFailure("isEnforced", "Single sign-on must be enabled to be enforced.")
+	}
+
+	if len(input.Model.IdPEntityID) > 300 {
+		result.AddFieldFailure("idpEntityId", "Entity ID must have less than 300 characters.")
+	}
+
+	if input.Model.IdPSSOURL == "" {
+		if input.Model.IsEnabled {
+			result.AddFieldFailure("idpSsoUrl", "SSO URL is required.")
+		}
+	} else if urlResult := validate.URL(input.Model.IdPSSOURL); !urlResult.Ok {
+		result.AddFieldMessages("idpSsoUrl", urlResult.Messages...)
+	}
+
+	if input.Model.IdPCertificate == "" {
+		if input.Model.IsEnabled {
+			result.AddFieldFailure("idpCertificate", "Certificate is required.")
+		}
+	} else if _, err := saml.ParseCertificate(input.Model.IdPCertificate); err != nil {
+		result.AddFieldFailure("idpCertificate", "Certificate must be a PEM or base64 encoded X.509 certificate.")
+	}
+
+	for field, value := range map[string]string{
+		"nameAttribute":          input.Model.NameAttribute,
+		"emailAttribute":         input.Model.EmailAttribute,
+		"roleAttribute":          input.Model.RoleAttribute,
+		"administratorRoleValue": input.Model.AdministratorRoleValue,
+		"collaboratorRoleValue":  input.Model.CollaboratorRoleValue,
+	} {
+		if len(value) > 200 {
+			result.AddFieldFailure(field, "Value must have less than 200 characters.")
+		}
+	}
+
+	if input.Model.RoleAttribute != "" && input.Model.AdministratorRoleValue == "" && input.Model.CollaboratorRoleValue == "" {
+		result.AddFieldFailure("roleAttribute", "At least one role value is required when mapping roles.")
+	}
+
+	return result
+}
+
+//UpdateTenantJWTSSOSettings is used to configure sign in with tokens issued by a trusted application
+type UpdateTenantJWTSSOSettings struct {
+	Model *models.UpdateTenantJWTSSOSettings
+}
+
+// Initialize the model
+func (input *UpdateTenantJWTSSOSettings) Initialize() interface{} {
+	input.Model = new(models.UpdateTenantJWTSSOSettings)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *UpdateTenantJWTSSOSettings) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageSettings)
+}
+
+// Validate is current model is valid
+func (input *UpdateTenantJWTSSOSettings) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	input.Model.Secret = strings.TrimSpace(input.Model.Secret)
+	input.Model.PublicKey = strings.TrimSpace(input.Model.PublicKey)
+
+	if input.Model.PublicKey != "" {
+		if _, err := jwt.ParsePublicKey(input.Model.PublicKey); err != nil {
+			result.AddFieldFailure("publicKey", "Public key must be a PEM encoded RSA or ECDSA key.")
+		}
+	} else if input.Model.Secret == "" {
+		if input.Model.IsEnabled && user.Tenant.JWTSSO.Secret == "" {
+			result.AddFieldFailure("secret", "Secret or public key is required.")
+		}
+	}
+
+	if input.Model.Secret != "" && len(input.Model.Secret) < 32 {
+		result.AddFieldFailure("secret", "Secret must have at least 32 characters.")
+	} else if len(input.Model.Secret) > 200 {
+		result.AddFieldFailure("secret", "Secret must have less than 200 characters.")
+	}
+
+	return result
+}
@@ -1,14 +1,27 @@
 package actions_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
 	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/storage/inmemory"
 
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/oauth"
 )
 
 func TestCreateTenant_ShouldHaveVerificationKey(t *testing.T) {
@@ -150,3 +163,249 @@ func TestUpdateTenantSettings_LargeLogo(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateEditOAuthConfig_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.CreateEditOAuthConfig{Model: &models.CreateEditOAuthConfig{
+		AuthorizeURL: "gitlab.com/oauth/authorize",
+		ProfileURL:   "ftp://gitlab.com/user",
+	}}
+	result := action.Validate(nil, services)
+	ExpectFailed(result, "displayName", "status", "clientId", "clientSecret", "authorizeUrl", "tokenUrl", "profileUrl", "jsonUserIdPath")
+	Expect(action.Model.Provider).ContainsSubstring("_")
+}
+
+func TestCreateEditOAuthConfig_UnknownProvider(t *testing.T) {
+	RegisterT(t)
+
+	tenants := &inmemory.TenantStorage{}
+	tenant, _ := tenants.Add("Demonstration", "demo", models.TenantActive)
+	tenants.SetCurrentTenant(tenant)
+
+	action := actions.CreateEditOAuthConfig{Model: &models.CreateEditOAuthConfig{Provider: "_unknown"}}
+	result := action.Validate(nil, &app.Services{Tenants: tenants})
+	ExpectFailed(result, "provider")
+}
+
+func TestCreateEditOAuthConfig_Discovery(t *testing.T) {
+	RegisterT(t)
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Expect(r.URL.Path).Equals("/.well-known/openid-configuration")
+		w.Write([]byte(`{
+			"authorization_endpoint": "` + server.URL + `/authorize",
+			"token_endpoint": "` + server.URL + `/token",
+			"userinfo_endpoint": "` + server.URL + `/userinfo"
+		}`))
+	}))
+	defer server.Close()
+
+	action := actions.CreateEditOAuthConfig{Model: &models.CreateEditOAuthConfig{
+		DisplayName:  "Keycloak",
+		Status:       models.OAuthConfigEnabled,
+		ClientID:     "fider",
+		ClientSecret: "s3cr3t",
+		DiscoveryURL: server.URL,
+		TokenURL:     "https://idp.example.com/token",
+	}}
+	ExpectSuccess(action.Validate(nil, &app.Services{OAuth: &oauth.HTTPService{Client: server.Client()}}))
+	Expect(action.Model.AuthorizeURL).Equals(server.URL + "/authorize")
+	Expect(action.Model.TokenURL).Equals("https://idp.example.com/token")
+	Expect(action.Model.ProfileURL).Equals(server.URL + "/userinfo")
+	Expect(action.Model.Scope).Equals("openid profile email")
+	Expect(action.Model.JSONUserIDPath).Equals("sub")
+	Expect(action.Model.JSONUserNamePath).Equals("name")
+	Expect(action.Model.JSONUserEmailPath).Equals("email")
+}
+
+func TestCreateEditOAuthConfig_InvalidDiscovery(t *testing.T) {
+	RegisterT(t)
+
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	action := actions.CreateEditOAuthConfig{Model: &models.CreateEditOAuthConfig{
+		DisplayName:    "Keycloak",
+		Status:         models.OAuthConfigEnabled,
+		ClientID:       "fider",
+		ClientSecret:   "s3cr3t",
+		DiscoveryURL:   server.URL,
+		AuthorizeURL:   "https://idp.example.com/authorize",
+		TokenURL:       "https://idp.example.com/token",
+		ProfileURL:     "https://idp.example.com/userinfo",
+		JSONUserIDPath: "sub",
+	}}
+	ExpectFailed(action.Validate(nil, &app.Services{OAuth: &oauth.HTTPService{Client: server.Client()}}), "discoveryUrl")
+}
+
+func TestCreateEditOAuthConfig_InsecureURL(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.CreateEditOAuthConfig{Model: &models.CreateEditOAuthConfig{
+		DisplayName:    "Keycloak",
+		Status:         models.OAuthConfigEnabled,
+		ClientID:       "fider",
+		ClientSecret:   "s3cr3t",
+		AuthorizeURL:   "https://idp.example.com/authorize",
+		TokenURL:       "http://idp.example.com/token",
+		ProfileURL:     "https://idp.example.com/userinfo",
+		JSONUserIDPath: "sub",
+	}}
+	ExpectFailed(action.Validate(nil, services), "tokenUrl")
+}
+
+func TestUpdateTenantSAMLSettings_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantSAMLSettings{Model: &models.UpdateTenantSAMLSettings{
+		IsEnabled:      true,
+		IdPSSOURL:      "idp.example.com/sso",
+		IdPCertificate: "not a certificate",
+		RoleAttribute:  "groups",
+	}}
+	result := action.Validate(nil, services)
+	ExpectFailed(result, "idpSsoUrl", "idpCertificate", "roleAttribute")
+
+	action = actions.UpdateTenantSAMLSettings{Model: &models.UpdateTenantSAMLSettings{
+		IsEnforced: true,
+		Metadata:   "<html></html>",
+	}}
+	result = action.Validate(nil, services)
+	ExpectFailed(result, "metadata")
+
+	action = actions.UpdateTenantSAMLSettings{Model: &models.UpdateTenantSAMLSettings{IsEnabled: true}}
+	result = action.Validate(nil, services)
+	ExpectFailed(result, "idpSsoUrl", "idpCertificate")
+
+	action = actions.UpdateTenantSAMLSettings{Model: &models.UpdateTenantSAMLSettings{IsEnforced: true}}
+	result = action.Validate(nil, services)
+	ExpectFailed(result, "isEnforced")
+}
+
+func TestUpdateTenantSAMLSettings_Metadata(t *testing.T) {
+	RegisterT(t)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	cert := base64.StdEncoding.EncodeToString(der)
+
+	action := actions.UpdateTenantSAMLSettings{Model: &models.UpdateTenantSAMLSettings{
+		IsEnabled: true,
+		IdPSSOURL: "https://idp.example.com/custom-sso",
+		Metadata: `<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com">
+			<IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+				<KeyDescriptor>
+					<KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>` + cert + `</X509Certificate></X509Data></KeyInfo>
+				</KeyDescriptor>
+				<SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso"/>
+			</IDPSSODescriptor>
+		</EntityDescriptor>`,
+	}}
+	result := action.Validate(nil, services)
+	ExpectSuccess(result)
+	Expect(action.Model.IdPEntityID).Equals("https://idp.example.com")
+	Expect(action.Model.IdPSSOURL).Equals("https://idp.example.com/custom-sso")
+	Expect(action.Model.IdPCertificate).Equals(cert)
+}
+
+func TestUpdateTenantJWTSSOSettings(t *testing.T) {
+	RegisterT(t)
+
+	user := &models.User{Tenant: &models.Tenant{}}
+
+	action := actions.UpdateTenantJWTSSOSettings{Model: &models.UpdateTenantJWTSSOSettings{IsEnabled: true}}
+	result := action.Validate(user, services)
+	ExpectFailed(result, "secret")
+
+	action = actions.UpdateTenantJWTSSOSettings{Model: &models.UpdateTenantJWTSSOSettings{Secret: "too-short"}}
+	result = action.Validate(user, services)
+	ExpectFailed(result, "secret")
+
+	action = actions.UpdateTenantJWTSSOSettings{Model: &models.UpdateTenantJWTSSOSettings{IsEnabled: true, PublicKey: "not a key"}}
+	result = action.Validate(user, services)
+	ExpectFailed(result, "publicKey")
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	action = actions.UpdateTenantJWTSSOSettings{Model: &models.UpdateTenantJWTSSOSettings{IsEnabled: true, PublicKey: publicKey}}
+	result = action.Validate(user, services)
+	ExpectSuccess(result)
+
+	//Secret is kept when not given
+	user.Tenant.JWTSSO.Secret = "a-very-long-secret-that-is-already-stored"
+	action = actions.UpdateTenantJWTSSOSettings{Model: &models.UpdateTenantJWTSSOSettings{IsEnabled: true}}
+	result = action.Validate(user, services)
+	ExpectSuccess(result)
+}
+
+func TestUpdateTenantAutoJoinSettings(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantAutoJoinSettings{Model: &models.UpdateTenantAutoJoinSettings{
+		Domains: []string{" @OurCompany.com", "ourcompany.com", "", "subsidiary.co.uk"},
+	}}
+	result := action.Validate(nil, services)
+	ExpectSuccess(result)
+	Expect(action.Model.Domains).Equals([]string{"ourcompany.com", "subsidiary.co.uk"})
+	Expect(action.Model.Role).Equals(models.RoleVisitor)
+
+	action = actions.UpdateTenantAutoJoinSettings{Model: &models.UpdateTenantAutoJoinSettings{
+		Domains: []string{"ourcompany"},
+		Role:    models.RoleAdministrator,
+	}}
+	result = action.Validate(nil, services)
+	ExpectFailed(result, "domains", "role")
+}
+
+func TestUpdateTenantEmbedSettings(t *testing.T) {
+	RegisterT(t)
+
+	action := actions.UpdateTenantEmbedSettings{Model: &models.UpdateTenantEmbedSettings{
+		Origins: []string{" https://App.OurCompany.com/", "https://app.ourcompany.com", "", "http://localhost:8080"},
+	}}
+	result := action.Validate(nil, services)
+	ExpectSuccess(result)
+	Expect(action.Model.Origins).Equals([]string{"https://app.ourcompany.com", "http://localhost:8080"})
+
+	for _, origin := range []string{"ourcompany.com", "javascript:alert(1)", "https://ourcompany.com/feedback", "https://*", "https://user@ourcompany.com"} {
+		action = actions.UpdateTenantEmbedSettings{Model: &models.UpdateTenantEmbedSettings{
+			Origins: []string{origin},
+		}}
+		result = action.Validate(nil, services)
+		ExpectFailed(result, "origins")
+	}
+}
+
+func TestUpdateTenantPrivacy_PlanWithoutPrivate(t *testing.T) {
+	RegisterT(t)
+
+	services.SetCurrentTenant(&models.Tenant{ID: 1, Plan: models.PlanFree})
+	defer services.SetCurrentTenant(nil)
+
+	action := actions.UpdateTenantPrivacy{Model: &models.UpdateTenantPrivacy{IsPrivate: true}}
+	ExpectFailed(action.Validate(nil, services), "")
+
+	action = actions.UpdateTenantPrivacy{Model: &models.UpdateTenantPrivacy{IsPrivate: false}}
+	ExpectSuccess(action.Validate(nil, services))
+}
+
+func TestUpdateTenantSettings_CustomDomainOnFreePlan(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{ID: 1, Plan: models.PlanFree}
+	services.SetCurrentTenant(tenant)
+	defer services.SetCurrentTenant(nil)
+
+	action := actions.UpdateTenantSettings{Model: &models.UpdateTenantSettings{Title: "Ok", CNAME: "feedback.got.com"}}
+	result := action.Validate(&models.User{ID: 1, Tenant: tenant}, services)
+	ExpectFailed(result, "cname")
+}
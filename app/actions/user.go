@@ -1,15 +1,19 @@
 package actions
 
 import (
+	"strings"
+
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/validate"
 )
 
 //ChangeUserRole is the input model change role of an user
 type ChangeUserRole struct {
-	Model *models.ChangeUserRole
+	Model      *models.ChangeUserRole
+	CustomRole *models.CustomRole
 }
 
 // Initialize the model
@@ -23,15 +27,35 @@ func (input *ChangeUserRole) IsAuthorized(user *models.User, services *app.Servi
 	if user == nil {
 		return false
 	}
-	return user.IsAdministrator() && user.ID != input.Model.UserID
+	return user.Can(models.PermissionManageMembers) && user.ID != input.Model.UserID
 }
 
 // Validate is current model is valid
 func (input *ChangeUserRole) Validate(user *models.User, services *app.Services) *validate.Result {
 	result := validate.Success()
-	if input.Model.Role < models.RoleVisitor || input.Model.Role > models.RoleAdministrator {
+
+	var permissions []models.Permission
+	if input.Model.CustomRoleID != 0 {
+		role, err := services.Tenants.GetCustomRoleByID(input.Model.CustomRoleID)
+		if err != nil {
+			if errors.Cause(err) != app.ErrNotFound {
+				return validate.Error(err)
+			}
+			result.AddFieldFailure("customRoleId", "Role not found")
+		} else {
+			input.CustomRole = role
+			permissions = role.Permissions
+		}
+	} else if input.Model.Role < models.RoleVisitor || input.Model.Role > models.RoleAdministrator {
 		result.AddFieldFailure("role", "Invalid role")
+	} else {
+		permissions = models.DefaultPermissions(input.Model.Role)
 	}
+
+	if !user.CanAll(permissions) {
+		result.AddFieldFailure("role", "You cannot grant permissions you don't have.")
+	}
+
 	target, err := services.Users.GetByID(input.Model.UserID)
 	if err != nil {
 		if errors.Cause(err) == app.ErrNotFound {
@@ -41,10 +65,110 @@ func (input *ChangeUserRole) Validate(user *models.User, services *app.Services)
 		}
 	} else if target.Tenant.ID != user.Tenant.ID {
 		result.AddFieldFailure("user_id", "User not found")
+	} else if !user.CanAll(target.Permissions()) {
+		result.AddFieldFailure("user_id", "You cannot change the role of a member with permissions you don't have.")
+	}
+	return result
+}
+
+//SaveCustomRole is the action used to create or edit a tenant-defined role
+type SaveCustomRole struct {
+	Model *models.SaveCustomRole
+}
+
+// Initialize the model
+func (input *SaveCustomRole) Initialize() interface{} {
+	input.Model = new(models.SaveCustomRole)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *SaveCustomRole) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageMembers)
+}
+
+// Validate is current model is valid
+func (input *SaveCustomRole) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+
+	if input.Model.ID != 0 {
+		existing, err := services.Tenants.GetCustomRoleByID(input.Model.ID)
+		if err != nil {
+			return validate.Error(err)
+		}
+		if !user.CanAll(existing.Permissions) {
+			result.AddFieldFailure("permissions", "You cannot change a role with permissions you don't have.")
+		}
+	}
+
+	input.Model.Name = strings.TrimSpace(input.Model.Name)
+	if input.Model.Name == "" {
+		result.AddFieldFailure("name", "Name is required.")
+	} else if len(input.Model.Name) > 50 {
+		result.AddFieldFailure("name", "Name must be less than 50 characters.")
+	} else {
+		roles, err := services.Tenants.ListCustomRoles()
+		if err != nil {
+			return validate.Error(err)
+		}
+		for _, role := range roles {
+			if role.ID != input.Model.ID && strings.EqualFold(role.Name, input.Model.Name) {
+				result.AddFieldFailure("name", "This name is already in use by another role.")
+			}
+		}
+	}
+
+	permissions := make([]models.Permission, 0)
+	for _, p := range input.Model.Permissions {
+		if !p.IsValid() {
+			result.AddFieldFailuref("permissions", "Unknown permission '{permission}'.", i18n.Params{"permission": p})
+		} else if !models.ContainsPermission(permissions, p) {
+			permissions = append(permissions, p)
+		}
+	}
+	input.Model.Permissions = permissions
+
+	if len(permissions) == 0 {
+		result.AddFieldFailure("permissions", "At least one permission is required.")
+	} else if !user.CanAll(permissions) {
+		result.AddFieldFailure("permissions", "You cannot grant permissions you don't have.")
 	}
+
 	return result
 }
 
+//DeleteCustomRole is the action used to delete a tenant-defined role
+type DeleteCustomRole struct {
+	Model *models.DeleteCustomRole
+	Role  *models.CustomRole
+}
+
+// Initialize the model
+func (input *DeleteCustomRole) Initialize() interface{} {
+	input.Model = new(models.DeleteCustomRole)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *DeleteCustomRole) IsAuthorized(user *models.User, services *app.Services) bool {
+	return user != nil && user.Can(models.PermissionManageMembers)
+}
+
+// Validate is current model is valid
+func (input *DeleteCustomRole) Validate(user *models.User, services *app.Services) *validate.Result {
+	role, err := services.Tenants.GetCustomRoleByID(input.Model.ID)
+	if err != nil {
+		return validate.Error(err)
+	}
+
+	if !user.CanAll(role.Permissions) {
+		return validate.Failed([]string{"You cannot delete a role with permissions you don't have."})
+	}
+
+	input.Role = role
+	return validate.Success()
+}
+
 //ChangeUserEmail is the action used to change current user's email
 type ChangeUserEmail struct {
 	Model *models.ChangeUserEmail
@@ -83,7 +207,7 @@ func (input *ChangeUserEmail) Validate(user *models.User, services *app.Services
 
 	emailResult := validate.Email(input.Model.Email)
 	if !emailResult.Ok {
-		result.AddFieldFailure("email", emailResult.Messages...)
+		result.AddFieldMessages("email", emailResult.Messages...)
 		return result
 	}
 
@@ -98,3 +222,31 @@ func (input *ChangeUserEmail) Validate(user *models.User, services *app.Services
 	input.Model.Requestor = user
 	return result
 }
+
+//TwoFactorCode is the action used to confirm a code from an authenticator app or a recovery code
+type TwoFactorCode struct {
+	Model *models.TwoFactorCode
+}
+
+// Initialize the model
+func (input *TwoFactorCode) Initialize() interface{} {
+	input.Model = new(models.TwoFactorCode)
+	return input.Model
+}
+
+// IsAuthorized returns true if current user is authorized to perform this action
+func (input *TwoFactorCode) IsAuthorized(user *models.User, services *app.Services) bool {
+	return true
+}
+
+// Validate is current model is valid
+func (input *TwoFactorCode) Validate(user *models.User, services *app.Services) *validate.Result {
+	result := validate.Success()
+	input.Model.Code = strings.TrimSpace(input.Model.Code)
+	if input.Model.Code == "" {
+		result.AddFieldFailure("code", "Code is required.")
+	} else if len(input.Model.Code) > 20 {
+		result.AddFieldFailure("code", "Code must be less than 20 characters.")
+	}
+	return result
+}
@@ -85,3 +85,31 @@ func TestChangeUserRole_InvalidUser_Tenant(t *testing.T) {
 	result := action.Validate(currentUser, services)
 	ExpectFailed(result, "user_id")
 }
+
+func TestSaveCustomRole_CannotGrantMissingPermissions(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{ID: 1}
+	services.SetCurrentTenant(tenant)
+
+	currentUser := &models.User{
+		Tenant: tenant,
+		Role:   models.RoleCollaborator,
+		CustomRole: &models.CustomRole{
+			Permissions: []models.Permission{models.PermissionManageMembers, models.PermissionRespond},
+		},
+	}
+
+	action := actions.SaveCustomRole{Model: &models.SaveCustomRole{
+		Name:        "Support",
+		Permissions: []models.Permission{models.PermissionRespond},
+	}}
+	Expect(action.IsAuthorized(currentUser, services)).IsTrue()
+	ExpectSuccess(action.Validate(currentUser, services))
+
+	action = actions.SaveCustomRole{Model: &models.SaveCustomRole{
+		Name:        "Settings",
+		Permissions: []models.Permission{models.PermissionManageSettings},
+	}}
+	ExpectFailed(action.Validate(currentUser, services), "permissions")
+}
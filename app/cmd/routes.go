@@ -6,7 +6,6 @@ import (
 	"github.com/getfider/fider/app/handlers"
 	"github.com/getfider/fider/app/middlewares"
 	"github.com/getfider/fider/app/models"
-	"github.com/getfider/fider/app/pkg/oauth"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -28,17 +27,33 @@ func routes(r *web.Engine) *web.Engine {
 	noTenant := r.Group()
 	{
 		noTenant.Get("/-/health", handlers.Health())
+		noTenant.Get("/.well-known/jwks.json", handlers.JWKS())
+		noTenant.Post("/api/webhooks/mailgun", handlers.MailgunWebhook())
 
+		noTenant.Use(middlewares.CSRF())
 		noTenant.Post("/api/tenants", handlers.CreateTenant())
 		noTenant.Get("/api/tenants/:subdomain/availability", handlers.CheckAvailability())
 		noTenant.Get("/signup", handlers.SignUp())
 
-		noTenant.Get("/oauth/facebook", handlers.SignInByOAuth(oauth.FacebookProvider))
-		noTenant.Get("/oauth/facebook/callback", handlers.OAuthCallback(oauth.FacebookProvider))
-		noTenant.Get("/oauth/google", handlers.SignInByOAuth(oauth.GoogleProvider))
-		noTenant.Get("/oauth/google/callback", handlers.OAuthCallback(oauth.GoogleProvider))
-		noTenant.Get("/oauth/github", handlers.SignInByOAuth(oauth.GitHubProvider))
-		noTenant.Get("/oauth/github/callback", handlers.OAuthCallback(oauth.GitHubProvider))
+		noTenant.Get("/oauth/:provider", handlers.SignInByOAuth())
+		noTenant.Get("/oauth/:provider/callback", handlers.OAuthCallback())
+
+		operator := noTenant.Group()
+		{
+			operator.Use(middlewares.OperatorConsole())
+			operator.Get("/operator/signin", handlers.OperatorSignInPage())
+			operator.Post("/api/operator/signin", handlers.OperatorSignIn())
+
+			operator.Use(middlewares.IsOperator())
+			operator.Get("/operator", handlers.OperatorConsole())
+			operator.Get("/operator/signout", handlers.OperatorSignOut())
+			operator.Get("/api/operator/tenants", handlers.SearchTenants())
+			operator.Post("/api/operator/tenants/:id/suspend", handlers.SuspendTenant())
+			operator.Post("/api/operator/tenants/:id/reactivate", handlers.ReactivateTenant())
+			operator.Post("/api/operator/tenants/:id/subdomain", handlers.ChangeTenantSubdomain())
+			operator.Post("/api/operator/tenants/:id/plan", handlers.ChangeTenantPlan())
+			operator.Post("/api/operator/tenants/:id/impersonate", handlers.ImpersonateTenantUser())
+		}
 	}
 
 	r.Use(middlewares.Tenant())
@@ -48,27 +63,49 @@ func routes(r *web.Engine) *web.Engine {
 		avatar.Use(middlewares.ClientCache(72 * time.Hour))
 		avatar.Get("/avatars/:size/:id/:name", handlers.Avatar())
 		avatar.Get("/logo/:size/:id", handlers.Logo())
+		avatar.Get("/favicon/:size/:id", handlers.Favicon())
+		avatar.Get("/header/:id", handlers.HeaderImage())
 	}
 
 	open := r.Group()
 	{
 		open.Get("/signup/verify", handlers.VerifySignUpKey())
 		open.Use(middlewares.OnlyActiveTenants())
+		open.Post("/sso/saml/acs", handlers.SAMLAssertionConsumer())
+
+		open.Use(middlewares.CSRF())
 		open.Get("/signin", handlers.SignInPage())
 		open.Get("/not-invited", handlers.NotInvitedPage())
+		open.Get("/limit-reached", handlers.LimitReachedPage())
 		open.Get("/signin/verify", handlers.VerifySignInKey(models.EmailVerificationKindSignIn))
 		open.Get("/invite/verify", handlers.VerifySignInKey(models.EmailVerificationKindUserInvitation))
 		open.Post("/api/signin/complete", handlers.CompleteSignInProfile())
 		open.Post("/api/signin", handlers.SignInByEmail())
+		open.Get("/signin/2fa", handlers.TwoFactorPage())
+		open.Post("/api/signin/2fa", handlers.VerifyTwoFactor())
+		open.Get("/sso", handlers.SignInByJWT())
+		open.Get("/sso/saml", handlers.SignInBySAML())
+		open.Get("/sso/saml/metadata", handlers.SAMLMetadata())
 	}
 
 	r.Use(middlewares.JwtGetter())
 	r.Use(middlewares.JwtSetter())
 
+	widget := r.Group()
+	{
+		widget.Use(middlewares.OnlyActiveTenants())
+		widget.Use(middlewares.CSRF())
+		widget.Get("/widget", handlers.Widget())
+		widget.Get("/widget/ideas/:number", handlers.WidgetIdea())
+		widget.Get("/widget/signin", handlers.WidgetSignIn())
+		widget.Post("/widget/signin", handlers.WidgetToken())
+	}
+
 	page := r.Group()
 	{
 		page.Use(middlewares.OnlyActiveTenants())
 		page.Use(middlewares.CheckTenantPrivacy())
+		page.Use(middlewares.CSRF())
 
 		public := page.Group()
 		{
@@ -76,6 +113,8 @@ func routes(r *web.Engine) *web.Engine {
 			public.Get("/api/ideas/search", handlers.SearchIdeas())
 			public.Get("/ideas/:number", handlers.IdeaDetails())
 			public.Get("/ideas/:number/*all", handlers.IdeaDetails())
+			public.Get("/pages/:slug", handlers.ShowPage())
+			public.Get("/oembed", handlers.OEmbed())
 			public.Get("/signout", handlers.SignOut())
 		}
 
@@ -92,6 +131,7 @@ func routes(r *web.Engine) *web.Engine {
 			private.Post("/api/ideas/:number/comments", handlers.PostComment())
 			private.Post("/api/ideas/:number/comments/:id", handlers.UpdateComment())
 			private.Post("/api/ideas/:number/status", handlers.SetResponse())
+			private.Post("/api/ideas/:number/privacy", handlers.SetIdeaPrivacy())
 			private.Post("/api/ideas/:number/support", handlers.AddSupporter())
 			private.Post("/api/ideas/:number/unsupport", handlers.RemoveSupporter())
 			private.Post("/api/ideas/:number/subscribe", handlers.Subscribe())
@@ -100,30 +140,109 @@ func routes(r *web.Engine) *web.Engine {
 			private.Delete("/api/ideas/:number/tags/:slug", handlers.UnassignTag())
 			private.Post("/api/user/settings", handlers.UpdateUserSettings())
 			private.Post("/api/user/change-email", handlers.ChangeUserEmail())
+			private.Post("/api/user/sessions/revoke-all", handlers.SignOutEverywhere())
+			private.Delete("/api/user/sessions/:id", handlers.RevokeSession())
+			private.Post("/api/user/2fa/setup", handlers.SetupTwoFactor())
+			private.Post("/api/user/2fa/enable", handlers.EnableTwoFactor())
+			private.Post("/api/user/2fa/disable", handlers.DisableTwoFactor())
+			private.Post("/api/user/2fa/recovery-codes", handlers.RegenerateRecoveryCodes())
 			private.Post("/api/notifications/read-all", handlers.ReadAllNotifications())
 			private.Get("/api/notifications/unread/total", handlers.TotalUnreadNotifications())
 
-			private.Use(middlewares.IsAuthorized(models.RoleCollaborator, models.RoleAdministrator))
+			private.Use(middlewares.IsAuthorized(models.AllPermissions...))
 
 			private.Get("/admin", handlers.GeneralSettingsPage())
-			private.Get("/admin/privacy", handlers.Page("Privacy · Site Settings", ""))
-			private.Get("/admin/invitations", handlers.Page("Invitations · Site Settings", ""))
+			private.Get("/admin/privacy", handlers.PrivacySettingsPage())
+			private.Get("/admin/widget", handlers.WidgetSettingsPage())
+			private.Get("/admin/theme", handlers.Page("Theme · Site Settings", ""))
 			private.Get("/admin/members", handlers.ManageMembers())
 			private.Get("/admin/tags", handlers.ManageTags())
-			private.Post("/api/admin/invitations/send", handlers.SendInvites())
-			private.Post("/api/admin/invitations/sample", handlers.SendSampleInvite())
-
-			private.Use(middlewares.IsAuthorized(models.RoleAdministrator))
-
-			private.Get("/admin/export", handlers.Page("Export · Site Settings", ""))
-			private.Get("/admin/export/ideas.csv", handlers.ExportIdeasToCSV())
-			private.Delete("/api/ideas/:number", handlers.DeleteIdea())
-			private.Post("/api/admin/settings/general", handlers.UpdateSettings())
-			private.Post("/api/admin/settings/privacy", handlers.UpdatePrivacy())
-			private.Delete("/api/admin/tags/:slug", handlers.DeleteTag())
-			private.Post("/api/admin/tags/:slug", handlers.CreateEditTag())
-			private.Post("/api/admin/tags", handlers.CreateEditTag())
-			private.Post("/api/admin/users/:user_id/role", handlers.ChangeUserRole())
+
+			invite := private.Group()
+			{
+				invite.Use(middlewares.IsAuthorized(models.PermissionInvite))
+				invite.Get("/admin/invitations", handlers.InvitationsPage())
+				invite.Post("/api/admin/invitations/send", handlers.SendInvites())
+				invite.Post("/api/admin/invitations/sample", handlers.SendSampleInvite())
+				invite.Get("/api/admin/invitations", handlers.ListInvitations())
+				invite.Post("/api/admin/invitations/import", handlers.ImportInvitations())
+				invite.Post("/api/admin/invitations/:id/resend", handlers.ResendInvitation())
+				invite.Delete("/api/admin/invitations/:id", handlers.RevokeInvitation())
+			}
+
+			export := private.Group()
+			{
+				export.Use(middlewares.IsAuthorized(models.PermissionExport))
+				export.Get("/admin/export", handlers.Page("Export · Site Settings", ""))
+				export.Get("/admin/export/ideas.csv", handlers.ExportIdeasToCSV())
+			}
+
+			ideas := private.Group()
+			{
+				ideas.Use(middlewares.IsAuthorized(models.PermissionDeleteIdeas))
+				ideas.Delete("/api/ideas/:number", handlers.DeleteIdea())
+			}
+
+			comments := private.Group()
+			{
+				comments.Use(middlewares.IsAuthorized(models.PermissionModerateComments))
+				comments.Delete("/api/ideas/:number/comments/:id", handlers.DeleteComment())
+			}
+
+			tags := private.Group()
+			{
+				tags.Use(middlewares.IsAuthorized(models.PermissionManageTags))
+				tags.Delete("/api/admin/tags/:slug", handlers.DeleteTag())
+				tags.Post("/api/admin/tags/:slug", handlers.CreateEditTag())
+				tags.Post("/api/admin/tags", handlers.CreateEditTag())
+			}
+
+			members := private.Group()
+			{
+				members.Use(middlewares.IsAuthorized(models.PermissionManageMembers))
+				members.Get("/admin/roles", handlers.ManageRoles())
+				members.Post("/api/admin/roles", handlers.SaveCustomRole())
+				members.Post("/api/admin/roles/:id", handlers.SaveCustomRole())
+				members.Delete("/api/admin/roles/:id", handlers.DeleteCustomRole())
+				members.Post("/api/admin/users/:user_id/role", handlers.ChangeUserRole())
+				members.Delete("/api/admin/users/:user_id/sessions", handlers.RevokeUserSessions())
+			}
+
+			settings := private.Group()
+			{
+				settings.Use(middlewares.IsAuthorized(models.PermissionManageSettings))
+				settings.Get("/admin/email-log", handlers.EmailLogPage())
+				settings.Get("/admin/email", handlers.EmailSettingsPage())
+				settings.Get("/admin/email/verify", handlers.VerifySenderAddressKey())
+				settings.Get("/admin/authentication", handlers.ManageAuthentication())
+				settings.Get("/admin/sso", handlers.SSOSettingsPage())
+				settings.Get("/admin/pages", handlers.ManagePages())
+				settings.Post("/api/admin/settings/general", handlers.UpdateSettings())
+				settings.Post("/api/admin/settings/privacy", handlers.UpdatePrivacy())
+				settings.Post("/api/admin/settings/theme", handlers.UpdateTheme())
+				settings.Post("/api/admin/settings/auto-join", handlers.UpdateAutoJoinSettings())
+				settings.Post("/api/admin/settings/embed", handlers.UpdateEmbedSettings())
+				settings.Post("/api/admin/settings/email", handlers.UpdateEmailSettings())
+				settings.Post("/api/admin/settings/email/verify", handlers.SendSenderVerification())
+				settings.Post("/api/admin/settings/email/check-dns", handlers.CheckSenderDomain())
+				settings.Post("/api/admin/oauth", handlers.SaveOAuthConfig())
+				settings.Post("/api/admin/settings/sso", handlers.UpdateSSOSettings())
+				settings.Post("/api/admin/settings/sso/jwt", handlers.UpdateJWTSSOSettings())
+				settings.Post("/api/admin/settings/two-factor", handlers.UpdateTwoFactorSettings())
+				settings.Post("/api/admin/settings/deletion", handlers.ScheduleTenantDeletion())
+				settings.Delete("/api/admin/settings/deletion", handlers.CancelTenantDeletion())
+				settings.Get("/api/admin/settings/aliases", handlers.ListTenantAliases())
+				settings.Delete("/api/admin/settings/aliases/:id", handlers.RemoveTenantAlias())
+				settings.Get("/api/admin/usage", handlers.GetTenantUsage())
+				settings.Get("/api/admin/email-templates", handlers.ListEmailTemplates())
+				settings.Post("/api/admin/email-templates/:name", handlers.SaveEmailTemplate())
+				settings.Post("/api/admin/email-templates/:name/preview", handlers.PreviewEmailTemplate())
+				settings.Delete("/api/admin/email-templates/:name", handlers.DeleteEmailTemplate())
+				settings.Delete("/api/admin/email-suppressions", handlers.RemoveEmailSuppression())
+				settings.Post("/api/admin/pages", handlers.SavePage())
+				settings.Post("/api/admin/pages/:slug", handlers.SavePage())
+				settings.Delete("/api/admin/pages/:slug", handlers.DeletePage())
+			}
 		}
 	}
 
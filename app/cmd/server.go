@@ -7,11 +7,14 @@ import (
 	"os/signal"
 	"runtime/pprof"
 	"syscall"
+	"time"
 
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/pkg/worker"
+	"github.com/getfider/fider/app/tasks"
 )
 
 //RunServer starts the Fider Server
@@ -21,11 +24,20 @@ func RunServer(settings *models.SystemSettings) int {
 	fmt.Printf("GO_ENV: %s\n", env.Current())
 
 	e := routes(web.New(settings))
+	autoSSL(e)
 
 	go e.Start(":" + env.GetEnvOrDefault("PORT", "3000"))
+	go schedule(e.Worker(), time.Hour, tasks.PurgeDeletedTenants)
 	return listenSignals(e, settings)
 }
 
+//schedule enqueues a new instance of given task every interval
+func schedule(w worker.Worker, interval time.Duration, task func() worker.Task) {
+	for range time.Tick(interval) {
+		w.Enqueue(task())
+	}
+}
+
 func listenSignals(e *web.Engine, settings *models.SystemSettings) int {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGUSR1)
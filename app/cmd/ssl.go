@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"net/url"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/storage/postgres"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+//autoSSL shares certificates across all instances and only issues them for the hostname of AUTH_ENDPOINT
+//on single tenant instances or for the hostnames of registered tenants on multi tenant instances
+func autoSSL(e *web.Engine) {
+	if env.GetEnvOrDefault("SSL_AUTO", "") != "true" {
+		return
+	}
+
+	db := dbx.NewWithLogger(e.Logger())
+	if env.IsSingleHostMode() {
+		//The single tenant is served on any hostname, so the one of its public address has to be configured
+		endpoint, err := url.Parse(env.MustGet("AUTH_ENDPOINT"))
+		if err != nil || endpoint.Hostname() == "" {
+			panic(errors.New("AUTH_ENDPOINT must be the public address of this instance to use SSL_AUTO"))
+		}
+		e.UseAutoSSL(postgres.NewCertificateCache(db), autocert.HostWhitelist(endpoint.Hostname()))
+		return
+	}
+
+	e.UseAutoSSL(postgres.NewCertificateCache(db), web.NewHostPolicy(func(host string) (bool, error) {
+		trx, err := db.Begin()
+		if err != nil {
+			return false, err
+		}
+		defer trx.Rollback()
+
+		//Aliases are included so that old https links can still be redirected
+		tenants := postgres.NewTenantStorage(trx)
+		_, err = tenants.GetByDomain(host)
+		if errors.Cause(err) == app.ErrNotFound {
+			_, err = tenants.GetByAlias(host)
+		}
+
+		if errors.Cause(err) == app.ErrNotFound {
+			return false, nil
+		}
+		return err == nil, err
+	}))
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"time"
+
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/tasks"
 )
 
 // GeneralSettingsPage is the general settings page
@@ -14,15 +20,116 @@ func GeneralSettingsPage() web.HandlerFunc {
 			c.Logger().Error(err)
 		}
 
+		aliases, err := c.Services().Tenants.ListAliases()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		var certificate *models.CertificateInfo
+		if c.Tenant().CNAME != "" && env.GetEnvOrDefault("SSL_AUTO", "") == "true" {
+			certificate, err = c.Services().Tenants.GetCertificateInfo(c.Tenant().CNAME)
+			if err != nil && errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+		}
+
 		return c.Page(web.Props{
-			Title: "General · Site Settings",
+			TitleKey: "General · Site Settings",
 			Data: web.Map{
-				"publicIP": publicIP,
+				"publicIP":            publicIP,
+				"deletionScheduledOn": c.Tenant().DeletionScheduledOn,
+				"aliases":             aliases,
+				"isAutoSSL":           env.GetEnvOrDefault("SSL_AUTO", "") == "true",
+				"certificate":         certificate,
 			},
 		})
 	}
 }
 
+// ListTenantAliases returns all former hostnames that redirect to current tenant
+func ListTenantAliases() web.HandlerFunc {
+	return func(c web.Context) error {
+		aliases, err := c.Services().Tenants.ListAliases()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(aliases)
+	}
+}
+
+// GetTenantUsage returns the consumption of current tenant against the limits of its plan
+func GetTenantUsage() web.HandlerFunc {
+	return func(c web.Context) error {
+		usage, err := c.Services().Tenants.GetUsage()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(usage)
+	}
+}
+
+// RemoveTenantAlias stops redirecting a former hostname to current tenant
+func RemoveTenantAlias() web.HandlerFunc {
+	return func(c web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		err = c.Services().Tenants.RemoveAlias(id)
+		if err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.NotFound()
+			}
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ScheduleTenantDeletion schedules current tenant to be purged when the grace period ends
+func ScheduleTenantDeletion() web.HandlerFunc {
+	return func(c web.Context) error {
+		if !c.User().IsAdministrator() {
+			return c.Unauthorized()
+		}
+
+		tenant := c.Tenant()
+		if !tenant.IsDeletionScheduled() {
+			on := time.Now().Add(models.TenantDeletionGracePeriod)
+			if err := c.Services().Tenants.ScheduleDeletion(tenant.ID, on); err != nil {
+				return c.Failure(err)
+			}
+
+			tenant.DeletionScheduledOn = &on
+			c.Enqueue(tasks.SendTenantDeletionScheduled())
+		}
+
+		return c.Ok(web.Map{
+			"deletionScheduledOn": tenant.DeletionScheduledOn,
+		})
+	}
+}
+
+// CancelTenantDeletion keeps current tenant from being purged
+func CancelTenantDeletion() web.HandlerFunc {
+	return func(c web.Context) error {
+		if !c.User().IsAdministrator() {
+			return c.Unauthorized()
+		}
+
+		if err := c.Services().Tenants.CancelDeletion(c.Tenant().ID); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Tenant().DeletionScheduledOn = nil
+		return c.Ok(web.Map{})
+	}
+}
+
 // UpdateSettings update current tenant' settings
 func UpdateSettings() web.HandlerFunc {
 	return func(c web.Context) error {
@@ -40,6 +147,23 @@ func UpdateSettings() web.HandlerFunc {
 	}
 }
 
+// UpdateTheme update current tenant's colors, images and custom stylesheet
+func UpdateTheme() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantTheme)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateTheme(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
 // UpdatePrivacy update current tenant's privacy settings
 func UpdatePrivacy() web.HandlerFunc {
 	return func(c web.Context) error {
@@ -57,6 +181,66 @@ func UpdatePrivacy() web.HandlerFunc {
 	}
 }
 
+// PrivacySettingsPage is the page used to configure who can access current tenant
+func PrivacySettingsPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Page(web.Props{
+			TitleKey: "Privacy · Site Settings",
+			Data: web.Map{
+				"autoJoin": c.Tenant().AutoJoin,
+			},
+		})
+	}
+}
+
+// UpdateAutoJoinSettings updates which email domains can join current tenant without an invitation
+func UpdateAutoJoinSettings() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantAutoJoinSettings)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateAutoJoinSettings(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(c.Tenant().AutoJoin)
+	}
+}
+
+// WidgetSettingsPage is the page used to configure which websites can embed the feedback widget
+func WidgetSettingsPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Page(web.Props{
+			TitleKey: "Widget · Site Settings",
+			Data: web.Map{
+				"origins": c.Tenant().EmbedOrigins,
+			},
+		})
+	}
+}
+
+// UpdateEmbedSettings updates which websites can embed the feedback widget of current tenant
+func UpdateEmbedSettings() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantEmbedSettings)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateEmbedSettings(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"origins": c.Tenant().EmbedOrigins,
+		})
+	}
+}
+
 // ManageMembers is the page used by administrators to change member's role
 func ManageMembers() web.HandlerFunc {
 	return func(c web.Context) error {
@@ -65,11 +249,127 @@ func ManageMembers() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		roles, err := c.Services().Tenants.ListCustomRoles()
+		if err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Page(web.Props{
-			Title: "Manage Members · Site Settings",
+			TitleKey: "Manage Members · Site Settings",
 			Data: web.Map{
 				"users": users,
+				"roles": roles,
+			},
+		})
+	}
+}
+
+// ManageRoles is the page used by administrators to define custom roles
+func ManageRoles() web.HandlerFunc {
+	return func(c web.Context) error {
+		roles, err := c.Services().Tenants.ListCustomRoles()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Roles · Site Settings",
+			Data: web.Map{
+				"roles":       roles,
+				"permissions": models.AllPermissions,
+			},
+		})
+	}
+}
+
+// SaveCustomRole creates or updates a tenant-defined role
+func SaveCustomRole() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.SaveCustomRole)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		role, err := c.Services().Tenants.SaveCustomRole(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(role)
+	}
+}
+
+// DeleteCustomRole removes a tenant-defined role and turns its members back into visitors
+func DeleteCustomRole() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.DeleteCustomRole)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		users, err := c.Services().Users.GetAll()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		for _, user := range users {
+			if user.CustomRole != nil && user.CustomRole.ID == input.Role.ID {
+				if err := c.Services().Users.ChangeRole(user.ID, models.RoleVisitor); err != nil {
+					return c.Failure(err)
+				}
+				//Members lose their staff powers, so sessions opened as staff must not outlive the role
+				if err := c.Services().Sessions.RevokeAll(user.ID); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		err = c.Services().Tenants.DeleteCustomRole(input.Role.ID)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ManageAuthentication is the page used by administrators to manage custom OAuth providers
+func ManageAuthentication() web.HandlerFunc {
+	return func(c web.Context) error {
+		providers, err := c.Services().Tenants.ListOAuthConfig()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Authentication · Site Settings",
+			Data: web.Map{
+				"providers":           providers,
+				"callbackURL":         c.AuthEndpoint() + "/oauth/{provider}/callback",
+				"isTwoFactorRequired": c.Tenant().IsTwoFactorRequired,
 			},
 		})
 	}
 }
+
+// SaveOAuthConfig registers a new custom OAuth provider or changes an existing one
+func SaveOAuthConfig() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.CreateEditOAuthConfig)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.SaveOAuthConfig(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		config, err := c.Services().Tenants.GetOAuthConfigByProvider(input.Model.Provider)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(config)
+	}
+}
@@ -3,8 +3,12 @@ package handlers_test
 import (
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
 	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/mock"
 
 	"github.com/getfider/fider/app/handlers"
@@ -46,6 +50,39 @@ func TestUpdatePrivacyHandler(t *testing.T) {
 	Expect(tenant.IsPrivate).IsTrue()
 }
 
+func TestUpdateAutoJoinSettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateAutoJoinSettings(),
+			`{ "domains": ["@GoT.com"], "role": 2 }`,
+		)
+
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(code).Equals(http.StatusOK)
+	Expect(tenant.AutoJoin.Domains).Equals([]string{"got.com"})
+	Expect(tenant.AutoJoin.Role).Equals(models.RoleCollaborator)
+}
+
+func TestUpdateAutoJoinSettingsHandler_Collaborator(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(
+			handlers.UpdateAutoJoinSettings(),
+			`{ "domains": ["got.com"] }`,
+		)
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
 func TestManageMembersHandler(t *testing.T) {
 	RegisterT(t)
 
@@ -58,3 +95,307 @@ func TestManageMembersHandler(t *testing.T) {
 
 	Expect(code).Equals(http.StatusOK)
 }
+
+func TestSaveOAuthConfigHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(
+			handlers.SaveOAuthConfig(),
+			`{
+				"displayName": "GitLab",
+				"status": 2,
+				"clientId": "123",
+				"clientSecret": "s3cr3t",
+				"authorizeUrl": "https://gitlab.com/oauth/authorize",
+				"tokenUrl": "https://gitlab.com/oauth/token",
+				"profileUrl": "https://gitlab.com/api/v4/user",
+				"scope": "read_user",
+				"jsonUserIdPath": "id",
+				"jsonUserNamePath": "name",
+				"jsonUserEmailPath": "email"
+			}`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	provider := query.String("provider")
+	Expect(provider).ContainsSubstring("_")
+	Expect(query.Contains("clientSecret")).IsFalse()
+
+	services.SetCurrentTenant(mock.DemoTenant)
+	config, err := services.Tenants.GetOAuthConfigByProvider(provider)
+	Expect(err).IsNil()
+	Expect(config.DisplayName).Equals("GitLab")
+	Expect(config.ClientSecret).Equals("s3cr3t")
+	Expect(config.IsEnabled()).IsTrue()
+}
+
+func TestSaveCustomRoleHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.SaveCustomRole(),
+			`{ "name": " Moderator ", "permissions": ["moderate_comments", "delete_ideas", "moderate_comments"] }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	services.SetCurrentTenant(mock.DemoTenant)
+	roles, _ := services.Tenants.ListCustomRoles()
+	Expect(roles).HasLen(1)
+	Expect(roles[0].Name).Equals("Moderator")
+	Expect(roles[0].Permissions).Equals([]models.Permission{models.PermissionModerateComments, models.PermissionDeleteIdeas})
+}
+
+func TestSaveCustomRoleHandler_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.SaveCustomRole(), `{ "name": "Moderator", "permissions": ["fly"] }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestDeleteCustomRoleHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	support, _ := services.Tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Support",
+		Permissions: []models.Permission{models.PermissionRespond},
+	})
+	services.Users.AssignCustomRole(mock.AryaStark.ID, support)
+	session, _ := services.Sessions.Create(mock.AryaStark, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", support.ID).
+		Execute(handlers.DeleteCustomRole())
+
+	Expect(code).Equals(http.StatusOK)
+	roles, _ := services.Tenants.ListCustomRoles()
+	Expect(roles).HasLen(0)
+	user, _ := services.Users.GetByID(mock.AryaStark.ID)
+	Expect(user.Role).Equals(models.RoleVisitor)
+	Expect(user.CustomRole).IsNil()
+	_, err := services.Sessions.GetByID(session.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestScheduleTenantDeletionHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.ScheduleTenantDeletion(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(tenant.IsDeletionScheduled()).IsTrue()
+	Expect(*tenant.DeletionScheduledOn).TemporarilySimilar(time.Now().Add(models.TenantDeletionGracePeriod), 5*time.Second)
+
+	server, _ = mock.NewServer()
+	code, _ = server.
+		OnTenant(tenant).
+		AsUser(mock.JonSnow).
+		Execute(handlers.CancelTenantDeletion())
+
+	Expect(code).Equals(http.StatusOK)
+	tenant, _ = services.Tenants.GetByDomain("demo")
+	Expect(tenant.IsDeletionScheduled()).IsFalse()
+}
+
+func TestScheduleTenantDeletionHandler_NonAdministrator(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.AryaStark.Role = models.RoleCollaborator
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.ScheduleTenantDeletion(), `{}`)
+
+	Expect(code).Equals(http.StatusForbidden)
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(tenant.IsDeletionScheduled()).IsFalse()
+}
+
+func TestUpdateSettingsHandler_ChangeSubdomain(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateSettings(),
+			`{ "title": "GoT", "subdomain": "westeros", "cname": "" }`,
+		)
+	Expect(code).Equals(http.StatusOK)
+
+	tenant, _ := services.Tenants.GetByDomain("westeros")
+	Expect(tenant.ID).Equals(mock.DemoTenant.ID)
+
+	aliases, _ := services.Tenants.ListAliases()
+	Expect(aliases).HasLen(1)
+	Expect(aliases[0].Hostname).Equals("demo")
+	Expect(aliases[0].IsCNAME).IsFalse()
+}
+
+func TestUpdateSettingsHandler_SubdomainAlreadyInUse(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateSettings(),
+			`{ "title": "GoT", "subdomain": "avengers", "cname": "" }`,
+		)
+	Expect(code).Equals(http.StatusBadRequest)
+
+	aliases, _ := services.Tenants.ListAliases()
+	Expect(aliases).HasLen(0)
+}
+
+func TestRemoveTenantAliasHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SetCurrentTenant(mock.AvengersTenant)
+	services.Tenants.UpdateSettings(&models.UpdateTenantSettings{Title: "Avengers", CNAME: "feedback.avengers.com"})
+	aliases, _ := services.Tenants.ListAliases()
+	Expect(aliases).HasLen(1)
+	Expect(aliases[0].Hostname).Equals("ideas.theavengers.com")
+
+	code, _ := server.
+		OnTenant(mock.AvengersTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", aliases[0].ID).
+		Execute(handlers.RemoveTenantAlias())
+	Expect(code).Equals(http.StatusOK)
+
+	aliases, _ = services.Tenants.ListAliases()
+	Expect(aliases).HasLen(0)
+
+	//Removed aliases are reserved during the cool-down period
+	services.Tenants.SetCurrentTenant(mock.DemoTenant)
+	available, _ := services.Tenants.IsCNAMEAvailable("ideas.theavengers.com")
+	Expect(available).IsFalse()
+}
+
+func TestRemoveTenantAliasHandler_OtherTenant(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SetCurrentTenant(mock.AvengersTenant)
+	services.Tenants.UpdateSettings(&models.UpdateTenantSettings{Title: "Avengers", CNAME: "feedback.avengers.com"})
+	aliases, _ := services.Tenants.ListAliases()
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", aliases[0].ID).
+		Execute(handlers.RemoveTenantAlias())
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestUpdatePrivacyHandler_PlanWithoutPrivate(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.ChangePlan(mock.DemoTenant.ID, models.PlanFree.Name)
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdatePrivacy(),
+			`{ "isPrivate": true }`,
+		)
+
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(tenant.IsPrivate).IsFalse()
+}
+
+func TestGetTenantUsageHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.ChangePlan(mock.DemoTenant.ID, models.PlanStandard.Name)
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecuteAsJSON(handlers.GetTenantUsage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.String("plan.name")).Equals("standard")
+	Expect(response.Int32("plan.maxUsers")).Equals(1000)
+}
+
+func TestUpdateThemeHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateTheme(),
+			`{ "primaryColor": "ff6600", "accentColor": "333333", "customCSS": "@import url(http://evil.com/x.css); .header { color: red; }" }`,
+		)
+
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(code).Equals(http.StatusOK)
+	Expect(tenant.Theme.PrimaryColor).Equals("FF6600")
+	Expect(tenant.Theme.AccentColor).Equals("333333")
+	Expect(tenant.Theme.CustomCSS).Equals(".header { color: red; }")
+}
+
+func TestUpdateThemeHandler_InvalidColor(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateTheme(),
+			`{ "primaryColor": "red" }`,
+		)
+
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(tenant.Theme.PrimaryColor).Equals("")
+}
+
+func TestUpdateEmbedSettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateEmbedSettings(),
+			`{ "origins": ["https://www.GoT.com/", "https://www.got.com", "http://localhost:3000"] }`,
+		)
+
+	tenant, _ := services.Tenants.GetByDomain("demo")
+	Expect(code).Equals(http.StatusOK)
+	Expect(tenant.EmbedOrigins).Equals([]string{"https://www.got.com", "http://localhost:3000"})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
@@ -16,16 +17,34 @@ func Health() web.HandlerFunc {
 	}
 }
 
-//Page returns a page without properties
-func Page(title, description string) web.HandlerFunc {
+//JWKS returns the public keys used to sign tokens, so that other services can verify them
+func JWKS() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Ok(web.Map{
+			"keys": jwt.PublicKeys(),
+		})
+	}
+}
+
+//Page returns a page without properties, titled by given message keys
+func Page(titleKey, descriptionKey string) web.HandlerFunc {
 	return func(c web.Context) error {
 		return c.Page(web.Props{
-			Title:       title,
-			Description: description,
+			TitleKey:       titleKey,
+			DescriptionKey: descriptionKey,
 		})
 	}
 }
 
+//canAddUser returns true if the plan of current tenant has room for one more user
+func canAddUser(c web.Context) (bool, error) {
+	usage, err := c.Services().Tenants.GetUsage()
+	if err != nil {
+		return false, err
+	}
+	return usage.CanAddUsers(1), nil
+}
+
 func validateKey(kind models.EmailVerificationKind, c web.Context) (*models.EmailVerification, error) {
 	key := c.QueryParam("k")
 
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/email/mailgun"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+	"github.com/getfider/fider/app/tasks"
+)
+
+// ListEmailTemplates returns all email templates that can be customized and current tenant's customizations
+func ListEmailTemplates() web.HandlerFunc {
+	return func(c web.Context) error {
+		customized, err := c.Services().EmailTemplates.GetAll()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"templates":  email.Templates(),
+			"customized": customized,
+		})
+	}
+}
+
+// SaveEmailTemplate creates or updates the customized version of an email template
+func SaveEmailTemplate() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.SaveEmailTemplate)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		template, err := c.Services().EmailTemplates.Save(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(template)
+	}
+}
+
+// PreviewEmailTemplate renders an email template with sample data without saving it
+func PreviewEmailTemplate() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.SaveEmailTemplate)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		return c.Ok(web.Map{
+			"subject":   input.Preview.Subject,
+			"body":      input.Preview.Body,
+			"plainText": input.Preview.PlainText,
+		})
+	}
+}
+
+// DeleteEmailTemplate removes the customized version of an email template
+func DeleteEmailTemplate() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.DeleteEmailTemplate)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().EmailTemplates.Delete(input.Model.Name, input.Model.Locale)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// EmailLogPage is the page used by administrators to see recent email deliveries and suppressed addresses
+func EmailLogPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		deliveries, err := c.Services().EmailLog.GetRecent(100)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		suppressions, err := c.Services().EmailLog.GetSuppressions()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Email Log · Site Settings",
+			Data: web.Map{
+				"deliveries":   deliveries,
+				"suppressions": suppressions,
+			},
+		})
+	}
+}
+
+// RemoveEmailSuppression removes an address from the suppression list
+func RemoveEmailSuppression() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.RemoveEmailSuppression)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().EmailLog.Unsuppress(input.Model.Address)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// MailgunWebhook receives Mailgun events and adds bounced and complained addresses to the suppression list
+func MailgunWebhook() web.HandlerFunc {
+	return func(c web.Context) error {
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		signingKey := env.GetEnvOrDefault("EMAIL_MAILGUN_WEBHOOK_KEY", env.GetEnvOrDefault("EMAIL_MAILGUN_API", ""))
+		event, err := mailgun.ParseEvent(body, signingKey)
+		if err != nil {
+			c.Logger().Warnf("Invalid Mailgun webhook request: %s", err.Error())
+			return c.BadRequest(web.Map{})
+		}
+
+		if event == nil {
+			return c.Ok(web.Map{})
+		}
+
+		tenant, err := c.Services().Tenants.GetByID(event.TenantID)
+		if err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.Ok(web.Map{})
+			}
+			return c.Failure(err)
+		}
+		c.SetTenant(tenant)
+
+		err = c.Services().EmailLog.Suppress(event.Address, event.Reason)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = c.Services().EmailLog.Add(&models.EmailDelivery{
+			TemplateName: event.TemplateName,
+			Address:      event.Address,
+			Provider:     "mailgun",
+			Status:       event.Status,
+			Response:     event.Description,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// EmailSettingsPage is the page used by administrators to change how emails are sent
+func EmailSettingsPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		settings := c.Tenant().Email
+		return c.Page(web.Props{
+			TitleKey: "Email · Site Settings",
+			Data: web.Map{
+				"settings":           settings,
+				"domain":             settings.Domain(),
+				"isVerified":         settings.IsVerified(),
+				"hasSMTPPassword":    settings.SMTPPassword != "",
+				"hasDKIMKey":         settings.DKIMPrivateKey != "",
+				"verificationRecord": email.VerificationRecord(settings.VerificationToken),
+			},
+		})
+	}
+}
+
+// UpdateEmailSettings updates current tenant's sender identity and delivery settings
+func UpdateEmailSettings() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantEmailSettings)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateEmailSettings(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		settings := c.Tenant().Email
+		return c.Ok(web.Map{
+			"domain":             settings.Domain(),
+			"isVerified":         settings.IsVerified(),
+			"verificationRecord": email.VerificationRecord(settings.VerificationToken),
+		})
+	}
+}
+
+// SendSenderVerification sends a verification email to current tenant's from address
+func SendSenderVerification() web.HandlerFunc {
+	return func(c web.Context) error {
+		address := c.Tenant().Email.FromAddress
+		if address == "" {
+			return c.HandleValidation(validate.Failed([]string{"From address is required."}))
+		}
+
+		model := &models.VerifySenderAddress{
+			Email:           address,
+			VerificationKey: models.GenerateVerificationKey(),
+			Requestor:       c.User(),
+		}
+
+		err := c.Services().Tenants.SaveVerificationKey(model.VerificationKey, 48*time.Hour, model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.SendSenderVerification(model))
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// VerifySenderAddressKey checks if key is correct and marks the from address as verified.
+// Only the mailbox is verified, other addresses of the same domain need a DNS record or their own verification.
+func VerifySenderAddressKey() web.HandlerFunc {
+	return func(c web.Context) error {
+		result, err := validateKey(models.EmailVerificationKindSenderAddress, c)
+		if result == nil {
+			return err
+		}
+
+		// From address has changed since the verification email was sent
+		settings := c.Tenant().Email
+		if !strings.EqualFold(result.Email, settings.FromAddress) {
+			return c.Redirect(c.BaseURL() + "/admin/email")
+		}
+
+		err = c.Services().Tenants.SetEmailVerifiedAddress(settings.FromAddress)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = c.Services().Tenants.SetKeyAsVerified(result.Key)
+		if err != nil {
+			return c.Failure(err)
+		}
+		return c.Redirect(c.BaseURL() + "/admin/email")
+	}
+}
+
+// CheckSenderDomain looks for the verification TXT record on the domain of current tenant's from address
+func CheckSenderDomain() web.HandlerFunc {
+	return func(c web.Context) error {
+		settings := c.Tenant().Email
+		if settings.Domain() == "" {
+			return c.HandleValidation(validate.Failed([]string{"From address is required."}))
+		}
+
+		found, err := email.HasVerificationRecord(settings.Domain(), settings.VerificationToken)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if !found {
+			return c.HandleValidation(validate.Failed([]string{
+				fmt.Sprintf("TXT record '%s' was not found on %s.", email.VerificationRecord(settings.VerificationToken), settings.Domain()),
+			}))
+		}
+
+		err = c.Services().Tenants.SetEmailVerifiedDomain(settings.Domain())
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -0,0 +1,287 @@
+package handlers_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/uuid"
+)
+
+func TestSaveEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "signin_email").
+		ExecutePost(
+			handlers.SaveEmailTemplate(),
+			`{ "locale": "pt-BR", "subject": "Entrar em {{ .tenantName }}", "body": "Clique no link: {{ .link }}" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	template, err := services.EmailTemplates.Get("signin_email", "pt-BR")
+	Expect(err).IsNil()
+	Expect(template.Subject).Equals("Entrar em {{ .tenantName }}")
+	Expect(template.Body).Equals("Clique no link: {{ .link }}")
+}
+
+func TestSaveEmailTemplateHandler_InvalidTemplate(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "signin_email").
+		ExecutePostAsJSON(
+			handlers.SaveEmailTemplate(),
+			`{ "locale": "en", "subject": "Sign in", "body": "{{ if .link }}" }`,
+		)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(query.Contains("failures.body")).IsTrue()
+	_, err := services.EmailTemplates.Get("signin_email", "en")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestPreviewEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "signin_email").
+		ExecutePostAsJSON(
+			handlers.PreviewEmailTemplate(),
+			`{ "locale": "pt-BR", "subject": "Entrar em {{ .tenantName }}", "body": "Clique no link: {{ .link }}" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.String("subject")).Equals("Entrar em Demonstration")
+	Expect(query.String("body")).Equals("Clique no link: <a href='https://demo.fider.io/signin/verify?k=1234'>https://demo.fider.io/signin/verify?k=1234</a>")
+
+	all, _ := services.EmailTemplates.GetAll()
+	Expect(all).HasLen(0)
+}
+
+func TestDeleteEmailTemplateHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.EmailTemplates.Save(&models.SaveEmailTemplate{Name: "new_idea", Locale: "en", Subject: "{{ .title }}", Body: "{{ .content }}"})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "new_idea").
+		ExecutePost(handlers.DeleteEmailTemplate(), `{ "locale": "en" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	_, err := services.EmailTemplates.Get("new_idea", "en")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestDeleteEmailTemplateHandler_NotFound(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("name", "new_idea").
+		ExecutePost(handlers.DeleteEmailTemplate(), `{ "locale": "en" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestRemoveEmailSuppressionHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.EmailLog.Suppress("arya.stark@got.com", "bounce")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.RemoveEmailSuppression(), `{ "address": "arya.stark@got.com" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	suppressed, _ := services.EmailLog.IsSuppressed("arya.stark@got.com")
+	Expect(suppressed).IsFalse()
+}
+
+func mailgunWebhookBody(key, event string) string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	token := uuid.NewV4().String()
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + token))
+	signature := hex.EncodeToString(mac.Sum(nil))
+	return fmt.Sprintf(`{
+		"signature": { "timestamp": "%s", "token": "%s", "signature": "%s" },
+		"event-data": {
+			"event": "%s",
+			"severity": "permanent",
+			"recipient": "arya.stark@got.com",
+			"user-variables": { "tenant_id": "1", "template": "new_idea" },
+			"delivery-status": { "code": 550, "description": "No such mailbox" }
+		}
+	}`, timestamp, token, signature, event)
+}
+
+func TestMailgunWebhookHandler(t *testing.T) {
+	RegisterT(t)
+
+	os.Setenv("EMAIL_MAILGUN_WEBHOOK_KEY", "my-key")
+	defer os.Unsetenv("EMAIL_MAILGUN_WEBHOOK_KEY")
+
+	server, services := mock.NewServer()
+	code, _ := server.ExecutePost(handlers.MailgunWebhook(), mailgunWebhookBody("my-key", "failed"))
+	Expect(code).Equals(http.StatusOK)
+
+	services.SetCurrentTenant(mock.DemoTenant)
+	suppressed, _ := services.EmailLog.IsSuppressed("arya.stark@got.com")
+	Expect(suppressed).IsTrue()
+
+	deliveries, _ := services.EmailLog.GetRecent(10)
+	Expect(deliveries).HasLen(1)
+	Expect(deliveries[0].Status).Equals(models.EmailDeliveryBounced)
+	Expect(deliveries[0].TemplateName).Equals("new_idea")
+	Expect(deliveries[0].Response).Equals("550 No such mailbox")
+}
+
+func TestMailgunWebhookHandler_InvalidSignature(t *testing.T) {
+	RegisterT(t)
+
+	os.Setenv("EMAIL_MAILGUN_WEBHOOK_KEY", "my-key")
+	defer os.Unsetenv("EMAIL_MAILGUN_WEBHOOK_KEY")
+
+	server, services := mock.NewServer()
+	code, _ := server.ExecutePost(handlers.MailgunWebhook(), mailgunWebhookBody("wrong-key", "complained"))
+	Expect(code).Equals(http.StatusBadRequest)
+
+	services.SetCurrentTenant(mock.DemoTenant)
+	suppressed, _ := services.EmailLog.IsSuppressed("arya.stark@got.com")
+	Expect(suppressed).IsFalse()
+}
+
+func TestUpdateEmailSettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(
+			handlers.UpdateEmailSettings(),
+			`{ "fromAddress": "Feedback@Demo.com", "replyTo": "support@demo.com", "smtpHost": "smtp.demo.com", "smtpPort": "587", "smtpPassword": "s3cr3t" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.String("domain")).Equals("demo.com")
+	Expect(mock.DemoTenant.Email.FromAddress).Equals("feedback@demo.com")
+	Expect(mock.DemoTenant.Email.SMTPPassword).Equals("s3cr3t")
+	Expect(mock.DemoTenant.Email.VerificationToken).IsNotEmpty()
+	Expect(query.String("verificationRecord")).Equals("fider-verification=" + mock.DemoTenant.Email.VerificationToken)
+}
+
+func TestUpdateEmailSettingsHandler_KeepPassword(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Tenants.UpdateEmailSettings(&models.UpdateTenantEmailSettings{
+		FromAddress:  "feedback@demo.com",
+		SMTPHost:     "smtp.demo.com",
+		SMTPPort:     "587",
+		SMTPPassword: "s3cr3t",
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(
+			handlers.UpdateEmailSettings(),
+			`{ "fromAddress": "feedback@demo.com", "smtpHost": "smtp2.demo.com", "smtpPort": "587" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(mock.DemoTenant.Email.SMTPHost).Equals("smtp2.demo.com")
+	Expect(mock.DemoTenant.Email.SMTPPassword).Equals("s3cr3t")
+}
+
+func TestSendSenderVerificationHandler_WithoutAddress(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.SendSenderVerification(), `{}`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestVerifySenderAddressKeyHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Tenants.UpdateEmailSettings(&models.UpdateTenantEmailSettings{FromAddress: "feedback@demo.com"})
+	services.Tenants.SaveVerificationKey("th3-s3cr3t", 48*time.Hour, &models.VerifySenderAddress{
+		Email:     "feedback@demo.com",
+		Requestor: mock.JonSnow,
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		WithURL("http://demo.test.fider.io/admin/email/verify?k=th3-s3cr3t").
+		Execute(handlers.VerifySenderAddressKey())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(mock.DemoTenant.Email.IsVerified()).IsTrue()
+	Expect(mock.DemoTenant.Email.VerifiedAddress).Equals("feedback@demo.com")
+	Expect(mock.DemoTenant.Email.VerifiedDomain).Equals("")
+
+	result, err := services.Tenants.FindVerificationByKey(models.EmailVerificationKindSenderAddress, "th3-s3cr3t")
+	Expect(err).IsNil()
+	Expect(result.VerifiedOn).IsNotNil()
+
+	//Other addresses of the same domain are not verified by the mailbox verification
+	services.Tenants.UpdateEmailSettings(&models.UpdateTenantEmailSettings{FromAddress: "ceo@demo.com"})
+	Expect(mock.DemoTenant.Email.IsVerified()).IsFalse()
+}
+
+func TestMailgunWebhookHandler_ReplayedRequest(t *testing.T) {
+	RegisterT(t)
+
+	os.Setenv("EMAIL_MAILGUN_WEBHOOK_KEY", "my-key")
+	defer os.Unsetenv("EMAIL_MAILGUN_WEBHOOK_KEY")
+
+	server, _ := mock.NewServer()
+	body := mailgunWebhookBody("my-key", "complained")
+	code, _ := server.ExecutePost(handlers.MailgunWebhook(), body)
+	Expect(code).Equals(http.StatusOK)
+
+	server, _ = mock.NewServer()
+	code, _ = server.ExecutePost(handlers.MailgunWebhook(), body)
+	Expect(code).Equals(http.StatusBadRequest)
+}
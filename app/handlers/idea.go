@@ -31,21 +31,21 @@ func Index() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		description := ""
-		if c.Tenant().WelcomeMessage != "" {
-			description = markdown.PlainText(c.Tenant().WelcomeMessage)
-		} else {
-			description = "We'd love to hear what you're thinking about. What can we do better? This is the place for you to vote, discuss and share ideas."
-		}
-
-		return c.Page(web.Props{
-			Description: description,
+		props := web.Props{
 			Data: web.Map{
 				"ideas":          ideas,
 				"tags":           tags,
 				"countPerStatus": stats,
 			},
-		})
+		}
+
+		if c.Tenant().WelcomeMessage != "" {
+			props.Description = markdown.PlainText(c.Tenant().WelcomeMessage)
+		} else {
+			props.DescriptionKey = "We'd love to hear what you're thinking about. What can we do better? This is the place for you to vote, discuss and share ideas."
+		}
+
+		return c.Page(props)
 	}
 }
 
@@ -78,6 +78,12 @@ func PostIdea() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		if input.Model.IsPrivate {
+			if err := ideas.SetPrivacy(idea, true); err != nil {
+				return c.Failure(err)
+			}
+		}
+
 		if err := ideas.AddSupporter(idea, c.User()); err != nil {
 			return c.Failure(err)
 		}
@@ -105,6 +111,23 @@ func UpdateIdea() web.HandlerFunc {
 	}
 }
 
+// SetIdeaPrivacy makes an existing idea of current tenant private or public
+func SetIdeaPrivacy() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.SetIdeaPrivacy)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Ideas.SetPrivacy(input.Idea, input.Model.IsPrivate)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
 // DeleteIdea deletes an existing idea of current tenant
 func DeleteIdea() web.HandlerFunc {
 	return func(c web.Context) error {
@@ -151,15 +174,21 @@ func IdeaDetails() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		data := web.Map{
+			"comments":   comments,
+			"subscribed": subscribed,
+			"idea":       idea,
+			"tags":       tags,
+		}
+
+		if !idea.IsPrivate {
+			data["__oEmbed"] = oEmbedDiscoveryURL(c, idea)
+		}
+
 		return c.Page(web.Props{
 			Title:       idea.Title,
 			Description: markdown.PlainText(idea.Description),
-			Data: web.Map{
-				"comments":   comments,
-				"subscribed": subscribed,
-				"idea":       idea,
-				"tags":       tags,
-			},
+			Data:        data,
 		})
 	}
 }
@@ -205,6 +234,23 @@ func UpdateComment() web.HandlerFunc {
 	}
 }
 
+// DeleteComment removes an existing comment
+func DeleteComment() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.DeleteComment)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Ideas.DeleteComment(input.Model.ID)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
 // SetResponse changes current idea staff response
 func SetResponse() web.HandlerFunc {
 	return func(c web.Context) error {
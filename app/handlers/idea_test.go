@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app"
@@ -445,3 +446,282 @@ func TestDeleteIdeaHandler_Authorized(t *testing.T) {
 	Expect(idea).IsNil()
 	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
 }
+
+func TestDeleteCommentHandler_Moderator(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.JonSnow)
+	idea, _ := services.Ideas.Add("The Idea #1", "The Description #1")
+	commentId, _ := services.Ideas.AddComment(idea, "My first comment")
+
+	moderator, _ := services.Tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Moderator",
+		Permissions: []models.Permission{models.PermissionModerateComments},
+	})
+	services.Users.AssignCustomRole(mock.AryaStark.ID, moderator)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", idea.Number).
+		AddParam("id", commentId).
+		Execute(handlers.DeleteComment())
+
+	Expect(code).Equals(http.StatusOK)
+	comments, _ := services.Ideas.GetCommentsByIdea(idea)
+	Expect(comments).HasLen(0)
+}
+
+func TestDeleteCommentHandler_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.AryaStark)
+	idea, _ := services.Ideas.Add("The Idea #1", "The Description #1")
+	commentId, _ := services.Ideas.AddComment(idea, "My first comment")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", idea.Number).
+		AddParam("id", commentId).
+		Execute(handlers.DeleteComment())
+
+	Expect(code).Equals(http.StatusForbidden)
+	comments, _ := services.Ideas.GetCommentsByIdea(idea)
+	Expect(comments).HasLen(1)
+}
+
+func TestPostIdeaHandler_Private(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.PostIdea(), `{ "title": "My confidential idea", "isPrivate": true }`)
+	Expect(code).Equals(http.StatusOK)
+
+	idea, err := services.Ideas.GetByNumber(1)
+	Expect(err).IsNil()
+	Expect(idea.IsPrivate).IsTrue()
+}
+
+func TestPostIdeaHandler_SameTitleAsPrivateIdea(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.JonSnow)
+	idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+	services.Ideas.SetPrivacy(idea, true)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.PostIdea(), `{ "title": "My confidential idea" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("This title can't be used.")
+	Expect(strings.Contains(response.Body.String(), "already been posted")).IsFalse()
+}
+
+func TestDetailsHandler_PrivateIdea(t *testing.T) {
+	RegisterT(t)
+
+	var testCases = []struct {
+		user *models.User
+		code int
+	}{
+		{nil, http.StatusNotFound},
+		{mock.AryaStark, http.StatusOK},
+		{mock.JonSnow, http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		server, services := mock.NewServer()
+		services.SetCurrentTenant(mock.DemoTenant)
+		services.SetCurrentUser(mock.AryaStark)
+		idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+		services.Ideas.SetPrivacy(idea, true)
+		services.SetCurrentUser(nil)
+
+		server.OnTenant(mock.DemoTenant)
+		if testCase.user != nil {
+			server.AsUser(testCase.user)
+		}
+
+		code, _ := server.
+			AddParam("number", idea.Number).
+			Execute(handlers.IdeaDetails())
+		Expect(code).Equals(testCase.code)
+	}
+}
+
+func TestDetailsHandler_PrivateIdea_CustomRole(t *testing.T) {
+	RegisterT(t)
+
+	var testCases = []struct {
+		permissions []models.Permission
+		code        int
+	}{
+		{[]models.Permission{models.PermissionRespond}, http.StatusNotFound},
+		{[]models.Permission{models.PermissionViewPrivate}, http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		server, services := mock.NewServer()
+		services.SetCurrentTenant(mock.DemoTenant)
+		services.SetCurrentUser(mock.AryaStark)
+		idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+		services.Ideas.SetPrivacy(idea, true)
+		services.SetCurrentUser(nil)
+
+		sansa := &models.User{Name: "Sansa Stark", Email: "sansa.stark@got.com", Tenant: mock.DemoTenant, Role: models.RoleVisitor}
+		services.Users.Register(sansa)
+		role, _ := services.Tenants.SaveCustomRole(&models.SaveCustomRole{Name: "Support", Permissions: testCase.permissions})
+		services.Users.AssignCustomRole(sansa.ID, role)
+		sansa, _ = services.Users.GetByID(sansa.ID)
+
+		code, _ := server.
+			OnTenant(mock.DemoTenant).
+			AsUser(sansa).
+			AddParam("number", idea.Number).
+			Execute(handlers.IdeaDetails())
+		Expect(code).Equals(testCase.code)
+	}
+}
+
+func TestSearchIdeasHandler_PrivateIdea(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.JonSnow)
+	services.Ideas.Add("My public idea", "Public details")
+	idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+	services.Ideas.SetPrivacy(idea, true)
+	services.SetCurrentUser(nil)
+
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		ExecuteAsJSON(handlers.SearchIdeas())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.ArrayLength()).Equals(1)
+}
+
+func TestSetIdeaPrivacyHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.AryaStark)
+	idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", idea.Number).
+		ExecutePost(handlers.SetIdeaPrivacy(), `{ "isPrivate": true }`)
+	Expect(code).Equals(http.StatusOK)
+	Expect(idea.IsPrivate).IsTrue()
+}
+
+func TestSetIdeaPrivacyHandler_Staff(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.AryaStark)
+	idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+	services.Ideas.SetPrivacy(idea, true)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", idea.Number).
+		ExecutePost(handlers.SetIdeaPrivacy(), `{ "isPrivate": false }`)
+	Expect(code).Equals(http.StatusOK)
+	Expect(idea.IsPrivate).IsFalse()
+}
+
+func TestSetIdeaPrivacyHandler_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.JonSnow)
+	idea, _ := services.Ideas.Add("Jon's public idea", "Public details")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", idea.Number).
+		ExecutePost(handlers.SetIdeaPrivacy(), `{ "isPrivate": true }`)
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(idea.IsPrivate).IsFalse()
+}
+
+func TestSetIdeaPrivacyHandler_CustomRoleWithoutEditIdeas(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.JonSnow)
+	idea, _ := services.Ideas.Add("Jon's public idea", "Public details")
+	role, _ := services.Tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Support",
+		Permissions: []models.Permission{models.PermissionRespond},
+	})
+	services.Users.AssignCustomRole(mock.AryaStark.ID, role)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("number", idea.Number).
+		ExecutePost(handlers.SetIdeaPrivacy(), `{ "isPrivate": true }`)
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(idea.IsPrivate).IsFalse()
+}
+
+func TestSetResponseHandler_Duplicate_PrivateOriginal(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.AryaStark)
+	idea1, _ := services.Ideas.Add("The Idea #1", "The Description #1")
+	idea2, _ := services.Ideas.Add("The Idea #2", "The Description #2")
+	services.Ideas.SetPrivacy(idea2, true)
+
+	body := fmt.Sprintf(`{ "status": %d, "originalNumber": %d }`, models.IdeaDuplicate, idea2.Number)
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", idea1.Number).
+		ExecutePost(handlers.SetResponse(), body)
+	Expect(code).Equals(http.StatusBadRequest)
+}
+
+func TestSetResponseHandler_Duplicate_PrivateIntoPublic(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.AryaStark)
+	idea1, _ := services.Ideas.Add("The Idea #1", "The Description #1")
+	idea2, _ := services.Ideas.Add("The Idea #2", "The Description #2")
+	services.Ideas.SetPrivacy(idea2, true)
+
+	body := fmt.Sprintf(`{ "status": %d, "originalNumber": %d }`, models.IdeaDuplicate, idea1.Number)
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("number", idea2.Number).
+		ExecutePost(handlers.SetResponse(), body)
+	Expect(code).Equals(http.StatusOK)
+	Expect(idea2.Status).Equals(models.IdeaDuplicate)
+}
@@ -25,6 +25,9 @@ func Avatar() web.HandlerFunc {
 		if err == nil && id > 0 {
 			user, err := c.Services().Users.GetByID(id)
 			if err == nil && user.Tenant.ID == c.Tenant().ID {
+				if user.AvatarURL != "" {
+					return c.Redirect(user.AvatarURL)
+				}
 				if user.Email != "" {
 					hash := md5.Sum([]byte(user.Email))
 					url := fmt.Sprintf("https://www.gravatar.com/avatar/%x?s=%d&d=404", hash, size)
@@ -85,3 +88,47 @@ func Logo() web.HandlerFunc {
 		return c.Blob(http.StatusOK, logo.ContentType, bytes)
 	}
 }
+
+//Favicon returns tenant favicon by its ID on a given size
+func Favicon() web.HandlerFunc {
+	return func(c web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		size, err := c.ParamAsInt("size")
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		favicon, err := c.Services().Tenants.GetThemeImage(id)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		bytes, err := img.Resize(favicon.Content, size)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Blob(http.StatusOK, favicon.ContentType, bytes)
+	}
+}
+
+//HeaderImage returns tenant header image by its ID
+func HeaderImage() web.HandlerFunc {
+	return func(c web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		header, err := c.Services().Tenants.GetThemeImage(id)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Blob(http.StatusOK, header.ContentType, header.Content)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/mock"
 
 	"github.com/getfider/fider/app/handlers"
@@ -73,3 +74,45 @@ func TestUnknownUser_LetterAvatarHandler(t *testing.T) {
 	bytes, _ := ioutil.ReadAll(response.Body)
 	Expect(bytes).Equals(expectedAvatar)
 }
+
+func TestFaviconHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	favicon, _ := ioutil.ReadFile(env.Path("/app/pkg/img/testdata/logo1.png"))
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Tenants.UpdateTheme(&models.UpdateTenantTheme{
+		Favicon: &models.UpdateTenantSettingsLogo{
+			Upload: &models.UpdateTenantSettingsLogoUpload{Content: favicon},
+		},
+	})
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("id", mock.DemoTenant.Theme.FaviconID).
+		AddParam("size", 50).
+		Execute(handlers.Favicon())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Type")).Equals("image/png")
+}
+
+func TestHeaderImageHandler_OtherTenant(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	header, _ := ioutil.ReadFile(env.Path("/app/pkg/img/testdata/logo1.png"))
+	services.SetCurrentTenant(mock.AvengersTenant)
+	services.Tenants.UpdateTheme(&models.UpdateTenantTheme{
+		Header: &models.UpdateTenantSettingsLogo{
+			Upload: &models.UpdateTenantSettingsLogoUpload{Content: header},
+		},
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("id", mock.AvengersTenant.Theme.HeaderID).
+		Execute(handlers.HeaderImage())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
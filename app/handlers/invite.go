@@ -2,15 +2,21 @@ package handlers
 
 import (
 	"strings"
+	"time"
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/email"
-	"github.com/getfider/fider/app/pkg/markdown"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/validate"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/tasks"
 )
 
+//invitesPerTask is the maximum number of invitations sent by each background task
+const invitesPerTask = 100
+
 // SendSampleInvite to current user's email
 func SendSampleInvite() web.HandlerFunc {
 	return func(c web.Context) error {
@@ -21,11 +27,11 @@ func SendSampleInvite() web.HandlerFunc {
 
 		if c.User().Email != "" {
 			input.Model.Message = strings.Replace(input.Model.Message, app.InvitePlaceholder, "*the link to accept invitation will be here*", -1)
-			to := email.NewRecipient(c.User().Name, c.User().Email, email.Params{
+			to := email.NewUserRecipient(c.User(), email.Params{
 				"subject": input.Model.Subject,
-				"message": markdown.Parse(input.Model.Message),
+				"message": email.Markdown(input.Model.Message),
 			})
-			err := c.Services().Emailer.Send(c.Tenant(), "invite_email", email.Params{}, c.Tenant().Name, to)
+			err := c.Services().Emailer.Send(c.Tenant(), c.Services().EmailTemplates, "invite_email", email.Params{}, c.Tenant().Name, to)
 			if err != nil {
 				return c.Failure(err)
 			}
@@ -48,3 +54,134 @@ func SendInvites() web.HandlerFunc {
 		return c.Ok(web.Map{})
 	}
 }
+
+// InvitationsPage is the page used to invite users and follow their invitations
+func InvitationsPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		invitations, err := getInvitations(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Invitations · Site Settings",
+			Data: web.Map{
+				"invitations": invitations,
+			},
+		})
+	}
+}
+
+// ListInvitations returns all invitations of current tenant
+func ListInvitations() web.HandlerFunc {
+	return func(c web.Context) error {
+		invitations, err := getInvitations(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(invitations)
+	}
+}
+
+// ImportInvitations sends an email to each recipient of a CSV file
+func ImportInvitations() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.ImportInvitations)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		for start := 0; start < len(input.Invitations); start += invitesPerTask {
+			end := start + invitesPerTask
+			if end > len(input.Invitations) {
+				end = len(input.Invitations)
+			}
+			c.Enqueue(tasks.SendInvites(input.Model.Subject, input.Model.Message, input.Invitations[start:end]))
+		}
+
+		return c.Ok(web.Map{
+			"total": len(input.Invitations),
+		})
+	}
+}
+
+// ResendInvitation replaces the link of an invitation and sends it again
+func ResendInvitation() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.ResendInvitation)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		verification, err := getPendingInvitation(c, input.Model.ID)
+		if verification == nil {
+			return err
+		}
+
+		if err = c.Services().Tenants.DeleteVerification(verification.ID); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Enqueue(tasks.SendInvites(input.Model.Subject, input.Model.Message, []*models.UserInvitation{
+			&models.UserInvitation{
+				Email:           verification.Email,
+				Name:            verification.Name,
+				VerificationKey: models.GenerateVerificationKey(),
+			},
+		}))
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// RevokeInvitation removes an invitation, so that its link can't be used anymore
+func RevokeInvitation() web.HandlerFunc {
+	return func(c web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		verification, err := getPendingInvitation(c, id)
+		if verification == nil {
+			return err
+		}
+
+		if err = c.Services().Tenants.DeleteVerification(verification.ID); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+func getInvitations(c web.Context) ([]*models.Invitation, error) {
+	verifications, err := c.Services().Tenants.GetVerificationsByKind(models.EmailVerificationKindUserInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitations := make([]*models.Invitation, len(verifications))
+	for i, verification := range verifications {
+		invitations[i] = models.NewInvitation(verification, now)
+	}
+	return invitations, nil
+}
+
+//getPendingInvitation returns the invitation with given id, as long as it hasn't been accepted yet
+func getPendingInvitation(c web.Context, id int) (*models.EmailVerification, error) {
+	verification, err := c.Services().Tenants.GetVerificationByID(models.EmailVerificationKindUserInvitation, id)
+	if err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return nil, c.NotFound()
+		}
+		return nil, c.Failure(err)
+	}
+
+	if models.NewInvitation(verification, time.Now()).Status == models.InvitationAccepted {
+		return nil, c.HandleValidation(validate.Failed([]string{"This invitation has already been accepted."}))
+	}
+	return verification, nil
+}
@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestListInvitationsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SaveVerificationKey("1111", 15*time.Minute, &models.UserInvitation{Email: "hot.pie@got.com"})
+	services.Tenants.SaveVerificationKey("2222", 15*time.Minute, &models.UserInvitation{Email: "gendry@got.com", Name: "Gendry"})
+	services.Tenants.SaveVerificationKey("3333", -1*time.Minute, &models.UserInvitation{Email: "sansa.stark@got.com"})
+	services.Tenants.SetKeyAsVerified("2222")
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(handlers.ListInvitations())
+
+	var invitations []*models.Invitation
+	json.Unmarshal(response.Body.Bytes(), &invitations)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(invitations).HasLen(3)
+	Expect(invitations[0].Email).Equals("sansa.stark@got.com")
+	Expect(invitations[0].Status).Equals(models.InvitationExpired)
+	Expect(invitations[1].Name).Equals("Gendry")
+	Expect(invitations[1].Status).Equals(models.InvitationAccepted)
+	Expect(invitations[1].AcceptedOn).IsNotNil()
+	Expect(invitations[2].Status).Equals(models.InvitationPending)
+}
+
+func TestImportInvitationsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(
+			handlers.ImportInvitations(),
+			`{ "subject": "Share your ideas", "message": "Join us: %invite%", "csv": "email,name\nhot.pie@got.com,Hot Pie\ngendry@got.com" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.Int32("total")).Equals(2)
+}
+
+func TestRevokeInvitationHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SaveVerificationKey("1111", 15*time.Minute, &models.UserInvitation{Email: "hot.pie@got.com"})
+	invitation, _ := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "1111")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", invitation.ID).
+		Execute(handlers.RevokeInvitation())
+
+	Expect(code).Equals(http.StatusOK)
+	_, err := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "1111")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestRevokeInvitationHandler_Accepted(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SaveVerificationKey("1111", 15*time.Minute, &models.UserInvitation{Email: "hot.pie@got.com"})
+	services.Tenants.SetKeyAsVerified("1111")
+	invitation, _ := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "1111")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", invitation.ID).
+		Execute(handlers.RevokeInvitation())
+
+	Expect(code).Equals(http.StatusBadRequest)
+	_, err := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "1111")
+	Expect(err).IsNil()
+}
+
+func TestResendInvitationHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SaveVerificationKey("1111", -1*time.Minute, &models.UserInvitation{Email: "hot.pie@got.com"})
+	invitation, _ := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "1111")
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", invitation.ID).
+		ExecutePost(handlers.ResendInvitation(), `{ "subject": "Share your ideas", "message": "Join us: %invite%" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	_, err := services.Tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "1111")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestResendInvitationHandler_Unknown(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", 999).
+		ExecutePost(handlers.ResendInvitation(), `{ "subject": "Share your ideas", "message": "Join us: %invite%" }`)
+
+	Expect(code).Equals(http.StatusNotFound)
+}
@@ -27,7 +27,7 @@ func Notifications() web.HandlerFunc {
 		}
 
 		return c.Page(web.Props{
-			Title: "Notifications",
+			TitleKey: "Notifications",
 			Data: web.Map{
 				"notifications": notifications,
 			},
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// operatorSessionDuration is how long operators stay signed in on the super-admin console
+const operatorSessionDuration = 8 * time.Hour
+
+// impersonationDuration is how long an operator can act as an administrator of a tenant
+const impersonationDuration = time.Hour
+
+// Sign in is refused after too many wrong attempts for the same email or from the same address, so that secrets can't be guessed
+const (
+	operatorFailureWindow = 15 * time.Minute
+	operatorMaxFailures   = 5
+)
+
+func auditOperatorAction(c web.Context, action models.OperatorAction, tenantID, userID int, details string) error {
+	return c.Services().Tenants.AddOperatorAudit(&models.OperatorAuditEntry{
+		OperatorEmail: c.Operator(),
+		Action:        action,
+		TenantID:      tenantID,
+		UserID:        userID,
+		Details:       details,
+		IPAddress:     c.ClientIP(),
+	})
+}
+
+// OperatorSignInPage renders the sign in page of the super-admin console
+func OperatorSignInPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Page(web.Props{
+			TitleKey: "Sign in · Operators",
+		})
+	}
+}
+
+// OperatorSignIn signs in an operator on the super-admin console
+func OperatorSignIn() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.OperatorSignIn)
+		result := c.BindTo(input)
+
+		tenants := c.Services().Tenants
+		failures, err := tenants.CountOperatorSignInFailures(input.Model.Email, c.ClientIP(), time.Now().Add(-operatorFailureWindow))
+		if err != nil {
+			return c.Failure(err)
+		}
+		if failures >= operatorMaxFailures {
+			c.Logger().Warnf("Blocked operator sign in attempt for '%s' after too many failures", input.Model.Email)
+			return c.HandleValidation(validate.Failed([]string{"Too many failed attempts. Please try again later."}))
+		}
+
+		if !result.Ok {
+			c.Logger().Warnf("Failed operator sign in attempt for '%s'", input.Model.Email)
+			if result.Error == nil {
+				if err := tenants.AddOperatorSignInFailure(input.Model.Email, c.ClientIP()); err != nil {
+					return c.Failure(err)
+				}
+			}
+			return c.HandleValidation(result)
+		}
+
+		expiresOn := time.Now().Add(operatorSessionDuration)
+		token, err := jwt.Encode(models.OperatorClaims{
+			OperatorEmail: input.Model.Email,
+			StandardClaims: jwtgo.StandardClaims{
+				ExpiresAt: expiresOn.Unix(),
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		c.AddCookie(web.CookieOperatorName, token, expiresOn)
+		return c.Ok(web.Map{})
+	}
+}
+
+// OperatorSignOut removes the operator cookie
+func OperatorSignOut() web.HandlerFunc {
+	return func(c web.Context) error {
+		c.RemoveCookie(web.CookieOperatorName)
+		return c.Redirect("/operator/signin")
+	}
+}
+
+// OperatorConsole is the page where operators manage all tenants of the instance
+func OperatorConsole() web.HandlerFunc {
+	return func(c web.Context) error {
+		tenants, err := c.Services().Tenants.Search(c.QueryParam("q"))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		audit, err := c.Services().Tenants.ListOperatorAudit()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Tenants · Operators",
+			Data: web.Map{
+				"operator": c.Operator(),
+				"tenants":  tenants,
+				"plans":    models.Plans,
+				"audit":    audit,
+			},
+		})
+	}
+}
+
+// SearchTenants returns the tenants which name or domain contain given query
+func SearchTenants() web.HandlerFunc {
+	return func(c web.Context) error {
+		tenants, err := c.Services().Tenants.Search(c.QueryParam("q"))
+		if err != nil {
+			return c.Failure(err)
+		}
+		return c.Ok(tenants)
+	}
+}
+
+// SuspendTenant blocks all requests to given tenant
+func SuspendTenant() web.HandlerFunc {
+	return func(c web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		tenant, err := c.Services().Tenants.GetByID(id)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if err := c.Services().Tenants.Suspend(tenant.ID); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := auditOperatorAction(c, models.OperatorActionSuspend, tenant.ID, 0, tenant.Subdomain); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ReactivateTenant activates a suspended tenant
+func ReactivateTenant() web.HandlerFunc {
+	return func(c web.Context) error {
+		id, err := c.ParamAsInt("id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		tenant, err := c.Services().Tenants.GetByID(id)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		//Tenants waiting for the owner to confirm the sign up must not be activated by someone else
+		if tenant.Status != models.TenantSuspended {
+			return c.BadRequest(web.Map{})
+		}
+
+		if err := c.Services().Tenants.Activate(tenant.ID); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := auditOperatorAction(c, models.OperatorActionReactivate, tenant.ID, 0, tenant.Subdomain); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ChangeTenantSubdomain moves a tenant to another subdomain
+func ChangeTenantSubdomain() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.ChangeTenantSubdomain)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		details := fmt.Sprintf("%s to %s", input.Tenant.Subdomain, input.Model.Subdomain)
+		err := c.Services().Tenants.ChangeSubdomain(input.Tenant.ID, input.Model.Subdomain)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if err := auditOperatorAction(c, models.OperatorActionChangeSubdomain, input.Tenant.ID, 0, details); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ChangeTenantPlan moves a tenant to another plan
+func ChangeTenantPlan() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.ChangeTenantPlan)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		details := fmt.Sprintf("%s to %s", input.Tenant.Plan.Name, input.Model.Plan)
+		if err := c.Services().Tenants.ChangePlan(input.Tenant.ID, input.Model.Plan); err != nil {
+			return c.Failure(err)
+		}
+
+		if err := auditOperatorAction(c, models.OperatorActionChangePlan, input.Tenant.ID, 0, details); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ImpersonateTenantUser signs the operator in as an administrator of a tenant
+// The session is short-lived and named after the operator, so the tenant can see and revoke it
+func ImpersonateTenantUser() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.ImpersonateTenantUser)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		userAgent := fmt.Sprintf("Impersonated by %s", c.Operator())
+		session, err := c.Services().Sessions.Create(input.User, userAgent, c.ClientIP(), time.Now().Add(impersonationDuration))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		token, err := jwt.Encode(models.FiderClaims{
+			UserID:    input.User.ID,
+			UserName:  input.User.Name,
+			UserEmail: input.User.Email,
+			SessionID: session.ID,
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		details := fmt.Sprintf("%s (%s)", input.User.Name, input.User.Email)
+		if err := auditOperatorAction(c, models.OperatorActionImpersonate, input.Tenant.ID, input.User.ID, details); err != nil {
+			return c.Failure(err)
+		}
+
+		c.Logger().Infof("Operator '%s' is impersonating user %d of tenant %d", c.Operator(), input.User.ID, input.Tenant.ID)
+		return c.Ok(web.Map{
+			"url": c.TenantBaseURL(input.Tenant) + "/?token=" + url.QueryEscape(token),
+		})
+	}
+}
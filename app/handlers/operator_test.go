@@ -0,0 +1,276 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func TestOperatorSignInHandler(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, _ := mock.NewServer()
+	code, response := server.ExecutePost(handlers.OperatorSignIn(), `{ "email": "OPS@fider.io", "secret": "secret" }`)
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieOperatorName + "=")
+}
+
+func TestOperatorSignInHandler_WrongSecret(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, _ := mock.NewServer()
+	code, response := server.ExecutePost(handlers.OperatorSignIn(), `{ "email": "ops@fider.io", "secret": "wrong" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestOperatorSignInHandler_WrongSecret_RecordsFailure(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, services := mock.NewServer()
+	server.ExecutePost(handlers.OperatorSignIn(), `{ "email": "OPS@fider.io", "secret": "wrong" }`)
+
+	failures, err := services.Tenants.CountOperatorSignInFailures("ops@fider.io", "", time.Now().Add(-time.Minute))
+	Expect(err).IsNil()
+	Expect(failures).Equals(1)
+}
+
+func TestOperatorSignInHandler_TooManyFailures(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, services := mock.NewServer()
+	for i := 0; i < 5; i++ {
+		services.Tenants.AddOperatorSignInFailure("ops@fider.io", "10.0.0.1")
+	}
+
+	code, response := server.ExecutePost(handlers.OperatorSignIn(), `{ "email": "ops@fider.io", "secret": "secret" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestOperatorSignInHandler_TooManyFailuresFromAddress(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	os.Setenv("TRUSTED_PROXIES", "10.1.1.1")
+	defer os.Setenv("OPERATORS", "")
+	defer os.Setenv("TRUSTED_PROXIES", "")
+
+	server, services := mock.NewServer()
+	for i := 0; i < 5; i++ {
+		services.Tenants.AddOperatorSignInFailure(fmt.Sprintf("guess%d@fider.io", i), "10.0.0.1")
+	}
+
+	code, response := server.
+		FromAddress("10.1.1.1:4000").
+		AddHeader("X-Forwarded-For", "10.0.0.1").
+		ExecutePost(handlers.OperatorSignIn(), `{ "email": "ops@fider.io", "secret": "secret" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestOperatorSignInHandler_SpoofedForwardedFor(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, services := mock.NewServer()
+	for i := 0; i < 4; i++ {
+		services.Tenants.AddOperatorSignInFailure(fmt.Sprintf("guess%d@fider.io", i), "203.0.113.9")
+	}
+
+	server.
+		FromAddress("203.0.113.9:5000").
+		AddHeader("X-Forwarded-For", "198.51.100.7").
+		ExecutePost(handlers.OperatorSignIn(), `{ "email": "guess4@fider.io", "secret": "wrong" }`)
+
+	since := time.Now().Add(-time.Minute)
+	failures, _ := services.Tenants.CountOperatorSignInFailures("someone@fider.io", "203.0.113.9", since)
+	Expect(failures).Equals(5)
+	failures, _ = services.Tenants.CountOperatorSignInFailures("someone@fider.io", "198.51.100.7", since)
+	Expect(failures).Equals(0)
+}
+
+func TestOperatorSignInHandler_SpoofedForwardedFor_StillThrottled(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, services := mock.NewServer()
+	for i := 0; i < 5; i++ {
+		services.Tenants.AddOperatorSignInFailure(fmt.Sprintf("guess%d@fider.io", i), "203.0.113.9")
+	}
+
+	code, response := server.
+		FromAddress("203.0.113.9:5000").
+		AddHeader("X-Forwarded-For", "198.51.100.7").
+		ExecutePost(handlers.OperatorSignIn(), `{ "email": "ops@fider.io", "secret": "secret" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestSuspendTenantHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.SuspendTenant(), "")
+	Expect(code).Equals(http.StatusOK)
+
+	tenant, _ := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(tenant.Status).Equals(models.TenantSuspended)
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(1)
+	Expect(audit[0].Action).Equals(models.OperatorActionSuspend)
+	Expect(audit[0].OperatorEmail).Equals("ops@fider.io")
+	Expect(audit[0].TenantID).Equals(mock.DemoTenant.ID)
+}
+
+func TestReactivateTenantHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.Suspend(mock.DemoTenant.ID)
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ReactivateTenant(), "")
+	Expect(code).Equals(http.StatusOK)
+
+	tenant, _ := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(tenant.Status).Equals(models.TenantActive)
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(1)
+	Expect(audit[0].Action).Equals(models.OperatorActionReactivate)
+}
+
+func TestReactivateTenantHandler_NotSuspended(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ReactivateTenant(), "")
+	Expect(code).Equals(http.StatusBadRequest)
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(0)
+}
+
+func TestChangeTenantSubdomainHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ChangeTenantSubdomain(), `{ "subdomain": "westeros" }`)
+	Expect(code).Equals(http.StatusOK)
+
+	tenant, _ := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(tenant.Subdomain).Equals("westeros")
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(1)
+	Expect(audit[0].Details).Equals("demo to westeros")
+}
+
+func TestChangeTenantSubdomainHandler_AlreadyInUse(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ChangeTenantSubdomain(), `{ "subdomain": "avengers" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+
+	tenant, _ := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(tenant.Subdomain).Equals("demo")
+}
+
+func TestChangeTenantPlanHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ChangeTenantPlan(), `{ "plan": "free" }`)
+	Expect(code).Equals(http.StatusOK)
+
+	tenant, _ := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(tenant.Plan).Equals(models.PlanFree)
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(1)
+	Expect(audit[0].Action).Equals(models.OperatorActionChangePlan)
+	Expect(audit[0].Details).Equals("unlimited to free")
+}
+
+func TestChangeTenantPlanHandler_UnknownPlan(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ChangeTenantPlan(), `{ "plan": "platinum" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+
+	tenant, _ := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(tenant.Plan).Equals(models.PlanUnlimited)
+}
+
+func TestImpersonateTenantUserHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, response := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePostAsJSON(handlers.ImpersonateTenantUser(), `{}`)
+	Expect(code).Equals(http.StatusOK)
+	Expect(strings.Contains(response.String("url"), "/?token=")).IsTrue()
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(1)
+	Expect(audit[0].Action).Equals(models.OperatorActionImpersonate)
+	Expect(audit[0].UserID).Equals(mock.JonSnow.ID)
+}
+
+func TestImpersonateTenantUserHandler_NonAdministrator(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		AsOperator("ops@fider.io").
+		AddParam("id", mock.DemoTenant.ID).
+		ExecutePost(handlers.ImpersonateTenantUser(), `{ "userId": `+strconv.Itoa(mock.AryaStark.ID)+` }`)
+	Expect(code).Equals(http.StatusBadRequest)
+
+	audit, _ := services.Tenants.ListOperatorAudit()
+	Expect(audit).HasLen(0)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/markdown"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// ManagePages is the page used by administrators to author pages
+func ManagePages() web.HandlerFunc {
+	return func(c web.Context) error {
+		pages, err := c.Services().Pages.GetAll()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Pages · Site Settings",
+			Data: web.Map{
+				"pages": pages,
+			},
+		})
+	}
+}
+
+// SavePage creates a new page or updates an existing one
+func SavePage() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.SavePage)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		var (
+			page *models.Page
+			err  error
+		)
+
+		if input.Page != nil {
+			page, err = c.Services().Pages.Update(input.Page, input.Model)
+		} else {
+			page, err = c.Services().Pages.Add(input.Model)
+		}
+
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(page)
+	}
+}
+
+// DeletePage deletes an existing page
+func DeletePage() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.DeletePage)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if err := c.Services().Pages.Delete(input.Page); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// ShowPage renders a page authored by current tenant
+func ShowPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		page, err := c.Services().Pages.GetBySlug(c.Param("slug"))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if !page.IsVisibleTo(c.User()) {
+			//Drafts are hidden from everyone that can't edit them
+			if page.Visibility == models.PageVisibilityMembers {
+				return c.Unauthorized()
+			}
+			return c.NotFound()
+		}
+
+		return c.Page(web.Props{
+			Title:       page.Title,
+			Description: markdown.PlainText(page.Content),
+			Data: web.Map{
+				"page": page,
+				"html": markdown.Parse(page.Content),
+			},
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func addPage(services *app.Services, slug string, visibility int) {
+	services.Pages.Add(&models.SavePage{
+		Slug:       slug,
+		Title:      "Terms of Use",
+		Content:    "We **never** sell your data.<script>alert(1)</script>",
+		Visibility: visibility,
+		Placement:  models.PagePlacementFooter,
+	})
+}
+
+func TestShowPageHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	addPage(services, "terms", models.PageVisibilityPublic)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("slug", "terms").
+		Execute(handlers.ShowPage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Body.String()).ContainsSubstring(`set("html", "\u003cp\u003eWe \u003cstrong\u003enever\u003c/strong\u003e sell your data.alert(1)\u003c/p\u003e\n")`)
+	Expect(response.Body.String()).ContainsSubstring(`set("navigation", [{"placement":2,"slug":"terms","title":"Terms of Use"}])`)
+}
+
+func TestShowPageHandler_TitleIsNotTranslated(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.DemoTenant.Locale = "pt-BR"
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Pages.Add(&models.SavePage{
+		Slug:       "settings",
+		Title:      "Settings",
+		Content:    "Not Authorized",
+		Visibility: models.PageVisibilityPublic,
+		Placement:  models.PagePlacementNone,
+	})
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("slug", "settings").
+		Execute(handlers.ShowPage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Body.String()).ContainsSubstring("<title>Settings · Demonstration</title>")
+	Expect(response.Body.String()).ContainsSubstring(`content="Not Authorized"`)
+}
+
+func TestShowPageHandler_NotFound(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("slug", "terms").
+		Execute(handlers.ShowPage())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestShowPageHandler_Visibility(t *testing.T) {
+	RegisterT(t)
+
+	var testCases = []struct {
+		visibility int
+		user       *models.User
+		code       int
+	}{
+		{models.PageVisibilityMembers, nil, http.StatusForbidden},
+		{models.PageVisibilityMembers, mock.AryaStark, http.StatusOK},
+		{models.PageVisibilityDraft, nil, http.StatusNotFound},
+		{models.PageVisibilityDraft, mock.AryaStark, http.StatusNotFound},
+		{models.PageVisibilityDraft, mock.JonSnow, http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		server, services := mock.NewServer()
+		services.SetCurrentTenant(mock.DemoTenant)
+		addPage(services, "terms", testCase.visibility)
+
+		server.OnTenant(mock.DemoTenant)
+		if testCase.user != nil {
+			server.AsUser(testCase.user)
+		}
+
+		code, _ := server.
+			AddParam("slug", "terms").
+			Execute(handlers.ShowPage())
+		Expect(code).Equals(testCase.code)
+	}
+}
+
+func TestSavePageHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.SavePage(), `{ "title": "Privacy Policy", "content": "Hello", "visibility": 1, "placement": 2 }`)
+	Expect(code).Equals(http.StatusOK)
+
+	page, err := services.Pages.GetBySlug("privacy-policy")
+	Expect(err).IsNil()
+	Expect(page.Title).Equals("Privacy Policy")
+	Expect(page.Placement).Equals(models.PagePlacementFooter)
+
+	code, _ = server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("slug", "privacy-policy").
+		ExecutePost(handlers.SavePage(), `{ "slug": "privacy", "title": "Privacy", "content": "Bye", "visibility": 2, "placement": 0 }`)
+	Expect(code).Equals(http.StatusOK)
+
+	page, err = services.Pages.GetBySlug("privacy")
+	Expect(err).IsNil()
+	Expect(page.Content).Equals("Bye")
+	Expect(page.Visibility).Equals(models.PageVisibilityMembers)
+
+	_, err = services.Pages.GetBySlug("privacy-policy")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestSavePageHandler_InvalidRequests(t *testing.T) {
+	RegisterT(t)
+
+	var testCases = []struct {
+		input    string
+		failures []string
+	}{
+		{`{ }`, []string{"failures.title", "failures.visibility"}},
+		{`{ "title": "Terms", "slug": "Not valid!", "visibility": 1 }`, []string{"failures.slug"}},
+		{`{ "title": "Terms", "slug": "faq", "visibility": 1 }`, []string{"failures.slug"}},
+		{`{ "title": "Terms", "visibility": 4, "placement": 3 }`, []string{"failures.visibility", "failures.placement"}},
+	}
+
+	for _, testCase := range testCases {
+		server, services := mock.NewServer()
+		services.SetCurrentTenant(mock.DemoTenant)
+		addPage(services, "faq", models.PageVisibilityPublic)
+
+		status, query := server.
+			OnTenant(mock.DemoTenant).
+			AsUser(mock.JonSnow).
+			ExecutePostAsJSON(handlers.SavePage(), testCase.input)
+
+		Expect(status).Equals(http.StatusBadRequest)
+		for _, failure := range testCase.failures {
+			Expect(query.Contains(failure)).IsTrue()
+		}
+	}
+}
+
+func TestSavePageHandler_Unauthorized(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.SavePage(), `{ "title": "Terms", "visibility": 1 }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestDeletePageHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	addPage(services, "terms", models.PageVisibilityPublic)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("slug", "terms").
+		Execute(handlers.DeletePage())
+	Expect(code).Equals(http.StatusOK)
+
+	_, err := services.Pages.GetBySlug("terms")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
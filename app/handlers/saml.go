@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/saml"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+//samlRequestLifetime is how long users have to sign in on the IdP
+const samlRequestLifetime = 10 * time.Minute
+
+func serviceProvider(c web.Context) *saml.ServiceProvider {
+	settings := c.Tenant().SAML
+	baseURL := c.TenantBaseURL(c.Tenant())
+	return &saml.ServiceProvider{
+		EntityID:       baseURL + "/sso/saml/metadata",
+		ACSURL:         baseURL + "/sso/saml/acs",
+		IdPEntityID:    settings.IdPEntityID,
+		IdPSSOURL:      settings.IdPSSOURL,
+		IdPCertificate: settings.IdPCertificate,
+	}
+}
+
+// SAMLMetadata returns the metadata used to register current tenant on the IdP
+func SAMLMetadata() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Blob(http.StatusOK, "application/samlmetadata+xml", serviceProvider(c).Metadata())
+	}
+}
+
+// SignInBySAML redirects user to the IdP of current tenant
+func SignInBySAML() web.HandlerFunc {
+	return func(c web.Context) error {
+		if !c.Tenant().SAML.IsEnabled {
+			return c.NotFound()
+		}
+
+		requestID, err := saml.NewRequestID()
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		authURL, err := serviceProvider(c).AuthURL(requestID, c.QueryParam("redirect"))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		//IdP posts the response back from its own site, so the cookie can't be SameSite=Lax
+		cookie := &http.Cookie{
+			Name:     web.CookieSAMLRequestName,
+			Value:    requestID,
+			HttpOnly: true,
+			Path:     "/sso/saml/acs",
+			Expires:  time.Now().Add(samlRequestLifetime),
+		}
+		if strings.HasPrefix(c.BaseURL(), "https://") {
+			cookie.Secure = true
+			c.SetSameSiteCookie(cookie, "None")
+		} else {
+			c.SetCookie(cookie)
+		}
+
+		return c.Redirect(authURL)
+	}
+}
+
+// SAMLAssertionConsumer signs in the user identified by the IdP
+func SAMLAssertionConsumer() web.HandlerFunc {
+	return func(c web.Context) error {
+		settings := c.Tenant().SAML
+		if !settings.IsEnabled {
+			return c.NotFound()
+		}
+
+		var requestID string
+		if cookie, err := c.Cookie(web.CookieSAMLRequestName); err == nil {
+			requestID = cookie.Value
+			c.SetCookie(&http.Cookie{
+				Name:    web.CookieSAMLRequestName,
+				Path:    "/sso/saml/acs",
+				MaxAge:  -1,
+				Expires: time.Now().Add(-100 * time.Hour),
+			})
+		}
+
+		assertion, err := serviceProvider(c).ParseResponse(c.Request.PostFormValue("SAMLResponse"), requestID, time.Now())
+		if err != nil {
+			c.Logger().Warnf("Invalid SAML response: %s", err.Error())
+			return c.Unauthorized()
+		}
+
+		//A captured response could otherwise be posted again until it expires
+		ok, err := c.Services().Tenants.UseSAMLAssertion(assertion.ID, assertion.ExpiresOn)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !ok {
+			c.Logger().Warnf("SAML assertion '%s' has already been used", assertion.ID)
+			return c.Unauthorized()
+		}
+
+		email := assertion.Attribute(settings.EmailAttribute)
+		if email == "" {
+			email = assertion.NameID
+		}
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !validate.Email(email).Ok {
+			c.Logger().Warnf("SAML assertion has no valid email: '%s'", email)
+			return c.Unauthorized()
+		}
+
+		name := strings.TrimSpace(assertion.Attribute(settings.NameAttribute))
+		if name == "" {
+			name = strings.Split(email, "@")[0]
+		}
+
+		role, isRoleMapped := samlRole(settings, assertion)
+		provider := &models.UserProvider{
+			UID:  assertion.NameID,
+			Name: saml.Provider,
+		}
+
+		users := c.Services().Users
+		user, err := users.GetByEmail(email)
+		if err != nil {
+			if errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+
+			canJoin, err := canAddUser(c)
+			if err != nil {
+				return c.Failure(err)
+			}
+			if !canJoin {
+				return LimitReachedPage()(c)
+			}
+
+			//Users allowed by the IdP are trusted, even when tenant is private
+			user = &models.User{
+				Name:      name,
+				Email:     email,
+				Tenant:    c.Tenant(),
+				Role:      role,
+				Providers: []*models.UserProvider{provider},
+			}
+			if err = users.Register(user); err != nil {
+				return c.Failure(err)
+			}
+		} else {
+			if isRoleMapped && user.Role != role {
+				if err = users.ChangeRole(user.ID, role); err != nil {
+					return c.Failure(err)
+				}
+				if err = c.Services().Sessions.RevokeAll(user.ID); err != nil {
+					return c.Failure(err)
+				}
+				user.Role = role
+			}
+			if !user.HasProvider(saml.Provider) {
+				if err = users.RegisterProvider(user.ID, provider); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		return signIn(c, user, c.Request.PostFormValue("RelayState"))
+	}
+}
+
+//relativeRedirect returns given address if it's relative to current site, otherwise "/"
+//Absolute addresses are not accepted as they would make sign in endpoints an open redirect
+func relativeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+	return redirect
+}
+
+//samlRole returns the role of a user based on role attribute and if roles are managed by the IdP
+func samlRole(settings models.TenantSAMLSettings, assertion *saml.Assertion) (models.Role, bool) {
+	if settings.RoleAttribute == "" {
+		return models.RoleVisitor, false
+	}
+
+	role := models.RoleVisitor
+	for _, value := range assertion.Attributes[settings.RoleAttribute] {
+		if settings.AdministratorRoleValue != "" && value == settings.AdministratorRoleValue {
+			return models.RoleAdministrator, true
+		}
+		if settings.CollaboratorRoleValue != "" && value == settings.CollaboratorRoleValue {
+			role = models.RoleCollaborator
+		}
+	}
+	return role, true
+}
+
+// SSOSettingsPage is the page used by administrators to configure single sign-on
+func SSOSettingsPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		sp := serviceProvider(c)
+		return c.Page(web.Props{
+			TitleKey: "Single Sign-On · Site Settings",
+			Data: web.Map{
+				"settings":    c.Tenant().SAML,
+				"entityId":    sp.EntityID,
+				"acsUrl":      sp.ACSURL,
+				"metadataUrl": sp.EntityID,
+				"jwt":         jwtSSOSettings(c.Tenant()),
+				"jwtUrl":      c.TenantBaseURL(c.Tenant()) + "/sso",
+			},
+		})
+	}
+}
+
+// UpdateSSOSettings updates current tenant's single sign-on settings
+func UpdateSSOSettings() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantSAMLSettings)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateSAMLSettings(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(c.Tenant().SAML)
+	}
+}
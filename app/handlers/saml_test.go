@@ -0,0 +1,333 @@
+package handlers_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/saml"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+var samlKey, _ = rsa.GenerateKey(rand.Reader, 1024)
+
+func samlCertificate() string {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &samlKey.PublicKey, samlKey)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func enableSAML(services *app.Services, enforced bool) {
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Tenants.UpdateSAMLSettings(&models.UpdateTenantSAMLSettings{
+		IsEnabled:              true,
+		IsEnforced:             enforced,
+		IdPEntityID:            "http://idp.test",
+		IdPSSOURL:              "http://idp.test/sso",
+		IdPCertificate:         samlCertificate(),
+		EmailAttribute:         "mail",
+		NameAttribute:          "displayName",
+		RoleAttribute:          "groups",
+		AdministratorRoleValue: "fider-admins",
+		CollaboratorRoleValue:  "fider-staff",
+	})
+}
+
+//samlResponse returns a base64 SAMLResponse with a signed assertion for given email and group
+func samlResponse(email, name, group string) string {
+	expires := time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+	assertion := `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_a1" IssueInstant="2018-06-05T10:00:00Z" Version="2.0">` +
+		`<saml:Issuer>http://idp.test</saml:Issuer>` +
+		`<saml:Subject><saml:NameID>` + email + `</saml:NameID></saml:Subject>` +
+		`<saml:Conditions NotOnOrAfter="` + expires + `">` +
+		`<saml:AudienceRestriction><saml:Audience>http://demo.test.fider.io/sso/saml/metadata</saml:Audience></saml:AudienceRestriction>` +
+		`</saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="mail"><saml:AttributeValue>` + email + `</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="displayName"><saml:AttributeValue>` + name + `</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="groups"><saml:AttributeValue>` + group + `</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement>` +
+		`</saml:Assertion>`
+
+	digest := sha256.Sum256([]byte(assertion))
+	signedInfo := `<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#_a1"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference></ds:SignedInfo>`
+	hashed := sha256.Sum256([]byte(signedInfo))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, samlKey, crypto.SHA256, hashed[:])
+
+	signed := strings.Replace(assertion, "</saml:Issuer>", "</saml:Issuer>"+
+		`<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">`+signedInfo+
+		`<ds:SignatureValue>`+base64.StdEncoding.EncodeToString(signature)+`</ds:SignatureValue></ds:Signature>`, 1)
+
+	response := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="_r1" Version="2.0" Destination="http://demo.test.fider.io/sso/saml/acs">` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		signed +
+		`</samlp:Response>`
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+func TestSAMLMetadataHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml/metadata").
+		Execute(handlers.SAMLMetadata())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Body.String()).ContainsSubstring(`entityID="http://demo.test.fider.io/sso/saml/metadata"`)
+	Expect(response.Body.String()).ContainsSubstring(`Location="http://demo.test.fider.io/sso/saml/acs"`)
+}
+
+func TestSignInBySAMLHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml").
+		Execute(handlers.SignInBySAML())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestSignInBySAMLHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml?redirect=/ideas/1").
+		Execute(handlers.SignInBySAML())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	location, _ := url.Parse(response.Header().Get("Location"))
+	Expect(location.Host).Equals("idp.test")
+	Expect(location.Query().Get("SAMLRequest")).IsNotEmpty()
+	Expect(location.Query().Get("RelayState")).Equals("/ideas/1")
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieSAMLRequestName + "=_")
+}
+
+func TestSignInBySAMLHandler_HTTPS(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml?redirect=/ideas/1").
+		AddHeader("X-Forwarded-Proto", "https").
+		Execute(handlers.SignInBySAML())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	cookie := response.Header().Get("Set-Cookie")
+	Expect(cookie).ContainsSubstring(web.CookieSAMLRequestName + "=_")
+	Expect(cookie).ContainsSubstring("; Secure")
+	Expect(cookie).ContainsSubstring("; SameSite=None")
+}
+
+func TestSAMLAssertionConsumerHandler_NewUser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.DemoTenant.IsPrivate = true
+	enableSAML(services, true)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml/acs").
+		ExecutePostForm(handlers.SAMLAssertionConsumer(), url.Values{
+			"SAMLResponse": {samlResponse("sansa.stark@got.com", "Sansa Stark", "fider-staff")},
+			"RelayState":   {"/ideas/1"},
+		})
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io/ideas/1")
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieAuthName + "=")
+
+	user, err := services.Users.GetByEmail("sansa.stark@got.com")
+	Expect(err).IsNil()
+	Expect(user.Name).Equals("Sansa Stark")
+	Expect(user.Role).Equals(models.RoleCollaborator)
+	Expect(user.HasProvider(saml.Provider)).IsTrue()
+}
+
+func TestSAMLAssertionConsumerHandler_ExistingUser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml/acs").
+		ExecutePostForm(handlers.SAMLAssertionConsumer(), url.Values{
+			"SAMLResponse": {samlResponse("arya.stark@got.com", "Arya", "fider-admins")},
+			"RelayState":   {"//evil.com"},
+		})
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io/")
+
+	user, err := services.Users.GetByEmail("arya.stark@got.com")
+	Expect(err).IsNil()
+	Expect(user.Name).Equals("Arya Stark")
+	Expect(user.Role).Equals(models.RoleAdministrator)
+	Expect(user.HasProvider(saml.Provider)).IsTrue()
+}
+
+func TestSAMLAssertionConsumerHandler_InvalidResponse(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+
+	tampered, _ := base64.StdEncoding.DecodeString(samlResponse("arya.stark@got.com", "Arya", "fider-staff"))
+	forged := strings.Replace(string(tampered), "fider-staff", "fider-admins", 1)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml/acs").
+		ExecutePostForm(handlers.SAMLAssertionConsumer(), url.Values{
+			"SAMLResponse": {base64.StdEncoding.EncodeToString([]byte(forged))},
+		})
+
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+	Expect(mock.AryaStark.Role).Equals(models.RoleVisitor)
+}
+
+func TestSAMLAssertionConsumerHandler_Replayed(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+	services.Tenants.UseSAMLAssertion("_a1", time.Now().Add(time.Minute))
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso/saml/acs").
+		ExecutePostForm(handlers.SAMLAssertionConsumer(), url.Values{
+			"SAMLResponse": {samlResponse("arya.stark@got.com", "Arya", "fider-staff")},
+		})
+
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+//solicitedSAMLResponse returns a SAMLResponse to the request with given ID
+func solicitedSAMLResponse(requestID string) string {
+	decoded, _ := base64.StdEncoding.DecodeString(samlResponse("arya.stark@got.com", "Arya", "fider-staff"))
+	solicited := strings.Replace(string(decoded), `ID="_r1"`, `ID="_r1" InResponseTo="`+requestID+`"`, 1)
+	return base64.StdEncoding.EncodeToString([]byte(solicited))
+}
+
+func TestSAMLAssertionConsumerHandler_InResponseTo(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSAMLRequestName, "_req1").
+		WithURL("http://demo.test.fider.io/sso/saml/acs").
+		ExecutePostForm(handlers.SAMLAssertionConsumer(), url.Values{
+			"SAMLResponse": {solicitedSAMLResponse("_req1")},
+		})
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+}
+
+func TestSAMLAssertionConsumerHandler_InResponseTo_OtherBrowser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, false)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieSAMLRequestName, "_req2").
+		WithURL("http://demo.test.fider.io/sso/saml/acs").
+		ExecutePostForm(handlers.SAMLAssertionConsumer(), url.Values{
+			"SAMLResponse": {solicitedSAMLResponse("_req1")},
+		})
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestSignInByEmailHandler_SSOEnforced(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableSAML(services, true)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByEmail(), `{ "email": "arya.stark@got.com" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+
+	server, services = mock.NewServer()
+	enableSAML(services, true)
+
+	code, _ = server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.SignInByEmail(), `{ "email": "jon.snow@got.com" }`)
+	Expect(code).Equals(http.StatusOK)
+}
+
+func TestUpdateSSOSettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(
+			handlers.UpdateSSOSettings(),
+			`{
+				"isEnabled": true,
+				"idpEntityId": "http://idp.test",
+				"idpSsoUrl": "http://idp.test/sso",
+				"idpCertificate": "`+samlCertificate()+`",
+				"emailAttribute": "mail"
+			}`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.String("idpSsoUrl")).Equals("http://idp.test/sso")
+	Expect(mock.DemoTenant.SAML.IsEnabled).IsTrue()
+	Expect(mock.DemoTenant.SAML.EmailAttribute).Equals("mail")
+}
@@ -3,7 +3,10 @@ package handlers
 import (
 	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/tasks"
 
 	"github.com/getfider/fider/app/actions"
@@ -50,6 +53,19 @@ func VerifyChangeEmailKey() web.HandlerFunc {
 		if err != nil {
 			return c.Failure(err)
 		}
+
+		//Other devices must sign in again with the new email, current one gets a new session
+		err = c.Services().Sessions.RevokeAll(result.UserID)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		user := c.User()
+		user.Email = result.Email
+		if _, err = c.AddAuthCookie(user); err != nil {
+			return c.Failure(err)
+		}
+
 		return c.Redirect(c.BaseURL() + "/settings")
 	}
 }
@@ -62,10 +78,24 @@ func UserSettings() web.HandlerFunc {
 			return err
 		}
 
+		sessions, err := c.Services().Sessions.GetActiveByUser(c.User().ID)
+		if err != nil {
+			return err
+		}
+
+		currentSessionID := ""
+		if c.Session() != nil {
+			currentSessionID = c.Session().ID
+		}
+
 		return c.Page(web.Props{
-			Title: "Settings",
+			TitleKey: "Settings",
 			Data: web.Map{
-				"settings": settings,
+				"settings":         settings,
+				"sessions":         sessions,
+				"currentSessionId": currentSessionID,
+				"twoFactor":        twoFactorSettings(c),
+				"locales":          i18n.Locales(),
 			},
 		})
 	}
@@ -101,7 +131,71 @@ func ChangeUserRole() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
-		err := c.Services().Users.ChangeRole(input.Model.UserID, input.Model.Role)
+		var err error
+		if input.CustomRole != nil {
+			err = c.Services().Users.AssignCustomRole(input.Model.UserID, input.CustomRole)
+		} else {
+			err = c.Services().Users.ChangeRole(input.Model.UserID, input.Model.Role)
+		}
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		err = c.Services().Sessions.RevokeAll(input.Model.UserID)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// RevokeSession signs out current user from one of its devices
+func RevokeSession() web.HandlerFunc {
+	return func(c web.Context) error {
+		err := c.Services().Sessions.Revoke(c.User().ID, c.Param("id"))
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// SignOutEverywhere signs out current user from all devices, including current one
+func SignOutEverywhere() web.HandlerFunc {
+	return func(c web.Context) error {
+		err := c.Services().Sessions.RevokeAll(c.User().ID)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		c.RemoveCookie(web.CookieAuthName)
+		return c.Ok(web.Map{})
+	}
+}
+
+// RevokeUserSessions signs out given user from all devices
+func RevokeUserSessions() web.HandlerFunc {
+	return func(c web.Context) error {
+		userID, err := c.ParamAsInt("user_id")
+		if err != nil {
+			return c.NotFound()
+		}
+
+		user, err := c.Services().Users.GetByID(userID)
+		if err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.NotFound()
+			}
+			return c.Failure(err)
+		}
+
+		if user.Tenant.ID != c.Tenant().ID {
+			return c.NotFound()
+		}
+
+		err = c.Services().Sessions.RevokeAll(user.ID)
 		if err != nil {
 			return c.Failure(err)
 		}
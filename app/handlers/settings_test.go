@@ -13,6 +13,7 @@ import (
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
 )
 
 func TestSettingsHandler(t *testing.T) {
@@ -20,10 +21,25 @@ func TestSettingsHandler(t *testing.T) {
 
 	server, _ := mock.NewServer()
 	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(handlers.UserSettings())
+
+	Expect(code).Equals(http.StatusOK)
+}
+
+func TestSettingsHandler_TranslatedTitle(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	mock.DemoTenant.Locale = "pt-BR"
+	code, response := server.
+		OnTenant(mock.DemoTenant).
 		AsUser(mock.JonSnow).
 		Execute(handlers.UserSettings())
 
 	Expect(code).Equals(http.StatusOK)
+	Expect(response.Body.String()).ContainsSubstring("<title>Configurações · Demonstration</title>")
 }
 
 func TestUpdateUserSettingsHandler_EmptyInput(t *testing.T) {
@@ -96,6 +112,119 @@ func TestChangeRoleHandler_Valid(t *testing.T) {
 	Expect(user.Role).Equals(models.RoleAdministrator)
 }
 
+func TestChangeRoleHandler_RevokesSessions(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.AryaStark, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("user_id", mock.AryaStark.ID).
+		ExecutePost(handlers.ChangeUserRole(), fmt.Sprintf(`{ "role": %d }`, models.RoleCollaborator))
+
+	Expect(code).Equals(http.StatusOK)
+	_, err := services.Sessions.GetByID(session.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestRevokeSessionHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session1, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	session2, _ := services.Sessions.Create(mock.JonSnow, "Firefox", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("id", session1.ID).
+		Execute(handlers.RevokeSession())
+
+	Expect(code).Equals(http.StatusOK)
+	_, err := services.Sessions.GetByID(session1.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	_, err = services.Sessions.GetByID(session2.ID)
+	Expect(err).IsNil()
+}
+
+func TestRevokeSessionHandler_AnotherUser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("id", session.ID).
+		Execute(handlers.RevokeSession())
+
+	Expect(code).Equals(http.StatusOK)
+	_, err := services.Sessions.GetByID(session.ID)
+	Expect(err).IsNil()
+}
+
+func TestSignOutEverywhereHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session1, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	session2, _ := services.Sessions.Create(mock.JonSnow, "Firefox", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.SignOutEverywhere(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieAuthName + "=;")
+	active, err := services.Sessions.GetActiveByUser(mock.JonSnow.ID)
+	Expect(err).IsNil()
+	Expect(active).HasLen(0)
+	_, err = services.Sessions.GetByID(session1.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	_, err = services.Sessions.GetByID(session2.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestRevokeUserSessionsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Sessions.Create(mock.AryaStark, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("user_id", mock.AryaStark.ID).
+		Execute(handlers.RevokeUserSessions())
+
+	Expect(code).Equals(http.StatusOK)
+	active, err := services.Sessions.GetActiveByUser(mock.AryaStark.ID)
+	Expect(err).IsNil()
+	Expect(active).HasLen(0)
+}
+
+func TestRevokeUserSessionsHandler_UnknownUser(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("user_id", 999).
+		Execute(handlers.RevokeUserSessions())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
 func TestChangeUserEmailHandler_Valid(t *testing.T) {
 	RegisterT(t)
 
@@ -142,7 +271,9 @@ func TestVerifyChangeEmailKeyHandler_Success(t *testing.T) {
 	}
 
 	services.Tenants.SaveVerificationKey("th3-s3cr3t", 24*time.Hour, request)
-	code, _ := server.
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	code, response := server.
 		OnTenant(mock.DemoTenant).
 		AsUser(mock.JonSnow).
 		WithURL("/change-email/verify?k=th3-s3cr3t").
@@ -158,6 +289,10 @@ func TestVerifyChangeEmailKeyHandler_Success(t *testing.T) {
 	result, err := services.Tenants.FindVerificationByKey(models.EmailVerificationKindChangeEmail, "th3-s3cr3t")
 	Expect(err).IsNil()
 	Expect(result.VerifiedOn).IsNotNil()
+
+	_, err = services.Sessions.GetByID(session.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	expectSessionToken(services, response.Header().Get("Set-Cookie"), web.CookieAuthName+"=", mock.JonSnow.ID)
 }
 
 func TestVerifyChangeEmailKeyHandler_DifferentUser(t *testing.T) {
@@ -185,3 +320,109 @@ func TestVerifyChangeEmailKeyHandler_DifferentUser(t *testing.T) {
 	_, err = services.Users.GetByEmail("jon.stark@got.com")
 	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
 }
+
+func TestChangeRoleHandler_CustomRole(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	support, _ := services.Tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Support",
+		Permissions: []models.Permission{models.PermissionRespond},
+	})
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		AddParam("user_id", mock.AryaStark.ID).
+		ExecutePost(handlers.ChangeUserRole(), fmt.Sprintf(`{ "role": %d, "customRoleId": %d }`, models.RoleCollaborator, support.ID))
+
+	user, _ := services.Users.GetByID(mock.AryaStark.ID)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(user.Role).Equals(models.RoleCollaborator)
+	Expect(user.CustomRole.ID).Equals(support.ID)
+	Expect(user.Can(models.PermissionRespond)).IsTrue()
+	Expect(user.Can(models.PermissionInvite)).IsFalse()
+}
+
+func TestChangeRoleHandler_CannotGrantMissingPermissions(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	managers, _ := services.Tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Managers",
+		Permissions: []models.Permission{models.PermissionManageMembers},
+	})
+	services.Users.AssignCustomRole(mock.AryaStark.ID, managers)
+
+	sansa := &models.User{Name: "Sansa Stark", Email: "sansa.stark@got.com", Tenant: mock.DemoTenant, Role: models.RoleVisitor}
+	services.Users.Register(sansa)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		AddParam("user_id", sansa.ID).
+		ExecutePost(handlers.ChangeUserRole(), fmt.Sprintf(`{ "role": %d }`, models.RoleAdministrator))
+
+	user, _ := services.Users.GetByID(sansa.ID)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(user.Role).Equals(models.RoleVisitor)
+}
+
+func TestUpdateUserSettingsHandler_TranslatedFailures(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	mock.DemoTenant.Locale = "pt-BR"
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.UpdateUserSettings(), `{ }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("O nome é obrigatório.")
+}
+
+func TestUpdateUserSettingsHandler_UserLocaleOverridesTenant(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	mock.DemoTenant.Locale = "pt-BR"
+	mock.AryaStark.Locale = "en"
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.UpdateUserSettings(), `{ }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(response.Body.String()).ContainsSubstring("Name is required.")
+}
+
+func TestUpdateUserSettingsHandler_Locale(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.UpdateUserSettings(), `{ "name": "Arya Stark", "locale": "pt-BR" }`)
+
+	user, _ := services.Users.GetByEmail("arya.stark@got.com")
+	Expect(code).Equals(http.StatusOK)
+	Expect(user.Locale).Equals("pt-BR")
+}
+
+func TestUpdateUserSettingsHandler_InvalidLocale(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.UpdateUserSettings(), `{ "name": "Arya Stark", "locale": "portuguese" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+}
@@ -6,14 +6,14 @@ import (
 	"strings"
 	"time"
 
-	jwtgo "github.com/dgrijalva/jwt-go"
-
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/actions"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/oauth"
+	"github.com/getfider/fider/app/pkg/validate"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/tasks"
 )
@@ -25,8 +25,12 @@ type oauthUserProfile struct {
 }
 
 // OAuthCallback handles OAuth callbacks
-func OAuthCallback(provider string) web.HandlerFunc {
+func OAuthCallback() web.HandlerFunc {
 	return func(c web.Context) error {
+		provider := c.Param("provider")
+		if !oauth.IsBuiltInProvider(provider) && !oauth.IsCustomProvider(provider) {
+			return c.NotFound()
+		}
 
 		redirect := c.QueryParam("state")
 		redirectURL, err := url.ParseRequestURI(redirect)
@@ -39,28 +43,34 @@ func OAuthCallback(provider string) web.HandlerFunc {
 			return c.Redirect(redirect)
 		}
 
+		//Custom providers belong to a tenant, so it must be known before exchanging the code
+		var tenant *models.Tenant
+		if redirectURL.Path != "/signup" {
+			tenant, err = getTenantFromURL(c, redirectURL)
+			if err != nil {
+				return c.Failure(err)
+			}
+			c.Services().SetCurrentTenant(tenant)
+		} else if oauth.IsCustomProvider(provider) {
+			return c.NotFound()
+		}
+
 		oauthUser, err := c.Services().OAuth.GetProfile(c.AuthEndpoint(), provider, code)
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		var claims jwtgo.Claims
-		if redirectURL.Path != "/signup" {
+		//An unverified email could belong to anyone, so it must not link existing users or match allowed domains
+		if !oauthUser.EmailVerified {
+			oauthUser.Email = ""
+		}
 
-			var (
-				tenant *models.Tenant
-				err    error
-			)
-			if env.IsSingleHostMode() {
-				tenant, err = c.Services().Tenants.First()
-			} else {
-				tenant, err = c.Services().Tenants.GetByDomain(stripPort(redirectURL.Host))
-			}
-			if err != nil {
-				return c.Failure(err)
+		var token string
+		if tenant != nil {
+			if tenant.SAML.IsSSOEnforced() {
+				return c.Redirect(c.TenantBaseURL(tenant) + "/sso/saml")
 			}
 
-			c.Services().SetCurrentTenant(tenant)
 			users := c.Services().Users
 
 			user, err := users.GetByProvider(provider, oauthUser.ID.String())
@@ -69,15 +79,23 @@ func OAuthCallback(provider string) web.HandlerFunc {
 			}
 			if err != nil {
 				if errors.Cause(err) == app.ErrNotFound {
-					if tenant.IsPrivate {
+					if tenant.IsPrivate && !tenant.AutoJoin.Allows(oauthUser.Email) {
 						return c.Redirect(c.TenantBaseURL(tenant) + "/not-invited")
 					}
 
+					canJoin, err := canAddUser(c)
+					if err != nil {
+						return c.Failure(err)
+					}
+					if !canJoin {
+						return c.Redirect(c.TenantBaseURL(tenant) + "/limit-reached")
+					}
+
 					user = &models.User{
 						Name:   oauthUser.Name,
 						Tenant: tenant,
 						Email:  oauthUser.Email,
-						Role:   models.RoleVisitor,
+						Role:   newUserRole(tenant, oauthUser.Email),
 						Providers: []*models.UserProvider{
 							&models.UserProvider{
 								UID:  oauthUser.ID.String(),
@@ -103,25 +121,30 @@ func OAuthCallback(provider string) web.HandlerFunc {
 				}
 			}
 
-			claims = models.FiderClaims{
-				UserID:    user.ID,
-				UserName:  user.Name,
-				UserEmail: user.Email,
+			//Tenant domain is only reached after the second factor, so the auth token is not sent yet
+			if requiresTwoFactor(tenant, user) {
+				pending, err := newTwoFactorToken(user, redirectURL.RequestURI())
+				if err != nil {
+					return c.Failure(err)
+				}
+				return c.Redirect(c.TenantBaseURL(tenant) + "/signin/2fa?token=" + url.QueryEscape(pending))
+			}
+
+			if token, err = c.NewAuthToken(user); err != nil {
+				return c.Failure(err)
 			}
 		} else {
-			claims = models.OAuthClaims{
+			token, err = jwt.Encode(models.OAuthClaims{
 				OAuthID:       oauthUser.ID.String(),
 				OAuthProvider: provider,
 				OAuthName:     oauthUser.Name,
 				OAuthEmail:    oauthUser.Email,
+			})
+			if err != nil {
+				return c.Failure(err)
 			}
 		}
 
-		var token string
-		if token, err = jwt.Encode(claims); err != nil {
-			return c.Failure(err)
-		}
-
 		var query = redirectURL.Query()
 		query.Set("token", token)
 		redirectURL.RawQuery = query.Encode()
@@ -137,7 +160,7 @@ func SignInPage() web.HandlerFunc {
 		}
 
 		return c.Page(web.Props{
-			Title: "Sign in",
+			TitleKey: "Sign in",
 		})
 	}
 }
@@ -146,16 +169,51 @@ func SignInPage() web.HandlerFunc {
 func NotInvitedPage() web.HandlerFunc {
 	return func(c web.Context) error {
 		return c.Render(http.StatusForbidden, "not-invited.html", web.Props{
-			Title:       "Not Invited",
-			Description: "We couldn't find your account for your email address.",
+			TitleKey:       "Not Invited",
+			DescriptionKey: "We couldn't find your account for your email address.",
+		})
+	}
+}
+
+// LimitReachedPage renders the page shown to new users of a site that has no room left on its plan
+func LimitReachedPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Render(http.StatusForbidden, "limit-reached.html", web.Props{
+			TitleKey:       "Limit Reached",
+			DescriptionKey: "This site has reached the maximum number of users of its plan.",
 		})
 	}
 }
 
 // SignInByOAuth handles OAuth sign in
-func SignInByOAuth(provider string) web.HandlerFunc {
+func SignInByOAuth() web.HandlerFunc {
 	return func(c web.Context) error {
-		authURL := c.Services().OAuth.GetAuthURL(c.AuthEndpoint(), provider, c.QueryParam("redirect"))
+		provider := c.Param("provider")
+		redirect := c.QueryParam("redirect")
+
+		if oauth.IsCustomProvider(provider) {
+			redirectURL, err := url.ParseRequestURI(redirect)
+			if err != nil {
+				return c.NotFound()
+			}
+
+			tenant, err := getTenantFromURL(c, redirectURL)
+			if err != nil {
+				return c.Failure(err)
+			}
+			c.Services().SetCurrentTenant(tenant)
+		} else if !oauth.IsBuiltInProvider(provider) {
+			return c.NotFound()
+		}
+
+		authURL, err := c.Services().OAuth.GetAuthURL(c.AuthEndpoint(), provider, redirect)
+		if err != nil {
+			if errors.Cause(err) == app.ErrNotFound {
+				return c.NotFound()
+			}
+			return c.Failure(err)
+		}
+
 		return c.Redirect(authURL)
 	}
 }
@@ -168,6 +226,17 @@ func SignInByEmail() web.HandlerFunc {
 			return c.HandleValidation(result)
 		}
 
+		//Administrators can still sign in by email, so that a misconfigured IdP doesn't lock everyone out
+		if c.Tenant().SAML.IsSSOEnforced() {
+			user, err := c.Services().Users.GetByEmail(input.Model.Email)
+			if err != nil && errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+			if user == nil || !user.IsAdministrator() {
+				return c.HandleValidation(validate.Failed([]string{"Please sign in with single sign-on."}))
+			}
+		}
+
 		err := c.Services().Tenants.SaveVerificationKey(input.Model.VerificationKey, 15*time.Minute, input.Model)
 		if err != nil {
 			return c.Failure(err)
@@ -207,7 +276,7 @@ func VerifySignInKey(kind models.EmailVerificationKind) web.HandlerFunc {
 			user, err = c.Services().Users.GetByEmail(result.Email)
 			if err != nil {
 				if errors.Cause(err) == app.ErrNotFound {
-					if c.Tenant().IsPrivate {
+					if c.Tenant().IsPrivate && !c.Tenant().AutoJoin.Allows(result.Email) {
 						return NotInvitedPage()(c)
 					}
 					return Index()(c)
@@ -229,17 +298,16 @@ func VerifySignInKey(kind models.EmailVerificationKind) web.HandlerFunc {
 			return c.NotFound()
 		}
 
-		err = c.Services().Tenants.SetKeyAsVerified(result.Key)
-		if err != nil {
-			return c.Failure(err)
+		if kind != models.EmailVerificationKindSignUp && c.Tenant().SAML.IsSSOEnforced() && !user.IsAdministrator() {
+			return c.Redirect(c.BaseURL() + "/sso/saml")
 		}
 
-		_, err = c.AddAuthCookie(user)
+		err = c.Services().Tenants.SetKeyAsVerified(result.Key)
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		return c.Redirect(c.BaseURL())
+		return signIn(c, user, "")
 	}
 }
 
@@ -256,11 +324,25 @@ func CompleteSignInProfile() web.HandlerFunc {
 			return c.Ok(web.Map{})
 		}
 
+		//Only invited users and those from an allowed domain can join private tenants
+		tenant := c.Tenant()
+		if tenant.IsPrivate && input.Model.Kind == models.EmailVerificationKindSignIn && !tenant.AutoJoin.Allows(input.Model.Email) {
+			return c.HandleValidation(validate.Failed([]string{"Your email address is not allowed to join this site."}))
+		}
+
+		canJoin, err := canAddUser(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !canJoin {
+			return c.HandleValidation(validate.Failed([]string{"This site has reached the maximum number of users of its plan."}))
+		}
+
 		user := &models.User{
 			Name:   input.Model.Name,
 			Email:  input.Model.Email,
-			Tenant: c.Tenant(),
-			Role:   models.RoleVisitor,
+			Tenant: tenant,
+			Role:   newUserRole(tenant, input.Model.Email),
 		}
 		err = c.Services().Users.Register(user)
 		if err != nil {
@@ -272,23 +354,44 @@ func CompleteSignInProfile() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
-		_, err = c.AddAuthCookie(user)
+		//Called by the client, which follows the redirect, so that staff joining automatically still enrol a second factor
+		next, err := startSignIn(c, user, "")
 		if err != nil {
 			return c.Failure(err)
 		}
 
-		return c.Ok(web.Map{})
+		return c.Ok(web.Map{"redirect": next})
 	}
 }
 
 // SignOut remove auth cookies
 func SignOut() web.HandlerFunc {
 	return func(c web.Context) error {
+		if session := c.Session(); session != nil {
+			if err := c.Services().Sessions.Revoke(session.UserID, session.ID); err != nil {
+				return c.Failure(err)
+			}
+		}
 		c.RemoveCookie(web.CookieAuthName)
 		return c.Redirect(c.QueryParam("redirect"))
 	}
 }
 
+//newUserRole returns the role given to users joining a tenant by themselves
+func newUserRole(tenant *models.Tenant, email string) models.Role {
+	if tenant.AutoJoin.Allows(email) {
+		return tenant.AutoJoin.Role
+	}
+	return models.RoleVisitor
+}
+
+func getTenantFromURL(c web.Context, u *url.URL) (*models.Tenant, error) {
+	if env.IsSingleHostMode() {
+		return c.Services().Tenants.First()
+	}
+	return c.Services().Tenants.GetByDomain(stripPort(u.Host))
+}
+
 func stripPort(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
 	if colon == -1 {
@@ -22,8 +22,9 @@ func CheckAvailability() web.HandlerFunc {
 		subdomain := strings.ToLower(c.Param("subdomain"))
 
 		if result := validate.Subdomain(c.Services().Tenants, subdomain); !result.Ok {
+			messages, _ := result.Translate(c.Locale())
 			return c.Ok(web.Map{
-				"message": strings.Join(result.Messages, ","),
+				"message": strings.Join(messages, ","),
 			})
 		}
 
@@ -51,6 +52,14 @@ func CreateTenant() web.HandlerFunc {
 			return c.Failure(err)
 		}
 
+		//Hosted instances can start new tenants on a limited plan
+		if plan := models.GetPlan(env.GetEnvOrDefault("DEFAULT_PLAN", "")); plan != nil {
+			if err := c.Services().Tenants.ChangePlan(tenant.ID, plan.Name); err != nil {
+				return c.Failure(err)
+			}
+			tenant.Plan = plan
+		}
+
 		c.SetTenant(tenant)
 
 		user := &models.User{
@@ -108,8 +117,8 @@ func SignUp() web.HandlerFunc {
 		}
 
 		return c.Page(web.Props{
-			Title:       "Sign up",
-			Description: "Sign up for Fider and let your customers share, vote and discuss on suggestions they have to make your product even better.",
+			TitleKey:       "Sign up",
+			DescriptionKey: "Sign up for Fider and let your customers share, vote and discuss on suggestions they have to make your product even better.",
 		})
 	}
 }
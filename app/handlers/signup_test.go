@@ -53,6 +53,19 @@ func TestCheckAvailabilityHandler_InvalidSubdomain(t *testing.T) {
 	Expect(response.String("message")).IsNotEmpty()
 }
 
+func TestCheckAvailabilityHandler_AcceptLanguage(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, response := server.
+		AddParam("subdomain", "signup").
+		AddHeader("Accept-Language", "pt-BR,pt;q=0.9,en;q=0.8").
+		ExecuteAsJSON(handlers.CheckAvailability())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.String("message")).Equals("signup é um subdomínio reservado.")
+}
+
 func TestCheckAvailabilityHandler_UnavailableSubdomain(t *testing.T) {
 	RegisterT(t)
 
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// SignInByJWT signs in users with a token issued by a trusted application
+func SignInByJWT() web.HandlerFunc {
+	return func(c web.Context) error {
+		settings := c.Tenant().JWTSSO
+		if !settings.IsEnabled {
+			return c.NotFound()
+		}
+
+		claims, err := jwt.DecodeSSOClaims(c.QueryParam("token"), settings)
+		if err != nil {
+			c.Logger().Warnf("Invalid SSO token: %s", err.Error())
+			return c.Unauthorized()
+		}
+
+		email := strings.ToLower(strings.TrimSpace(claims.Email))
+		if claims.Subject == "" || !validate.Email(email).Ok {
+			c.Logger().Warnf("SSO token has no subject or valid email: '%s'", email)
+			return c.Unauthorized()
+		}
+
+		avatarURL := strings.TrimSpace(claims.AvatarURL)
+		if avatarURL != "" && (!validate.URL(avatarURL).Ok || len(avatarURL) > 500) {
+			avatarURL = ""
+		}
+
+		ok, err := c.Services().Tenants.UseSSOToken(claims.Id, time.Unix(claims.ExpiresAt, 0))
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !ok {
+			c.Logger().Warnf("SSO token '%s' has already been used", claims.Id)
+			return c.Unauthorized()
+		}
+
+		name := strings.TrimSpace(claims.Name)
+		if name == "" {
+			name = strings.Split(email, "@")[0]
+		}
+
+		provider := &models.UserProvider{
+			UID:  claims.Subject,
+			Name: jwt.SSOProvider,
+		}
+
+		users := c.Services().Users
+		user, err := users.GetByEmail(email)
+		if err != nil {
+			if errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+
+			canJoin, err := canAddUser(c)
+			if err != nil {
+				return c.Failure(err)
+			}
+			if !canJoin {
+				return LimitReachedPage()(c)
+			}
+
+			//Role is only a hint for new users, existing users are managed on Fider
+			user = &models.User{
+				Name:      name,
+				Email:     email,
+				Tenant:    c.Tenant(),
+				Role:      ssoRole(claims.Role),
+				AvatarURL: avatarURL,
+				Providers: []*models.UserProvider{provider},
+			}
+			if err = users.Register(user); err != nil {
+				return c.Failure(err)
+			}
+		} else {
+			//Applications that don't send an avatar keep the one the user already has
+			if avatarURL != "" && user.AvatarURL != avatarURL {
+				if err = users.ChangeAvatarURL(user.ID, avatarURL); err != nil {
+					return c.Failure(err)
+				}
+				user.AvatarURL = avatarURL
+			}
+			if !user.HasProvider(jwt.SSOProvider) {
+				if err = users.RegisterProvider(user.ID, provider); err != nil {
+					return c.Failure(err)
+				}
+			}
+		}
+
+		return signIn(c, user, c.QueryParam("redirect"))
+	}
+}
+
+//ssoRole returns the role of a new user based on the hint sent by the trusted application
+func ssoRole(hint string) models.Role {
+	switch strings.ToLower(hint) {
+	case "administrator":
+		return models.RoleAdministrator
+	case "collaborator":
+		return models.RoleCollaborator
+	}
+	return models.RoleVisitor
+}
+
+//jwtSSOSettings returns the trusted application settings without exposing the secret
+func jwtSSOSettings(tenant *models.Tenant) web.Map {
+	return web.Map{
+		"isEnabled": tenant.JWTSSO.IsEnabled,
+		"publicKey": tenant.JWTSSO.PublicKey,
+		"hasSecret": tenant.JWTSSO.Secret != "",
+	}
+}
+
+// UpdateJWTSSOSettings updates current tenant's trusted application settings
+func UpdateJWTSSOSettings() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantJWTSSOSettings)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateJWTSSOSettings(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(jwtSSOSettings(c.Tenant()))
+	}
+}
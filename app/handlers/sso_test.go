@@ -0,0 +1,177 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+var ssoSecret = "a-shared-secret-with-more-than-32-characters"
+
+func enableJWTSSO(services *app.Services) {
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.Tenants.UpdateJWTSSOSettings(&models.UpdateTenantJWTSSOSettings{
+		IsEnabled: true,
+		Secret:    ssoSecret,
+	})
+}
+
+func ssoToken(claims *models.SSOClaims) string {
+	if claims.ExpiresAt == 0 {
+		claims.ExpiresAt = time.Now().Add(time.Minute).Unix()
+	}
+	token, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, claims).SignedString([]byte(ssoSecret))
+	return token
+}
+
+func TestSignInByJWTHandler_Disabled(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + ssoToken(&models.SSOClaims{Email: "sansa.stark@got.com"})).
+		Execute(handlers.SignInByJWT())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestSignInByJWTHandler_NewUser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableJWTSSO(services)
+
+	token := ssoToken(&models.SSOClaims{
+		Email:          "sansa.stark@got.com",
+		Name:           "Sansa Stark",
+		AvatarURL:      "https://got.com/sansa.png",
+		Role:           "collaborator",
+		StandardClaims: jwtgo.StandardClaims{Subject: "123", Id: "token-1"},
+	})
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + token + "&redirect=/ideas/1").
+		Execute(handlers.SignInByJWT())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io/ideas/1")
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieAuthName + "=")
+
+	user, err := services.Users.GetByEmail("sansa.stark@got.com")
+	Expect(err).IsNil()
+	Expect(user.Name).Equals("Sansa Stark")
+	Expect(user.Role).Equals(models.RoleCollaborator)
+	Expect(user.AvatarURL).Equals("https://got.com/sansa.png")
+	Expect(user.HasProvider(jwt.SSOProvider)).IsTrue()
+}
+
+func TestSignInByJWTHandler_ExistingUser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableJWTSSO(services)
+
+	token := ssoToken(&models.SSOClaims{
+		Email:          "arya.stark@got.com",
+		Role:           "administrator",
+		StandardClaims: jwtgo.StandardClaims{Subject: "456", Id: "token-1"},
+	})
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + token + "&redirect=http://evil.com").
+		Execute(handlers.SignInByJWT())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io/")
+	Expect(mock.AryaStark.Role).Equals(models.RoleVisitor)
+	Expect(mock.AryaStark.HasProvider(jwt.SSOProvider)).IsTrue()
+}
+
+func TestSignInByJWTHandler_ExistingUser_WithoutAvatar(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableJWTSSO(services)
+	services.Users.ChangeAvatarURL(mock.AryaStark.ID, "https://got.com/arya.png")
+
+	token := ssoToken(&models.SSOClaims{
+		Email:          "arya.stark@got.com",
+		StandardClaims: jwtgo.StandardClaims{Subject: "456", Id: "token-1"},
+	})
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SignInByJWT())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	user, err := services.Users.GetByEmail("arya.stark@got.com")
+	Expect(err).IsNil()
+	Expect(user.AvatarURL).Equals("https://got.com/arya.png")
+}
+
+func TestSignInByJWTHandler_Expired(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableJWTSSO(services)
+
+	token := ssoToken(&models.SSOClaims{
+		Email:          "sansa.stark@got.com",
+		StandardClaims: jwtgo.StandardClaims{Subject: "123", Id: "token-1", ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+	})
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SignInByJWT())
+
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestSignInByJWTHandler_Replayed(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableJWTSSO(services)
+	token := ssoToken(&models.SSOClaims{
+		Email:          "sansa.stark@got.com",
+		StandardClaims: jwtgo.StandardClaims{Subject: "123", Id: "token-1"},
+	})
+	services.Tenants.UseSSOToken("token-1", time.Now().Add(time.Minute))
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/sso?token=" + token).
+		Execute(handlers.SignInByJWT())
+
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestUpdateJWTSSOSettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePostAsJSON(
+			handlers.UpdateJWTSSOSettings(),
+			`{ "isEnabled": true, "secret": "`+ssoSecret+`" }`,
+		)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.Contains("secret")).IsFalse()
+	Expect(mock.DemoTenant.JWTSSO.IsEnabled).IsTrue()
+	Expect(mock.DemoTenant.JWTSSO.Secret).Equals(ssoSecret)
+}
@@ -15,7 +15,7 @@ func ManageTags() web.HandlerFunc {
 		}
 
 		return c.Page(web.Props{
-			Title: "Manage Tags · Site Settings",
+			TitleKey: "Manage Tags · Site Settings",
 			Data: web.Map{
 				"tags": tags,
 			},
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/actions"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/totp"
+	"github.com/getfider/fider/app/pkg/validate"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+const (
+	twoFactorTokenLifetime = 10 * time.Minute
+	twoFactorFailureWindow = 15 * time.Minute
+	twoFactorMaxFailures   = 5
+	recoveryCodesCount     = 10
+)
+
+//requiresTwoFactor returns true if user must confirm sign in with a second factor
+func requiresTwoFactor(tenant *models.Tenant, user *models.User) bool {
+	return user.IsTOTPEnabled || (tenant.IsTwoFactorRequired && user.IsCollaborator())
+}
+
+//newTwoFactorToken returns the token of a sign in waiting for a second factor
+func newTwoFactorToken(user *models.User, redirect string) (string, error) {
+	return jwt.Encode(models.TwoFactorClaims{
+		UserID:   user.ID,
+		Redirect: redirect,
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: time.Now().Add(twoFactorTokenLifetime).Unix(),
+		},
+	})
+}
+
+//signIn adds the auth cookie of given user and redirects to given relative address.
+//Users that need a second factor are sent to confirm it before getting the auth cookie.
+func signIn(c web.Context, user *models.User, redirect string) error {
+	next, err := startSignIn(c, user, redirect)
+	if err != nil {
+		return c.Failure(err)
+	}
+	return c.Redirect(next)
+}
+
+//startSignIn adds either the auth cookie or the pending second factor cookie of given user
+//and returns the address the user must be sent to next
+func startSignIn(c web.Context, user *models.User, redirect string) (string, error) {
+	if requiresTwoFactor(c.Tenant(), user) {
+		token, err := newTwoFactorToken(user, redirect)
+		if err != nil {
+			return "", err
+		}
+		c.AddCookie(web.CookieTwoFactorName, token, time.Now().Add(twoFactorTokenLifetime))
+		return c.BaseURL() + "/signin/2fa", nil
+	}
+
+	if _, err := c.AddAuthCookie(user); err != nil {
+		return "", err
+	}
+	return redirectURL(c, redirect), nil
+}
+
+//redirectURL returns the absolute address of given relative redirect, which is the home page when empty
+func redirectURL(c web.Context, redirect string) string {
+	if redirect == "" {
+		return c.BaseURL()
+	}
+	return c.BaseURL() + relativeRedirect(redirect)
+}
+
+//pendingTwoFactor returns the user and claims of the sign in waiting for a second factor, if any
+func pendingTwoFactor(c web.Context) (*models.User, *models.TwoFactorClaims, error) {
+	cookie, err := c.Cookie(web.CookieTwoFactorName)
+	if err != nil {
+		if errors.Cause(err) == http.ErrNoCookie {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	claims, err := jwt.DecodeTwoFactorClaims(cookie.Value)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	user, err := c.Services().Users.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Cause(err) == app.ErrNotFound {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	if user.Tenant.ID != c.Tenant().ID {
+		return nil, nil, nil
+	}
+	return user, claims, nil
+}
+
+//checkTwoFactorCode returns true if code is the current code of user's authenticator app or one of the unused recovery codes.
+//Codes are not checked after too many wrong attempts, so that they can't be guessed.
+func checkTwoFactorCode(c web.Context, user *models.User, code string) (bool, error) {
+	users := c.Services().Users
+	since := time.Now().Add(-twoFactorFailureWindow)
+
+	failures, err := users.CountTwoFactorFailures(user.ID, since)
+	if err != nil {
+		return false, err
+	}
+	if failures >= twoFactorMaxFailures {
+		return false, nil
+	}
+
+	ok := false
+	if step, valid := totp.Validate(user.TOTPSecret, code, time.Now()); valid {
+		ok, err = users.UseTOTPStep(user.ID, step)
+	} else {
+		ok, err = users.UseRecoveryCode(user.ID, totp.HashRecoveryCode(code))
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !ok {
+		if _, err = users.AddTwoFactorFailure(user.ID, since); err != nil {
+			return false, err
+		}
+	}
+	return ok, nil
+}
+
+//enrollTOTP enables the secret being enrolled by user if code matches it and returns a new set of recovery codes
+func enrollTOTP(c web.Context, user *models.User, code string) ([]string, bool, error) {
+	if user.TOTPSecret == "" {
+		return nil, false, nil
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, false, nil
+	}
+
+	recoveryCodes, err := newRecoveryCodes(c, user)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, err = c.Services().Users.UseTOTPStep(user.ID, step); err != nil {
+		return nil, false, err
+	}
+
+	user.IsTOTPEnabled = true
+	return recoveryCodes, true, nil
+}
+
+//newRecoveryCodes replaces the recovery codes of given user
+func newRecoveryCodes(c web.Context, user *models.User) ([]string, error) {
+	recoveryCodes, err := totp.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = totp.HashRecoveryCode(code)
+	}
+
+	if err = c.Services().Users.EnableTOTP(user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+//newTOTPSecret generates and stores a new secret to be enrolled by given user
+func newTOTPSecret(c web.Context, user *models.User) (web.Map, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.Services().Users.SetTOTPSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	return web.Map{
+		"secret": secret,
+		"uri":    totp.URI(secret, c.Tenant().Name, user.Email),
+	}, nil
+}
+
+//invalidTwoFactorCode returns the validation failure of a wrong code
+func invalidTwoFactorCode(c web.Context) error {
+	result := validate.Success()
+	result.AddFieldFailure("code", "Invalid code.")
+	return c.HandleValidation(result)
+}
+
+// TwoFactorPage renders the page where users confirm their sign in with a second factor
+func TwoFactorPage() web.HandlerFunc {
+	return func(c web.Context) error {
+		//Sign ins from OAuth providers happen on another domain, so the pending token comes by query string
+		if token := c.QueryParam("token"); token != "" {
+			if claims, err := jwt.DecodeTwoFactorClaims(token); err == nil {
+				c.AddCookie(web.CookieTwoFactorName, token, time.Unix(claims.ExpiresAt, 0))
+			}
+			return c.Redirect(c.BaseURL() + "/signin/2fa")
+		}
+
+		user, claims, err := pendingTwoFactor(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if user == nil {
+			return c.Redirect(c.BaseURL())
+		}
+
+		if !requiresTwoFactor(c.Tenant(), user) {
+			c.RemoveCookie(web.CookieTwoFactorName)
+			return signIn(c, user, claims.Redirect)
+		}
+
+		data := web.Map{
+			"isEnrolling": !user.IsTOTPEnabled,
+		}
+
+		if !user.IsTOTPEnabled {
+			enrollment, err := newTOTPSecret(c, user)
+			if err != nil {
+				return c.Failure(err)
+			}
+			data["enrollment"] = enrollment
+		}
+
+		return c.Page(web.Props{
+			TitleKey: "Two-factor authentication",
+			Data:     data,
+		})
+	}
+}
+
+// VerifyTwoFactor completes a sign in waiting for a second factor
+func VerifyTwoFactor() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.TwoFactorCode)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		user, claims, err := pendingTwoFactor(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if user == nil {
+			return c.HandleValidation(validate.Failed([]string{"Your sign in has expired. Please sign in again."}))
+		}
+
+		var ok bool
+		var recoveryCodes []string
+		if user.IsTOTPEnabled {
+			ok, err = checkTwoFactorCode(c, user, input.Model.Code)
+		} else {
+			recoveryCodes, ok, err = enrollTOTP(c, user, input.Model.Code)
+		}
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !ok {
+			return invalidTwoFactorCode(c)
+		}
+
+		c.RemoveCookie(web.CookieTwoFactorName)
+		if _, err = c.AddAuthCookie(user); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"redirect":      redirectURL(c, claims.Redirect),
+			"recoveryCodes": recoveryCodes,
+		})
+	}
+}
+
+//twoFactorSettings returns the two-factor state of current user
+func twoFactorSettings(c web.Context) web.Map {
+	return web.Map{
+		"isEnabled":  c.User().IsTOTPEnabled,
+		"isRequired": c.Tenant().IsTwoFactorRequired && c.User().IsCollaborator(),
+	}
+}
+
+// SetupTwoFactor generates a new secret for current user to register on an authenticator app
+func SetupTwoFactor() web.HandlerFunc {
+	return func(c web.Context) error {
+		if c.User().IsTOTPEnabled {
+			return c.HandleValidation(validate.Failed([]string{"Two-factor authentication is already enabled."}))
+		}
+
+		enrollment, err := newTOTPSecret(c, c.User())
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(enrollment)
+	}
+}
+
+// EnableTwoFactor enables the secret being enrolled by current user
+func EnableTwoFactor() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.TwoFactorCode)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		if c.User().IsTOTPEnabled {
+			return c.HandleValidation(validate.Failed([]string{"Two-factor authentication is already enabled."}))
+		}
+
+		recoveryCodes, ok, err := enrollTOTP(c, c.User(), input.Model.Code)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !ok {
+			return invalidTwoFactorCode(c)
+		}
+
+		return c.Ok(web.Map{
+			"recoveryCodes": recoveryCodes,
+		})
+	}
+}
+
+// DisableTwoFactor disables two-factor authentication of current user
+func DisableTwoFactor() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.TwoFactorCode)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		user := c.User()
+		if !user.IsTOTPEnabled {
+			return c.Ok(web.Map{})
+		}
+
+		if c.Tenant().IsTwoFactorRequired && user.IsCollaborator() {
+			return c.HandleValidation(validate.Failed([]string{"Two-factor authentication is required for staff members of this site."}))
+		}
+
+		ok, err := checkTwoFactorCode(c, user, input.Model.Code)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !ok {
+			return invalidTwoFactorCode(c)
+		}
+
+		if err = c.Services().Users.DisableTOTP(user.ID); err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of current user
+func RegenerateRecoveryCodes() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.TwoFactorCode)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		user := c.User()
+		if !user.IsTOTPEnabled {
+			return c.HandleValidation(validate.Failed([]string{"Two-factor authentication is not enabled."}))
+		}
+
+		ok, err := checkTwoFactorCode(c, user, input.Model.Code)
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !ok {
+			return invalidTwoFactorCode(c)
+		}
+
+		recoveryCodes, err := newRecoveryCodes(c, user)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"recoveryCodes": recoveryCodes,
+		})
+	}
+}
+
+// UpdateTwoFactorSettings updates whether staff members of current tenant must use two-factor authentication
+func UpdateTwoFactorSettings() web.HandlerFunc {
+	return func(c web.Context) error {
+		input := new(actions.UpdateTenantTwoFactorSettings)
+		if result := c.BindTo(input); !result.Ok {
+			return c.HandleValidation(result)
+		}
+
+		err := c.Services().Tenants.UpdateTwoFactorSettings(input.Model)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{})
+	}
+}
@@ -0,0 +1,411 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/jsonq"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/oauth"
+	"github.com/getfider/fider/app/pkg/totp"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func enableTOTP(services *app.Services, user *models.User) string {
+	services.SetCurrentTenant(user.Tenant)
+	secret, _ := totp.GenerateSecret()
+	services.Users.SetTOTPSecret(user.ID, secret)
+	services.Users.EnableTOTP(user.ID, []string{totp.HashRecoveryCode("abcd-efgh")})
+	return secret
+}
+
+func currentCode(secret string) string {
+	code, _ := totp.Code(secret, totp.Step(time.Now()))
+	return code
+}
+
+func pendingTwoFactorToken(user *models.User) string {
+	token, _ := jwt.Encode(&models.TwoFactorClaims{
+		UserID:   user.ID,
+		Redirect: "/ideas/1",
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: time.Now().Add(10 * time.Minute).Unix(),
+		},
+	})
+	return token
+}
+
+func findCookie(response http.ResponseWriter, name string) string {
+	for _, value := range response.Header()["Set-Cookie"] {
+		if strings.HasPrefix(value, name+"=") {
+			return value
+		}
+	}
+	return ""
+}
+
+func TestVerifySignInKeyHandler_TwoFactorRequired(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.DemoTenant.IsTwoFactorRequired = true
+
+	e := &models.SignInByEmail{Email: "jon.snow@got.com"}
+	services.Tenants.SaveVerificationKey("1234567890", 15*time.Minute, e)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/verify?k=1234567890").
+		Execute(handlers.VerifySignInKey(models.EmailVerificationKindSignIn))
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io/signin/2fa")
+	Expect(findCookie(response, web.CookieAuthName)).Equals("")
+
+	cookie := findCookie(response, web.CookieTwoFactorName)
+	token := strings.Split(strings.TrimPrefix(cookie, web.CookieTwoFactorName+"="), ";")[0]
+	claims, err := jwt.DecodeTwoFactorClaims(token)
+	Expect(err).IsNil()
+	Expect(claims.UserID).Equals(mock.JonSnow.ID)
+}
+
+func TestVerifySignInKeyHandler_TwoFactorRequired_Visitor(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.DemoTenant.IsTwoFactorRequired = true
+
+	e := &models.SignInByEmail{Email: "arya.stark@got.com"}
+	services.Tenants.SaveVerificationKey("1234567890", 15*time.Minute, e)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/verify?k=1234567890").
+		Execute(handlers.VerifySignInKey(models.EmailVerificationKindSignIn))
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io")
+	expectSessionToken(services, response.Header().Get("Set-Cookie"), web.CookieAuthName+"=", mock.AryaStark.ID)
+}
+
+func TestCallbackHandler_TwoFactorEnabled(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableTOTP(services, mock.JonSnow)
+
+	code, response := server.
+		WithURL("http://login.test.fider.io/oauth/callback?state=http://demo.test.fider.io/ideas/1&code=123").
+		AddParam("provider", oauth.FacebookProvider).
+		Execute(handlers.OAuthCallback())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	location := response.Header().Get("Location")
+	Expect(strings.HasPrefix(location, "http://demo.test.fider.io/signin/2fa?token=")).IsTrue()
+
+	claims, err := jwt.DecodeTwoFactorClaims(strings.TrimPrefix(location, "http://demo.test.fider.io/signin/2fa?token="))
+	Expect(err).IsNil()
+	Expect(claims.UserID).Equals(mock.JonSnow.ID)
+	Expect(claims.Redirect).Equals("/ideas/1")
+}
+
+func TestTwoFactorPageHandler_TokenFromQuery(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	token := pendingTwoFactorToken(mock.JonSnow)
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/2fa?token=" + token).
+		Execute(handlers.TwoFactorPage())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io/signin/2fa")
+	Expect(strings.HasPrefix(findCookie(response, web.CookieTwoFactorName), web.CookieTwoFactorName+"="+token+";")).IsTrue()
+}
+
+func TestTwoFactorPageHandler_NoPendingSignIn(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/signin/2fa").
+		Execute(handlers.TwoFactorPage())
+
+	Expect(code).Equals(http.StatusTemporaryRedirect)
+	Expect(response.Header().Get("Location")).Equals("http://demo.test.fider.io")
+}
+
+func TestTwoFactorPageHandler_Enrolling(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	mock.DemoTenant.IsTwoFactorRequired = true
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		Execute(handlers.TwoFactorPage())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(mock.JonSnow.TOTPSecret).IsNotEmpty()
+	Expect(mock.JonSnow.IsTOTPEnabled).IsFalse()
+}
+
+func TestVerifyTwoFactorHandler_ValidCode(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	secret := enableTOTP(services, mock.JonSnow)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/api/signin/2fa").
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(jsonq.New(response.Body.String()).String("redirect")).Equals("http://demo.test.fider.io/ideas/1")
+	Expect(findCookie(response, web.CookieTwoFactorName)).ContainsSubstring("Max-Age=0")
+	expectSessionToken(services, findCookie(response, web.CookieAuthName), web.CookieAuthName+"=", mock.JonSnow.ID)
+}
+
+func TestVerifyTwoFactorHandler_UsedCode(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	secret := enableTOTP(services, mock.JonSnow)
+	services.Users.UseTOTPStep(mock.JonSnow.ID, totp.Step(time.Now().Add(30*time.Second)))
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(findCookie(response, web.CookieAuthName)).Equals("")
+}
+
+func TestVerifyTwoFactorHandler_RecoveryCode(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableTOTP(services, mock.JonSnow)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "ABCD-EFGH" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	expectSessionToken(services, findCookie(response, web.CookieAuthName), web.CookieAuthName+"=", mock.JonSnow.ID)
+
+	ok, err := services.Users.UseRecoveryCode(mock.JonSnow.ID, totp.HashRecoveryCode("abcd-efgh"))
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+}
+
+func TestVerifyTwoFactorHandler_InvalidCode(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableTOTP(services, mock.JonSnow)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "000000" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(findCookie(response, web.CookieAuthName)).Equals("")
+
+	failures, err := services.Users.CountTwoFactorFailures(mock.JonSnow.ID, time.Now().Add(-1*time.Minute))
+	Expect(err).IsNil()
+	Expect(failures).Equals(1)
+}
+
+func TestVerifyTwoFactorHandler_TooManyFailures(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	secret := enableTOTP(services, mock.JonSnow)
+	for i := 0; i < 5; i++ {
+		services.Users.AddTwoFactorFailure(mock.JonSnow.ID, time.Now().Add(-15*time.Minute))
+	}
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(findCookie(response, web.CookieAuthName)).Equals("")
+}
+
+func TestVerifyTwoFactorHandler_AuthTokenIsNotAccepted(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	secret := enableTOTP(services, mock.JonSnow)
+	authToken, _ := jwt.Encode(&models.FiderClaims{UserID: mock.JonSnow.ID})
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, authToken).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(findCookie(response, web.CookieAuthName)).Equals("")
+}
+
+func TestVerifyTwoFactorHandler_Enroll(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.DemoTenant.IsTwoFactorRequired = true
+	services.SetCurrentTenant(mock.DemoTenant)
+	secret, _ := totp.GenerateSecret()
+	services.Users.SetTOTPSecret(mock.JonSnow.ID, secret)
+
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieTwoFactorName, pendingTwoFactorToken(mock.JonSnow)).
+		ExecutePost(handlers.VerifyTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(jsonq.New(response.Body.String()).Contains("recoveryCodes")).IsTrue()
+	Expect(mock.JonSnow.IsTOTPEnabled).IsTrue()
+	expectSessionToken(services, findCookie(response, web.CookieAuthName), web.CookieAuthName+"=", mock.JonSnow.ID)
+}
+
+func TestSetupTwoFactorHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePostAsJSON(handlers.SetupTwoFactor(), `{}`)
+
+	Expect(code).Equals(http.StatusOK)
+	secret := query.String("secret")
+	Expect(secret).Equals(mock.AryaStark.TOTPSecret)
+	Expect(query.String("uri")).ContainsSubstring("secret=" + secret)
+	Expect(mock.AryaStark.IsTOTPEnabled).IsFalse()
+}
+
+func TestEnableTwoFactorHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	secret, _ := totp.GenerateSecret()
+	services.Users.SetTOTPSecret(mock.AryaStark.ID, secret)
+
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePostAsJSON(handlers.EnableTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.Contains("recoveryCodes")).IsTrue()
+	Expect(mock.AryaStark.IsTOTPEnabled).IsTrue()
+}
+
+func TestEnableTwoFactorHandler_InvalidCode(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	secret, _ := totp.GenerateSecret()
+	services.Users.SetTOTPSecret(mock.AryaStark.ID, secret)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.EnableTwoFactor(), `{ "code": "000000" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(mock.AryaStark.IsTOTPEnabled).IsFalse()
+}
+
+func TestDisableTwoFactorHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	secret := enableTOTP(services, mock.AryaStark)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.DisableTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(mock.AryaStark.IsTOTPEnabled).IsFalse()
+	Expect(mock.AryaStark.TOTPSecret).Equals("")
+}
+
+func TestDisableTwoFactorHandler_InvalidCode(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	enableTOTP(services, mock.AryaStark)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.DisableTwoFactor(), `{ "code": "000000" }`)
+
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(mock.AryaStark.IsTOTPEnabled).IsTrue()
+}
+
+func TestDisableTwoFactorHandler_RequiredForStaff(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	mock.DemoTenant.IsTwoFactorRequired = true
+	secret := enableTOTP(services, mock.JonSnow)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.DisableTwoFactor(), `{ "code": "`+currentCode(secret)+`" }`)
+	Expect(code).Equals(http.StatusBadRequest)
+	Expect(mock.JonSnow.IsTOTPEnabled).IsTrue()
+}
+
+func TestUpdateTwoFactorSettingsHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		ExecutePost(handlers.UpdateTwoFactorSettings(), `{ "isRequired": true }`)
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(mock.DemoTenant.IsTwoFactorRequired).IsTrue()
+}
+
+func TestUpdateTwoFactorSettingsHandler_NonAdministrator(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		ExecutePost(handlers.UpdateTwoFactorSettings(), `{ "isRequired": true }`)
+
+	Expect(code).Equals(http.StatusForbidden)
+	Expect(mock.DemoTenant.IsTwoFactorRequired).IsFalse()
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/markdown"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+var oEmbedPathRegex = regexp.MustCompile(`^/ideas/(\d+)(/.*)?$`)
+
+//widgetTokenLifetime is how long the widget stays signed in before it has to open the popup again
+const widgetTokenLifetime = 24 * time.Hour
+
+// Widget is the minimal page embedded by other websites to post, search and support ideas
+func Widget() web.HandlerFunc {
+	return func(c web.Context) error {
+		ideas := make([]*models.Idea, 0)
+
+		//Private sites only list ideas once the widget has signed in through the popup
+		if !c.Tenant().IsPrivate || c.IsAuthenticated() {
+			var err error
+			ideas, err = c.Services().Ideas.Search(c.QueryParam("q"), "trending", []string{})
+			if err != nil {
+				return c.Failure(err)
+			}
+		}
+
+		c.AllowFraming(c.Tenant().EmbedOrigins)
+		return c.Page(web.Props{
+			TitleKey: "Feedback",
+			Data: web.Map{
+				"ideas": ideas,
+			},
+		})
+	}
+}
+
+// WidgetIdea is the minimal page of a single idea, which is what oEmbed consumers embed
+func WidgetIdea() web.HandlerFunc {
+	return func(c web.Context) error {
+		if c.Tenant().IsPrivate && !c.IsAuthenticated() {
+			return c.NotFound()
+		}
+
+		number, err := c.ParamAsInt("number")
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		idea, err := c.Services().Ideas.GetByNumber(number)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		c.AllowFraming(c.Tenant().EmbedOrigins)
+		return c.Page(web.Props{
+			Title:       idea.Title,
+			Description: markdown.PlainText(idea.Description),
+			Data: web.Map{
+				"idea": idea,
+			},
+		})
+	}
+}
+
+// WidgetSignIn is opened by the widget as a popup, where cookies are first-party.
+// Once the user is signed in, the page asks WidgetToken for a token to hand back to the widget.
+func WidgetSignIn() web.HandlerFunc {
+	return func(c web.Context) error {
+		return c.Page(web.Props{
+			TitleKey: "Sign in",
+			Data: web.Map{
+				"signedIn": c.IsAuthenticated(),
+			},
+		})
+	}
+}
+
+// WidgetToken issues a short-lived token of current session, which the widget sends as a Bearer token.
+// Only the sign in popup can request it, as widget tokens can't be exchanged for new ones.
+func WidgetToken() web.HandlerFunc {
+	return func(c web.Context) error {
+		if !c.IsAuthenticated() || c.Session() == nil || c.Request.Header.Get("Authorization") != "" {
+			return c.Unauthorized()
+		}
+
+		user := c.User()
+		token, err := jwt.Encode(models.FiderClaims{
+			UserID:    user.ID,
+			UserName:  user.Name,
+			UserEmail: user.Email,
+			SessionID: c.Session().ID,
+			Scope:     models.ClaimsScopeWidget,
+			StandardClaims: jwtgo.StandardClaims{
+				ExpiresAt: time.Now().Add(widgetTokenLifetime).Unix(),
+			},
+		})
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		return c.Ok(web.Map{
+			"token": token,
+		})
+	}
+}
+
+// OEmbed returns the oEmbed representation of an idea, so that its link can be embedded on other websites
+func OEmbed() web.HandlerFunc {
+	return func(c web.Context) error {
+		if format := c.QueryParam("format"); format != "" && format != "json" {
+			return c.NoContent(http.StatusNotImplemented)
+		}
+
+		u, err := url.Parse(c.QueryParam("url"))
+		if err != nil || !strings.EqualFold(u.Host, c.Request.Host) {
+			return c.NotFound()
+		}
+
+		matches := oEmbedPathRegex.FindStringSubmatch(u.Path)
+		if matches == nil {
+			return c.NotFound()
+		}
+
+		number, _ := strconv.Atoi(matches[1])
+		idea, err := c.Services().Ideas.GetByNumber(number)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		width := oEmbedSize(c.QueryParam("maxwidth"), 500)
+		height := oEmbedSize(c.QueryParam("maxheight"), 200)
+		src := fmt.Sprintf("%s/widget/ideas/%d", c.BaseURL(), idea.Number)
+
+		return c.Ok(web.Map{
+			"version":       "1.0",
+			"type":          "rich",
+			"title":         idea.Title,
+			"provider_name": c.Tenant().Name,
+			"provider_url":  c.BaseURL(),
+			"width":         width,
+			"height":        height,
+			"html":          fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0"></iframe>`, html.EscapeString(src), width, height),
+		})
+	}
+}
+
+func oEmbedSize(max string, size int) int {
+	if value, err := strconv.Atoi(max); err == nil && value > 0 && value < size {
+		return value
+	}
+	return size
+}
+
+func oEmbedDiscoveryURL(c web.Context, idea *models.Idea) string {
+	ideaURL := fmt.Sprintf("%s/ideas/%d/%s", c.BaseURL(), idea.Number, idea.Slug)
+	return fmt.Sprintf("%s/oembed?format=json&url=%s", c.BaseURL(), url.QueryEscape(ideaURL))
+}
@@ -0,0 +1,189 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/handlers"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+)
+
+func TestWidgetHandler(t *testing.T) {
+	RegisterT(t)
+
+	tenant := *mock.DemoTenant
+	tenant.EmbedOrigins = []string{"https://www.got.com"}
+
+	server, _ := mock.NewServer()
+	code, response := server.
+		OnTenant(&tenant).
+		Execute(handlers.Widget())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Header().Get("Content-Security-Policy")).ContainsSubstring("frame-ancestors 'self' https://www.got.com")
+}
+
+func TestWidgetHandler_PrivateTenant(t *testing.T) {
+	RegisterT(t)
+
+	tenant := *mock.DemoTenant
+	tenant.IsPrivate = true
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(&tenant)
+	services.SetCurrentUser(mock.JonSnow)
+	services.Ideas.Add("My Idea", "My Idea Description")
+	services.SetCurrentUser(nil)
+
+	code, response := server.
+		OnTenant(&tenant).
+		Execute(handlers.Widget())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(strings.Contains(response.Body.String(), "My Idea Description")).IsFalse()
+}
+
+func TestWidgetIdeaHandler_PrivateIdea(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.AryaStark)
+	idea, _ := services.Ideas.Add("My confidential idea", "Secret details")
+	services.Ideas.SetPrivacy(idea, true)
+	services.SetCurrentUser(nil)
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddParam("number", idea.Number).
+		Execute(handlers.WidgetIdea())
+
+	Expect(code).Equals(http.StatusNotFound)
+}
+
+func TestWidgetSignInHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	code, response := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		Execute(handlers.WidgetSignIn())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(response.Body.String()).ContainsSubstring(`set("signedIn",  true )`)
+	Expect(strings.Contains(response.Body.String(), `set("token"`)).IsFalse()
+
+	sessions, err := services.Sessions.GetActiveByUser(mock.AryaStark.ID)
+	Expect(err).IsNil()
+	Expect(sessions).HasLen(0)
+}
+
+func TestWidgetTokenHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.AryaStark, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithSession(session).
+		ExecutePostAsJSON(handlers.WidgetToken(), "{}")
+
+	Expect(code).Equals(http.StatusOK)
+	claims, err := jwt.DecodeFiderClaims(query.String("token"))
+	Expect(err).IsNil()
+	Expect(claims.UserID).Equals(mock.AryaStark.ID)
+	Expect(claims.SessionID).Equals(session.ID)
+	Expect(claims.Scope).Equals(models.ClaimsScopeWidget)
+	Expect(time.Unix(claims.ExpiresAt, 0)).TemporarilySimilar(time.Now().Add(24*time.Hour), 5*time.Second)
+
+	sessions, err := services.Sessions.GetActiveByUser(mock.AryaStark.ID)
+	Expect(err).IsNil()
+	Expect(sessions).HasLen(1)
+}
+
+func TestWidgetTokenHandler_Anonymous(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		ExecutePost(handlers.WidgetToken(), "{}")
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestWidgetTokenHandler_WithBearerToken(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.AryaStark, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+
+	code, _ := server.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.AryaStark).
+		WithSession(session).
+		AddHeader("Authorization", "Bearer some-widget-token").
+		ExecutePost(handlers.WidgetToken(), "{}")
+
+	Expect(code).Equals(http.StatusForbidden)
+}
+
+func TestOEmbedHandler(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	services.SetCurrentUser(mock.JonSnow)
+	idea, _ := services.Ideas.Add("My Idea", "My Idea Description")
+
+	ideaURL := "http://demo.test.fider.io/ideas/1/my-idea"
+	code, query := server.
+		OnTenant(mock.DemoTenant).
+		WithURL("http://demo.test.fider.io/oembed?maxwidth=300&url=" + url.QueryEscape(ideaURL)).
+		ExecuteAsJSON(handlers.OEmbed())
+
+	Expect(code).Equals(http.StatusOK)
+	Expect(query.String("type")).Equals("rich")
+	Expect(query.String("title")).Equals(idea.Title)
+	Expect(query.Int32("width")).Equals(300)
+	Expect(query.String("html")).ContainsSubstring(`src="http://demo.test.fider.io/widget/ideas/1"`)
+}
+
+func TestOEmbedHandler_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	var testCases = []struct {
+		query string
+		code  int
+	}{
+		{"url=" + url.QueryEscape("http://demo.test.fider.io/ideas/1/my-idea") + "&format=xml", http.StatusNotImplemented},
+		{"url=" + url.QueryEscape("http://other.test.fider.io/ideas/1/my-idea"), http.StatusNotFound},
+		{"url=" + url.QueryEscape("http://demo.test.fider.io/admin"), http.StatusNotFound},
+		{"url=" + url.QueryEscape("http://demo.test.fider.io/ideas/9/other-idea"), http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		server, services := mock.NewServer()
+		services.SetCurrentTenant(mock.DemoTenant)
+		services.SetCurrentUser(mock.JonSnow)
+		services.Ideas.Add("My Idea", "My Idea Description")
+
+		code, _ := server.
+			OnTenant(mock.DemoTenant).
+			WithURL("http://demo.test.fider.io/oembed?" + testCase.query).
+			Execute(handlers.OEmbed())
+
+		Expect(code).Equals(testCase.code)
+	}
+}
@@ -17,13 +17,13 @@ func IsAuthenticated() web.MiddlewareFunc {
 	}
 }
 
-// IsAuthorized blocks non-authorized requests
-func IsAuthorized(roles ...models.Role) web.MiddlewareFunc {
+// IsAuthorized blocks requests from users that have none of given permissions
+func IsAuthorized(permissions ...models.Permission) web.MiddlewareFunc {
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(c web.Context) error {
 			user := c.User()
-			for _, role := range roles {
-				if user.Role == role {
+			for _, permission := range permissions {
+				if user.Can(permission) {
 					return next(c)
 				}
 			}
@@ -15,7 +15,7 @@ func TestIsAuthorized_WithAllowedRole(t *testing.T) {
 	RegisterT(t)
 
 	server, _ := mock.NewServer()
-	server.Use(middlewares.IsAuthorized(models.RoleAdministrator, models.RoleCollaborator))
+	server.Use(middlewares.IsAuthorized(models.PermissionManageSettings, models.PermissionRespond))
 	status, _ := server.AsUser(mock.JonSnow).Execute(func(c web.Context) error {
 		return c.NoContent(http.StatusOK)
 	})
@@ -27,7 +27,7 @@ func TestIsAuthorized_WithForbiddenRole(t *testing.T) {
 	RegisterT(t)
 
 	server, _ := mock.NewServer()
-	server.Use(middlewares.IsAuthorized(models.RoleAdministrator, models.RoleCollaborator))
+	server.Use(middlewares.IsAuthorized(models.PermissionManageSettings, models.PermissionRespond))
 	status, _ := server.AsUser(mock.AryaStark).Execute(func(c web.Context) error {
 		return c.NoContent(http.StatusOK)
 	})
@@ -35,6 +35,36 @@ func TestIsAuthorized_WithForbiddenRole(t *testing.T) {
 	Expect(status).Equals(http.StatusForbidden)
 }
 
+func TestIsAuthorized_WithCustomRole(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	mock.AryaStark.Role = models.RoleCollaborator
+	mock.AryaStark.CustomRole = &models.CustomRole{
+		Name:        "Moderator",
+		Permissions: []models.Permission{models.PermissionModerateComments},
+	}
+	server.Use(middlewares.IsAuthorized(models.PermissionManageSettings))
+	status, _ := server.AsUser(mock.AryaStark).Execute(func(c web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusForbidden)
+
+	server, _ = mock.NewServer()
+	mock.AryaStark.Role = models.RoleCollaborator
+	mock.AryaStark.CustomRole = &models.CustomRole{
+		Name:        "Moderator",
+		Permissions: []models.Permission{models.PermissionModerateComments},
+	}
+	server.Use(middlewares.IsAuthorized(models.PermissionModerateComments))
+	status, _ = server.AsUser(mock.AryaStark).Execute(func(c web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusOK)
+}
+
 func TestIsAuthenticated_WithUser(t *testing.T) {
 	RegisterT(t)
 
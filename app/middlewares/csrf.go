@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// CSRF blocks state-changing requests that may have been forged by another site.
+// They must come from the same origin and, when authenticated by the auth cookie, carry the token of current session.
+// Calls with an Authorization header are not authenticated by the cookie and browsers can't send them cross-site, so they are exempt.
+func CSRF() web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c web.Context) error {
+			if !isUnsafeMethod(c.Request.Method) || c.Request.Header.Get("Authorization") != "" {
+				return next(c)
+			}
+
+			if !isSameOrigin(c) {
+				c.Logger().Warnf("Blocked cross-site request to '%s' from '%s'", c.Request.URL.Path, requestOrigin(c))
+				return c.Unauthorized()
+			}
+
+			if session := c.Session(); session != nil {
+				token := c.Request.Header.Get(web.CSRFHeaderName)
+				if subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+					c.Logger().Warnf("Blocked request to '%s' with invalid CSRF token", c.Request.URL.Path)
+					return c.Unauthorized()
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func isUnsafeMethod(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+}
+
+func requestOrigin(c web.Context) string {
+	if origin := c.Request.Header.Get("Origin"); origin != "" {
+		return origin
+	}
+	return c.Request.Header.Get("Referer")
+}
+
+//isSameOrigin returns false when Origin or Referer points to another host.
+//Some browsers and privacy tools omit both, in which case SameSite cookies are the remaining defense.
+func isSameOrigin(c web.Context) bool {
+	origin := requestOrigin(c)
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, c.Request.Host)
+}
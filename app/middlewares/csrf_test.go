@@ -0,0 +1,112 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+var csrfSession = &models.Session{ID: "session-1", CSRFToken: "secret-token"}
+
+func okHandler(c web.Context) error {
+	return c.NoContent(http.StatusOK)
+}
+
+func TestCSRF_SafeMethod(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.CSRF())
+	status, _ := server.
+		WithURL("http://demo.test.fider.io/").
+		AsUser(mock.JonSnow).
+		WithSession(csrfSession).
+		AddHeader("Origin", "http://evil.com").
+		Execute(okHandler)
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestCSRF_ValidToken(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.CSRF())
+	status, _ := server.
+		WithURL("http://demo.test.fider.io/").
+		AsUser(mock.JonSnow).
+		WithSession(csrfSession).
+		AddHeader("Origin", "http://demo.test.fider.io").
+		AddHeader(web.CSRFHeaderName, "secret-token").
+		ExecutePost(okHandler, "{}")
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestCSRF_MissingOrInvalidToken(t *testing.T) {
+	RegisterT(t)
+
+	for _, token := range []string{"", "wrong-token"} {
+		server, _ := mock.NewServer()
+		server.Use(middlewares.CSRF())
+		server.
+			WithURL("http://demo.test.fider.io/").
+			AsUser(mock.JonSnow).
+			WithSession(csrfSession)
+		if token != "" {
+			server.AddHeader(web.CSRFHeaderName, token)
+		}
+		status, _ := server.ExecutePost(okHandler, "{}")
+
+		Expect(status).Equals(http.StatusForbidden)
+	}
+}
+
+func TestCSRF_CrossOrigin(t *testing.T) {
+	RegisterT(t)
+
+	for _, header := range []string{"Origin", "Referer"} {
+		server, _ := mock.NewServer()
+		server.Use(middlewares.CSRF())
+		status, _ := server.
+			WithURL("http://demo.test.fider.io/").
+			AddHeader(header, "http://evil.com/page").
+			ExecutePost(okHandler, "{}")
+
+		Expect(status).Equals(http.StatusForbidden)
+	}
+}
+
+func TestCSRF_AnonymousSameOrigin(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.CSRF())
+	status, _ := server.
+		WithURL("http://demo.test.fider.io/").
+		AddHeader("Referer", "http://demo.test.fider.io/signin").
+		ExecutePost(okHandler, "{}")
+
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestCSRF_AuthorizationHeader(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.CSRF())
+	status, _ := server.
+		WithURL("http://demo.test.fider.io/").
+		AsUser(mock.JonSnow).
+		WithSession(csrfSession).
+		AddHeader("Authorization", "Bearer some-api-key").
+		AddHeader("Origin", "http://other.com").
+		ExecutePost(okHandler, "{}")
+
+	Expect(status).Equals(http.StatusOK)
+}
@@ -66,6 +66,16 @@ func MultiTenant() web.MiddlewareFunc {
 				return next(c)
 			}
 
+			if errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+
+			// Former hostnames of a tenant are kept so that old links still work
+			tenant, err = c.Services().Tenants.GetByAlias(hostname)
+			if err == nil {
+				return c.PermanentRedirect(c.TenantBaseURL(tenant) + c.Request.URL.RequestURI())
+			}
+
 			if errors.Cause(err) == app.ErrNotFound {
 				c.Logger().Debugf("Tenant not found for '%s'.", hostname)
 				return c.NotFound()
@@ -76,13 +86,22 @@ func MultiTenant() web.MiddlewareFunc {
 	}
 }
 
-// OnlyActiveTenants blocks requests for inactive tenants
+// OnlyActiveTenants blocks requests for inactive and suspended tenants
 func OnlyActiveTenants() web.MiddlewareFunc {
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(c web.Context) error {
 			if c.Tenant().Status == models.TenantActive {
 				return next(c)
 			}
+			if c.Tenant().Status == models.TenantSuspended {
+				if c.IsAjax() {
+					return c.JSON(http.StatusForbidden, web.Map{})
+				}
+				return c.Render(http.StatusForbidden, "suspended.html", web.Props{
+					TitleKey:       "Site suspended",
+					DescriptionKey: "This site has been suspended.",
+				})
+			}
 			return c.NotFound()
 		}
 	}
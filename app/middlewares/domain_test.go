@@ -77,6 +77,49 @@ func TestMultiTenant_UnknownDomain(t *testing.T) {
 	Expect(status).Equals(http.StatusNotFound)
 }
 
+func TestMultiTenant_Alias(t *testing.T) {
+	RegisterT(t)
+
+	for _, url := range []string{
+		"http://demo.test.fider.io/ideas/1?q=1",
+		"http://feedback.westeros.com/ideas/1?q=1",
+	} {
+		server, services := mock.NewServer()
+		services.Tenants.SetCurrentTenant(mock.DemoTenant)
+		services.Tenants.UpdateSettings(&models.UpdateTenantSettings{
+			Title:     mock.DemoTenant.Name,
+			Subdomain: "westeros",
+			CNAME:     "feedback.westeros.com",
+		})
+		services.Tenants.UpdateSettings(&models.UpdateTenantSettings{
+			Title: mock.DemoTenant.Name,
+			CNAME: "ideas.westeros.com",
+		})
+
+		server.Use(middlewares.MultiTenant())
+		status, response := server.WithURL(url).Execute(okHandler)
+		Expect(status).Equals(http.StatusMovedPermanently)
+		Expect(response.Header().Get("Location")).Equals("http://ideas.westeros.com/ideas/1?q=1")
+	}
+}
+
+func TestMultiTenant_RemovedAlias(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.Tenants.SetCurrentTenant(mock.DemoTenant)
+	services.Tenants.UpdateSettings(&models.UpdateTenantSettings{
+		Title:     mock.DemoTenant.Name,
+		Subdomain: "westeros",
+	})
+	aliases, _ := services.Tenants.ListAliases()
+	services.Tenants.RemoveAlias(aliases[0].ID)
+
+	server.Use(middlewares.MultiTenant())
+	status, _ := server.WithURL("http://demo.test.fider.io/").Execute(okHandler)
+	Expect(status).Equals(http.StatusNotFound)
+}
+
 func TestMultiTenant_CanonicalHeader(t *testing.T) {
 	RegisterT(t)
 
@@ -217,6 +260,21 @@ func TestOnlyActiveTenants_Inactive(t *testing.T) {
 	Expect(status).Equals(http.StatusNotFound)
 }
 
+func TestOnlyActiveTenants_Suspended(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	mock.DemoTenant.Status = models.TenantSuspended
+
+	server.Use(middlewares.OnlyActiveTenants())
+	status, response := server.OnTenant(mock.DemoTenant).Execute(func(c web.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	Expect(status).Equals(http.StatusForbidden)
+	Expect(response.Body.String()).ContainsSubstring("SITE SUSPENDED")
+}
+
 func TestCheckTenantPrivacy_Private_Unauthenticated(t *testing.T) {
 	RegisterT(t)
 
@@ -6,41 +6,90 @@ import (
 	"time"
 
 	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/web"
 )
 
-// JwtGetter gets JWT token from cookie and insert into context
+// JwtGetter gets JWT token from Authorization header or cookie and insert into context
+// Embedded widgets can't rely on cookies when browsers block them on third-party frames, so they send the token as a Bearer
 func JwtGetter() web.MiddlewareFunc {
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(c web.Context) error {
 
-			cookie, err := c.Cookie(web.CookieAuthName)
+			token, fromCookie := bearerToken(c), false
+			if token == "" {
+				cookie, err := c.Cookie(web.CookieAuthName)
+				if err != nil {
+					if errors.Cause(err) == http.ErrNoCookie {
+						return next(c)
+					}
+					return err
+				}
+				token, fromCookie = cookie.Value, true
+			}
+
+			if c.Tenant() == nil {
+				return next(c)
+			}
+
+			invalidate := func() error {
+				if fromCookie {
+					c.RemoveCookie(web.CookieAuthName)
+				}
+				return next(c)
+			}
+
+			//Tokens issued before sessions existed don't have a session and can't be revoked
+			claims, err := jwt.DecodeFiderClaims(token)
+			if err != nil || claims.SessionID == "" {
+				return invalidate()
+			}
+
+			//Widget tokens are only handed to the widget, so they are never expected on the cookie
+			if claims.Scope == models.ClaimsScopeWidget && fromCookie {
+				return invalidate()
+			}
+
+			//Any other Bearer token is a call to the API, which not every plan includes
+			if claims.Scope != models.ClaimsScopeWidget && !fromCookie {
+				if plan := c.Tenant().Plan; plan != nil && !plan.AllowAPI {
+					return c.JSON(http.StatusForbidden, web.Map{
+						"messages": []string{"The plan of this site doesn't include API access."},
+					})
+				}
+			}
+
+			session, err := c.Services().Sessions.GetByID(claims.SessionID)
 			if err != nil {
-				if errors.Cause(err) == http.ErrNoCookie {
-					return next(c)
+				if errors.Cause(err) == app.ErrNotFound {
+					return invalidate()
 				}
 				return err
 			}
 
-			claims, err := jwt.DecodeFiderClaims(cookie.Value)
-			if err != nil {
-				c.RemoveCookie(web.CookieAuthName)
-				return next(c)
+			if session.UserID != claims.UserID {
+				return invalidate()
 			}
 
 			user, err := c.Services().Users.GetByID(claims.UserID)
 			if err != nil {
 				if errors.Cause(err) == app.ErrNotFound {
-					c.RemoveCookie(web.CookieAuthName)
-					return next(c)
+					return invalidate()
 				}
 				return err
 			}
 
-			if c.Tenant() != nil && user.Tenant.ID == c.Tenant().ID {
+			if user.Tenant.ID == c.Tenant().ID {
+				//Last seen is only an indication, so it doesn't need to be updated on every request
+				if time.Since(session.LastSeenOn) > 5*time.Minute {
+					if err := c.Services().Sessions.Touch(session.ID); err != nil {
+						return err
+					}
+				}
 				c.SetUser(user)
+				c.SetSession(session)
 			}
 
 			return next(c)
@@ -75,6 +124,14 @@ func JwtSetter() web.MiddlewareFunc {
 	}
 }
 
+func bearerToken(c web.Context) string {
+	header := c.Request.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
 func stripPort(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
 	if colon == -1 {
@@ -3,6 +3,7 @@ package middlewares_test
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/getfider/fider/app/middlewares"
 	"github.com/getfider/fider/app/models"
@@ -31,10 +32,13 @@ func TestJwtGetter_NoCookie(t *testing.T) {
 func TestJwtGetter_WithCookie(t *testing.T) {
 	RegisterT(t)
 
-	server, _ := mock.NewServer()
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
 	token, _ := jwt.Encode(&models.FiderClaims{
-		UserID:   mock.JonSnow.ID,
-		UserName: mock.JonSnow.Name,
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
 	})
 
 	server.Use(middlewares.JwtGetter())
@@ -50,6 +54,209 @@ func TestJwtGetter_WithCookie(t *testing.T) {
 	Expect(response.Body.String()).Equals("Jon Snow")
 }
 
+func TestJwtGetter_WithBearerToken(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AddHeader("Authorization", "Bearer "+token).
+		Execute(func(c web.Context) error {
+			return c.String(http.StatusOK, c.User().Name)
+		})
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(response.Body.String()).Equals("Jon Snow")
+}
+
+func TestJwtGetter_WithBearerToken_PlanWithoutAPI(t *testing.T) {
+	RegisterT(t)
+
+	tenant := *mock.DemoTenant
+	tenant.Plan = models.PlanStandard
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(&tenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, response := server.
+		OnTenant(&tenant).
+		AddHeader("Authorization", "Bearer "+token).
+		Execute(func(c web.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusForbidden)
+	Expect(response.Body.String()).ContainsSubstring("API access")
+}
+
+func TestJwtGetter_WithWidgetToken_PlanWithoutAPI(t *testing.T) {
+	RegisterT(t)
+
+	tenant := *mock.DemoTenant
+	tenant.Plan = models.PlanFree
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(&tenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
+		Scope:     models.ClaimsScopeWidget,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, response := server.
+		OnTenant(&tenant).
+		AddHeader("Authorization", "Bearer "+token).
+		Execute(func(c web.Context) error {
+			return c.String(http.StatusOK, c.User().Name)
+		})
+
+	Expect(status).Equals(http.StatusOK)
+	Expect(response.Body.String()).Equals("Jon Snow")
+}
+
+func TestJwtGetter_WithInvalidBearerToken(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.JwtGetter())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AddHeader("Authorization", "Bearer not-a-token").
+		Execute(func(c web.Context) error {
+			if c.User() == nil {
+				return c.NoContent(http.StatusNoContent)
+			}
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusNoContent)
+	Expect(response.Header().Get("Set-Cookie")).Equals("")
+}
+
+func TestJwtGetter_WithCookie_WidgetToken(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
+		Scope:     models.ClaimsScopeWidget,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		Execute(func(c web.Context) error {
+			if c.User() == nil {
+				return c.NoContent(http.StatusNoContent)
+			}
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusNoContent)
+}
+
+func TestJwtGetter_WithCookie_WithoutSession(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:   mock.JonSnow.ID,
+		UserName: mock.JonSnow.Name,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		Execute(func(c web.Context) error {
+			if c.User() == nil {
+				return c.NoContent(http.StatusNoContent)
+			}
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusNoContent)
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieAuthName + "=;")
+}
+
+func TestJwtGetter_WithCookie_RevokedSession(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.JonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	services.Sessions.RevokeAll(mock.JonSnow.ID)
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, response := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		Execute(func(c web.Context) error {
+			if c.User() == nil {
+				return c.NoContent(http.StatusNoContent)
+			}
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusNoContent)
+	Expect(response.Header().Get("Set-Cookie")).ContainsSubstring(web.CookieAuthName + "=;")
+}
+
+func TestJwtGetter_WithCookie_SessionOfAnotherUser(t *testing.T) {
+	RegisterT(t)
+
+	server, services := mock.NewServer()
+	services.SetCurrentTenant(mock.DemoTenant)
+	session, _ := services.Sessions.Create(mock.AryaStark, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	token, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    mock.JonSnow.ID,
+		UserName:  mock.JonSnow.Name,
+		SessionID: session.ID,
+	})
+
+	server.Use(middlewares.JwtGetter())
+	status, _ := server.
+		OnTenant(mock.DemoTenant).
+		AddCookie(web.CookieAuthName, token).
+		Execute(func(c web.Context) error {
+			if c.User() == nil {
+				return c.NoContent(http.StatusNoContent)
+			}
+			return c.NoContent(http.StatusOK)
+		})
+
+	Expect(status).Equals(http.StatusNoContent)
+}
+
 func TestJwtGetter_WithCookie_InvalidUser(t *testing.T) {
 	RegisterT(t)
 
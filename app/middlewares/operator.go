@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"net/url"
+
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+// OperatorConsole only serves the super-admin console on the auth endpoint of multi host instances with operators
+func OperatorConsole() web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c web.Context) error {
+			if env.IsSingleHostMode() || len(env.Operators()) == 0 {
+				return c.NotFound()
+			}
+
+			endpoint, err := url.Parse(c.AuthEndpoint())
+			if err != nil {
+				return c.Failure(err)
+			}
+
+			if c.Request.Host != endpoint.Host {
+				return c.NotFound()
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// IsOperator blocks requests of anyone that hasn't signed in on the super-admin console
+func IsOperator() web.MiddlewareFunc {
+	return func(next web.HandlerFunc) web.HandlerFunc {
+		return func(c web.Context) error {
+			cookie, err := c.Cookie(web.CookieOperatorName)
+			if err == nil {
+				claims, err := jwt.DecodeOperatorClaims(cookie.Value)
+				//Operators removed from the configuration lose access immediately
+				if err == nil && env.Operators()[claims.OperatorEmail] != "" {
+					c.SetOperator(claims.OperatorEmail)
+					return next(c)
+				}
+				c.RemoveCookie(web.CookieOperatorName)
+			}
+
+			if c.IsAjax() {
+				return c.Unauthorized()
+			}
+			return c.Redirect("/operator/signin")
+		}
+	}
+}
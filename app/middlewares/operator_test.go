@@ -0,0 +1,84 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app/middlewares"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/jwt"
+	"github.com/getfider/fider/app/pkg/mock"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func operatorToken(email string) string {
+	token, _ := jwt.Encode(&models.OperatorClaims{
+		OperatorEmail: email,
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		},
+	})
+	return token
+}
+
+func TestOperatorConsole(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.OperatorConsole())
+	status, _ := server.WithURL("http://login.test.fider.io:3000/operator").Execute(okHandler)
+	Expect(status).Equals(http.StatusOK)
+
+	server, _ = mock.NewServer()
+	server.Use(middlewares.OperatorConsole())
+	status, _ = server.WithURL("http://demo.test.fider.io:3000/operator").Execute(okHandler)
+	Expect(status).Equals(http.StatusNotFound)
+}
+
+func TestOperatorConsole_WithoutOperators(t *testing.T) {
+	RegisterT(t)
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.OperatorConsole())
+	status, _ := server.WithURL("http://login.test.fider.io:3000/operator").Execute(okHandler)
+	Expect(status).Equals(http.StatusNotFound)
+}
+
+func TestIsOperator(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	server, _ := mock.NewServer()
+	server.Use(middlewares.IsOperator())
+	status, _ := server.
+		AddCookie(web.CookieOperatorName, operatorToken("ops@fider.io")).
+		Execute(func(c web.Context) error {
+			return c.String(http.StatusOK, c.Operator())
+		})
+	Expect(status).Equals(http.StatusOK)
+}
+
+func TestIsOperator_Invalid(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("OPERATORS", "ops@fider.io:secret")
+	defer os.Setenv("OPERATORS", "")
+
+	authToken, _ := jwt.Encode(&models.FiderClaims{UserID: mock.JonSnow.ID, SessionID: "abc"})
+	for _, token := range []string{"", authToken, operatorToken("removed@fider.io")} {
+		server, _ := mock.NewServer()
+		server.Use(middlewares.IsOperator())
+		if token != "" {
+			server.AddCookie(web.CookieOperatorName, token)
+		}
+		status, response := server.Execute(okHandler)
+		Expect(status).Equals(http.StatusTemporaryRedirect)
+		Expect(response.Header().Get("Location")).Equals("/operator/signin")
+	}
+}
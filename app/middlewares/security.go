@@ -10,7 +10,7 @@ import (
 func Secure() web.MiddlewareFunc {
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(c web.Context) error {
-			c.Response.Header().Add("Content-Security-Policy", fmt.Sprintf(web.CspPolicyTemplate, c.ContextID()))
+			c.Response.Header().Add("Content-Security-Policy", fmt.Sprintf(web.CspPolicyTemplate, c.ContextID(), "'self'"))
 			c.Response.Header().Add("X-XSS-Protection", "1; mode=block")
 			c.Response.Header().Add("X-Content-Type-Options", "nosniff")
 			c.Response.Header().Add("Referrer-Policy", "no-referrer-when-downgrade")
@@ -24,7 +24,7 @@ func TestSecure(t *testing.T) {
 	})
 
 	Expect(status).Equals(http.StatusOK)
-	Expect(response.Header().Get("Content-Security-Policy")).Equals(fmt.Sprintf(web.CspPolicyTemplate, id))
+	Expect(response.Header().Get("Content-Security-Policy")).Equals(fmt.Sprintf(web.CspPolicyTemplate, id, "'self'"))
 	Expect(response.Header().Get("X-XSS-Protection")).Equals("1; mode=block")
 	Expect(response.Header().Get("X-Content-Type-Options")).Equals("nosniff")
 	Expect(response.Header().Get("Referrer-Policy")).Equals("no-referrer-when-downgrade")
@@ -8,7 +8,9 @@ import (
 	"github.com/getfider/fider/app/pkg/worker"
 
 	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/email"
 	"github.com/getfider/fider/app/pkg/oauth"
 	"github.com/getfider/fider/app/pkg/web"
 	"github.com/getfider/fider/app/storage/postgres"
@@ -23,10 +25,57 @@ func Noop() web.MiddlewareFunc {
 	}
 }
 
+// emailDeliveryLog records email deliveries on its own transaction
+// because emails are sent outside of the request or task transaction
+type emailDeliveryLog struct {
+	db     *dbx.Database
+	logger log.Logger
+}
+
+func (l *emailDeliveryLog) Suppressions(tenant *models.Tenant) []*models.EmailSuppression {
+	trx, err := l.db.Begin()
+	if err != nil {
+		l.logger.Error(err)
+		return nil
+	}
+	defer trx.Rollback()
+
+	emailLog := postgres.NewEmailLogStorage(trx)
+	emailLog.SetCurrentTenant(tenant)
+	suppressions, err := emailLog.GetSuppressions()
+	if err != nil {
+		l.logger.Error(err)
+	}
+	return suppressions
+}
+
+func (l *emailDeliveryLog) Record(tenant *models.Tenant, deliveries []*models.EmailDelivery) {
+	trx, err := l.db.Begin()
+	if err != nil {
+		l.logger.Error(err)
+		return
+	}
+
+	emailLog := postgres.NewEmailLogStorage(trx)
+	emailLog.SetCurrentTenant(tenant)
+	for _, delivery := range deliveries {
+		if err := emailLog.Add(delivery); err != nil {
+			l.logger.Error(err)
+			trx.Rollback()
+			return
+		}
+	}
+
+	if err := trx.Commit(); err != nil {
+		l.logger.Error(err)
+	}
+}
+
 //WorkerSetup current context with some services
 func WorkerSetup(logger log.Logger) worker.MiddlewareFunc {
 	db := dbx.NewWithLogger(logger)
 	emailer := app.NewEmailer(logger)
+	email.SetDeliveryLog(&emailDeliveryLog{db, logger})
 	return func(next worker.Job) worker.Job {
 		return func(c *worker.Context) (err error) {
 			start := time.Now()
@@ -40,12 +89,16 @@ func WorkerSetup(logger log.Logger) worker.MiddlewareFunc {
 			}
 
 			c.SetServices(&app.Services{
-				Tenants:       postgres.NewTenantStorage(trx),
-				Users:         postgres.NewUserStorage(trx),
-				Ideas:         postgres.NewIdeaStorage(trx),
-				Tags:          postgres.NewTagStorage(trx),
-				Notifications: postgres.NewNotificationStorage(trx),
-				Emailer:       emailer,
+				Tenants:        postgres.NewTenantStorage(trx),
+				Users:          postgres.NewUserStorage(trx),
+				Ideas:          postgres.NewIdeaStorage(trx),
+				Tags:           postgres.NewTagStorage(trx),
+				Notifications:  postgres.NewNotificationStorage(trx),
+				EmailTemplates: postgres.NewEmailTemplateStorage(trx),
+				Pages:          postgres.NewPageStorage(trx),
+				EmailLog:       postgres.NewEmailLogStorage(trx),
+				Sessions:       postgres.NewSessionStorage(trx),
+				Emailer:        emailer,
 			})
 
 			//In case it panics somewhere
@@ -76,6 +129,7 @@ func WorkerSetup(logger log.Logger) worker.MiddlewareFunc {
 			}
 
 			//Still no errors, everything is fine!
+			c.Committed()
 			c.Logger().Debugf("Task '%s' finished in %s (committed)", log.Magenta(c.TaskName()), log.Magenta(time.Since(start).String()))
 			return nil
 		}
@@ -87,6 +141,7 @@ func WebSetup(logger log.Logger) web.MiddlewareFunc {
 	db := dbx.NewWithLogger(logger)
 	db.Migrate()
 	emailer := app.NewEmailer(logger)
+	email.SetDeliveryLog(&emailDeliveryLog{db, logger})
 	return func(next web.HandlerFunc) web.HandlerFunc {
 		return func(c web.Context) error {
 			path := log.Magenta(c.Request.Method + " " + c.Request.URL.String())
@@ -102,14 +157,19 @@ func WebSetup(logger log.Logger) web.MiddlewareFunc {
 			}
 
 			c.SetActiveTransaction(trx)
+			tenants := postgres.NewTenantStorage(trx)
 			c.SetServices(&app.Services{
-				Tenants:       postgres.NewTenantStorage(trx),
-				OAuth:         &oauth.HTTPService{},
-				Users:         postgres.NewUserStorage(trx),
-				Ideas:         postgres.NewIdeaStorage(trx),
-				Tags:          postgres.NewTagStorage(trx),
-				Notifications: postgres.NewNotificationStorage(trx),
-				Emailer:       emailer,
+				Tenants:        tenants,
+				OAuth:          &oauth.HTTPService{Configs: tenants},
+				Users:          postgres.NewUserStorage(trx),
+				Ideas:          postgres.NewIdeaStorage(trx),
+				Tags:           postgres.NewTagStorage(trx),
+				Notifications:  postgres.NewNotificationStorage(trx),
+				EmailTemplates: postgres.NewEmailTemplateStorage(trx),
+				Pages:          postgres.NewPageStorage(trx),
+				EmailLog:       postgres.NewEmailLogStorage(trx),
+				Sessions:       postgres.NewSessionStorage(trx),
+				Emailer:        emailer,
 			})
 
 			//In case it panics somewhere
@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+//EmailTemplate is a tenant customized version of a built-in email template
+type EmailTemplate struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Locale    string    `json:"locale"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	UpdatedOn time.Time `json:"updatedOn"`
+}
+
+//SaveEmailTemplate is the input model used to customize an email template
+type SaveEmailTemplate struct {
+	Name    string `route:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+//DeleteEmailTemplate is the input model used to restore the built-in version of an email template
+type DeleteEmailTemplate struct {
+	Name   string `route:"name"`
+	Locale string `json:"locale"`
+}
+
+//EmailDeliveryStatus is the status of an email sent to a recipient
+type EmailDeliveryStatus int
+
+const (
+	//EmailDeliverySent is an email accepted by the email provider
+	EmailDeliverySent EmailDeliveryStatus = 1
+	//EmailDeliveryFailed is an email that the email provider failed to accept
+	EmailDeliveryFailed EmailDeliveryStatus = 2
+	//EmailDeliverySuppressed is an email that was not sent because recipient is on the suppression list
+	EmailDeliverySuppressed EmailDeliveryStatus = 3
+	//EmailDeliveryBounced is an email that was permanently rejected by recipient's server
+	EmailDeliveryBounced EmailDeliveryStatus = 4
+	//EmailDeliveryComplained is an email that recipient has marked as spam
+	EmailDeliveryComplained EmailDeliveryStatus = 5
+)
+
+//EmailDelivery is a record of an email sent to a recipient
+type EmailDelivery struct {
+	ID           int                 `json:"id"`
+	TemplateName string              `json:"templateName"`
+	Address      string              `json:"address"`
+	Provider     string              `json:"provider"`
+	Status       EmailDeliveryStatus `json:"status"`
+	Response     string              `json:"response"`
+	CreatedOn    time.Time           `json:"createdOn"`
+}
+
+//EmailSuppression is an address that should not receive emails anymore
+type EmailSuppression struct {
+	Address   string    `json:"address"`
+	Reason    string    `json:"reason"`
+	CreatedOn time.Time `json:"createdOn"`
+}
+
+//RemoveEmailSuppression is the input model used to remove an address from the suppression list
+type RemoveEmailSuppression struct {
+	Address string `json:"address"`
+}
+
+//UpdateTenantEmailSettings is the input model used to update the sender identity and delivery settings of a tenant
+type UpdateTenantEmailSettings struct {
+	FromAddress    string `json:"fromAddress" format:"lower"`
+	ReplyTo        string `json:"replyTo" format:"lower"`
+	SMTPHost       string `json:"smtpHost"`
+	SMTPPort       string `json:"smtpPort"`
+	SMTPUsername   string `json:"smtpUsername"`
+	SMTPPassword   string `json:"smtpPassword"`
+	DKIMSelector   string `json:"dkimSelector"`
+	DKIMPrivateKey string `json:"dkimPrivateKey"`
+}
+
+//VerifySenderAddress is the input model used to verify the address a tenant sends emails from
+type VerifySenderAddress struct {
+	Email           string
+	VerificationKey string
+	Requestor       *User
+}
+
+//GetEmail returns the sender address being verified
+func (e *VerifySenderAddress) GetEmail() string {
+	return e.Email
+}
+
+//GetName returns empty for this kind of process
+func (e *VerifySenderAddress) GetName() string {
+	return ""
+}
+
+//GetUser returns the current user performing this action
+func (e *VerifySenderAddress) GetUser() *User {
+	return e.Requestor
+}
+
+//GetKind returns EmailVerificationKindSenderAddress
+func (e *VerifySenderAddress) GetKind() EmailVerificationKind {
+	return EmailVerificationKindSenderAddress
+}
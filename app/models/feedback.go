@@ -20,6 +20,15 @@ type Idea struct {
 	Status          int           `json:"status"`
 	Response        *IdeaResponse `json:"response"`
 	Tags            []string      `json:"tags"`
+	IsPrivate       bool          `json:"isPrivate"`
+}
+
+// IsVisibleTo returns true if given user can see this idea. Private ideas are only visible to its author and staff allowed to view them
+func (i *Idea) IsVisibleTo(user *User) bool {
+	if !i.IsPrivate {
+		return true
+	}
+	return user != nil && (user.Can(PermissionViewPrivate) || (i.User != nil && i.User.ID == user.ID))
 }
 
 // CanBeSupported returns true if this idea can be Supported/UnSupported
@@ -31,6 +40,7 @@ func (i *Idea) CanBeSupported() bool {
 type NewIdea struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
+	IsPrivate   bool   `json:"isPrivate"`
 }
 
 // UpdateIdea represents a request to edit an existing idea
@@ -40,6 +50,12 @@ type UpdateIdea struct {
 	Description string `json:"description"`
 }
 
+// SetIdeaPrivacy represents a request to make an idea private or public
+type SetIdeaPrivacy struct {
+	Number    int  `route:"number"`
+	IsPrivate bool `json:"isPrivate"`
+}
+
 // DeleteIdea represents a request to delete an existing idea
 type DeleteIdea struct {
 	Number int    `route:"number"`
@@ -59,6 +75,12 @@ type EditComment struct {
 	Content    string `json:"content"`
 }
 
+// DeleteComment represents a request to delete existing comment
+type DeleteComment struct {
+	IdeaNumber int `route:"number"`
+	ID         int `route:"id"`
+}
+
 // SetResponse represents the action to update an idea response
 type SetResponse struct {
 	Number         int    `route:"number"`
@@ -10,15 +10,132 @@ import (
 
 //Tenant represents a tenant
 type Tenant struct {
-	ID             int    `json:"id"`
-	Name           string `json:"name"`
-	Subdomain      string `json:"subdomain"`
-	Invitation     string `json:"invitation"`
-	WelcomeMessage string `json:"welcomeMessage"`
-	CNAME          string `json:"cname"`
-	Status         int    `json:"-"`
-	IsPrivate      bool   `json:"isPrivate"`
-	LogoID         int    `json:"logoId"`
+	ID             int                    `json:"id"`
+	Name           string                 `json:"name"`
+	Subdomain      string                 `json:"subdomain"`
+	Invitation     string                 `json:"invitation"`
+	WelcomeMessage string                 `json:"welcomeMessage"`
+	CNAME          string                 `json:"cname"`
+	Status         int                    `json:"-"`
+	IsPrivate      bool                   `json:"isPrivate"`
+	LogoID         int                    `json:"logoId"`
+	Locale         string                 `json:"locale"`
+	Email          TenantEmailSettings    `json:"-"`
+	SAML           TenantSAMLSettings     `json:"-"`
+	JWTSSO         TenantJWTSSOSettings   `json:"-"`
+	AutoJoin       TenantAutoJoinSettings `json:"-"`
+	Theme          TenantTheme            `json:"theme"`
+	EmbedOrigins   []string               `json:"-"`
+
+	IsTwoFactorRequired bool       `json:"isTwoFactorRequired"`
+	DeletionScheduledOn *time.Time `json:"-"`
+	Plan                *Plan      `json:"-"`
+}
+
+//IsDeletionScheduled returns true if the tenant is going to be purged when its grace period ends
+func (t *Tenant) IsDeletionScheduled() bool {
+	return t.DeletionScheduledOn != nil
+}
+
+//TenantTheme is the branding of a tenant applied on top of the default look
+type TenantTheme struct {
+	PrimaryColor string `json:"primaryColor"`
+	AccentColor  string `json:"accentColor"`
+	CustomCSS    string `json:"customCSS"`
+	FaviconID    int    `json:"faviconId"`
+	HeaderID     int    `json:"headerId"`
+}
+
+//TenantEmailSettings is the identity and delivery configuration used to send emails on behalf of a tenant
+type TenantEmailSettings struct {
+	FromAddress       string `json:"fromAddress"`
+	ReplyTo           string `json:"replyTo"`
+	VerifiedDomain    string `json:"verifiedDomain"`
+	VerifiedAddress   string `json:"verifiedAddress"`
+	VerificationToken string `json:"verificationToken"`
+	SMTPHost          string `json:"smtpHost"`
+	SMTPPort          string `json:"smtpPort"`
+	SMTPUsername      string `json:"smtpUsername"`
+	SMTPPassword      string `json:"-"`
+	DKIMSelector      string `json:"dkimSelector"`
+	DKIMPrivateKey    string `json:"-"`
+}
+
+//Domain returns the domain of the from address
+func (s TenantEmailSettings) Domain() string {
+	at := strings.LastIndex(s.FromAddress, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(s.FromAddress[at+1:])
+}
+
+//IsVerified returns true if either the domain of the from address has been verified by DNS,
+//or the from address itself has been verified by email
+func (s TenantEmailSettings) IsVerified() bool {
+	domain := s.Domain()
+	if domain == "" {
+		return false
+	}
+	return domain == strings.ToLower(s.VerifiedDomain) || strings.EqualFold(s.FromAddress, s.VerifiedAddress)
+}
+
+//HasSMTP returns true if emails should be sent through tenant's own SMTP server
+func (s TenantEmailSettings) HasSMTP() bool {
+	return s.SMTPHost != ""
+}
+
+//HasDKIM returns true if emails should be signed with tenant's own DKIM key
+func (s TenantEmailSettings) HasDKIM() bool {
+	return s.DKIMSelector != "" && s.DKIMPrivateKey != ""
+}
+
+//TenantSAMLSettings is the configuration used to sign in users with the SAML 2.0 IdP of a tenant
+type TenantSAMLSettings struct {
+	IsEnabled              bool   `json:"isEnabled"`
+	IsEnforced             bool   `json:"isEnforced"`
+	IdPEntityID            string `json:"idpEntityId"`
+	IdPSSOURL              string `json:"idpSsoUrl"`
+	IdPCertificate         string `json:"idpCertificate"`
+	NameAttribute          string `json:"nameAttribute"`
+	EmailAttribute         string `json:"emailAttribute"`
+	RoleAttribute          string `json:"roleAttribute"`
+	AdministratorRoleValue string `json:"administratorRoleValue"`
+	CollaboratorRoleValue  string `json:"collaboratorRoleValue"`
+}
+
+//IsSSOEnforced returns true if users can only sign in through the SAML IdP
+func (s TenantSAMLSettings) IsSSOEnforced() bool {
+	return s.IsEnabled && s.IsEnforced
+}
+
+//TenantJWTSSOSettings is the configuration used to sign in users with tokens issued by a trusted application
+type TenantJWTSSOSettings struct {
+	IsEnabled bool   `json:"isEnabled"`
+	Secret    string `json:"-"`
+	PublicKey string `json:"publicKey"`
+}
+
+//TenantAutoJoinSettings is the list of email domains allowed to join a private tenant without an invitation
+type TenantAutoJoinSettings struct {
+	Domains []string `json:"domains"`
+	Role    Role     `json:"role"`
+}
+
+//Allows returns true if given email address belongs to one of the allowed domains
+func (s TenantAutoJoinSettings) Allows(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range s.Domains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
 }
 
 var (
@@ -26,8 +143,31 @@ var (
 	TenantActive = 1
 	//TenantInactive is used for signup via email that requires user confirmation
 	TenantInactive = 2
+	//TenantSuspended is used for tenants that have been blocked by the operators of the service
+	TenantSuspended = 3
 )
 
+//TenantDeletionGracePeriod is how long a tenant scheduled for deletion can still be restored before it's purged
+var TenantDeletionGracePeriod = 30 * 24 * time.Hour
+
+//TenantAliasCooldownPeriod is how long a removed alias stays reserved before anyone else can use it
+var TenantAliasCooldownPeriod = 90 * 24 * time.Hour
+
+//CertificateInfo is the status of the SSL certificate issued automatically for a custom domain
+type CertificateInfo struct {
+	Hostname  string    `json:"hostname"`
+	ExpiresOn time.Time `json:"expiresOn"`
+	IssuedOn  time.Time `json:"issuedOn"`
+}
+
+//TenantAlias is a former subdomain or custom domain of a tenant that redirects to its current one
+type TenantAlias struct {
+	ID        int       `json:"id"`
+	Hostname  string    `json:"hostname"`
+	IsCNAME   bool      `json:"isCNAME"`
+	CreatedOn time.Time `json:"createdOn"`
+}
+
 //Upload represents a file that has been uploaded to Fider
 type Upload struct {
 	ContentType string `db:"content_type"`
@@ -42,7 +182,14 @@ type User struct {
 	Email     string          `json:"-"`
 	Tenant    *Tenant         `json:"-"`
 	Role      Role            `json:"role"`
+	AvatarURL string          `json:"-"`
+	Locale    string          `json:"locale"`
 	Providers []*UserProvider `json:"-"`
+
+	TOTPSecret    string `json:"-"`
+	IsTOTPEnabled bool   `json:"-"`
+
+	CustomRole *CustomRole `json:"customRole,omitempty"`
 }
 
 //Role is the role of a user inside a tenant
@@ -57,6 +204,89 @@ const (
 	RoleAdministrator Role = 3
 )
 
+//Permission is a named action that members of a role are allowed to perform
+type Permission string
+
+const (
+	//PermissionRespond allows setting the status and response of ideas
+	PermissionRespond Permission = "respond"
+	//PermissionEditIdeas allows editing title and description of any idea
+	PermissionEditIdeas Permission = "edit_ideas"
+	//PermissionDeleteIdeas allows deleting ideas
+	PermissionDeleteIdeas Permission = "delete_ideas"
+	//PermissionModerateComments allows editing and deleting comments of other users
+	PermissionModerateComments Permission = "moderate_comments"
+	//PermissionAssignTags allows assigning and unassigning tags on ideas
+	PermissionAssignTags Permission = "assign_tags"
+	//PermissionManageTags allows creating, editing and deleting tags
+	PermissionManageTags Permission = "manage_tags"
+	//PermissionInvite allows inviting new users
+	PermissionInvite Permission = "invite"
+	//PermissionManageMembers allows changing roles and signing out other users
+	PermissionManageMembers Permission = "manage_members"
+	//PermissionExport allows exporting site data
+	PermissionExport Permission = "export"
+	//PermissionManageSettings allows changing site settings
+	PermissionManageSettings Permission = "manage_settings"
+	//PermissionViewPrivate allows seeing private ideas of other users and private tags
+	PermissionViewPrivate Permission = "view_private"
+)
+
+//AllPermissions contains all possible permissions
+var AllPermissions = []Permission{
+	PermissionRespond,
+	PermissionEditIdeas,
+	PermissionDeleteIdeas,
+	PermissionModerateComments,
+	PermissionAssignTags,
+	PermissionManageTags,
+	PermissionInvite,
+	PermissionManageMembers,
+	PermissionExport,
+	PermissionManageSettings,
+	PermissionViewPrivate,
+}
+
+//IsValid returns true if permission is known
+func (p Permission) IsValid() bool {
+	return ContainsPermission(AllPermissions, p)
+}
+
+//ContainsPermission returns true if given permission is in the list
+func ContainsPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+//DefaultPermissions returns the permissions granted to a built-in role
+func DefaultPermissions(role Role) []Permission {
+	switch role {
+	case RoleAdministrator:
+		return AllPermissions
+	case RoleCollaborator:
+		return []Permission{
+			PermissionRespond,
+			PermissionEditIdeas,
+			PermissionModerateComments,
+			PermissionAssignTags,
+			PermissionInvite,
+			PermissionViewPrivate,
+		}
+	}
+	return []Permission{}
+}
+
+//CustomRole is a tenant-defined role that bundles a set of permissions
+type CustomRole struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+}
+
 //EmailVerificationKind specifies which kind of process is being verified by email
 type EmailVerificationKind int16
 
@@ -69,6 +299,8 @@ const (
 	EmailVerificationKindChangeEmail EmailVerificationKind = 3
 	//EmailVerificationKindUserInvitation is the sign in invitation sent to an user
 	EmailVerificationKindUserInvitation EmailVerificationKind = 4
+	//EmailVerificationKindSenderAddress is the verification of the address a tenant sends emails from
+	EmailVerificationKindSenderAddress EmailVerificationKind = 5
 )
 
 //HasProvider returns true if current user has registered with given provider
@@ -91,17 +323,64 @@ func (u *User) IsAdministrator() bool {
 	return u.Role == RoleAdministrator
 }
 
+// Permissions returns what user is allowed to do, either from its custom role or from the defaults of its role
+func (u *User) Permissions() []Permission {
+	if u.CustomRole != nil && u.Role != RoleAdministrator {
+		return u.CustomRole.Permissions
+	}
+	return DefaultPermissions(u.Role)
+}
+
+// Can returns true if user has been granted given permission
+func (u *User) Can(permission Permission) bool {
+	return ContainsPermission(u.Permissions(), permission)
+}
+
+// CanAll returns true if user has been granted all given permissions
+func (u *User) CanAll(permissions []Permission) bool {
+	for _, p := range permissions {
+		if !u.Can(p) {
+			return false
+		}
+	}
+	return true
+}
+
 //UserProvider represents the relashionship between an User and an Authentication provide
 type UserProvider struct {
 	Name string
 	UID  string
 }
 
+//ClaimsScopeWidget restricts tokens to the embedded widget, which sends them as Bearer tokens
+const ClaimsScopeWidget = "widget"
+
 //FiderClaims represents what goes into JWT tokens
 type FiderClaims struct {
 	UserID    int    `json:"user/id"`
 	UserName  string `json:"user/name"`
 	UserEmail string `json:"user/email"`
+	SessionID string `json:"session/id"`
+	Scope     string `json:"scope,omitempty"`
+	jwt.StandardClaims
+}
+
+//Session is a device where a user is signed in
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int       `json:"-"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedOn  time.Time `json:"createdOn"`
+	LastSeenOn time.Time `json:"lastSeenOn"`
+	ExpiresOn  time.Time `json:"-"`
+	CSRFToken  string    `json:"-"`
+}
+
+//TwoFactorClaims represents what goes into the temporary token of a sign in waiting for a second factor
+type TwoFactorClaims struct {
+	UserID   int    `json:"2fa/user_id"`
+	Redirect string `json:"2fa/redirect"`
 	jwt.StandardClaims
 }
 
@@ -114,6 +393,16 @@ type OAuthClaims struct {
 	jwt.StandardClaims
 }
 
+//SSOClaims represents what a trusted application sends to sign in a user.
+//Subject is the ID of the user on the trusted application and ID is used to prevent replays.
+type SSOClaims struct {
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+	Role      string `json:"role"`
+	jwt.StandardClaims
+}
+
 //CreateTenant is the input model used to create a tenant
 type CreateTenant struct {
 	Token           string `json:"token"`
@@ -151,7 +440,9 @@ type UpdateTenantSettings struct {
 	Title          string                    `json:"title"`
 	Invitation     string                    `json:"invitation"`
 	WelcomeMessage string                    `json:"welcomeMessage"`
+	Subdomain      string                    `json:"subdomain" format:"lower"`
 	CNAME          string                    `json:"cname" format:"lower"`
+	Locale         string                    `json:"locale"`
 }
 
 //UpdateTenantSettingsLogo is the input model used to update logo
@@ -166,11 +457,65 @@ type UpdateTenantSettingsLogoUpload struct {
 	Content     []byte `json:"content"`
 }
 
+//UpdateTenantTheme is the input model used to update tenant theme
+//Images are only changed when given, the same way as the logo
+type UpdateTenantTheme struct {
+	PrimaryColor string                    `json:"primaryColor" format:"upper"`
+	AccentColor  string                    `json:"accentColor" format:"upper"`
+	CustomCSS    string                    `json:"customCSS"`
+	Favicon      *UpdateTenantSettingsLogo `json:"favicon"`
+	Header       *UpdateTenantSettingsLogo `json:"header"`
+}
+
 //UpdateTenantPrivacy is the input model used to update tenant privacy settings
 type UpdateTenantPrivacy struct {
 	IsPrivate bool `json:"isPrivate"`
 }
 
+//UpdateTenantSAMLSettings is the input model used to configure SAML single sign-on.
+//IdP fields left empty are read from Metadata, when given.
+type UpdateTenantSAMLSettings struct {
+	IsEnabled              bool   `json:"isEnabled"`
+	IsEnforced             bool   `json:"isEnforced"`
+	Metadata               string `json:"metadata"`
+	IdPEntityID            string `json:"idpEntityId"`
+	IdPSSOURL              string `json:"idpSsoUrl"`
+	IdPCertificate         string `json:"idpCertificate"`
+	NameAttribute          string `json:"nameAttribute"`
+	EmailAttribute         string `json:"emailAttribute"`
+	RoleAttribute          string `json:"roleAttribute"`
+	AdministratorRoleValue string `json:"administratorRoleValue"`
+	CollaboratorRoleValue  string `json:"collaboratorRoleValue"`
+}
+
+//UpdateTenantJWTSSOSettings is the input model used to configure sign in with tokens issued by a trusted application
+type UpdateTenantJWTSSOSettings struct {
+	IsEnabled bool   `json:"isEnabled"`
+	Secret    string `json:"secret"`
+	PublicKey string `json:"publicKey"`
+}
+
+//UpdateTenantTwoFactorSettings is the input model used to require two-factor authentication from staff members
+type UpdateTenantTwoFactorSettings struct {
+	IsRequired bool `json:"isRequired"`
+}
+
+//UpdateTenantAutoJoinSettings is the input model used to configure which email domains can join without an invitation
+type UpdateTenantAutoJoinSettings struct {
+	Domains []string `json:"domains"`
+	Role    Role     `json:"role"`
+}
+
+//UpdateTenantEmbedSettings is the input model used to configure which websites can embed the feedback widget
+type UpdateTenantEmbedSettings struct {
+	Origins []string `json:"origins"`
+}
+
+//TwoFactorCode is the input model used to confirm a code from an authenticator app or a recovery code
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
 //SignInByEmail is the input model when user request to sign in by email
 type SignInByEmail struct {
 	Email           string `json:"email" format:"lower"`
@@ -227,6 +572,7 @@ func (e *ChangeUserEmail) GetKind() EmailVerificationKind {
 //UserInvitation is the model used to register an invite sent to an user
 type UserInvitation struct {
 	Email           string
+	Name            string
 	VerificationKey string
 }
 
@@ -235,9 +581,9 @@ func (e *UserInvitation) GetEmail() string {
 	return e.Email
 }
 
-//GetName returns empty for this kind of process
+//GetName returns the invited user's name, when known
 func (e *UserInvitation) GetName() string {
-	return ""
+	return e.Name
 }
 
 //GetUser returns the current user performing this action
@@ -260,6 +606,7 @@ type NewEmailVerification interface {
 
 //EmailVerification is the model used by email verification process
 type EmailVerification struct {
+	ID         int
 	Email      string
 	Name       string
 	Key        string
@@ -270,23 +617,79 @@ type EmailVerification struct {
 	VerifiedOn *time.Time
 }
 
+var (
+	//InvitationPending is used for invitations that can still be accepted
+	InvitationPending = "pending"
+	//InvitationAccepted is used for invitations that have been used to sign in
+	InvitationAccepted = "accepted"
+	//InvitationExpired is used for invitations that can't be accepted anymore
+	InvitationExpired = "expired"
+)
+
+//Invitation is the current state of an invitation to join a tenant
+type Invitation struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	CreatedOn  time.Time  `json:"createdOn"`
+	ExpiresOn  time.Time  `json:"expiresOn"`
+	AcceptedOn *time.Time `json:"acceptedOn,omitempty"`
+}
+
+//NewInvitation returns the state of an invitation based on its email verification
+func NewInvitation(verification *EmailVerification, now time.Time) *Invitation {
+	invitation := &Invitation{
+		ID:        verification.ID,
+		Email:     verification.Email,
+		Name:      verification.Name,
+		Status:    InvitationPending,
+		CreatedOn: verification.CreatedOn,
+		ExpiresOn: verification.ExpiresOn,
+	}
+
+	//Expired keys are also marked as verified when someone tries to use them
+	if verification.VerifiedOn != nil && verification.VerifiedOn.Before(verification.ExpiresOn) {
+		invitation.Status = InvitationAccepted
+		invitation.AcceptedOn = verification.VerifiedOn
+	} else if verification.VerifiedOn != nil || !now.Before(verification.ExpiresOn) {
+		invitation.Status = InvitationExpired
+	}
+	return invitation
+}
+
 // CompleteProfile is the model used to complete user profile during email sign in
 type CompleteProfile struct {
-	Key   string `json:"key"`
-	Name  string `json:"name"`
-	Email string
+	Key   string                `json:"key"`
+	Name  string                `json:"name"`
+	Email string                `json:"-"`
+	Kind  EmailVerificationKind `json:"-"`
 }
 
 // UpdateUserSettings is the model used to update user's settings
 type UpdateUserSettings struct {
 	Name     string            `json:"name"`
+	Locale   string            `json:"locale"`
 	Settings map[string]string `json:"settings"`
 }
 
 // ChangeUserRole is the input model change role of an user
 type ChangeUserRole struct {
-	UserID int  `route:"user_id"`
-	Role   Role `json:"role"`
+	UserID       int  `route:"user_id"`
+	Role         Role `json:"role"`
+	CustomRoleID int  `json:"customRoleId"`
+}
+
+// SaveCustomRole is used to create or edit a tenant-defined role
+type SaveCustomRole struct {
+	ID          int          `route:"id"`
+	Name        string       `json:"name"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// DeleteCustomRole is used to delete a tenant-defined role
+type DeleteCustomRole struct {
+	ID int `route:"id"`
 }
 
 // InviteUsers is used to invite new users into Fider
@@ -296,7 +699,71 @@ type InviteUsers struct {
 	Recipients []string `json:"recipients" format:"lower"`
 }
 
+// ImportInvitations is used to invite users from a CSV file of email addresses and optional names
+type ImportInvitations struct {
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	CSV     string `json:"csv"`
+}
+
+// ResendInvitation is used to send a new link to an invited user
+type ResendInvitation struct {
+	ID      int    `route:"id"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
 // GenerateVerificationKey used on email verifications
 func GenerateVerificationKey() string {
 	return strings.Replace(uuid.NewV4().String(), "-", "", 4)
 }
+
+//OAuthConfig is the configuration of a custom OAuth2 or OpenID Connect provider registered by a tenant
+type OAuthConfig struct {
+	ID                int    `json:"id"`
+	Provider          string `json:"provider"`
+	DisplayName       string `json:"displayName"`
+	Status            int    `json:"status"`
+	ClientID          string `json:"clientId"`
+	ClientSecret      string `json:"-"`
+	AuthorizeURL      string `json:"authorizeUrl"`
+	TokenURL          string `json:"tokenUrl"`
+	ProfileURL        string `json:"profileUrl"`
+	Scope             string `json:"scope"`
+	JSONUserIDPath    string `json:"jsonUserIdPath"`
+	JSONUserNamePath  string `json:"jsonUserNamePath"`
+	JSONUserEmailPath string `json:"jsonUserEmailPath"`
+	//EmailAlwaysVerified is set for providers that only return addresses their users have confirmed,
+	//otherwise the email is only trusted when the profile has an 'email_verified' claim
+	EmailAlwaysVerified bool `json:"emailAlwaysVerified"`
+}
+
+var (
+	//OAuthConfigDisabled is used to disable an OAuthConfig for signin
+	OAuthConfigDisabled = 1
+	//OAuthConfigEnabled is used to enable an OAuthConfig for public use
+	OAuthConfigEnabled = 2
+)
+
+//IsEnabled returns true if users can sign in with this provider
+func (c *OAuthConfig) IsEnabled() bool {
+	return c.Status == OAuthConfigEnabled
+}
+
+//CreateEditOAuthConfig is the input model used to register or change a custom OAuth provider
+type CreateEditOAuthConfig struct {
+	Provider            string `json:"provider"`
+	DisplayName         string `json:"displayName"`
+	Status              int    `json:"status"`
+	ClientID            string `json:"clientId"`
+	ClientSecret        string `json:"clientSecret"`
+	DiscoveryURL        string `json:"discoveryUrl"`
+	AuthorizeURL        string `json:"authorizeUrl"`
+	TokenURL            string `json:"tokenUrl"`
+	ProfileURL          string `json:"profileUrl"`
+	Scope               string `json:"scope"`
+	JSONUserIDPath      string `json:"jsonUserIdPath"`
+	JSONUserNamePath    string `json:"jsonUserNamePath"`
+	JSONUserEmailPath   string `json:"jsonUserEmailPath"`
+	EmailAlwaysVerified bool   `json:"emailAlwaysVerified"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+//OperatorClaims is what goes into the JWT token of an operator signed in on the console
+type OperatorClaims struct {
+	OperatorEmail string `json:"operator/email"`
+	jwt.StandardClaims
+}
+
+//TenantSummary is the overview of a tenant shown to operators
+type TenantSummary struct {
+	ID                  int        `json:"id"`
+	Name                string     `json:"name"`
+	Subdomain           string     `json:"subdomain"`
+	CNAME               string     `json:"cname"`
+	Status              int        `json:"status"`
+	Plan                string     `json:"plan"`
+	CreatedOn           time.Time  `json:"createdOn"`
+	UserCount           int        `json:"userCount"`
+	IdeaCount           int        `json:"ideaCount"`
+	LastActivityOn      *time.Time `json:"lastActivityOn,omitempty"`
+	StorageUsage        int64      `json:"storageUsage"`
+	DeletionScheduledOn *time.Time `json:"deletionScheduledOn,omitempty"`
+}
+
+//OperatorAction is something an operator did on the console
+type OperatorAction string
+
+var (
+	//OperatorActionSuspend is used when a tenant is suspended
+	OperatorActionSuspend OperatorAction = "suspend"
+	//OperatorActionReactivate is used when a suspended tenant is activated again
+	OperatorActionReactivate OperatorAction = "reactivate"
+	//OperatorActionChangeSubdomain is used when the subdomain of a tenant is changed
+	OperatorActionChangeSubdomain OperatorAction = "change_subdomain"
+	//OperatorActionImpersonate is used when an operator signs in as a member of a tenant
+	OperatorActionImpersonate OperatorAction = "impersonate"
+	//OperatorActionChangePlan is used when the plan of a tenant is changed
+	OperatorActionChangePlan OperatorAction = "change_plan"
+)
+
+//OperatorAuditEntry is the record of an action performed by an operator
+type OperatorAuditEntry struct {
+	ID            int            `json:"id"`
+	OperatorEmail string         `json:"operatorEmail"`
+	Action        OperatorAction `json:"action"`
+	TenantID      int            `json:"tenantId"`
+	UserID        int            `json:"userId,omitempty"`
+	Details       string         `json:"details"`
+	IPAddress     string         `json:"ipAddress"`
+	CreatedOn     time.Time      `json:"createdOn"`
+}
+
+//OperatorSignIn is the input model used by operators to sign in on the console
+type OperatorSignIn struct {
+	Email  string `json:"email" format:"lower"`
+	Secret string `json:"secret"`
+}
+
+//ChangeTenantSubdomain is the input model used by operators to change the subdomain of a tenant
+type ChangeTenantSubdomain struct {
+	TenantID  int    `route:"id"`
+	Subdomain string `json:"subdomain" format:"lower"`
+}
+
+//ImpersonateTenantUser is the input model used by operators to sign in as a member of a tenant
+type ImpersonateTenantUser struct {
+	TenantID int `route:"id"`
+	UserID   int `json:"userId"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+//Page is a markdown page authored by the tenant, e.g. terms of use or privacy policy
+type Page struct {
+	ID         int       `json:"id"`
+	Slug       string    `json:"slug"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	Visibility int       `json:"visibility"`
+	Placement  int       `json:"placement"`
+	UpdatedOn  time.Time `json:"updatedOn"`
+}
+
+//IsVisibleTo returns true if given user can read this page
+func (p *Page) IsVisibleTo(user *User) bool {
+	switch p.Visibility {
+	case PageVisibilityPublic:
+		return true
+	case PageVisibilityMembers:
+		return user != nil
+	}
+	return user != nil && user.Can(PermissionManageSettings)
+}
+
+var (
+	//PageVisibilityPublic is used for pages that anyone that can see the site can read
+	PageVisibilityPublic = 1
+	//PageVisibilityMembers is used for pages that only signed in users can read
+	PageVisibilityMembers = 2
+	//PageVisibilityDraft is used for pages that are still being written and only administrators can read
+	PageVisibilityDraft = 3
+)
+
+var (
+	//PagePlacementNone is used for pages that are not linked from the navigation
+	PagePlacementNone = 0
+	//PagePlacementHeader is used for pages linked from the header of the site
+	PagePlacementHeader = 1
+	//PagePlacementFooter is used for pages linked from the footer of the site
+	PagePlacementFooter = 2
+)
+
+//SavePage is the input model used to create or edit a page
+type SavePage struct {
+	CurrentSlug string `route:"slug"`
+	Slug        string `json:"slug" format:"lower"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Visibility  int    `json:"visibility"`
+	Placement   int    `json:"placement"`
+}
+
+//DeletePage is the input model used to delete a page
+type DeletePage struct {
+	Slug string `route:"slug"`
+}
@@ -0,0 +1,80 @@
+package models
+
+//Plan defines the limits of a tenant
+//Limits set to zero are unlimited
+type Plan struct {
+	Name              string `json:"name"`
+	MaxUsers          int    `json:"maxUsers"`
+	MaxIdeas          int    `json:"maxIdeas"`
+	MaxStorage        int64  `json:"maxStorage"`
+	AllowPrivate      bool   `json:"allowPrivate"`
+	AllowCustomDomain bool   `json:"allowCustomDomain"`
+	AllowAPI          bool   `json:"allowAPI"`
+}
+
+var (
+	//PlanFree is for small communities hosted by us
+	PlanFree = &Plan{
+		Name:       "free",
+		MaxUsers:   50,
+		MaxIdeas:   100,
+		MaxStorage: 1024 * 1024,
+	}
+	//PlanStandard is for companies hosted by us
+	PlanStandard = &Plan{
+		Name:              "standard",
+		MaxUsers:          1000,
+		MaxStorage:        100 * 1024 * 1024,
+		AllowPrivate:      true,
+		AllowCustomDomain: true,
+	}
+	//PlanUnlimited is used by self-hosted instances and tenants without a plan
+	PlanUnlimited = &Plan{
+		Name:              "unlimited",
+		AllowPrivate:      true,
+		AllowCustomDomain: true,
+		AllowAPI:          true,
+	}
+
+	//Plans lists all plans a tenant can have
+	Plans = []*Plan{PlanFree, PlanStandard, PlanUnlimited}
+)
+
+//GetPlan returns the plan with given name or nil if it doesn't exist
+func GetPlan(name string) *Plan {
+	for _, plan := range Plans {
+		if plan.Name == name {
+			return plan
+		}
+	}
+	return nil
+}
+
+//TenantUsage is what a tenant consumes out of its plan
+type TenantUsage struct {
+	Plan    *Plan `json:"plan"`
+	Users   int   `json:"users"`
+	Ideas   int   `json:"ideas"`
+	Storage int64 `json:"storage"`
+}
+
+//CanAddUsers returns true if the plan has room for given number of new users
+func (u *TenantUsage) CanAddUsers(count int) bool {
+	return u.Plan.MaxUsers == 0 || u.Users+count <= u.Plan.MaxUsers
+}
+
+//CanAddIdea returns true if the plan has room for a new idea
+func (u *TenantUsage) CanAddIdea() bool {
+	return u.Plan.MaxIdeas == 0 || u.Ideas < u.Plan.MaxIdeas
+}
+
+//CanStore returns true if the plan has room for an upload of given size in bytes
+func (u *TenantUsage) CanStore(size int64) bool {
+	return u.Plan.MaxStorage == 0 || u.Storage+size <= u.Plan.MaxStorage
+}
+
+//ChangeTenantPlan is the input model used by operators to change the plan of a tenant
+type ChangeTenantPlan struct {
+	TenantID int    `route:"id"`
+	Plan     string `json:"plan"`
+}
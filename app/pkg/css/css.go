@@ -0,0 +1,41 @@
+package css
+
+import (
+	"regexp"
+	"strings"
+)
+
+//Rules that could run scripts or load content from elsewhere in older browsers
+var unsafe = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)@import[^;]*;?`),
+	regexp.MustCompile(`(?i)@charset[^;]*;?`),
+	regexp.MustCompile(`(?i)expression\s*\(`),
+	regexp.MustCompile(`(?i)javascript\s*:`),
+	regexp.MustCompile(`(?i)vbscript\s*:`),
+	regexp.MustCompile(`(?i)behavior\s*:[^;}]*;?`),
+	regexp.MustCompile(`(?i)-moz-binding\s*:[^;}]*;?`),
+}
+
+var comments = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// Sanitize removes everything from given stylesheet that can be used to run scripts or break out of a <style> tag
+func Sanitize(input string) string {
+	//Escapes and comments can hide any of the unsafe rules, so they go first
+	output := comments.ReplaceAllString(input, "")
+	output = strings.Replace(output, "\\", "", -1)
+	output = strings.Replace(output, "<", "", -1)
+
+	//Removing a rule can join the text around it into a new one, so repeat until nothing changes
+	for {
+		sanitized := output
+		for _, rule := range unsafe {
+			sanitized = rule.ReplaceAllString(sanitized, "")
+		}
+		if sanitized == output {
+			break
+		}
+		output = sanitized
+	}
+
+	return strings.TrimSpace(output)
+}
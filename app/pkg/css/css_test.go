@@ -0,0 +1,30 @@
+package css_test
+
+import (
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/css"
+)
+
+func TestSanitize(t *testing.T) {
+	RegisterT(t)
+
+	for input, expected := range map[string]string{
+		"":                                "",
+		".header { color: red; }":         ".header { color: red; }",
+		"ul > li { margin: 0 }":           "ul > li { margin: 0 }",
+		"/* comment */ a { color: blue }": "a { color: blue }",
+		"@import url(http://evil.com/x.css); a {}":     "a {}",
+		"a { width: expression(alert(1)) }":            "a { width: alert(1)) }",
+		"a { background: url(javascript:alert(1)) }":   "a { background: url(alert(1)) }",
+		"a { background: url(java\\script:alert(1)) }": "a { background: url(alert(1)) }",
+		"a { -moz-binding: url(x.xml#x) }":             "a { }",
+		"a { behavior: url(x.htc) }":                   "a { }",
+		"</style><script>alert(1)</script>":            "/style>script>alert(1)/script>",
+		"@imp@importort 'x.css';":                      "@imp",
+		"a { color: red } /* </style> */":              "a { color: red }",
+	} {
+		Expect(css.Sanitize(input)).Equals(expected)
+	}
+}
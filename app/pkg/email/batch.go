@@ -0,0 +1,46 @@
+package email
+
+import "html/template"
+
+// placeholder is a recipient specific param that is replaced by the email provider on delivery
+type placeholder struct {
+	name   string
+	format func(name string) string
+}
+
+func (p placeholder) HTML() template.HTML {
+	return template.HTML(p.format(p.name))
+}
+
+func (p placeholder) Text() string {
+	return p.format(p.name + "_text")
+}
+
+// WithPlaceholders replaces recipient specific params with provider variables named by given format
+// so that a batch email is rendered only once. Use RecipientVariables to get the values of each recipient
+func WithPlaceholders(params Params, recipient Params, format func(name string) string) Params {
+	result := Params{}.Merge(params)
+	for k, v := range recipient {
+		if _, ok := v.(Alternative); ok {
+			result[k] = placeholder{k, format}
+		} else {
+			result[k] = format(k)
+		}
+	}
+	return result
+}
+
+// RecipientVariables returns the provider variables of given recipient params
+// Alternative params are split into an HTML variable and a plain text one, suffixed with _text
+func RecipientVariables(params Params) Params {
+	result := make(Params, len(params))
+	for k, v := range params {
+		if alternative, ok := v.(Alternative); ok {
+			result[k] = alternative.HTML()
+			result[k+"_text"] = alternative.Text()
+		} else {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package email
+
+import (
+	"strings"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+// DeliveryLog is used to record email deliveries and to check the suppression list of a tenant
+type DeliveryLog interface {
+	Suppressions(tenant *models.Tenant) []*models.EmailSuppression
+	Record(tenant *models.Tenant, deliveries []*models.EmailDelivery)
+}
+
+var deliveryLog DeliveryLog
+
+// SetDeliveryLog is used to define where email deliveries are recorded
+func SetDeliveryLog(l DeliveryLog) {
+	deliveryLog = l
+}
+
+// suppressedAddresses returns the lowercased addresses on the suppression list of given tenant
+func suppressedAddresses(tenant *models.Tenant) map[string]bool {
+	addresses := make(map[string]bool)
+	if deliveryLog != nil && tenant != nil {
+		for _, s := range deliveryLog.Suppressions(tenant) {
+			addresses[strings.ToLower(s.Address)] = true
+		}
+	}
+	return addresses
+}
+
+// Filter returns the recipients that are allowed to receive emails from given tenant
+// Recipients without address or not on the whitelist are skipped and the suppressed ones are recorded as such
+func Filter(logger log.Logger, tenant *models.Tenant, provider, templateName string, to []Recipient) []Recipient {
+	addresses := suppressedAddresses(tenant)
+	allowed := make([]Recipient, 0, len(to))
+	suppressed := make([]Recipient, 0)
+	for _, r := range to {
+		if r.Address == "" {
+			continue
+		}
+		if !CanSendTo(r.Address) {
+			logger.Warnf("Skipping email to '%s <%s>' due to whitelist.", r.Name, r.Address)
+		} else if addresses[strings.ToLower(r.Address)] {
+			logger.Warnf("Skipping email to '%s <%s>' due to suppression list.", r.Name, r.Address)
+			suppressed = append(suppressed, r)
+		} else {
+			allowed = append(allowed, r)
+		}
+	}
+
+	Record(tenant, provider, templateName, suppressed, models.EmailDeliverySuppressed, "")
+	return allowed
+}
+
+// Record adds an entry to the delivery log of given tenant for each recipient
+func Record(tenant *models.Tenant, provider, templateName string, to []Recipient, status models.EmailDeliveryStatus, response string) {
+	if deliveryLog == nil || tenant == nil || len(to) == 0 {
+		return
+	}
+
+	deliveries := make([]*models.EmailDelivery, len(to))
+	for i, r := range to {
+		deliveries[i] = &models.EmailDelivery{
+			TemplateName: templateName,
+			Address:      r.Address,
+			Provider:     provider,
+			Status:       status,
+			Response:     response,
+		}
+	}
+	deliveryLog.Record(tenant, deliveries)
+}
+
+// RecordResult adds an entry to the delivery log of given tenant for each recipient based on the result of a send
+func RecordResult(tenant *models.Tenant, provider, templateName string, to []Recipient, response string, err error) {
+	if err != nil {
+		if response == "" {
+			response = errors.Cause(err).Error()
+		}
+		Record(tenant, provider, templateName, to, models.EmailDeliveryFailed, response)
+	} else {
+		Record(tenant, provider, templateName, to, models.EmailDeliverySent, response)
+	}
+}
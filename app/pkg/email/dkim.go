@@ -0,0 +1,147 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+// dkimHeaders are the headers that are signed when present on the message
+var dkimHeaders = []string{"from", "to", "subject", "reply-to", "message-id", "in-reply-to", "references", "mime-version", "content-type"}
+
+// ParseDKIMKey parses a PEM encoded RSA private key, either PKCS#1 or PKCS#8
+func ParseDKIMKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not a RSA key")
+	}
+	return rsaKey, nil
+}
+
+// SignMessage adds a DKIM signature to given raw message when tenant has a DKIM key for its verified domain
+func SignMessage(tenant *models.Tenant, message []byte) ([]byte, error) {
+	if tenant == nil || !tenant.Email.IsVerified() || !tenant.Email.HasDKIM() {
+		return message, nil
+	}
+	return SignDKIM(message, tenant.Email.Domain(), tenant.Email.DKIMSelector, tenant.Email.DKIMPrivateKey, time.Now())
+}
+
+// SignDKIM returns given raw message with a DKIM-Signature header using relaxed/relaxed canonicalization and rsa-sha256
+func SignDKIM(message []byte, domain, selector, privateKey string, now time.Time) ([]byte, error) {
+	key, err := ParseDKIMKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header, body := splitMessage(message)
+	fields := parseHeaderFields(header)
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	signed := make([]string, 0)
+	var canonical bytes.Buffer
+	for _, name := range dkimHeaders {
+		if value, ok := fields[name]; ok {
+			signed = append(signed, name)
+			canonical.WriteString(relaxedHeader(name, value))
+			canonical.WriteString("\r\n")
+		}
+	}
+
+	signature := fmt.Sprintf("v=1; a=rsa-sha256; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		domain, selector, now.Unix(), strings.Join(signed, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+	canonical.WriteString(relaxedHeader("dkim-signature", signature))
+
+	hash := sha256.Sum256(canonical.Bytes())
+	b, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign message")
+	}
+
+	var result bytes.Buffer
+	result.WriteString("DKIM-Signature: ")
+	result.WriteString(signature)
+	result.WriteString(base64.StdEncoding.EncodeToString(b))
+	result.WriteString("\r\n")
+	result.Write(message)
+	return result.Bytes(), nil
+}
+
+// splitMessage returns the header and body sections of a raw message
+func splitMessage(message []byte) (string, string) {
+	parts := strings.SplitN(string(message), "\r\n\r\n", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// parseHeaderFields returns the unfolded value of each header field indexed by its lowercase name
+func parseHeaderFields(header string) map[string]string {
+	fields := make(map[string]string)
+	lastName := ""
+	for _, line := range strings.Split(header, "\r\n") {
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && lastName != "" {
+			fields[lastName] += line
+			continue
+		}
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		lastName = strings.ToLower(strings.TrimSpace(line[:colon]))
+		fields[lastName] = line[colon+1:]
+	}
+	return fields
+}
+
+// relaxedHeader returns the relaxed canonicalization of a header field as defined on RFC 6376 3.4.2
+func relaxedHeader(name, value string) string {
+	return strings.ToLower(name) + ":" + strings.Join(strings.Fields(value), " ")
+}
+
+// relaxedBody returns the relaxed canonicalization of a message body as defined on RFC 6376 3.4.4
+func relaxedBody(body string) []byte {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		lines[i] = strings.Join(strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' }), " ")
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			lines[i] = " " + lines[i]
+		}
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
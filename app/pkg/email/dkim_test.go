@@ -0,0 +1,93 @@
+package email_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/email"
+)
+
+func generateKey() (*rsa.PrivateKey, string) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func TestSignDKIM(t *testing.T) {
+	RegisterT(t)
+
+	key, privateKey := generateKey()
+	message := "From: Demo <feedback@demo.com>\r\n" +
+		"To:  Jon Snow\r\n <jon.snow@got.com>\r\n" +
+		"Subject: Hello   World \r\n" +
+		"X-Custom: ignored\r\n" +
+		"\r\n" +
+		"Hello  World \r\n" +
+		"\r\n" +
+		"\r\n"
+
+	now := time.Unix(1527500000, 0)
+	signed, err := email.SignDKIM([]byte(message), "demo.com", "fider", privateKey, now)
+	Expect(err).IsNil()
+
+	lines := strings.SplitN(string(signed), "\r\n", 2)
+	Expect(lines[1]).Equals(message)
+
+	// Body is "Hello World\r\n" after relaxed canonicalization
+	bodyHash := sha256.Sum256([]byte("Hello World\r\n"))
+	value := strings.TrimPrefix(lines[0], "DKIM-Signature: ")
+	prefix := "v=1; a=rsa-sha256; c=relaxed/relaxed; d=demo.com; s=fider; t=1527500000; h=from:to:subject; bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) + "; b="
+	Expect(strings.HasPrefix(value, prefix)).IsTrue()
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	Expect(err).IsNil()
+
+	canonical := "from:Demo <feedback@demo.com>\r\n" +
+		"to:Jon Snow <jon.snow@got.com>\r\n" +
+		"subject:Hello World\r\n" +
+		"dkim-signature:" + prefix
+	hash := sha256.Sum256([]byte(canonical))
+	Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], signature)).IsNil()
+}
+
+func TestSignDKIM_InvalidKey(t *testing.T) {
+	RegisterT(t)
+
+	signed, err := email.SignDKIM([]byte("Subject: Hi\r\n\r\nHello"), "demo.com", "fider", "not a key", time.Now())
+	Expect(err).IsNotNil()
+	Expect(signed).IsNil()
+}
+
+func TestSignMessage_OnlyVerifiedDomains(t *testing.T) {
+	RegisterT(t)
+
+	_, privateKey := generateKey()
+	message := []byte("Subject: Hi\r\n\r\nHello")
+	tenant := &models.Tenant{Email: models.TenantEmailSettings{
+		FromAddress:    "feedback@demo.com",
+		DKIMSelector:   "fider",
+		DKIMPrivateKey: privateKey,
+	}}
+
+	signed, err := email.SignMessage(tenant, message)
+	Expect(err).IsNil()
+	Expect(signed).Equals(message)
+
+	tenant.Email.VerifiedDomain = "demo.com"
+	signed, err = email.SignMessage(tenant, message)
+	Expect(err).IsNil()
+	Expect(string(signed)).ContainsSubstring("DKIM-Signature: v=1; a=rsa-sha256; c=relaxed/relaxed; d=demo.com; s=fider;")
+
+	signed, err = email.SignMessage(nil, message)
+	Expect(err).IsNil()
+	Expect(signed).Equals(message)
+}
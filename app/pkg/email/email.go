@@ -3,14 +3,19 @@ package email
 import (
 	"bytes"
 	"html/template"
+	"io/ioutil"
 	"regexp"
 	"strings"
 
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
+	"github.com/getfider/fider/app/pkg/markdown"
 )
 
 var cache = make(map[string]*template.Template, 0)
+var subjects = make(map[string]string, 0)
 
 // Params used to replace variables on emails
 type Params map[string]interface{}
@@ -25,12 +30,45 @@ func (p Params) Merge(p2 Params) Params {
 
 // Message represents what is sent by email
 type Message struct {
-	Subject string
-	Body    string
+	Subject   string
+	Body      string
+	PlainText string
 }
 
-// RenderMessage returns the HTML of an email based on template and params
-func RenderMessage(templateName string, params Params) *Message {
+// Alternative is a param value that is represented differently on the HTML body and on its plain text alternative
+type Alternative interface {
+	HTML() template.HTML
+	Text() string
+}
+
+// Markdown is a param value written in markdown
+type Markdown string
+
+// HTML returns the markdown content as HTML
+func (m Markdown) HTML() template.HTML {
+	return markdown.Parse(string(m))
+}
+
+// Text returns the markdown content as plain text
+func (m Markdown) Text() string {
+	return markdown.PlainText(string(m))
+}
+
+// TemplateSource finds the templates customized by current tenant, e.g. the EmailTemplates storage of a request or task
+type TemplateSource interface {
+	Get(name, locale string) (*models.EmailTemplate, error)
+}
+
+// RenderMessage returns the HTML and plain text of an email based on template and params on given locale
+// Templates customized by the tenant take precedence over the built-in ones
+func RenderMessage(templates TemplateSource, templateName, locale string, params Params) *Message {
+	if custom := loadTemplate(templates, templateName, locale); custom != nil {
+		message, err := RenderTemplate(custom.Subject, custom.Body, params)
+		if err == nil {
+			return message
+		}
+	}
+
 	tpl, ok := cache[templateName]
 	if !ok || env.IsDevelopment() {
 		var err error
@@ -40,24 +78,149 @@ func RenderMessage(templateName string, params Params) *Message {
 			panic(err)
 		}
 		cache[templateName] = tpl
+		subjects[templateName] = readSubject(file)
 	}
 
-	var bf bytes.Buffer
-	tpl.Execute(&bf, params)
+	var bf, textBf bytes.Buffer
+	tpl.Execute(&bf, htmlParams(params))
+	tpl.Execute(&textBf, textParams(params))
 	lines := strings.Split(bf.String(), "\n")
+	textLines := strings.Split(textBf.String(), "\n")
 	return &Message{
-		Subject: strings.TrimLeft(lines[0], "subject: "),
-		Body:    strings.TrimLeft(strings.Join(lines[2:], "\n"), " "),
+		Subject:   translateSubject(locale, subjects[templateName], strings.TrimPrefix(lines[0], "subject: "), params),
+		Body:      strings.TrimLeft(strings.Join(lines[2:], "\n"), " "),
+		PlainText: htmlToText(strings.Join(textLines[2:], "\n")),
+	}
+}
+
+// readSubject returns the subject line of a built-in template before it's executed
+func readSubject(file string) string {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		panic(err)
+	}
+	line := strings.SplitN(string(content), "\n", 2)[0]
+	return strings.TrimSpace(strings.TrimPrefix(line, "subject: "))
+}
+
+// translateSubject returns the subject of a built-in template on given locale
+// The raw subject is the message key, so its translation can use the same template variables
+func translateSubject(locale, raw, rendered string, params Params) string {
+	if locale == "" || raw == "" {
+		return rendered
+	}
+
+	translated := i18n.T(locale, raw)
+	if translated == raw {
+		return rendered
+	}
+
+	tpl, err := template.New("subject").Parse(translated)
+	if err != nil {
+		return rendered
+	}
+
+	var bf bytes.Buffer
+	if err := tpl.Execute(&bf, htmlParams(params)); err != nil {
+		return rendered
+	}
+	return strings.TrimSpace(bf.String())
+}
+
+// RenderTemplate returns the HTML and plain text of an email based on given subject and body templates
+func RenderTemplate(subject, body string, params Params) (*Message, error) {
+	subjectTpl, bodyTpl, err := ParseTemplate(subject, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjectBf, bodyBf, textBf bytes.Buffer
+	if err := subjectTpl.Execute(&subjectBf, htmlParams(params)); err != nil {
+		return nil, errors.Wrap(err, "failed to render email subject")
+	}
+	if err := bodyTpl.Execute(&bodyBf, htmlParams(params)); err != nil {
+		return nil, errors.Wrap(err, "failed to render email body")
 	}
+	if err := bodyTpl.Execute(&textBf, textParams(params)); err != nil {
+		return nil, errors.Wrap(err, "failed to render email plain text body")
+	}
+
+	return &Message{
+		Subject:   strings.TrimSpace(subjectBf.String()),
+		Body:      strings.TrimSpace(bodyBf.String()),
+		PlainText: htmlToText(textBf.String()),
+	}, nil
+}
+
+// ParseTemplate parses given subject and body templates
+func ParseTemplate(subject, body string) (*template.Template, *template.Template, error) {
+	subjectTpl, err := template.New("subject").Parse(subject)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse email subject")
+	}
+	bodyTpl, err := template.New("body").Parse(body)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse email body")
+	}
+	return subjectTpl, bodyTpl, nil
+}
+
+func htmlParams(params Params) Params {
+	result := make(Params, len(params))
+	for k, v := range params {
+		if alternative, ok := v.(Alternative); ok {
+			v = alternative.HTML()
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func textParams(params Params) Params {
+	result := make(Params, len(params))
+	for k, v := range params {
+		if alternative, ok := v.(Alternative); ok {
+			v = alternative.Text()
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func loadTemplate(templates TemplateSource, templateName, locale string) *models.EmailTemplate {
+	if templates == nil {
+		return nil
+	}
+
+	for _, fallback := range fallbackLocales(locale) {
+		custom, err := templates.Get(templateName, fallback)
+		if err == nil && custom != nil {
+			return custom
+		}
+	}
+	return nil
+}
+
+// fallbackLocales returns given locale followed by its base language, e.g. pt-BR, pt
+func fallbackLocales(locale string) []string {
+	if locale == "" {
+		return []string{}
+	}
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		return []string{locale, locale[0:idx]}
+	}
+	return []string{locale}
 }
 
 // NoReply is the default 'from' address
 var NoReply = env.MustGet("EMAIL_NOREPLY")
 
 // Recipient contains details of who is receiving the email
+// Recipients without a locale receive emails on the locale of the tenant
 type Recipient struct {
 	Name    string
 	Address string
+	Locale  string
 	Params  Params
 }
 
@@ -70,6 +233,44 @@ func NewRecipient(name, address string, params Params) Recipient {
 	}
 }
 
+// NewUserRecipient creates a new Recipient that receives emails on the locale chosen by given user
+func NewUserRecipient(user *models.User, params Params) Recipient {
+	return Recipient{
+		Name:    user.Name,
+		Address: user.Email,
+		Locale:  user.Locale,
+		Params:  params,
+	}
+}
+
+// LocaleOf returns the locale that emails of given tenant are rendered on for given recipient
+func LocaleOf(tenant *models.Tenant, to Recipient) string {
+	if to.Locale != "" {
+		return to.Locale
+	}
+	if tenant != nil {
+		return tenant.Locale
+	}
+	return ""
+}
+
+// GroupByLocale splits recipients by the locale their emails are rendered on, keeping their order
+func GroupByLocale(tenant *models.Tenant, to []Recipient) [][]Recipient {
+	groups := make([][]Recipient, 0)
+	indexes := make(map[string]int)
+	for _, r := range to {
+		locale := LocaleOf(tenant, r)
+		idx, ok := indexes[locale]
+		if !ok {
+			idx = len(groups)
+			indexes[locale] = idx
+			groups = append(groups, []Recipient{})
+		}
+		groups[idx] = append(groups[idx], r)
+	}
+	return groups
+}
+
 var whitelist = env.GetEnvOrDefault("EMAIL_WHITELIST", "")
 var whitelistRegex = regexp.MustCompile(whitelist)
 
@@ -91,9 +292,10 @@ func CanSendTo(address string) bool {
 }
 
 // Sender is used to send emails
+// Templates customized by the tenant are found on given source, which can be nil when there's no tenant
 type Sender interface {
-	Send(tenant *models.Tenant, templateName string, params Params, from string, to Recipient) error
-	BatchSend(tenant *models.Tenant, templateName string, params Params, from string, to []Recipient) error
+	Send(tenant *models.Tenant, templates TemplateSource, templateName string, params Params, from string, to Recipient) error
+	BatchSend(tenant *models.Tenant, templates TemplateSource, templateName string, params Params, from string, to []Recipient) error
 }
 
 // NoopSender does not send emails
@@ -106,12 +308,12 @@ func NewNoopSender() *NoopSender {
 }
 
 // Send an email
-func (s *NoopSender) Send(tenant *models.Tenant, templateName string, params Params, from string, to Recipient) error {
+func (s *NoopSender) Send(tenant *models.Tenant, templates TemplateSource, templateName string, params Params, from string, to Recipient) error {
 	return nil
 
 }
 
 // BatchSend an email to multiple recipients
-func (s *NoopSender) BatchSend(tenant *models.Tenant, templateName string, params Params, from string, to []Recipient) error {
+func (s *NoopSender) BatchSend(tenant *models.Tenant, templates TemplateSource, templateName string, params Params, from string, to []Recipient) error {
 	return nil
 }
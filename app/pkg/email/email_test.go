@@ -1,9 +1,14 @@
 package email_test
 
 import (
+	"errors"
+	"fmt"
+	"html/template"
 	"testing"
 
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/log"
 
 	. "github.com/getfider/fider/app/pkg/assert"
 )
@@ -11,13 +16,143 @@ import (
 func TestRenderMessage(t *testing.T) {
 	RegisterT(t)
 
-	message := email.RenderMessage("echo_test", email.Params{
+	message := email.RenderMessage(nil, "echo_test", "", email.Params{
 		"name": "Fider",
 	})
 	Expect(message.Subject).Equals("Message to: Fider")
 	Expect(message.Body).Equals("Hello World Fider!")
 }
 
+func TestRenderMessage_TranslatedSubject(t *testing.T) {
+	RegisterT(t)
+
+	params := email.Params{"tenantName": "Demonstration"}
+
+	message := email.RenderMessage(nil, "signin_email", "pt-BR", params)
+	Expect(message.Subject).Equals("Entrar em Demonstration")
+
+	message = email.RenderMessage(nil, "signin_email", "en", params)
+	Expect(message.Subject).Equals("Sign in to Demonstration")
+
+	message = email.RenderMessage(nil, "signin_email", "", params)
+	Expect(message.Subject).Equals("Sign in to Demonstration")
+}
+
+func TestRenderMessage_CustomTemplate(t *testing.T) {
+	RegisterT(t)
+
+	templates := templateSource{
+		"echo_test/pt": &models.EmailTemplate{Subject: "Mensagem para: {{ .name }}", Body: "Olá {{ .name }}!"},
+	}
+
+	params := email.Params{"name": "Fider"}
+
+	message := email.RenderMessage(templates, "echo_test", "pt-BR", params)
+	Expect(message.Subject).Equals("Mensagem para: Fider")
+	Expect(message.Body).Equals("Olá Fider!")
+
+	message = email.RenderMessage(templates, "echo_test", "en", params)
+	Expect(message.Subject).Equals("Message to: Fider")
+	Expect(message.Body).Equals("Hello World Fider!")
+
+	message = email.RenderMessage(nil, "echo_test", "pt", params)
+	Expect(message.Subject).Equals("Message to: Fider")
+	Expect(message.Body).Equals("Hello World Fider!")
+}
+
+func TestLocaleOf(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{ID: 1, Locale: "pt-BR"}
+	user := &models.User{Name: "Jon Snow", Email: "jon.snow@got.com", Locale: "en"}
+
+	Expect(email.LocaleOf(tenant, email.NewUserRecipient(user, email.Params{}))).Equals("en")
+	Expect(email.LocaleOf(tenant, email.NewRecipient("", "arya.stark@got.com", email.Params{}))).Equals("pt-BR")
+	Expect(email.LocaleOf(nil, email.NewRecipient("", "arya.stark@got.com", email.Params{}))).Equals("")
+}
+
+func TestGroupByLocale(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{ID: 1, Locale: "pt-BR"}
+	groups := email.GroupByLocale(tenant, []email.Recipient{
+		email.NewUserRecipient(&models.User{Email: "jon.snow@got.com", Locale: "en"}, email.Params{}),
+		email.NewRecipient("", "arya.stark@got.com", email.Params{}),
+		email.NewUserRecipient(&models.User{Email: "sansa.stark@got.com", Locale: "en"}, email.Params{}),
+	})
+
+	Expect(groups).HasLen(2)
+	Expect(groups[0]).HasLen(2)
+	Expect(groups[0][0].Address).Equals("jon.snow@got.com")
+	Expect(groups[0][1].Address).Equals("sansa.stark@got.com")
+	Expect(groups[1]).HasLen(1)
+	Expect(groups[1][0].Address).Equals("arya.stark@got.com")
+}
+
+type templateSource map[string]*models.EmailTemplate
+
+func (s templateSource) Get(name, locale string) (*models.EmailTemplate, error) {
+	if template, ok := s[name+"/"+locale]; ok {
+		return template, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestRenderTemplate_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	message, err := email.RenderTemplate("{{ .name", "Hello", email.Params{})
+	Expect(message).IsNil()
+	Expect(err).IsNotNil()
+
+	message, err = email.RenderTemplate("Hi", "{{ unknown .name }}", email.Params{})
+	Expect(message).IsNil()
+	Expect(err).IsNotNil()
+}
+
+func TestRenderMessage_PlainText(t *testing.T) {
+	RegisterT(t)
+
+	message := email.RenderMessage(nil, "new_comment", "", email.Params{
+		"title":       "[Demonstration] Add support for dark mode",
+		"content":     email.Markdown("We are **working** on it!"),
+		"view":        template.HTML("<a href='http://demo.test.fider.io/ideas/1/dark-mode'>View it on your browser</a>"),
+		"unsubscribe": template.HTML("<a href='http://demo.test.fider.io/ideas/1/dark-mode'>unsubscribe from it</a>"),
+		"change":      template.HTML("<a href='http://demo.test.fider.io/settings'>change your notification settings</a>"),
+	})
+	Expect(message.Subject).Equals("[Demonstration] Add support for dark mode")
+	Expect(message.Body).ContainsSubstring("<p>We are <strong>working</strong> on it!</p>")
+	Expect(message.PlainText).Equals(`We are working on it!
+
+—
+You are receiving this because you are subscribed to this thread. Please do not reply to this email.
+View it on your browser (http://demo.test.fider.io/ideas/1/dark-mode), unsubscribe from it (http://demo.test.fider.io/ideas/1/dark-mode) or change your notification settings (http://demo.test.fider.io/settings).`)
+}
+
+func TestNewIdeaThread(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{ID: 2}
+	idea := &models.Idea{ID: 5}
+
+	thread := email.NewIdeaThread(tenant, idea, false)
+	Expect(thread.Headers()).Equals(map[string]string{
+		"Message-ID": "<ideas.2.5@random.org>",
+	})
+
+	reply := email.NewIdeaThread(tenant, idea, true)
+	headers := reply.Headers()
+	Expect(headers["Message-ID"]).NotEquals("<ideas.2.5@random.org>")
+	Expect(headers["Message-ID"]).ContainsSubstring("<ideas.2.5.")
+	Expect(headers["In-Reply-To"]).Equals("<ideas.2.5@random.org>")
+	Expect(headers["References"]).Equals("<ideas.2.5@random.org>")
+
+	params := email.Params{}
+	Expect(params.Thread()).IsNil()
+	params.SetThread(reply)
+	Expect(params.Thread()).Equals(reply)
+}
+
 func TestEmailWhitelist_Valid(t *testing.T) {
 	RegisterT(t)
 
@@ -64,3 +199,70 @@ func TestParamsMerge(t *testing.T) {
 		"email": "john.snow@got.com",
 	})
 }
+
+func TestNewSender_Providers(t *testing.T) {
+	RegisterT(t)
+
+	email.RegisterProvider("test_provider", func(logger log.Logger) email.Sender {
+		return email.NewNoopSender()
+	})
+
+	Expect(email.Providers()).Equals([]string{"test_provider"})
+	Expect(func() {
+		email.RegisterProvider("test_provider", nil)
+	}).Panics()
+
+	sender, err := email.NewSender("test_provider", log.NewNoopLogger())
+	Expect(err).IsNil()
+	Expect(sender).IsNotNil()
+
+	sender, err = email.NewSender("unknown_provider", log.NewNoopLogger())
+	Expect(err).IsNotNil()
+	Expect(sender).IsNil()
+}
+
+type fakeDeliveryLog struct {
+	deliveries []*models.EmailDelivery
+}
+
+func (l *fakeDeliveryLog) Suppressions(tenant *models.Tenant) []*models.EmailSuppression {
+	return []*models.EmailSuppression{
+		{Address: "Arya.Stark@got.com", Reason: "bounce"},
+	}
+}
+
+func (l *fakeDeliveryLog) Record(tenant *models.Tenant, deliveries []*models.EmailDelivery) {
+	l.deliveries = append(l.deliveries, deliveries...)
+}
+
+func TestFilterAndRecord(t *testing.T) {
+	RegisterT(t)
+
+	deliveryLog := &fakeDeliveryLog{}
+	email.SetDeliveryLog(deliveryLog)
+	defer email.SetDeliveryLog(nil)
+
+	email.SetWhitelist("^.*@got.com$")
+	defer email.SetWhitelist("")
+
+	tenant := &models.Tenant{ID: 1}
+	recipients := email.Filter(log.NewNoopLogger(), tenant, "smtp", "new_idea", []email.Recipient{
+		email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{}),
+		email.NewRecipient("Arya Stark", "arya.stark@got.com", email.Params{}),
+		email.NewRecipient("Tony Stark", "tony.stark@avengers.com", email.Params{}),
+		email.NewRecipient("Nobody", "", email.Params{}),
+	})
+	Expect(recipients).HasLen(1)
+	Expect(recipients[0].Address).Equals("jon.snow@got.com")
+	Expect(deliveryLog.deliveries).HasLen(1)
+	Expect(deliveryLog.deliveries[0].Address).Equals("arya.stark@got.com")
+	Expect(deliveryLog.deliveries[0].Status).Equals(models.EmailDeliverySuppressed)
+
+	email.RecordResult(tenant, "smtp", "new_idea", recipients, "", fmt.Errorf("connection refused"))
+	Expect(deliveryLog.deliveries).HasLen(2)
+	Expect(deliveryLog.deliveries[1].Status).Equals(models.EmailDeliveryFailed)
+	Expect(deliveryLog.deliveries[1].Response).Equals("connection refused")
+
+	email.RecordResult(nil, "smtp", "signup_email", recipients, "", nil)
+	Expect(deliveryLog.deliveries).HasLen(2)
+}
@@ -0,0 +1,246 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+const (
+	// Postmark sends emails using Postmark's batch API
+	Postmark = "postmark"
+	// SendGrid sends emails using SendGrid's v3 mail API
+	SendGrid = "sendgrid"
+)
+
+var defaultURLs = map[string]string{
+	Postmark: "https://api.postmarkapp.com",
+	SendGrid: "https://api.sendgrid.com",
+}
+
+var batchSizes = map[string]int{
+	Postmark: 500,
+	SendGrid: 1000,
+}
+
+func init() {
+	email.RegisterProvider("http", func(logger log.Logger) email.Sender {
+		format := env.MustGet("EMAIL_HTTP_FORMAT")
+		return NewSender(
+			logger,
+			format,
+			env.GetEnvOrDefault("EMAIL_HTTP_URL", defaultURLs[format]),
+			env.MustGet("EMAIL_HTTP_API_KEY"),
+		)
+	})
+}
+
+//Sender is used to send emails through JSON over HTTP APIs like Postmark and SendGrid
+type Sender struct {
+	logger  log.Logger
+	format  string
+	baseURL string
+	apiKey  string
+}
+
+//NewSender creates a new HTTP API email sender for given format
+func NewSender(logger log.Logger, format, baseURL, apiKey string) *Sender {
+	if _, ok := defaultURLs[format]; !ok {
+		panic(fmt.Sprintf("unknown email HTTP API format '%s'", format))
+	}
+	return &Sender{logger, format, baseURL, apiKey}
+}
+
+//Send an email
+func (s *Sender) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	return s.BatchSend(tenant, templates, templateName, params, from, []email.Recipient{to})
+}
+
+// BatchSend an email to multiple recipients
+// SendGrid renders a single message per request, so recipients on each locale are sent on their own requests
+func (s *Sender) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
+	recipients := email.Filter(s.logger, tenant, s.format, templateName, to)
+
+	size := batchSizes[s.format]
+	for _, group := range email.GroupByLocale(tenant, recipients) {
+		for start := 0; start < len(group); start += size {
+			end := start + size
+			if end > len(group) {
+				end = len(group)
+			}
+
+			var response string
+			var err error
+			if s.format == Postmark {
+				response, err = s.sendPostmark(tenant, templates, templateName, params, from, group[start:end])
+			} else {
+				response, err = s.sendSendGrid(tenant, templates, templateName, params, from, group[start:end])
+			}
+
+			email.RecordResult(tenant, s.format, templateName, group[start:end], response, err)
+			if err != nil {
+				return errors.Wrap(err, "failed to batch send email to %d recipients", len(to))
+			}
+		}
+	}
+	return nil
+}
+
+type postmarkHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type postmarkMessage struct {
+	From     string            `json:"From"`
+	To       string            `json:"To"`
+	Subject  string            `json:"Subject"`
+	ReplyTo  string            `json:"ReplyTo,omitempty"`
+	HTMLBody string            `json:"HtmlBody"`
+	TextBody string            `json:"TextBody"`
+	Tag      string            `json:"Tag"`
+	Headers  []postmarkHeader  `json:"Headers,omitempty"`
+	Metadata map[string]string `json:"Metadata,omitempty"`
+}
+
+// sendPostmark renders one message per recipient and sends all of them in a single batch request
+func (s *Sender) sendPostmark(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) (string, error) {
+	headers := make([]postmarkHeader, 0)
+	for k, v := range params.Thread().Headers() {
+		headers = append(headers, postmarkHeader{Name: k, Value: v})
+	}
+
+	var metadata map[string]string
+	if tenant != nil && !env.IsSingleHostMode() {
+		metadata = map[string]string{"tenant": tenant.Subdomain}
+	}
+
+	messages := make([]*postmarkMessage, len(to))
+	for i, r := range to {
+		message := email.RenderMessage(templates, templateName, email.LocaleOf(tenant, r), email.Params{}.Merge(params).Merge(r.Params))
+		messages[i] = &postmarkMessage{
+			From:     fmt.Sprintf("%s <%s>", from, email.FromAddress(tenant)),
+			ReplyTo:  email.ReplyTo(tenant),
+			To:       fmt.Sprintf("%s <%s>", r.Name, r.Address),
+			Subject:  message.Subject,
+			HTMLBody: message.Body,
+			TextBody: message.PlainText,
+			Tag:      templateName,
+			Headers:  headers,
+			Metadata: metadata,
+		}
+	}
+
+	return s.post(templateName, "/email/batch", map[string]string{"X-Postmark-Server-Token": s.apiKey}, messages)
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To            []sendGridAddress `json:"to"`
+	Substitutions map[string]string `json:"substitutions,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+	Categories       []string                  `json:"categories"`
+}
+
+// sendSendGrid renders the message once and uses substitutions for recipient specific variables
+// Recipients must be on the same locale
+func (s *Sender) sendSendGrid(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) (string, error) {
+	variable := func(name string) string {
+		return fmt.Sprintf("%%recipient.%s%%", name)
+	}
+
+	message := email.RenderMessage(templates, templateName, email.LocaleOf(tenant, to[0]), email.WithPlaceholders(params, to[0].Params, variable))
+
+	personalizations := make([]sendGridPersonalization, len(to))
+	for i, r := range to {
+		substitutions := make(map[string]string)
+		for k, v := range email.RecipientVariables(r.Params) {
+			substitutions[variable(k)] = fmt.Sprintf("%v", v)
+		}
+		personalizations[i] = sendGridPersonalization{
+			To:            []sendGridAddress{{Email: r.Address, Name: r.Name}},
+			Substitutions: substitutions,
+		}
+	}
+
+	categories := []string{fmt.Sprintf("template:%s", templateName)}
+	if tenant != nil && !env.IsSingleHostMode() {
+		categories = append(categories, fmt.Sprintf("tenant:%s", tenant.Subdomain))
+	}
+
+	payload := &sendGridMessage{
+		Personalizations: personalizations,
+		From:             sendGridAddress{Email: email.FromAddress(tenant), Name: from},
+		Subject:          message.Subject,
+		Content: []sendGridContent{
+			{Type: "text/plain", Value: message.PlainText},
+			{Type: "text/html", Value: message.Body},
+		},
+		Headers:    params.Thread().Headers(),
+		Categories: categories,
+	}
+
+	if replyTo := email.ReplyTo(tenant); replyTo != "" {
+		payload.ReplyTo = &sendGridAddress{Email: replyTo}
+	}
+
+	return s.post(templateName, "/v3/mail/send", map[string]string{"Authorization": "Bearer " + s.apiKey}, payload)
+}
+
+func (s *Sender) post(templateName, path string, headers map[string]string, payload interface{}) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal email payload")
+	}
+
+	request, err := http.NewRequest("POST", s.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create POST request")
+	}
+
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	s.logger.Debugf("Sending email with template %s to %s.", templateName, s.format)
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to send email with template %s", templateName)
+	}
+
+	defer resp.Body.Close()
+	content, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return string(content), errors.New(fmt.Sprintf("failed to send email with template %s: %d %s", templateName, resp.StatusCode, string(content)))
+	}
+
+	s.logger.Debugf("Email sent with response code %d.", resp.StatusCode)
+	return string(content), nil
+}
@@ -0,0 +1,126 @@
+package httpapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/email/httpapi"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+type request struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func startServer() (*httptest.Server, chan *request) {
+	requests := make(chan *request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- &request{r.URL.Path, r.Header, body}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, requests
+}
+
+var recipients = []email.Recipient{
+	email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{"message": email.Markdown("Hello **Jon**")}),
+	email.NewRecipient("Tony Stark", "tony.stark@avengers.com", email.Params{"message": email.Markdown("Hello **Tony**")}),
+	email.NewRecipient("Arya Stark", "arya.stark@got.com", email.Params{"message": email.Markdown("Hello **Arya**")}),
+}
+
+func TestPostmark_BatchSend(t *testing.T) {
+	RegisterT(t)
+
+	server, requests := startServer()
+	defer server.Close()
+
+	email.SetWhitelist("^.*@got.com$")
+	defer email.SetWhitelist("")
+
+	tenant := &models.Tenant{ID: 1, Subdomain: "demo"}
+	params := email.Params{"subject": "Join us"}
+	thread := email.NewIdeaThread(tenant, &models.Idea{ID: 3}, false)
+	params.SetThread(thread)
+
+	sender := httpapi.NewSender(log.NewNoopLogger(), httpapi.Postmark, server.URL, "my-token")
+	err := sender.BatchSend(tenant, nil, "invite_email", params, "Fider", recipients)
+	Expect(err).IsNil()
+
+	req := <-requests
+	Expect(req.path).Equals("/email/batch")
+	Expect(req.header.Get("X-Postmark-Server-Token")).Equals("my-token")
+
+	var messages []map[string]interface{}
+	json.Unmarshal(req.body, &messages)
+	Expect(messages).HasLen(2)
+	Expect(messages[0]["To"]).Equals("Jon Snow <jon.snow@got.com>")
+	Expect(messages[0]["Subject"]).Equals("Join us")
+	Expect(messages[0]["Tag"]).Equals("invite_email")
+	Expect(messages[0]["HtmlBody"]).Equals("<p>Hello <strong>Jon</strong></p>\n")
+	Expect(messages[0]["TextBody"]).Equals("Hello Jon")
+	Expect(messages[1]["To"]).Equals("Arya Stark <arya.stark@got.com>")
+	Expect(messages[1]["TextBody"]).Equals("Hello Arya")
+	Expect(messages[1]["Headers"]).Equals([]interface{}{
+		map[string]interface{}{"Name": "Message-ID", "Value": thread.MessageID},
+	})
+}
+
+func TestSendGrid_BatchSend(t *testing.T) {
+	RegisterT(t)
+
+	server, requests := startServer()
+	defer server.Close()
+
+	email.SetWhitelist("^.*@got.com$")
+	defer email.SetWhitelist("")
+
+	tenant := &models.Tenant{ID: 1, Subdomain: "demo"}
+	sender := httpapi.NewSender(log.NewNoopLogger(), httpapi.SendGrid, server.URL, "my-key")
+	err := sender.BatchSend(tenant, nil, "invite_email", email.Params{"subject": "Join us"}, "Fider", recipients)
+	Expect(err).IsNil()
+
+	req := <-requests
+	Expect(req.path).Equals("/v3/mail/send")
+	Expect(req.header.Get("Authorization")).Equals("Bearer my-key")
+
+	payload := struct {
+		Personalizations []struct {
+			To []struct {
+				Email string `json:"email"`
+			} `json:"to"`
+			Substitutions map[string]string `json:"substitutions"`
+		} `json:"personalizations"`
+		Subject string `json:"subject"`
+		Content []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"content"`
+		Categories []string `json:"categories"`
+	}{}
+	json.Unmarshal(req.body, &payload)
+
+	Expect(payload.Subject).Equals("Join us")
+	Expect(payload.Content[0].Value).Equals("%recipient.message_text%")
+	Expect(payload.Content[1].Value).Equals("%recipient.message%")
+	Expect(payload.Personalizations).HasLen(2)
+	Expect(payload.Personalizations[0].To[0].Email).Equals("jon.snow@got.com")
+	Expect(payload.Personalizations[0].Substitutions["%recipient.message_text%"]).Equals("Hello Jon")
+	Expect(payload.Personalizations[1].Substitutions["%recipient.message%"]).Equals("<p>Hello <strong>Arya</strong></p>\n")
+	Expect(payload.Categories).Equals([]string{"template:invite_email", "tenant:demo"})
+}
+
+func TestNewSender_UnknownFormat(t *testing.T) {
+	RegisterT(t)
+
+	Expect(func() {
+		httpapi.NewSender(log.NewNoopLogger(), "unknown", "", "")
+	}).Panics()
+}
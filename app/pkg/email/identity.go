@@ -0,0 +1,66 @@
+package email
+
+import (
+	"net"
+	"strings"
+
+	"github.com/getfider/fider/app/models"
+)
+
+// FromAddress returns the address emails of given tenant are sent from
+// Tenants can only send from their own address once its domain has been verified
+func FromAddress(tenant *models.Tenant) string {
+	if tenant != nil && tenant.Email.IsVerified() {
+		return tenant.Email.FromAddress
+	}
+	return NoReply
+}
+
+// ReplyTo returns the address replies to emails of given tenant should be sent to, if any
+func ReplyTo(tenant *models.Tenant) string {
+	if tenant != nil {
+		return tenant.Email.ReplyTo
+	}
+	return ""
+}
+
+// IdentityHeaders returns the Reply-To header of given tenant merged into given headers
+func IdentityHeaders(tenant *models.Tenant, headers map[string]string) map[string]string {
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	if replyTo := ReplyTo(tenant); replyTo != "" {
+		headers["Reply-To"] = replyTo
+	}
+	return headers
+}
+
+var lookupTXT = net.LookupTXT
+
+// VerificationRecord returns the DNS TXT record a tenant adds to its domain to prove it owns it
+func VerificationRecord(token string) string {
+	return "fider-verification=" + token
+}
+
+// HasVerificationRecord returns true if given domain has the TXT record for given verification token
+func HasVerificationRecord(domain, token string) (bool, error) {
+	if domain == "" || token == "" {
+		return false, nil
+	}
+
+	records, err := lookupTXT(domain)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && !dnsErr.Temporary() {
+			return false, nil
+		}
+		return false, err
+	}
+
+	expected := VerificationRecord(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,57 @@
+package email
+
+import (
+	"net"
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestFromAddressAndReplyTo(t *testing.T) {
+	RegisterT(t)
+
+	tenant := &models.Tenant{Email: models.TenantEmailSettings{
+		FromAddress: "feedback@Demo.com",
+		ReplyTo:     "support@demo.com",
+	}}
+	Expect(FromAddress(nil)).Equals(NoReply)
+	Expect(FromAddress(tenant)).Equals(NoReply)
+	Expect(ReplyTo(tenant)).Equals("support@demo.com")
+	Expect(IdentityHeaders(tenant, nil)).Equals(map[string]string{"Reply-To": "support@demo.com"})
+	Expect(IdentityHeaders(nil, map[string]string{})).Equals(map[string]string{})
+
+	tenant.Email.VerifiedDomain = "demo.com"
+	Expect(FromAddress(tenant)).Equals("feedback@Demo.com")
+
+	tenant.Email.FromAddress = "feedback@other.com"
+	Expect(FromAddress(tenant)).Equals(NoReply)
+}
+
+func TestHasVerificationRecord(t *testing.T) {
+	RegisterT(t)
+
+	defer func() { lookupTXT = net.LookupTXT }()
+	lookupTXT = func(domain string) ([]string, error) {
+		if domain == "demo.com" {
+			return []string{"v=spf1 -all", "fider-verification=1234"}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: domain}
+	}
+
+	found, err := HasVerificationRecord("demo.com", "1234")
+	Expect(err).IsNil()
+	Expect(found).IsTrue()
+
+	found, err = HasVerificationRecord("demo.com", "4321")
+	Expect(err).IsNil()
+	Expect(found).IsFalse()
+
+	found, err = HasVerificationRecord("other.com", "1234")
+	Expect(err).IsNil()
+	Expect(found).IsFalse()
+
+	found, err = HasVerificationRecord("demo.com", "")
+	Expect(err).IsNil()
+	Expect(found).IsFalse()
+}
@@ -3,8 +3,10 @@ package mailgun
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/getfider/fider/app/models"
@@ -16,6 +18,12 @@ import (
 
 var baseURL = "https://api.mailgun.net/v3/%s/messages"
 
+func init() {
+	email.RegisterProvider("mailgun", func(logger log.Logger) email.Sender {
+		return NewSender(logger, env.MustGet("EMAIL_MAILGUN_DOMAIN"), env.MustGet("EMAIL_MAILGUN_API"))
+	})
+}
+
 //Sender is used to send emails
 type Sender struct {
 	logger log.Logger
@@ -29,52 +37,68 @@ func NewSender(logger log.Logger, domain, apiKey string) *Sender {
 }
 
 //Send an email
-func (s *Sender) Send(tenant *models.Tenant, templateName string, params email.Params, from string, to email.Recipient) error {
-	return s.BatchSend(tenant, templateName, params, from, []email.Recipient{to})
+func (s *Sender) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	return s.BatchSend(tenant, templates, templateName, params, from, []email.Recipient{to})
 }
 
 // BatchSend an email to multiple recipients
-func (s *Sender) BatchSend(tenant *models.Tenant, templateName string, params email.Params, from string, to []email.Recipient) error {
+// Mailgun renders a single message per request, so recipients on each locale are sent on their own request
+func (s *Sender) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
+	for _, group := range email.GroupByLocale(tenant, to) {
+		if err := s.sendBatch(tenant, templates, templateName, email.Params{}.Merge(params), from, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendBatch sends an email to recipients on the same locale
+func (s *Sender) sendBatch(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
 	if len(to) == 0 {
 		return nil
 	}
 
 	isBatch := len(to) > 1
+	locale := email.LocaleOf(tenant, to[0])
 
 	var message *email.Message
 	if isBatch {
 		// Replace recipient specific Go templates variables with Mailgun template variables
-		for k := range to[0].Params {
-			params[k] = fmt.Sprintf("%%recipient.%s%%", k)
-		}
-		message = email.RenderMessage(templateName, params)
+		params = email.WithPlaceholders(params, to[0].Params, func(name string) string {
+			return fmt.Sprintf("%%recipient.%s%%", name)
+		})
+		message = email.RenderMessage(templates, templateName, locale, params)
 	} else {
-		message = email.RenderMessage(templateName, params.Merge(to[0].Params))
+		message = email.RenderMessage(templates, templateName, locale, params.Merge(to[0].Params))
 	}
 
 	form := url.Values{}
-	form.Add("from", fmt.Sprintf("%s <%s>", from, email.NoReply))
+	form.Add("from", fmt.Sprintf("%s <%s>", from, email.FromAddress(tenant)))
 	form.Add("subject", message.Subject)
 	form.Add("html", message.Body)
+	form.Add("text", message.PlainText)
+	for k, v := range email.IdentityHeaders(tenant, params.Thread().Headers()) {
+		form.Add("h:"+k, v)
+	}
 	form.Add("o:tag", fmt.Sprintf("template:%s", templateName))
-	if tenant != nil && !env.IsSingleHostMode() {
-		form.Add("o:tag", fmt.Sprintf("tenant:%s", tenant.Subdomain))
+	if tenant != nil {
+		if !env.IsSingleHostMode() {
+			form.Add("o:tag", fmt.Sprintf("tenant:%s", tenant.Subdomain))
+		}
+		// Custom variables are sent back on webhook events
+		form.Add("v:tenant_id", strconv.Itoa(tenant.ID))
+		form.Add("v:template", templateName)
 	}
 
 	// Set Mailgun's var based on each recipient's variables
+	recipients := email.Filter(s.logger, tenant, "mailgun", templateName, to)
 	recipientVariables := make(map[string]email.Params, 0)
-	for _, r := range to {
-		if r.Address != "" {
-			if email.CanSendTo(r.Address) {
-				form.Add("to", fmt.Sprintf("%s <%s>", r.Name, r.Address))
-				recipientVariables[r.Address] = r.Params
-			} else {
-				s.logger.Warnf("Skipping email to '%s <%s>' due to whitelist.", r.Name, r.Address)
-			}
-		}
+	for _, r := range recipients {
+		form.Add("to", fmt.Sprintf("%s <%s>", r.Name, r.Address))
+		recipientVariables[r.Address] = email.RecipientVariables(r.Params)
 	}
 
-	// If we skipped all recipients due to whitelist, just return
+	// If we skipped all recipients due to whitelist or suppression list, just return
 	if len(recipientVariables) == 0 {
 		return nil
 	}
@@ -105,10 +129,21 @@ func (s *Sender) BatchSend(tenant *models.Tenant, templateName string, params em
 
 	resp, err := http.DefaultClient.Do(request)
 	if err != nil {
+		email.RecordResult(tenant, "mailgun", templateName, recipients, "", err)
 		return errors.Wrap(err, "failed to send email with template %s", templateName)
 	}
 
 	defer resp.Body.Close()
+	content, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		err = errors.New(fmt.Sprintf("failed to send email with template %s: %d %s", templateName, resp.StatusCode, string(content)))
+	}
+
+	email.RecordResult(tenant, "mailgun", templateName, recipients, string(content), err)
+	if err != nil {
+		return err
+	}
+
 	s.logger.Debugf("Email sent with response code %d.", resp.StatusCode)
 	return nil
 }
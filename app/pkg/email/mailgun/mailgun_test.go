@@ -0,0 +1,53 @@
+package mailgun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+func startServer() (*httptest.Server, chan url.Values) {
+	forms := make(chan url.Values, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		forms <- r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, forms
+}
+
+func TestBatchSend_PlainTextAndThread(t *testing.T) {
+	RegisterT(t)
+
+	server, forms := startServer()
+	defer server.Close()
+	baseURL = server.URL + "/%s/messages"
+
+	tenant := &models.Tenant{ID: 1, Subdomain: "demo"}
+	params := email.Params{
+		"subject": "Join us",
+	}
+	thread := email.NewIdeaThread(tenant, &models.Idea{ID: 3}, false)
+	params.SetThread(thread)
+
+	sender := NewSender(log.NewNoopLogger(), "mydomain.com", "mys3cr3tk3y")
+	err := sender.BatchSend(tenant, nil, "invite_email", params, "Fider", []email.Recipient{
+		email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{"message": email.Markdown("Hello **Jon**")}),
+		email.NewRecipient("Arya Stark", "arya.stark@got.com", email.Params{"message": email.Markdown("Hello **Arya**")}),
+	})
+	Expect(err).IsNil()
+
+	form := <-forms
+	Expect(form.Get("subject")).Equals("Join us")
+	Expect(form.Get("html")).Equals("%recipient.message%")
+	Expect(form.Get("text")).Equals("%recipient.message_text%")
+	Expect(form.Get("h:Message-ID")).Equals(thread.MessageID)
+	Expect(form.Get("recipient-variables")).ContainsSubstring(`"message_text":"Hello Arya"`)
+	Expect(form.Get("recipient-variables")).ContainsSubstring(`"message":"\u003cp\u003eHello \u003cstrong\u003eJon\u003c/strong\u003e\u003c/p\u003e\n"`)
+}
@@ -0,0 +1,134 @@
+package mailgun
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//Event is a bounce or complaint reported by Mailgun webhooks
+type Event struct {
+	TenantID     int
+	TemplateName string
+	Address      string
+	Status       models.EmailDeliveryStatus
+	Reason       string
+	Description  string
+}
+
+//maxTimestampAge is how far the timestamp of a webhook payload can be from current time
+const maxTimestampAge = 5 * time.Minute
+
+var (
+	mu         sync.Mutex
+	usedTokens = make(map[string]time.Time)
+	now        = time.Now
+)
+
+//useToken marks given token as used and returns false if it was already used
+//Tokens are only kept while their timestamp is within maxTimestampAge, older payloads are rejected anyway
+func useToken(token string, timestamp time.Time) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current := now()
+	for t, expiresOn := range usedTokens {
+		if current.After(expiresOn) {
+			delete(usedTokens, t)
+		}
+	}
+
+	if _, ok := usedTokens[token]; ok {
+		return false
+	}
+	usedTokens[token] = timestamp.Add(maxTimestampAge)
+	return true
+}
+
+type webhookPayload struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event          string            `json:"event"`
+		Severity       string            `json:"severity"`
+		Recipient      string            `json:"recipient"`
+		UserVariables  map[string]string `json:"user-variables"`
+		DeliveryStatus struct {
+			Code        int    `json:"code"`
+			Message     string `json:"message"`
+			Description string `json:"description"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+//ParseEvent verifies the signature, timestamp and token of a Mailgun webhook payload and returns the event it describes
+//Events other than permanent failures and complaints are not relevant and are returned as nil
+func ParseEvent(body []byte, signingKey string) (*Event, error) {
+	payload := &webhookPayload{}
+	if err := json.Unmarshal(body, payload); err != nil {
+		return nil, errors.Wrap(err, "failed to parse mailgun webhook payload")
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(payload.Signature.Timestamp + payload.Signature.Token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if signingKey == "" || !hmac.Equal([]byte(expected), []byte(payload.Signature.Signature)) {
+		return nil, errors.New("invalid mailgun webhook signature")
+	}
+
+	seconds, err := strconv.ParseInt(payload.Signature.Timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse timestamp of mailgun webhook payload")
+	}
+	timestamp := time.Unix(seconds, 0)
+	if age := now().Sub(timestamp); age > maxTimestampAge || age < -maxTimestampAge {
+		return nil, errors.New(fmt.Sprintf("mailgun webhook timestamp '%s' is not recent", payload.Signature.Timestamp))
+	}
+
+	if !useToken(payload.Signature.Token, timestamp) {
+		return nil, errors.New(fmt.Sprintf("mailgun webhook token '%s' has already been used", payload.Signature.Token))
+	}
+
+	data := payload.EventData
+	event := &Event{
+		TemplateName: data.UserVariables["template"],
+		Address:      data.Recipient,
+	}
+
+	switch {
+	case data.Event == "failed" && data.Severity == "permanent":
+		event.Status = models.EmailDeliveryBounced
+		event.Reason = "bounce"
+		event.Description = data.DeliveryStatus.Description
+		if event.Description == "" {
+			event.Description = data.DeliveryStatus.Message
+		}
+		if data.DeliveryStatus.Code > 0 {
+			event.Description = fmt.Sprintf("%d %s", data.DeliveryStatus.Code, event.Description)
+		}
+	case data.Event == "complained":
+		event.Status = models.EmailDeliveryComplained
+		event.Reason = "complaint"
+		event.Description = "Recipient has marked the email as spam"
+	default:
+		return nil, nil
+	}
+
+	tenantID, err := strconv.Atoi(data.UserVariables["tenant_id"])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse tenant_id of mailgun webhook event")
+	}
+	event.TenantID = tenantID
+	return event, nil
+}
@@ -0,0 +1,150 @@
+package mailgun
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+var tokenCount = 0
+
+func signedBody(key, timestamp, token, eventData string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + token))
+	signature := hex.EncodeToString(mac.Sum(nil))
+	return []byte(fmt.Sprintf(`{
+		"signature": { "timestamp": "%s", "token": "%s", "signature": "%s" },
+		"event-data": %s
+	}`, timestamp, token, signature, eventData))
+}
+
+func webhookBody(key, eventData string) []byte {
+	tokenCount++
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	token := fmt.Sprintf("a8ce0edb2dd8301dee6c2405235584e45aa91d1e9f979f3de%d", tokenCount)
+	return signedBody(key, timestamp, token, eventData)
+}
+
+func TestParseEvent_Bounce(t *testing.T) {
+	RegisterT(t)
+
+	event, err := ParseEvent(webhookBody("my-key", `{
+		"event": "failed",
+		"severity": "permanent",
+		"recipient": "jon.snow@got.com",
+		"user-variables": { "tenant_id": "2", "template": "new_idea" },
+		"delivery-status": { "code": 550, "message": "", "description": "No such mailbox" }
+	}`), "my-key")
+	Expect(err).IsNil()
+	Expect(event.TenantID).Equals(2)
+	Expect(event.TemplateName).Equals("new_idea")
+	Expect(event.Address).Equals("jon.snow@got.com")
+	Expect(event.Status).Equals(models.EmailDeliveryBounced)
+	Expect(event.Reason).Equals("bounce")
+	Expect(event.Description).Equals("550 No such mailbox")
+}
+
+func TestParseEvent_Complaint(t *testing.T) {
+	RegisterT(t)
+
+	event, err := ParseEvent(webhookBody("my-key", `{
+		"event": "complained",
+		"recipient": "jon.snow@got.com",
+		"user-variables": { "tenant_id": "1" }
+	}`), "my-key")
+	Expect(err).IsNil()
+	Expect(event.TenantID).Equals(1)
+	Expect(event.Status).Equals(models.EmailDeliveryComplained)
+	Expect(event.Reason).Equals("complaint")
+}
+
+func TestParseEvent_IgnoredEvents(t *testing.T) {
+	RegisterT(t)
+
+	for _, data := range []string{
+		`{ "event": "delivered", "recipient": "jon.snow@got.com" }`,
+		`{ "event": "failed", "severity": "temporary", "recipient": "jon.snow@got.com" }`,
+	} {
+		event, err := ParseEvent(webhookBody("my-key", data), "my-key")
+		Expect(err).IsNil()
+		Expect(event).IsNil()
+	}
+}
+
+func TestParseEvent_InvalidSignature(t *testing.T) {
+	RegisterT(t)
+
+	data := `{ "event": "complained", "recipient": "jon.snow@got.com", "user-variables": { "tenant_id": "1" } }`
+
+	event, err := ParseEvent(webhookBody("other-key", data), "my-key")
+	Expect(err).IsNotNil()
+	Expect(event).IsNil()
+
+	event, err = ParseEvent(webhookBody("", data), "")
+	Expect(err).IsNotNil()
+	Expect(event).IsNil()
+
+	event, err = ParseEvent([]byte("not json"), "my-key")
+	Expect(err).IsNotNil()
+	Expect(event).IsNil()
+}
+
+func TestParseEvent_OldTimestamp(t *testing.T) {
+	RegisterT(t)
+
+	data := `{ "event": "complained", "recipient": "jon.snow@got.com", "user-variables": { "tenant_id": "1" } }`
+
+	for _, age := range []time.Duration{-10 * time.Minute, 10 * time.Minute, 24 * time.Hour} {
+		timestamp := strconv.FormatInt(time.Now().Add(-age).Unix(), 10)
+		event, err := ParseEvent(signedBody("my-key", timestamp, "b7cf1ee1", data), "my-key")
+		Expect(err).IsNotNil()
+		Expect(event).IsNil()
+	}
+
+	event, err := ParseEvent(signedBody("my-key", "not-a-number", "b7cf1ee2", data), "my-key")
+	Expect(err).IsNotNil()
+	Expect(event).IsNil()
+}
+
+func TestParseEvent_ReusedToken(t *testing.T) {
+	RegisterT(t)
+
+	data := `{ "event": "complained", "recipient": "jon.snow@got.com", "user-variables": { "tenant_id": "1" } }`
+	body := webhookBody("my-key", data)
+
+	event, err := ParseEvent(body, "my-key")
+	Expect(err).IsNil()
+	Expect(event).IsNotNil()
+
+	event, err = ParseEvent(body, "my-key")
+	Expect(err).IsNotNil()
+	Expect(event).IsNil()
+}
+
+func TestParseEvent_ExpiredTokensAreForgotten(t *testing.T) {
+	RegisterT(t)
+	defer func() { now = time.Now }()
+
+	data := `{ "event": "complained", "recipient": "jon.snow@got.com", "user-variables": { "tenant_id": "1" } }`
+	timestamp := time.Now()
+	body := signedBody("my-key", strconv.FormatInt(timestamp.Unix(), 10), "c4d2e3f4", data)
+
+	event, err := ParseEvent(body, "my-key")
+	Expect(err).IsNil()
+	Expect(event).IsNotNil()
+
+	now = func() time.Time { return timestamp.Add(maxTimestampAge + time.Minute) }
+	useToken("other-token", now())
+
+	mu.Lock()
+	_, ok := usedTokens["c4d2e3f4"]
+	mu.Unlock()
+	Expect(ok).IsFalse()
+}
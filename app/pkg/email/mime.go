@@ -0,0 +1,57 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+)
+
+// BuildMIME returns a multipart email with both plain text and HTML versions of given message
+func BuildMIME(headers map[string]string, message *Message) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	headers["MIME-version"] = "1.0"
+	headers["Content-Type"] = fmt.Sprintf("multipart/alternative; boundary=\"%s\"", writer.Boundary())
+
+	var result bytes.Buffer
+	for k, v := range headers {
+		result.WriteString(fmt.Sprintf("%s: %s\r\n", k, v))
+	}
+	result.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=\"UTF-8\"", message.PlainText},
+		{"text/html; charset=\"UTF-8\"", message.Body},
+	}
+
+	for _, part := range parts {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	result.Write(body.Bytes())
+	return result.Bytes(), nil
+}
@@ -0,0 +1,40 @@
+package email
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+// SenderFactory creates a new Sender based on system configuration
+type SenderFactory func(logger log.Logger) Sender
+
+var providers = make(map[string]SenderFactory)
+
+// RegisterProvider makes an email provider available by given name
+func RegisterProvider(name string, factory SenderFactory) {
+	if _, ok := providers[name]; ok {
+		panic(fmt.Sprintf("email provider '%s' is already registered", name))
+	}
+	providers[name] = factory
+}
+
+// Providers returns the name of all registered email providers
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSender creates a new Sender using given provider
+func NewSender(provider string, logger log.Logger) (Sender, error) {
+	factory, ok := providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown email provider '%s'", provider)
+	}
+	return factory(logger), nil
+}
@@ -0,0 +1,185 @@
+package ses
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+func init() {
+	email.RegisterProvider("ses", func(logger log.Logger) email.Sender {
+		region := env.MustGet("EMAIL_SES_REGION")
+		return NewSender(
+			logger,
+			env.GetEnvOrDefault("EMAIL_SES_ENDPOINT", fmt.Sprintf("https://email.%s.amazonaws.com/", region)),
+			region,
+			env.MustGet("EMAIL_SES_ACCESS_KEY_ID"),
+			env.MustGet("EMAIL_SES_SECRET_ACCESS_KEY"),
+		)
+	})
+}
+
+//Sender is used to send emails through Amazon SES or any SES-compatible API
+type Sender struct {
+	logger          log.Logger
+	endpoint        string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+//NewSender creates a new SES email sender
+func NewSender(logger log.Logger, endpoint, region, accessKeyID, secretAccessKey string) *Sender {
+	return &Sender{logger, endpoint, region, accessKeyID, secretAccessKey}
+}
+
+//Send an email
+func (s *Sender) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	recipients := email.Filter(s.logger, tenant, "ses", templateName, []email.Recipient{to})
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	response, err := s.send(tenant, templates, templateName, params, from, to)
+	email.RecordResult(tenant, "ses", templateName, recipients, response, err)
+	return err
+}
+
+// send renders and sends the message, returning SES' response
+func (s *Sender) send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) (string, error) {
+	s.logger.Debugf("Sending email to %s with template %s.", to.Address, templateName)
+
+	message := email.RenderMessage(templates, templateName, email.LocaleOf(tenant, to), email.Params{}.Merge(params).Merge(to.Params))
+	headers := email.IdentityHeaders(tenant, params.Thread().Headers())
+	headers["From"] = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("UTF-8", from), email.FromAddress(tenant))
+	headers["To"] = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("UTF-8", to.Name), to.Address)
+	headers["Subject"] = mime.QEncoding.Encode("UTF-8", message.Subject)
+
+	raw, err := email.BuildMIME(headers, message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build email with template %s", templateName)
+	}
+
+	raw, err = email.SignMessage(tenant, raw)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign email with template %s", templateName)
+	}
+
+	form := url.Values{}
+	form.Add("Action", "SendRawEmail")
+	form.Add("Version", "2010-12-01")
+	form.Add("Source", email.FromAddress(tenant))
+	form.Add("Destinations.member.1", to.Address)
+	form.Add("RawMessage.Data", base64.StdEncoding.EncodeToString(raw))
+	form.Add("Tags.member.1.Name", "template")
+	form.Add("Tags.member.1.Value", templateName)
+	if tenant != nil && !env.IsSingleHostMode() {
+		form.Add("Tags.member.2.Name", "tenant")
+		form.Add("Tags.member.2.Value", tenant.Subdomain)
+	}
+
+	body := form.Encode()
+	request, err := http.NewRequest("POST", s.endpoint, strings.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create POST request")
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	s.sign(request, []byte(body), time.Now().UTC())
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to send email with template %s", templateName)
+	}
+
+	defer resp.Body.Close()
+	content, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return string(content), errors.New(fmt.Sprintf("failed to send email with template %s: %d %s", templateName, resp.StatusCode, string(content)))
+	}
+
+	s.logger.Debugf("Email sent with response code %d.", resp.StatusCode)
+	return string(content), nil
+}
+
+// BatchSend an email to multiple recipients
+// SES renders a single message per request, so each recipient gets its own message
+func (s *Sender) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
+	for _, r := range to {
+		if err := s.Send(tenant, templates, templateName, params, from, r); err != nil {
+			return errors.Wrap(err, "failed to batch send email to %d recipients", len(to))
+		}
+	}
+	return nil
+}
+
+// sign given request using AWS Signature Version 4
+func (s *Sender) sign(request *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	scope := fmt.Sprintf("%s/%s/ses/aws4_request", date, s.region)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("Host", request.URL.Host)
+
+	signedHeaders := "content-type;host;x-amz-date"
+	path := request.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		path,
+		request.URL.RawQuery,
+		"content-type:" + request.Header.Get("Content-Type"),
+		"host:" + request.URL.Host,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hashHex(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, content string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
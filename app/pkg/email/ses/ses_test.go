@@ -0,0 +1,97 @@
+package ses_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/email/ses"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+type request struct {
+	header http.Header
+	form   url.Values
+}
+
+func startServer() (*httptest.Server, chan *request) {
+	requests := make(chan *request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests <- &request{r.Header, r.PostForm}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, requests
+}
+
+var authRegex = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKID/\d{8}/us-east-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=[0-9a-f]{64}$`)
+
+func TestSend(t *testing.T) {
+	RegisterT(t)
+
+	server, requests := startServer()
+	defer server.Close()
+
+	sender := ses.NewSender(log.NewNoopLogger(), server.URL, "us-east-1", "AKID", "SECRET")
+	err := sender.Send(&models.Tenant{Subdomain: "demo"}, nil, "echo_test", email.Params{}, "Fider", email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{
+		"name": "Jon",
+	}))
+	Expect(err).IsNil()
+
+	req := <-requests
+	Expect(authRegex.MatchString(req.header.Get("Authorization"))).IsTrue()
+	Expect(req.header.Get("X-Amz-Date")).IsNotEmpty()
+	Expect(req.form.Get("Action")).Equals("SendRawEmail")
+	Expect(req.form.Get("Source")).Equals("noreply@random.org")
+	Expect(req.form.Get("Destinations.member.1")).Equals("jon.snow@got.com")
+
+	raw, err := base64.StdEncoding.DecodeString(req.form.Get("RawMessage.Data"))
+	Expect(err).IsNil()
+	Expect(string(raw)).ContainsSubstring("Subject: Message to: Jon")
+	Expect(string(raw)).ContainsSubstring("Content-Type: text/plain")
+	Expect(string(raw)).ContainsSubstring("Hello World Jon!")
+}
+
+func TestBatchSend_RecipientVariables(t *testing.T) {
+	RegisterT(t)
+
+	server, requests := startServer()
+	defer server.Close()
+
+	email.SetWhitelist("^.*@got.com$")
+	defer email.SetWhitelist("")
+
+	sender := ses.NewSender(log.NewNoopLogger(), server.URL, "us-east-1", "AKID", "SECRET")
+	err := sender.BatchSend(&models.Tenant{Subdomain: "demo"}, nil, "echo_test", email.Params{}, "Fider", []email.Recipient{
+		email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{"name": "Jon"}),
+		email.NewRecipient("Tony Stark", "tony.stark@avengers.com", email.Params{"name": "Tony"}),
+		email.NewRecipient("Arya Stark", "arya.stark@got.com", email.Params{"name": "Arya"}),
+	})
+	Expect(err).IsNil()
+	Expect(requests).HasLen(2)
+
+	for _, name := range []string{"Jon", "Arya"} {
+		req := <-requests
+		raw, _ := base64.StdEncoding.DecodeString(req.form.Get("RawMessage.Data"))
+		Expect(string(raw)).ContainsSubstring("Hello World " + name + "!")
+	}
+}
+
+func TestSend_Failure(t *testing.T) {
+	RegisterT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender := ses.NewSender(log.NewNoopLogger(), server.URL, "us-east-1", "AKID", "SECRET")
+	err := sender.Send(nil, nil, "echo_test", email.Params{}, "Fider", email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{}))
+	Expect(err).IsNotNil()
+}
@@ -1,15 +1,24 @@
 package smtp
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"mime"
+	"net"
 	gosmtp "net/smtp"
+	"time"
 
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/safehttp"
 )
 
+const dialTimeout = 30 * time.Second
+
 func authenticate(username string, password string, host string) gosmtp.Auth {
 	if username == "" && password == "" {
 		return nil
@@ -17,6 +26,18 @@ func authenticate(username string, password string, host string) gosmtp.Auth {
 	return gosmtp.PlainAuth("", username, password, host)
 }
 
+func init() {
+	email.RegisterProvider("smtp", func(logger log.Logger) email.Sender {
+		return NewSender(
+			logger,
+			env.MustGet("EMAIL_SMTP_HOST"),
+			env.MustGet("EMAIL_SMTP_PORT"),
+			env.GetEnvOrDefault("EMAIL_SMTP_USERNAME", ""),
+			env.GetEnvOrDefault("EMAIL_SMTP_PASSWORD", ""),
+		)
+	})
+}
+
 //Sender is used to send emails
 type Sender struct {
 	logger   log.Logger
@@ -24,43 +45,43 @@ type Sender struct {
 	port     string
 	username string
 	password string
+	dial     safehttp.DialFunc
 }
 
 //NewSender creates a new mailgun email sender
 func NewSender(logger log.Logger, host, port, username, password string) *Sender {
-	return &Sender{logger, host, port, username, password}
+	return &Sender{logger, host, port, username, password, (&net.Dialer{Timeout: dialTimeout}).DialContext}
 }
 
 //Send an email
-func (s *Sender) Send(tenant *models.Tenant, templateName string, params email.Params, from string, to email.Recipient) error {
-	if to.Address == "" {
-		return nil
-	}
-
-	if !email.CanSendTo(to.Address) {
-		s.logger.Warnf("Skipping email to %s <%s> due to whitelist.", to.Name, to.Address)
+func (s *Sender) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	recipients := email.Filter(s.logger, tenant, "smtp", templateName, []email.Recipient{to})
+	if len(recipients) == 0 {
 		return nil
 	}
 
 	s.logger.Debugf("Sending email to %s with template %s and params %s.", to.Address, templateName, to.Params)
 
-	message := email.RenderMessage(templateName, params.Merge(to.Params))
-	headers := make(map[string]string)
-	headers["From"] = fmt.Sprintf("%s <%s>", from, email.NoReply)
+	message := email.RenderMessage(templates, templateName, email.LocaleOf(tenant, to), email.Params{}.Merge(params).Merge(to.Params))
+	headers := email.IdentityHeaders(tenant, params.Thread().Headers())
+	headers["From"] = fmt.Sprintf("%s <%s>", from, email.FromAddress(tenant))
 	headers["To"] = fmt.Sprintf("%s <%s>", to.Name, to.Address)
-	headers["Subject"] = message.Subject
-	headers["MIME-version"] = "1.0"
-	headers["Content-Type"] = "text/html; charset=\"UTF-8\""
+	headers["Subject"] = mime.QEncoding.Encode("UTF-8", message.Subject)
 
-	body := ""
-	for k, v := range headers {
-		body += fmt.Sprintf("%s: %s\r\n", k, v)
+	body, err := email.BuildMIME(headers, message)
+	if err != nil {
+		return errors.Wrap(err, "failed to build email with template %s", templateName)
+	}
+
+	body, err = email.SignMessage(tenant, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign email with template %s", templateName)
 	}
-	body += "\r\n" + message.Body
 
 	servername := fmt.Sprintf("%s:%s", s.host, s.port)
 	auth := authenticate(s.username, s.password, s.host)
-	err := gosmtp.SendMail(servername, auth, email.NoReply, []string{to.Address}, []byte(body))
+	err = sendMail(s.dial, servername, auth, email.FromAddress(tenant), []string{to.Address}, body)
+	email.RecordResult(tenant, "smtp", templateName, recipients, "", err)
 	if err != nil {
 		return errors.Wrap(err, "failed to send email with template %s", templateName)
 	}
@@ -69,11 +90,101 @@ func (s *Sender) Send(tenant *models.Tenant, templateName string, params email.P
 }
 
 // BatchSend an email to multiple recipients
-func (s *Sender) BatchSend(tenant *models.Tenant, templateName string, params email.Params, from string, to []email.Recipient) error {
+func (s *Sender) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
 	for _, r := range to {
-		if err := s.Send(tenant, templateName, params, from, r); err != nil {
+		if err := s.Send(tenant, templates, templateName, params, from, r); err != nil {
 			return errors.Wrap(err, "failed to batch send email to %d recipients", len(to))
 		}
 	}
 	return nil
 }
+
+//sendMail is the same as net/smtp SendMail, but connects with given dial function
+func sendMail(dial safehttp.DialFunc, addr string, auth gosmtp.Auth, from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := dial(context.Background(), "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	c, err := gosmtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err = c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err = c.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err = c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+//TenantSender sends emails through tenant's own SMTP server when one is configured
+//Emails of every other tenant are sent by the fallback sender
+type TenantSender struct {
+	logger            log.Logger
+	fallback          email.Sender
+	allowPrivateHosts bool
+}
+
+//NewTenantSender creates a new sender that respects tenant's SMTP settings.
+//Unless allowPrivateHosts is true, tenants' SMTP servers can only be reached on public addresses.
+func NewTenantSender(logger log.Logger, fallback email.Sender, allowPrivateHosts bool) *TenantSender {
+	return &TenantSender{logger, fallback, allowPrivateHosts}
+}
+
+func (s *TenantSender) senderFor(tenant *models.Tenant) email.Sender {
+	if tenant != nil && tenant.Email.HasSMTP() {
+		settings := tenant.Email
+		sender := NewSender(s.logger, settings.SMTPHost, settings.SMTPPort, settings.SMTPUsername, settings.SMTPPassword)
+		if !s.allowPrivateHosts {
+			sender.dial = safehttp.Dialer(dialTimeout)
+		}
+		return sender
+	}
+	return s.fallback
+}
+
+//Send an email
+func (s *TenantSender) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	return s.senderFor(tenant).Send(tenant, templates, templateName, params, from, to)
+}
+
+// BatchSend an email to multiple recipients
+func (s *TenantSender) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
+	return s.senderFor(tenant).BatchSend(tenant, templates, templateName, params, from, to)
+}
@@ -0,0 +1,178 @@
+package smtp_test
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/email/smtp"
+	"github.com/getfider/fider/app/pkg/log"
+)
+
+// startServer runs a minimal SMTP server that accepts a single message and sends its data to the returned channel
+func startServer(t *testing.T) (string, string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				var message strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					message.WriteString(line)
+				}
+				data <- message.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, data
+}
+
+func TestSend_MultipartWithThread(t *testing.T) {
+	RegisterT(t)
+
+	host, port, data := startServer(t)
+	sender := smtp.NewSender(log.NewNoopLogger(), host, port, "", "")
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration"}
+	thread := email.NewIdeaThread(tenant, &models.Idea{ID: 3}, true)
+	params := email.Params{
+		"title":       "[Demonstration] Add support for dark mode",
+		"content":     email.Markdown("We are **working** on it!"),
+		"view":        "view",
+		"unsubscribe": "unsubscribe",
+		"change":      "change",
+	}
+	params.SetThread(thread)
+
+	err := sender.Send(tenant, nil, "new_comment", params, "Jon Snow", email.NewRecipient("Arya Stark", "arya.stark@got.com", email.Params{}))
+	Expect(err).IsNil()
+
+	message := <-data
+	Expect(message).ContainsSubstring("Content-Type: multipart/alternative; boundary=")
+	Expect(message).ContainsSubstring("Content-Type: text/plain; charset=\"UTF-8\"")
+	Expect(message).ContainsSubstring("Content-Type: text/html; charset=\"UTF-8\"")
+	Expect(message).ContainsSubstring("We are working on it!")
+	Expect(message).ContainsSubstring("<strong>working</strong>")
+	Expect(message).ContainsSubstring("Message-ID: " + thread.MessageID)
+	Expect(message).ContainsSubstring("In-Reply-To: <ideas.1.3@random.org>")
+	Expect(message).ContainsSubstring("References: <ideas.1.3@random.org>")
+}
+
+type recordingSender struct {
+	templates []string
+}
+
+func (s *recordingSender) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	s.templates = append(s.templates, templateName)
+	return nil
+}
+
+func (s *recordingSender) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
+	s.templates = append(s.templates, templateName)
+	return nil
+}
+
+func TestTenantSender_OwnSMTP(t *testing.T) {
+	RegisterT(t)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	host, port, data := startServer(t)
+	fallback := &recordingSender{}
+	sender := smtp.NewTenantSender(log.NewNoopLogger(), fallback, true)
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration", Email: models.TenantEmailSettings{
+		FromAddress:    "feedback@demo.com",
+		ReplyTo:        "support@demo.com",
+		VerifiedDomain: "demo.com",
+		SMTPHost:       host,
+		SMTPPort:       port,
+		DKIMSelector:   "fider",
+		DKIMPrivateKey: privateKey,
+	}}
+	err := sender.Send(tenant, nil, "echo_test", email.Params{}, "Demonstration", email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{
+		"name": "Jon",
+	}))
+	Expect(err).IsNil()
+	Expect(fallback.templates).HasLen(0)
+
+	message := <-data
+	Expect(strings.HasPrefix(message, "DKIM-Signature: v=1; a=rsa-sha256; c=relaxed/relaxed; d=demo.com; s=fider;")).IsTrue()
+	Expect(message).ContainsSubstring("From: Demonstration <feedback@demo.com>\r\n")
+	Expect(message).ContainsSubstring("Reply-To: support@demo.com\r\n")
+}
+
+func TestTenantSender_PrivateHost(t *testing.T) {
+	RegisterT(t)
+
+	host, port, _ := startServer(t)
+	fallback := &recordingSender{}
+	sender := smtp.NewTenantSender(log.NewNoopLogger(), fallback, false)
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration", Email: models.TenantEmailSettings{
+		FromAddress: "feedback@demo.com",
+		SMTPHost:    host,
+		SMTPPort:    port,
+	}}
+	err := sender.Send(tenant, nil, "echo_test", email.Params{}, "Demonstration", email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{}))
+	Expect(err).IsNotNil()
+	Expect(fallback.templates).HasLen(0)
+}
+
+func TestTenantSender_Fallback(t *testing.T) {
+	RegisterT(t)
+
+	fallback := &recordingSender{}
+	sender := smtp.NewTenantSender(log.NewNoopLogger(), fallback, false)
+
+	tenant := &models.Tenant{ID: 1, Name: "Demonstration"}
+	err := sender.Send(tenant, nil, "echo_test", email.Params{}, "Demonstration", email.NewRecipient("Jon Snow", "jon.snow@got.com", email.Params{}))
+	Expect(err).IsNil()
+	err = sender.BatchSend(nil, nil, "signup_email", email.Params{}, "Fider", []email.Recipient{})
+	Expect(err).IsNil()
+	Expect(fallback.templates).Equals([]string{"echo_test", "signup_email"})
+}
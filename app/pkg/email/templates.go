@@ -0,0 +1,76 @@
+package email
+
+import (
+	"html/template"
+	"sort"
+)
+
+// samples holds the params used to preview each template that can be customized
+var samples = map[string]Params{
+	"signup_email": {
+		"link": template.HTML("<a href='https://demo.fider.io/signup/verify?k=1234'>https://demo.fider.io/signup/verify?k=1234</a>"),
+	},
+	"signin_email": {
+		"tenantName": "Demonstration",
+		"link":       template.HTML("<a href='https://demo.fider.io/signin/verify?k=1234'>https://demo.fider.io/signin/verify?k=1234</a>"),
+	},
+	"change_emailaddress_email": {
+		"name":     "Jon Snow",
+		"oldEmail": "jon.snow@got.com",
+		"newEmail": "jon.snow@nightswatch.com",
+		"link":     template.HTML("<a href='https://demo.fider.io/change-email/verify?k=1234'>https://demo.fider.io/change-email/verify?k=1234</a>"),
+	},
+	"invite_email": {
+		"subject": "Share your ideas and thoughts about Demonstration",
+		"message": template.HTML("<p>We would love to hear what you think.</p><p><a href='https://demo.fider.io/invite/verify?k=1234'>https://demo.fider.io/invite/verify?k=1234</a></p>"),
+	},
+	"new_idea": {
+		"title":   "[Demonstration] Add support for dark mode",
+		"content": template.HTML("<p>It would be great to have a dark theme.</p>"),
+		"view":    template.HTML("<a href='https://demo.fider.io/ideas/1/add-support-for-dark-mode'>View it on your browser</a>"),
+		"change":  template.HTML("<a href='https://demo.fider.io/settings'>change your notification settings</a>"),
+	},
+	"new_comment": {
+		"title":       "[Demonstration] Add support for dark mode",
+		"content":     template.HTML("<p>We are working on it!</p>"),
+		"view":        template.HTML("<a href='https://demo.fider.io/ideas/1/add-support-for-dark-mode'>View it on your browser</a>"),
+		"unsubscribe": template.HTML("<a href='https://demo.fider.io/ideas/1/add-support-for-dark-mode'>unsubscribe from it</a>"),
+		"change":      template.HTML("<a href='https://demo.fider.io/settings'>change your notification settings</a>"),
+	},
+	"change_status": {
+		"title":       "[Demonstration] Add support for dark mode",
+		"content":     template.HTML("<p>This is now available for everyone.</p>"),
+		"status":      "Completed",
+		"duplicate":   template.HTML(""),
+		"view":        template.HTML("<a href='https://demo.fider.io/ideas/1/add-support-for-dark-mode'>View it on your browser</a>"),
+		"unsubscribe": template.HTML("<a href='https://demo.fider.io/ideas/1/add-support-for-dark-mode'>unsubscribe from it</a>"),
+		"change":      template.HTML("<a href='https://demo.fider.io/settings'>change your notification settings</a>"),
+	},
+}
+
+// Templates returns the name of all templates that can be customized
+func Templates() []string {
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsCustomizable returns true if given template can be customized
+func IsCustomizable(templateName string) bool {
+	_, ok := samples[templateName]
+	return ok
+}
+
+// SampleParams returns the params used to preview given template
+func SampleParams(templateName string) Params {
+	params := Params{}
+	return params.Merge(samples[templateName])
+}
+
+// RenderSample renders given subject and body templates with sample params of given template
+func RenderSample(templateName, subject, body string) (*Message, error) {
+	return RenderTemplate(subject, body, SampleParams(templateName))
+}
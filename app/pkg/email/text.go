@@ -0,0 +1,75 @@
+package email
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var blankLinesRegex = regexp.MustCompile(`\n{3,}`)
+
+// htmlToText converts the HTML body of an email into its plain text alternative
+func htmlToText(input string) string {
+	var bf bytes.Buffer
+	href := ""
+	linkStart := 0
+
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return cleanText(bf.String())
+		case html.TextToken:
+			text := string(tokenizer.Text())
+			// Line breaks used to format the HTML source are meaningless after a block or a <br />
+			if bf.Len() == 0 || bytes.HasSuffix(bf.Bytes(), []byte("\n")) {
+				text = strings.TrimLeft(text, " \t\r\n")
+			}
+			bf.WriteString(text)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.Br:
+				bf.WriteString("\n")
+			case atom.P, atom.Div, atom.Li, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				bf.WriteString("\n")
+			case atom.A:
+				href = attr(token, "href")
+				linkStart = bf.Len()
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.P, atom.Div, atom.Li, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				bf.WriteString("\n\n")
+			case atom.A:
+				text := strings.TrimSpace(bf.String()[linkStart:])
+				if href != "" && text != href {
+					bf.WriteString(" (" + href + ")")
+				}
+				href = ""
+			}
+		}
+	}
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func cleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLinesRegex.ReplaceAllString(text, "\n\n"))
+}
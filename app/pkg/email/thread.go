@@ -0,0 +1,68 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+)
+
+const threadKey = "__thread"
+
+// Thread is used to group all emails about the same subject into a single conversation
+type Thread struct {
+	MessageID  string
+	InReplyTo  string
+	References []string
+}
+
+// NewIdeaThread returns the thread of an email about given idea
+// The new idea notification starts the thread and every other email is a reply to it
+func NewIdeaThread(tenant *models.Tenant, idea *models.Idea, isReply bool) *Thread {
+	root := fmt.Sprintf("<ideas.%d.%d@%s>", tenant.ID, idea.ID, messageIDDomain())
+	if !isReply {
+		return &Thread{MessageID: root}
+	}
+
+	return &Thread{
+		MessageID:  fmt.Sprintf("<ideas.%d.%d.%d@%s>", tenant.ID, idea.ID, time.Now().UnixNano(), messageIDDomain()),
+		InReplyTo:  root,
+		References: []string{root},
+	}
+}
+
+// Headers returns the email headers that represent this thread
+func (t *Thread) Headers() map[string]string {
+	headers := make(map[string]string)
+	if t == nil {
+		return headers
+	}
+	if t.MessageID != "" {
+		headers["Message-ID"] = t.MessageID
+	}
+	if t.InReplyTo != "" {
+		headers["In-Reply-To"] = t.InReplyTo
+	}
+	if len(t.References) > 0 {
+		headers["References"] = strings.Join(t.References, " ")
+	}
+	return headers
+}
+
+// SetThread defines the thread this email belongs to
+func (p Params) SetThread(thread *Thread) Params {
+	p[threadKey] = thread
+	return p
+}
+
+// Thread returns the thread this email belongs to, if any
+func (p Params) Thread() *Thread {
+	thread, _ := p[threadKey].(*Thread)
+	return thread
+}
+
+func messageIDDomain() string {
+	parts := strings.Split(NoReply, "@")
+	return strings.Trim(parts[len(parts)-1], "<> ")
+}
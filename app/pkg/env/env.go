@@ -46,6 +46,15 @@ func IsSingleHostMode() bool {
 	return Mode() == "single"
 }
 
+// AllowPrivateSMTPHosts returns true if tenants can send emails through SMTP servers on private networks.
+// Defaults to true only on single host mode, where the tenant is also who runs the server.
+func AllowPrivateSMTPHosts() bool {
+	if IsDefined("EMAIL_SMTP_ALLOW_PRIVATE_HOSTS") {
+		return MustGet("EMAIL_SMTP_ALLOW_PRIVATE_HOSTS") == "true"
+	}
+	return IsSingleHostMode()
+}
+
 // MultiTenantDomain returns domain name of current instance for multi tenant hosts
 func MultiTenantDomain() string {
 	if !IsSingleHostMode() {
@@ -63,6 +72,42 @@ func MultiTenantDomain() string {
 	return ""
 }
 
+// Operators returns the secrets of the operators allowed on the super-admin console, indexed by email
+// OPERATORS is a comma separated list of email:secret pairs
+func Operators() map[string]string {
+	operators := make(map[string]string, 0)
+	for _, entry := range strings.Split(os.Getenv("OPERATORS"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			operators[strings.ToLower(parts[0])] = parts[1]
+		}
+	}
+	return operators
+}
+
+// TrustedProxies returns the networks of the reverse proxies allowed to set X-Forwarded-For
+// TRUSTED_PROXIES is a comma separated list of IP addresses or CIDR ranges
+func TrustedProxies() []*net.IPNet {
+	proxies := make([]*net.IPNet, 0)
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+	return proxies
+}
+
 var publicIP = make(map[string]string, 0)
 
 // GetPublicIP returns the public IP of current hosting server
@@ -67,6 +67,20 @@ func TestMultiTenantDomain(t *testing.T) {
 	Expect(env.MultiTenantDomain()).IsEmpty()
 }
 
+func TestOperators(t *testing.T) {
+	RegisterT(t)
+
+	os.Setenv("OPERATORS", "")
+	Expect(env.Operators()).HasLen(0)
+
+	os.Setenv("OPERATORS", "Ops@fider.io:s3cr:et, support@fider.io:1234,invalid,:empty,nosecret:")
+	operators := env.Operators()
+	Expect(operators).HasLen(2)
+	Expect(operators["ops@fider.io"]).Equals("s3cr:et")
+	Expect(operators["support@fider.io"]).Equals("1234")
+	os.Setenv("OPERATORS", "")
+}
+
 func TestGetPublicIP(t *testing.T) {
 	RegisterT(t)
 
@@ -91,3 +105,18 @@ func TestGetPublicIP(t *testing.T) {
 	Expect(err).IsNil()
 	Expect(ip).Equals("")
 }
+
+func TestTrustedProxies(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("TRUSTED_PROXIES", "10.1.1.1, 172.16.0.0/12,,invalid, ::1")
+	defer os.Setenv("TRUSTED_PROXIES", "")
+
+	proxies := env.TrustedProxies()
+	Expect(proxies).HasLen(3)
+	Expect(proxies[0].String()).Equals("10.1.1.1/32")
+	Expect(proxies[1].String()).Equals("172.16.0.0/12")
+	Expect(proxies[2].String()).Equals("::1/128")
+
+	os.Setenv("TRUSTED_PROXIES", "")
+	Expect(env.TrustedProxies()).HasLen(0)
+}
@@ -0,0 +1,190 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getfider/fider/app/pkg/env"
+)
+
+//DefaultLocale is used when nothing better is known about the reader
+//Messages are written in this locale, so it doesn't need a catalog
+const DefaultLocale = "en"
+
+//Params used to replace placeholders on messages, e.g. {count}
+type Params map[string]interface{}
+
+//Message is a text that is translated only when the reader is known
+type Message struct {
+	Key    string
+	Params Params
+}
+
+//NewMessage creates a message from given key and optional params
+func NewMessage(key string, params ...Params) Message {
+	msg := Message{Key: key}
+	if len(params) > 0 {
+		msg.Params = params[0]
+	}
+	return msg
+}
+
+//String returns the message in the default locale
+func (m Message) String() string {
+	return m.Translate(DefaultLocale)
+}
+
+//Translate returns the message in given locale
+func (m Message) Translate(locale string) string {
+	return T(locale, m.Key, m.Params)
+}
+
+type catalog map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs map[string]catalog
+)
+
+//load reads all catalogs from /locale once, or on every call during development
+func load() map[string]catalog {
+	mu.RLock()
+	loaded := catalogs
+	mu.RUnlock()
+	if loaded != nil && !env.IsDevelopment() {
+		return loaded
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	loaded = map[string]catalog{DefaultLocale: catalog{}}
+	files, _ := filepath.Glob(env.Path("/locale", "*.json"))
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to read locale file %s: %s", file, err))
+		}
+
+		messages := catalog{}
+		if err := json.Unmarshal(content, &messages); err != nil {
+			panic(fmt.Sprintf("Failed to parse locale file %s: %s", file, err))
+		}
+		loaded[strings.TrimSuffix(filepath.Base(file), ".json")] = messages
+	}
+
+	catalogs = loaded
+	return loaded
+}
+
+//Locales returns all locales with a catalog, sorted by name
+func Locales() []string {
+	all := load()
+	locales := make([]string, 0, len(all))
+	for locale := range all {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+//IsSupported returns true if there's a catalog for given locale or its base language
+func IsSupported(locale string) bool {
+	return supported(locale) != ""
+}
+
+//supported returns the locale which catalog is used for given locale, e.g. pt for pt-PT when only pt exists
+func supported(locale string) string {
+	all := load()
+	for _, candidate := range fallbackLocales(locale) {
+		if _, ok := all[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+//Resolve returns the first supported locale out of given candidates, ordered by preference
+func Resolve(candidates ...string) string {
+	for _, candidate := range candidates {
+		if locale := supported(candidate); locale != "" {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+//FromAcceptLanguage returns the locales of an Accept-Language header, ordered by preference
+func FromAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "q=") {
+				if value, err := strconv.ParseFloat(field[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+		items = append(items, weighted{locale, q})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+
+	locales := make([]string, len(items))
+	for i, item := range items {
+		locales[i] = item.locale
+	}
+	return locales
+}
+
+//T returns the translation of given key on given locale, with its placeholders replaced by params
+//Keys are the messages in the default locale, so they are used when there's no translation
+func T(locale, key string, params ...Params) string {
+	text := key
+	all := load()
+	for _, candidate := range fallbackLocales(locale) {
+		if translated, ok := all[candidate][key]; ok && translated != "" {
+			text = translated
+			break
+		}
+	}
+
+	for _, p := range params {
+		for name, value := range p {
+			text = strings.Replace(text, "{"+name+"}", fmt.Sprint(value), -1)
+		}
+	}
+	return text
+}
+
+//fallbackLocales returns given locale followed by its base language, e.g. pt-BR, pt
+func fallbackLocales(locale string) []string {
+	if locale == "" {
+		return []string{}
+	}
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		return []string{locale, locale[0:idx]}
+	}
+	return []string{locale}
+}
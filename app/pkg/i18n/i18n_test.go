@@ -0,0 +1,63 @@
+package i18n_test
+
+import (
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/i18n"
+)
+
+func TestT(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.T("en", "Name is required.")).Equals("Name is required.")
+	Expect(i18n.T("pt-BR", "Name is required.")).Equals("O nome é obrigatório.")
+	Expect(i18n.T("pt-BR", "Some message without translation.")).Equals("Some message without translation.")
+	Expect(i18n.T("xx", "Name is required.")).Equals("Name is required.")
+	Expect(i18n.T("", "Name is required.")).Equals("Name is required.")
+}
+
+func TestT_WithParams(t *testing.T) {
+	RegisterT(t)
+
+	key := "Line {line}: '{email}' is not a valid email address."
+	params := i18n.Params{"line": 3, "email": "jon"}
+	Expect(i18n.T("en", key, params)).Equals("Line 3: 'jon' is not a valid email address.")
+	Expect(i18n.T("pt-BR", key, params)).Equals("Linha 3: 'jon' não é um endereço de email válido.")
+}
+
+func TestMessage_Translate(t *testing.T) {
+	RegisterT(t)
+
+	msg := i18n.NewMessage("{subdomain} is a reserved subdomain.", i18n.Params{"subdomain": "admin"})
+	Expect(msg.String()).Equals("admin is a reserved subdomain.")
+	Expect(msg.Translate("pt-BR")).Equals("admin é um subdomínio reservado.")
+}
+
+func TestResolve(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.Resolve()).Equals("en")
+	Expect(i18n.Resolve("pt-BR")).Equals("pt-BR")
+	Expect(i18n.Resolve("xx", "pt-BR", "en")).Equals("pt-BR")
+	Expect(i18n.Resolve("en", "pt-BR")).Equals("en")
+	Expect(i18n.Resolve("en-US")).Equals("en")
+	Expect(i18n.Resolve("pt")).Equals("en")
+	Expect(i18n.IsSupported("pt-BR")).IsTrue()
+	Expect(i18n.IsSupported("de")).IsFalse()
+}
+
+func TestLocales(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.Locales()).Equals([]string{"en", "pt-BR"})
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	RegisterT(t)
+
+	Expect(i18n.FromAcceptLanguage("")).Equals([]string{})
+	Expect(i18n.FromAcceptLanguage("pt-BR")).Equals([]string{"pt-BR"})
+	Expect(i18n.FromAcceptLanguage("en;q=0.5, pt-BR,pt;q=0.8, *;q=0.1")).Equals([]string{"pt-BR", "pt", "en"})
+	Expect(i18n.FromAcceptLanguage("de;q=bad, fr")).Equals([]string{"de", "fr"})
+}
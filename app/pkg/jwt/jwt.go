@@ -1,18 +1,38 @@
 package jwt
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 )
 
-var jwtSecret = env.MustGet("JWT_SECRET")
+var keyring = mustLoadKeyring()
+
+func mustLoadKeyring() *Keyring {
+	if !env.IsDefined("JWT_KEYS") {
+		env.MustGet("JWT_SECRET")
+	}
+
+	keyring, err := ParseKeyring(os.Getenv("JWT_SECRET"), os.Getenv("JWT_KEYS"), os.Getenv("JWT_ACTIVE_KEY"))
+	if err != nil {
+		panic(err)
+	}
+	return keyring
+}
+
+//PublicKeys returns the public part of the asymmetric keys used to sign tokens
+func PublicKeys() []JSONWebKey {
+	return keyring.PublicKeys()
+}
 
 //Encode creates new JWT tokens with given claims
 func Encode(claims jwtgo.Claims) (string, error) {
-	jwtToken := jwtgo.NewWithClaims(jwtgo.GetSigningMethod("HS256"), claims)
-	token, err := jwtToken.SignedString([]byte(jwtSecret))
+	token, err := keyring.Sign(claims)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to encode the requested claims")
 	}
@@ -39,13 +59,84 @@ func DecodeOAuthClaims(token string) (*models.OAuthClaims, error) {
 	return claims, nil
 }
 
-func decode(token string, claims jwtgo.Claims) error {
-	jwtToken, err := jwtgo.ParseWithClaims(token, claims, func(t *jwtgo.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
+//DecodeTwoFactorClaims extract TwoFactorClaims from given JWT token
+func DecodeTwoFactorClaims(token string) (*models.TwoFactorClaims, error) {
+	claims := &models.TwoFactorClaims{}
+	err := decode(token, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode two-factor claims")
+	}
+	//Other tokens are signed with the same secret, but don't have the user of a pending sign in
+	if claims.UserID == 0 || claims.ExpiresAt == 0 {
+		return nil, errors.New("token is not a two-factor token")
+	}
+	return claims, nil
+}
+
+//DecodeOperatorClaims extract OperatorClaims from given JWT token
+func DecodeOperatorClaims(token string) (*models.OperatorClaims, error) {
+	claims := &models.OperatorClaims{}
+	err := decode(token, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode operator claims")
+	}
+	//Tokens of tenant users are signed with the same keys, so the operator must be explicit
+	if claims.OperatorEmail == "" || claims.ExpiresAt == 0 {
+		return nil, errors.New("token is not an operator token")
+	}
+	return claims, nil
+}
+
+//SSOProvider is the provider name of users signed in by a trusted application
+const SSOProvider = "sso"
+
+//MaxSSOTokenLifetime is the longest a token issued by a trusted application can be valid for
+const MaxSSOTokenLifetime = 10 * time.Minute
+
+//ParsePublicKey parses a PEM encoded RSA or ECDSA public key
+func ParsePublicKey(key string) (interface{}, error) {
+	if rsaKey, err := jwtgo.ParseRSAPublicKeyFromPEM([]byte(key)); err == nil {
+		return rsaKey, nil
+	}
+	ecdsaKey, err := jwtgo.ParseECPublicKeyFromPEM([]byte(key))
+	if err != nil {
+		return nil, errors.New("key is not a valid RSA or ECDSA public key")
+	}
+	return ecdsaKey, nil
+}
+
+//DecodeSSOClaims extract SSOClaims from a JWT token issued by a trusted application
+//Tokens are verified with the public key when there is one, otherwise with the shared secret
+func DecodeSSOClaims(token string, settings models.TenantJWTSSOSettings) (*models.SSOClaims, error) {
+	claims := &models.SSOClaims{}
+	_, err := jwtgo.ParseWithClaims(token, claims, func(t *jwtgo.Token) (interface{}, error) {
+		if settings.PublicKey != "" {
+			switch t.Method.(type) {
+			case *jwtgo.SigningMethodRSA, *jwtgo.SigningMethodECDSA:
+				return ParsePublicKey(settings.PublicKey)
+			}
+		} else if _, ok := t.Method.(*jwtgo.SigningMethodHMAC); ok && settings.Secret != "" {
+			return []byte(settings.Secret), nil
+		}
+		return nil, errors.New(fmt.Sprintf("unexpected signing method '%s'", t.Header["alg"]))
 	})
 
-	if err == nil && jwtToken.Valid {
-		return nil
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode SSO claims")
+	}
+
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("token has no expiration time")
+	}
+	if time.Unix(claims.ExpiresAt, 0).After(time.Now().Add(MaxSSOTokenLifetime)) {
+		return nil, errors.New(fmt.Sprintf("token must not be valid for more than %s", MaxSSOTokenLifetime))
 	}
-	return err
+	if claims.Id == "" || len(claims.Id) > 200 {
+		return nil, errors.New("token must have an id with up to 200 characters")
+	}
+	return claims, nil
+}
+
+func decode(token string, claims jwtgo.Claims) error {
+	return keyring.Verify(token, claims)
 }
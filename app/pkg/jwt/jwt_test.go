@@ -1,8 +1,14 @@
 package jwt_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
+	"time"
 
+	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/getfider/fider/app/models"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/jwt"
@@ -75,3 +81,134 @@ func TestJWT_DecodeChangedToken(t *testing.T) {
 	Expect(err).IsNotNil()
 	Expect(decoded).IsNil()
 }
+
+var ssoSecret = "a-shared-secret-with-more-than-32-characters"
+
+func ssoToken(method jwtgo.SigningMethod, key interface{}, expiresIn time.Duration, id string) string {
+	token, _ := jwtgo.NewWithClaims(method, &models.SSOClaims{
+		Email: "jon.snow@got.com",
+		Name:  "Jon Snow",
+		StandardClaims: jwtgo.StandardClaims{
+			Subject:   "42",
+			Id:        id,
+			ExpiresAt: time.Now().Add(expiresIn).Unix(),
+		},
+	}).SignedString(key)
+	return token
+}
+
+func TestJWT_DecodeTwoFactorClaims(t *testing.T) {
+	RegisterT(t)
+
+	token, _ := jwt.Encode(&models.TwoFactorClaims{
+		UserID:   424,
+		Redirect: "/ideas/1",
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: time.Now().Add(10 * time.Minute).Unix(),
+		},
+	})
+
+	decoded, err := jwt.DecodeTwoFactorClaims(token)
+	Expect(err).IsNil()
+	Expect(decoded.UserID).Equals(424)
+	Expect(decoded.Redirect).Equals("/ideas/1")
+}
+
+func TestJWT_DecodeTwoFactorClaims_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	authToken, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    424,
+		SessionID: "abc",
+	})
+	noExpiration, _ := jwt.Encode(&models.TwoFactorClaims{
+		UserID: 424,
+	})
+	expired, _ := jwt.Encode(&models.TwoFactorClaims{
+		UserID: 424,
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: time.Now().Add(-1 * time.Minute).Unix(),
+		},
+	})
+
+	for _, token := range []string{authToken, noExpiration, expired, "invalid"} {
+		decoded, err := jwt.DecodeTwoFactorClaims(token)
+		Expect(err).IsNotNil()
+		Expect(decoded).IsNil()
+	}
+}
+
+func TestJWT_DecodeOperatorClaims(t *testing.T) {
+	RegisterT(t)
+
+	token, _ := jwt.Encode(&models.OperatorClaims{
+		OperatorEmail: "ops@fider.io",
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: time.Now().Add(1 * time.Hour).Unix(),
+		},
+	})
+	decoded, err := jwt.DecodeOperatorClaims(token)
+	Expect(err).IsNil()
+	Expect(decoded.OperatorEmail).Equals("ops@fider.io")
+
+	authToken, _ := jwt.Encode(&models.FiderClaims{
+		UserID:    424,
+		SessionID: "abc",
+	})
+	noExpiration, _ := jwt.Encode(&models.OperatorClaims{
+		OperatorEmail: "ops@fider.io",
+	})
+	for _, token := range []string{authToken, noExpiration, "invalid"} {
+		decoded, err := jwt.DecodeOperatorClaims(token)
+		Expect(err).IsNotNil()
+		Expect(decoded).IsNil()
+	}
+}
+
+func TestJWT_DecodeSSOClaims(t *testing.T) {
+	RegisterT(t)
+
+	settings := models.TenantJWTSSOSettings{IsEnabled: true, Secret: ssoSecret}
+	token := ssoToken(jwtgo.SigningMethodHS256, []byte(ssoSecret), time.Minute, "abc")
+
+	claims, err := jwt.DecodeSSOClaims(token, settings)
+	Expect(err).IsNil()
+	Expect(claims.Email).Equals("jon.snow@got.com")
+	Expect(claims.Subject).Equals("42")
+	Expect(claims.Id).Equals("abc")
+}
+
+func TestJWT_DecodeSSOClaims_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	settings := models.TenantJWTSSOSettings{IsEnabled: true, Secret: ssoSecret}
+	for _, token := range []string{
+		ssoToken(jwtgo.SigningMethodHS256, []byte("another-secret"), time.Minute, "abc"),
+		ssoToken(jwtgo.SigningMethodHS256, []byte(ssoSecret), -time.Minute, "abc"),
+		ssoToken(jwtgo.SigningMethodHS256, []byte(ssoSecret), time.Hour, "abc"),
+		ssoToken(jwtgo.SigningMethodHS256, []byte(ssoSecret), time.Minute, ""),
+		ssoToken(jwtgo.SigningMethodNone, jwtgo.UnsafeAllowNoneSignatureType, time.Minute, "abc"),
+	} {
+		claims, err := jwt.DecodeSSOClaims(token, settings)
+		Expect(err).IsNotNil()
+		Expect(claims).IsNil()
+	}
+}
+
+func TestJWT_DecodeSSOClaims_PublicKey(t *testing.T) {
+	RegisterT(t)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	settings := models.TenantJWTSSOSettings{IsEnabled: true, PublicKey: publicKey}
+
+	claims, err := jwt.DecodeSSOClaims(ssoToken(jwtgo.SigningMethodRS256, key, time.Minute, "abc"), settings)
+	Expect(err).IsNil()
+	Expect(claims.Subject).Equals("42")
+
+	//Public key must not be accepted as an HMAC secret
+	claims, err = jwt.DecodeSSOClaims(ssoToken(jwtgo.SigningMethodHS256, []byte(publicKey), time.Minute, "abc"), settings)
+	Expect(err).IsNotNil()
+	Expect(claims).IsNil()
+}
@@ -0,0 +1,218 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//Key is a key used to sign and verify the tokens issued by Fider
+type Key struct {
+	ID        string
+	Method    jwtgo.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+//JSONWebKey is the public part of an asymmetric key, as described by RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+//Keyring holds every key accepted when verifying tokens and the one used to sign new tokens
+type Keyring struct {
+	keys   map[string]*Key
+	order  []string
+	active *Key
+}
+
+//ParseKeyring creates a keyring from the legacy secret and a list of keys.
+//Keys are separated by comma and written as 'kid:alg:path', where path is a file holding the secret of HMAC keys
+//or the PEM encoded private key of RSA and ECDSA keys, so that secrets never have to be written in the list itself.
+//The legacy secret verifies tokens without a key ID and is only used to sign when there are no other keys.
+//New tokens are signed with the key named by active, or with the first key of the list when empty.
+func ParseKeyring(secret, keys, active string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]*Key)}
+
+	if secret != "" {
+		keyring.add(&Key{
+			Method:    jwtgo.SigningMethodHS256,
+			signKey:   []byte(secret),
+			verifyKey: []byte(secret),
+		})
+	}
+
+	first := ""
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, err := parseKey(entry)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := keyring.keys[key.ID]; ok {
+			return nil, errors.New(fmt.Sprintf("JWT key '%s' is defined more than once", key.ID))
+		}
+		keyring.add(key)
+		if first == "" {
+			first = key.ID
+		}
+	}
+
+	if len(keyring.order) == 0 {
+		return nil, errors.New("at least one JWT key is required")
+	}
+
+	if active == "" {
+		active = first
+	}
+
+	keyring.active = keyring.keys[active]
+	if keyring.active == nil {
+		return nil, errors.New(fmt.Sprintf("active JWT key '%s' is not defined", active))
+	}
+	return keyring, nil
+}
+
+func (k *Keyring) add(key *Key) {
+	k.keys[key.ID] = key
+	k.order = append(k.order, key.ID)
+}
+
+func parseKey(entry string) (*Key, error) {
+	parts := strings.SplitN(entry, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return nil, errors.New(fmt.Sprintf("JWT key '%s' must be written as 'kid:alg:path'", parts[0]))
+	}
+
+	key := &Key{ID: parts[0]}
+	method := jwtgo.GetSigningMethod(parts[1])
+	switch method.(type) {
+	case *jwtgo.SigningMethodHMAC, *jwtgo.SigningMethodRSA, *jwtgo.SigningMethodECDSA:
+	default:
+		return nil, errors.New(fmt.Sprintf("JWT key '%s' has unsupported algorithm '%s'", key.ID, parts[1]))
+	}
+
+	content, err := ioutil.ReadFile(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read JWT key '%s'", key.ID)
+	}
+
+	switch method := method.(type) {
+	case *jwtgo.SigningMethodHMAC:
+		secret := []byte(strings.TrimRight(string(content), "\r\n"))
+		if len(secret) == 0 {
+			return nil, errors.New(fmt.Sprintf("JWT key '%s' is empty", key.ID))
+		}
+		key.Method = method
+		key.signKey = secret
+		key.verifyKey = secret
+	case *jwtgo.SigningMethodRSA:
+		private, err := jwtgo.ParseRSAPrivateKeyFromPEM(content)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse JWT key '%s'", key.ID)
+		}
+		key.Method = method
+		key.signKey = private
+		key.verifyKey = &private.PublicKey
+	case *jwtgo.SigningMethodECDSA:
+		private, err := jwtgo.ParseECPrivateKeyFromPEM(content)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse JWT key '%s'", key.ID)
+		}
+		if private.Curve.Params().BitSize != method.CurveBits {
+			return nil, errors.New(fmt.Sprintf("JWT key '%s' doesn't match the curve of %s", key.ID, method.Alg()))
+		}
+		key.Method = method
+		key.signKey = private
+		key.verifyKey = &private.PublicKey
+	}
+
+	return key, nil
+}
+
+//Sign creates a new token with given claims using the active key
+func (k *Keyring) Sign(claims jwtgo.Claims) (string, error) {
+	token := jwtgo.NewWithClaims(k.active.Method, claims)
+	if k.active.ID != "" {
+		token.Header["kid"] = k.active.ID
+	}
+	return token.SignedString(k.active.signKey)
+}
+
+//Verify checks the signature of given token with the key named by its kid header and extracts its claims
+func (k *Keyring) Verify(token string, claims jwtgo.Claims) error {
+	_, err := jwtgo.ParseWithClaims(token, claims, func(t *jwtgo.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("unknown key '%s'", kid))
+		}
+		//Algorithm must be the one of the key, otherwise a public key could be used as an HMAC secret
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New(fmt.Sprintf("unexpected signing method '%s' for key '%s'", t.Method.Alg(), kid))
+		}
+		return key.verifyKey, nil
+	})
+	return err
+}
+
+//PublicKeys returns the public part of every asymmetric key, so that other services can verify tokens issued by Fider
+func (k *Keyring) PublicKeys() []JSONWebKey {
+	result := make([]JSONWebKey, 0)
+	for _, id := range k.order {
+		key := k.keys[id]
+		jwk := JSONWebKey{
+			KeyID:     key.ID,
+			Algorithm: key.Method.Alg(),
+			Use:       "sig",
+		}
+
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = encodeBase64(public.N)
+			jwk.E = encodeBase64(big.NewInt(int64(public.E)))
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.KeyType = "EC"
+			jwk.Curve = public.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(padLeft(public.X.Bytes(), size))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(padLeft(public.Y.Bytes(), size))
+		default:
+			continue
+		}
+
+		result = append(result, jwk)
+	}
+	return result
+}
+
+func encodeBase64(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+func padLeft(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
@@ -0,0 +1,200 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/jwt"
+)
+
+func writeKeyFile(t *testing.T, block *pem.Block) string {
+	file, err := ioutil.TempFile("", "jwt-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pem.Encode(file, block)
+	return file.Name()
+}
+
+func secretFile(t *testing.T, secret string) string {
+	file, err := ioutil.TempFile("", "jwt-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.WriteString(secret + "\n")
+	return file.Name()
+}
+
+func rsaKeyFile(t *testing.T) string {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	return writeKeyFile(t, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func ecKeyFile(t *testing.T, curve elliptic.Curve) string {
+	key, _ := ecdsa.GenerateKey(curve, rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(key)
+	return writeKeyFile(t, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+var keyringClaims = &models.FiderClaims{
+	UserID:   424,
+	UserName: "Jon Snow",
+}
+
+func TestKeyring_SignWithActiveKey(t *testing.T) {
+	RegisterT(t)
+
+	oldFile := secretFile(t, "old-secret")
+	newFile := secretFile(t, "new,secret")
+	defer os.Remove(oldFile)
+	defer os.Remove(newFile)
+
+	keyring, err := jwt.ParseKeyring("legacy", "2018-05:HS256:"+oldFile+",2018-06:HS512:"+newFile, "2018-06")
+	Expect(err).IsNil()
+
+	token, err := keyring.Sign(keyringClaims)
+	Expect(err).IsNil()
+
+	parsed, _ := jwtgo.Parse(token, nil)
+	Expect(parsed.Header["kid"]).Equals("2018-06")
+	Expect(parsed.Header["alg"]).Equals("HS512")
+
+	decoded := &models.FiderClaims{}
+	Expect(keyring.Verify(token, decoded)).IsNil()
+	Expect(decoded.UserID).Equals(424)
+
+	//Secrets are read from the file as is, commas included, without the trailing line break
+	parsed, err = jwtgo.Parse(token, func(*jwtgo.Token) (interface{}, error) { return []byte("new,secret"), nil })
+	Expect(err).IsNil()
+	Expect(parsed.Valid).IsTrue()
+}
+
+func TestKeyring_DefaultActiveKey(t *testing.T) {
+	RegisterT(t)
+
+	oldFile := secretFile(t, "old-secret")
+	newFile := secretFile(t, "new-secret")
+	defer os.Remove(oldFile)
+	defer os.Remove(newFile)
+
+	keyring, _ := jwt.ParseKeyring("legacy", "2018-05:HS256:"+oldFile+",2018-06:HS512:"+newFile, "")
+	token, _ := keyring.Sign(keyringClaims)
+	parsed, _ := jwtgo.Parse(token, nil)
+	Expect(parsed.Header["kid"]).Equals("2018-05")
+
+	keyring, _ = jwt.ParseKeyring("legacy", "", "")
+	token, _ = keyring.Sign(keyringClaims)
+	parsed, _ = jwtgo.Parse(token, nil)
+	_, hasKid := parsed.Header["kid"]
+	Expect(hasKid).IsFalse()
+}
+
+func TestKeyring_VerifyRotatedKeys(t *testing.T) {
+	RegisterT(t)
+
+	oldFile := secretFile(t, "old-secret")
+	newFile := secretFile(t, "new-secret")
+	defer os.Remove(oldFile)
+	defer os.Remove(newFile)
+
+	old, _ := jwt.ParseKeyring("legacy", "2018-05:HS256:"+oldFile, "")
+	legacyToken, _ := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, keyringClaims).SignedString([]byte("legacy"))
+	oldToken, _ := old.Sign(keyringClaims)
+
+	keyring, _ := jwt.ParseKeyring("legacy", "2018-06:HS256:"+newFile+",2018-05:HS256:"+oldFile, "2018-06")
+	Expect(keyring.Verify(legacyToken, &models.FiderClaims{})).IsNil()
+	Expect(keyring.Verify(oldToken, &models.FiderClaims{})).IsNil()
+
+	retired, _ := jwt.ParseKeyring("", "2018-06:HS256:"+newFile, "")
+	Expect(retired.Verify(legacyToken, &models.FiderClaims{})).IsNotNil()
+	Expect(retired.Verify(oldToken, &models.FiderClaims{})).IsNotNil()
+}
+
+func TestKeyring_VerifyUnexpectedAlgorithm(t *testing.T) {
+	RegisterT(t)
+
+	rsaFile := rsaKeyFile(t)
+	defer os.Remove(rsaFile)
+
+	keyring, err := jwt.ParseKeyring("", "2018-06:RS256:"+rsaFile, "")
+	Expect(err).IsNil()
+
+	//Signing with the public key as an HMAC secret must not be accepted
+	der, _ := x509.MarshalPKIXPublicKey(&rsa.PublicKey{})
+	token := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, keyringClaims)
+	token.Header["kid"] = "2018-06"
+	signed, _ := token.SignedString(der)
+	Expect(keyring.Verify(signed, &models.FiderClaims{})).IsNotNil()
+
+	token = jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, keyringClaims)
+	token.Header["kid"] = "unknown"
+	signed, _ = token.SignedString([]byte("secret"))
+	Expect(keyring.Verify(signed, &models.FiderClaims{})).IsNotNil()
+}
+
+func TestKeyring_AsymmetricKeys(t *testing.T) {
+	RegisterT(t)
+
+	rsaFile := rsaKeyFile(t)
+	ecFile := ecKeyFile(t, elliptic.P256())
+	defer os.Remove(rsaFile)
+	defer os.Remove(ecFile)
+
+	keyring, err := jwt.ParseKeyring("legacy", "rsa:RS256:"+rsaFile+",ec:ES256:"+ecFile, "ec")
+	Expect(err).IsNil()
+
+	token, err := keyring.Sign(keyringClaims)
+	Expect(err).IsNil()
+	Expect(keyring.Verify(token, &models.FiderClaims{})).IsNil()
+
+	keys := keyring.PublicKeys()
+	Expect(keys).HasLen(2)
+	Expect(keys[0].KeyID).Equals("rsa")
+	Expect(keys[0].KeyType).Equals("RSA")
+	Expect(keys[0].Algorithm).Equals("RS256")
+	Expect(keys[0].E).Equals("AQAB")
+	Expect(keys[1].KeyID).Equals("ec")
+	Expect(keys[1].KeyType).Equals("EC")
+	Expect(keys[1].Curve).Equals("P-256")
+	Expect(keys[1].X).HasLen(43)
+}
+
+func TestKeyring_Invalid(t *testing.T) {
+	RegisterT(t)
+
+	ecFile := ecKeyFile(t, elliptic.P384())
+	secret := secretFile(t, "secret")
+	empty := secretFile(t, "")
+	defer os.Remove(ecFile)
+	defer os.Remove(secret)
+	defer os.Remove(empty)
+
+	for _, keys := range []string{
+		"",
+		"2018-06:HS256:" + secret + ",2018-06:HS256:" + secret,
+		"2018-06:none:" + secret,
+		"2018-06:HS256",
+		"2018-06:HS256:/does/not/exist",
+		"2018-06:HS256:" + empty,
+		"2018-06:RS256:/does/not/exist.pem",
+		"2018-06:ES256:" + ecFile,
+	} {
+		_, err := jwt.ParseKeyring("", keys, "")
+		Expect(err).IsNotNil()
+	}
+
+	_, err := jwt.ParseKeyring("legacy", "2018-06:HS256:"+secret, "2018-07")
+	Expect(err).IsNotNil()
+}
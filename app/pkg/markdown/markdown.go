@@ -20,6 +20,8 @@ var htmlExtns = 0 |
 	blackfriday.HTML_USE_XHTML |
 	blackfriday.HTML_USE_SMARTYPANTS |
 	blackfriday.HTML_SKIP_IMAGES |
+	blackfriday.HTML_SKIP_HTML |
+	blackfriday.HTML_SAFELINK |
 	blackfriday.HTML_SMARTYPANTS_FRACTIONS |
 	blackfriday.HTML_SMARTYPANTS_DASHES |
 	blackfriday.HTML_SMARTYPANTS_LATEX_DASHES
@@ -40,6 +40,10 @@ Thanks!`: `<p>Please add:<br />
 This will allow to send and receive SMS and get the IMEI No. in our app.</p>
 
 <p>Thanks!</p>
+`,
+		"Hello <script>alert(1)</script>": `<p>Hello alert(1)</p>
+`,
+		"[Click me](javascript:alert(1))": `<p><tt>Click me</tt></p>
 `,
 	} {
 		output := markdown.Parse(input)
@@ -1,30 +1,35 @@
 package mock
 
-import "github.com/getfider/fider/app/pkg/oauth"
+import (
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/pkg/oauth"
+)
 
 //OAuthService implements a mocked OAuthService
 type OAuthService struct{}
 
 //GetAuthURL returns authentication url for given provider
-func (p *OAuthService) GetAuthURL(authEndpoint string, provider string, redirect string) string {
-	return "http://avengers.test.fider.io/oauth/token?provider=" + provider + "&redirect=" + redirect
+func (p *OAuthService) GetAuthURL(authEndpoint string, provider string, redirect string) (string, error) {
+	return "http://avengers.test.fider.io/oauth/token?provider=" + provider + "&redirect=" + redirect, nil
 }
 
 //GetProfile returns user profile based on provider and code
 func (p *OAuthService) GetProfile(authEndpoint string, provider string, code string) (*oauth.UserProfile, error) {
 	if provider == "facebook" && code == "123" {
 		return &oauth.UserProfile{
-			ID:    "FB123",
-			Name:  "Jon Snow",
-			Email: "jon.snow@got.com",
+			ID:            "FB123",
+			Name:          "Jon Snow",
+			Email:         "jon.snow@got.com",
+			EmailVerified: true,
 		}, nil
 	}
 
 	if provider == "facebook" && code == "456" {
 		return &oauth.UserProfile{
-			ID:    "FB456",
-			Name:  "Some Facebook Guy",
-			Email: "some.guy@facebook.com",
+			ID:            "FB456",
+			Name:          "Some Facebook Guy",
+			Email:         "some.guy@facebook.com",
+			EmailVerified: true,
 		}, nil
 	}
 
@@ -38,11 +43,34 @@ func (p *OAuthService) GetProfile(authEndpoint string, provider string, code str
 
 	if provider == "google" && code == "123" {
 		return &oauth.UserProfile{
-			ID:    "GO123",
-			Name:  "Jon Snow",
-			Email: "jon.snow@got.com",
+			ID:            "GO123",
+			Name:          "Jon Snow",
+			Email:         "jon.snow@got.com",
+			EmailVerified: true,
+		}, nil
+	}
+
+	if provider == "_custom" && code == "123" {
+		return &oauth.UserProfile{
+			ID:            "CU123",
+			Name:          "Arya Stark",
+			Email:         "arya.stark@got.com",
+			EmailVerified: true,
+		}, nil
+	}
+
+	if provider == "_custom" && code == "456" {
+		return &oauth.UserProfile{
+			ID:    "CU456",
+			Name:  "Not Arya",
+			Email: "arya.stark@got.com",
 		}, nil
 	}
 
 	return nil, nil
 }
+
+//Discover returns not found for every issuer
+func (p *OAuthService) Discover(issuer string) (*oauth.Discovery, error) {
+	return nil, app.ErrNotFound
+}
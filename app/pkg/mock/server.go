@@ -64,6 +64,18 @@ func (s *Server) AsUser(user *models.User) *Server {
 	return s
 }
 
+// AsOperator set current context operator of the super-admin console
+func (s *Server) AsOperator(email string) *Server {
+	s.context.SetOperator(email)
+	return s
+}
+
+// WithSession set current context session
+func (s *Server) WithSession(session *models.Session) *Server {
+	s.context.SetSession(session)
+	return s
+}
+
 // AddParam to current context route parameters
 func (s *Server) AddParam(name string, value interface{}) *Server {
 	s.context.AddParam(name, fmt.Sprintf("%v", value))
@@ -82,6 +94,12 @@ func (s *Server) AddCookie(name string, value string) *Server {
 	return s
 }
 
+// FromAddress set the network address the current request comes from
+func (s *Server) FromAddress(remoteAddr string) *Server {
+	s.context.Request.RemoteAddr = remoteAddr
+	return s
+}
+
 // WithURL set current context Request URL
 func (s *Server) WithURL(fullURL string) *Server {
 	s.context.Request.URL, _ = url.Parse(fullURL)
@@ -119,6 +137,21 @@ func (s *Server) ExecutePost(handler web.HandlerFunc, body string) (int, *httpte
 	return s.recorder.Code, s.recorder
 }
 
+// ExecutePostForm executes given handler as a form POST and return response
+func (s *Server) ExecutePostForm(handler web.HandlerFunc, form url.Values) (int, *httptest.ResponseRecorder) {
+	body := form.Encode()
+	s.context.Request.Method = "POST"
+	s.context.Request.Body = ioutil.NopCloser(strings.NewReader(body))
+	s.context.Request.ContentLength = int64(len(body))
+	s.context.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := s.middleware(handler)(s.context); err != nil {
+		s.context.Failure(err)
+	}
+
+	return s.recorder.Code, s.recorder
+}
+
 // ExecutePostAsJSON executes given handler as POST and return json response
 func (s *Server) ExecutePostAsJSON(handler web.HandlerFunc, body string) (int, *jsonq.Query) {
 	code, response := s.ExecutePost(handler, body)
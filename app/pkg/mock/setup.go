@@ -47,13 +47,17 @@ func NewWorker() (*Worker, *app.Services) {
 
 func createServices(seed bool) *app.Services {
 	services := &app.Services{
-		Tenants:       &inmemory.TenantStorage{},
-		Users:         &inmemory.UserStorage{},
-		Tags:          inmemory.NewTagStorage(),
-		Notifications: inmemory.NewNotificationStorage(),
-		Ideas:         inmemory.NewIdeaStorage(),
-		OAuth:         &OAuthService{},
-		Emailer:       email.NewNoopSender(),
+		Tenants:        &inmemory.TenantStorage{},
+		Users:          &inmemory.UserStorage{},
+		Tags:           inmemory.NewTagStorage(),
+		Notifications:  inmemory.NewNotificationStorage(),
+		Ideas:          inmemory.NewIdeaStorage(),
+		EmailTemplates: inmemory.NewEmailTemplateStorage(),
+		Pages:          inmemory.NewPageStorage(),
+		EmailLog:       inmemory.NewEmailLogStorage(),
+		Sessions:       inmemory.NewSessionStorage(),
+		OAuth:          &OAuthService{},
+		Emailer:        email.NewNoopSender(),
 	}
 
 	if seed {
@@ -38,7 +38,11 @@ func (w *Worker) Execute(task worker.Task) error {
 	context.SetServices(w.services)
 	context.SetUser(w.user)
 	context.SetTenant(w.tenant)
-	return task.Job(context)
+	if err := task.Job(context); err != nil {
+		return err
+	}
+	context.Committed()
+	return nil
 }
 
 // NewNoopTask returns a worker task that does nothing
@@ -1,14 +1,20 @@
 package oauth
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/safehttp"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/facebook"
 	"golang.org/x/oauth2/github"
@@ -72,8 +78,10 @@ var (
 	}
 )
 
-func doGet(url string, v interface{}) error {
-	r, err := http.Get(url)
+var defaultClient = safehttp.NewClient()
+
+func doGet(client *http.Client, url string, v interface{}) error {
+	r, err := client.Get(url)
 	if err != nil {
 		return err
 	}
@@ -94,36 +102,98 @@ func doGet(url string, v interface{}) error {
 }
 
 //HTTPService implements real OAuth operations using Golang's oauth2 package
-type HTTPService struct{}
+type HTTPService struct {
+	//Configs is used to look up custom providers, which are only available after current tenant is known
+	Configs ConfigStore
+	//Client is used for every request to a provider. Defaults to a client that only reaches public HTTPS addresses,
+	//because custom providers are configured by tenants.
+	Client *http.Client
+}
+
+func (p *HTTPService) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return defaultClient
+}
+
+func (p *HTTPService) getConfig(authEndpoint string, provider string) (*oauth2.Config, *models.OAuthConfig, error) {
+	if settings, ok := oauthSettings[provider]; ok {
+		return settings.config(authEndpoint), nil, nil
+	}
+
+	if !IsCustomProvider(provider) || p.Configs == nil {
+		return nil, nil, errors.New(fmt.Sprintf("unknown OAuth provider '%s'", provider))
+	}
+
+	custom, err := p.Configs.GetOAuthConfigByProvider(provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !custom.IsEnabled() {
+		return nil, nil, errors.New(fmt.Sprintf("OAuth provider '%s' is disabled", provider))
+	}
+
+	return &oauth2.Config{
+		ClientID:     custom.ClientID,
+		ClientSecret: custom.ClientSecret,
+		RedirectURL:  authEndpoint + "/oauth/" + provider + "/callback",
+		Scopes:       strings.Fields(custom.Scope),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  custom.AuthorizeURL,
+			TokenURL: custom.TokenURL,
+		},
+	}, custom, nil
+}
 
 //GetAuthURL returns authentication url for given provider
-func (p *HTTPService) GetAuthURL(authEndpoint string, provider string, redirect string) string {
-	config := oauthSettings[provider].config(authEndpoint)
+func (p *HTTPService) GetAuthURL(authEndpoint string, provider string, redirect string) (string, error) {
+	config, _, err := p.getConfig(authEndpoint, provider)
+	if err != nil {
+		return "", err
+	}
 
-	authURL, _ := url.Parse(config.Endpoint.AuthURL)
-	parameters := url.Values{}
+	authURL, err := url.Parse(config.Endpoint.AuthURL)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse authorize URL of %s", provider)
+	}
+
+	parameters := authURL.Query()
 	parameters.Add("client_id", config.ClientID)
 	parameters.Add("scope", strings.Join(config.Scopes, " "))
 	parameters.Add("redirect_uri", config.RedirectURL)
 	parameters.Add("response_type", "code")
 	parameters.Add("state", redirect)
 	authURL.RawQuery = parameters.Encode()
-	return authURL.String()
+	return authURL.String(), nil
 }
 
 //GetProfile returns user profile based on provider and code
 func (p *HTTPService) GetProfile(authEndpoint string, provider string, code string) (*UserProfile, error) {
-	settings := oauthSettings[provider]
-	oauthToken, err := settings.config(authEndpoint).Exchange(oauth2.NoContext, code)
+	config, custom, err := p.getConfig(authEndpoint, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, p.client())
+	oauthToken, err := config.Exchange(ctx, code)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to exchange OAuth2 code with %s", provider)
 	}
 
+	if custom != nil {
+		return p.getCustomProfile(custom, oauthToken)
+	}
+
 	profile := &UserProfile{}
-	if err = doGet(settings.profileURL(oauthToken), profile); err != nil {
+	if err = doGet(p.client(), oauthSettings[provider].profileURL(oauthToken), profile); err != nil {
 		return nil, err
 	}
 
+	//Built-in providers only return addresses their users have confirmed
+	profile.EmailVerified = profile.Email != ""
+
 	//GitHub allows users to omit name, so we use their login name
 	if strings.Trim(profile.Name, " ") == "" {
 		profile.Name = profile.Login
@@ -131,3 +201,110 @@ func (p *HTTPService) GetProfile(authEndpoint string, provider string, code stri
 
 	return profile, nil
 }
+
+func (p *HTTPService) getCustomProfile(config *models.OAuthConfig, token *oauth2.Token) (*UserProfile, error) {
+	req, err := http.NewRequest("GET", config.ProfileURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create profile request for %s", config.Provider)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+
+	r, err := p.client().Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request GET %s", config.ProfileURL)
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read profile response from %s", config.ProfileURL)
+	}
+
+	if r.StatusCode >= 300 {
+		return nil, errors.New(fmt.Sprintf("failed to request GET %s with status %d: %s", config.ProfileURL, r.StatusCode, string(body)))
+	}
+
+	return ParseProfile(body, config)
+}
+
+//ParseProfile extracts user profile from a JSON response using the paths of given configuration
+func ParseProfile(body []byte, config *models.OAuthConfig) (*UserProfile, error) {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, errors.Wrap(err, "failed unmarshal response")
+	}
+
+	profile := &UserProfile{
+		ID:    json.Number(jsonPath(data, config.JSONUserIDPath)),
+		Name:  strings.TrimSpace(jsonPath(data, config.JSONUserNamePath)),
+		Email: strings.ToLower(strings.TrimSpace(jsonPath(data, config.JSONUserEmailPath))),
+		//Unless the provider is configured to only return confirmed addresses, the standard OpenID Connect claim is required,
+		//without it the email can't be trusted to identify an existing user
+		EmailVerified: config.EmailAlwaysVerified || jsonPath(data, "email_verified") == "true",
+	}
+
+	if profile.ID == "" {
+		return nil, errors.New(fmt.Sprintf("user id not found at '%s' in profile of %s", config.JSONUserIDPath, config.Provider))
+	}
+
+	//Fallback to the local part of the email when provider doesn't return a name
+	if profile.Name == "" && profile.Email != "" {
+		profile.Name = strings.Split(profile.Email, "@")[0]
+	}
+
+	return profile, nil
+}
+
+//jsonPath returns the value at given dot-separated path as a string
+func jsonPath(data interface{}, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		data = m[key]
+	}
+
+	switch value := data.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	return ""
+}
+
+//Discovery is the subset of an OpenID Connect discovery document used to configure a provider
+type Discovery struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+//Discover fetches the OpenID Connect discovery document of given issuer
+func (p *HTTPService) Discover(issuer string) (*Discovery, error) {
+	discoveryURL := issuer
+	if !strings.Contains(discoveryURL, "/.well-known/") {
+		discoveryURL = strings.TrimSuffix(discoveryURL, "/") + "/.well-known/openid-configuration"
+	}
+
+	discovery := &Discovery{}
+	if err := doGet(p.client(), discoveryURL, discovery); err != nil {
+		return nil, err
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return nil, errors.New(fmt.Sprintf("invalid discovery document at %s", discoveryURL))
+	}
+
+	return discovery, nil
+}
@@ -1,18 +1,89 @@
 package oauth_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
 	. "github.com/getfider/fider/app/pkg/assert"
 	"github.com/getfider/fider/app/pkg/oauth"
 )
 
+type configStore struct {
+	configs []*models.OAuthConfig
+}
+
+func (s *configStore) GetOAuthConfigByProvider(provider string) (*models.OAuthConfig, error) {
+	for _, config := range s.configs {
+		if config.Provider == provider {
+			return config, nil
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+//newIdentityProvider starts a minimal OpenID Connect provider that accepts the code 'th3-c0d3'
+func newIdentityProvider() *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "th3-c0d3" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"t0k3n","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":{"id":9876,"profile":{"name":"Jon Snow"},"mail":"Jon.Snow@got.com"},"email_verified":true}`))
+	})
+
+	return server
+}
+
+func customConfig(server *httptest.Server) *models.OAuthConfig {
+	return &models.OAuthConfig{
+		Provider:          "_test1234",
+		DisplayName:       "My IdP",
+		Status:            models.OAuthConfigEnabled,
+		ClientID:          "client-1",
+		ClientSecret:      "s3cr3t",
+		AuthorizeURL:      server.URL + "/authorize?prompt=login",
+		TokenURL:          server.URL + "/token",
+		ProfileURL:        server.URL + "/userinfo",
+		Scope:             "openid profile",
+		JSONUserIDPath:    "data.id",
+		JSONUserNamePath:  "data.profile.name",
+		JSONUserEmailPath: "data.mail",
+	}
+}
+
 func TestGetAuthURL_Facebook(t *testing.T) {
 	RegisterT(t)
 
 	svc := &oauth.HTTPService{}
-	authURL := svc.GetAuthURL("http://login.test.fider.io:3000", oauth.FacebookProvider, "")
+	authURL, err := svc.GetAuthURL("http://login.test.fider.io:3000", oauth.FacebookProvider, "")
 
+	Expect(err).IsNil()
 	Expect(authURL).Equals("https://www.facebook.com/dialog/oauth?client_id=&redirect_uri=http%3A%2F%2Flogin.test.fider.io%3A3000%2Foauth%2Ffacebook%2Fcallback&response_type=code&scope=public_profile+email&state=")
 }
 
@@ -20,8 +91,9 @@ func TestGetAuthURL_Google(t *testing.T) {
 	RegisterT(t)
 
 	svc := &oauth.HTTPService{}
-	authURL := svc.GetAuthURL("http://login.test.fider.io:3000", oauth.GoogleProvider, "")
+	authURL, err := svc.GetAuthURL("http://login.test.fider.io:3000", oauth.GoogleProvider, "")
 
+	Expect(err).IsNil()
 	Expect(authURL).Equals("https://accounts.google.com/o/oauth2/auth?client_id=&redirect_uri=http%3A%2F%2Flogin.test.fider.io%3A3000%2Foauth%2Fgoogle%2Fcallback&response_type=code&scope=https%3A%2F%2Fwww.googleapis.com%2Fauth%2Fuserinfo.profile+https%3A%2F%2Fwww.googleapis.com%2Fauth%2Fuserinfo.email&state=")
 }
 
@@ -29,7 +101,142 @@ func TestGetAuthURL_GitHub(t *testing.T) {
 	RegisterT(t)
 
 	svc := &oauth.HTTPService{}
-	authURL := svc.GetAuthURL("http://login.test.fider.io:3000", oauth.GitHubProvider, "")
+	authURL, err := svc.GetAuthURL("http://login.test.fider.io:3000", oauth.GitHubProvider, "")
 
+	Expect(err).IsNil()
 	Expect(authURL).Equals("https://github.com/login/oauth/authorize?client_id=&redirect_uri=http%3A%2F%2Flogin.test.fider.io%3A3000%2Foauth%2Fgithub%2Fcallback&response_type=code&scope=user%3Aemail&state=")
 }
+
+func TestGetAuthURL_CustomProvider(t *testing.T) {
+	RegisterT(t)
+
+	server := newIdentityProvider()
+	defer server.Close()
+
+	svc := &oauth.HTTPService{Configs: &configStore{[]*models.OAuthConfig{customConfig(server)}}}
+	authURL, err := svc.GetAuthURL("http://login.test.fider.io:3000", "_test1234", "http://demo.test.fider.io")
+	Expect(err).IsNil()
+
+	u, _ := url.Parse(authURL)
+	Expect(u.Host).Equals(server.Listener.Addr().String())
+	Expect(u.Path).Equals("/authorize")
+	Expect(u.Query().Get("prompt")).Equals("login")
+	Expect(u.Query().Get("client_id")).Equals("client-1")
+	Expect(u.Query().Get("scope")).Equals("openid profile")
+	Expect(u.Query().Get("redirect_uri")).Equals("http://login.test.fider.io:3000/oauth/_test1234/callback")
+	Expect(u.Query().Get("state")).Equals("http://demo.test.fider.io")
+}
+
+func TestGetAuthURL_UnknownProvider(t *testing.T) {
+	RegisterT(t)
+
+	svc := &oauth.HTTPService{Configs: &configStore{}}
+	_, err := svc.GetAuthURL("http://login.test.fider.io:3000", "_unknown", "")
+	Expect(err).Equals(app.ErrNotFound)
+
+	_, err = svc.GetAuthURL("http://login.test.fider.io:3000", "twitter", "")
+	Expect(err).IsNotNil()
+}
+
+func TestGetProfile_CustomProvider(t *testing.T) {
+	RegisterT(t)
+
+	server := newIdentityProvider()
+	defer server.Close()
+
+	svc := &oauth.HTTPService{Configs: &configStore{[]*models.OAuthConfig{customConfig(server)}}, Client: server.Client()}
+	profile, err := svc.GetProfile("http://login.test.fider.io:3000", "_test1234", "th3-c0d3")
+	Expect(err).IsNil()
+	Expect(profile.ID.String()).Equals("9876")
+	Expect(profile.Name).Equals("Jon Snow")
+	Expect(profile.Email).Equals("jon.snow@got.com")
+	Expect(profile.EmailVerified).IsTrue()
+
+	_, err = svc.GetProfile("http://login.test.fider.io:3000", "_test1234", "wrong")
+	Expect(err).IsNotNil()
+}
+
+func TestGetProfile_DisabledCustomProvider(t *testing.T) {
+	RegisterT(t)
+
+	server := newIdentityProvider()
+	defer server.Close()
+
+	config := customConfig(server)
+	config.Status = models.OAuthConfigDisabled
+
+	svc := &oauth.HTTPService{Configs: &configStore{[]*models.OAuthConfig{config}}, Client: server.Client()}
+	profile, err := svc.GetProfile("http://login.test.fider.io:3000", "_test1234", "th3-c0d3")
+	Expect(err).IsNotNil()
+	Expect(profile).IsNil()
+}
+
+func TestParseProfile(t *testing.T) {
+	RegisterT(t)
+
+	config := &models.OAuthConfig{JSONUserIDPath: "sub", JSONUserNamePath: "name", JSONUserEmailPath: "email"}
+
+	profile, err := oauth.ParseProfile([]byte(`{"sub":"abc-123","name":" Arya Stark ","email":"arya@got.com"}`), config)
+	Expect(err).IsNil()
+	Expect(profile.ID.String()).Equals("abc-123")
+	Expect(profile.Name).Equals("Arya Stark")
+	Expect(profile.Email).Equals("arya@got.com")
+	Expect(profile.EmailVerified).IsFalse()
+
+	profile, err = oauth.ParseProfile([]byte(`{"sub":"abc-123","email":"arya@got.com","email_verified":true}`), config)
+	Expect(err).IsNil()
+	Expect(profile.EmailVerified).IsTrue()
+
+	alwaysVerified := &models.OAuthConfig{JSONUserIDPath: "id", JSONUserEmailPath: "email", EmailAlwaysVerified: true}
+	profile, err = oauth.ParseProfile([]byte(`{"id":1234,"login":"arya","email":"arya@got.com"}`), alwaysVerified)
+	Expect(err).IsNil()
+	Expect(profile.Email).Equals("arya@got.com")
+	Expect(profile.EmailVerified).IsTrue()
+
+	profile, err = oauth.ParseProfile([]byte(`{"sub":12345678901234567890,"email":"arya@got.com"}`), config)
+	Expect(err).IsNil()
+	Expect(profile.ID.String()).Equals("12345678901234567890")
+	Expect(profile.Name).Equals("arya")
+
+	profile, err = oauth.ParseProfile([]byte(`{"id":"abc-123"}`), config)
+	Expect(err).IsNotNil()
+	Expect(profile).IsNil()
+}
+
+func TestDiscover(t *testing.T) {
+	RegisterT(t)
+
+	server := newIdentityProvider()
+	defer server.Close()
+
+	svc := &oauth.HTTPService{Client: server.Client()}
+	discovery, err := svc.Discover(server.URL + "/")
+	Expect(err).IsNil()
+	Expect(discovery.AuthorizationEndpoint).Equals(server.URL + "/authorize")
+	Expect(discovery.TokenEndpoint).Equals(server.URL + "/token")
+	Expect(discovery.UserinfoEndpoint).Equals(server.URL + "/userinfo")
+
+	discovery, err = svc.Discover(server.URL + "/.well-known/openid-configuration")
+	Expect(err).IsNil()
+	Expect(discovery.TokenEndpoint).Equals(server.URL + "/token")
+
+	discovery, err = svc.Discover(server.URL + "/unknown")
+	Expect(err).IsNotNil()
+	Expect(discovery).IsNil()
+}
+
+func TestGetProfile_CustomProvider_PrivateAddress(t *testing.T) {
+	RegisterT(t)
+
+	server := newIdentityProvider()
+	defer server.Close()
+
+	svc := &oauth.HTTPService{Configs: &configStore{[]*models.OAuthConfig{customConfig(server)}}}
+	profile, err := svc.GetProfile("http://login.test.fider.io:3000", "_test1234", "th3-c0d3")
+	Expect(err).IsNotNil()
+	Expect(profile).IsNil()
+
+	discovery, err := svc.Discover(server.URL)
+	Expect(err).IsNotNil()
+	Expect(discovery).IsNil()
+}
@@ -3,6 +3,9 @@ package oauth
 import (
 	"encoding/json"
 	"os"
+	"strings"
+
+	"github.com/getfider/fider/app/models"
 )
 
 const (
@@ -20,6 +23,19 @@ type UserProfile struct {
 	Name  string
 	Login string
 	Email string
+	//EmailVerified is true when the provider asserts that the user owns the email address
+	EmailVerified bool `json:"-"`
+}
+
+//IsCustomProvider returns true if provider was registered by a tenant
+func IsCustomProvider(name string) bool {
+	return strings.HasPrefix(name, "_")
+}
+
+//IsBuiltInProvider returns true if provider is one of the providers configured by environment variables
+func IsBuiltInProvider(name string) bool {
+	_, ok := oauthSettings[name]
+	return ok
 }
 
 //IsProviderEnabled returns true if provider is enabled
@@ -34,8 +50,14 @@ func IsProviderEnabled(name string) bool {
 	return false
 }
 
+//ConfigStore gives access to the custom OAuth providers of current tenant
+type ConfigStore interface {
+	GetOAuthConfigByProvider(provider string) (*models.OAuthConfig, error)
+}
+
 //Service provides OAuth operations
 type Service interface {
-	GetAuthURL(authEndpoint string, provider string, redirect string) string
+	GetAuthURL(authEndpoint string, provider string, redirect string) (string, error)
 	GetProfile(authEndpoint string, provider string, code string) (*UserProfile, error)
+	Discover(issuer string) (*Discovery, error)
 }
@@ -0,0 +1,119 @@
+package safehttp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//privateNetworks are the private IPv4 ranges of RFC 1918 and the unique local IPv6 range of RFC 4193
+var privateNetworks = parseNetworks("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7")
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+//IsPublicIP returns false for addresses of the host itself and of private or link-local networks
+func IsPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+//CheckPublicHost returns an error unless every address given host resolves to is public
+func CheckPublicHost(host string) error {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve %s", host)
+	}
+
+	for _, ip := range ips {
+		if !IsPublicIP(ip) {
+			return errors.New(fmt.Sprintf("%s resolves to non-public address %s", host, ip))
+		}
+	}
+	return nil
+}
+
+//DialFunc connects to given network address, as net.Dialer.DialContext does
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+//Dialer returns a dial function that refuses to connect to non-public addresses.
+//The host is resolved once and the connection is made to the checked address,
+//so it can't be bypassed by a host that changes its records between the check and the connection.
+func Dialer(timeout time.Duration) DialFunc {
+	dialer := &net.Dialer{Timeout: timeout}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to resolve %s", host)
+		}
+
+		for _, addr := range addrs {
+			if !IsPublicIP(addr.IP) {
+				return nil, errors.New(fmt.Sprintf("connection to non-public address %s is not allowed", addr.IP))
+			}
+		}
+
+		err = errors.New(fmt.Sprintf("%s has no addresses", host))
+		for _, addr := range addrs {
+			conn, dialErr := dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+			if dialErr == nil {
+				return conn, nil
+			}
+			err = dialErr
+		}
+		return nil, err
+	}
+}
+
+type httpsOnly struct {
+	next http.RoundTripper
+}
+
+func (t *httpsOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return nil, errors.New(fmt.Sprintf("request to %s is not allowed, only HTTPS is supported", req.URL))
+	}
+	return t.next.RoundTrip(req)
+}
+
+//NewClient returns an HTTP client for URLs configured by tenants, which only talks HTTPS to public addresses.
+//Redirects go through the same checks.
+func NewClient() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &httpsOnly{
+			next: &http.Transport{
+				DialContext:         Dialer(10 * time.Second),
+				TLSHandshakeTimeout: 10 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package safehttp_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/safehttp"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	RegisterT(t)
+
+	for _, ip := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0"} {
+		Expect(safehttp.IsPublicIP(net.ParseIP(ip))).IsFalse()
+	}
+
+	for _, ip := range []string{"8.8.8.8", "104.16.0.1", "2606:4700::1111"} {
+		Expect(safehttp.IsPublicIP(net.ParseIP(ip))).IsTrue()
+	}
+}
+
+func TestCheckPublicHost(t *testing.T) {
+	RegisterT(t)
+
+	Expect(safehttp.CheckPublicHost("localhost")).IsNotNil()
+	Expect(safehttp.CheckPublicHost("127.0.0.1")).IsNotNil()
+	Expect(safehttp.CheckPublicHost("10.0.0.1")).IsNotNil()
+	Expect(safehttp.CheckPublicHost("8.8.8.8")).IsNil()
+}
+
+func TestNewClient_Rejected(t *testing.T) {
+	RegisterT(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	client := safehttp.NewClient()
+	for _, url := range []string{
+		server.URL,
+		"http://8.8.8.8/",
+		"https://127.0.0.1/",
+		"https://169.254.169.254/latest/meta-data/",
+	} {
+		resp, err := client.Get(url)
+		Expect(err).IsNotNil()
+		Expect(resp).IsNil()
+	}
+}
+
+func TestDialer_Rejected(t *testing.T) {
+	RegisterT(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).IsNil()
+	defer listener.Close()
+
+	dial := safehttp.Dialer(time.Second)
+	for _, address := range []string{
+		listener.Addr().String(),
+		"localhost:25",
+		"10.0.0.1:25",
+		"[fd00::1]:25",
+	} {
+		conn, err := dial(context.Background(), "tcp", address)
+		Expect(err).IsNotNil()
+		Expect(conn).IsNil()
+	}
+}
@@ -0,0 +1,388 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+//Provider is the name used to link users to the SAML IdP of their tenant
+const Provider = "saml"
+
+const (
+	nsMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	nsAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+
+	bindingRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	bindingPOST     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	statusSuccess   = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	methodBearer    = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	nameIDEmail     = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+
+	//maxClockSkew is how much the clocks of the IdP and Fider can disagree
+	maxClockSkew = 3 * time.Minute
+)
+
+var errSignatureNotFound = errors.New("element is not signed")
+
+//ServiceProvider holds the configuration used to talk to the IdP of a tenant
+type ServiceProvider struct {
+	EntityID       string
+	ACSURL         string
+	IdPEntityID    string
+	IdPSSOURL      string
+	IdPCertificate string
+}
+
+//Assertion is the verified identity sent by the IdP
+type Assertion struct {
+	ID         string
+	NameID     string
+	Attributes map[string][]string
+	//ExpiresOn is when the assertion stops being valid, so it only needs to be remembered until then
+	ExpiresOn time.Time
+}
+
+//Attribute returns the first value of given attribute, looked up by Name or FriendlyName
+func (a *Assertion) Attribute(name string) string {
+	if name == "" {
+		return ""
+	}
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+//Metadata returns the SAML metadata document of this service provider
+func (sp *ServiceProvider) Metadata() []byte {
+	return []byte(fmt.Sprintf(
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<md:EntityDescriptor xmlns:md="%s" entityID="%s">`+
+			`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="%s">`+
+			`<md:NameIDFormat>%s</md:NameIDFormat>`+
+			`<md:AssertionConsumerService Binding="%s" Location="%s" index="0" isDefault="true"/>`+
+			`</md:SPSSODescriptor>`+
+			`</md:EntityDescriptor>`,
+		nsMetadata, escapeAttr(sp.EntityID), nsProtocol, nameIDEmail, bindingPOST, escapeAttr(sp.ACSURL),
+	))
+}
+
+//NewRequestID returns a random ID for an AuthnRequest, which the IdP sends back as InResponseTo
+func NewRequestID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", errors.Wrap(err, "failed to generate request id")
+	}
+	return "_" + hex.EncodeToString(id), nil
+}
+
+//AuthURL returns the IdP address the user is sent to, using the HTTP-Redirect binding
+func (sp *ServiceProvider) AuthURL(requestID, relayState string) (string, error) {
+	request := fmt.Sprintf(
+		`<samlp:AuthnRequest xmlns:samlp="%s" xmlns:saml="%s" ID="%s" Version="2.0" IssueInstant="%s" Destination="%s" AssertionConsumerServiceURL="%s" ProtocolBinding="%s">`+
+			`<saml:Issuer>%s</saml:Issuer>`+
+			`<samlp:NameIDPolicy Format="%s" AllowCreate="true"/>`+
+			`</samlp:AuthnRequest>`,
+		nsProtocol, nsAssertion, escapeAttr(requestID), time.Now().UTC().Format(time.RFC3339),
+		escapeAttr(sp.IdPSSOURL), escapeAttr(sp.ACSURL), bindingPOST, escapeText(sp.EntityID), nameIDEmail,
+	)
+
+	var buf bytes.Buffer
+	writer, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create deflate writer")
+	}
+	writer.Write([]byte(request))
+	writer.Close()
+
+	authURL, err := url.Parse(sp.IdPSSOURL)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse IdP SSO URL")
+	}
+
+	parameters := authURL.Query()
+	parameters.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		parameters.Set("RelayState", relayState)
+	}
+	authURL.RawQuery = parameters.Encode()
+	return authURL.String(), nil
+}
+
+//ParseResponse verifies a base64 encoded SAMLResponse and returns its assertion.
+//requestID is the AuthnRequest sent by this browser, if any. A response to a request must match it,
+//while a response without InResponseTo is accepted as IdP-initiated.
+func (sp *ServiceProvider) ParseResponse(encoded, requestID string, now time.Time) (*Assertion, error) {
+	cert, err := ParseCertificate(sp.IdPCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode SAMLResponse")
+	}
+
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !root.is(nsProtocol, "Response") {
+		return nil, errors.New("SAMLResponse must be a samlp:Response")
+	}
+
+	if destination := root.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, errors.New(fmt.Sprintf("response destination '%s' does not match '%s'", destination, sp.ACSURL))
+	}
+
+	if err := checkInResponseTo(root.attr("InResponseTo"), requestID); err != nil {
+		return nil, err
+	}
+
+	status := root.element(nsProtocol, "Status")
+	if status == nil {
+		return nil, errors.New("response has no status")
+	}
+	statusCode := status.element(nsProtocol, "StatusCode")
+	if statusCode == nil || statusCode.attr("Value") != statusSuccess {
+		return nil, errors.New("response status is not successful")
+	}
+
+	if root.element(nsAssertion, "EncryptedAssertion") != nil {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+
+	assertions := root.elements(nsAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("response must have exactly one assertion")
+	}
+	assertion := assertions[0]
+
+	//Assertion is usually signed, but some IdPs only sign the whole response
+	err = verifySignature(root, assertion, cert)
+	if err == errSignatureNotFound {
+		err = verifySignature(root, root, cert)
+		if err == errSignatureNotFound {
+			return nil, errors.New("response is not signed")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sp.readAssertion(assertion, requestID, now)
+}
+
+func (sp *ServiceProvider) readAssertion(assertion *xmlNode, requestID string, now time.Time) (*Assertion, error) {
+	issuer := assertion.element(nsAssertion, "Issuer")
+	if sp.IdPEntityID != "" && (issuer == nil || issuer.text() != sp.IdPEntityID) {
+		return nil, errors.New("assertion issuer does not match IdP")
+	}
+
+	subject := assertion.element(nsAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("assertion has no subject")
+	}
+
+	result := &Assertion{ID: assertion.attr("ID"), Attributes: make(map[string][]string)}
+	if result.ID == "" {
+		return nil, errors.New("assertion has no ID")
+	}
+	if nameID := subject.element(nsAssertion, "NameID"); nameID != nil {
+		result.NameID = nameID.text()
+	}
+
+	for _, confirmation := range subject.elements(nsAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != methodBearer {
+			continue
+		}
+		data := confirmation.element(nsAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if recipient := data.attr("Recipient"); recipient != "" && recipient != sp.ACSURL {
+			return nil, errors.New(fmt.Sprintf("assertion recipient '%s' does not match '%s'", recipient, sp.ACSURL))
+		}
+		if err := checkInResponseTo(data.attr("InResponseTo"), requestID); err != nil {
+			return nil, err
+		}
+		if err := result.checkNotOnOrAfter(data.attr("NotOnOrAfter"), now); err != nil {
+			return nil, err
+		}
+	}
+
+	conditions := assertion.element(nsAssertion, "Conditions")
+	if conditions == nil {
+		return nil, errors.New("assertion has no conditions")
+	}
+	if notBefore := conditions.attr("NotBefore"); notBefore != "" {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse NotBefore")
+		}
+		if now.Add(maxClockSkew).Before(t) {
+			return nil, errors.New("assertion is not yet valid")
+		}
+	}
+	if err := result.checkNotOnOrAfter(conditions.attr("NotOnOrAfter"), now); err != nil {
+		return nil, err
+	}
+	if result.ExpiresOn.IsZero() {
+		return nil, errors.New("assertion has no NotOnOrAfter")
+	}
+
+	for _, restriction := range conditions.elements(nsAssertion, "AudienceRestriction") {
+		valid := false
+		for _, audience := range restriction.elements(nsAssertion, "Audience") {
+			if audience.text() == sp.EntityID {
+				valid = true
+			}
+		}
+		if !valid {
+			return nil, errors.New(fmt.Sprintf("assertion audience does not include '%s'", sp.EntityID))
+		}
+	}
+
+	for _, statement := range assertion.elements(nsAssertion, "AttributeStatement") {
+		for _, attribute := range statement.elements(nsAssertion, "Attribute") {
+			var values []string
+			for _, value := range attribute.elements(nsAssertion, "AttributeValue") {
+				values = append(values, value.text())
+			}
+			for _, key := range []string{attribute.attr("Name"), attribute.attr("FriendlyName")} {
+				if key != "" {
+					result.Attributes[key] = append(result.Attributes[key], values...)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+//checkNotOnOrAfter fails if given time has passed, otherwise it keeps the earliest one as expiration of the assertion
+func (a *Assertion) checkNotOnOrAfter(value string, now time.Time) error {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse NotOnOrAfter")
+	}
+	if !now.Add(-maxClockSkew).Before(t) {
+		return errors.New("assertion has expired")
+	}
+	if t = t.Add(maxClockSkew); a.ExpiresOn.IsZero() || t.Before(a.ExpiresOn) {
+		a.ExpiresOn = t
+	}
+	return nil
+}
+
+//checkInResponseTo fails when a response answers a request that was not sent by this browser
+func checkInResponseTo(inResponseTo, requestID string) error {
+	if inResponseTo != "" && inResponseTo != requestID {
+		return errors.New(fmt.Sprintf("response is to request '%s', which was not sent by this browser", inResponseTo))
+	}
+	return nil
+}
+
+//IdPMetadata is the information extracted from the metadata XML of an IdP
+type IdPMetadata struct {
+	EntityID    string
+	SSOURL      string
+	Certificate string
+}
+
+//ParseMetadata reads entity id, SSO address and signing certificate from IdP metadata
+func ParseMetadata(data []byte) (*IdPMetadata, error) {
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := root
+	if root.is(nsMetadata, "EntitiesDescriptor") {
+		entity = nil
+		for _, e := range root.elements(nsMetadata, "EntityDescriptor") {
+			if e.element(nsMetadata, "IDPSSODescriptor") != nil {
+				entity = e
+				break
+			}
+		}
+	}
+	if entity == nil || !entity.is(nsMetadata, "EntityDescriptor") {
+		return nil, errors.New("metadata has no IdP EntityDescriptor")
+	}
+
+	descriptor := entity.element(nsMetadata, "IDPSSODescriptor")
+	if descriptor == nil {
+		return nil, errors.New("metadata has no IDPSSODescriptor")
+	}
+
+	metadata := &IdPMetadata{EntityID: entity.attr("entityID")}
+	for _, service := range descriptor.elements(nsMetadata, "SingleSignOnService") {
+		if service.attr("Binding") == bindingRedirect {
+			metadata.SSOURL = service.attr("Location")
+			break
+		}
+		if metadata.SSOURL == "" {
+			metadata.SSOURL = service.attr("Location")
+		}
+	}
+
+	for _, key := range descriptor.elements(nsMetadata, "KeyDescriptor") {
+		if use := key.attr("use"); use != "" && use != "signing" {
+			continue
+		}
+		if keyInfo := key.element(nsDSig, "KeyInfo"); keyInfo != nil {
+			if data := keyInfo.element(nsDSig, "X509Data"); data != nil {
+				if cert := data.element(nsDSig, "X509Certificate"); cert != nil {
+					metadata.Certificate = strings.Join(strings.Fields(cert.text()), "")
+					break
+				}
+			}
+		}
+	}
+
+	if metadata.SSOURL == "" {
+		return nil, errors.New("metadata has no SingleSignOnService")
+	}
+	if metadata.Certificate == "" {
+		return nil, errors.New("metadata has no signing certificate")
+	}
+
+	return metadata, nil
+}
+
+//ParseCertificate reads a certificate either in PEM format or as plain base64
+func ParseCertificate(value string) (*x509.Certificate, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(strings.TrimSpace(value))); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := decodeBase64(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode certificate")
+		}
+		der = decoded
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	return cert, nil
+}
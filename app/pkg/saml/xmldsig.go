@@ -0,0 +1,416 @@
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	//Register hash functions used by signature algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+const (
+	nsXML       = "http://www.w3.org/XML/1998/namespace"
+	nsDSig      = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnvelope = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+)
+
+//SHA-1 is not accepted, as collisions make it unsafe for signatures
+var digestAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2001/04/xmlenc#sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmlenc#sha512": crypto.SHA512,
+}
+
+var signatureAlgorithms = map[string]crypto.Hash{
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256": crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512": crypto.SHA512,
+}
+
+//xmlNode is a minimal DOM element that keeps namespace prefixes, which is required for canonicalization
+type xmlNode struct {
+	parent   *xmlNode
+	prefix   string
+	local    string
+	nsDecls  []xml.Attr
+	attrs    []xml.Attr
+	children []interface{}
+}
+
+//parseXML builds a tree from given document, rejecting DTDs
+func parseXML(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *xmlNode
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse xml")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if root != nil && current == nil {
+				return nil, errors.New("xml document has more than one root element")
+			}
+			node := &xmlNode{parent: current, prefix: t.Name.Space, local: t.Name.Local}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" {
+					node.nsDecls = append(node.nsDecls, xml.Attr{Name: xml.Name{Local: attr.Name.Local}, Value: attr.Value})
+				} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+					node.nsDecls = append(node.nsDecls, xml.Attr{Name: xml.Name{Local: ""}, Value: attr.Value})
+				} else {
+					node.attrs = append(node.attrs, attr)
+				}
+			}
+			if current == nil {
+				root = node
+			} else {
+				current.children = append(current.children, node)
+			}
+			current = node
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.local != t.Name.Local {
+				return nil, errors.New("xml document has unbalanced elements")
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, xml.CharData(t.Copy()))
+			}
+		case xml.Directive:
+			return nil, errors.New("xml document must not have directives")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, errors.New("xml document is incomplete")
+	}
+	return root, nil
+}
+
+//lookupNamespace returns the namespace bound to given prefix in the scope of this element
+func (n *xmlNode) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for e := n; e != nil; e = e.parent {
+		for _, decl := range e.nsDecls {
+			if decl.Name.Local == prefix {
+				return decl.Value, true
+			}
+		}
+	}
+	return "", prefix == ""
+}
+
+func (n *xmlNode) namespace() string {
+	ns, _ := n.lookupNamespace(n.prefix)
+	return ns
+}
+
+func (n *xmlNode) is(namespace, local string) bool {
+	return n.local == local && n.namespace() == namespace
+}
+
+func (n *xmlNode) attr(local string) string {
+	for _, attr := range n.attrs {
+		if attr.Name.Space == "" && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func (n *xmlNode) elements(namespace, local string) []*xmlNode {
+	var result []*xmlNode
+	for _, child := range n.children {
+		if e, ok := child.(*xmlNode); ok && e.is(namespace, local) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func (n *xmlNode) element(namespace, local string) *xmlNode {
+	elements := n.elements(namespace, local)
+	if len(elements) == 0 {
+		return nil
+	}
+	return elements[0]
+}
+
+func (n *xmlNode) text() string {
+	var buf bytes.Buffer
+	for _, child := range n.children {
+		if data, ok := child.(xml.CharData); ok {
+			buf.Write(data)
+		}
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+//countID returns how many elements of the tree have given ID
+func (n *xmlNode) countID(id string) int {
+	count := 0
+	if n.attr("ID") == id {
+		count++
+	}
+	for _, child := range n.children {
+		if e, ok := child.(*xmlNode); ok {
+			count += e.countID(id)
+		}
+	}
+	return count
+}
+
+//canonicalize serializes given element using Exclusive XML Canonicalization without comments.
+//The excluded element is left out of the output, as required by the enveloped signature transform.
+func canonicalize(n *xmlNode, inclusivePrefixes []string, exclude *xmlNode) []byte {
+	c := &canonicalizer{inclusive: inclusivePrefixes, exclude: exclude}
+	c.element(n, map[string]string{})
+	return c.buf.Bytes()
+}
+
+type canonicalizer struct {
+	buf       bytes.Buffer
+	inclusive []string
+	exclude   *xmlNode
+}
+
+func (c *canonicalizer) element(n *xmlNode, rendered map[string]string) {
+	utilized := map[string]bool{n.prefix: true}
+	for _, attr := range n.attrs {
+		if attr.Name.Space != "" && attr.Name.Space != "xml" {
+			utilized[attr.Name.Space] = true
+		}
+	}
+	for _, prefix := range c.inclusive {
+		if prefix == "#default" {
+			prefix = ""
+		}
+		if _, ok := n.lookupNamespace(prefix); ok {
+			utilized[prefix] = true
+		}
+	}
+
+	prefixes := make([]string, 0, len(utilized))
+	for prefix := range utilized {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	scope, copied := rendered, false
+	c.buf.WriteString("<" + qualifiedName(n.prefix, n.local))
+	for _, prefix := range prefixes {
+		ns, ok := n.lookupNamespace(prefix)
+		if !ok || prefix == "xml" {
+			continue
+		}
+		previous, hasPrevious := rendered[prefix]
+		if prefix == "" && ns == "" && !hasPrevious {
+			continue
+		}
+		if hasPrevious && previous == ns {
+			continue
+		}
+		if !copied {
+			copied = true
+			scope = make(map[string]string, len(rendered)+1)
+			for k, v := range rendered {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = ns
+		if prefix == "" {
+			c.buf.WriteString(` xmlns="` + escapeAttr(ns) + `"`)
+		} else {
+			c.buf.WriteString(` xmlns:` + prefix + `="` + escapeAttr(ns) + `"`)
+		}
+	}
+
+	attrs := make([]xml.Attr, len(n.attrs))
+	copy(attrs, n.attrs)
+	sort.Slice(attrs, func(i, j int) bool {
+		nsi, _ := n.lookupNamespace(attrs[i].Name.Space)
+		nsj, _ := n.lookupNamespace(attrs[j].Name.Space)
+		if attrs[i].Name.Space == "" {
+			nsi = ""
+		}
+		if attrs[j].Name.Space == "" {
+			nsj = ""
+		}
+		if nsi != nsj {
+			return nsi < nsj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+	for _, attr := range attrs {
+		c.buf.WriteString(" " + qualifiedName(attr.Name.Space, attr.Name.Local) + `="` + escapeAttr(attr.Value) + `"`)
+	}
+	c.buf.WriteString(">")
+
+	for _, child := range n.children {
+		switch e := child.(type) {
+		case *xmlNode:
+			if e != c.exclude {
+				c.element(e, scope)
+			}
+		case xml.CharData:
+			c.buf.WriteString(escapeText(string(e)))
+		}
+	}
+
+	c.buf.WriteString("</" + qualifiedName(n.prefix, n.local) + ">")
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
+
+//inclusivePrefixes returns the PrefixList of the InclusiveNamespaces child of given transform
+func inclusivePrefixes(transform *xmlNode) []string {
+	if transform == nil {
+		return nil
+	}
+	inclusive := transform.element(nsExcC14N, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil
+	}
+	return strings.Fields(inclusive.attr("PrefixList"))
+}
+
+//verifySignature checks the enveloped signature of given element against the certificate.
+//It returns errSignatureNotFound when the element is not signed at all.
+func verifySignature(root, n *xmlNode, cert *x509.Certificate) error {
+	signature := n.element(nsDSig, "Signature")
+	if signature == nil {
+		return errSignatureNotFound
+	}
+
+	id := n.attr("ID")
+	if id == "" {
+		return errors.New("signed element has no ID")
+	}
+	if root.countID(id) != 1 {
+		return errors.New("signed element ID is not unique")
+	}
+
+	signedInfo := signature.element(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("signature has no SignedInfo")
+	}
+
+	c14nMethod := signedInfo.element(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != nsExcC14N {
+		return errors.New("unsupported canonicalization method")
+	}
+
+	signatureMethod := signedInfo.element(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return errors.New("signature has no SignatureMethod")
+	}
+	signatureHash, ok := signatureAlgorithms[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return errors.New(fmt.Sprintf("unsupported signature method '%s'", signatureMethod.attr("Algorithm")))
+	}
+
+	references := signedInfo.elements(nsDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("signature must have exactly one reference")
+	}
+	reference := references[0]
+	if reference.attr("URI") != "#"+id {
+		return errors.New("signature reference does not match signed element")
+	}
+
+	var prefixes []string
+	if transforms := reference.element(nsDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.elements(nsDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case algEnvelope:
+			case nsExcC14N:
+				prefixes = inclusivePrefixes(transform)
+			default:
+				return errors.New(fmt.Sprintf("unsupported transform '%s'", transform.attr("Algorithm")))
+			}
+		}
+	}
+
+	digestMethod := reference.element(nsDSig, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("reference has no DigestMethod")
+	}
+	digestHash, ok := digestAlgorithms[digestMethod.attr("Algorithm")]
+	if !ok {
+		return errors.New(fmt.Sprintf("unsupported digest method '%s'", digestMethod.attr("Algorithm")))
+	}
+
+	digestValue := reference.element(nsDSig, "DigestValue")
+	if digestValue == nil {
+		return errors.New("reference has no DigestValue")
+	}
+	expectedDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return errors.Wrap(err, "failed to decode digest")
+	}
+
+	h := digestHash.New()
+	h.Write(canonicalize(n, prefixes, signature))
+	if !bytes.Equal(h.Sum(nil), expectedDigest) {
+		return errors.New("digest of signed element does not match")
+	}
+
+	signatureValue := signature.element(nsDSig, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("signature has no SignatureValue")
+	}
+	sig, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return errors.Wrap(err, "failed to decode signature")
+	}
+
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("certificate must have an RSA public key")
+	}
+
+	h = signatureHash.New()
+	h.Write(canonicalize(signedInfo, inclusivePrefixes(c14nMethod), nil))
+	if err := rsa.VerifyPKCS1v15(publicKey, signatureHash, h.Sum(nil), sig); err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+
+	return nil
+}
+
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
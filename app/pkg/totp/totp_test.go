@@ -0,0 +1,89 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/totp"
+)
+
+//RFC 6238 test secret
+var secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238(t *testing.T) {
+	RegisterT(t)
+
+	for unix, expected := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		code, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+		Expect(err).IsNil()
+		Expect(code).Equals(expected)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	RegisterT(t)
+
+	now := time.Unix(1111111111, 0)
+	step, ok := totp.Validate(secret, "050471", now)
+	Expect(ok).IsTrue()
+	Expect(step).Equals(totp.Step(now))
+
+	step, ok = totp.Validate(secret, "050 471", now.Add(30*time.Second))
+	Expect(ok).IsTrue()
+	Expect(step).Equals(totp.Step(now))
+
+	_, ok = totp.Validate(secret, "050471", now.Add(90*time.Second))
+	Expect(ok).IsFalse()
+
+	_, ok = totp.Validate(secret, "123456", now)
+	Expect(ok).IsFalse()
+
+	_, ok = totp.Validate(secret, "", now)
+	Expect(ok).IsFalse()
+}
+
+func TestGenerateSecret(t *testing.T) {
+	RegisterT(t)
+
+	secret, err := totp.GenerateSecret()
+	Expect(err).IsNil()
+	Expect(secret).HasLen(32)
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	Expect(err).IsNil()
+	_, ok := totp.Validate(secret, code, time.Now())
+	Expect(ok).IsTrue()
+}
+
+func TestURI(t *testing.T) {
+	RegisterT(t)
+
+	uri := totp.URI("JBSWY3DPEHPK3PXP", "Demonstration", "jon.snow@got.com")
+	Expect(strings.HasPrefix(uri, "otpauth://totp/Demonstration:jon.snow@got.com?")).IsTrue()
+	Expect(uri).ContainsSubstring("secret=JBSWY3DPEHPK3PXP")
+	Expect(uri).ContainsSubstring("issuer=Demonstration")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	RegisterT(t)
+
+	codes, err := totp.GenerateRecoveryCodes(10)
+	Expect(err).IsNil()
+	Expect(codes).HasLen(10)
+	Expect(codes[0]).HasLen(9)
+	Expect(codes[0] == codes[1]).IsFalse()
+
+	Expect(totp.HashRecoveryCode(codes[0])).Equals(totp.HashRecoveryCode(" " + strings.ToUpper(codes[0]) + " "))
+	Expect(totp.HashRecoveryCode(codes[0])).Equals(totp.HashRecoveryCode(strings.Replace(codes[0], "-", "", -1)))
+	Expect(totp.HashRecoveryCode(codes[0]) == totp.HashRecoveryCode(codes[1])).IsFalse()
+}
@@ -1,15 +1,17 @@
 package validate
 
 import (
-	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 
 	"github.com/getfider/fider/app/pkg/env"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/storage"
 )
 
 var emailRegex = regexp.MustCompile("^(((([a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+(\\.([a-zA-Z]|\\d|[!#\\$%&'\\*\\+\\-\\/=\\?\\^_`{\\|}~]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])+)*)|((\\x22)((((\\x20|\\x09)*(\\x0d\\x0a))?(\\x20|\\x09)+)?(([\\x01-\\x08\\x0b\\x0c\\x0e-\\x1f\\x7f]|\\x21|[\\x23-\\x5b]|[\\x5d-\\x7e]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(\\([\\x01-\\x09\\x0b\\x0c\\x0d-\\x7f]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}]))))*(((\\x20|\\x09)*(\\x0d\\x0a))?(\\x20|\\x09)+)?(\\x22)))@((([a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(([a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])([a-zA-Z]|\\d|-|\\.|_|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*([a-zA-Z]|\\d|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.)+(([a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])|(([a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])([a-zA-Z]|\\d|-|_|~|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])*([a-zA-Z]|[\\x{00A0}-\\x{D7FF}\\x{F900}-\\x{FDCF}\\x{FDF0}-\\x{FFEF}])))\\.?$")
+var localeRegex = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
 var hostnameRegex = regexp.MustCompile(`^(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-zA-Z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][A-Za-z0-9\-]*[A-Za-z0-9])$`)
 
 //Email validates given email address
@@ -21,7 +23,7 @@ func Email(email string) *Result {
 	}
 
 	if !emailRegex.MatchString(email) {
-		return Failed([]string{fmt.Sprintf("'%s' is not a valid email address.", email)})
+		return Failedf("'{email}' is not a valid email address.", i18n.Params{"email": email})
 	}
 
 	return Success()
@@ -34,7 +36,7 @@ func CNAME(tenants storage.Tenant, cname string) *Result {
 	if !env.IsSingleHostMode() {
 		domain := env.MultiTenantDomain()
 		if strings.HasSuffix(cname, domain) || cname == domain[1:] {
-			return Failed([]string{fmt.Sprintf("'%s' is not a valid custom domain.", cname)})
+			return Failedf("'{cname}' is not a valid custom domain.", i18n.Params{"cname": cname})
 		}
 	}
 
@@ -43,7 +45,7 @@ func CNAME(tenants storage.Tenant, cname string) *Result {
 	}
 
 	if !hostnameRegex.MatchString(cname) || strings.Index(cname, ".") == -1 {
-		return Failed([]string{fmt.Sprintf("'%s' is not a valid custom domain.", cname)})
+		return Failedf("'{cname}' is not a valid custom domain.", i18n.Params{"cname": cname})
 	}
 
 	available, err := tenants.IsCNAMEAvailable(cname)
@@ -57,3 +59,50 @@ func CNAME(tenants storage.Tenant, cname string) *Result {
 
 	return Success()
 }
+
+//Domain validates given domain name, e.g. ourcompany.com
+func Domain(domain string) *Result {
+	if len(domain) > 100 {
+		return Failed([]string{"Domain name must have less than 100 characters."})
+	}
+
+	if !hostnameRegex.MatchString(domain) || strings.Index(domain, ".") == -1 {
+		return Failedf("'{domain}' is not a valid domain.", i18n.Params{"domain": domain})
+	}
+
+	return Success()
+}
+
+//Locale validates given locale, which must be a language code optionally followed by a region, e.g. en or pt-BR
+func Locale(locale string) *Result {
+	if !localeRegex.MatchString(locale) {
+		return Failedf("'{locale}' is not a valid locale.", i18n.Params{"locale": locale})
+	}
+
+	return Success()
+}
+
+//URL validates given absolute http or https URL
+func URL(rawurl string) *Result {
+	if len(rawurl) > 300 {
+		return Failed([]string{"URL must be less than 300 characters."})
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Failedf("'{url}' is not a valid URL.", i18n.Params{"url": rawurl})
+	}
+
+	return Success()
+}
+
+//Origin validates given website origin, which is a scheme and host without any path, e.g. https://app.ourcompany.com
+func Origin(origin string) *Result {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || !hostnameRegex.MatchString(u.Hostname()) ||
+		strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		return Failedf("'{origin}' is not a valid website address.", i18n.Params{"origin": origin})
+	}
+
+	return Success()
+}
@@ -1,6 +1,7 @@
 package validate_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/getfider/fider/app/models"
@@ -113,3 +114,136 @@ func TestValidCNAME_Availability(t *testing.T) {
 		Expect(result.Error).IsNil()
 	}
 }
+
+func TestInvalidDomain(t *testing.T) {
+	RegisterT(t)
+
+	for _, domain := range []string{
+		"",
+		"ourcompany",
+		"@ourcompany.com",
+		"ourcompany.com/abc",
+		"-ourcompany.com",
+	} {
+		result := validate.Domain(domain)
+		Expect(result.Ok).IsFalse()
+		Expect(len(result.Messages) > 0).IsTrue()
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestValidDomain(t *testing.T) {
+	RegisterT(t)
+
+	for _, domain := range []string{
+		"ourcompany.com",
+		"mail.ourcompany.co.uk",
+	} {
+		result := validate.Domain(domain)
+		Expect(result.Ok).IsTrue()
+		Expect(result.Messages).HasLen(0)
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestInvalidLocale(t *testing.T) {
+	RegisterT(t)
+
+	for _, locale := range []string{
+		"",
+		"e",
+		"english",
+		"EN",
+		"pt_BR",
+		"pt-br",
+		"pt-BRA",
+	} {
+		result := validate.Locale(locale)
+		Expect(result.Ok).IsFalse()
+		Expect(len(result.Messages) > 0).IsTrue()
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestValidLocale(t *testing.T) {
+	RegisterT(t)
+
+	for _, locale := range []string{
+		"en",
+		"pt-BR",
+		"de",
+	} {
+		result := validate.Locale(locale)
+		Expect(result.Ok).IsTrue()
+		Expect(result.Messages).HasLen(0)
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestInvalidURL(t *testing.T) {
+	RegisterT(t)
+
+	for _, rawurl := range []string{
+		"",
+		"login.example.com/oauth",
+		"/oauth/token",
+		"ftp://example.com/token",
+		"https://",
+		"https://example.com/" + strings.Repeat("a", 300),
+	} {
+		result := validate.URL(rawurl)
+		Expect(result.Ok).IsFalse()
+		Expect(len(result.Messages) > 0).IsTrue()
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestValidURL(t *testing.T) {
+	RegisterT(t)
+
+	for _, rawurl := range []string{
+		"https://login.example.com/oauth/authorize",
+		"http://localhost:8080/token",
+		"https://gitlab.com/api/v4/user?x=1",
+	} {
+		result := validate.URL(rawurl)
+		Expect(result.Ok).IsTrue()
+		Expect(result.Messages).HasLen(0)
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestInvalidOrigin(t *testing.T) {
+	RegisterT(t)
+
+	for _, origin := range []string{
+		"",
+		"example.com",
+		"ftp://example.com",
+		"https://*",
+		"https://*.example.com",
+		"https://user@example.com",
+		"https://example.com/feedback",
+		"https://example.com?q=1",
+	} {
+		result := validate.Origin(origin)
+		Expect(result.Ok).IsFalse()
+		Expect(len(result.Messages) > 0).IsTrue()
+		Expect(result.Error).IsNil()
+	}
+}
+
+func TestValidOrigin(t *testing.T) {
+	RegisterT(t)
+
+	for _, origin := range []string{
+		"https://www.example.com",
+		"https://www.example.com/",
+		"http://localhost:3000",
+	} {
+		result := validate.Origin(origin)
+		Expect(result.Ok).IsTrue()
+		Expect(result.Messages).HasLen(0)
+		Expect(result.Error).IsNil()
+	}
+}
@@ -1,11 +1,11 @@
 package validate
 
 import (
-	"fmt"
 	"regexp"
 
 	"strings"
 
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/storage"
 )
 
@@ -32,7 +32,7 @@ func Subdomain(tenants storage.Tenant, subdomain string) *Result {
 		"signup", "fider", "login", "customers", "admin", "setup", "about",
 		"wecanhearyou", "dev", "mail", "billing", "www", "web", "translate",
 		"help", "support", "status", "staging":
-		return Failed([]string{fmt.Sprintf("%s is a reserved subdomain.", subdomain)})
+		return Failedf("{subdomain} is a reserved subdomain.", i18n.Params{"subdomain": subdomain})
 	}
 
 	available, err := tenants.IsSubdomainAvailable(subdomain)
@@ -3,6 +3,7 @@ package validate
 import (
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/i18n"
 )
 
 // Validatable defines which models can be validated against context
@@ -16,26 +17,67 @@ type Result struct {
 	Ok         bool
 	Authorized bool
 	Error      error
-	Messages   []string
-	Failures   map[string][]string
+	Messages   []i18n.Message
+	Failures   map[string][]i18n.Message
 }
 
 //AddFieldFailure add failure message to specific field
 func (r *Result) AddFieldFailure(field string, messages ...string) {
+	converted := make([]i18n.Message, len(messages))
+	for i, message := range messages {
+		converted[i] = i18n.NewMessage(message)
+	}
+	r.AddFieldMessages(field, converted...)
+}
+
+//AddFieldFailuref add failure message with placeholders to specific field, e.g. "Line {line} is invalid."
+func (r *Result) AddFieldFailuref(field string, message string, params i18n.Params) {
+	r.AddFieldMessages(field, i18n.NewMessage(message, params))
+}
+
+//AddFailuref add a failure message with placeholders that isn't specific to any field
+func (r *Result) AddFailuref(message string, params i18n.Params) {
+	r.Messages = append(r.Messages, i18n.NewMessage(message, params))
+	r.Ok = false
+}
+
+//AddFieldMessages add messages of another result to specific field
+func (r *Result) AddFieldMessages(field string, messages ...i18n.Message) {
 	if r.Failures == nil {
-		r.Failures = make(map[string][]string)
+		r.Failures = make(map[string][]i18n.Message)
 	}
 
 	if r.Failures[field] == nil {
-		r.Failures[field] = []string{}
+		r.Failures[field] = []i18n.Message{}
 	}
 
-	for _, message := range messages {
-		r.Failures[field] = append(r.Failures[field], message)
-	}
+	r.Failures[field] = append(r.Failures[field], messages...)
 	r.Ok = false
 }
 
+//Translate returns messages and failures in given locale
+func (r *Result) Translate(locale string) ([]string, map[string][]string) {
+	var messages []string
+	if r.Messages != nil {
+		messages = make([]string, len(r.Messages))
+		for i, message := range r.Messages {
+			messages[i] = message.Translate(locale)
+		}
+	}
+
+	var failures map[string][]string
+	if r.Failures != nil {
+		failures = make(map[string][]string, len(r.Failures))
+		for field, fieldMessages := range r.Failures {
+			failures[field] = make([]string, len(fieldMessages))
+			for i, message := range fieldMessages {
+				failures[field][i] = message.Translate(locale)
+			}
+		}
+	}
+	return messages, failures
+}
+
 // Success returns a successful validation
 func Success() *Result {
 	return &Result{Ok: true, Authorized: true}
@@ -43,7 +85,16 @@ func Success() *Result {
 
 // Failed returns a failed validation result
 func Failed(messages []string) *Result {
-	return &Result{Ok: false, Authorized: true, Messages: messages}
+	result := &Result{Ok: false, Authorized: true, Messages: make([]i18n.Message, len(messages))}
+	for i, message := range messages {
+		result.Messages[i] = i18n.NewMessage(message)
+	}
+	return result
+}
+
+// Failedf returns a failed validation result with a message that has placeholders
+func Failedf(message string, params i18n.Params) *Result {
+	return &Result{Ok: false, Authorized: true, Messages: []i18n.Message{i18n.NewMessage(message, params)}}
 }
 
 // Error returns a failed validation result
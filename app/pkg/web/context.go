@@ -17,6 +17,7 @@ import (
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/jwt"
 	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/validate"
@@ -33,7 +34,10 @@ type StringMap map[string]string
 type Props struct {
 	Title       string
 	Description string
-	Data        Map
+	//TitleKey and DescriptionKey are messages translated to the locale of the request, used when Title and Description are empty
+	TitleKey       string
+	DescriptionKey string
+	Data           Map
 }
 
 // HTMLMimeType is the mimetype for HTML responses
@@ -49,10 +53,24 @@ var (
 // CookieAuthName is the name of the authentication cookie
 const CookieAuthName = "auth"
 
+// CookieTwoFactorName is the name of the cookie of a sign in waiting for a second factor
+const CookieTwoFactorName = "auth_2fa"
+
+// CookieSAMLRequestName is the name of the cookie with the ID of the last request sent to a SAML IdP
+const CookieSAMLRequestName = "saml_request"
+
+// CookieOperatorName is the name of the cookie of an operator signed in on the super-admin console
+const CookieOperatorName = "operator"
+
+// CSRFHeaderName is the header that state-changing requests use to send the CSRF token of current session
+const CSRFHeaderName = "X-CSRF-Token"
+
 var (
 	preffixKey             = "__CTX_"
 	tenantContextKey       = preffixKey + "TENANT"
 	userContextKey         = preffixKey + "USER"
+	sessionContextKey      = preffixKey + "SESSION"
+	operatorContextKey     = preffixKey + "OPERATOR"
 	authEndpointContextKey = preffixKey + "AUTH_ENDPOINT"
 	transactionContextKey  = preffixKey + "TRANSACTION"
 	servicesContextKey     = preffixKey + "SERVICES"
@@ -183,24 +201,24 @@ func (ctx *Context) Unauthorized() error {
 		return ctx.JSON(http.StatusForbidden, Map{})
 	}
 	return ctx.Render(http.StatusForbidden, "403.html", Props{
-		Title:       "Not Authorized",
-		Description: "You are not authorized to view this page.",
+		TitleKey:       "Not Authorized",
+		DescriptionKey: "You are not authorized to view this page.",
 	})
 }
 
 //NotFound returns a 404 page
 func (ctx *Context) NotFound() error {
 	return ctx.Render(http.StatusNotFound, "404.html", Props{
-		Title:       "Page not found",
-		Description: "The link you clicked may be broken or the page may have been removed.",
+		TitleKey:       "Page not found",
+		DescriptionKey: "The link you clicked may be broken or the page may have been removed.",
 	})
 }
 
 //Gone returns a 410 page
 func (ctx *Context) Gone() error {
 	return ctx.Render(http.StatusGone, "410.html", Props{
-		Title:       "Expired",
-		Description: "The link you clicked has expired.",
+		TitleKey:       "Expired",
+		DescriptionKey: "The link you clicked has expired.",
 	})
 }
 
@@ -225,8 +243,8 @@ func (ctx *Context) Failure(err error) error {
 	message := fmt.Sprintf("URL: %s\nTenant: %s\nUser: %s\n%s", url, tenant, user, err.Error())
 	ctx.Logger().Errorf(log.Red(message))
 	ctx.Render(http.StatusInternalServerError, "500.html", Props{
-		Title:       "Shoot! Well, this is unexpected…",
-		Description: "An error has occurred and we're working to fix the problem!",
+		TitleKey:       "Shoot! Well, this is unexpected…",
+		DescriptionKey: "An error has occurred and we're working to fix the problem!",
 	})
 	return err
 }
@@ -241,9 +259,10 @@ func (ctx *Context) HandleValidation(result *validate.Result) error {
 		return ctx.Unauthorized()
 	}
 
+	messages, failures := result.Translate(ctx.Locale())
 	return ctx.BadRequest(Map{
-		"messages": result.Messages,
-		"failures": result.Failures,
+		"messages": messages,
+		"failures": failures,
 	})
 }
 
@@ -283,6 +302,21 @@ func (ctx *Context) AddParam(name, value string) {
 	ctx.params[name] = value
 }
 
+//Locale returns the locale used to translate responses
+//Users can override the locale of their tenant, and Accept-Language is used when neither is known
+func (ctx *Context) Locale() string {
+	candidates := make([]string, 0)
+	if user := ctx.User(); user != nil && user.Locale != "" {
+		candidates = append(candidates, user.Locale)
+	}
+	if tenant := ctx.Tenant(); tenant != nil && tenant.Locale != "" {
+		candidates = append(candidates, tenant.Locale)
+	} else {
+		candidates = append(candidates, i18n.FromAcceptLanguage(ctx.Request.Header.Get("Accept-Language"))...)
+	}
+	return i18n.Resolve(candidates...)
+}
+
 //User returns authenticated user
 func (ctx *Context) User() *models.User {
 	user, ok := ctx.Get(userContextKey).(*models.User)
@@ -308,14 +342,53 @@ func (ctx *Context) Services() *app.Services {
 	return ctx.Get(servicesContextKey).(*app.Services)
 }
 
-//AddAuthCookie generates and adds a cookie
-func (ctx *Context) AddAuthCookie(user *models.User) (string, error) {
+//Session returns the session of current user
+func (ctx *Context) Session() *models.Session {
+	session, ok := ctx.Get(sessionContextKey).(*models.Session)
+	if ok {
+		return session
+	}
+	return nil
+}
+
+//Operator returns the email of the operator signed in on the super-admin console
+func (ctx *Context) Operator() string {
+	operator, _ := ctx.Get(operatorContextKey).(string)
+	return operator
+}
+
+//SetOperator update HTTP context with the operator signed in on the super-admin console
+func (ctx *Context) SetOperator(email string) {
+	ctx.Set(operatorContextKey, email)
+}
+
+//SetSession update HTTP context with the session of current user
+func (ctx *Context) SetSession(session *models.Session) {
+	ctx.Set(sessionContextKey, session)
+}
+
+//NewAuthToken starts a new session for given user and returns its token
+func (ctx *Context) NewAuthToken(user *models.User) (string, error) {
+	session, err := ctx.Services().Sessions.Create(user, ctx.Request.UserAgent(), ctx.ClientIP(), time.Now().Add(365*24*time.Hour))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create session")
+	}
+
 	token, err := jwt.Encode(models.FiderClaims{
 		UserID:    user.ID,
 		UserName:  user.Name,
 		UserEmail: user.Email,
+		SessionID: session.ID,
 	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode auth token")
+	}
+	return token, nil
+}
 
+//AddAuthCookie generates and adds a cookie
+func (ctx *Context) AddAuthCookie(user *models.User) (string, error) {
+	token, err := ctx.NewAuthToken(user)
 	if err != nil {
 		return token, errors.Wrap(err, "failed to add auth cookie")
 	}
@@ -324,15 +397,62 @@ func (ctx *Context) AddAuthCookie(user *models.User) (string, error) {
 	return token, nil
 }
 
+//ClientIP returns the address of the client, which is used to help users recognize their sessions, in audit logs and to throttle operator sign in.
+//X-Forwarded-For can be set by anyone, so it's only used when the request comes from one of the TRUSTED_PROXIES,
+//in which case the client is the last address of the header that isn't a trusted proxy itself
+func (ctx *Context) ClientIP() string {
+	ip, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
+	if err != nil {
+		ip = ctx.Request.RemoteAddr
+	}
+
+	proxies := env.TrustedProxies()
+	if !isTrustedProxy(ip, proxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(ctx.Request.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(forwarded[i])
+		if address == "" {
+			continue
+		}
+		ip = address
+		if !isTrustedProxy(address, proxies) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(address string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+//AllowFraming replaces the frame-ancestors of current response so that it can be embedded by given origins
+func (ctx *Context) AllowFraming(origins []string) {
+	ancestors := strings.Join(append([]string{"'self'"}, origins...), " ")
+	ctx.Response.Header().Set("Content-Security-Policy", fmt.Sprintf(CspPolicyTemplate, ctx.ContextID(), ancestors))
+}
+
 //AddCookie adds a cookie
 func (ctx *Context) AddCookie(name, value string, expires time.Time) {
-	ctx.SetCookie(&http.Cookie{
+	ctx.SetSameSiteCookie(&http.Cookie{
 		Name:     name,
 		Value:    value,
 		HttpOnly: true,
 		Path:     "/",
 		Expires:  expires,
-	})
+	}, "Lax")
 }
 
 //RemoveCookie removes a cookie
@@ -490,6 +610,14 @@ func (ctx *Context) SetCookie(cookie *http.Cookie) {
 	http.SetCookie(ctx.Response, cookie)
 }
 
+//SetSameSiteCookie adds a cookie with given SameSite attribute, e.g. Lax or None.
+//http.Cookie has no field for it on the Go version Fider is built with, so the attribute is appended to the header
+func (ctx *Context) SetSameSiteCookie(cookie *http.Cookie, sameSite string) {
+	if value := cookie.String(); value != "" {
+		ctx.Response.Header().Add("Set-Cookie", value+"; SameSite="+sameSite)
+	}
+}
+
 // NoContent sends a response with no body and a status code.
 func (ctx *Context) NoContent(code int) error {
 	ctx.Response.WriteHeader(code)
@@ -502,3 +630,10 @@ func (ctx *Context) Redirect(url string) error {
 	ctx.Response.WriteHeader(http.StatusTemporaryRedirect)
 	return nil
 }
+
+// PermanentRedirect the request to a provided URL, which clients and search engines should use from now on
+func (ctx *Context) PermanentRedirect(url string) error {
+	ctx.Response.Header().Set("Location", url)
+	ctx.Response.WriteHeader(http.StatusMovedPermanently)
+	return nil
+}
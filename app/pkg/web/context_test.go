@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/getfider/fider/app/models"
 	. "github.com/getfider/fider/app/pkg/assert"
@@ -88,3 +89,44 @@ func TestTenantURL_SingleTenantMode(t *testing.T) {
 	}
 	Expect(ctx.TenantBaseURL(tenant)).Equals("http://demo.test.fider.io:3000")
 }
+
+func TestClientIP(t *testing.T) {
+	RegisterT(t)
+	os.Setenv("TRUSTED_PROXIES", "10.1.1.1, 172.16.0.0/12")
+	defer os.Setenv("TRUSTED_PROXIES", "")
+
+	var testCases = []struct {
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{"203.0.113.9:5000", "", "203.0.113.9"},
+		{"203.0.113.9:5000", "198.51.100.7", "203.0.113.9"},
+		{"10.1.1.1:4000", "", "10.1.1.1"},
+		{"10.1.1.1:4000", "198.51.100.7", "198.51.100.7"},
+		{"10.1.1.1:4000", "1.2.3.4, 198.51.100.7", "198.51.100.7"},
+		{"10.1.1.1:4000", "1.2.3.4, 198.51.100.7, 172.16.5.4", "198.51.100.7"},
+		{"10.1.1.2:4000", "198.51.100.7", "10.1.1.2"},
+	}
+
+	for _, testCase := range testCases {
+		ctx := newGetContext(nil)
+		ctx.Request.RemoteAddr = testCase.remoteAddr
+		if testCase.forwarded != "" {
+			ctx.Request.Header.Set("X-Forwarded-For", testCase.forwarded)
+		}
+		Expect(ctx.ClientIP()).Equals(testCase.expected)
+	}
+}
+
+func TestAddCookie(t *testing.T) {
+	RegisterT(t)
+
+	ctx := newGetContext(nil)
+	ctx.AddCookie("auth", "some-token", time.Now().Add(1*time.Hour))
+
+	cookie := ctx.Response.Header().Get("Set-Cookie")
+	Expect(cookie).ContainsSubstring("auth=some-token")
+	Expect(cookie).ContainsSubstring("; HttpOnly")
+	Expect(cookie).ContainsSubstring("; SameSite=Lax")
+}
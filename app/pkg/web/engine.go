@@ -19,6 +19,7 @@ import (
 	"github.com/getfider/fider/app/pkg/uuid"
 	"github.com/getfider/fider/app/pkg/worker"
 	"github.com/julienschmidt/httprouter"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 var (
@@ -31,9 +32,10 @@ var (
 	cspObject  = "object-src 'none'"
 	cspMedia   = "media-src 'none'"
 	cspConnect = "connect-src 'self' https://www.google-analytics.com"
+	cspFrame   = "frame-ancestors %[2]s"
 
-	//CspPolicyTemplate is the template used to generate the policy
-	CspPolicyTemplate = fmt.Sprintf("%s; %s; %s; %s; %s; %s; %s; %s; %s", cspBase, cspDefault, cspStyle, cspScript, cspImage, cspFont, cspObject, cspMedia, cspConnect)
+	//CspPolicyTemplate is the template used to generate the policy, given a nonce and the sources allowed to frame the page
+	CspPolicyTemplate = fmt.Sprintf("%s; %s; %s; %s; %s; %s; %s; %s; %s; %s", cspBase, cspDefault, cspStyle, cspScript, cspImage, cspFont, cspObject, cspMedia, cspConnect, cspFrame)
 )
 
 type notFoundHandler struct {
@@ -60,6 +62,8 @@ type Engine struct {
 	middlewares []MiddlewareFunc
 	worker      worker.Worker
 	server      *http.Server
+	certCache   autocert.Cache
+	hostPolicy  autocert.HostPolicy
 }
 
 //New creates a new Engine
@@ -102,7 +106,12 @@ func (e *Engine) Start(address string) {
 		certManager *CertificateManager
 	)
 	if autoSSL == "true" {
-		certManager, err = NewCertificateManager(certFile, keyFile, "certs")
+		certCache := e.certCache
+		if certCache == nil {
+			certCache = autocert.DirCache("certs")
+		}
+
+		certManager, err = NewCertificateManager(certFile, keyFile, certCache, e.hostPolicy)
 		if err != nil {
 			panic(errors.Wrap(err, "failed to initialize CertificateManager"))
 		}
@@ -127,6 +136,12 @@ func (e *Engine) Start(address string) {
 	}
 }
 
+//UseAutoSSL sets where certificates issued by Let's Encrypt are cached and which hostnames can have one
+func (e *Engine) UseAutoSSL(cache autocert.Cache, policy autocert.HostPolicy) {
+	e.certCache = cache
+	e.hostPolicy = policy
+}
+
 //Stop the server.
 func (e *Engine) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
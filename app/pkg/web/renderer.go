@@ -11,6 +11,7 @@ import (
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/pkg/i18n"
 	"github.com/getfider/fider/app/pkg/log"
 	"github.com/getfider/fider/app/pkg/oauth"
 )
@@ -34,6 +35,8 @@ func NewRenderer(settings *models.SystemSettings, logger log.Logger) *Renderer {
 
 	r.add("index.html")
 	r.add("not-invited.html")
+	r.add("limit-reached.html")
+	r.add("suspended.html")
 	r.add("403.html")
 	r.add("404.html")
 	r.add("410.html")
@@ -72,6 +75,25 @@ func (r *Renderer) getBundle(folder string) string {
 	panic(fmt.Sprintf("Bundle not found: %s.", folder))
 }
 
+//themeStyle returns the stylesheet that applies given theme on top of the default one
+//Colors are validated and custom CSS is sanitized before they are saved
+func themeStyle(baseURL string, theme models.TenantTheme) template.CSS {
+	var style strings.Builder
+	if theme.PrimaryColor != "" {
+		fmt.Fprintf(&style, ".gm-primary, .gm-primary-hover:hover { color: #%s; }\n", theme.PrimaryColor)
+		fmt.Fprintf(&style, ".ui.primary.button, .ui.primary.button:hover { background-color: #%s; }\n", theme.PrimaryColor)
+	}
+	if theme.AccentColor != "" {
+		fmt.Fprintf(&style, "#c-header .ui.borderless.menu { border-bottom: 3px solid #%s; }\n", theme.AccentColor)
+		fmt.Fprintf(&style, ".ui.positive.button, .ui.positive.button:hover { background-color: #%s; }\n", theme.AccentColor)
+	}
+	if theme.HeaderID > 0 {
+		fmt.Fprintf(&style, "#c-header .ui.borderless.menu { background: url('%s/header/%d') center / cover no-repeat; }\n", baseURL, theme.HeaderID)
+	}
+	style.WriteString(theme.CustomCSS)
+	return template.CSS(style.String())
+}
+
 //Render a template based on parameters
 func (r *Renderer) Render(w io.Writer, name string, props Props, ctx *Context) error {
 	tmpl, ok := r.templates[name]
@@ -95,15 +117,28 @@ func (r *Renderer) Render(w io.Writer, name string, props Props, ctx *Context) e
 		tenantName = ctx.Tenant().Name
 	}
 
+	//Only message keys are translated, titles and descriptions are user content and kept as is
+	locale := ctx.Locale()
+	pageTitle := props.Title
+	if pageTitle == "" && props.TitleKey != "" {
+		pageTitle = i18n.T(locale, props.TitleKey)
+	}
+
 	title := tenantName
-	if props.Title != "" {
-		title = fmt.Sprintf("%s · %s", props.Title, tenantName)
+	if pageTitle != "" {
+		title = fmt.Sprintf("%s · %s", pageTitle, tenantName)
 	}
 
 	m["__Title"] = title
+	m["__Locale"] = locale
 
-	if props.Description != "" {
-		description := strings.Replace(props.Description, "\n", " ", -1)
+	description := props.Description
+	if description == "" && props.DescriptionKey != "" {
+		description = i18n.T(locale, props.DescriptionKey)
+	}
+
+	if description != "" {
+		description = strings.Replace(description, "\n", " ", -1)
 		m["__Description"] = fmt.Sprintf("%.150s", description)
 	}
 
@@ -118,10 +153,56 @@ func (r *Renderer) Render(w io.Writer, name string, props Props, ctx *Context) e
 		m["__favicon"] = "/favicon.ico"
 	}
 
+	if ctx.Tenant() != nil {
+		if ctx.Tenant().Theme.FaviconID > 0 {
+			m["__favicon"] = fmt.Sprintf("%s/favicon/50/%d", ctx.BaseURL(), ctx.Tenant().Theme.FaviconID)
+		}
+		m["__theme"] = themeStyle(ctx.BaseURL(), ctx.Tenant().Theme)
+	}
+
 	m["system"] = r.settings
 	m["baseURL"] = ctx.BaseURL()
 	m["currentURL"] = ctx.CurrentURL()
 	m["tenant"] = ctx.Tenant()
+
+	customProviders := make([]Map, 0)
+	sso := Map{"enabled": false, "enforced": false}
+	if ctx.Tenant() != nil {
+		sso["enabled"] = ctx.Tenant().SAML.IsEnabled
+		sso["enforced"] = ctx.Tenant().SAML.IsSSOEnforced()
+
+		configs, err := ctx.Services().Tenants.ListOAuthConfig()
+		if err != nil {
+			return errors.Wrap(err, "failed to get custom oauth providers")
+		}
+		for _, config := range configs {
+			if config.IsEnabled() {
+				customProviders = append(customProviders, Map{
+					"provider":    config.Provider,
+					"displayName": config.DisplayName,
+				})
+			}
+		}
+	}
+
+	navigation := make([]Map, 0)
+	if ctx.Tenant() != nil && (!ctx.Tenant().IsPrivate || ctx.IsAuthenticated()) {
+		pages, err := ctx.Services().Pages.GetNavigation()
+		if err != nil {
+			return errors.Wrap(err, "failed to get navigation pages")
+		}
+		for _, page := range pages {
+			if page.IsVisibleTo(ctx.User()) {
+				navigation = append(navigation, Map{
+					"slug":      page.Slug,
+					"title":     page.Title,
+					"placement": page.Placement,
+				})
+			}
+		}
+	}
+	m["navigation"] = navigation
+
 	m["auth"] = Map{
 		"endpoint": ctx.AuthEndpoint(),
 		"providers": Map{
@@ -129,6 +210,12 @@ func (r *Renderer) Render(w io.Writer, name string, props Props, ctx *Context) e
 			oauth.FacebookProvider: oauth.IsProviderEnabled(oauth.FacebookProvider),
 			oauth.GitHubProvider:   oauth.IsProviderEnabled(oauth.GitHubProvider),
 		},
+		"customProviders": customProviders,
+		"sso":             sso,
+	}
+
+	if session := ctx.Session(); session != nil {
+		m["csrfToken"] = session.CSRFToken
 	}
 
 	if ctx.IsAuthenticated() {
@@ -138,8 +225,9 @@ func (r *Renderer) Render(w io.Writer, name string, props Props, ctx *Context) e
 			"name":            u.Name,
 			"email":           u.Email,
 			"role":            u.Role,
+			"locale":          u.Locale,
 			"isAdministrator": u.IsAdministrator(),
-			"isCollaborator":  u.IsCollaborator(),
+			"permissions":     u.Permissions(),
 		}
 	}
 
@@ -1,11 +1,15 @@
 package web
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
 	"golang.org/x/crypto/acme/autocert"
 )
@@ -18,11 +22,13 @@ type CertificateManager struct {
 }
 
 //NewCertificateManager creates a new CertificateManager
-func NewCertificateManager(certFile, keyFile, cacheDir string) (*CertificateManager, error) {
+//Certificates are only requested for hostnames accepted by policy, or any hostname when policy is nil
+func NewCertificateManager(certFile, keyFile string, cache autocert.Cache, policy autocert.HostPolicy) (*CertificateManager, error) {
 	manager := &CertificateManager{
 		autossl: autocert.Manager{
-			Prompt: autocert.AcceptTOS,
-			Cache:  autocert.DirCache(cacheDir),
+			Prompt:     autocert.AcceptTOS,
+			Cache:      cache,
+			HostPolicy: policy,
 		},
 	}
 
@@ -60,3 +66,23 @@ func (m *CertificateManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Ce
 func (m *CertificateManager) StartHTTPServer() {
 	http.ListenAndServe(":80", m.autossl.HTTPHandler(nil))
 }
+
+//NewHostPolicy creates an autocert.HostPolicy that allows the auth endpoint and every hostname accepted by isKnownHost
+//It prevents anyone from triggering certificate orders by pointing random hostnames to this server
+func NewHostPolicy(isKnownHost func(host string) (bool, error)) autocert.HostPolicy {
+	return func(ctx context.Context, host string) error {
+		if endpoint, err := url.Parse(env.GetEnvOrDefault("AUTH_ENDPOINT", "")); err == nil && endpoint.Hostname() == host {
+			return nil
+		}
+
+		known, err := isKnownHost(host)
+		if err != nil {
+			return errors.Wrap(err, "failed to check if '%s' is a known host", host)
+		}
+
+		if !known {
+			return errors.New(fmt.Sprintf("'%s' is not a known host", host))
+		}
+		return nil
+	}
+}
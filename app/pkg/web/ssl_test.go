@@ -0,0 +1,35 @@
+package web_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/web"
+)
+
+func TestHostPolicy(t *testing.T) {
+	RegisterT(t)
+
+	policy := web.NewHostPolicy(func(host string) (bool, error) {
+		return host == "demo.test.fider.io" || host == "feedback.demo.com", nil
+	})
+
+	Expect(policy(context.Background(), "login.test.fider.io")).IsNil()
+	Expect(policy(context.Background(), "demo.test.fider.io")).IsNil()
+	Expect(policy(context.Background(), "feedback.demo.com")).IsNil()
+	Expect(policy(context.Background(), "unknown.test.fider.io")).IsNotNil()
+	Expect(policy(context.Background(), "evil.com")).IsNotNil()
+}
+
+func TestHostPolicy_Failure(t *testing.T) {
+	RegisterT(t)
+
+	policy := web.NewHostPolicy(func(host string) (bool, error) {
+		return false, errors.New("connection refused")
+	})
+
+	Expect(policy(context.Background(), "login.test.fider.io")).IsNil()
+	Expect(policy(context.Background(), "demo.test.fider.io")).IsNotNil()
+}
@@ -18,6 +18,8 @@ type Context struct {
 	baseURL  string
 	user     *models.User
 	tenant   *models.Tenant
+
+	afterCommit []func()
 }
 
 //NewContext creates a new context
@@ -86,6 +88,19 @@ func (c *Context) Logger() log.Logger {
 	return c.logger
 }
 
+//AfterCommit registers given function to run only once the changes of current task have been committed
+func (c *Context) AfterCommit(fn func()) {
+	c.afterCommit = append(c.afterCommit, fn)
+}
+
+//Committed runs every function registered with AfterCommit, it's called after changes have been committed
+func (c *Context) Committed() {
+	for _, fn := range c.afterCommit {
+		fn()
+	}
+	c.afterCommit = nil
+}
+
 //Failure logs details of error
 func (c *Context) Failure(err error) error {
 	err = errors.StackN(err, 1)
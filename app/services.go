@@ -3,7 +3,9 @@ package app
 import (
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/email"
-	"github.com/getfider/fider/app/pkg/email/mailgun"
+	_ "github.com/getfider/fider/app/pkg/email/httpapi"
+	_ "github.com/getfider/fider/app/pkg/email/mailgun"
+	_ "github.com/getfider/fider/app/pkg/email/ses"
 	"github.com/getfider/fider/app/pkg/email/smtp"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/log"
@@ -13,13 +15,17 @@ import (
 
 // Services holds reference to all Fider services
 type Services struct {
-	OAuth         oauth.Service
-	Users         storage.User
-	Tags          storage.Tag
-	Tenants       storage.Tenant
-	Notifications storage.Notification
-	Ideas         storage.Idea
-	Emailer       email.Sender
+	OAuth          oauth.Service
+	Users          storage.User
+	Tags           storage.Tag
+	Tenants        storage.Tenant
+	Notifications  storage.Notification
+	Ideas          storage.Idea
+	EmailTemplates storage.EmailTemplate
+	Pages          storage.Page
+	EmailLog       storage.EmailLog
+	Sessions       storage.Session
+	Emailer        email.Sender
 }
 
 // SetCurrentTenant to current context
@@ -29,6 +35,10 @@ func (s *Services) SetCurrentTenant(tenant *models.Tenant) {
 	s.Tenants.SetCurrentTenant(tenant)
 	s.Ideas.SetCurrentTenant(tenant)
 	s.Notifications.SetCurrentTenant(tenant)
+	s.EmailTemplates.SetCurrentTenant(tenant)
+	s.Pages.SetCurrentTenant(tenant)
+	s.EmailLog.SetCurrentTenant(tenant)
+	s.Sessions.SetCurrentTenant(tenant)
 }
 
 // SetCurrentUser to current context
@@ -38,6 +48,10 @@ func (s *Services) SetCurrentUser(user *models.User) {
 	s.Tenants.SetCurrentUser(user)
 	s.Ideas.SetCurrentUser(user)
 	s.Notifications.SetCurrentUser(user)
+	s.EmailTemplates.SetCurrentUser(user)
+	s.Pages.SetCurrentUser(user)
+	s.EmailLog.SetCurrentUser(user)
+	s.Sessions.SetCurrentUser(user)
 }
 
 //NewEmailer creates a new emailer based on system configuration
@@ -45,14 +59,24 @@ func NewEmailer(logger log.Logger) email.Sender {
 	if env.IsTest() {
 		return email.NewNoopSender()
 	}
+
+	sender, err := email.NewSender(EmailProvider(), logger)
+	if err != nil {
+		panic(err)
+	}
+
+	// Tenants with their own SMTP server bypass the configured provider
+	return smtp.NewTenantSender(logger, sender, env.AllowPrivateSMTPHosts())
+}
+
+//EmailProvider returns the name of the configured email provider
+//Mailgun is used when its API key is defined and SMTP is the fallback when no provider is set
+func EmailProvider() string {
+	if env.IsDefined("EMAIL_PROVIDER") {
+		return env.MustGet("EMAIL_PROVIDER")
+	}
 	if env.IsDefined("EMAIL_MAILGUN_API") {
-		return mailgun.NewSender(logger, env.MustGet("EMAIL_MAILGUN_DOMAIN"), env.MustGet("EMAIL_MAILGUN_API"))
+		return "mailgun"
 	}
-	return smtp.NewSender(
-		logger,
-		env.MustGet("EMAIL_SMTP_HOST"),
-		env.MustGet("EMAIL_SMTP_PORT"),
-		env.GetEnvOrDefault("EMAIL_SMTP_USERNAME", ""),
-		env.GetEnvOrDefault("EMAIL_SMTP_PASSWORD", ""),
-	)
+	return "smtp"
 }
@@ -0,0 +1,98 @@
+package inmemory
+
+import (
+	"strings"
+	"time"
+
+	"github.com/getfider/fider/app/models"
+)
+
+// EmailLogStorage contains read and write operations for email deliveries and suppressed addresses
+type EmailLogStorage struct {
+	lastID       int
+	deliveries   map[int][]*models.EmailDelivery
+	suppressions map[int][]*models.EmailSuppression
+	tenant       *models.Tenant
+	user         *models.User
+}
+
+// NewEmailLogStorage creates a new EmailLogStorage
+func NewEmailLogStorage() *EmailLogStorage {
+	return &EmailLogStorage{
+		deliveries:   make(map[int][]*models.EmailDelivery),
+		suppressions: make(map[int][]*models.EmailSuppression),
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *EmailLogStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *EmailLogStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// Add records an email delivery for current tenant
+func (s *EmailLogStorage) Add(delivery *models.EmailDelivery) error {
+	s.lastID = s.lastID + 1
+	delivery.ID = s.lastID
+	if delivery.CreatedOn.IsZero() {
+		delivery.CreatedOn = time.Now()
+	}
+	s.deliveries[s.tenant.ID] = append([]*models.EmailDelivery{delivery}, s.deliveries[s.tenant.ID]...)
+	return nil
+}
+
+// GetRecent returns the most recent email deliveries of current tenant
+func (s *EmailLogStorage) GetRecent(limit int) ([]*models.EmailDelivery, error) {
+	deliveries := s.deliveries[s.tenant.ID]
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// GetSuppressions returns all suppressed addresses of current tenant
+func (s *EmailLogStorage) GetSuppressions() ([]*models.EmailSuppression, error) {
+	return s.suppressions[s.tenant.ID], nil
+}
+
+// IsSuppressed returns true if given address is on the suppression list of current tenant
+func (s *EmailLogStorage) IsSuppressed(address string) (bool, error) {
+	for _, suppression := range s.suppressions[s.tenant.ID] {
+		if strings.EqualFold(suppression.Address, address) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Suppress adds given address to the suppression list of current tenant
+func (s *EmailLogStorage) Suppress(address, reason string) error {
+	for _, suppression := range s.suppressions[s.tenant.ID] {
+		if strings.EqualFold(suppression.Address, address) {
+			suppression.Reason = reason
+			return nil
+		}
+	}
+	s.suppressions[s.tenant.ID] = append(s.suppressions[s.tenant.ID], &models.EmailSuppression{
+		Address:   address,
+		Reason:    reason,
+		CreatedOn: time.Now(),
+	})
+	return nil
+}
+
+// Unsuppress removes given address from the suppression list of current tenant
+func (s *EmailLogStorage) Unsuppress(address string) error {
+	suppressions := s.suppressions[s.tenant.ID]
+	for i, suppression := range suppressions {
+		if strings.EqualFold(suppression.Address, address) {
+			s.suppressions[s.tenant.ID] = append(suppressions[:i], suppressions[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package inmemory
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+)
+
+// EmailTemplateStorage contains read and write operations for customized email templates
+type EmailTemplateStorage struct {
+	lastID    int
+	templates map[int][]*models.EmailTemplate
+	tenant    *models.Tenant
+	user      *models.User
+}
+
+// NewEmailTemplateStorage creates a new EmailTemplateStorage
+func NewEmailTemplateStorage() *EmailTemplateStorage {
+	return &EmailTemplateStorage{
+		templates: make(map[int][]*models.EmailTemplate),
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *EmailTemplateStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *EmailTemplateStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// GetAll returns all customized templates of current tenant
+func (s *EmailTemplateStorage) GetAll() ([]*models.EmailTemplate, error) {
+	return s.templates[s.tenant.ID], nil
+}
+
+// Get returns the customized template with given name and locale
+func (s *EmailTemplateStorage) Get(name, locale string) (*models.EmailTemplate, error) {
+	for _, template := range s.templates[s.tenant.ID] {
+		if template.Name == name && template.Locale == locale {
+			return template, nil
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+// Save creates or replaces the customized template with given name and locale
+func (s *EmailTemplateStorage) Save(input *models.SaveEmailTemplate) (*models.EmailTemplate, error) {
+	template, err := s.Get(input.Name, input.Locale)
+	if err == app.ErrNotFound {
+		s.lastID = s.lastID + 1
+		template = &models.EmailTemplate{ID: s.lastID, Name: input.Name, Locale: input.Locale}
+		s.templates[s.tenant.ID] = append(s.templates[s.tenant.ID], template)
+	}
+	template.Subject = input.Subject
+	template.Body = input.Body
+	template.UpdatedOn = time.Now()
+	return template, nil
+}
+
+// Delete removes the customized template with given name and locale
+func (s *EmailTemplateStorage) Delete(name, locale string) error {
+	templates := s.templates[s.tenant.ID]
+	for i, template := range templates {
+		if template.Name == name && template.Locale == locale {
+			s.templates[s.tenant.ID] = append(templates[:i], templates[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
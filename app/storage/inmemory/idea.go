@@ -59,10 +59,10 @@ func (s *IdeaStorage) Update(idea *models.Idea, title, description string) (*mod
 	return idea, nil
 }
 
-// GetByNumber returns idea by tenant and number
+// GetByNumber returns idea by tenant and number, as long as it's visible to current user
 func (s *IdeaStorage) GetByNumber(number int) (*models.Idea, error) {
 	for _, idea := range s.ideas {
-		if idea.Number == number {
+		if idea.Number == number && idea.IsVisibleTo(s.user) {
 			return idea, nil
 		}
 	}
@@ -81,7 +81,7 @@ func (s *IdeaStorage) GetBySlug(slug string) (*models.Idea, error) {
 
 // GetAll returns all tenant ideas
 func (s *IdeaStorage) GetAll() ([]*models.Idea, error) {
-	return s.ideas, nil
+	return s.visibleIdeas(), nil
 }
 
 // CountPerStatus returns total number of ideas per status
@@ -91,7 +91,17 @@ func (s *IdeaStorage) CountPerStatus() (map[int]int, error) {
 
 // Search existing ideas based on input
 func (s *IdeaStorage) Search(query, filter string, tags []string) ([]*models.Idea, error) {
-	return s.ideas, nil
+	return s.visibleIdeas(), nil
+}
+
+func (s *IdeaStorage) visibleIdeas() []*models.Idea {
+	ideas := make([]*models.Idea, 0)
+	for _, idea := range s.ideas {
+		if idea.IsVisibleTo(s.user) {
+			ideas = append(ideas, idea)
+		}
+	}
+	return ideas
 }
 
 // GetCommentsByIdea returns all comments from given idea
@@ -153,6 +163,19 @@ func (s *IdeaStorage) UpdateComment(id int, content string) error {
 	return nil
 }
 
+// DeleteComment with given ID
+func (s *IdeaStorage) DeleteComment(id int) error {
+	for ideaID, comments := range s.ideaComments {
+		for i, comment := range comments {
+			if comment.ID == id {
+				s.ideaComments[ideaID] = append(comments[:i], comments[i+1:]...)
+				return nil
+			}
+		}
+	}
+	return app.ErrNotFound
+}
+
 // AddSupporter adds user to idea list of supporters
 func (s *IdeaStorage) AddSupporter(idea *models.Idea, user *models.User) error {
 	s.ideasSupportedBy[user.ID] = append(s.ideasSupportedBy[user.ID], idea.ID)
@@ -208,6 +231,12 @@ func (s *IdeaStorage) MarkAsDuplicate(idea *models.Idea, original *models.Idea)
 	return nil
 }
 
+// SetPrivacy changes whether given idea is only visible to its author and staff
+func (s *IdeaStorage) SetPrivacy(idea *models.Idea, isPrivate bool) error {
+	idea.IsPrivate = isPrivate
+	return nil
+}
+
 // IsReferenced returns true if another idea is referencing given idea
 func (s *IdeaStorage) IsReferenced(idea *models.Idea) (bool, error) {
 	for _, i := range s.ideas {
@@ -0,0 +1,105 @@
+package inmemory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+)
+
+// PageStorage contains read and write operations for pages authored by tenants
+type PageStorage struct {
+	lastID int
+	pages  map[int][]*models.Page
+	tenant *models.Tenant
+	user   *models.User
+}
+
+// NewPageStorage creates a new PageStorage
+func NewPageStorage() *PageStorage {
+	return &PageStorage{
+		pages: make(map[int][]*models.Page),
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *PageStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *PageStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// GetAll returns all pages of current tenant
+func (s *PageStorage) GetAll() ([]*models.Page, error) {
+	pages := append([]*models.Page{}, s.pages[s.tenant.ID]...)
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Title < pages[j].Title
+	})
+	return pages, nil
+}
+
+// GetNavigation returns the pages of current tenant that are linked from the navigation, without their content
+func (s *PageStorage) GetNavigation() ([]*models.Page, error) {
+	pages := make([]*models.Page, 0)
+	for _, page := range s.pages[s.tenant.ID] {
+		if page.Placement != models.PagePlacementNone {
+			pages = append(pages, &models.Page{
+				ID:         page.ID,
+				Slug:       page.Slug,
+				Title:      page.Title,
+				Visibility: page.Visibility,
+				Placement:  page.Placement,
+				UpdatedOn:  page.UpdatedOn,
+			})
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Title < pages[j].Title
+	})
+	return pages, nil
+}
+
+// GetBySlug returns the page with given slug
+func (s *PageStorage) GetBySlug(slug string) (*models.Page, error) {
+	for _, page := range s.pages[s.tenant.ID] {
+		if page.Slug == slug {
+			return page, nil
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+// Add creates a new page
+func (s *PageStorage) Add(input *models.SavePage) (*models.Page, error) {
+	s.lastID = s.lastID + 1
+	page := &models.Page{ID: s.lastID}
+	s.pages[s.tenant.ID] = append(s.pages[s.tenant.ID], page)
+	return s.Update(page, input)
+}
+
+// Update given page
+func (s *PageStorage) Update(page *models.Page, input *models.SavePage) (*models.Page, error) {
+	page.Slug = input.Slug
+	page.Title = input.Title
+	page.Content = input.Content
+	page.Visibility = input.Visibility
+	page.Placement = input.Placement
+	page.UpdatedOn = time.Now()
+	return page, nil
+}
+
+// Delete given page
+func (s *PageStorage) Delete(page *models.Page) error {
+	pages := s.pages[s.tenant.ID]
+	for i, p := range pages {
+		if p.ID == page.ID {
+			s.pages[s.tenant.ID] = append(pages[:i], pages[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package inmemory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+)
+
+// SessionStorage contains read and write operations for user sessions
+type SessionStorage struct {
+	sessions map[string]*models.Session
+	tenants  map[string]int
+	revoked  map[string]bool
+	tenant   *models.Tenant
+	user     *models.User
+}
+
+// NewSessionStorage creates a new SessionStorage
+func NewSessionStorage() *SessionStorage {
+	return &SessionStorage{
+		sessions: make(map[string]*models.Session),
+		tenants:  make(map[string]int),
+		revoked:  make(map[string]bool),
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *SessionStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *SessionStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// Create a new session for given user
+func (s *SessionStorage) Create(user *models.User, userAgent, ipAddress string, expiresOn time.Time) (*models.Session, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         models.GenerateVerificationKey(),
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedOn:  now,
+		LastSeenOn: now,
+		ExpiresOn:  expiresOn,
+		CSRFToken:  models.GenerateVerificationKey(),
+	}
+	s.sessions[session.ID] = session
+	s.tenants[session.ID] = s.tenant.ID
+	return session, nil
+}
+
+func (s *SessionStorage) isActive(session *models.Session) bool {
+	return s.tenants[session.ID] == s.tenant.ID && !s.revoked[session.ID] && session.ExpiresOn.After(time.Now())
+}
+
+// GetByID returns an active session of current tenant
+func (s *SessionStorage) GetByID(id string) (*models.Session, error) {
+	session, ok := s.sessions[id]
+	if !ok || !s.isActive(session) {
+		return nil, app.ErrNotFound
+	}
+	return session, nil
+}
+
+// GetActiveByUser returns all active sessions of given user, most recently used first
+func (s *SessionStorage) GetActiveByUser(userID int) ([]*models.Session, error) {
+	result := make([]*models.Session, 0)
+	for _, session := range s.sessions {
+		if session.UserID == userID && s.isActive(session) {
+			result = append(result, session)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeenOn.After(result[j].LastSeenOn)
+	})
+	return result, nil
+}
+
+// Touch marks given session as used now
+func (s *SessionStorage) Touch(id string) error {
+	if session, ok := s.sessions[id]; ok {
+		session.LastSeenOn = time.Now()
+	}
+	return nil
+}
+
+// Revoke given session of given user
+func (s *SessionStorage) Revoke(userID int, id string) error {
+	if session, ok := s.sessions[id]; ok && session.UserID == userID && s.tenants[id] == s.tenant.ID {
+		s.revoked[id] = true
+	}
+	return nil
+}
+
+// RevokeAll sessions of given user
+func (s *SessionStorage) RevokeAll(userID int) error {
+	for id, session := range s.sessions {
+		if session.UserID == userID && s.tenants[id] == s.tenant.ID {
+			s.revoked[id] = true
+		}
+	}
+	return nil
+}
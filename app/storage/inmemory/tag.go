@@ -122,7 +122,7 @@ func (s *TagStorage) GetAssigned(idea *models.Idea) ([]*models.Tag, error) {
 
 // GetAll returns all tags
 func (s *TagStorage) GetAll() ([]*models.Tag, error) {
-	if s.user != nil && s.user.IsCollaborator() {
+	if s.user != nil && s.user.Can(models.PermissionViewPrivate) {
 		return s.tags, nil
 	}
 	tags := make([]*models.Tag, 0)
@@ -12,13 +12,35 @@ import (
 
 // TenantStorage contains read and write operations for tenants
 type TenantStorage struct {
-	lastID        int
-	lastLogoID    int
-	tenants       []*models.Tenant
-	current       *models.Tenant
-	user          *models.User
-	verifications []*models.EmailVerification
-	tenantLogos   map[int]*models.Upload
+	lastID           int
+	lastLogoID       int
+	lastVerifyID     int
+	tenants          []*models.Tenant
+	current          *models.Tenant
+	user             *models.User
+	verifications    []*models.EmailVerification
+	tenantLogos      map[int]*models.Upload
+	oauthConfigs     map[int][]*models.OAuthConfig
+	customRoles      map[int][]*models.CustomRole
+	lastRoleID       int
+	ssoTokens        map[int]map[string]time.Time
+	samlAssertions   map[int]map[string]time.Time
+	operatorAudit    []*models.OperatorAuditEntry
+	operatorFailures []*operatorSignInFailure
+	aliases          []*tenantAlias
+	lastAliasID      int
+}
+
+type tenantAlias struct {
+	models.TenantAlias
+	tenantID  int
+	removedOn *time.Time
+}
+
+type operatorSignInFailure struct {
+	email     string
+	ipAddress string
+	failedOn  time.Time
 }
 
 // SetCurrentTenant tenant
@@ -34,7 +56,7 @@ func (s *TenantStorage) SetCurrentUser(user *models.User) {
 // Add given tenant to tenant list
 func (s *TenantStorage) Add(name string, subdomain string, status int) (*models.Tenant, error) {
 	s.lastID = s.lastID + 1
-	tenant := &models.Tenant{ID: s.lastID, Name: name, Subdomain: subdomain, Status: status}
+	tenant := &models.Tenant{ID: s.lastID, Name: name, Subdomain: subdomain, Status: status, Locale: "en", Plan: models.PlanUnlimited}
 	s.tenants = append(s.tenants, tenant)
 	return tenant, nil
 }
@@ -48,6 +70,17 @@ func (s *TenantStorage) First() (*models.Tenant, error) {
 	return nil, app.ErrNotFound
 }
 
+// GetByID returns a tenant based on its id
+func (s *TenantStorage) GetByID(id int) (*models.Tenant, error) {
+	for _, tenant := range s.tenants {
+		if tenant.ID == id {
+			return tenant, nil
+		}
+	}
+
+	return nil, app.ErrNotFound
+}
+
 // GetByDomain returns a tenant based on its domain
 func (s *TenantStorage) GetByDomain(domain string) (*models.Tenant, error) {
 	for _, tenant := range s.tenants {
@@ -62,11 +95,11 @@ func (s *TenantStorage) GetByDomain(domain string) (*models.Tenant, error) {
 // IsSubdomainAvailable returns true if subdomain is available to use
 func (s *TenantStorage) IsSubdomainAvailable(subdomain string) (bool, error) {
 	for _, tenant := range s.tenants {
-		if tenant.Subdomain == subdomain {
+		if tenant.Subdomain == subdomain && (s.current == nil || tenant.ID != s.current.ID) {
 			return false, nil
 		}
 	}
-	return true, nil
+	return !s.isAliasReserved(subdomain, false), nil
 }
 
 // IsCNAMEAvailable returns true if cname is available to use
@@ -76,7 +109,88 @@ func (s *TenantStorage) IsCNAMEAvailable(cname string) (bool, error) {
 			return false, nil
 		}
 	}
-	return true, nil
+	return !s.isAliasReserved(cname, true), nil
+}
+
+func (s *TenantStorage) isAliasReserved(hostname string, isCNAME bool) bool {
+	cooldown := time.Now().Add(-models.TenantAliasCooldownPeriod)
+	for _, alias := range s.aliases {
+		if alias.Hostname == hostname && alias.IsCNAME == isCNAME &&
+			(s.current == nil || alias.tenantID != s.current.ID) &&
+			(alias.removedOn == nil || alias.removedOn.After(cooldown)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *TenantStorage) replaceHostname(tenantID int, previous, hostname string, isCNAME bool) {
+	if previous == hostname {
+		return
+	}
+
+	aliases := make([]*tenantAlias, 0)
+	for _, alias := range s.aliases {
+		if alias.tenantID != tenantID || alias.Hostname != hostname || alias.IsCNAME != isCNAME {
+			aliases = append(aliases, alias)
+		}
+	}
+	s.aliases = aliases
+
+	if previous != "" {
+		s.lastAliasID = s.lastAliasID + 1
+		s.aliases = append(s.aliases, &tenantAlias{
+			TenantAlias: models.TenantAlias{
+				ID:        s.lastAliasID,
+				Hostname:  previous,
+				IsCNAME:   isCNAME,
+				CreatedOn: time.Now(),
+			},
+			tenantID: tenantID,
+		})
+	}
+}
+
+// GetByAlias returns the tenant that used to be served on given domain
+func (s *TenantStorage) GetByAlias(domain string) (*models.Tenant, error) {
+	for i := len(s.aliases) - 1; i >= 0; i-- {
+		alias := s.aliases[i]
+		if alias.removedOn == nil &&
+			((alias.IsCNAME && alias.Hostname == domain) || (!alias.IsCNAME && alias.Hostname == extractSubdomain(domain))) {
+			return s.GetByID(alias.tenantID)
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+// ListAliases returns all hostnames that redirect to current tenant
+func (s *TenantStorage) ListAliases() ([]*models.TenantAlias, error) {
+	result := make([]*models.TenantAlias, 0)
+	for i := len(s.aliases) - 1; i >= 0; i-- {
+		alias := s.aliases[i]
+		if alias.tenantID == s.current.ID && alias.removedOn == nil {
+			result = append(result, &alias.TenantAlias)
+		}
+	}
+	return result, nil
+}
+
+// GetCertificateInfo returns the status of the certificate issued automatically for given hostname
+// Certificates are only cached on Postgres, so there's never one in memory
+func (s *TenantStorage) GetCertificateInfo(hostname string) (*models.CertificateInfo, error) {
+	return nil, app.ErrNotFound
+}
+
+// RemoveAlias stops redirecting given alias to current tenant
+func (s *TenantStorage) RemoveAlias(id int) error {
+	for _, alias := range s.aliases {
+		if alias.ID == id && alias.tenantID == s.current.ID && alias.removedOn == nil {
+			now := time.Now()
+			alias.removedOn = &now
+			return nil
+		}
+	}
+	return app.ErrNotFound
 }
 
 // UpdateSettings of current tenant
@@ -84,23 +198,64 @@ func (s *TenantStorage) UpdateSettings(settings *models.UpdateTenantSettings) er
 	for _, tenant := range s.tenants {
 		if tenant.ID == s.current.ID {
 
-			if settings.Logo != nil && settings.Logo.Upload != nil && len(settings.Logo.Upload.Content) > 0 {
-				s.lastLogoID = s.lastLogoID + 1
-				if s.tenantLogos == nil {
-					s.tenantLogos = make(map[int]*models.Upload, 0)
-				}
-				tenant.LogoID = s.lastLogoID
-				s.tenantLogos[s.lastLogoID] = &models.Upload{
-					Content:     settings.Logo.Upload.Content,
-					Size:        len(settings.Logo.Upload.Content),
-					ContentType: http.DetectContentType(settings.Logo.Upload.Content),
-				}
+			if settings.Logo != nil {
+				tenant.LogoID = s.replaceUpload(tenant.LogoID, settings.Logo)
 			}
 
 			tenant.Invitation = settings.Invitation
 			tenant.WelcomeMessage = settings.WelcomeMessage
 			tenant.Name = settings.Title
+			if settings.Subdomain != "" {
+				s.replaceHostname(tenant.ID, tenant.Subdomain, settings.Subdomain, false)
+				tenant.Subdomain = settings.Subdomain
+			}
+			s.replaceHostname(tenant.ID, tenant.CNAME, settings.CNAME, true)
 			tenant.CNAME = settings.CNAME
+			if settings.Locale != "" {
+				tenant.Locale = settings.Locale
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *TenantStorage) replaceUpload(previousID int, image *models.UpdateTenantSettingsLogo) int {
+	if image.Remove {
+		delete(s.tenantLogos, previousID)
+		return 0
+	}
+
+	if image.Upload == nil || len(image.Upload.Content) == 0 {
+		return previousID
+	}
+
+	s.lastLogoID = s.lastLogoID + 1
+	if s.tenantLogos == nil {
+		s.tenantLogos = make(map[int]*models.Upload, 0)
+	}
+	delete(s.tenantLogos, previousID)
+	s.tenantLogos[s.lastLogoID] = &models.Upload{
+		Content:     image.Upload.Content,
+		Size:        len(image.Upload.Content),
+		ContentType: http.DetectContentType(image.Upload.Content),
+	}
+	return s.lastLogoID
+}
+
+// UpdateTheme of current tenant
+func (s *TenantStorage) UpdateTheme(settings *models.UpdateTenantTheme) error {
+	for _, tenant := range s.tenants {
+		if tenant.ID == s.current.ID {
+			tenant.Theme.PrimaryColor = settings.PrimaryColor
+			tenant.Theme.AccentColor = settings.AccentColor
+			tenant.Theme.CustomCSS = settings.CustomCSS
+			if settings.Favicon != nil {
+				tenant.Theme.FaviconID = s.replaceUpload(tenant.Theme.FaviconID, settings.Favicon)
+			}
+			if settings.Header != nil {
+				tenant.Theme.HeaderID = s.replaceUpload(tenant.Theme.HeaderID, settings.Header)
+			}
 			return nil
 		}
 	}
@@ -118,6 +273,122 @@ func (s *TenantStorage) UpdatePrivacy(settings *models.UpdateTenantPrivacy) erro
 	return nil
 }
 
+// UpdateEmailSettings of current tenant
+func (s *TenantStorage) UpdateEmailSettings(settings *models.UpdateTenantEmailSettings) error {
+	email := &s.current.Email
+	email.FromAddress = settings.FromAddress
+	email.ReplyTo = settings.ReplyTo
+	email.SMTPHost = settings.SMTPHost
+	email.SMTPPort = settings.SMTPPort
+	email.SMTPUsername = settings.SMTPUsername
+	email.DKIMSelector = settings.DKIMSelector
+	if settings.SMTPPassword != "" {
+		email.SMTPPassword = settings.SMTPPassword
+	}
+	if settings.DKIMPrivateKey != "" {
+		email.DKIMPrivateKey = settings.DKIMPrivateKey
+	}
+	if email.VerificationToken == "" {
+		email.VerificationToken = models.GenerateVerificationKey()
+	}
+	return nil
+}
+
+// UpdateSAMLSettings of current tenant
+func (s *TenantStorage) UpdateSAMLSettings(settings *models.UpdateTenantSAMLSettings) error {
+	s.current.SAML = models.TenantSAMLSettings{
+		IsEnabled:              settings.IsEnabled,
+		IsEnforced:             settings.IsEnforced,
+		IdPEntityID:            settings.IdPEntityID,
+		IdPSSOURL:              settings.IdPSSOURL,
+		IdPCertificate:         settings.IdPCertificate,
+		NameAttribute:          settings.NameAttribute,
+		EmailAttribute:         settings.EmailAttribute,
+		RoleAttribute:          settings.RoleAttribute,
+		AdministratorRoleValue: settings.AdministratorRoleValue,
+		CollaboratorRoleValue:  settings.CollaboratorRoleValue,
+	}
+	return nil
+}
+
+// UpdateJWTSSOSettings of current tenant
+func (s *TenantStorage) UpdateJWTSSOSettings(settings *models.UpdateTenantJWTSSOSettings) error {
+	sso := &s.current.JWTSSO
+	sso.IsEnabled = settings.IsEnabled
+	sso.PublicKey = settings.PublicKey
+	if settings.Secret != "" {
+		sso.Secret = settings.Secret
+	}
+	return nil
+}
+
+// UpdateTwoFactorSettings of current tenant
+func (s *TenantStorage) UpdateTwoFactorSettings(settings *models.UpdateTenantTwoFactorSettings) error {
+	s.current.IsTwoFactorRequired = settings.IsRequired
+	return nil
+}
+
+// UpdateAutoJoinSettings of current tenant
+func (s *TenantStorage) UpdateAutoJoinSettings(settings *models.UpdateTenantAutoJoinSettings) error {
+	s.current.AutoJoin = models.TenantAutoJoinSettings{
+		Domains: settings.Domains,
+		Role:    settings.Role,
+	}
+	return nil
+}
+
+// UpdateEmbedSettings of current tenant
+func (s *TenantStorage) UpdateEmbedSettings(settings *models.UpdateTenantEmbedSettings) error {
+	s.current.EmbedOrigins = settings.Origins
+	return nil
+}
+
+// UseSSOToken records given token as used and returns false if it has been used before
+func (s *TenantStorage) UseSSOToken(id string, expiresOn time.Time) (bool, error) {
+	if s.ssoTokens == nil {
+		s.ssoTokens = make(map[int]map[string]time.Time)
+	}
+	if s.ssoTokens[s.current.ID] == nil {
+		s.ssoTokens[s.current.ID] = make(map[string]time.Time)
+	}
+
+	tokens := s.ssoTokens[s.current.ID]
+	if used, ok := tokens[id]; ok && used.After(time.Now()) {
+		return false, nil
+	}
+	tokens[id] = expiresOn
+	return true, nil
+}
+
+// UseSAMLAssertion records given assertion as used and returns false if it has been used before
+func (s *TenantStorage) UseSAMLAssertion(id string, expiresOn time.Time) (bool, error) {
+	if s.samlAssertions == nil {
+		s.samlAssertions = make(map[int]map[string]time.Time)
+	}
+	if s.samlAssertions[s.current.ID] == nil {
+		s.samlAssertions[s.current.ID] = make(map[string]time.Time)
+	}
+
+	assertions := s.samlAssertions[s.current.ID]
+	if used, ok := assertions[id]; ok && used.After(time.Now()) {
+		return false, nil
+	}
+	assertions[id] = expiresOn
+	return true, nil
+}
+
+// SetEmailVerifiedDomain marks given domain as owned by current tenant
+func (s *TenantStorage) SetEmailVerifiedDomain(domain string) error {
+	s.current.Email.VerifiedDomain = strings.ToLower(domain)
+	return nil
+}
+
+// SetEmailVerifiedAddress marks given email address as owned by current tenant
+func (s *TenantStorage) SetEmailVerifiedAddress(address string) error {
+	s.current.Email.VerifiedAddress = strings.ToLower(address)
+	return nil
+}
+
 // Activate given tenant
 func (s *TenantStorage) Activate(id int) error {
 	for _, tenant := range s.tenants {
@@ -129,13 +400,185 @@ func (s *TenantStorage) Activate(id int) error {
 	return app.ErrNotFound
 }
 
+// Suspend given tenant
+func (s *TenantStorage) Suspend(id int) error {
+	tenant, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	tenant.Status = models.TenantSuspended
+	return nil
+}
+
+// ScheduleDeletion marks given tenant to be purged on given date
+func (s *TenantStorage) ScheduleDeletion(id int, on time.Time) error {
+	tenant, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	tenant.DeletionScheduledOn = &on
+	return nil
+}
+
+// CancelDeletion removes the scheduled deletion of given tenant
+func (s *TenantStorage) CancelDeletion(id int) error {
+	tenant, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	tenant.DeletionScheduledOn = nil
+	return nil
+}
+
+// GetDueForDeletion returns all tenants which deletion date is before given date
+func (s *TenantStorage) GetDueForDeletion(before time.Time) ([]*models.Tenant, error) {
+	result := make([]*models.Tenant, 0)
+	for _, tenant := range s.tenants {
+		if tenant.DeletionScheduledOn != nil && !tenant.DeletionScheduledOn.After(before) {
+			result = append(result, tenant)
+		}
+	}
+	return result, nil
+}
+
+// LockForPurge tries to take the lock held while tenants are purged
+func (s *TenantStorage) LockForPurge() (bool, error) {
+	return true, nil
+}
+
+// Purge permanently deletes given tenant
+func (s *TenantStorage) Purge(id int) error {
+	for i, tenant := range s.tenants {
+		if tenant.ID == id {
+			s.tenants = append(s.tenants[:i], s.tenants[i+1:]...)
+			return nil
+		}
+	}
+	return app.ErrNotFound
+}
+
+// Search returns an overview of the tenants which name or domain contain given query, newest first
+func (s *TenantStorage) Search(query string) ([]*models.TenantSummary, error) {
+	query = strings.ToLower(query)
+	result := make([]*models.TenantSummary, 0)
+	for i := len(s.tenants) - 1; i >= 0; i-- {
+		tenant := s.tenants[i]
+		if strings.Contains(strings.ToLower(tenant.Name), query) ||
+			strings.Contains(tenant.Subdomain, query) ||
+			strings.Contains(tenant.CNAME, query) {
+			result = append(result, &models.TenantSummary{
+				ID:                  tenant.ID,
+				Name:                tenant.Name,
+				Subdomain:           tenant.Subdomain,
+				CNAME:               tenant.CNAME,
+				Status:              tenant.Status,
+				Plan:                planOf(tenant).Name,
+				DeletionScheduledOn: tenant.DeletionScheduledOn,
+			})
+		}
+	}
+	return result, nil
+}
+
+// ChangePlan of given tenant
+func (s *TenantStorage) ChangePlan(id int, plan string) error {
+	tenant, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	tenant.Plan = models.GetPlan(plan)
+	return nil
+}
+
+func planOf(tenant *models.Tenant) *models.Plan {
+	if tenant == nil || tenant.Plan == nil {
+		return models.PlanUnlimited
+	}
+	return tenant.Plan
+}
+
+// GetUsage returns what current tenant consumes out of its plan
+// Users and ideas are kept by other in-memory storages, so only the storage is measured
+func (s *TenantStorage) GetUsage() (*models.TenantUsage, error) {
+	usage := &models.TenantUsage{Plan: planOf(s.current)}
+	if s.current != nil {
+		for _, id := range []int{s.current.LogoID, s.current.Theme.FaviconID, s.current.Theme.HeaderID} {
+			if upload, ok := s.tenantLogos[id]; ok {
+				usage.Storage += int64(upload.Size)
+			}
+		}
+	}
+	return usage, nil
+}
+
+// ChangeSubdomain of given tenant
+func (s *TenantStorage) ChangeSubdomain(id int, subdomain string) error {
+	tenant, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	s.replaceHostname(tenant.ID, tenant.Subdomain, subdomain, false)
+	tenant.Subdomain = subdomain
+	return nil
+}
+
+// AddOperatorAudit records an action performed by an operator
+func (s *TenantStorage) AddOperatorAudit(entry *models.OperatorAuditEntry) error {
+	if entry.CreatedOn.IsZero() {
+		entry.CreatedOn = time.Now()
+	}
+	entry.ID = len(s.operatorAudit) + 1
+	s.operatorAudit = append(s.operatorAudit, entry)
+	return nil
+}
+
+// ListOperatorAudit returns the latest actions performed by operators
+func (s *TenantStorage) ListOperatorAudit() ([]*models.OperatorAuditEntry, error) {
+	result := make([]*models.OperatorAuditEntry, 0)
+	for i := len(s.operatorAudit) - 1; i >= 0; i-- {
+		result = append(result, s.operatorAudit[i])
+	}
+	return result, nil
+}
+
+// AddOperatorSignInFailure records a wrong email or secret given on the sign in of the super-admin console
+func (s *TenantStorage) AddOperatorSignInFailure(email, ipAddress string) error {
+	s.operatorFailures = append(s.operatorFailures, &operatorSignInFailure{
+		email:     email,
+		ipAddress: ipAddress,
+		failedOn:  time.Now(),
+	})
+	return nil
+}
+
+// CountOperatorSignInFailures returns the most failed operator sign ins for given email or from given IP address since given time
+func (s *TenantStorage) CountOperatorSignInFailures(email, ipAddress string, since time.Time) (int, error) {
+	byEmail, byIPAddress := 0, 0
+	for _, failure := range s.operatorFailures {
+		if failure.failedOn.After(since) {
+			if failure.email == email {
+				byEmail++
+			}
+			if failure.ipAddress == ipAddress {
+				byIPAddress++
+			}
+		}
+	}
+	if byEmail > byIPAddress {
+		return byEmail, nil
+	}
+	return byIPAddress, nil
+}
+
 // SaveVerificationKey used by email verification
 func (s *TenantStorage) SaveVerificationKey(key string, duration time.Duration, request models.NewEmailVerification) error {
 	userID := 0
 	if request.GetUser() != nil {
 		userID = request.GetUser().ID
 	}
+	s.lastVerifyID = s.lastVerifyID + 1
 	s.verifications = append(s.verifications, &models.EmailVerification{
+		ID:         s.lastVerifyID,
 		Email:      request.GetEmail(),
 		Name:       request.GetName(),
 		Kind:       request.GetKind(),
@@ -169,6 +612,38 @@ func (s *TenantStorage) SetKeyAsVerified(key string) error {
 	return nil
 }
 
+// GetVerificationsByKind returns all email verifications of given kind, most recent first
+func (s *TenantStorage) GetVerificationsByKind(kind models.EmailVerificationKind) ([]*models.EmailVerification, error) {
+	result := make([]*models.EmailVerification, 0)
+	for i := len(s.verifications) - 1; i >= 0; i-- {
+		if s.verifications[i].Kind == kind {
+			result = append(result, s.verifications[i])
+		}
+	}
+	return result, nil
+}
+
+// GetVerificationByID returns the email verification of given kind with given id
+func (s *TenantStorage) GetVerificationByID(kind models.EmailVerificationKind, id int) (*models.EmailVerification, error) {
+	for _, verification := range s.verifications {
+		if verification.ID == id && verification.Kind == kind {
+			return verification, nil
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+// DeleteVerification so that its key cannot be used anymore
+func (s *TenantStorage) DeleteVerification(id int) error {
+	for i, verification := range s.verifications {
+		if verification.ID == id {
+			s.verifications = append(s.verifications[:i], s.verifications[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 // GetLogo returns tenant logo by id
 func (s *TenantStorage) GetLogo(id int) (*models.Upload, error) {
 	if s.tenantLogos != nil {
@@ -181,6 +656,117 @@ func (s *TenantStorage) GetLogo(id int) (*models.Upload, error) {
 	return nil, app.ErrNotFound
 }
 
+// GetThemeImage returns the favicon or header image of current tenant by id
+func (s *TenantStorage) GetThemeImage(id int) (*models.Upload, error) {
+	if id == 0 || (id != s.current.Theme.FaviconID && id != s.current.Theme.HeaderID) {
+		return nil, app.ErrNotFound
+	}
+	return s.GetLogo(id)
+}
+
+// GetOAuthConfigByProvider returns a custom OAuth configuration by provider name
+func (s *TenantStorage) GetOAuthConfigByProvider(provider string) (*models.OAuthConfig, error) {
+	for _, config := range s.oauthConfigs[s.current.ID] {
+		if config.Provider == provider {
+			return config, nil
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+// ListOAuthConfig returns all custom OAuth configurations of current tenant
+func (s *TenantStorage) ListOAuthConfig() ([]*models.OAuthConfig, error) {
+	configs := s.oauthConfigs[s.current.ID]
+	if configs == nil {
+		return []*models.OAuthConfig{}, nil
+	}
+	return configs, nil
+}
+
+// SaveOAuthConfig saves given custom OAuth configuration, keeping current secret if none is given
+func (s *TenantStorage) SaveOAuthConfig(config *models.CreateEditOAuthConfig) error {
+	if s.oauthConfigs == nil {
+		s.oauthConfigs = make(map[int][]*models.OAuthConfig)
+	}
+
+	existing, err := s.GetOAuthConfigByProvider(config.Provider)
+	if err != nil {
+		existing = &models.OAuthConfig{
+			ID:       len(s.oauthConfigs[s.current.ID]) + 1,
+			Provider: config.Provider,
+		}
+		s.oauthConfigs[s.current.ID] = append(s.oauthConfigs[s.current.ID], existing)
+	}
+
+	existing.DisplayName = config.DisplayName
+	existing.Status = config.Status
+	existing.ClientID = config.ClientID
+	if config.ClientSecret != "" {
+		existing.ClientSecret = config.ClientSecret
+	}
+	existing.AuthorizeURL = config.AuthorizeURL
+	existing.TokenURL = config.TokenURL
+	existing.ProfileURL = config.ProfileURL
+	existing.Scope = config.Scope
+	existing.JSONUserIDPath = config.JSONUserIDPath
+	existing.JSONUserNamePath = config.JSONUserNamePath
+	existing.JSONUserEmailPath = config.JSONUserEmailPath
+	existing.EmailAlwaysVerified = config.EmailAlwaysVerified
+	return nil
+}
+
+// ListCustomRoles returns all roles defined by current tenant
+func (s *TenantStorage) ListCustomRoles() ([]*models.CustomRole, error) {
+	roles := s.customRoles[s.current.ID]
+	if roles == nil {
+		return []*models.CustomRole{}, nil
+	}
+	return roles, nil
+}
+
+// GetCustomRoleByID returns a role defined by current tenant
+func (s *TenantStorage) GetCustomRoleByID(id int) (*models.CustomRole, error) {
+	for _, role := range s.customRoles[s.current.ID] {
+		if role.ID == id {
+			return role, nil
+		}
+	}
+	return nil, app.ErrNotFound
+}
+
+// SaveCustomRole creates a new role or updates an existing one when ID is given
+func (s *TenantStorage) SaveCustomRole(input *models.SaveCustomRole) (*models.CustomRole, error) {
+	if s.customRoles == nil {
+		s.customRoles = make(map[int][]*models.CustomRole)
+	}
+
+	role, err := s.GetCustomRoleByID(input.ID)
+	if err != nil {
+		if input.ID != 0 {
+			return nil, err
+		}
+		s.lastRoleID = s.lastRoleID + 1
+		role = &models.CustomRole{ID: s.lastRoleID}
+		s.customRoles[s.current.ID] = append(s.customRoles[s.current.ID], role)
+	}
+
+	role.Name = input.Name
+	role.Permissions = input.Permissions
+	return role, nil
+}
+
+// DeleteCustomRole removes a role defined by current tenant
+func (s *TenantStorage) DeleteCustomRole(id int) error {
+	roles := s.customRoles[s.current.ID]
+	for i, role := range roles {
+		if role.ID == id {
+			s.customRoles[s.current.ID] = append(roles[:i], roles[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func extractSubdomain(hostname string) string {
 	domain := env.MultiTenantDomain()
 	if domain == "" {
@@ -1,6 +1,8 @@
 package inmemory
 
 import (
+	"time"
+
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/errors"
@@ -13,6 +15,9 @@ type UserStorage struct {
 	users           []*models.User
 	lastID          int
 	settingsPerUser map[int]map[string]string
+	totpLastStep    map[int]int64
+	recoveryCodes   map[int]map[string]bool
+	failures        map[int][]time.Time
 }
 
 // GetByID returns a user based on given id
@@ -85,6 +90,7 @@ func (s *UserStorage) Update(settings *models.UpdateUserSettings) error {
 	user, err := s.GetByID(s.user.ID)
 	if err == nil {
 		user.Name = settings.Name
+		user.Locale = settings.Locale
 	}
 	return err
 }
@@ -94,10 +100,113 @@ func (s *UserStorage) ChangeRole(userID int, role models.Role) error {
 	user, err := s.GetByID(userID)
 	if err == nil {
 		user.Role = role
+		user.CustomRole = nil
+	}
+	return err
+}
+
+// AssignCustomRole of given user, who becomes a collaborator with the permissions of that role
+func (s *UserStorage) AssignCustomRole(userID int, role *models.CustomRole) error {
+	user, err := s.GetByID(userID)
+	if err == nil {
+		user.Role = models.RoleCollaborator
+		user.CustomRole = role
+	}
+	return err
+}
+
+// ChangeAvatarURL of given user
+func (s *UserStorage) ChangeAvatarURL(userID int, avatarURL string) error {
+	user, err := s.GetByID(userID)
+	if err == nil {
+		user.AvatarURL = avatarURL
+	}
+	return err
+}
+
+// SetTOTPSecret stores the secret being enrolled by given user, which is only used after being enabled
+func (s *UserStorage) SetTOTPSecret(userID int, secret string) error {
+	user, err := s.GetByID(userID)
+	if err == nil && !user.IsTOTPEnabled {
+		user.TOTPSecret = secret
 	}
 	return err
 }
 
+// EnableTOTP turns on two-factor authentication of given user and replaces the recovery codes
+func (s *UserStorage) EnableTOTP(userID int, recoveryCodeHashes []string) error {
+	user, err := s.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPSecret != "" {
+		user.IsTOTPEnabled = true
+	}
+
+	if s.recoveryCodes == nil {
+		s.recoveryCodes = make(map[int]map[string]bool)
+	}
+	s.recoveryCodes[userID] = make(map[string]bool)
+	for _, hash := range recoveryCodeHashes {
+		s.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+// DisableTOTP turns off two-factor authentication of given user
+func (s *UserStorage) DisableTOTP(userID int) error {
+	user, err := s.GetByID(userID)
+	if err == nil {
+		user.IsTOTPEnabled = false
+		user.TOTPSecret = ""
+		delete(s.totpLastStep, userID)
+		delete(s.recoveryCodes, userID)
+	}
+	return err
+}
+
+// UseTOTPStep records given time step as used and returns false if it, or a later one, has been used before
+func (s *UserStorage) UseTOTPStep(userID int, step int64) (bool, error) {
+	if s.totpLastStep == nil {
+		s.totpLastStep = make(map[int]int64)
+	}
+	if s.totpLastStep[userID] >= step {
+		return false, nil
+	}
+	s.totpLastStep[userID] = step
+	return true, nil
+}
+
+// UseRecoveryCode marks given recovery code as used and returns false if it doesn't exist or has been used before
+func (s *UserStorage) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	used, ok := s.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	s.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+// AddTwoFactorFailure records a wrong code given by the user and returns how many were given since given time
+func (s *UserStorage) AddTwoFactorFailure(userID int, since time.Time) (int, error) {
+	if s.failures == nil {
+		s.failures = make(map[int][]time.Time)
+	}
+	s.failures[userID] = append(s.failures[userID], time.Now())
+	return s.CountTwoFactorFailures(userID, since)
+}
+
+// CountTwoFactorFailures returns how many wrong codes were given by the user since given time
+func (s *UserStorage) CountTwoFactorFailures(userID int, since time.Time) (int, error) {
+	count := 0
+	for _, failedOn := range s.failures[userID] {
+		if failedOn.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // ChangeEmail of given user
 func (s *UserStorage) ChangeEmail(userID int, email string) error {
 	user, err := s.GetByID(userID)
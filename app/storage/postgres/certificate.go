@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// CertificateCache stores certificates issued by Let's Encrypt so that they are shared by all instances
+// It is used outside of requests, so each operation runs on its own transaction
+type CertificateCache struct {
+	db *dbx.Database
+}
+
+// NewCertificateCache creates a new CertificateCache
+func NewCertificateCache(db *dbx.Database) *CertificateCache {
+	return &CertificateCache{db: db}
+}
+
+// Get returns the data stored under given key
+func (c *CertificateCache) Get(ctx context.Context, key string) ([]byte, error) {
+	trx, err := c.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer trx.Rollback()
+
+	var data []byte
+	err = trx.Scalar(&data, "SELECT data FROM autocert_cache WHERE key = $1", key)
+	if err != nil {
+		if err == app.ErrNotFound {
+			return nil, autocert.ErrCacheMiss
+		}
+		return nil, errors.Wrap(err, "failed to get certificate cache '%s'", key)
+	}
+	return data, nil
+}
+
+// Put stores data under given key
+func (c *CertificateCache) Put(ctx context.Context, key string, data []byte) error {
+	trx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = trx.Execute(`
+		INSERT INTO autocert_cache (key, data, expires_on, updated_on) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE SET data = $2, expires_on = $3, updated_on = $4`,
+		key, data, certificateExpiry(data), time.Now(),
+	)
+	if err != nil {
+		trx.Rollback()
+		return errors.Wrap(err, "failed to put certificate cache '%s'", key)
+	}
+	return trx.Commit()
+}
+
+// Delete removes the data stored under given key
+func (c *CertificateCache) Delete(ctx context.Context, key string) error {
+	trx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = trx.Execute("DELETE FROM autocert_cache WHERE key = $1", key)
+	if err != nil {
+		trx.Rollback()
+		return errors.Wrap(err, "failed to delete certificate cache '%s'", key)
+	}
+	return trx.Commit()
+}
+
+// certificateExpiry returns when the first certificate of a PEM bundle expires
+// Account keys and other entries without certificates return nil
+func certificateExpiry(data []byte) *time.Time {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil
+		}
+
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil
+			}
+			return &cert.NotAfter
+		}
+	}
+}
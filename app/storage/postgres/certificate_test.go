@@ -0,0 +1,75 @@
+package postgres_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/getfider/fider/app/storage/postgres"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func selfSignedCertificate(hostname string, expiresOn time.Time) []byte {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hostname},
+		DNSNames:     []string{hostname},
+		NotBefore:    time.Now(),
+		NotAfter:     expiresOn,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+}
+
+func TestCertificateCache(t *testing.T) {
+	RegisterT(t)
+
+	ctx := context.Background()
+	cache := postgres.NewCertificateCache(db)
+
+	_, err := cache.Get(ctx, "feedback.demo.com")
+	Expect(err).Equals(autocert.ErrCacheMiss)
+
+	expiresOn := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+	data := selfSignedCertificate("feedback.demo.com", expiresOn)
+	err = cache.Put(ctx, "feedback.demo.com", data)
+	Expect(err).IsNil()
+	err = cache.Put(ctx, "acme_account+key", []byte("account key"))
+	Expect(err).IsNil()
+
+	cached, err := cache.Get(ctx, "feedback.demo.com")
+	Expect(err).IsNil()
+	Expect(cached).Equals(data)
+
+	SetupDatabaseTest(t)
+	info, err := tenants.GetCertificateInfo("feedback.demo.com")
+	Expect(err).IsNil()
+	Expect(info.Hostname).Equals("feedback.demo.com")
+	Expect(info.ExpiresOn.Unix()).Equals(expiresOn.Unix())
+
+	_, err = tenants.GetCertificateInfo("acme_account")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	TeardownDatabaseTest()
+
+	err = cache.Delete(ctx, "feedback.demo.com")
+	Expect(err).IsNil()
+	err = cache.Delete(ctx, "acme_account+key")
+	Expect(err).IsNil()
+
+	_, err = cache.Get(ctx, "feedback.demo.com")
+	Expect(err).Equals(autocert.ErrCacheMiss)
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbEmailDelivery struct {
+	ID           int       `db:"id"`
+	TemplateName string    `db:"template_name"`
+	Address      string    `db:"address"`
+	Provider     string    `db:"provider"`
+	Status       int       `db:"status"`
+	Response     string    `db:"response"`
+	CreatedOn    time.Time `db:"created_on"`
+}
+
+func (d *dbEmailDelivery) toModel() *models.EmailDelivery {
+	return &models.EmailDelivery{
+		ID:           d.ID,
+		TemplateName: d.TemplateName,
+		Address:      d.Address,
+		Provider:     d.Provider,
+		Status:       models.EmailDeliveryStatus(d.Status),
+		Response:     d.Response,
+		CreatedOn:    d.CreatedOn,
+	}
+}
+
+type dbEmailSuppression struct {
+	Address   string    `db:"address"`
+	Reason    string    `db:"reason"`
+	CreatedOn time.Time `db:"created_on"`
+}
+
+func (s *dbEmailSuppression) toModel() *models.EmailSuppression {
+	return &models.EmailSuppression{
+		Address:   s.Address,
+		Reason:    s.Reason,
+		CreatedOn: s.CreatedOn,
+	}
+}
+
+// EmailLogStorage contains read and write operations for email deliveries and suppressed addresses
+type EmailLogStorage struct {
+	trx    *dbx.Trx
+	tenant *models.Tenant
+	user   *models.User
+}
+
+// NewEmailLogStorage creates a new EmailLogStorage
+func NewEmailLogStorage(trx *dbx.Trx) *EmailLogStorage {
+	return &EmailLogStorage{
+		trx: trx,
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *EmailLogStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *EmailLogStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// Add records an email delivery for current tenant
+func (s *EmailLogStorage) Add(delivery *models.EmailDelivery) error {
+	if delivery.CreatedOn.IsZero() {
+		delivery.CreatedOn = time.Now()
+	}
+
+	err := s.trx.Get(&delivery.ID, `
+		INSERT INTO email_deliveries (tenant_id, template_name, address, provider, status, response, created_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, s.tenant.ID, delivery.TemplateName, delivery.Address, delivery.Provider, delivery.Status, delivery.Response, delivery.CreatedOn)
+	if err != nil {
+		return errors.Wrap(err, "failed to add email delivery")
+	}
+	return nil
+}
+
+// GetRecent returns the most recent email deliveries of current tenant
+func (s *EmailLogStorage) GetRecent(limit int) ([]*models.EmailDelivery, error) {
+	deliveries := []*dbEmailDelivery{}
+	err := s.trx.Select(&deliveries, `
+		SELECT id, template_name, address, provider, status, response, created_on
+		FROM email_deliveries
+		WHERE tenant_id = $1
+		ORDER BY created_on DESC, id DESC
+		LIMIT $2
+	`, s.tenant.ID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get recent email deliveries")
+	}
+
+	var result = make([]*models.EmailDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		result[i] = delivery.toModel()
+	}
+	return result, nil
+}
+
+// GetSuppressions returns all suppressed addresses of current tenant
+func (s *EmailLogStorage) GetSuppressions() ([]*models.EmailSuppression, error) {
+	suppressions := []*dbEmailSuppression{}
+	err := s.trx.Select(&suppressions, `
+		SELECT address, reason, created_on
+		FROM email_suppressions
+		WHERE tenant_id = $1
+		ORDER BY created_on DESC
+	`, s.tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get email suppressions")
+	}
+
+	var result = make([]*models.EmailSuppression, len(suppressions))
+	for i, suppression := range suppressions {
+		result[i] = suppression.toModel()
+	}
+	return result, nil
+}
+
+// IsSuppressed returns true if given address is on the suppression list of current tenant
+func (s *EmailLogStorage) IsSuppressed(address string) (bool, error) {
+	exists, err := s.trx.Exists(`
+		SELECT 1 FROM email_suppressions WHERE tenant_id = $1 AND lower(address) = lower($2)
+	`, s.tenant.ID, address)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check if '%s' is suppressed", address)
+	}
+	return exists, nil
+}
+
+// Suppress adds given address to the suppression list of current tenant
+func (s *EmailLogStorage) Suppress(address, reason string) error {
+	_, err := s.trx.Execute(`
+		INSERT INTO email_suppressions (tenant_id, address, reason, created_on)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, lower(address)) DO UPDATE SET reason = $3
+	`, s.tenant.ID, address, reason, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to suppress '%s'", address)
+	}
+	return nil
+}
+
+// Unsuppress removes given address from the suppression list of current tenant
+func (s *EmailLogStorage) Unsuppress(address string) error {
+	_, err := s.trx.Execute(`
+		DELETE FROM email_suppressions WHERE tenant_id = $1 AND lower(address) = lower($2)
+	`, s.tenant.ID, address)
+	if err != nil {
+		return errors.Wrap(err, "failed to unsuppress '%s'", address)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+)
+
+func TestEmailLogStorage_AddAndGetRecent(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	emailLog.SetCurrentTenant(demoTenant)
+	err := emailLog.Add(&models.EmailDelivery{
+		TemplateName: "new_idea",
+		Address:      "jon.snow@got.com",
+		Provider:     "mailgun",
+		Status:       models.EmailDeliverySent,
+		Response:     `{"message": "Queued. Thank you."}`,
+	})
+	Expect(err).IsNil()
+
+	err = emailLog.Add(&models.EmailDelivery{
+		TemplateName: "new_comment",
+		Address:      "arya.stark@got.com",
+		Provider:     "mailgun",
+		Status:       models.EmailDeliveryFailed,
+		Response:     "timeout",
+	})
+	Expect(err).IsNil()
+
+	deliveries, err := emailLog.GetRecent(10)
+	Expect(err).IsNil()
+	Expect(deliveries).HasLen(2)
+	Expect(deliveries[0].TemplateName).Equals("new_comment")
+	Expect(deliveries[0].Status).Equals(models.EmailDeliveryFailed)
+	Expect(deliveries[1].Address).Equals("jon.snow@got.com")
+	Expect(deliveries[1].Response).Equals(`{"message": "Queued. Thank you."}`)
+
+	deliveries, err = emailLog.GetRecent(1)
+	Expect(err).IsNil()
+	Expect(deliveries).HasLen(1)
+
+	emailLog.SetCurrentTenant(avengersTenant)
+	deliveries, err = emailLog.GetRecent(10)
+	Expect(err).IsNil()
+	Expect(deliveries).HasLen(0)
+}
+
+func TestEmailLogStorage_Suppressions(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	emailLog.SetCurrentTenant(demoTenant)
+	Expect(emailLog.Suppress("jon.snow@got.com", "bounce")).IsNil()
+	Expect(emailLog.Suppress("Jon.Snow@got.com", "complaint")).IsNil()
+
+	suppressed, err := emailLog.IsSuppressed("JON.SNOW@got.com")
+	Expect(err).IsNil()
+	Expect(suppressed).IsTrue()
+
+	suppressions, err := emailLog.GetSuppressions()
+	Expect(err).IsNil()
+	Expect(suppressions).HasLen(1)
+	Expect(suppressions[0].Reason).Equals("complaint")
+
+	emailLog.SetCurrentTenant(avengersTenant)
+	suppressed, err = emailLog.IsSuppressed("jon.snow@got.com")
+	Expect(err).IsNil()
+	Expect(suppressed).IsFalse()
+
+	emailLog.SetCurrentTenant(demoTenant)
+	Expect(emailLog.Unsuppress("jon.snow@got.com")).IsNil()
+	suppressed, err = emailLog.IsSuppressed("jon.snow@got.com")
+	Expect(err).IsNil()
+	Expect(suppressed).IsFalse()
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbEmailTemplate struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Locale    string    `db:"locale"`
+	Subject   string    `db:"subject"`
+	Body      string    `db:"body"`
+	UpdatedOn time.Time `db:"updated_on"`
+}
+
+func (t *dbEmailTemplate) toModel() *models.EmailTemplate {
+	return &models.EmailTemplate{
+		ID:        t.ID,
+		Name:      t.Name,
+		Locale:    t.Locale,
+		Subject:   t.Subject,
+		Body:      t.Body,
+		UpdatedOn: t.UpdatedOn,
+	}
+}
+
+// EmailTemplateStorage contains read and write operations for customized email templates
+type EmailTemplateStorage struct {
+	trx    *dbx.Trx
+	tenant *models.Tenant
+	user   *models.User
+}
+
+// NewEmailTemplateStorage creates a new EmailTemplateStorage
+func NewEmailTemplateStorage(trx *dbx.Trx) *EmailTemplateStorage {
+	return &EmailTemplateStorage{
+		trx: trx,
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *EmailTemplateStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *EmailTemplateStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// GetAll returns all customized templates of current tenant
+func (s *EmailTemplateStorage) GetAll() ([]*models.EmailTemplate, error) {
+	templates := []*dbEmailTemplate{}
+	err := s.trx.Select(&templates, `
+		SELECT id, name, locale, subject, body, updated_on
+		FROM email_templates
+		WHERE tenant_id = $1
+		ORDER BY name, locale
+	`, s.tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get all email templates")
+	}
+
+	var result = make([]*models.EmailTemplate, len(templates))
+	for i, template := range templates {
+		result[i] = template.toModel()
+	}
+	return result, nil
+}
+
+// Get returns the customized template with given name and locale
+func (s *EmailTemplateStorage) Get(name, locale string) (*models.EmailTemplate, error) {
+	template := dbEmailTemplate{}
+	err := s.trx.Get(&template, `
+		SELECT id, name, locale, subject, body, updated_on
+		FROM email_templates
+		WHERE tenant_id = $1 AND name = $2 AND locale = $3
+	`, s.tenant.ID, name, locale)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get email template '%s' for locale '%s'", name, locale)
+	}
+	return template.toModel(), nil
+}
+
+// Save creates or replaces the customized template with given name and locale
+func (s *EmailTemplateStorage) Save(template *models.SaveEmailTemplate) (*models.EmailTemplate, error) {
+	now := time.Now()
+	_, err := s.trx.Execute(`
+		INSERT INTO email_templates (tenant_id, name, locale, subject, body, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (tenant_id, name, locale) DO UPDATE
+		SET subject = $4, body = $5, updated_on = $6
+	`, s.tenant.ID, template.Name, template.Locale, template.Subject, template.Body, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save email template '%s'", template.Name)
+	}
+	return s.Get(template.Name, template.Locale)
+}
+
+// Delete removes the customized template with given name and locale
+func (s *EmailTemplateStorage) Delete(name, locale string) error {
+	_, err := s.trx.Execute(`
+		DELETE FROM email_templates WHERE tenant_id = $1 AND name = $2 AND locale = $3
+	`, s.tenant.ID, name, locale)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete email template '%s'", name)
+	}
+	return nil
+}
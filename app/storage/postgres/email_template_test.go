@@ -0,0 +1,67 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestEmailTemplateStorage_SaveAndGet(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	emailTemplates.SetCurrentTenant(demoTenant)
+	template, err := emailTemplates.Save(&models.SaveEmailTemplate{
+		Name:    "new_idea",
+		Locale:  "pt-BR",
+		Subject: "Nova ideia: {{ .title }}",
+		Body:    "{{ .content }}",
+	})
+	Expect(err).IsNil()
+	Expect(template.ID).NotEquals(0)
+
+	template, err = emailTemplates.Save(&models.SaveEmailTemplate{
+		Name:    "new_idea",
+		Locale:  "pt-BR",
+		Subject: "Nova sugestão: {{ .title }}",
+		Body:    "{{ .content }}",
+	})
+	Expect(err).IsNil()
+
+	dbTemplate, err := emailTemplates.Get("new_idea", "pt-BR")
+	Expect(err).IsNil()
+	Expect(dbTemplate.ID).Equals(template.ID)
+	Expect(dbTemplate.Subject).Equals("Nova sugestão: {{ .title }}")
+	Expect(dbTemplate.Body).Equals("{{ .content }}")
+
+	all, err := emailTemplates.GetAll()
+	Expect(err).IsNil()
+	Expect(all).HasLen(1)
+
+	emailTemplates.SetCurrentTenant(avengersTenant)
+	dbTemplate, err = emailTemplates.Get("new_idea", "pt-BR")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(dbTemplate).IsNil()
+}
+
+func TestEmailTemplateStorage_Delete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	emailTemplates.SetCurrentTenant(demoTenant)
+	emailTemplates.Save(&models.SaveEmailTemplate{
+		Name:    "signin_email",
+		Locale:  "en",
+		Subject: "Sign in",
+		Body:    "{{ .link }}",
+	})
+
+	err := emailTemplates.Delete("signin_email", "en")
+	Expect(err).IsNil()
+
+	_, err = emailTemplates.Get("signin_email", "en")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
@@ -36,6 +36,7 @@ type dbIdea struct {
 	OriginalSlug     sql.NullString `db:"original_slug"`
 	OriginalStatus   sql.NullInt64  `db:"original_status"`
 	Tags             []string       `db:"tags"`
+	IsPrivate        bool           `db:"is_private"`
 }
 
 func (i *dbIdea) toModel() *models.Idea {
@@ -52,6 +53,7 @@ func (i *dbIdea) toModel() *models.Idea {
 		Status:          i.Status,
 		User:            i.User.toModel(),
 		Tags:            i.Tags,
+		IsPrivate:       i.IsPrivate,
 	}
 
 	if i.Response.Valid {
@@ -161,6 +163,7 @@ var (
 																COALESCE(agg_s.recent, 0) AS recent_supporters,
 																COALESCE(agg_c.recent, 0) AS recent_comments,																
 																i.status, 
+																i.is_private,
 																u.id AS user_id, 
 																u.name AS user_name, 
 																u.email AS user_email,
@@ -203,12 +206,22 @@ func (s *IdeaStorage) getIdeaQuery(filter string) string {
 		viewerSupportedSubQuery = fmt.Sprintf("(SELECT true FROM idea_supporters WHERE idea_id = i.id AND user_id = %d)", s.user.ID)
 	}
 	tagCondition := `AND t.is_public = true`
-	if s.user != nil && s.user.IsCollaborator() {
+	if s.user != nil && s.user.Can(models.PermissionViewPrivate) {
 		tagCondition = ``
 	}
 	return fmt.Sprintf(sqlSelectIdeasWhere, viewerSupportedSubQuery, tagCondition, filter)
 }
 
+func (s *IdeaStorage) getPrivacyCondition() string {
+	if s.user == nil {
+		return `AND i.is_private = false`
+	}
+	if s.user.Can(models.PermissionViewPrivate) {
+		return ``
+	}
+	return fmt.Sprintf(`AND (i.is_private = false OR i.user_id = %d)`, s.user.ID)
+}
+
 func (s *IdeaStorage) getSingle(query string, args ...interface{}) (*models.Idea, error) {
 	idea := dbIdea{}
 
@@ -237,9 +250,9 @@ func (s *IdeaStorage) GetBySlug(slug string) (*models.Idea, error) {
 	return idea, nil
 }
 
-// GetByNumber returns idea by tenant and number
+// GetByNumber returns idea by tenant and number, as long as it's visible to current user
 func (s *IdeaStorage) GetByNumber(number int) (*models.Idea, error) {
-	idea, err := s.getSingle(s.getIdeaQuery("i.tenant_id = $1 AND i.number = $2"), s.tenant.ID, number)
+	idea, err := s.getSingle(s.getIdeaQuery("i.tenant_id = $1 AND i.number = $2 "+s.getPrivacyCondition()), s.tenant.ID, number)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get idea with number '%d'", number)
 	}
@@ -258,7 +271,7 @@ func (s *IdeaStorage) GetAll() ([]*models.Idea, error) {
 // CountPerStatus returns total number of ideas per status
 func (s *IdeaStorage) CountPerStatus() (map[int]int, error) {
 	stats := []*dbStatusCount{}
-	err := s.trx.Select(&stats, "SELECT status, COUNT(*) AS count FROM ideas WHERE tenant_id = $1 GROUP BY status", s.tenant.ID)
+	err := s.trx.Select(&stats, "SELECT status, COUNT(*) AS count FROM ideas i WHERE tenant_id = $1 "+s.getPrivacyCondition()+" GROUP BY status", s.tenant.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to count ideas per status")
 	}
@@ -271,7 +284,7 @@ func (s *IdeaStorage) CountPerStatus() (map[int]int, error) {
 
 // Search existing ideas based on input
 func (s *IdeaStorage) Search(query, filter string, tags []string) ([]*models.Idea, error) {
-	innerQuery := s.getIdeaQuery("i.tenant_id = $1 AND i.status = ANY($2)")
+	innerQuery := s.getIdeaQuery("i.tenant_id = $1 AND i.status = ANY($2) " + s.getPrivacyCondition())
 
 	var (
 		ideas []*dbIdea
@@ -450,6 +463,15 @@ func (s *IdeaStorage) UpdateComment(id int, content string) error {
 	return nil
 }
 
+// DeleteComment with given ID
+func (s *IdeaStorage) DeleteComment(id int) error {
+	_, err := s.trx.Execute("DELETE FROM comments WHERE id = $1 AND tenant_id = $2", id, s.tenant.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed delete comment")
+	}
+	return nil
+}
+
 // AddSupporter adds user to idea list of supporters
 func (s *IdeaStorage) AddSupporter(idea *models.Idea, user *models.User) error {
 	if !idea.CanBeSupported() {
@@ -541,7 +563,7 @@ func (s *IdeaStorage) GetActiveSubscribers(number int, channel models.Notificati
 
 	if len(event.RequiresSubscripionUserRoles) == 0 {
 		err = s.trx.Select(&users, `
-			SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role
+			SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.locale, u.custom_role_id
 			FROM users u
 			LEFT JOIN user_settings set
 			ON set.user_id = u.id
@@ -559,7 +581,7 @@ func (s *IdeaStorage) GetActiveSubscribers(number int, channel models.Notificati
 		)
 	} else {
 		err = s.trx.Select(&users, `
-			SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role
+			SELECT DISTINCT u.id, u.name, u.email, u.tenant_id, u.role, u.locale, u.custom_role_id
 			FROM users u
 			LEFT JOIN idea_subscribers sub
 			ON sub.user_id = u.id
@@ -589,9 +611,17 @@ func (s *IdeaStorage) GetActiveSubscribers(number int, channel models.Notificati
 		return nil, errors.Wrap(err, "failed to get idea number '%d' subscribers", number)
 	}
 
-	var result = make([]*models.User, len(users))
-	for i, user := range users {
-		result[i] = user.toModel()
+	all, err := withCustomRoles(s.trx, s.tenant, users)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get idea number '%d' subscribers", number)
+	}
+
+	//Custom roles are required here, as they may not grant access to private ideas
+	var result = make([]*models.User, 0, len(all))
+	for _, u := range all {
+		if idea.IsVisibleTo(u) {
+			result = append(result, u)
+		}
 	}
 	return result, nil
 }
@@ -682,6 +712,17 @@ func (s *IdeaStorage) IsReferenced(idea *models.Idea) (bool, error) {
 	return exists, nil
 }
 
+// SetPrivacy changes whether given idea is only visible to its author and staff
+func (s *IdeaStorage) SetPrivacy(idea *models.Idea, isPrivate bool) error {
+	_, err := s.trx.Execute(`UPDATE ideas SET is_private = $3 WHERE id = $1 AND tenant_id = $2`, idea.ID, s.tenant.ID, isPrivate)
+	if err != nil {
+		return errors.Wrap(err, "failed to change idea privacy")
+	}
+
+	idea.IsPrivate = isPrivate
+	return nil
+}
+
 // SupportedBy returns a list of Idea ID supported by given user
 func (s *IdeaStorage) SupportedBy() ([]int, error) {
 	ideas, err := s.trx.QueryIntArray("SELECT idea_id FROM idea_supporters WHERE user_id = $1 AND tenant_id = $2", s.user.ID, s.tenant.ID)
@@ -152,6 +152,28 @@ func TestIdeaStorage_AddGetUpdateComment(t *testing.T) {
 	Expect(comment.EditedBy.ID).Equals(aryaStark.ID)
 }
 
+func TestIdeaStorage_DeleteComment(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	ideas.SetCurrentTenant(demoTenant)
+	ideas.SetCurrentUser(jonSnow)
+	idea, _ := ideas.Add("My new idea", "with this description")
+	commentId, _ := ideas.AddComment(idea, "Comment #1")
+	ideas.AddComment(idea, "Comment #2")
+
+	err := ideas.DeleteComment(commentId)
+	Expect(err).IsNil()
+
+	_, err = ideas.GetCommentByID(commentId)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	comments, err := ideas.GetCommentsByIdea(idea)
+	Expect(err).IsNil()
+	Expect(comments).HasLen(1)
+	Expect(comments[0].Content).Equals("Comment #2")
+}
+
 func TestIdeaStorage_AddAndGet_DifferentTenants(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -502,3 +524,38 @@ func TestIdeaStorage_IsReferenced(t *testing.T) {
 	Expect(referenced).IsTrue()
 	Expect(err).IsNil()
 }
+
+func TestIdeaStorage_PrivateIdeas(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	ideas.SetCurrentTenant(demoTenant)
+	ideas.SetCurrentUser(aryaStark)
+	public, _ := ideas.Add("My public idea", "Public details")
+	private, _ := ideas.Add("My confidential idea", "Secret details")
+	err := ideas.SetPrivacy(private, true)
+	Expect(err).IsNil()
+
+	dbIdeas, err := ideas.GetAll()
+	Expect(err).IsNil()
+	Expect(dbIdeas).HasLen(2)
+
+	ideas.SetCurrentUser(nil)
+	dbIdeas, err = ideas.GetAll()
+	Expect(err).IsNil()
+	Expect(dbIdeas).HasLen(1)
+	Expect(dbIdeas[0].ID).Equals(public.ID)
+
+	stats, err := ideas.CountPerStatus()
+	Expect(err).IsNil()
+	Expect(stats[models.IdeaOpen]).Equals(1)
+
+	dbIdea, err := ideas.GetByNumber(private.Number)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(dbIdea).IsNil()
+
+	ideas.SetCurrentUser(jonSnow)
+	dbIdea, err = ideas.GetByNumber(private.Number)
+	Expect(err).IsNil()
+	Expect(dbIdea.IsPrivate).IsTrue()
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbPage struct {
+	ID         int       `db:"id"`
+	Slug       string    `db:"slug"`
+	Title      string    `db:"title"`
+	Content    string    `db:"content"`
+	Visibility int       `db:"visibility"`
+	Placement  int       `db:"placement"`
+	UpdatedOn  time.Time `db:"updated_on"`
+}
+
+func (p *dbPage) toModel() *models.Page {
+	return &models.Page{
+		ID:         p.ID,
+		Slug:       p.Slug,
+		Title:      p.Title,
+		Content:    p.Content,
+		Visibility: p.Visibility,
+		Placement:  p.Placement,
+		UpdatedOn:  p.UpdatedOn,
+	}
+}
+
+// PageStorage contains read and write operations for pages authored by tenants
+type PageStorage struct {
+	trx    *dbx.Trx
+	tenant *models.Tenant
+	user   *models.User
+}
+
+// NewPageStorage creates a new PageStorage
+func NewPageStorage(trx *dbx.Trx) *PageStorage {
+	return &PageStorage{
+		trx: trx,
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *PageStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *PageStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// GetAll returns all pages of current tenant
+func (s *PageStorage) GetAll() ([]*models.Page, error) {
+	pages := []*dbPage{}
+	err := s.trx.Select(&pages, `
+		SELECT id, slug, title, content, visibility, placement, updated_on
+		FROM pages
+		WHERE tenant_id = $1
+		ORDER BY title
+	`, s.tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get all pages")
+	}
+
+	var result = make([]*models.Page, len(pages))
+	for i, page := range pages {
+		result[i] = page.toModel()
+	}
+	return result, nil
+}
+
+// GetNavigation returns the pages of current tenant that are linked from the navigation, without their content
+func (s *PageStorage) GetNavigation() ([]*models.Page, error) {
+	pages := []*dbPage{}
+	err := s.trx.Select(&pages, `
+		SELECT id, slug, title, visibility, placement, updated_on
+		FROM pages
+		WHERE tenant_id = $1 AND placement <> $2
+		ORDER BY title
+	`, s.tenant.ID, models.PagePlacementNone)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get navigation pages")
+	}
+
+	var result = make([]*models.Page, len(pages))
+	for i, page := range pages {
+		result[i] = page.toModel()
+	}
+	return result, nil
+}
+
+// GetBySlug returns the page with given slug
+func (s *PageStorage) GetBySlug(slug string) (*models.Page, error) {
+	page := dbPage{}
+	err := s.trx.Get(&page, `
+		SELECT id, slug, title, content, visibility, placement, updated_on
+		FROM pages
+		WHERE tenant_id = $1 AND slug = $2
+	`, s.tenant.ID, slug)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get page with slug '%s'", slug)
+	}
+	return page.toModel(), nil
+}
+
+// Add creates a new page
+func (s *PageStorage) Add(input *models.SavePage) (*models.Page, error) {
+	now := time.Now()
+	_, err := s.trx.Execute(`
+		INSERT INTO pages (tenant_id, slug, title, content, visibility, placement, created_on, updated_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	`, s.tenant.ID, input.Slug, input.Title, input.Content, input.Visibility, input.Placement, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add page '%s'", input.Slug)
+	}
+	return s.GetBySlug(input.Slug)
+}
+
+// Update given page
+func (s *PageStorage) Update(page *models.Page, input *models.SavePage) (*models.Page, error) {
+	_, err := s.trx.Execute(`
+		UPDATE pages
+		SET slug = $3, title = $4, content = $5, visibility = $6, placement = $7, updated_on = $8
+		WHERE id = $1 AND tenant_id = $2
+	`, page.ID, s.tenant.ID, input.Slug, input.Title, input.Content, input.Visibility, input.Placement, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update page '%s'", page.Slug)
+	}
+	return s.GetBySlug(input.Slug)
+}
+
+// Delete given page
+func (s *PageStorage) Delete(page *models.Page) error {
+	_, err := s.trx.Execute("DELETE FROM pages WHERE id = $1 AND tenant_id = $2", page.ID, s.tenant.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete page '%s'", page.Slug)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/getfider/fider/app"
+	"github.com/getfider/fider/app/models"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestPageStorage_AddAndGet(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	pages.SetCurrentTenant(demoTenant)
+	page, err := pages.Add(&models.SavePage{
+		Slug:       "terms",
+		Title:      "Terms of Use",
+		Content:    "# Terms",
+		Visibility: models.PageVisibilityPublic,
+		Placement:  models.PagePlacementFooter,
+	})
+	Expect(err).IsNil()
+	Expect(page.ID).NotEquals(0)
+
+	dbPage, err := pages.GetBySlug("terms")
+	Expect(err).IsNil()
+	Expect(dbPage.ID).Equals(page.ID)
+	Expect(dbPage.Title).Equals("Terms of Use")
+	Expect(dbPage.Content).Equals("# Terms")
+	Expect(dbPage.Visibility).Equals(models.PageVisibilityPublic)
+	Expect(dbPage.Placement).Equals(models.PagePlacementFooter)
+
+	pages.SetCurrentTenant(avengersTenant)
+	_, err = pages.GetBySlug("terms")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestPageStorage_GetNavigation(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	pages.SetCurrentTenant(demoTenant)
+	pages.Add(&models.SavePage{Slug: "terms", Title: "Terms of Use", Content: "# Terms", Visibility: models.PageVisibilityPublic, Placement: models.PagePlacementFooter})
+	pages.Add(&models.SavePage{Slug: "faq", Title: "FAQ", Content: "# FAQ", Visibility: models.PageVisibilityMembers, Placement: models.PagePlacementHeader})
+	pages.Add(&models.SavePage{Slug: "draft", Title: "Draft", Content: "# Draft", Visibility: models.PageVisibilityPublic, Placement: models.PagePlacementNone})
+
+	navigation, err := pages.GetNavigation()
+	Expect(err).IsNil()
+	Expect(navigation).HasLen(2)
+	Expect(navigation[0].Slug).Equals("faq")
+	Expect(navigation[0].Title).Equals("FAQ")
+	Expect(navigation[0].Visibility).Equals(models.PageVisibilityMembers)
+	Expect(navigation[0].Placement).Equals(models.PagePlacementHeader)
+	Expect(navigation[0].Content).Equals("")
+	Expect(navigation[1].Slug).Equals("terms")
+
+	pages.SetCurrentTenant(avengersTenant)
+	navigation, err = pages.GetNavigation()
+	Expect(err).IsNil()
+	Expect(navigation).HasLen(0)
+}
+
+func TestPageStorage_UpdateAndDelete(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	pages.SetCurrentTenant(demoTenant)
+	page, _ := pages.Add(&models.SavePage{Slug: "faq", Title: "FAQ", Visibility: models.PageVisibilityDraft})
+	page, err := pages.Update(page, &models.SavePage{
+		Slug:       "how-we-prioritize",
+		Title:      "How we prioritize",
+		Content:    "Votes!",
+		Visibility: models.PageVisibilityMembers,
+		Placement:  models.PagePlacementHeader,
+	})
+	Expect(err).IsNil()
+	Expect(page.Slug).Equals("how-we-prioritize")
+
+	all, err := pages.GetAll()
+	Expect(err).IsNil()
+	Expect(all).HasLen(1)
+	Expect(all[0].Title).Equals("How we prioritize")
+	Expect(all[0].Visibility).Equals(models.PageVisibilityMembers)
+
+	err = pages.Delete(page)
+	Expect(err).IsNil()
+
+	_, err = pages.GetBySlug("how-we-prioritize")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/getfider/fider/app/models"
+	"github.com/getfider/fider/app/pkg/dbx"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+type dbSession struct {
+	ID         string    `db:"id"`
+	UserID     int       `db:"user_id"`
+	UserAgent  string    `db:"user_agent"`
+	IPAddress  string    `db:"ip_address"`
+	CreatedOn  time.Time `db:"created_on"`
+	LastSeenOn time.Time `db:"last_seen_on"`
+	ExpiresOn  time.Time `db:"expires_on"`
+	CSRFToken  string    `db:"csrf_token"`
+}
+
+func (s *dbSession) toModel() *models.Session {
+	return &models.Session{
+		ID:         s.ID,
+		UserID:     s.UserID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedOn:  s.CreatedOn,
+		LastSeenOn: s.LastSeenOn,
+		ExpiresOn:  s.ExpiresOn,
+		CSRFToken:  s.CSRFToken,
+	}
+}
+
+// SessionStorage contains read and write operations for user sessions
+type SessionStorage struct {
+	trx    *dbx.Trx
+	tenant *models.Tenant
+	user   *models.User
+}
+
+// NewSessionStorage creates a new SessionStorage
+func NewSessionStorage(trx *dbx.Trx) *SessionStorage {
+	return &SessionStorage{
+		trx: trx,
+	}
+}
+
+// SetCurrentTenant to current context
+func (s *SessionStorage) SetCurrentTenant(tenant *models.Tenant) {
+	s.tenant = tenant
+}
+
+// SetCurrentUser to current context
+func (s *SessionStorage) SetCurrentUser(user *models.User) {
+	s.user = user
+}
+
+// Create a new session for given user
+func (s *SessionStorage) Create(user *models.User, userAgent, ipAddress string, expiresOn time.Time) (*models.Session, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         models.GenerateVerificationKey(),
+		UserID:     user.ID,
+		UserAgent:  truncate(userAgent, 300),
+		IPAddress:  truncate(ipAddress, 50),
+		CreatedOn:  now,
+		LastSeenOn: now,
+		ExpiresOn:  expiresOn,
+		CSRFToken:  models.GenerateVerificationKey(),
+	}
+
+	_, err := s.trx.Execute(`
+		INSERT INTO user_sessions (id, tenant_id, user_id, user_agent, ip_address, created_on, last_seen_on, expires_on, csrf_token)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, session.ID, s.tenant.ID, session.UserID, session.UserAgent, session.IPAddress, session.CreatedOn, session.LastSeenOn, session.ExpiresOn, session.CSRFToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create session for user with id '%d'", user.ID)
+	}
+	return session, nil
+}
+
+// GetByID returns an active session of current tenant
+func (s *SessionStorage) GetByID(id string) (*models.Session, error) {
+	session := dbSession{}
+	err := s.trx.Get(&session, `
+		SELECT id, user_id, user_agent, ip_address, created_on, last_seen_on, expires_on, csrf_token
+		FROM user_sessions
+		WHERE id = $1 AND tenant_id = $2 AND revoked_on IS NULL AND expires_on > $3
+	`, id, s.tenant.ID, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get session")
+	}
+	return session.toModel(), nil
+}
+
+// GetActiveByUser returns all active sessions of given user, most recently used first
+func (s *SessionStorage) GetActiveByUser(userID int) ([]*models.Session, error) {
+	sessions := []*dbSession{}
+	err := s.trx.Select(&sessions, `
+		SELECT id, user_id, user_agent, ip_address, created_on, last_seen_on, expires_on, csrf_token
+		FROM user_sessions
+		WHERE user_id = $1 AND tenant_id = $2 AND revoked_on IS NULL AND expires_on > $3
+		ORDER BY last_seen_on DESC
+	`, userID, s.tenant.ID, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sessions of user with id '%d'", userID)
+	}
+
+	var result = make([]*models.Session, len(sessions))
+	for i, session := range sessions {
+		result[i] = session.toModel()
+	}
+	return result, nil
+}
+
+// Touch marks given session as used now
+func (s *SessionStorage) Touch(id string) error {
+	_, err := s.trx.Execute(
+		"UPDATE user_sessions SET last_seen_on = $3 WHERE id = $1 AND tenant_id = $2",
+		id, s.tenant.ID, time.Now(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to touch session")
+	}
+	return nil
+}
+
+// Revoke given session of given user
+func (s *SessionStorage) Revoke(userID int, id string) error {
+	_, err := s.trx.Execute(
+		"UPDATE user_sessions SET revoked_on = $4 WHERE id = $1 AND user_id = $2 AND tenant_id = $3 AND revoked_on IS NULL",
+		id, userID, s.tenant.ID, time.Now(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke session of user with id '%d'", userID)
+	}
+	return nil
+}
+
+// RevokeAll sessions of given user
+func (s *SessionStorage) RevokeAll(userID int) error {
+	_, err := s.trx.Execute(
+		"UPDATE user_sessions SET revoked_on = $3 WHERE user_id = $1 AND tenant_id = $2 AND revoked_on IS NULL",
+		userID, s.tenant.ID, time.Now(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke all sessions of user with id '%d'", userID)
+	}
+	return nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) > length {
+		return value[:length]
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getfider/fider/app"
+	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/errors"
+)
+
+func TestSessionStorage_CreateAndGet(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	sessions.SetCurrentTenant(demoTenant)
+	session, err := sessions.Create(jonSnow, "Mozilla/5.0", "127.0.0.1", time.Now().Add(time.Hour))
+	Expect(err).IsNil()
+	Expect(session.ID).IsNotEmpty()
+	Expect(session.CSRFToken).IsNotEmpty()
+	Expect(session.CSRFToken).NotEquals(session.ID)
+
+	dbSession, err := sessions.GetByID(session.ID)
+	Expect(err).IsNil()
+	Expect(dbSession.UserID).Equals(jonSnow.ID)
+	Expect(dbSession.UserAgent).Equals("Mozilla/5.0")
+	Expect(dbSession.IPAddress).Equals("127.0.0.1")
+	Expect(dbSession.CSRFToken).Equals(session.CSRFToken)
+
+	sessions.SetCurrentTenant(avengersTenant)
+	dbSession, err = sessions.GetByID(session.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(dbSession).IsNil()
+}
+
+func TestSessionStorage_Expired(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	sessions.SetCurrentTenant(demoTenant)
+	session, _ := sessions.Create(jonSnow, "Mozilla/5.0", "127.0.0.1", time.Now().Add(-time.Hour))
+
+	_, err := sessions.GetByID(session.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestSessionStorage_Revoke(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	sessions.SetCurrentTenant(demoTenant)
+	session1, _ := sessions.Create(jonSnow, "Chrome", "127.0.0.1", time.Now().Add(time.Hour))
+	session2, _ := sessions.Create(jonSnow, "Firefox", "127.0.0.1", time.Now().Add(time.Hour))
+	session3, _ := sessions.Create(aryaStark, "Safari", "127.0.0.1", time.Now().Add(time.Hour))
+
+	active, err := sessions.GetActiveByUser(jonSnow.ID)
+	Expect(err).IsNil()
+	Expect(active).HasLen(2)
+
+	//Sessions can only be revoked by their owners
+	err = sessions.Revoke(aryaStark.ID, session1.ID)
+	Expect(err).IsNil()
+	_, err = sessions.GetByID(session1.ID)
+	Expect(err).IsNil()
+
+	err = sessions.Revoke(jonSnow.ID, session1.ID)
+	Expect(err).IsNil()
+	_, err = sessions.GetByID(session1.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = sessions.RevokeAll(jonSnow.ID)
+	Expect(err).IsNil()
+	_, err = sessions.GetByID(session2.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	_, err = sessions.GetByID(session3.ID)
+	Expect(err).IsNil()
+
+	active, err = sessions.GetActiveByUser(jonSnow.ID)
+	Expect(err).IsNil()
+	Expect(active).HasLen(0)
+}
@@ -18,6 +18,10 @@ var users *postgres.UserStorage
 var ideas *postgres.IdeaStorage
 var tags *postgres.TagStorage
 var notifications *postgres.NotificationStorage
+var emailTemplates *postgres.EmailTemplateStorage
+var pages *postgres.PageStorage
+var emailLog *postgres.EmailLogStorage
+var sessions *postgres.SessionStorage
 
 var demoTenant *models.Tenant
 var avengersTenant *models.Tenant
@@ -36,6 +40,10 @@ func SetupDatabaseTest(t *testing.T) {
 	ideas = postgres.NewIdeaStorage(trx)
 	tags = postgres.NewTagStorage(trx)
 	notifications = postgres.NewNotificationStorage(trx)
+	emailTemplates = postgres.NewEmailTemplateStorage(trx)
+	pages = postgres.NewPageStorage(trx)
+	emailLog = postgres.NewEmailLogStorage(trx)
+	sessions = postgres.NewSessionStorage(trx)
 
 	demoTenant, _ = tenants.GetByDomain("demo")
 	avengersTenant, _ = tenants.GetByDomain("avengers")
@@ -288,3 +288,64 @@ func TestSubscription_DisabledEverything(t *testing.T) {
 	Expect(err).IsNil()
 	Expect(subscribers).HasLen(0)
 }
+
+func TestSubscription_PrivateIdea_CustomRole(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	ideas.SetCurrentTenant(demoTenant)
+	ideas.SetCurrentUser(jonSnow)
+	users.SetCurrentTenant(demoTenant)
+	tenants.SetCurrentTenant(demoTenant)
+
+	idea1, _ := ideas.Add("Idea #1", "Description #1")
+	Expect(ideas.SetPrivacy(idea1, true)).IsNil()
+	Expect(users.ChangeRole(aryaStark.ID, models.RoleCollaborator)).IsNil()
+	Expect(ideas.AddSubscriber(idea1, aryaStark)).IsNil()
+
+	subscribers, err := ideas.GetActiveSubscribers(idea1.Number, models.NotificationChannelEmail, models.NotificationEventNewComment)
+	Expect(err).IsNil()
+	Expect(subscribers).HasLen(2)
+	Expect(subscribers[0].ID + subscribers[1].ID).Equals(jonSnow.ID + aryaStark.ID)
+
+	moderator, _ := tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Moderator",
+		Permissions: []models.Permission{models.PermissionModerateComments},
+	})
+	Expect(users.AssignCustomRole(aryaStark.ID, moderator)).IsNil()
+
+	for _, event := range []models.NotificationEvent{
+		models.NotificationEventNewIdea,
+		models.NotificationEventNewComment,
+		models.NotificationEventChangeStatus,
+	} {
+		subscribers, err = ideas.GetActiveSubscribers(idea1.Number, models.NotificationChannelEmail, event)
+		Expect(err).IsNil()
+		Expect(subscribers).HasLen(1)
+		Expect(subscribers[0].ID).Equals(jonSnow.ID)
+	}
+}
+
+func TestSubscription_LocaleOfSubscribers(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	ideas.SetCurrentTenant(demoTenant)
+	ideas.SetCurrentUser(aryaStark)
+	users.SetCurrentTenant(demoTenant)
+	users.SetCurrentUser(aryaStark)
+	Expect(users.Update(&models.UpdateUserSettings{Name: "Arya Stark", Locale: "pt-BR"})).IsNil()
+
+	idea1, _ := ideas.Add("Idea #1", "Description #1")
+
+	subscribers, err := ideas.GetActiveSubscribers(idea1.Number, models.NotificationChannelEmail, models.NotificationEventNewComment)
+	Expect(err).IsNil()
+	Expect(subscribers).HasLen(2)
+	for _, subscriber := range subscribers {
+		if subscriber.ID == aryaStark.ID {
+			Expect(subscriber.Locale).Equals("pt-BR")
+		} else {
+			Expect(subscriber.Locale).Equals("")
+		}
+	}
+}
@@ -162,7 +162,7 @@ func (s *TagStorage) GetAssigned(idea *models.Idea) ([]*models.Tag, error) {
 // GetAll returns all tags
 func (s *TagStorage) GetAll() ([]*models.Tag, error) {
 	condition := `AND t.is_public = true`
-	if s.user != nil && s.user.IsCollaborator() {
+	if s.user != nil && s.user.Can(models.PermissionViewPrivate) {
 		condition = ``
 	}
 
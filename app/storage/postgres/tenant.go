@@ -11,18 +11,66 @@ import (
 	"github.com/getfider/fider/app/pkg/dbx"
 	"github.com/getfider/fider/app/pkg/env"
 	"github.com/getfider/fider/app/pkg/errors"
+	"github.com/lib/pq"
 )
 
+const tenantColumns = `id, name, subdomain, cname, invitation, welcome_message, status, is_private, logo_id, locale,
+	email_from_address, email_reply_to, email_verified_domain, email_verified_address, email_verification_token,
+	email_smtp_host, email_smtp_port, email_smtp_username, email_smtp_password,
+	email_dkim_selector, email_dkim_private_key,
+	saml_enabled, saml_enforced, saml_idp_entity_id, saml_idp_sso_url, saml_idp_certificate,
+	saml_name_attribute, saml_email_attribute, saml_role_attribute,
+	saml_admin_role_value, saml_collaborator_role_value,
+	sso_jwt_enabled, sso_jwt_secret, sso_jwt_public_key, two_factor_required,
+	auto_join_domains, auto_join_role, deletion_scheduled_on, plan,
+	primary_color, accent_color, custom_css, favicon_id, header_id, embed_origins`
+
 type dbTenant struct {
-	ID             int         `db:"id"`
-	Name           string      `db:"name"`
-	Subdomain      string      `db:"subdomain"`
-	CNAME          string      `db:"cname"`
-	Invitation     string      `db:"invitation"`
-	WelcomeMessage string      `db:"welcome_message"`
-	Status         int         `db:"status"`
-	IsPrivate      bool        `db:"is_private"`
-	LogoID         dbx.NullInt `db:"logo_id"`
+	ID                     int          `db:"id"`
+	Name                   string       `db:"name"`
+	Subdomain              string       `db:"subdomain"`
+	CNAME                  string       `db:"cname"`
+	Invitation             string       `db:"invitation"`
+	WelcomeMessage         string       `db:"welcome_message"`
+	Status                 int          `db:"status"`
+	IsPrivate              bool         `db:"is_private"`
+	LogoID                 dbx.NullInt  `db:"logo_id"`
+	Locale                 string       `db:"locale"`
+	EmailFromAddress       string       `db:"email_from_address"`
+	EmailReplyTo           string       `db:"email_reply_to"`
+	EmailVerifiedDomain    string       `db:"email_verified_domain"`
+	EmailVerifiedAddress   string       `db:"email_verified_address"`
+	EmailVerificationToken string       `db:"email_verification_token"`
+	EmailSMTPHost          string       `db:"email_smtp_host"`
+	EmailSMTPPort          string       `db:"email_smtp_port"`
+	EmailSMTPUsername      string       `db:"email_smtp_username"`
+	EmailSMTPPassword      string       `db:"email_smtp_password"`
+	EmailDKIMSelector      string       `db:"email_dkim_selector"`
+	EmailDKIMPrivateKey    string       `db:"email_dkim_private_key"`
+	SAMLEnabled            bool         `db:"saml_enabled"`
+	SAMLEnforced           bool         `db:"saml_enforced"`
+	SAMLIdPEntityID        string       `db:"saml_idp_entity_id"`
+	SAMLIdPSSOURL          string       `db:"saml_idp_sso_url"`
+	SAMLIdPCertificate     string       `db:"saml_idp_certificate"`
+	SAMLNameAttribute      string       `db:"saml_name_attribute"`
+	SAMLEmailAttribute     string       `db:"saml_email_attribute"`
+	SAMLRoleAttribute      string       `db:"saml_role_attribute"`
+	SAMLAdminRoleValue     string       `db:"saml_admin_role_value"`
+	SAMLCollabRoleValue    string       `db:"saml_collaborator_role_value"`
+	JWTSSOEnabled          bool         `db:"sso_jwt_enabled"`
+	JWTSSOSecret           string       `db:"sso_jwt_secret"`
+	JWTSSOPublicKey        string       `db:"sso_jwt_public_key"`
+	TwoFactorRequired      bool         `db:"two_factor_required"`
+	AutoJoinDomains        []string     `db:"auto_join_domains"`
+	AutoJoinRole           models.Role  `db:"auto_join_role"`
+	DeletionScheduledOn    dbx.NullTime `db:"deletion_scheduled_on"`
+	Plan                   string       `db:"plan"`
+	PrimaryColor           string       `db:"primary_color"`
+	AccentColor            string       `db:"accent_color"`
+	CustomCSS              string       `db:"custom_css"`
+	FaviconID              dbx.NullInt  `db:"favicon_id"`
+	HeaderID               dbx.NullInt  `db:"header_id"`
+	EmbedOrigins           []string     `db:"embed_origins"`
 }
 
 func (t *dbTenant) toModel() *models.Tenant {
@@ -39,12 +87,71 @@ func (t *dbTenant) toModel() *models.Tenant {
 		WelcomeMessage: t.WelcomeMessage,
 		Status:         t.Status,
 		IsPrivate:      t.IsPrivate,
+		Locale:         t.Locale,
+		Email: models.TenantEmailSettings{
+			FromAddress:       t.EmailFromAddress,
+			ReplyTo:           t.EmailReplyTo,
+			VerifiedDomain:    t.EmailVerifiedDomain,
+			VerifiedAddress:   t.EmailVerifiedAddress,
+			VerificationToken: t.EmailVerificationToken,
+			SMTPHost:          t.EmailSMTPHost,
+			SMTPPort:          t.EmailSMTPPort,
+			SMTPUsername:      t.EmailSMTPUsername,
+			SMTPPassword:      t.EmailSMTPPassword,
+			DKIMSelector:      t.EmailDKIMSelector,
+			DKIMPrivateKey:    t.EmailDKIMPrivateKey,
+		},
+		SAML: models.TenantSAMLSettings{
+			IsEnabled:              t.SAMLEnabled,
+			IsEnforced:             t.SAMLEnforced,
+			IdPEntityID:            t.SAMLIdPEntityID,
+			IdPSSOURL:              t.SAMLIdPSSOURL,
+			IdPCertificate:         t.SAMLIdPCertificate,
+			NameAttribute:          t.SAMLNameAttribute,
+			EmailAttribute:         t.SAMLEmailAttribute,
+			RoleAttribute:          t.SAMLRoleAttribute,
+			AdministratorRoleValue: t.SAMLAdminRoleValue,
+			CollaboratorRoleValue:  t.SAMLCollabRoleValue,
+		},
+		JWTSSO: models.TenantJWTSSOSettings{
+			IsEnabled: t.JWTSSOEnabled,
+			Secret:    t.JWTSSOSecret,
+			PublicKey: t.JWTSSOPublicKey,
+		},
+		AutoJoin: models.TenantAutoJoinSettings{
+			Domains: t.AutoJoinDomains,
+			Role:    t.AutoJoinRole,
+		},
+		Theme: models.TenantTheme{
+			PrimaryColor: t.PrimaryColor,
+			AccentColor:  t.AccentColor,
+			CustomCSS:    t.CustomCSS,
+		},
+		EmbedOrigins:        t.EmbedOrigins,
+		IsTwoFactorRequired: t.TwoFactorRequired,
 	}
 
 	if t.LogoID.Valid {
 		tenant.LogoID = int(t.LogoID.Int64)
 	}
 
+	if t.FaviconID.Valid {
+		tenant.Theme.FaviconID = int(t.FaviconID.Int64)
+	}
+
+	if t.HeaderID.Valid {
+		tenant.Theme.HeaderID = int(t.HeaderID.Int64)
+	}
+
+	if t.DeletionScheduledOn.Valid {
+		tenant.DeletionScheduledOn = &t.DeletionScheduledOn.Time
+	}
+
+	tenant.Plan = models.GetPlan(t.Plan)
+	if tenant.Plan == nil {
+		tenant.Plan = models.PlanUnlimited
+	}
+
 	return tenant
 }
 
@@ -62,6 +169,7 @@ type dbEmailVerification struct {
 
 func (t *dbEmailVerification) toModel() *models.EmailVerification {
 	model := &models.EmailVerification{
+		ID:         t.ID,
 		Name:       t.Name,
 		Email:      t.Email,
 		Key:        t.Key,
@@ -108,8 +216,8 @@ func (s *TenantStorage) SetCurrentUser(user *models.User) {
 func (s *TenantStorage) Add(name string, subdomain string, status int) (*models.Tenant, error) {
 	var id int
 	err := s.trx.Get(&id,
-		`INSERT INTO tenants (name, subdomain, created_on, cname, invitation, welcome_message, status, is_private) 
-		 VALUES ($1, $2, $3, '', '', '', $4, false) 
+		`INSERT INTO tenants (name, subdomain, created_on, cname, invitation, welcome_message, status, is_private, locale) 
+		 VALUES ($1, $2, $3, '', '', '', $4, false, 'en') 
 		 RETURNING id`, name, subdomain, time.Now(), status)
 	if err != nil {
 		return nil, err
@@ -122,7 +230,7 @@ func (s *TenantStorage) Add(name string, subdomain string, status int) (*models.
 func (s *TenantStorage) First() (*models.Tenant, error) {
 	tenant := dbTenant{}
 
-	err := s.trx.Get(&tenant, "SELECT id, name, subdomain, cname, invitation, welcome_message, status, is_private, logo_id FROM tenants ORDER BY id LIMIT 1")
+	err := s.trx.Get(&tenant, "SELECT "+tenantColumns+" FROM tenants ORDER BY id LIMIT 1")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get first tenant")
 	}
@@ -130,11 +238,23 @@ func (s *TenantStorage) First() (*models.Tenant, error) {
 	return tenant.toModel(), nil
 }
 
+// GetByID returns a tenant based on its id
+func (s *TenantStorage) GetByID(id int) (*models.Tenant, error) {
+	tenant := dbTenant{}
+
+	err := s.trx.Get(&tenant, "SELECT "+tenantColumns+" FROM tenants WHERE id = $1", id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tenant with id '%d'", id)
+	}
+
+	return tenant.toModel(), nil
+}
+
 // GetByDomain returns a tenant based on its domain
 func (s *TenantStorage) GetByDomain(domain string) (*models.Tenant, error) {
 	tenant := dbTenant{}
 
-	err := s.trx.Get(&tenant, "SELECT id, name, subdomain, cname, invitation, welcome_message, status, is_private, logo_id FROM tenants WHERE subdomain = $1 OR cname = $2 ORDER BY cname DESC", extractSubdomain(domain), domain)
+	err := s.trx.Get(&tenant, "SELECT "+tenantColumns+" FROM tenants WHERE subdomain = $1 OR cname = $2 ORDER BY cname DESC", extractSubdomain(domain), domain)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get tenant with domain '%s'", domain)
 	}
@@ -144,45 +264,106 @@ func (s *TenantStorage) GetByDomain(domain string) (*models.Tenant, error) {
 
 // UpdateSettings of current tenant
 func (s *TenantStorage) UpdateSettings(settings *models.UpdateTenantSettings) error {
-	query := "UPDATE tenants SET name = $1, invitation = $2, welcome_message = $3, cname = $4 WHERE id = $5"
-	_, err := s.trx.Execute(query, settings.Title, settings.Invitation, settings.WelcomeMessage, settings.CNAME, s.current.ID)
+	if settings.Locale == "" {
+		settings.Locale = s.current.Locale
+	}
+
+	if settings.Subdomain == "" {
+		settings.Subdomain = s.current.Subdomain
+	}
+
+	query := "UPDATE tenants SET name = $1, invitation = $2, welcome_message = $3, subdomain = $4, cname = $5, locale = $6 WHERE id = $7"
+	_, err := s.trx.Execute(query, settings.Title, settings.Invitation, settings.WelcomeMessage, settings.Subdomain, settings.CNAME, settings.Locale, s.current.ID)
 	if err != nil {
 		return errors.Wrap(err, "failed update tenant settings")
 	}
 
+	if err := s.replaceHostname(s.current.ID, s.current.Subdomain, settings.Subdomain, false); err != nil {
+		return err
+	}
+
+	if err := s.replaceHostname(s.current.ID, s.current.CNAME, settings.CNAME, true); err != nil {
+		return err
+	}
+
 	s.current.Name = settings.Title
+	s.current.Subdomain = settings.Subdomain
 	s.current.Invitation = settings.Invitation
 	s.current.CNAME = settings.CNAME
 	s.current.WelcomeMessage = settings.WelcomeMessage
+	s.current.Locale = settings.Locale
 
 	if settings.Logo != nil {
-		var newLogoID sql.NullInt64
-
-		if !settings.Logo.Remove && len(settings.Logo.Upload.Content) > 0 {
-			err := s.trx.Get(&newLogoID, `
-				INSERT INTO uploads (tenant_id, size, content_type, file, created_on)
-				VALUES ($1, $2, $3, $4, $5) RETURNING id
-				`, s.current.ID, len(settings.Logo.Upload.Content), http.DetectContentType(settings.Logo.Upload.Content), settings.Logo.Upload.Content, time.Now(),
-			)
-			if err != nil {
-				return errors.Wrap(err, "failed to upload new tenant logo")
-			}
+		logoID, err := s.replaceUpload("logo_id", s.current.LogoID, settings.Logo)
+		if err != nil {
+			return errors.Wrap(err, "failed to update tenant logo")
+		}
+		s.current.LogoID = logoID
+	}
+
+	return nil
+}
+
+// replaceUpload stores given image on the column of current tenant and deletes the previous one
+// Column must be one of the upload columns of tenants, it's never an user input
+func (s *TenantStorage) replaceUpload(column string, previousID int, image *models.UpdateTenantSettingsLogo) (int, error) {
+	var newID sql.NullInt64
+
+	if !image.Remove && image.Upload != nil && len(image.Upload.Content) > 0 {
+		err := s.trx.Get(&newID, `
+			INSERT INTO uploads (tenant_id, size, content_type, file, created_on)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id
+			`, s.current.ID, len(image.Upload.Content), http.DetectContentType(image.Upload.Content), image.Upload.Content, time.Now(),
+		)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to upload new image")
+		}
+	} else if !image.Remove {
+		return previousID, nil
+	}
+
+	query := "UPDATE tenants SET " + column + " = $1 WHERE id = $2"
+	_, err := s.trx.Execute(query, newID, s.current.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to update %s", column)
+	}
+
+	if previousID > 0 {
+		query := "DELETE FROM uploads WHERE id = $1 AND tenant_id = $2"
+		_, err = s.trx.Execute(query, previousID, s.current.ID)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to delete previous upload")
 		}
+	}
+	return int(newID.Int64), nil
+}
+
+// UpdateTheme of current tenant
+func (s *TenantStorage) UpdateTheme(settings *models.UpdateTenantTheme) error {
+	query := "UPDATE tenants SET primary_color = $1, accent_color = $2, custom_css = $3 WHERE id = $4"
+	_, err := s.trx.Execute(query, settings.PrimaryColor, settings.AccentColor, settings.CustomCSS, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant theme")
+	}
+
+	s.current.Theme.PrimaryColor = settings.PrimaryColor
+	s.current.Theme.AccentColor = settings.AccentColor
+	s.current.Theme.CustomCSS = settings.CustomCSS
 
-		query := "UPDATE tenants SET logo_id = $1 WHERE id = $2"
-		_, err = s.trx.Execute(query, newLogoID, s.current.ID)
+	if settings.Favicon != nil {
+		faviconID, err := s.replaceUpload("favicon_id", s.current.Theme.FaviconID, settings.Favicon)
 		if err != nil {
-			return errors.Wrap(err, "failed update tenant logo")
+			return errors.Wrap(err, "failed to update tenant favicon")
 		}
+		s.current.Theme.FaviconID = faviconID
+	}
 
-		if s.current.LogoID > 0 {
-			query := "DELETE FROM uploads WHERE id = $1 AND tenant_id = $2"
-			_, err = s.trx.Execute(query, s.current.LogoID, s.current.ID)
-			if err != nil {
-				return errors.Wrap(err, "failed delete old tenant logo")
-			}
+	if settings.Header != nil {
+		headerID, err := s.replaceUpload("header_id", s.current.Theme.HeaderID, settings.Header)
+		if err != nil {
+			return errors.Wrap(err, "failed to update tenant header image")
 		}
-		s.current.LogoID = int(newLogoID.Int64)
+		s.current.Theme.HeaderID = headerID
 	}
 
 	return nil
@@ -198,13 +379,206 @@ func (s *TenantStorage) UpdatePrivacy(settings *models.UpdateTenantPrivacy) erro
 	return nil
 }
 
+// UpdateEmailSettings of current tenant
+// SMTP password and DKIM key are kept when not given, so that they don't need to be sent back to the browser
+func (s *TenantStorage) UpdateEmailSettings(settings *models.UpdateTenantEmailSettings) error {
+	email := s.current.Email
+	email.FromAddress = settings.FromAddress
+	email.ReplyTo = settings.ReplyTo
+	email.SMTPHost = settings.SMTPHost
+	email.SMTPPort = settings.SMTPPort
+	email.SMTPUsername = settings.SMTPUsername
+	email.DKIMSelector = settings.DKIMSelector
+	if settings.SMTPPassword != "" {
+		email.SMTPPassword = settings.SMTPPassword
+	}
+	if settings.DKIMPrivateKey != "" {
+		email.DKIMPrivateKey = settings.DKIMPrivateKey
+	}
+	if email.VerificationToken == "" {
+		email.VerificationToken = models.GenerateVerificationKey()
+	}
+
+	query := `UPDATE tenants SET email_from_address = $1, email_reply_to = $2, email_verification_token = $3,
+		email_smtp_host = $4, email_smtp_port = $5, email_smtp_username = $6, email_smtp_password = $7,
+		email_dkim_selector = $8, email_dkim_private_key = $9 WHERE id = $10`
+	_, err := s.trx.Execute(query, email.FromAddress, email.ReplyTo, email.VerificationToken,
+		email.SMTPHost, email.SMTPPort, email.SMTPUsername, email.SMTPPassword,
+		email.DKIMSelector, email.DKIMPrivateKey, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant email settings")
+	}
+
+	s.current.Email = email
+	return nil
+}
+
+// UpdateSAMLSettings of current tenant
+func (s *TenantStorage) UpdateSAMLSettings(settings *models.UpdateTenantSAMLSettings) error {
+	query := `UPDATE tenants SET saml_enabled = $1, saml_enforced = $2, saml_idp_entity_id = $3, saml_idp_sso_url = $4,
+		saml_idp_certificate = $5, saml_name_attribute = $6, saml_email_attribute = $7, saml_role_attribute = $8,
+		saml_admin_role_value = $9, saml_collaborator_role_value = $10 WHERE id = $11`
+	_, err := s.trx.Execute(query, settings.IsEnabled, settings.IsEnforced, settings.IdPEntityID, settings.IdPSSOURL,
+		settings.IdPCertificate, settings.NameAttribute, settings.EmailAttribute, settings.RoleAttribute,
+		settings.AdministratorRoleValue, settings.CollaboratorRoleValue, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant SAML settings")
+	}
+
+	s.current.SAML = models.TenantSAMLSettings{
+		IsEnabled:              settings.IsEnabled,
+		IsEnforced:             settings.IsEnforced,
+		IdPEntityID:            settings.IdPEntityID,
+		IdPSSOURL:              settings.IdPSSOURL,
+		IdPCertificate:         settings.IdPCertificate,
+		NameAttribute:          settings.NameAttribute,
+		EmailAttribute:         settings.EmailAttribute,
+		RoleAttribute:          settings.RoleAttribute,
+		AdministratorRoleValue: settings.AdministratorRoleValue,
+		CollaboratorRoleValue:  settings.CollaboratorRoleValue,
+	}
+	return nil
+}
+
+// UpdateJWTSSOSettings of current tenant
+// Secret is kept when not given, so that it doesn't need to be sent back to the browser
+func (s *TenantStorage) UpdateJWTSSOSettings(settings *models.UpdateTenantJWTSSOSettings) error {
+	sso := s.current.JWTSSO
+	sso.IsEnabled = settings.IsEnabled
+	sso.PublicKey = settings.PublicKey
+	if settings.Secret != "" {
+		sso.Secret = settings.Secret
+	}
+
+	query := "UPDATE tenants SET sso_jwt_enabled = $1, sso_jwt_secret = $2, sso_jwt_public_key = $3 WHERE id = $4"
+	_, err := s.trx.Execute(query, sso.IsEnabled, sso.Secret, sso.PublicKey, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant JWT SSO settings")
+	}
+
+	s.current.JWTSSO = sso
+	return nil
+}
+
+// UpdateTwoFactorSettings of current tenant
+func (s *TenantStorage) UpdateTwoFactorSettings(settings *models.UpdateTenantTwoFactorSettings) error {
+	query := "UPDATE tenants SET two_factor_required = $1 WHERE id = $2"
+	_, err := s.trx.Execute(query, settings.IsRequired, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant two-factor settings")
+	}
+
+	s.current.IsTwoFactorRequired = settings.IsRequired
+	return nil
+}
+
+// UpdateAutoJoinSettings of current tenant
+func (s *TenantStorage) UpdateAutoJoinSettings(settings *models.UpdateTenantAutoJoinSettings) error {
+	query := "UPDATE tenants SET auto_join_domains = $1, auto_join_role = $2 WHERE id = $3"
+	_, err := s.trx.Execute(query, pq.Array(settings.Domains), settings.Role, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant auto join settings")
+	}
+
+	s.current.AutoJoin = models.TenantAutoJoinSettings{
+		Domains: settings.Domains,
+		Role:    settings.Role,
+	}
+	return nil
+}
+
+// UpdateEmbedSettings of current tenant
+func (s *TenantStorage) UpdateEmbedSettings(settings *models.UpdateTenantEmbedSettings) error {
+	query := "UPDATE tenants SET embed_origins = $1 WHERE id = $2"
+	_, err := s.trx.Execute(query, pq.Array(settings.Origins), s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed update tenant embed settings")
+	}
+
+	s.current.EmbedOrigins = settings.Origins
+	return nil
+}
+
+// UseSSOToken records given token as used and returns false if it has been used before
+func (s *TenantStorage) UseSSOToken(id string, expiresOn time.Time) (bool, error) {
+	_, err := s.trx.Execute("DELETE FROM sso_tokens WHERE tenant_id = $1 AND expires_on < $2", s.current.ID, time.Now())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete expired sso tokens")
+	}
+
+	rows, err := s.trx.Execute(`
+		INSERT INTO sso_tokens (tenant_id, token_id, expires_on) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, token_id) DO NOTHING
+	`, s.current.ID, id, expiresOn)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to use sso token '%s'", id)
+	}
+
+	return rows == 1, nil
+}
+
+// UseSAMLAssertion records given assertion as used and returns false if it has been used before
+func (s *TenantStorage) UseSAMLAssertion(id string, expiresOn time.Time) (bool, error) {
+	_, err := s.trx.Execute("DELETE FROM saml_assertions WHERE tenant_id = $1 AND expires_on < $2", s.current.ID, time.Now())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete expired saml assertions")
+	}
+
+	rows, err := s.trx.Execute(`
+		INSERT INTO saml_assertions (tenant_id, assertion_id, expires_on) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, assertion_id) DO NOTHING
+	`, s.current.ID, id, expiresOn)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to use saml assertion '%s'", id)
+	}
+
+	return rows == 1, nil
+}
+
+// SetEmailVerifiedDomain marks given domain as owned by current tenant
+func (s *TenantStorage) SetEmailVerifiedDomain(domain string) error {
+	domain = strings.ToLower(domain)
+	_, err := s.trx.Execute("UPDATE tenants SET email_verified_domain = $1 WHERE id = $2", domain, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set email verified domain")
+	}
+
+	s.current.Email.VerifiedDomain = domain
+	return nil
+}
+
+// SetEmailVerifiedAddress marks given email address as owned by current tenant
+func (s *TenantStorage) SetEmailVerifiedAddress(address string) error {
+	address = strings.ToLower(address)
+	_, err := s.trx.Execute("UPDATE tenants SET email_verified_address = $1 WHERE id = $2", address, s.current.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set email verified address")
+	}
+
+	s.current.Email.VerifiedAddress = address
+	return nil
+}
+
 // IsSubdomainAvailable returns true if subdomain is available to use
 func (s *TenantStorage) IsSubdomainAvailable(subdomain string) (bool, error) {
-	exists, err := s.trx.Exists("SELECT id FROM tenants WHERE subdomain = $1", subdomain)
+	currentID := 0
+	if s.current != nil {
+		currentID = s.current.ID
+	}
+
+	exists, err := s.trx.Exists("SELECT id FROM tenants WHERE subdomain = $1 AND id <> $2", subdomain, currentID)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to check if tenant exists with subdomain '%s'", subdomain)
 	}
-	return !exists, nil
+	if exists {
+		return false, nil
+	}
+
+	reserved, err := s.isAliasReserved(subdomain, false)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check if subdomain '%s' is reserved", subdomain)
+	}
+	return !reserved, nil
 }
 
 // IsCNAMEAvailable returns true if cname is available to use
@@ -213,7 +587,151 @@ func (s *TenantStorage) IsCNAMEAvailable(cname string) (bool, error) {
 	if err != nil {
 		return false, errors.Wrap(err, "failed to check if tenant exists with CNAME '%s'", cname)
 	}
-	return !exists, nil
+	if exists {
+		return false, nil
+	}
+
+	reserved, err := s.isAliasReserved(cname, true)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check if CNAME '%s' is reserved", cname)
+	}
+	return !reserved, nil
+}
+
+// isAliasReserved returns true if hostname is an alias of another tenant or was removed less than a cool-down period ago
+// Aliases of purged tenants don't have a tenant anymore and are reserved for everyone
+func (s *TenantStorage) isAliasReserved(hostname string, isCNAME bool) (bool, error) {
+	currentID := 0
+	if s.current != nil {
+		currentID = s.current.ID
+	}
+
+	return s.trx.Exists(`
+		SELECT id FROM tenant_aliases
+		WHERE hostname = $1 AND is_cname = $2 AND (tenant_id IS NULL OR tenant_id <> $3)
+		AND (removed_on IS NULL OR removed_on > $4)`,
+		hostname, isCNAME, currentID, time.Now().Add(-models.TenantAliasCooldownPeriod),
+	)
+}
+
+// replaceHostname keeps the previous hostname of a tenant as an alias
+// Tenants going back to one of their aliases take it back as their hostname
+func (s *TenantStorage) replaceHostname(tenantID int, previous, hostname string, isCNAME bool) error {
+	if previous == hostname {
+		return nil
+	}
+
+	if hostname != "" {
+		_, err := s.trx.Execute(
+			"DELETE FROM tenant_aliases WHERE tenant_id = $1 AND hostname = $2 AND is_cname = $3",
+			tenantID, hostname, isCNAME,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to reclaim alias '%s' of tenant with id '%d'", hostname, tenantID)
+		}
+	}
+
+	if previous != "" {
+		_, err := s.trx.Execute(
+			"INSERT INTO tenant_aliases (tenant_id, hostname, is_cname, created_on) VALUES ($1, $2, $3, $4)",
+			tenantID, previous, isCNAME, time.Now(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to add alias '%s' to tenant with id '%d'", previous, tenantID)
+		}
+	}
+
+	return nil
+}
+
+type dbTenantAlias struct {
+	ID        int       `db:"id"`
+	Hostname  string    `db:"hostname"`
+	IsCNAME   bool      `db:"is_cname"`
+	CreatedOn time.Time `db:"created_on"`
+}
+
+// GetByAlias returns the tenant that used to be served on given domain
+func (s *TenantStorage) GetByAlias(domain string) (*models.Tenant, error) {
+	tenant := dbTenant{}
+
+	err := s.trx.Get(&tenant, `
+		SELECT `+tenantColumns+` FROM tenants WHERE id = (
+			SELECT tenant_id FROM tenant_aliases
+			WHERE removed_on IS NULL AND ((is_cname = false AND hostname = $1) OR (is_cname = true AND hostname = $2))
+			ORDER BY is_cname DESC, id DESC
+			LIMIT 1
+		)`, extractSubdomain(domain), domain)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tenant with alias '%s'", domain)
+	}
+
+	return tenant.toModel(), nil
+}
+
+// ListAliases returns all hostnames that redirect to current tenant
+func (s *TenantStorage) ListAliases() ([]*models.TenantAlias, error) {
+	var aliases []*dbTenantAlias
+	err := s.trx.Select(&aliases, `
+		SELECT id, hostname, is_cname, created_on FROM tenant_aliases
+		WHERE tenant_id = $1 AND removed_on IS NULL
+		ORDER BY id DESC`, s.current.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get aliases of tenant with id '%d'", s.current.ID)
+	}
+
+	var result = make([]*models.TenantAlias, len(aliases))
+	for i, alias := range aliases {
+		result[i] = &models.TenantAlias{
+			ID:        alias.ID,
+			Hostname:  alias.Hostname,
+			IsCNAME:   alias.IsCNAME,
+			CreatedOn: alias.CreatedOn,
+		}
+	}
+	return result, nil
+}
+
+type dbCertificateInfo struct {
+	ExpiresOn time.Time `db:"expires_on"`
+	UpdatedOn time.Time `db:"updated_on"`
+}
+
+// GetCertificateInfo returns the status of the certificate issued automatically for given hostname
+func (s *TenantStorage) GetCertificateInfo(hostname string) (*models.CertificateInfo, error) {
+	info := dbCertificateInfo{}
+
+	//Certificates are stored under the hostname and, for RSA only clients, under hostname+rsa
+	err := s.trx.Get(&info, `
+		SELECT expires_on, updated_on FROM autocert_cache
+		WHERE key IN ($1, $2) AND expires_on IS NOT NULL
+		ORDER BY expires_on DESC
+		LIMIT 1`, hostname, hostname+"+rsa")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get certificate of '%s'", hostname)
+	}
+
+	return &models.CertificateInfo{
+		Hostname:  hostname,
+		ExpiresOn: info.ExpiresOn,
+		IssuedOn:  info.UpdatedOn,
+	}, nil
+}
+
+// RemoveAlias stops redirecting given alias to current tenant
+// The hostname stays reserved during a cool-down period so that old links don't lead to someone else
+func (s *TenantStorage) RemoveAlias(id int) error {
+	affected, err := s.trx.Execute(
+		"UPDATE tenant_aliases SET removed_on = $1 WHERE id = $2 AND tenant_id = $3 AND removed_on IS NULL",
+		time.Now(), id, s.current.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove alias with id '%d'", id)
+	}
+	if affected == 0 {
+		return app.ErrNotFound
+	}
+	return nil
 }
 
 // Activate given tenant
@@ -226,6 +744,356 @@ func (s *TenantStorage) Activate(id int) error {
 	return nil
 }
 
+// Suspend given tenant, which blocks all requests to it until it's activated again
+func (s *TenantStorage) Suspend(id int) error {
+	query := "UPDATE tenants SET status = $1 WHERE id = $2"
+	_, err := s.trx.Execute(query, models.TenantSuspended, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to suspend tenant with id '%d'", id)
+	}
+	return nil
+}
+
+// ScheduleDeletion marks given tenant to be purged on given date
+func (s *TenantStorage) ScheduleDeletion(id int, on time.Time) error {
+	query := "UPDATE tenants SET deletion_scheduled_on = $1 WHERE id = $2"
+	_, err := s.trx.Execute(query, on, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule deletion of tenant with id '%d'", id)
+	}
+	return nil
+}
+
+// CancelDeletion removes the scheduled deletion of given tenant
+func (s *TenantStorage) CancelDeletion(id int) error {
+	query := "UPDATE tenants SET deletion_scheduled_on = NULL WHERE id = $1"
+	_, err := s.trx.Execute(query, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel deletion of tenant with id '%d'", id)
+	}
+	return nil
+}
+
+// GetDueForDeletion returns all tenants which deletion date is before given date
+// Rows are locked so that concurrent purges skip tenants that are already being deleted
+func (s *TenantStorage) GetDueForDeletion(before time.Time) ([]*models.Tenant, error) {
+	var tenants []*dbTenant
+	err := s.trx.Select(&tenants, `
+		SELECT `+tenantColumns+` FROM tenants 
+		WHERE deletion_scheduled_on IS NOT NULL AND deletion_scheduled_on <= $1
+		ORDER BY deletion_scheduled_on
+		FOR UPDATE SKIP LOCKED`, before)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tenants due for deletion")
+	}
+
+	var result = make([]*models.Tenant, len(tenants))
+	for i, tenant := range tenants {
+		result[i] = tenant.toModel()
+	}
+	return result, nil
+}
+
+// purgeLockID identifies the advisory lock held by the instance that is purging tenants
+const purgeLockID = 201806251000
+
+// LockForPurge tries to take a lock that is held until the end of current transaction,
+// so that only one instance purges tenants at a time. Returns false when another instance holds it
+func (s *TenantStorage) LockForPurge() (bool, error) {
+	var locked bool
+	err := s.trx.Scalar(&locked, "SELECT pg_try_advisory_xact_lock($1)", purgeLockID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to lock tenants for purge")
+	}
+	return locked, nil
+}
+
+// tenantTables lists every table with tenant data in an order that respects their foreign keys
+var tenantTables = []string{
+	"notifications",
+	"idea_tags",
+	"idea_subscribers",
+	"idea_supporters",
+	"comments",
+	"ideas",
+	"tags",
+	"email_verifications",
+	"user_recovery_codes",
+	"user_sessions",
+	"user_settings",
+	"user_providers",
+	"users",
+	"tenant_roles",
+	"uploads",
+	"email_templates",
+	"pages",
+	"email_deliveries",
+	"email_suppressions",
+	"oauth_providers",
+	"sso_tokens",
+	"saml_assertions",
+}
+
+// Purge permanently deletes given tenant and all of its data
+func (s *TenantStorage) Purge(id int) error {
+	_, err := s.trx.Execute("UPDATE tenants SET logo_id = NULL, favicon_id = NULL, header_id = NULL WHERE id = $1", id)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove logo and theme images of tenant with id '%d'", id)
+	}
+
+	for _, table := range tenantTables {
+		_, err := s.trx.Execute("DELETE FROM "+table+" WHERE tenant_id = $1", id)
+		if err != nil {
+			return errors.Wrap(err, "failed to purge '%s' of tenant with id '%d'", table, id)
+		}
+	}
+
+	if err := s.releaseHostnames(id); err != nil {
+		return err
+	}
+
+	_, err = s.trx.Execute("DELETE FROM tenants WHERE id = $1", id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete tenant with id '%d'", id)
+	}
+	return nil
+}
+
+// releaseHostnames keeps the hostnames of a purged tenant as removed aliases without a tenant
+// They stay reserved for a cool-down period, so that nobody else can take over links to the purged tenant right away
+func (s *TenantStorage) releaseHostnames(id int) error {
+	now := time.Now()
+	_, err := s.trx.Execute(
+		"UPDATE tenant_aliases SET tenant_id = NULL, removed_on = COALESCE(removed_on, $1) WHERE tenant_id = $2",
+		now, id,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to release aliases of tenant with id '%d'", id)
+	}
+
+	_, err = s.trx.Execute(`
+		INSERT INTO tenant_aliases (tenant_id, hostname, is_cname, created_on, removed_on)
+		SELECT NULL::int, subdomain, false, $1::timestamptz, $1::timestamptz FROM tenants WHERE id = $2
+		UNION ALL
+		SELECT NULL::int, cname, true, $1::timestamptz, $1::timestamptz FROM tenants WHERE id = $2 AND cname <> ''`,
+		now, id,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to release hostnames of tenant with id '%d'", id)
+	}
+	return nil
+}
+
+type dbTenantSummary struct {
+	ID                  int          `db:"id"`
+	Name                string       `db:"name"`
+	Subdomain           string       `db:"subdomain"`
+	CNAME               string       `db:"cname"`
+	Status              int          `db:"status"`
+	Plan                string       `db:"plan"`
+	CreatedOn           time.Time    `db:"created_on"`
+	UserCount           int          `db:"user_count"`
+	IdeaCount           int          `db:"idea_count"`
+	LastActivityOn      dbx.NullTime `db:"last_activity_on"`
+	StorageUsage        int64        `db:"storage_usage"`
+	DeletionScheduledOn dbx.NullTime `db:"deletion_scheduled_on"`
+}
+
+func (t *dbTenantSummary) toModel() *models.TenantSummary {
+	summary := &models.TenantSummary{
+		ID:           t.ID,
+		Name:         t.Name,
+		Subdomain:    t.Subdomain,
+		CNAME:        t.CNAME,
+		Status:       t.Status,
+		Plan:         t.Plan,
+		CreatedOn:    t.CreatedOn,
+		UserCount:    t.UserCount,
+		IdeaCount:    t.IdeaCount,
+		StorageUsage: t.StorageUsage,
+	}
+	if t.LastActivityOn.Valid {
+		summary.LastActivityOn = &t.LastActivityOn.Time
+	}
+	if t.DeletionScheduledOn.Valid {
+		summary.DeletionScheduledOn = &t.DeletionScheduledOn.Time
+	}
+	return summary
+}
+
+// Search returns an overview of the tenants which name or domain contain given query, newest first
+func (s *TenantStorage) Search(query string) ([]*models.TenantSummary, error) {
+	var tenants []*dbTenantSummary
+	err := s.trx.Select(&tenants, `
+		SELECT t.id, t.name, t.subdomain, t.cname, t.status, t.plan, t.created_on, t.deletion_scheduled_on,
+			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.id) AS user_count,
+			(SELECT COUNT(*) FROM ideas i WHERE i.tenant_id = t.id) AS idea_count,
+			GREATEST(
+				(SELECT MAX(i.created_on) FROM ideas i WHERE i.tenant_id = t.id),
+				(SELECT MAX(c.created_on) FROM comments c WHERE c.tenant_id = t.id),
+				(SELECT MAX(us.last_seen_on) FROM user_sessions us WHERE us.tenant_id = t.id)
+			) AS last_activity_on,
+			(SELECT COALESCE(SUM(up.size), 0) FROM uploads up WHERE up.tenant_id = t.id) AS storage_usage
+		FROM tenants t
+		WHERE $1::text = '' OR t.name ILIKE $2 OR t.subdomain ILIKE $2 OR t.cname ILIKE $2
+		ORDER BY t.id DESC
+		LIMIT 100`, query, "%"+query+"%")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search tenants with '%s'", query)
+	}
+
+	var result = make([]*models.TenantSummary, len(tenants))
+	for i, tenant := range tenants {
+		result[i] = tenant.toModel()
+	}
+	return result, nil
+}
+
+// ChangePlan of given tenant
+func (s *TenantStorage) ChangePlan(id int, plan string) error {
+	_, err := s.trx.Execute("UPDATE tenants SET plan = $1 WHERE id = $2", plan, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to change plan of tenant with id '%d'", id)
+	}
+	return nil
+}
+
+// GetUsage returns what current tenant consumes out of its plan
+func (s *TenantStorage) GetUsage() (*models.TenantUsage, error) {
+	usage := dbTenantUsage{}
+	err := s.trx.Get(&usage, `
+		SELECT
+			(SELECT COUNT(*) FROM users WHERE tenant_id = $1) AS users,
+			(SELECT COUNT(*) FROM ideas WHERE tenant_id = $1) AS ideas,
+			(SELECT COALESCE(SUM(size), 0) FROM uploads WHERE tenant_id = $1) AS storage`, s.current.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get usage of tenant with id '%d'", s.current.ID)
+	}
+
+	return &models.TenantUsage{
+		Plan:    s.current.Plan,
+		Users:   usage.Users,
+		Ideas:   usage.Ideas,
+		Storage: usage.Storage,
+	}, nil
+}
+
+type dbTenantUsage struct {
+	Users   int   `db:"users"`
+	Ideas   int   `db:"ideas"`
+	Storage int64 `db:"storage"`
+}
+
+// ChangeSubdomain of given tenant
+func (s *TenantStorage) ChangeSubdomain(id int, subdomain string) error {
+	tenant, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE tenants SET subdomain = $1 WHERE id = $2"
+	_, err = s.trx.Execute(query, subdomain, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to change subdomain of tenant with id '%d'", id)
+	}
+
+	return s.replaceHostname(id, tenant.Subdomain, subdomain, false)
+}
+
+type dbOperatorAuditEntry struct {
+	ID            int         `db:"id"`
+	OperatorEmail string      `db:"operator_email"`
+	Action        string      `db:"action"`
+	TenantID      int         `db:"tenant_id"`
+	UserID        dbx.NullInt `db:"user_id"`
+	Details       string      `db:"details"`
+	IPAddress     string      `db:"ip_address"`
+	CreatedOn     time.Time   `db:"created_on"`
+}
+
+func (e *dbOperatorAuditEntry) toModel() *models.OperatorAuditEntry {
+	entry := &models.OperatorAuditEntry{
+		ID:            e.ID,
+		OperatorEmail: e.OperatorEmail,
+		Action:        models.OperatorAction(e.Action),
+		TenantID:      e.TenantID,
+		Details:       e.Details,
+		IPAddress:     e.IPAddress,
+		CreatedOn:     e.CreatedOn,
+	}
+	if e.UserID.Valid {
+		entry.UserID = int(e.UserID.Int64)
+	}
+	return entry
+}
+
+// AddOperatorAudit records an action performed by an operator
+// Entries aren't removed when a tenant is purged, so they don't reference it
+func (s *TenantStorage) AddOperatorAudit(entry *models.OperatorAuditEntry) error {
+	if entry.CreatedOn.IsZero() {
+		entry.CreatedOn = time.Now()
+	}
+
+	var userID interface{}
+	if entry.UserID > 0 {
+		userID = entry.UserID
+	}
+
+	err := s.trx.Get(&entry.ID, `
+		INSERT INTO operator_audit_log (operator_email, action, tenant_id, user_id, details, ip_address, created_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, entry.OperatorEmail, string(entry.Action), entry.TenantID, userID, entry.Details, entry.IPAddress, entry.CreatedOn)
+	if err != nil {
+		return errors.Wrap(err, "failed to add operator audit entry")
+	}
+	return nil
+}
+
+// ListOperatorAudit returns the latest actions performed by operators
+func (s *TenantStorage) ListOperatorAudit() ([]*models.OperatorAuditEntry, error) {
+	var entries []*dbOperatorAuditEntry
+	err := s.trx.Select(&entries, `
+		SELECT id, operator_email, action, tenant_id, user_id, details, ip_address, created_on
+		FROM operator_audit_log
+		ORDER BY created_on DESC, id DESC
+		LIMIT 100`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list operator audit entries")
+	}
+
+	var result = make([]*models.OperatorAuditEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.toModel()
+	}
+	return result, nil
+}
+
+// AddOperatorSignInFailure records a wrong email or secret given on the sign in of the super-admin console
+func (s *TenantStorage) AddOperatorSignInFailure(email, ipAddress string) error {
+	_, err := s.trx.Execute(
+		"INSERT INTO operator_sign_in_failures (email, ip_address, failed_on) VALUES ($1, $2, $3)",
+		email, ipAddress, time.Now(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to add operator sign in failure")
+	}
+	return nil
+}
+
+// CountOperatorSignInFailures returns the most failed operator sign ins for given email or from given IP address since given time
+func (s *TenantStorage) CountOperatorSignInFailures(email, ipAddress string, since time.Time) (int, error) {
+	var count int
+	query := `SELECT GREATEST(
+		(SELECT COUNT(*) FROM operator_sign_in_failures WHERE email = $1 AND failed_on > $3),
+		(SELECT COUNT(*) FROM operator_sign_in_failures WHERE ip_address = $2 AND failed_on > $3)
+	)`
+	if err := s.trx.Scalar(&count, query, email, ipAddress, since); err != nil {
+		return 0, errors.Wrap(err, "failed to count operator sign in failures")
+	}
+	return count, nil
+}
+
 // SaveVerificationKey used by email verification process
 func (s *TenantStorage) SaveVerificationKey(key string, duration time.Duration, request models.NewEmailVerification) error {
 	var userID interface{}
@@ -264,6 +1132,45 @@ func (s *TenantStorage) SetKeyAsVerified(key string) error {
 	return nil
 }
 
+// GetVerificationsByKind returns all email verifications of given kind, most recent first
+func (s *TenantStorage) GetVerificationsByKind(kind models.EmailVerificationKind) ([]*models.EmailVerification, error) {
+	verifications := []*dbEmailVerification{}
+
+	query := "SELECT id, email, name, key, created_on, verified_on, expires_on, kind, user_id FROM email_verifications WHERE tenant_id = $1 AND kind = $2 ORDER BY created_on DESC, id DESC"
+	err := s.trx.Select(&verifications, query, s.current.ID, kind)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get email verifications of kind '%d'", kind)
+	}
+
+	var result = make([]*models.EmailVerification, len(verifications))
+	for i, verification := range verifications {
+		result[i] = verification.toModel()
+	}
+	return result, nil
+}
+
+// GetVerificationByID returns the email verification of given kind with given id
+func (s *TenantStorage) GetVerificationByID(kind models.EmailVerificationKind, id int) (*models.EmailVerification, error) {
+	verification := dbEmailVerification{}
+
+	query := "SELECT id, email, name, key, created_on, verified_on, expires_on, kind, user_id FROM email_verifications WHERE tenant_id = $1 AND kind = $2 AND id = $3"
+	err := s.trx.Get(&verification, query, s.current.ID, kind, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get email verification by its id")
+	}
+
+	return verification.toModel(), nil
+}
+
+// DeleteVerification so that its key cannot be used anymore
+func (s *TenantStorage) DeleteVerification(id int) error {
+	_, err := s.trx.Execute("DELETE FROM email_verifications WHERE tenant_id = $1 AND id = $2", s.current.ID, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete email verification")
+	}
+	return nil
+}
+
 // GetLogo returns tenant logo by id
 func (s *TenantStorage) GetLogo(id int) (*models.Upload, error) {
 	upload := &models.Upload{}
@@ -283,6 +1190,210 @@ func (s *TenantStorage) GetLogo(id int) (*models.Upload, error) {
 	return upload, nil
 }
 
+// GetThemeImage returns the favicon or header image of current tenant by id
+func (s *TenantStorage) GetThemeImage(id int) (*models.Upload, error) {
+	upload := &models.Upload{}
+	err := s.trx.Get(upload, `
+		SELECT content_type, size, file FROM tenants
+		INNER JOIN uploads
+		ON uploads.tenant_id = tenants.id
+		AND (uploads.id = tenants.favicon_id OR uploads.id = tenants.header_id)
+		WHERE tenants.id = $1 AND uploads.id = $2
+	`, s.current.ID, id)
+	if err == app.ErrNotFound {
+		return nil, app.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get theme image from tenant")
+	}
+	return upload, nil
+}
+
+type dbOAuthConfig struct {
+	ID                  int    `db:"id"`
+	Provider            string `db:"provider"`
+	DisplayName         string `db:"display_name"`
+	Status              int    `db:"status"`
+	ClientID            string `db:"client_id"`
+	ClientSecret        string `db:"client_secret"`
+	AuthorizeURL        string `db:"authorize_url"`
+	TokenURL            string `db:"token_url"`
+	ProfileURL          string `db:"profile_url"`
+	Scope               string `db:"scope"`
+	JSONUserIDPath      string `db:"json_user_id_path"`
+	JSONUserNamePath    string `db:"json_user_name_path"`
+	JSONUserEmailPath   string `db:"json_user_email_path"`
+	EmailAlwaysVerified bool   `db:"email_always_verified"`
+}
+
+func (m *dbOAuthConfig) toModel() *models.OAuthConfig {
+	return &models.OAuthConfig{
+		ID:                  m.ID,
+		Provider:            m.Provider,
+		DisplayName:         m.DisplayName,
+		Status:              m.Status,
+		ClientID:            m.ClientID,
+		ClientSecret:        m.ClientSecret,
+		AuthorizeURL:        m.AuthorizeURL,
+		TokenURL:            m.TokenURL,
+		ProfileURL:          m.ProfileURL,
+		Scope:               m.Scope,
+		JSONUserIDPath:      m.JSONUserIDPath,
+		JSONUserNamePath:    m.JSONUserNamePath,
+		JSONUserEmailPath:   m.JSONUserEmailPath,
+		EmailAlwaysVerified: m.EmailAlwaysVerified,
+	}
+}
+
+const oauthConfigColumns = `id, provider, display_name, status, client_id, client_secret,
+	authorize_url, token_url, profile_url, scope,
+	json_user_id_path, json_user_name_path, json_user_email_path, email_always_verified`
+
+// GetOAuthConfigByProvider returns a custom OAuth configuration by provider name
+func (s *TenantStorage) GetOAuthConfigByProvider(provider string) (*models.OAuthConfig, error) {
+	config := &dbOAuthConfig{}
+	err := s.trx.Get(config, "SELECT "+oauthConfigColumns+" FROM oauth_providers WHERE tenant_id = $1 AND provider = $2", s.current.ID, provider)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get oauth provider '%s'", provider)
+	}
+	return config.toModel(), nil
+}
+
+// ListOAuthConfig returns all custom OAuth configurations of current tenant
+func (s *TenantStorage) ListOAuthConfig() ([]*models.OAuthConfig, error) {
+	configs := []*dbOAuthConfig{}
+	err := s.trx.Select(&configs, "SELECT "+oauthConfigColumns+" FROM oauth_providers WHERE tenant_id = $1 ORDER BY id", s.current.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get oauth providers")
+	}
+
+	var result = make([]*models.OAuthConfig, len(configs))
+	for i, config := range configs {
+		result[i] = config.toModel()
+	}
+	return result, nil
+}
+
+// SaveOAuthConfig saves given custom OAuth configuration, keeping current secret if none is given
+func (s *TenantStorage) SaveOAuthConfig(config *models.CreateEditOAuthConfig) error {
+	existing, err := s.GetOAuthConfigByProvider(config.Provider)
+	if err != nil && errors.Cause(err) != app.ErrNotFound {
+		return err
+	}
+
+	if existing == nil {
+		_, err = s.trx.Execute(`
+			INSERT INTO oauth_providers (
+				tenant_id, provider, display_name, status, client_id, client_secret,
+				authorize_url, token_url, profile_url, scope,
+				json_user_id_path, json_user_name_path, json_user_email_path, email_always_verified
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			s.current.ID, config.Provider, config.DisplayName, config.Status, config.ClientID, config.ClientSecret,
+			config.AuthorizeURL, config.TokenURL, config.ProfileURL, config.Scope,
+			config.JSONUserIDPath, config.JSONUserNamePath, config.JSONUserEmailPath, config.EmailAlwaysVerified,
+		)
+	} else {
+		secret := config.ClientSecret
+		if secret == "" {
+			secret = existing.ClientSecret
+		}
+		_, err = s.trx.Execute(`
+			UPDATE oauth_providers SET display_name = $3, status = $4, client_id = $5, client_secret = $6,
+			authorize_url = $7, token_url = $8, profile_url = $9, scope = $10,
+			json_user_id_path = $11, json_user_name_path = $12, json_user_email_path = $13,
+			email_always_verified = $14
+			WHERE tenant_id = $1 AND provider = $2`,
+			s.current.ID, config.Provider, config.DisplayName, config.Status, config.ClientID, secret,
+			config.AuthorizeURL, config.TokenURL, config.ProfileURL, config.Scope,
+			config.JSONUserIDPath, config.JSONUserNamePath, config.JSONUserEmailPath, config.EmailAlwaysVerified,
+		)
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "failed to save oauth provider '%s'", config.Provider)
+	}
+	return nil
+}
+
+type dbCustomRole struct {
+	ID          int      `db:"id"`
+	Name        string   `db:"name"`
+	Permissions []string `db:"permissions"`
+}
+
+func (r *dbCustomRole) toModel() *models.CustomRole {
+	role := &models.CustomRole{
+		ID:          r.ID,
+		Name:        r.Name,
+		Permissions: make([]models.Permission, len(r.Permissions)),
+	}
+	for i, p := range r.Permissions {
+		role.Permissions[i] = models.Permission(p)
+	}
+	return role
+}
+
+// ListCustomRoles returns all roles defined by current tenant
+func (s *TenantStorage) ListCustomRoles() ([]*models.CustomRole, error) {
+	roles := []*dbCustomRole{}
+	err := s.trx.Select(&roles, "SELECT id, name, permissions FROM tenant_roles WHERE tenant_id = $1 ORDER BY name", s.current.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get custom roles")
+	}
+
+	var result = make([]*models.CustomRole, len(roles))
+	for i, role := range roles {
+		result[i] = role.toModel()
+	}
+	return result, nil
+}
+
+// GetCustomRoleByID returns a role defined by current tenant
+func (s *TenantStorage) GetCustomRoleByID(id int) (*models.CustomRole, error) {
+	role := &dbCustomRole{}
+	err := s.trx.Get(role, "SELECT id, name, permissions FROM tenant_roles WHERE tenant_id = $1 AND id = $2", s.current.ID, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get custom role with id '%d'", id)
+	}
+	return role.toModel(), nil
+}
+
+// SaveCustomRole creates a new role or updates an existing one when ID is given
+func (s *TenantStorage) SaveCustomRole(input *models.SaveCustomRole) (*models.CustomRole, error) {
+	permissions := make([]string, len(input.Permissions))
+	for i, p := range input.Permissions {
+		permissions[i] = string(p)
+	}
+
+	var err error
+	id := input.ID
+	if id == 0 {
+		err = s.trx.Get(&id,
+			"INSERT INTO tenant_roles (tenant_id, name, permissions) VALUES ($1, $2, $3) RETURNING id",
+			s.current.ID, input.Name, pq.Array(permissions),
+		)
+	} else {
+		_, err = s.trx.Execute(
+			"UPDATE tenant_roles SET name = $3, permissions = $4 WHERE tenant_id = $1 AND id = $2",
+			s.current.ID, id, input.Name, pq.Array(permissions),
+		)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save custom role '%s'", input.Name)
+	}
+	return s.GetCustomRoleByID(id)
+}
+
+// DeleteCustomRole removes a role defined by current tenant
+func (s *TenantStorage) DeleteCustomRole(id int) error {
+	_, err := s.trx.Execute("DELETE FROM tenant_roles WHERE tenant_id = $1 AND id = $2", s.current.ID, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete custom role with id '%d'", id)
+	}
+	return nil
+}
+
 func extractSubdomain(hostname string) string {
 	domain := env.MultiTenantDomain()
 	if domain == "" {
@@ -72,6 +72,20 @@ func TestTenantStorage_Empty_First(t *testing.T) {
 	Expect(tenant).IsNil()
 }
 
+func TestTenantStorage_GetByID(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, err := tenants.GetByID(2)
+	Expect(err).IsNil()
+	Expect(tenant.ID).Equals(2)
+	Expect(tenant.Subdomain).Equals("avengers")
+
+	tenant, err = tenants.GetByID(999)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(tenant).IsNil()
+}
+
 func TestTenantStorage_UpdatePrivacy(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -155,6 +169,525 @@ func TestTenantStorage_UpdateSettings(t *testing.T) {
 	Expect(tenant.LogoID).Equals(0)
 }
 
+func TestTenantStorage_UpdateEmailSettings(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	err := tenants.UpdateEmailSettings(&models.UpdateTenantEmailSettings{
+		FromAddress:  "feedback@demo.com",
+		ReplyTo:      "support@demo.com",
+		SMTPHost:     "smtp.demo.com",
+		SMTPPort:     "587",
+		SMTPUsername: "fider",
+		SMTPPassword: "s3cr3t",
+	})
+	Expect(err).IsNil()
+
+	err = tenants.UpdateEmailSettings(&models.UpdateTenantEmailSettings{
+		FromAddress: "feedback@demo.com",
+		SMTPHost:    "smtp.demo.com",
+		SMTPPort:    "465",
+	})
+	Expect(err).IsNil()
+
+	err = tenants.SetEmailVerifiedDomain("demo.com")
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.Email.FromAddress).Equals("feedback@demo.com")
+	Expect(tenant.Email.ReplyTo).Equals("")
+	Expect(tenant.Email.SMTPPort).Equals("465")
+	Expect(tenant.Email.SMTPUsername).Equals("")
+	Expect(tenant.Email.SMTPPassword).Equals("s3cr3t")
+	Expect(tenant.Email.VerificationToken).IsNotEmpty()
+	Expect(tenant.Email.IsVerified()).IsTrue()
+}
+
+func TestTenantStorage_SetEmailVerifiedAddress(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+	err := tenants.UpdateEmailSettings(&models.UpdateTenantEmailSettings{FromAddress: "feedback@demo.com"})
+	Expect(err).IsNil()
+
+	err = tenants.SetEmailVerifiedAddress("Feedback@demo.com")
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.Email.VerifiedAddress).Equals("feedback@demo.com")
+	Expect(tenant.Email.VerifiedDomain).Equals("")
+	Expect(tenant.Email.IsVerified()).IsTrue()
+}
+
+func TestTenantStorage_UpdateSAMLSettings(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	err := tenants.UpdateSAMLSettings(&models.UpdateTenantSAMLSettings{
+		IsEnabled:              true,
+		IsEnforced:             true,
+		IdPEntityID:            "http://idp.demo.com",
+		IdPSSOURL:              "http://idp.demo.com/sso",
+		IdPCertificate:         "MIIC",
+		EmailAttribute:         "mail",
+		RoleAttribute:          "groups",
+		AdministratorRoleValue: "fider-admins",
+	})
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.SAML.IsSSOEnforced()).IsTrue()
+	Expect(tenant.SAML.IdPEntityID).Equals("http://idp.demo.com")
+	Expect(tenant.SAML.IdPSSOURL).Equals("http://idp.demo.com/sso")
+	Expect(tenant.SAML.IdPCertificate).Equals("MIIC")
+	Expect(tenant.SAML.NameAttribute).Equals("")
+	Expect(tenant.SAML.EmailAttribute).Equals("mail")
+	Expect(tenant.SAML.RoleAttribute).Equals("groups")
+	Expect(tenant.SAML.AdministratorRoleValue).Equals("fider-admins")
+	Expect(tenant.SAML.CollaboratorRoleValue).Equals("")
+}
+
+func TestTenantStorage_UpdateJWTSSOSettings(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	err := tenants.UpdateJWTSSOSettings(&models.UpdateTenantJWTSSOSettings{IsEnabled: true, Secret: "my-secret"})
+	Expect(err).IsNil()
+
+	err = tenants.UpdateJWTSSOSettings(&models.UpdateTenantJWTSSOSettings{IsEnabled: true, PublicKey: "my-key"})
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.JWTSSO.IsEnabled).IsTrue()
+	Expect(tenant.JWTSSO.Secret).Equals("my-secret")
+	Expect(tenant.JWTSSO.PublicKey).Equals("my-key")
+}
+
+func TestTenantStorage_UpdateTwoFactorSettings(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	err := tenants.UpdateTwoFactorSettings(&models.UpdateTenantTwoFactorSettings{IsRequired: true})
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.IsTwoFactorRequired).IsTrue()
+}
+
+func TestTenantStorage_UpdateAutoJoinSettings(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	Expect(tenant.AutoJoin.Domains).HasLen(0)
+	Expect(tenant.AutoJoin.Role).Equals(models.RoleVisitor)
+	tenants.SetCurrentTenant(tenant)
+
+	err := tenants.UpdateAutoJoinSettings(&models.UpdateTenantAutoJoinSettings{
+		Domains: []string{"ourcompany.com", "subsidiary.com"},
+		Role:    models.RoleCollaborator,
+	})
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.AutoJoin.Domains).Equals([]string{"ourcompany.com", "subsidiary.com"})
+	Expect(tenant.AutoJoin.Role).Equals(models.RoleCollaborator)
+}
+
+func TestTenantStorage_CustomRoles(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenants.SetCurrentTenant(demoTenant)
+	roles, err := tenants.ListCustomRoles()
+	Expect(err).IsNil()
+	Expect(roles).HasLen(0)
+
+	support, err := tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Support",
+		Permissions: []models.Permission{models.PermissionRespond},
+	})
+	Expect(err).IsNil()
+	Expect(support.ID).NotEquals(0)
+	Expect(support.Permissions).Equals([]models.Permission{models.PermissionRespond})
+
+	moderator, err := tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Moderator",
+		Permissions: []models.Permission{models.PermissionModerateComments},
+	})
+	Expect(err).IsNil()
+
+	support, err = tenants.SaveCustomRole(&models.SaveCustomRole{
+		ID:          support.ID,
+		Name:        "Customer Support",
+		Permissions: []models.Permission{models.PermissionRespond, models.PermissionAssignTags},
+	})
+	Expect(err).IsNil()
+	Expect(support.Name).Equals("Customer Support")
+
+	roles, err = tenants.ListCustomRoles()
+	Expect(err).IsNil()
+	Expect(roles).HasLen(2)
+	Expect(roles[0].Name).Equals("Customer Support")
+	Expect(roles[0].Permissions).Equals([]models.Permission{models.PermissionRespond, models.PermissionAssignTags})
+	Expect(roles[1].ID).Equals(moderator.ID)
+
+	tenants.SetCurrentTenant(avengersTenant)
+	_, err = tenants.GetCustomRoleByID(moderator.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	tenants.SetCurrentTenant(demoTenant)
+	err = tenants.DeleteCustomRole(moderator.ID)
+	Expect(err).IsNil()
+	_, err = tenants.GetCustomRoleByID(moderator.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestTenantStorage_Suspend(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := tenants.Suspend(demoTenant.ID)
+	Expect(err).IsNil()
+
+	tenant, _ := tenants.GetByID(demoTenant.ID)
+	Expect(tenant.Status).Equals(models.TenantSuspended)
+
+	tenants.Activate(demoTenant.ID)
+	tenant, _ = tenants.GetByID(demoTenant.ID)
+	Expect(tenant.Status).Equals(models.TenantActive)
+}
+
+func TestTenantStorage_ScheduleCancelDeletion(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	now := time.Now()
+	Expect(tenants.ScheduleDeletion(demoTenant.ID, now.Add(-time.Hour))).IsNil()
+	Expect(tenants.ScheduleDeletion(avengersTenant.ID, now.Add(time.Hour))).IsNil()
+
+	tenant, _ := tenants.GetByID(demoTenant.ID)
+	Expect(tenant.IsDeletionScheduled()).IsTrue()
+	Expect(*tenant.DeletionScheduledOn).TemporarilySimilar(now.Add(-time.Hour), time.Second)
+
+	due, err := tenants.GetDueForDeletion(now)
+	Expect(err).IsNil()
+	Expect(due).HasLen(1)
+	Expect(due[0].ID).Equals(demoTenant.ID)
+
+	Expect(tenants.CancelDeletion(demoTenant.ID)).IsNil()
+	tenant, _ = tenants.GetByID(demoTenant.ID)
+	Expect(tenant.IsDeletionScheduled()).IsFalse()
+
+	due, err = tenants.GetDueForDeletion(now)
+	Expect(err).IsNil()
+	Expect(due).HasLen(0)
+}
+
+func TestTenantStorage_Purge(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	ideas.SetCurrentTenant(demoTenant)
+	ideas.SetCurrentUser(jonSnow)
+	idea, _ := ideas.Add("My new idea", "with this description")
+	ideas.AddComment(idea, "Comment #1")
+	ideas.AddSupporter(idea, aryaStark)
+	notifications.SetCurrentTenant(demoTenant)
+	notifications.Insert(aryaStark, "New idea", "/ideas/1", idea.ID)
+	sessions.SetCurrentTenant(demoTenant)
+	sessions.Create(jonSnow, "Firefox", "127.0.0.1", time.Now().Add(time.Hour))
+
+	err := tenants.Purge(demoTenant.ID)
+	Expect(err).IsNil()
+
+	_, err = tenants.GetByID(demoTenant.ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	for _, table := range []string{"ideas", "comments", "users", "notifications", "user_sessions"} {
+		var count int
+		trx.Get(&count, "SELECT COUNT(*) FROM "+table+" WHERE tenant_id = $1", demoTenant.ID)
+		Expect(count).Equals(0)
+	}
+
+	users.SetCurrentTenant(avengersTenant)
+	_, err = users.GetByID(tonyStark.ID)
+	Expect(err).IsNil()
+}
+
+func TestTenantStorage_Purge_HostnamesStayReserved(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenants.SetCurrentTenant(demoTenant)
+	err := tenants.UpdateSettings(&models.UpdateTenantSettings{
+		Title:     "Westeros",
+		Subdomain: "westeros",
+		CNAME:     "feedback.westeros.com",
+	})
+	Expect(err).IsNil()
+
+	err = tenants.Purge(demoTenant.ID)
+	Expect(err).IsNil()
+
+	var count int
+	trx.Get(&count, "SELECT COUNT(*) FROM tenant_aliases WHERE tenant_id IS NULL AND removed_on IS NOT NULL")
+	Expect(count).Equals(3)
+
+	_, err = tenants.GetByAlias("demo.test.fider.io")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	tenants.SetCurrentTenant(avengersTenant)
+	for _, subdomain := range []string{"demo", "westeros"} {
+		available, err := tenants.IsSubdomainAvailable(subdomain)
+		Expect(err).IsNil()
+		Expect(available).IsFalse()
+	}
+
+	available, err := tenants.IsCNAMEAvailable("feedback.westeros.com")
+	Expect(err).IsNil()
+	Expect(available).IsFalse()
+}
+
+func TestTenantStorage_Search(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	ideas.SetCurrentTenant(demoTenant)
+	ideas.SetCurrentUser(jonSnow)
+	ideas.Add("My new idea", "with this description")
+
+	result, err := tenants.Search("")
+	Expect(err).IsNil()
+	Expect(len(result) >= 2).IsTrue()
+
+	result, err = tenants.Search("DEMO")
+	Expect(err).IsNil()
+	Expect(result).HasLen(1)
+	Expect(result[0].ID).Equals(demoTenant.ID)
+	Expect(result[0].Subdomain).Equals("demo")
+	Expect(result[0].Status).Equals(models.TenantActive)
+	Expect(result[0].UserCount).Equals(3)
+	Expect(result[0].IdeaCount).Equals(1)
+	Expect(result[0].LastActivityOn).IsNotNil()
+	Expect(result[0].StorageUsage).Equals(int64(0))
+
+	result, err = tenants.Search("nothing-like-this")
+	Expect(err).IsNil()
+	Expect(result).HasLen(0)
+}
+
+func TestTenantStorage_ChangeSubdomain(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := tenants.ChangeSubdomain(demoTenant.ID, "westeros")
+	Expect(err).IsNil()
+
+	tenant, err := tenants.GetByDomain("westeros")
+	Expect(err).IsNil()
+	Expect(tenant.ID).Equals(demoTenant.ID)
+
+	_, err = tenants.GetByDomain("demo")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestTenantStorage_Aliases(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenants.SetCurrentTenant(demoTenant)
+	err := tenants.UpdateSettings(&models.UpdateTenantSettings{
+		Title:     "Westeros",
+		Subdomain: "westeros",
+		CNAME:     "feedback.westeros.com",
+	})
+	Expect(err).IsNil()
+
+	err = tenants.UpdateSettings(&models.UpdateTenantSettings{
+		Title:     "Westeros",
+		Subdomain: "westeros",
+		CNAME:     "ideas.westeros.com",
+	})
+	Expect(err).IsNil()
+
+	aliases, err := tenants.ListAliases()
+	Expect(err).IsNil()
+	Expect(aliases).HasLen(2)
+	Expect(aliases[0].Hostname).Equals("feedback.westeros.com")
+	Expect(aliases[0].IsCNAME).IsTrue()
+	Expect(aliases[1].Hostname).Equals("demo")
+	Expect(aliases[1].IsCNAME).IsFalse()
+
+	tenant, err := tenants.GetByAlias("demo.test.fider.io")
+	Expect(err).IsNil()
+	Expect(tenant.ID).Equals(demoTenant.ID)
+	Expect(tenant.CNAME).Equals("ideas.westeros.com")
+
+	tenant, err = tenants.GetByAlias("feedback.westeros.com")
+	Expect(err).IsNil()
+	Expect(tenant.ID).Equals(demoTenant.ID)
+
+	_, err = tenants.GetByAlias("ideas.westeros.com")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	tenants.SetCurrentTenant(avengersTenant)
+	available, err := tenants.IsSubdomainAvailable("demo")
+	Expect(err).IsNil()
+	Expect(available).IsFalse()
+
+	tenants.SetCurrentTenant(demoTenant)
+	available, err = tenants.IsSubdomainAvailable("demo")
+	Expect(err).IsNil()
+	Expect(available).IsTrue()
+
+	err = tenants.RemoveAlias(aliases[1].ID)
+	Expect(err).IsNil()
+
+	_, err = tenants.GetByAlias("demo.test.fider.io")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	tenants.SetCurrentTenant(avengersTenant)
+	available, err = tenants.IsSubdomainAvailable("demo")
+	Expect(err).IsNil()
+	Expect(available).IsFalse()
+
+	err = tenants.RemoveAlias(aliases[0].ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+}
+
+func TestTenantStorage_ChangeSubdomain_Reclaim(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenants.ChangeSubdomain(demoTenant.ID, "westeros")
+	tenants.ChangeSubdomain(demoTenant.ID, "demo")
+
+	tenants.SetCurrentTenant(demoTenant)
+	aliases, err := tenants.ListAliases()
+	Expect(err).IsNil()
+	Expect(aliases).HasLen(1)
+	Expect(aliases[0].Hostname).Equals("westeros")
+}
+
+func TestTenantStorage_OperatorAudit(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	err := tenants.AddOperatorAudit(&models.OperatorAuditEntry{
+		OperatorEmail: "ops@fider.io",
+		Action:        models.OperatorActionSuspend,
+		TenantID:      demoTenant.ID,
+		Details:       "demo",
+		IPAddress:     "127.0.0.1",
+	})
+	Expect(err).IsNil()
+
+	err = tenants.AddOperatorAudit(&models.OperatorAuditEntry{
+		OperatorEmail: "ops@fider.io",
+		Action:        models.OperatorActionImpersonate,
+		TenantID:      demoTenant.ID,
+		UserID:        jonSnow.ID,
+		Details:       "Jon Snow (jon.snow@got.com)",
+		IPAddress:     "127.0.0.1",
+	})
+	Expect(err).IsNil()
+
+	audit, err := tenants.ListOperatorAudit()
+	Expect(err).IsNil()
+	Expect(audit).HasLen(2)
+	Expect(audit[0].Action).Equals(models.OperatorActionImpersonate)
+	Expect(audit[0].UserID).Equals(jonSnow.ID)
+	Expect(audit[1].Action).Equals(models.OperatorActionSuspend)
+	Expect(audit[1].UserID).Equals(0)
+	Expect(audit[1].OperatorEmail).Equals("ops@fider.io")
+}
+
+func TestTenantStorage_OperatorSignInFailures(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	since := time.Now().Add(-time.Minute)
+	Expect(tenants.AddOperatorSignInFailure("ops@fider.io", "10.0.0.1")).IsNil()
+	Expect(tenants.AddOperatorSignInFailure("ops@fider.io", "10.0.0.2")).IsNil()
+	Expect(tenants.AddOperatorSignInFailure("admin@fider.io", "10.0.0.2")).IsNil()
+
+	failures, err := tenants.CountOperatorSignInFailures("ops@fider.io", "10.0.0.3", since)
+	Expect(err).IsNil()
+	Expect(failures).Equals(2)
+
+	failures, err = tenants.CountOperatorSignInFailures("other@fider.io", "10.0.0.2", since)
+	Expect(err).IsNil()
+	Expect(failures).Equals(2)
+
+	failures, err = tenants.CountOperatorSignInFailures("ops@fider.io", "10.0.0.1", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(failures).Equals(0)
+}
+
+func TestTenantStorage_UseSSOToken(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	ok, err := tenants.UseSSOToken("token-1", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(ok).IsTrue()
+
+	ok, err = tenants.UseSSOToken("token-1", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+
+	ok, err = tenants.UseSSOToken("token-2", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(ok).IsTrue()
+}
+
+func TestTenantStorage_UseSAMLAssertion(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	ok, err := tenants.UseSAMLAssertion("_a1", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(ok).IsTrue()
+
+	ok, err = tenants.UseSAMLAssertion("_a1", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+
+	//Same ID can be reused by other tenants
+	tenant, _ = tenants.GetByDomain("avengers")
+	tenants.SetCurrentTenant(tenant)
+	ok, err = tenants.UseSAMLAssertion("_a1", time.Now().Add(time.Minute))
+	Expect(err).IsNil()
+	Expect(ok).IsTrue()
+}
+
 func TestTenantStorage_UpdateSettings_WithLogo(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -292,6 +825,42 @@ func TestTenantStorage_SaveFindSet_VerificationKey(t *testing.T) {
 	Expect(result).IsNil()
 }
 
+func TestTenantStorage_GetDeleteVerifications(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	tenants.SaveVerificationKey("key-1", 15*time.Minute, &models.UserInvitation{Email: "hot.pie@got.com", Name: "Hot Pie"})
+	tenants.SaveVerificationKey("key-2", 15*time.Minute, &models.UserInvitation{Email: "gendry@got.com"})
+	tenants.SaveVerificationKey("key-3", 15*time.Minute, &models.SignInByEmail{Email: "jon.snow@got.com"})
+
+	result, err := tenants.GetVerificationsByKind(models.EmailVerificationKindUserInvitation)
+	Expect(err).IsNil()
+	Expect(result).HasLen(2)
+	Expect(result[0].Email).Equals("gendry@got.com")
+	Expect(result[1].Email).Equals("hot.pie@got.com")
+	Expect(result[1].Name).Equals("Hot Pie")
+
+	verification, err := tenants.GetVerificationByID(models.EmailVerificationKindUserInvitation, result[1].ID)
+	Expect(err).IsNil()
+	Expect(verification.Key).Equals("key-1")
+
+	_, err = tenants.GetVerificationByID(models.EmailVerificationKindSignIn, result[1].ID)
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	err = tenants.DeleteVerification(result[1].ID)
+	Expect(err).IsNil()
+
+	_, err = tenants.FindVerificationByKey(models.EmailVerificationKindUserInvitation, "key-1")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+
+	result, err = tenants.GetVerificationsByKind(models.EmailVerificationKindUserInvitation)
+	Expect(err).IsNil()
+	Expect(result).HasLen(1)
+}
+
 func TestTenantStorage_SaveFindSet_ChangeEmailVerificationKey(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -332,3 +901,143 @@ func TestTenantStorage_FindUnknownVerificationKey(t *testing.T) {
 	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
 	Expect(result).IsNil()
 }
+
+func TestTenantStorage_SaveGetListOAuthConfig(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenants.SetCurrentTenant(demoTenant)
+
+	config, err := tenants.GetOAuthConfigByProvider("_TEST")
+	Expect(errors.Cause(err)).Equals(app.ErrNotFound)
+	Expect(config).IsNil()
+
+	err = tenants.SaveOAuthConfig(&models.CreateEditOAuthConfig{
+		Provider:          "_TEST",
+		DisplayName:       "My Provider",
+		Status:            models.OAuthConfigEnabled,
+		ClientID:          "823187ahjjfdha8fds7yfdashfjkdsa",
+		ClientSecret:      "s3cr3t-v4lu3",
+		AuthorizeURL:      "http://provider/oauth/authorize",
+		TokenURL:          "http://provider/oauth/token",
+		ProfileURL:        "http://provider/profile/me",
+		Scope:             "profile email",
+		JSONUserIDPath:    "user.id",
+		JSONUserNamePath:  "user.name",
+		JSONUserEmailPath: "user.email",
+	})
+	Expect(err).IsNil()
+
+	err = tenants.SaveOAuthConfig(&models.CreateEditOAuthConfig{
+		Provider:            "_TEST",
+		DisplayName:         "My New Provider",
+		Status:              models.OAuthConfigDisabled,
+		ClientID:            "823187ahjjfdha8fds7yfdashfjkdsa",
+		AuthorizeURL:        "http://provider/oauth/authorize",
+		TokenURL:            "http://provider/oauth/token",
+		ProfileURL:          "http://provider/profile/me",
+		Scope:               "profile email",
+		JSONUserIDPath:      "user.id",
+		JSONUserNamePath:    "user.name",
+		JSONUserEmailPath:   "user.email",
+		EmailAlwaysVerified: true,
+	})
+	Expect(err).IsNil()
+
+	config, err = tenants.GetOAuthConfigByProvider("_TEST")
+	Expect(err).IsNil()
+	Expect(config.ID).Equals(1)
+	Expect(config.DisplayName).Equals("My New Provider")
+	Expect(config.Status).Equals(models.OAuthConfigDisabled)
+	Expect(config.ClientSecret).Equals("s3cr3t-v4lu3")
+	Expect(config.JSONUserIDPath).Equals("user.id")
+	Expect(config.EmailAlwaysVerified).IsTrue()
+
+	configs, err := tenants.ListOAuthConfig()
+	Expect(err).IsNil()
+	Expect(configs).HasLen(1)
+	Expect(configs[0].Provider).Equals("_TEST")
+
+	tenants.SetCurrentTenant(avengersTenant)
+	configs, err = tenants.ListOAuthConfig()
+	Expect(err).IsNil()
+	Expect(configs).HasLen(0)
+}
+
+func TestTenantStorage_ChangePlan_Usage(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	Expect(demoTenant.Plan).Equals(models.PlanUnlimited)
+
+	err := tenants.ChangePlan(demoTenant.ID, models.PlanFree.Name)
+	Expect(err).IsNil()
+
+	tenant, err := tenants.GetByID(demoTenant.ID)
+	Expect(err).IsNil()
+	Expect(tenant.Plan).Equals(models.PlanFree)
+
+	tenants.SetCurrentTenant(tenant)
+	usage, err := tenants.GetUsage()
+	Expect(err).IsNil()
+	Expect(usage.Plan).Equals(models.PlanFree)
+	Expect(usage.Users).Equals(3)
+
+	ideas.SetCurrentTenant(tenant)
+	ideas.SetCurrentUser(jonSnow)
+	ideas.Add("My new idea", "with this description")
+
+	newUsage, err := tenants.GetUsage()
+	Expect(err).IsNil()
+	Expect(newUsage.Ideas).Equals(usage.Ideas + 1)
+}
+
+func TestTenantStorage_UpdateTheme(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenant, _ := tenants.GetByDomain("demo")
+	tenants.SetCurrentTenant(tenant)
+
+	favicon, _ := ioutil.ReadFile(env.Path("./favicon.ico"))
+
+	settings := &models.UpdateTenantTheme{
+		PrimaryColor: "FF6600",
+		AccentColor:  "333333",
+		CustomCSS:    ".header { color: red; }",
+		Favicon: &models.UpdateTenantSettingsLogo{
+			Upload: &models.UpdateTenantSettingsLogoUpload{
+				Content: favicon,
+			},
+		},
+	}
+	err := tenants.UpdateTheme(settings)
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.Theme.PrimaryColor).Equals("FF6600")
+	Expect(tenant.Theme.AccentColor).Equals("333333")
+	Expect(tenant.Theme.CustomCSS).Equals(".header { color: red; }")
+	Expect(tenant.Theme.FaviconID).NotEquals(0)
+	Expect(tenant.Theme.HeaderID).Equals(0)
+
+	tenants.SetCurrentTenant(tenant)
+	upload, err := tenants.GetThemeImage(tenant.Theme.FaviconID)
+	Expect(err).IsNil()
+	Expect(upload.Content).Equals(favicon)
+
+	//Logo can't be read as a theme image
+	upload, err = tenants.GetThemeImage(tenant.LogoID)
+	Expect(err).Equals(app.ErrNotFound)
+	Expect(upload).IsNil()
+
+	//Colors change without touching the favicon
+	err = tenants.UpdateTheme(&models.UpdateTenantTheme{PrimaryColor: "000000"})
+	Expect(err).IsNil()
+
+	tenant, err = tenants.GetByDomain("demo")
+	Expect(err).IsNil()
+	Expect(tenant.Theme.PrimaryColor).Equals("000000")
+	Expect(tenant.Theme.FaviconID).NotEquals(0)
+}
@@ -13,12 +13,17 @@ import (
 )
 
 type dbUser struct {
-	ID        sql.NullInt64  `db:"id"`
-	Name      sql.NullString `db:"name"`
-	Email     sql.NullString `db:"email"`
-	Tenant    *dbTenant      `db:"tenant"`
-	Role      sql.NullInt64  `db:"role"`
-	Providers []*dbUserProvider
+	ID          sql.NullInt64  `db:"id"`
+	Name        sql.NullString `db:"name"`
+	Email       sql.NullString `db:"email"`
+	Tenant      *dbTenant      `db:"tenant"`
+	Role        sql.NullInt64  `db:"role"`
+	AvatarURL   sql.NullString `db:"avatar_url"`
+	Locale      sql.NullString `db:"locale"`
+	TOTPSecret  sql.NullString `db:"totp_secret"`
+	TOTPEnabled sql.NullBool   `db:"totp_enabled"`
+	CustomRole  sql.NullInt64  `db:"custom_role_id"`
+	Providers   []*dbUserProvider
 }
 
 type dbUserProvider struct {
@@ -37,7 +42,12 @@ func (u *dbUser) toModel() *models.User {
 		Email:     u.Email.String,
 		Tenant:    u.Tenant.toModel(),
 		Role:      models.Role(u.Role.Int64),
+		AvatarURL: u.AvatarURL.String,
+		Locale:    u.Locale.String,
 		Providers: make([]*models.UserProvider, len(u.Providers)),
+
+		TOTPSecret:    u.TOTPSecret.String,
+		IsTOTPEnabled: u.TOTPEnabled.Bool,
 	}
 
 	for i, p := range u.Providers {
@@ -118,8 +128,8 @@ func (s *UserStorage) Register(user *models.User) error {
 	now := time.Now()
 	user.Email = strings.TrimSpace(user.Email)
 	if err := s.trx.Get(&user.ID,
-		"INSERT INTO users (name, email, created_on, tenant_id, role) VALUES ($1, $2, $3, $4, $5) RETURNING id",
-		user.Name, user.Email, now, s.tenant.ID, user.Role); err != nil {
+		"INSERT INTO users (name, email, created_on, tenant_id, role, avatar_url) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		user.Name, user.Email, now, s.tenant.ID, user.Role, user.AvatarURL); err != nil {
 		return errors.Wrap(err, "failed to register new user")
 	}
 
@@ -144,8 +154,8 @@ func (s *UserStorage) RegisterProvider(userID int, provider *models.UserProvider
 
 // Update user profile
 func (s *UserStorage) Update(settings *models.UpdateUserSettings) error {
-	cmd := "UPDATE users SET name = $2 WHERE id = $1 AND tenant_id = $3"
-	_, err := s.trx.Execute(cmd, s.user.ID, settings.Name, s.tenant.ID)
+	cmd := "UPDATE users SET name = $2, locale = $4 WHERE id = $1 AND tenant_id = $3"
+	_, err := s.trx.Execute(cmd, s.user.ID, settings.Name, s.tenant.ID, settings.Locale)
 	if err != nil {
 		return errors.Wrap(err, "failed to update user")
 	}
@@ -201,7 +211,7 @@ func (s *UserStorage) GetUserSettings() (map[string]string, error) {
 
 // ChangeRole of given user
 func (s *UserStorage) ChangeRole(userID int, role models.Role) error {
-	cmd := "UPDATE users SET role = $3 WHERE id = $1 AND tenant_id = $2"
+	cmd := "UPDATE users SET role = $3, custom_role_id = NULL WHERE id = $1 AND tenant_id = $2"
 	_, err := s.trx.Execute(cmd, userID, s.tenant.ID, role)
 	if err != nil {
 		return errors.Wrap(err, "failed to change user's role")
@@ -209,6 +219,16 @@ func (s *UserStorage) ChangeRole(userID int, role models.Role) error {
 	return nil
 }
 
+// AssignCustomRole of given user, who becomes a collaborator with the permissions of that role
+func (s *UserStorage) AssignCustomRole(userID int, role *models.CustomRole) error {
+	cmd := "UPDATE users SET role = $3, custom_role_id = $4 WHERE id = $1 AND tenant_id = $2"
+	_, err := s.trx.Execute(cmd, userID, s.tenant.ID, models.RoleCollaborator, role.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to assign custom role to user")
+	}
+	return nil
+}
+
 // ChangeEmail of given user
 func (s *UserStorage) ChangeEmail(userID int, email string) error {
 	cmd := "UPDATE users SET email = $3 WHERE id = $1 AND tenant_id = $2"
@@ -219,10 +239,110 @@ func (s *UserStorage) ChangeEmail(userID int, email string) error {
 	return nil
 }
 
+// ChangeAvatarURL of given user
+func (s *UserStorage) ChangeAvatarURL(userID int, avatarURL string) error {
+	cmd := "UPDATE users SET avatar_url = $3 WHERE id = $1 AND tenant_id = $2"
+	_, err := s.trx.Execute(cmd, userID, s.tenant.ID, avatarURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to update user's avatar url")
+	}
+	return nil
+}
+
+// SetTOTPSecret stores the secret being enrolled by given user, which is only used after being enabled
+func (s *UserStorage) SetTOTPSecret(userID int, secret string) error {
+	cmd := "UPDATE users SET totp_secret = $3 WHERE id = $1 AND tenant_id = $2 AND totp_enabled = false"
+	_, err := s.trx.Execute(cmd, userID, s.tenant.ID, secret)
+	if err != nil {
+		return errors.Wrap(err, "failed to set user's TOTP secret")
+	}
+	return nil
+}
+
+// EnableTOTP turns on two-factor authentication of given user and replaces the recovery codes
+func (s *UserStorage) EnableTOTP(userID int, recoveryCodeHashes []string) error {
+	cmd := "UPDATE users SET totp_enabled = true WHERE id = $1 AND tenant_id = $2 AND totp_secret <> ''"
+	if _, err := s.trx.Execute(cmd, userID, s.tenant.ID); err != nil {
+		return errors.Wrap(err, "failed to enable user's TOTP")
+	}
+
+	cmd = "DELETE FROM user_recovery_codes WHERE user_id = $1 AND tenant_id = $2"
+	if _, err := s.trx.Execute(cmd, userID, s.tenant.ID); err != nil {
+		return errors.Wrap(err, "failed to delete user's recovery codes")
+	}
+
+	cmd = "INSERT INTO user_recovery_codes (tenant_id, user_id, code_hash) VALUES ($1, $2, $3)"
+	for _, hash := range recoveryCodeHashes {
+		if _, err := s.trx.Execute(cmd, s.tenant.ID, userID, hash); err != nil {
+			return errors.Wrap(err, "failed to add user's recovery code")
+		}
+	}
+	return nil
+}
+
+// DisableTOTP turns off two-factor authentication of given user
+func (s *UserStorage) DisableTOTP(userID int) error {
+	cmd := "UPDATE users SET totp_enabled = false, totp_secret = '', totp_last_step = 0 WHERE id = $1 AND tenant_id = $2"
+	if _, err := s.trx.Execute(cmd, userID, s.tenant.ID); err != nil {
+		return errors.Wrap(err, "failed to disable user's TOTP")
+	}
+
+	cmd = "DELETE FROM user_recovery_codes WHERE user_id = $1 AND tenant_id = $2"
+	if _, err := s.trx.Execute(cmd, userID, s.tenant.ID); err != nil {
+		return errors.Wrap(err, "failed to delete user's recovery codes")
+	}
+	return nil
+}
+
+// UseTOTPStep records given time step as used and returns false if it, or a later one, has been used before
+func (s *UserStorage) UseTOTPStep(userID int, step int64) (bool, error) {
+	cmd := "UPDATE users SET totp_last_step = $3 WHERE id = $1 AND tenant_id = $2 AND totp_last_step < $3"
+	rows, err := s.trx.Execute(cmd, userID, s.tenant.ID, step)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to use TOTP step")
+	}
+	return rows == 1, nil
+}
+
+// UseRecoveryCode marks given recovery code as used and returns false if it doesn't exist or has been used before
+func (s *UserStorage) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	cmd := `UPDATE user_recovery_codes SET used_on = $4
+		WHERE user_id = $1 AND tenant_id = $2 AND code_hash = $3 AND used_on IS NULL`
+	rows, err := s.trx.Execute(cmd, userID, s.tenant.ID, codeHash, time.Now())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to use recovery code")
+	}
+	return rows >= 1, nil
+}
+
+// AddTwoFactorFailure records a wrong code given by the user and returns how many were given since given time
+func (s *UserStorage) AddTwoFactorFailure(userID int, since time.Time) (int, error) {
+	var attempts int
+	cmd := `UPDATE users SET
+		totp_failed_attempts = CASE WHEN totp_failed_on > $3 THEN totp_failed_attempts + 1 ELSE 1 END,
+		totp_failed_on = $4
+		WHERE id = $1 AND tenant_id = $2 RETURNING totp_failed_attempts`
+	if err := s.trx.Scalar(&attempts, cmd, userID, s.tenant.ID, since, time.Now()); err != nil {
+		return 0, errors.Wrap(err, "failed to add two-factor failure")
+	}
+	return attempts, nil
+}
+
+// CountTwoFactorFailures returns how many wrong codes were given by the user since given time
+func (s *UserStorage) CountTwoFactorFailures(userID int, since time.Time) (int, error) {
+	var attempts int
+	query := `SELECT CASE WHEN totp_failed_on > $3 THEN totp_failed_attempts ELSE 0 END
+		FROM users WHERE id = $1 AND tenant_id = $2`
+	if err := s.trx.Scalar(&attempts, query, userID, s.tenant.ID, since); err != nil {
+		return 0, errors.Wrap(err, "failed to count two-factor failures")
+	}
+	return attempts, nil
+}
+
 // GetByID returns a user based on given id
 func getUser(trx *dbx.Trx, filter string, args ...interface{}) (*models.User, error) {
 	user := dbUser{}
-	err := trx.Get(&user, "SELECT id, name, email, tenant_id, role FROM users WHERE "+filter, args...)
+	err := trx.Get(&user, "SELECT id, name, email, tenant_id, role, avatar_url, locale, totp_secret, totp_enabled, custom_role_id FROM users WHERE "+filter, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -232,20 +352,48 @@ func getUser(trx *dbx.Trx, filter string, args ...interface{}) (*models.User, er
 		return nil, err
 	}
 
-	return user.toModel(), nil
+	result := user.toModel()
+	if user.CustomRole.Valid {
+		role := &dbCustomRole{}
+		err = trx.Get(role, "SELECT id, name, permissions FROM tenant_roles WHERE id = $1", user.CustomRole.Int64)
+		if err != nil {
+			return nil, err
+		}
+		result.CustomRole = role.toModel()
+	}
+	return result, nil
 }
 
 // GetAll return all users of current tenant
 func (s *UserStorage) GetAll() ([]*models.User, error) {
 	var users []*dbUser
-	err := s.trx.Select(&users, "SELECT id, name, email, tenant_id, role FROM users WHERE tenant_id = $1 ORDER BY id", s.tenant.ID)
+	err := s.trx.Select(&users, "SELECT id, name, email, tenant_id, role, avatar_url, locale, totp_secret, totp_enabled, custom_role_id FROM users WHERE tenant_id = $1 ORDER BY id", s.tenant.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get all users")
 	}
 
+	return withCustomRoles(s.trx, s.tenant, users)
+}
+
+//withCustomRoles converts given users to models with the custom role of each one attached
+func withCustomRoles(trx *dbx.Trx, tenant *models.Tenant, users []*dbUser) ([]*models.User, error) {
+	var roles []*dbCustomRole
+	err := trx.Select(&roles, "SELECT id, name, permissions FROM tenant_roles WHERE tenant_id = $1", tenant.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get custom roles of users")
+	}
+
+	rolesByID := make(map[int64]*models.CustomRole, len(roles))
+	for _, role := range roles {
+		rolesByID[int64(role.ID)] = role.toModel()
+	}
+
 	var result = make([]*models.User, len(users))
 	for i, user := range users {
 		result[i] = user.toModel()
+		if user.CustomRole.Valid {
+			result[i].CustomRole = rolesByID[user.CustomRole.Int64]
+		}
 	}
 	return result, nil
 }
@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
@@ -222,6 +223,150 @@ func TestUserStorage_ChangeRole(t *testing.T) {
 	Expect(user.Role).Equals(models.RoleVisitor)
 }
 
+func TestUserStorage_AssignCustomRole(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	tenants.SetCurrentTenant(demoTenant)
+	moderator, _ := tenants.SaveCustomRole(&models.SaveCustomRole{
+		Name:        "Moderator",
+		Permissions: []models.Permission{models.PermissionModerateComments},
+	})
+
+	users.SetCurrentTenant(demoTenant)
+	err := users.AssignCustomRole(aryaStark.ID, moderator)
+	Expect(err).IsNil()
+
+	user, err := users.GetByID(aryaStark.ID)
+	Expect(err).IsNil()
+	Expect(user.Role).Equals(models.RoleCollaborator)
+	Expect(user.CustomRole.Name).Equals("Moderator")
+	Expect(user.Can(models.PermissionModerateComments)).IsTrue()
+	Expect(user.Can(models.PermissionRespond)).IsFalse()
+
+	all, err := users.GetAll()
+	Expect(err).IsNil()
+	for _, u := range all {
+		if u.ID == aryaStark.ID {
+			Expect(u.CustomRole.ID).Equals(moderator.ID)
+		}
+	}
+
+	err = users.ChangeRole(aryaStark.ID, models.RoleVisitor)
+	Expect(err).IsNil()
+
+	user, err = users.GetByID(aryaStark.ID)
+	Expect(err).IsNil()
+	Expect(user.Role).Equals(models.RoleVisitor)
+	Expect(user.CustomRole).IsNil()
+}
+
+func TestUserStorage_ChangeAvatarURL(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	users.SetCurrentTenant(demoTenant)
+	err := users.ChangeAvatarURL(jonSnow.ID, "https://got.com/jon.png")
+	Expect(err).IsNil()
+
+	user, err := users.GetByEmail("jon.snow@got.com")
+	Expect(err).IsNil()
+	Expect(user.AvatarURL).Equals("https://got.com/jon.png")
+}
+
+func TestUserStorage_TOTP(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	users.SetCurrentTenant(demoTenant)
+	err := users.SetTOTPSecret(jonSnow.ID, "JBSWY3DPEHPK3PXP")
+	Expect(err).IsNil()
+
+	user, err := users.GetByID(jonSnow.ID)
+	Expect(err).IsNil()
+	Expect(user.TOTPSecret).Equals("JBSWY3DPEHPK3PXP")
+	Expect(user.IsTOTPEnabled).IsFalse()
+
+	err = users.EnableTOTP(jonSnow.ID, []string{"hash-1", "hash-2"})
+	Expect(err).IsNil()
+
+	err = users.SetTOTPSecret(jonSnow.ID, "ANOTHERSECRET")
+	Expect(err).IsNil()
+
+	user, err = users.GetByID(jonSnow.ID)
+	Expect(err).IsNil()
+	Expect(user.TOTPSecret).Equals("JBSWY3DPEHPK3PXP")
+	Expect(user.IsTOTPEnabled).IsTrue()
+
+	ok, err := users.UseTOTPStep(jonSnow.ID, 100)
+	Expect(err).IsNil()
+	Expect(ok).IsTrue()
+
+	ok, err = users.UseTOTPStep(jonSnow.ID, 100)
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+
+	ok, err = users.UseTOTPStep(jonSnow.ID, 99)
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+
+	ok, err = users.UseRecoveryCode(jonSnow.ID, "hash-1")
+	Expect(err).IsNil()
+	Expect(ok).IsTrue()
+
+	ok, err = users.UseRecoveryCode(jonSnow.ID, "hash-1")
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+
+	ok, err = users.UseRecoveryCode(aryaStark.ID, "hash-2")
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+
+	err = users.DisableTOTP(jonSnow.ID)
+	Expect(err).IsNil()
+
+	user, err = users.GetByID(jonSnow.ID)
+	Expect(err).IsNil()
+	Expect(user.TOTPSecret).Equals("")
+	Expect(user.IsTOTPEnabled).IsFalse()
+
+	ok, err = users.UseRecoveryCode(jonSnow.ID, "hash-2")
+	Expect(err).IsNil()
+	Expect(ok).IsFalse()
+}
+
+func TestUserStorage_TwoFactorFailures(t *testing.T) {
+	SetupDatabaseTest(t)
+	defer TeardownDatabaseTest()
+
+	users.SetCurrentTenant(demoTenant)
+	since := time.Now().Add(-15 * time.Minute)
+
+	count, err := users.CountTwoFactorFailures(jonSnow.ID, since)
+	Expect(err).IsNil()
+	Expect(count).Equals(0)
+
+	count, err = users.AddTwoFactorFailure(jonSnow.ID, since)
+	Expect(err).IsNil()
+	Expect(count).Equals(1)
+
+	count, err = users.AddTwoFactorFailure(jonSnow.ID, since)
+	Expect(err).IsNil()
+	Expect(count).Equals(2)
+
+	count, err = users.CountTwoFactorFailures(jonSnow.ID, since)
+	Expect(err).IsNil()
+	Expect(count).Equals(2)
+
+	count, err = users.CountTwoFactorFailures(jonSnow.ID, time.Now().Add(1*time.Minute))
+	Expect(err).IsNil()
+	Expect(count).Equals(0)
+
+	count, err = users.AddTwoFactorFailure(jonSnow.ID, time.Now().Add(1*time.Minute))
+	Expect(err).IsNil()
+	Expect(count).Equals(1)
+}
+
 func TestUserStorage_ChangeEmail(t *testing.T) {
 	SetupDatabaseTest(t)
 	defer TeardownDatabaseTest()
@@ -27,6 +27,7 @@ type Idea interface {
 	AddComment(idea *models.Idea, content string) (int, error)
 	GetCommentByID(id int) (*models.Comment, error)
 	UpdateComment(id int, content string) error
+	DeleteComment(id int) error
 	AddSupporter(idea *models.Idea, user *models.User) error
 	RemoveSupporter(idea *models.Idea, user *models.User) error
 	AddSubscriber(idea *models.Idea, user *models.User) error
@@ -35,6 +36,7 @@ type Idea interface {
 	SetResponse(idea *models.Idea, text string, status int) error
 	MarkAsDuplicate(idea *models.Idea, original *models.Idea) error
 	IsReferenced(idea *models.Idea) (bool, error)
+	SetPrivacy(idea *models.Idea, isPrivate bool) error
 	SupportedBy() ([]int, error)
 }
 
@@ -49,6 +51,15 @@ type User interface {
 	Update(settings *models.UpdateUserSettings) error
 	ChangeEmail(userID int, email string) error
 	ChangeRole(userID int, role models.Role) error
+	AssignCustomRole(userID int, role *models.CustomRole) error
+	ChangeAvatarURL(userID int, avatarURL string) error
+	SetTOTPSecret(userID int, secret string) error
+	EnableTOTP(userID int, recoveryCodeHashes []string) error
+	DisableTOTP(userID int) error
+	UseTOTPStep(userID int, step int64) (bool, error)
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	AddTwoFactorFailure(userID int, since time.Time) (int, error)
+	CountTwoFactorFailures(userID int, since time.Time) (int, error)
 	GetAll() ([]*models.User, error)
 	GetUserSettings() (map[string]string, error)
 	UpdateSettings(settings map[string]string) error
@@ -60,16 +71,57 @@ type Tenant interface {
 	Base
 	Add(name string, subdomain string, status int) (*models.Tenant, error)
 	First() (*models.Tenant, error)
+	GetByID(id int) (*models.Tenant, error)
 	Activate(id int) error
+	Suspend(id int) error
+	ScheduleDeletion(id int, on time.Time) error
+	CancelDeletion(id int) error
+	GetDueForDeletion(before time.Time) ([]*models.Tenant, error)
+	LockForPurge() (bool, error)
+	Purge(id int) error
+	Search(query string) ([]*models.TenantSummary, error)
+	ChangeSubdomain(id int, subdomain string) error
+	ChangePlan(id int, plan string) error
+	GetUsage() (*models.TenantUsage, error)
+	AddOperatorAudit(entry *models.OperatorAuditEntry) error
+	ListOperatorAudit() ([]*models.OperatorAuditEntry, error)
+	AddOperatorSignInFailure(email, ipAddress string) error
+	CountOperatorSignInFailures(email, ipAddress string, since time.Time) (int, error)
 	GetByDomain(domain string) (*models.Tenant, error)
+	GetByAlias(domain string) (*models.Tenant, error)
+	ListAliases() ([]*models.TenantAlias, error)
+	RemoveAlias(id int) error
+	GetCertificateInfo(hostname string) (*models.CertificateInfo, error)
 	UpdateSettings(settings *models.UpdateTenantSettings) error
 	UpdatePrivacy(settings *models.UpdateTenantPrivacy) error
+	UpdateEmailSettings(settings *models.UpdateTenantEmailSettings) error
+	SetEmailVerifiedDomain(domain string) error
+	SetEmailVerifiedAddress(address string) error
+	UpdateSAMLSettings(settings *models.UpdateTenantSAMLSettings) error
+	UpdateJWTSSOSettings(settings *models.UpdateTenantJWTSSOSettings) error
+	UpdateTwoFactorSettings(settings *models.UpdateTenantTwoFactorSettings) error
+	UpdateAutoJoinSettings(settings *models.UpdateTenantAutoJoinSettings) error
+	UpdateEmbedSettings(settings *models.UpdateTenantEmbedSettings) error
+	UseSSOToken(id string, expiresOn time.Time) (bool, error)
+	UseSAMLAssertion(id string, expiresOn time.Time) (bool, error)
 	IsSubdomainAvailable(subdomain string) (bool, error)
 	IsCNAMEAvailable(cname string) (bool, error)
 	SaveVerificationKey(key string, duration time.Duration, request models.NewEmailVerification) error
 	FindVerificationByKey(kind models.EmailVerificationKind, key string) (*models.EmailVerification, error)
+	GetVerificationsByKind(kind models.EmailVerificationKind) ([]*models.EmailVerification, error)
+	GetVerificationByID(kind models.EmailVerificationKind, id int) (*models.EmailVerification, error)
+	DeleteVerification(id int) error
 	SetKeyAsVerified(key string) error
 	GetLogo(id int) (*models.Upload, error)
+	UpdateTheme(settings *models.UpdateTenantTheme) error
+	GetThemeImage(id int) (*models.Upload, error)
+	GetOAuthConfigByProvider(provider string) (*models.OAuthConfig, error)
+	ListOAuthConfig() ([]*models.OAuthConfig, error)
+	SaveOAuthConfig(config *models.CreateEditOAuthConfig) error
+	ListCustomRoles() ([]*models.CustomRole, error)
+	GetCustomRoleByID(id int) (*models.CustomRole, error)
+	SaveCustomRole(role *models.SaveCustomRole) (*models.CustomRole, error)
+	DeleteCustomRole(id int) error
 }
 
 // Tag contains read and write operations for tags
@@ -95,3 +147,45 @@ type Notification interface {
 	GetActiveNotifications() ([]*models.Notification, error)
 	GetNotification(id int) (*models.Notification, error)
 }
+
+// EmailTemplate contains read and write operations for customized email templates
+type EmailTemplate interface {
+	Base
+	GetAll() ([]*models.EmailTemplate, error)
+	Get(name, locale string) (*models.EmailTemplate, error)
+	Save(template *models.SaveEmailTemplate) (*models.EmailTemplate, error)
+	Delete(name, locale string) error
+}
+
+// Page contains read and write operations for pages authored by tenants
+type Page interface {
+	Base
+	GetAll() ([]*models.Page, error)
+	GetNavigation() ([]*models.Page, error)
+	GetBySlug(slug string) (*models.Page, error)
+	Add(input *models.SavePage) (*models.Page, error)
+	Update(page *models.Page, input *models.SavePage) (*models.Page, error)
+	Delete(page *models.Page) error
+}
+
+// EmailLog contains read and write operations for email deliveries and suppressed addresses
+type EmailLog interface {
+	Base
+	Add(delivery *models.EmailDelivery) error
+	GetRecent(limit int) ([]*models.EmailDelivery, error)
+	GetSuppressions() ([]*models.EmailSuppression, error)
+	IsSuppressed(address string) (bool, error)
+	Suppress(address, reason string) error
+	Unsuppress(address string) error
+}
+
+// Session is used to manage the devices where users are signed in
+type Session interface {
+	Base
+	Create(user *models.User, userAgent, ipAddress string, expiresOn time.Time) (*models.Session, error)
+	GetByID(id string) (*models.Session, error)
+	GetActiveByUser(userID int) ([]*models.Session, error)
+	Touch(id string) error
+	Revoke(userID int, id string) error
+	RevokeAll(userID int) error
+}
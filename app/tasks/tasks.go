@@ -9,7 +9,7 @@ import (
 	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/models"
 	"github.com/getfider/fider/app/pkg/email"
-	"github.com/getfider/fider/app/pkg/markdown"
+	"github.com/getfider/fider/app/pkg/errors"
 	"github.com/getfider/fider/app/pkg/worker"
 )
 
@@ -31,7 +31,7 @@ func SendSignUpEmail(model *models.CreateTenant, baseURL string) worker.Task {
 		to := email.NewRecipient(model.Name, model.Email, email.Params{
 			"link": link(baseURL, "/signup/verify?k=%s", model.VerificationKey),
 		})
-		return c.Services().Emailer.Send(c.Tenant(), "signup_email", email.Params{}, "Fider", to)
+		return c.Services().Emailer.Send(c.Tenant(), c.Services().EmailTemplates, "signup_email", email.Params{}, "Fider", to)
 	})
 }
 
@@ -42,7 +42,7 @@ func SendSignInEmail(model *models.SignInByEmail) worker.Task {
 			"tenantName": c.Tenant().Name,
 			"link":       link(c.BaseURL(), "/signin/verify?k=%s", model.VerificationKey),
 		})
-		return c.Services().Emailer.Send(c.Tenant(), "signin_email", email.Params{}, c.Tenant().Name, to)
+		return c.Services().Emailer.Send(c.Tenant(), c.Services().EmailTemplates, "signin_email", email.Params{}, c.Tenant().Name, to)
 	})
 }
 
@@ -60,7 +60,20 @@ func SendChangeEmailConfirmation(model *models.ChangeUserEmail) worker.Task {
 			"newEmail": model.Email,
 			"link":     link(c.BaseURL(), "/change-email/verify?k=%s", model.VerificationKey),
 		})
-		return c.Services().Emailer.Send(c.Tenant(), "change_emailaddress_email", email.Params{}, c.Tenant().Name, to)
+		to.Locale = model.Requestor.Locale
+		return c.Services().Emailer.Send(c.Tenant(), c.Services().EmailTemplates, "change_emailaddress_email", email.Params{}, c.Tenant().Name, to)
+	})
+}
+
+//SendSenderVerification is used to send the verification email to the address a tenant wants to send emails from
+func SendSenderVerification(model *models.VerifySenderAddress) worker.Task {
+	return describe("Send sender address verification", func(c *worker.Context) error {
+		to := email.NewRecipient("", model.Email, email.Params{
+			"tenantName": c.Tenant().Name,
+			"address":    model.Email,
+			"link":       link(c.BaseURL(), "/admin/email/verify?k=%s", model.VerificationKey),
+		})
+		return c.Services().Emailer.Send(c.Tenant(), c.Services().EmailTemplates, "verify_sender_email", email.Params{}, c.Tenant().Name, to)
 	})
 }
 
@@ -90,18 +103,19 @@ func NotifyAboutNewIdea(idea *models.Idea) worker.Task {
 		to := make([]email.Recipient, 0)
 		for _, user := range users {
 			if user.ID != c.User().ID {
-				to = append(to, email.NewRecipient(user.Name, user.Email, email.Params{}))
+				to = append(to, email.NewUserRecipient(user, email.Params{}))
 			}
 		}
 
 		params := email.Params{
 			"title":   fmt.Sprintf("[%s] %s", c.Tenant().Name, idea.Title),
-			"content": markdown.Parse(idea.Description),
+			"content": email.Markdown(idea.Description),
 			"view":    linkWithText("View it on your browser", c.BaseURL(), "/ideas/%d/%s", idea.Number, idea.Slug),
 			"change":  linkWithText("change your notification settings", c.BaseURL(), "/settings"),
 		}
 
-		return c.Services().Emailer.BatchSend(c.Tenant(), "new_idea", params, c.User().Name, to)
+		params.SetThread(email.NewIdeaThread(c.Tenant(), idea, false))
+		return c.Services().Emailer.BatchSend(c.Tenant(), c.Services().EmailTemplates, "new_idea", params, c.User().Name, to)
 	})
 }
 
@@ -131,19 +145,20 @@ func NotifyAboutNewComment(idea *models.Idea, comment *models.NewComment) worker
 		to := make([]email.Recipient, 0)
 		for _, user := range users {
 			if user.ID != c.User().ID {
-				to = append(to, email.NewRecipient(user.Name, user.Email, email.Params{}))
+				to = append(to, email.NewUserRecipient(user, email.Params{}))
 			}
 		}
 
 		params := email.Params{
 			"title":       fmt.Sprintf("[%s] %s", c.Tenant().Name, idea.Title),
-			"content":     markdown.Parse(comment.Content),
+			"content":     email.Markdown(comment.Content),
 			"view":        linkWithText("View it on your browser", c.BaseURL(), "/ideas/%d/%s", idea.Number, idea.Slug),
 			"unsubscribe": linkWithText("unsubscribe from it", c.BaseURL(), "/ideas/%d/%s", idea.Number, idea.Slug),
 			"change":      linkWithText("change your notification settings", c.BaseURL(), "/settings"),
 		}
 
-		return c.Services().Emailer.BatchSend(c.Tenant(), "new_comment", params, c.User().Name, to)
+		params.SetThread(email.NewIdeaThread(c.Tenant(), idea, true))
+		return c.Services().Emailer.BatchSend(c.Tenant(), c.Services().EmailTemplates, "new_comment", params, c.User().Name, to)
 	})
 }
 
@@ -187,13 +202,13 @@ func NotifyAboutStatusChange(idea *models.Idea, response *models.SetResponse) wo
 		to := make([]email.Recipient, 0)
 		for _, user := range users {
 			if user.ID != c.User().ID {
-				to = append(to, email.NewRecipient(user.Name, user.Email, email.Params{}))
+				to = append(to, email.NewUserRecipient(user, email.Params{}))
 			}
 		}
 
 		params := email.Params{
 			"title":       fmt.Sprintf("[%s] %s", c.Tenant().Name, idea.Title),
-			"content":     markdown.Parse(response.Text),
+			"content":     email.Markdown(response.Text),
 			"status":      models.GetIdeaStatusName(response.Status),
 			"duplicate":   duplicate,
 			"view":        linkWithText("View it on your browser", c.BaseURL(), "/ideas/%d/%s", idea.Number, idea.Slug),
@@ -201,28 +216,126 @@ func NotifyAboutStatusChange(idea *models.Idea, response *models.SetResponse) wo
 			"change":      linkWithText("change your notification settings", c.BaseURL(), "/settings"),
 		}
 
-		return c.Services().Emailer.BatchSend(c.Tenant(), "change_status", params, c.User().Name, to)
+		params.SetThread(email.NewIdeaThread(c.Tenant(), idea, true))
+		return c.Services().Emailer.BatchSend(c.Tenant(), c.Services().EmailTemplates, "change_status", params, c.User().Name, to)
 	})
 }
 
 //SendInvites sends one email to each invited recipient
 func SendInvites(subject, message string, invitations []*models.UserInvitation) worker.Task {
 	return describe("Send invites", func(c *worker.Context) error {
-		to := make([]email.Recipient, len(invitations))
-		for i, invite := range invitations {
-			err := c.Services().Tenants.SaveVerificationKey(invite.VerificationKey, 15*24*time.Hour, invite)
+		to := make([]email.Recipient, 0, len(invitations))
+		for _, invite := range invitations {
+			//Users might have joined while invitations were waiting to be sent
+			_, err := c.Services().Users.GetByEmail(invite.Email)
+			if err == nil {
+				continue
+			} else if errors.Cause(err) != app.ErrNotFound {
+				return c.Failure(err)
+			}
+
+			err = c.Services().Tenants.SaveVerificationKey(invite.VerificationKey, 15*24*time.Hour, invite)
 			if err != nil {
 				return c.Failure(err)
 			}
 
 			url := link(c.BaseURL(), "/invite/verify?k=%s", invite.VerificationKey)
 			toMessage := strings.Replace(message, app.InvitePlaceholder, string(url), -1)
-			to[i] = email.NewRecipient("", invite.Email, email.Params{
-				"message": markdown.Parse(toMessage),
-			})
+			to = append(to, email.NewRecipient(invite.Name, invite.Email, email.Params{
+				"message": email.Markdown(toMessage),
+			}))
+		}
+
+		if len(to) == 0 {
+			return nil
 		}
-		return c.Services().Emailer.BatchSend(c.Tenant(), "invite_email", email.Params{
+
+		return c.Services().Emailer.BatchSend(c.Tenant(), c.Services().EmailTemplates, "invite_email", email.Params{
 			"subject": subject,
 		}, c.User().Name, to)
 	})
 }
+
+func administrators(c *worker.Context) ([]email.Recipient, error) {
+	users, err := c.Services().Users.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	to := make([]email.Recipient, 0)
+	for _, user := range users {
+		if user.IsAdministrator() && user.Email != "" {
+			to = append(to, email.NewUserRecipient(user, email.Params{}))
+		}
+	}
+	return to, nil
+}
+
+//SendTenantDeletionScheduled notifies administrators that current tenant is going to be deleted
+func SendTenantDeletionScheduled() worker.Task {
+	return describe("Send tenant deletion scheduled email", func(c *worker.Context) error {
+		to, err := administrators(c)
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		if len(to) == 0 || !c.Tenant().IsDeletionScheduled() {
+			return nil
+		}
+
+		return c.Services().Emailer.BatchSend(c.Tenant(), c.Services().EmailTemplates, "tenant_deletion_scheduled", email.Params{
+			"tenantName":   c.Tenant().Name,
+			"deletionDate": c.Tenant().DeletionScheduledOn.UTC().Format("January 2, 2006"),
+			"link":         link(c.BaseURL(), "/admin"),
+		}, "Fider", to)
+	})
+}
+
+//PurgeDeletedTenants permanently deletes all tenants which grace period has ended and notifies their administrators
+//It runs on every instance, so only the one holding the purge lock deletes anything
+func PurgeDeletedTenants() worker.Task {
+	return describe("Purge deleted tenants", func(c *worker.Context) error {
+		locked, err := c.Services().Tenants.LockForPurge()
+		if err != nil {
+			return c.Failure(err)
+		}
+		if !locked {
+			c.Logger().Debugf("Tenants are being purged by another instance")
+			return nil
+		}
+
+		tenants, err := c.Services().Tenants.GetDueForDeletion(time.Now())
+		if err != nil {
+			return c.Failure(err)
+		}
+
+		for _, tenant := range tenants {
+			c.Services().SetCurrentTenant(tenant)
+			to, err := administrators(c)
+			if err != nil {
+				return c.Failure(err)
+			}
+
+			if err := c.Services().Tenants.Purge(tenant.ID); err != nil {
+				return c.Failure(err)
+			}
+			c.Logger().Infof("Tenant '%s' (%d) has been purged", tenant.Name, tenant.ID)
+
+			//The tenant is gone, so this email can't use its templates, sender or delivery log
+			//It's only sent once the purge is committed, otherwise a failed purge would notify again on the next run
+			if len(to) > 0 {
+				tenantName := tenant.Name
+				c.AfterCommit(func() {
+					err := c.Services().Emailer.BatchSend(nil, nil, "tenant_deleted", email.Params{
+						"tenantName": tenantName,
+					}, "Fider", to)
+					if err != nil {
+						c.Logger().Error(err)
+					}
+				})
+			}
+		}
+
+		return nil
+	})
+}
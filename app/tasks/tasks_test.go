@@ -2,11 +2,16 @@ package tasks_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/getfider/fider/app"
 	"github.com/getfider/fider/app/pkg/mock"
 
 	"github.com/getfider/fider/app/models"
 	. "github.com/getfider/fider/app/pkg/assert"
+	"github.com/getfider/fider/app/pkg/email"
+	"github.com/getfider/fider/app/pkg/log"
+	"github.com/getfider/fider/app/pkg/worker"
 	"github.com/getfider/fider/app/tasks"
 )
 
@@ -33,6 +38,21 @@ func TestSendSignInEmailTask(t *testing.T) {
 	Expect(err).IsNil()
 }
 
+func TestSendSenderVerificationTask(t *testing.T) {
+	RegisterT(t)
+
+	worker, _ := mock.NewWorker()
+	task := tasks.SendSenderVerification(&models.VerifySenderAddress{
+		Email:           "feedback@demo.com",
+		VerificationKey: "1234",
+	})
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+	Expect(err).IsNil()
+}
+
 func TestSendChangeEmailConfirmationTask(t *testing.T) {
 	RegisterT(t)
 
@@ -49,3 +69,89 @@ func TestSendChangeEmailConfirmationTask(t *testing.T) {
 		Execute(task)
 	Expect(err).IsNil()
 }
+
+func TestSendInvitesTask_IgnoreRegisteredUsers(t *testing.T) {
+	RegisterT(t)
+
+	worker, services := mock.NewWorker()
+	task := tasks.SendInvites("Share your ideas", "Join us: %invite%", []*models.UserInvitation{
+		&models.UserInvitation{Email: mock.AryaStark.Email, VerificationKey: "1234"},
+		&models.UserInvitation{Email: "hot.pie@got.com", Name: "Hot Pie", VerificationKey: "5678"},
+	})
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(task)
+	Expect(err).IsNil()
+
+	invitations, _ := services.Tenants.GetVerificationsByKind(models.EmailVerificationKindUserInvitation)
+	Expect(invitations).HasLen(1)
+	Expect(invitations[0].Email).Equals("hot.pie@got.com")
+	Expect(invitations[0].Name).Equals("Hot Pie")
+}
+
+func TestPurgeDeletedTenantsTask(t *testing.T) {
+	RegisterT(t)
+
+	worker, services := mock.NewWorker()
+	services.Tenants.ScheduleDeletion(mock.DemoTenant.ID, time.Now().Add(-time.Minute))
+	services.Tenants.ScheduleDeletion(mock.AvengersTenant.ID, time.Now().Add(time.Hour))
+
+	err := worker.Execute(tasks.PurgeDeletedTenants())
+	Expect(err).IsNil()
+
+	_, err = services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(err).Equals(app.ErrNotFound)
+	avengers, err := services.Tenants.GetByID(mock.AvengersTenant.ID)
+	Expect(err).IsNil()
+	Expect(avengers.IsDeletionScheduled()).IsTrue()
+}
+
+type recordingEmailer struct {
+	templates []string
+}
+
+func (s *recordingEmailer) Send(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to email.Recipient) error {
+	s.templates = append(s.templates, templateName)
+	return nil
+}
+
+func (s *recordingEmailer) BatchSend(tenant *models.Tenant, templates email.TemplateSource, templateName string, params email.Params, from string, to []email.Recipient) error {
+	s.templates = append(s.templates, templateName)
+	return nil
+}
+
+func TestPurgeDeletedTenantsTask_EmailAfterCommit(t *testing.T) {
+	RegisterT(t)
+
+	_, services := mock.NewWorker()
+	emailer := &recordingEmailer{}
+	services.Emailer = emailer
+	services.Tenants.ScheduleDeletion(mock.DemoTenant.ID, time.Now().Add(-time.Minute))
+
+	task := tasks.PurgeDeletedTenants()
+	c := worker.NewContext("0", task.Name, log.NewNoopLogger())
+	c.SetServices(services)
+	Expect(task.Job(c)).IsNil()
+
+	_, err := services.Tenants.GetByID(mock.DemoTenant.ID)
+	Expect(err).Equals(app.ErrNotFound)
+	Expect(emailer.templates).HasLen(0)
+
+	c.Committed()
+	Expect(emailer.templates).HasLen(1)
+	Expect(emailer.templates[0]).Equals("tenant_deleted")
+}
+
+func TestSendTenantDeletionScheduledTask(t *testing.T) {
+	RegisterT(t)
+
+	worker, _ := mock.NewWorker()
+	deletion := time.Now().Add(models.TenantDeletionGracePeriod)
+	mock.DemoTenant.DeletionScheduledOn = &deletion
+	err := worker.
+		OnTenant(mock.DemoTenant).
+		AsUser(mock.JonSnow).
+		Execute(tasks.SendTenantDeletionScheduled())
+	Expect(err).IsNil()
+}
@@ -0,0 +1,15 @@
+alter table tenants add locale varchar(10) not null default 'en';
+
+create table if not exists email_templates (
+  id          serial primary key,
+  tenant_id   int not null,
+  name        varchar(50) not null,
+  locale      varchar(10) not null,
+  subject     varchar(200) not null,
+  body        text not null,
+  created_on  timestamptz not null default now(),
+  updated_on  timestamptz not null default now(),
+  foreign key (tenant_id) references tenants(id)
+);
+
+create unique index email_templates_uq_name_locale on email_templates (tenant_id, name, locale);
@@ -0,0 +1,24 @@
+create table if not exists email_deliveries (
+  id             serial primary key,
+  tenant_id      int not null,
+  template_name  varchar(50) not null,
+  address        varchar(200) not null,
+  provider       varchar(50) not null,
+  status         int not null,
+  response       text not null,
+  created_on     timestamptz not null default now(),
+  foreign key (tenant_id) references tenants(id)
+);
+
+create index email_deliveries_tenant_created_on on email_deliveries (tenant_id, created_on desc);
+
+create table if not exists email_suppressions (
+  id          serial primary key,
+  tenant_id   int not null,
+  address     varchar(200) not null,
+  reason      varchar(50) not null,
+  created_on  timestamptz not null default now(),
+  foreign key (tenant_id) references tenants(id)
+);
+
+create unique index email_suppressions_uq_address on email_suppressions (tenant_id, lower(address));
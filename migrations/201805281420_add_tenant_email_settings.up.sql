@@ -0,0 +1,10 @@
+alter table tenants add email_from_address varchar(200) not null default '';
+alter table tenants add email_reply_to varchar(200) not null default '';
+alter table tenants add email_verified_domain varchar(200) not null default '';
+alter table tenants add email_verification_token varchar(100) not null default '';
+alter table tenants add email_smtp_host varchar(200) not null default '';
+alter table tenants add email_smtp_port varchar(10) not null default '';
+alter table tenants add email_smtp_username varchar(200) not null default '';
+alter table tenants add email_smtp_password varchar(200) not null default '';
+alter table tenants add email_dkim_selector varchar(100) not null default '';
+alter table tenants add email_dkim_private_key text not null default '';
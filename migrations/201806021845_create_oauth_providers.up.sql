@@ -0,0 +1,19 @@
+create table if not exists oauth_providers (
+  id                    serial primary key,
+  tenant_id             int not null,
+  provider              varchar(30) not null,
+  display_name          varchar(50) not null,
+  status                int not null,
+  client_id             varchar(100) not null,
+  client_secret         varchar(500) not null,
+  authorize_url         varchar(300) not null,
+  token_url             varchar(300) not null,
+  profile_url           varchar(300) not null,
+  scope                 varchar(100) not null,
+  json_user_id_path     varchar(100) not null,
+  json_user_name_path   varchar(100) not null,
+  json_user_email_path  varchar(100) not null,
+  foreign key (tenant_id) references tenants(id)
+);
+
+create unique index oauth_providers_uq_provider on oauth_providers (tenant_id, provider);
@@ -0,0 +1,10 @@
+alter table tenants add saml_enabled boolean not null default false;
+alter table tenants add saml_enforced boolean not null default false;
+alter table tenants add saml_idp_entity_id varchar(300) not null default '';
+alter table tenants add saml_idp_sso_url varchar(300) not null default '';
+alter table tenants add saml_idp_certificate text not null default '';
+alter table tenants add saml_name_attribute varchar(200) not null default '';
+alter table tenants add saml_email_attribute varchar(200) not null default '';
+alter table tenants add saml_role_attribute varchar(200) not null default '';
+alter table tenants add saml_admin_role_value varchar(200) not null default '';
+alter table tenants add saml_collaborator_role_value varchar(200) not null default '';
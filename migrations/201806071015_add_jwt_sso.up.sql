@@ -0,0 +1,13 @@
+alter table tenants add sso_jwt_enabled boolean not null default false;
+alter table tenants add sso_jwt_secret varchar(200) not null default '';
+alter table tenants add sso_jwt_public_key text not null default '';
+
+alter table users add avatar_url varchar(500) not null default '';
+
+create table if not exists sso_tokens (
+  tenant_id   int not null,
+  token_id    varchar(200) not null,
+  expires_on  timestamptz not null,
+  primary key (tenant_id, token_id),
+  foreign key (tenant_id) references tenants(id)
+);
@@ -0,0 +1,16 @@
+create table if not exists user_sessions (
+  id            varchar(32) not null,
+  tenant_id     int not null,
+  user_id       int not null,
+  user_agent    varchar(300) not null,
+  ip_address    varchar(50) not null,
+  created_on    timestamptz not null,
+  last_seen_on  timestamptz not null,
+  expires_on    timestamptz not null,
+  revoked_on    timestamptz null,
+  primary key (id),
+  foreign key (tenant_id) references tenants(id),
+  foreign key (user_id) references users(id)
+);
+
+create index user_sessions_idx_user on user_sessions (tenant_id, user_id);
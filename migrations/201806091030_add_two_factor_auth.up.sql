@@ -0,0 +1,20 @@
+alter table tenants add two_factor_required boolean not null default false;
+
+alter table users add totp_secret varchar(100) not null default '';
+alter table users add totp_enabled boolean not null default false;
+alter table users add totp_last_step bigint not null default 0;
+alter table users add totp_failed_attempts int not null default 0;
+alter table users add totp_failed_on timestamptz null;
+
+create table if not exists user_recovery_codes (
+  id          serial not null,
+  tenant_id   int not null,
+  user_id     int not null,
+  code_hash   varchar(64) not null,
+  used_on     timestamptz null,
+  primary key (id),
+  foreign key (tenant_id) references tenants(id),
+  foreign key (user_id) references users(id)
+);
+
+create index user_recovery_codes_idx_user on user_recovery_codes (tenant_id, user_id);
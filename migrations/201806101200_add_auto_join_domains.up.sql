@@ -0,0 +1,2 @@
+alter table tenants add auto_join_domains varchar(100)[] not null default '{}';
+alter table tenants add auto_join_role int not null default 1;
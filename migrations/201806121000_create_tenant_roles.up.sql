@@ -0,0 +1,12 @@
+create table if not exists tenant_roles (
+  id            serial not null,
+  tenant_id     int not null,
+  name          varchar(50) not null,
+  permissions   varchar(50)[] not null default '{}',
+  primary key (id),
+  foreign key (tenant_id) references tenants(id)
+);
+
+create unique index tenant_roles_idx_name on tenant_roles (tenant_id, lower(name));
+
+alter table users add custom_role_id int null references tenant_roles(id) on delete set null;
@@ -0,0 +1,3 @@
+alter table user_sessions add csrf_token varchar(64) null;
+update user_sessions set csrf_token = md5(random()::text || id);
+alter table user_sessions alter column csrf_token set not null;
@@ -0,0 +1 @@
+alter table tenants add deletion_scheduled_on timestamptz null;
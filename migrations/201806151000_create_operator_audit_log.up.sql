@@ -0,0 +1,13 @@
+create table if not exists operator_audit_log (
+  id              serial not null,
+  operator_email  varchar(200) not null,
+  action          varchar(50) not null,
+  tenant_id       int not null,
+  user_id         int null,
+  details         text not null,
+  ip_address      varchar(50) not null,
+  created_on      timestamptz not null default now(),
+  primary key (id)
+);
+
+create index operator_audit_log_tenant_id_idx on operator_audit_log (tenant_id);
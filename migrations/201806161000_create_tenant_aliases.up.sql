@@ -0,0 +1,12 @@
+create table if not exists tenant_aliases (
+  id            serial not null,
+  tenant_id     int not null,
+  hostname      varchar(100) not null,
+  is_cname      boolean not null,
+  created_on    timestamptz not null default now(),
+  removed_on    timestamptz null,
+  primary key (id),
+  foreign key (tenant_id) references tenants(id)
+);
+
+create index tenant_aliases_idx_hostname on tenant_aliases (hostname);
@@ -0,0 +1,7 @@
+create table if not exists autocert_cache (
+  key           varchar(300) not null,
+  data          bytea not null,
+  expires_on    timestamptz null,
+  updated_on    timestamptz not null,
+  primary key (key)
+);
@@ -0,0 +1 @@
+alter table tenants add plan varchar(20) not null default 'unlimited';
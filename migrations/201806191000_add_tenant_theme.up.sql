@@ -0,0 +1,15 @@
+ALTER TABLE tenants ADD primary_color VARCHAR(6) NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD accent_color VARCHAR(6) NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD custom_css TEXT NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD favicon_id INT NULL;
+ALTER TABLE tenants ADD header_id INT NULL;
+
+ALTER TABLE tenants
+   ADD CONSTRAINT tenants_favicon_id_fkey
+   FOREIGN KEY (favicon_id, id) 
+   REFERENCES uploads(id, tenant_id);
+
+ALTER TABLE tenants
+   ADD CONSTRAINT tenants_header_id_fkey
+   FOREIGN KEY (header_id, id) 
+   REFERENCES uploads(id, tenant_id);
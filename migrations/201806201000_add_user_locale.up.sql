@@ -0,0 +1 @@
+ALTER TABLE users ADD locale VARCHAR(10) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+create table if not exists pages (
+  id            serial not null,
+  tenant_id     int not null,
+  slug          varchar(60) not null,
+  title         varchar(100) not null,
+  content       text not null,
+  visibility    int not null,
+  placement     int not null,
+  created_on    timestamptz not null default now(),
+  updated_on    timestamptz not null default now(),
+  primary key (id),
+  foreign key (tenant_id) references tenants(id)
+);
+
+create unique index pages_tenant_id_slug_key on pages (tenant_id, slug);
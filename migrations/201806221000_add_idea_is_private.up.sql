@@ -0,0 +1 @@
+ALTER TABLE ideas ADD is_private BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+alter table tenants add embed_origins varchar(200)[] not null default '{}';
@@ -0,0 +1 @@
+alter table tenants add email_verified_address varchar(200) not null default '';
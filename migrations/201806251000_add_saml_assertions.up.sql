@@ -0,0 +1,7 @@
+create table if not exists saml_assertions (
+  tenant_id     int not null,
+  assertion_id  varchar(200) not null,
+  expires_on    timestamptz not null,
+  primary key (tenant_id, assertion_id),
+  foreign key (tenant_id) references tenants(id)
+);
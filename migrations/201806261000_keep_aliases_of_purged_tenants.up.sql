@@ -0,0 +1 @@
+alter table tenant_aliases alter column tenant_id drop not null;
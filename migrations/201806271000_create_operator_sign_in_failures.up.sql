@@ -0,0 +1,10 @@
+create table if not exists operator_sign_in_failures (
+  id          serial not null,
+  email       varchar(200) not null,
+  ip_address  varchar(50) not null,
+  failed_on   timestamptz not null default now(),
+  primary key (id)
+);
+
+create index operator_sign_in_failures_idx_email on operator_sign_in_failures (email, failed_on);
+create index operator_sign_in_failures_idx_ip_address on operator_sign_in_failures (ip_address, failed_on);
@@ -0,0 +1 @@
+alter table oauth_providers add email_always_verified boolean not null default false;
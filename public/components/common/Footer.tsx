@@ -1,12 +1,28 @@
 import "./Footer.scss";
 
 import * as React from "react";
+import { NavigationLink, PagePlacement } from "@fider/models";
 const logo = require("@fider/assets/images/logo-small.png");
 
-export const Footer = () => {
+interface FooterProps {
+  navigation?: NavigationLink[];
+}
+
+export const Footer = (props: FooterProps) => {
+  const links = (props.navigation || []).filter(x => x.placement === PagePlacement.Footer);
+
   return (
     <div id="c-footer">
       <div className="ui container">
+        {links.length > 0 && (
+          <p className="links">
+            {links.map(x => (
+              <a key={x.slug} href={`/pages/${x.slug}`}>
+                {x.title}
+              </a>
+            ))}
+          </p>
+        )}
         <a target="_blank" href="https://getfider.com/">
           <img src={logo} alt="Fider" />
           <span>Powered by Fider</span>
@@ -1,7 +1,7 @@
 import "./Header.scss";
 
 import * as React from "react";
-import { SystemSettings, CurrentUser, Tenant } from "@fider/models";
+import { SystemSettings, CurrentUser, Tenant, NavigationLink, PagePlacement } from "@fider/models";
 import { SignInModal, SignInControl, EnvironmentInfo, Gravatar, Logo } from "@fider/components";
 import { page, actions, classSet } from "@fider/services";
 
@@ -9,6 +9,7 @@ interface HeaderProps {
   user?: CurrentUser;
   system: SystemSettings;
   tenant: Tenant;
+  navigation?: NavigationLink[];
 }
 
 interface HeaderState {
@@ -58,7 +59,7 @@ export class Header extends React.Component<HeaderProps, HeaderState> {
           )}
         </a>
         <div className="divider" />
-        {this.props.user.isCollaborator && [
+        {this.props.user.permissions.length > 0 && [
           <div key={1} className="header">
             <i className="setting icon" />
             Administration
@@ -74,6 +75,7 @@ export class Header extends React.Component<HeaderProps, HeaderState> {
       </div>
     );
 
+    const links = (this.props.navigation || []).filter(x => x.placement === PagePlacement.Header);
     const showRightMenu = this.props.user || !this.props.tenant.isPrivate;
     const profileMenuClassName = classSet({
       "ui right simple dropdown item signin": true,
@@ -90,6 +92,11 @@ export class Header extends React.Component<HeaderProps, HeaderState> {
               <Logo size={100} tenant={this.props.tenant} />
               <span>{this.props.tenant.name}</span>
             </a>
+            {links.map(x => (
+              <a key={x.slug} href={`/pages/${x.slug}`} className="item page-link">
+                {x.title}
+              </a>
+            ))}
             {showRightMenu && (
               <div onClick={this.showModal} className={profileMenuClassName}>
                 {this.props.user && <Gravatar user={this.props.user} />}
@@ -0,0 +1,17 @@
+import * as React from "react";
+
+interface RecoveryCodesProps {
+  codes: string[];
+}
+
+export const RecoveryCodes = (props: RecoveryCodesProps) => {
+  return (
+    <div className="c-recovery-codes">
+      <p>
+        Keep these recovery codes in a safe place. Each of them can be used once to sign in if you lose access to your
+        authenticator app. <b>They won’t be shown again.</b>
+      </p>
+      <pre>{props.codes.join("\n")}</pre>
+    </div>
+  );
+};
@@ -75,16 +75,41 @@ export class SignInControl extends React.Component<SignInControlProps, SignInCon
         />
       </div>
     );
-    const hasOAuth = !!(google || facebook || github);
+    const custom = (this.settings.customProviders || []).map(p => (
+      <div key={p.provider} className="column">
+        <SocialSignInButton
+          oauthEndpoint={this.settings.endpoint}
+          provider={p.provider}
+          displayName={p.displayName}
+          redirectTo={this.props.redirectTo}
+        />
+      </div>
+    ));
+    const isSSOEnabled = !!this.settings.sso && this.settings.sso.enabled;
+    const isSSOEnforced = isSSOEnabled && this.settings.sso.enforced;
+    const hasOAuth = !isSSOEnforced && !!(google || facebook || github || custom.length > 0);
+
+    // SAML RelayState must be a relative address
+    const ssoRedirect = (this.props.redirectTo || location.href).replace(location.origin, "") || "/";
 
     return (
       <div className="signin-options">
+        {isSSOEnabled && (
+          <div>
+            <Button href={`/sso/saml?redirect=${encodeURIComponent(ssoRedirect)}`} fluid={true} color="positive">
+              <i className="sign in icon" />Sign in with single sign-on
+            </Button>
+            {(hasOAuth || this.props.useEmail) && <div className="ui horizontal divider">OR</div>}
+          </div>
+        )}
+
         {hasOAuth && (
           <div>
             <div className="ui stackable three column centered grid">
               {facebook}
               {google}
               {github}
+              {custom}
             </div>
             <p className="info">We'll never post to any of your accounts</p>
             <div className="ui horizontal divider">OR</div>
@@ -93,7 +118,11 @@ export class SignInControl extends React.Component<SignInControlProps, SignInCon
 
         {this.props.useEmail && (
           <div>
-            <p>Enter your email address to sign in</p>
+            <p>
+              {isSSOEnforced
+                ? "Administrators can also sign in with their email address"
+                : "Enter your email address to sign in"}
+            </p>
             <Form
               ref={f => {
                 this.form = f!;
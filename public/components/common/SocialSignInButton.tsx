@@ -3,11 +3,12 @@ import { Button } from "@fider/components/common";
 
 interface SocialSignInButtonProps {
   oauthEndpoint: string;
-  provider: "google" | "facebook" | "github";
+  provider: string;
+  displayName?: string;
   redirectTo?: string;
 }
 
-const providers = {
+const providers: { [key: string]: { name: string; class: string } } = {
   google: {
     name: "Google",
     class: "social google"
@@ -26,11 +27,12 @@ export class SocialSignInButton extends React.Component<SocialSignInButtonProps,
   public render() {
     const redirectTo = this.props.redirectTo || location.href;
     const href = `${this.props.oauthEndpoint}/oauth/${this.props.provider}?redirect=${redirectTo}`;
+    const provider = providers[this.props.provider] || { name: this.props.displayName, class: "social custom" };
 
     return (
-      <Button href={href} fluid={true} className={providers[this.props.provider].class}>
+      <Button href={href} fluid={true} className={provider.class}>
         <i className="svg" />
-        <span>{providers[this.props.provider].name}</span>
+        <span>{provider.name}</span>
       </Button>
     );
   }
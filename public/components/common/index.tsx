@@ -15,6 +15,7 @@ export * from "./Loader";
 export * from "./Logo";
 export * from "./Toggle";
 export * from "./FiderVersion";
+export * from "./RecoveryCodes";
 import Textarea from "react-textarea-autosize";
 export { Textarea };
 
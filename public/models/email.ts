@@ -0,0 +1,33 @@
+export enum EmailDeliveryStatus {
+  Sent = 1,
+  Failed = 2,
+  Suppressed = 3,
+  Bounced = 4,
+  Complained = 5
+}
+
+export interface EmailDelivery {
+  id: number;
+  templateName: string;
+  address: string;
+  provider: string;
+  status: EmailDeliveryStatus;
+  response: string;
+  createdOn: string;
+}
+
+export interface EmailSuppression {
+  address: string;
+  reason: string;
+  createdOn: string;
+}
+
+export interface TenantEmailSettings {
+  fromAddress: string;
+  replyTo: string;
+  verifiedDomain: string;
+  smtpHost: string;
+  smtpPort: string;
+  smtpUsername: string;
+  dkimSelector: string;
+}
@@ -14,6 +14,7 @@ export interface Idea {
   totalSupporters: number;
   totalComments: number;
   tags: string[];
+  isPrivate: boolean;
 }
 
 export class IdeaStatus {
@@ -7,12 +7,90 @@ export interface Tenant {
   welcomeMessage: string;
   isPrivate: boolean;
   logoId: number;
+  isTwoFactorRequired: boolean;
+  theme: TenantTheme;
+}
+
+export interface TenantTheme {
+  primaryColor: string;
+  accentColor: string;
+  customCSS: string;
+  faviconId: number;
+  headerId: number;
+}
+
+export interface CertificateInfo {
+  hostname: string;
+  expiresOn: string;
+  issuedOn: string;
+}
+
+export interface TenantAlias {
+  id: number;
+  hostname: string;
+  isCNAME: boolean;
+  createdOn: string;
+}
+
+export interface Plan {
+  name: string;
+  maxUsers: number;
+  maxIdeas: number;
+  maxStorage: number;
+  allowPrivate: boolean;
+  allowCustomDomain: boolean;
+  allowAPI: boolean;
+}
+
+export interface TenantUsage {
+  plan: Plan;
+  users: number;
+  ideas: number;
+  storage: number;
 }
 
 export interface User {
   id: number;
   name: string;
   role: UserRole;
+  customRole?: CustomRole;
+}
+
+export type Permission =
+  | "respond"
+  | "edit_ideas"
+  | "delete_ideas"
+  | "moderate_comments"
+  | "assign_tags"
+  | "manage_tags"
+  | "invite"
+  | "manage_members"
+  | "export"
+  | "manage_settings"
+  | "view_private";
+
+export interface CustomRole {
+  id: number;
+  name: string;
+  permissions: Permission[];
+}
+
+export interface UserSession {
+  id: string;
+  userAgent: string;
+  ipAddress: string;
+  createdOn: string;
+  lastSeenOn: string;
+}
+
+export interface TwoFactorEnrollment {
+  secret: string;
+  uri: string;
+}
+
+export interface UserTwoFactorSettings {
+  isEnabled: boolean;
+  isRequired: boolean;
 }
 
 export enum UserRole {
@@ -26,6 +104,64 @@ export interface CurrentUser {
   name: string;
   email: string;
   role: UserRole;
+  locale: string;
   isAdministrator: boolean;
-  isCollaborator: boolean;
+  permissions: Permission[];
+}
+
+export enum OAuthConfigStatus {
+  Disabled = 1,
+  Enabled = 2
+}
+
+export interface OAuthConfig {
+  id: number;
+  provider: string;
+  displayName: string;
+  status: OAuthConfigStatus;
+  clientId: string;
+  authorizeUrl: string;
+  tokenUrl: string;
+  profileUrl: string;
+  scope: string;
+  jsonUserIdPath: string;
+  jsonUserNamePath: string;
+  jsonUserEmailPath: string;
+  emailAlwaysVerified: boolean;
+}
+
+export interface TenantSAMLSettings {
+  isEnabled: boolean;
+  isEnforced: boolean;
+  idpEntityId: string;
+  idpSsoUrl: string;
+  idpCertificate: string;
+  nameAttribute: string;
+  emailAttribute: string;
+  roleAttribute: string;
+  administratorRoleValue: string;
+  collaboratorRoleValue: string;
+}
+
+export type InvitationStatus = "pending" | "accepted" | "expired";
+
+export interface Invitation {
+  id: number;
+  email: string;
+  name: string;
+  status: InvitationStatus;
+  createdOn: string;
+  expiresOn: string;
+  acceptedOn?: string;
+}
+
+export interface TenantAutoJoinSettings {
+  domains: string[];
+  role: UserRole;
+}
+
+export interface TenantJWTSSOSettings {
+  isEnabled: boolean;
+  publicKey: string;
+  hasSecret: boolean;
 }
@@ -2,3 +2,6 @@ export * from "./idea";
 export * from "./identity";
 export * from "./settings";
 export * from "./notification";
+export * from "./email";
+export * from "./operator";
+export * from "./page";
@@ -0,0 +1,33 @@
+export enum TenantStatus {
+  Active = 1,
+  Inactive = 2,
+  Suspended = 3
+}
+
+export interface TenantSummary {
+  id: number;
+  name: string;
+  subdomain: string;
+  cname: string;
+  status: TenantStatus;
+  plan: string;
+  createdOn: string;
+  userCount: number;
+  ideaCount: number;
+  lastActivityOn?: string;
+  storageUsage: number;
+  deletionScheduledOn?: string;
+}
+
+export type OperatorAction = "suspend" | "reactivate" | "change_subdomain" | "impersonate" | "change_plan";
+
+export interface OperatorAuditEntry {
+  id: number;
+  operatorEmail: string;
+  action: OperatorAction;
+  tenantId: number;
+  userId?: number;
+  details: string;
+  ipAddress: string;
+  createdOn: string;
+}
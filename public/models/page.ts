@@ -0,0 +1,27 @@
+export enum PageVisibility {
+  Public = 1,
+  Members = 2,
+  Draft = 3
+}
+
+export enum PagePlacement {
+  None = 0,
+  Header = 1,
+  Footer = 2
+}
+
+export interface Page {
+  id: number;
+  slug: string;
+  title: string;
+  content: string;
+  visibility: PageVisibility;
+  placement: PagePlacement;
+  updatedOn: string;
+}
+
+export interface NavigationLink {
+  slug: string;
+  title: string;
+  placement: PagePlacement;
+}
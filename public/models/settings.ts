@@ -16,6 +16,14 @@ export interface AuthSettings {
     facebook: boolean;
     github: boolean;
   };
+  customProviders: Array<{
+    provider: string;
+    displayName: string;
+  }>;
+  sso: {
+    enabled: boolean;
+    enforced: boolean;
+  };
 }
 
 export interface UserSettings {
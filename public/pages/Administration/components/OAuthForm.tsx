@@ -0,0 +1,155 @@
+import * as React from "react";
+import { Button, ButtonClickEvent, DisplayError, Toggle } from "@fider/components/common";
+import { OAuthConfig, OAuthConfigStatus } from "@fider/models";
+import { Failure } from "@fider/services";
+
+interface OAuthFormProps {
+  config?: OAuthConfig;
+  callbackURL: string;
+  onSave: (data: OAuthFormState) => Promise<Failure | undefined>;
+  onCancel: () => void;
+}
+
+export interface OAuthFormState {
+  provider: string;
+  displayName: string;
+  status: OAuthConfigStatus;
+  clientId: string;
+  clientSecret: string;
+  discoveryUrl: string;
+  authorizeUrl: string;
+  tokenUrl: string;
+  profileUrl: string;
+  scope: string;
+  jsonUserIdPath: string;
+  jsonUserNamePath: string;
+  jsonUserEmailPath: string;
+  emailAlwaysVerified: boolean;
+  error?: Failure;
+}
+
+interface FieldProps {
+  field: keyof OAuthFormState;
+  label: string;
+  placeholder?: string;
+  info?: string;
+}
+
+export class OAuthForm extends React.Component<OAuthFormProps, OAuthFormState> {
+  constructor(props: OAuthFormProps) {
+    super(props);
+    const config = props.config;
+    this.state = {
+      provider: config ? config.provider : "",
+      displayName: config ? config.displayName : "",
+      status: config ? config.status : OAuthConfigStatus.Enabled,
+      clientId: config ? config.clientId : "",
+      clientSecret: "",
+      discoveryUrl: "",
+      authorizeUrl: config ? config.authorizeUrl : "",
+      tokenUrl: config ? config.tokenUrl : "",
+      profileUrl: config ? config.profileUrl : "",
+      scope: config ? config.scope : "",
+      jsonUserIdPath: config ? config.jsonUserIdPath : "",
+      jsonUserNamePath: config ? config.jsonUserNamePath : "",
+      jsonUserEmailPath: config ? config.jsonUserEmailPath : "",
+      emailAlwaysVerified: config ? config.emailAlwaysVerified : false
+    };
+  }
+
+  private onSave = async (e: ButtonClickEvent) => {
+    const error = await this.props.onSave(this.state);
+    if (error) {
+      this.setState({ error });
+    }
+  };
+
+  private setField(field: keyof OAuthFormState, value: string) {
+    this.setState({ [field]: value } as any);
+  }
+
+  private renderField(props: FieldProps) {
+    return (
+      <>
+        <DisplayError fields={[props.field]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor={props.field}>{props.label}</label>
+          <input
+            id={props.field}
+            type={props.field === "clientSecret" ? "password" : "text"}
+            placeholder={props.placeholder}
+            value={this.state[props.field] as string}
+            onChange={e => this.setField(props.field, e.currentTarget.value)}
+          />
+          {props.info && <p className="info">{props.info}</p>}
+        </div>
+      </>
+    );
+  }
+
+  public render() {
+    const isNew = !this.state.provider;
+    const callbackURL = this.props.callbackURL.replace("{provider}", this.state.provider || "{provider}");
+
+    return (
+      <div id="oauth-form" className="ui form">
+        <DisplayError fields={["provider"]} error={this.state.error} />
+        {this.renderField({ field: "displayName", label: "Display Name", placeholder: "My Company" })}
+        <div className="field">
+          <label htmlFor="status">
+            Enabled
+            <Toggle
+              active={this.state.status === OAuthConfigStatus.Enabled}
+              onToggle={async active =>
+                this.setState({ status: active ? OAuthConfigStatus.Enabled : OAuthConfigStatus.Disabled })
+              }
+            />
+          </label>
+        </div>
+        {this.renderField({ field: "clientId", label: "Client ID" })}
+        {this.renderField({
+          field: "clientSecret",
+          label: "Client Secret",
+          placeholder: isNew ? "" : "(unchanged)"
+        })}
+        {this.renderField({
+          field: "discoveryUrl",
+          label: "OpenID Connect Discovery URL",
+          placeholder: "https://login.yourcompany.com",
+          info: "Optional. Endpoints, scope and JSON paths left empty are filled from the OpenID Connect configuration."
+        })}
+        {this.renderField({ field: "authorizeUrl", label: "Authorize URL" })}
+        {this.renderField({ field: "tokenUrl", label: "Token URL" })}
+        {this.renderField({ field: "profileUrl", label: "Profile API URL" })}
+        {this.renderField({ field: "scope", label: "Scope", placeholder: "openid profile email" })}
+        {this.renderField({ field: "jsonUserIdPath", label: "JSON Path: User ID", placeholder: "sub" })}
+        {this.renderField({ field: "jsonUserNamePath", label: "JSON Path: Display Name", placeholder: "name" })}
+        {this.renderField({ field: "jsonUserEmailPath", label: "JSON Path: Email", placeholder: "email" })}
+        <div className="field">
+          <label htmlFor="emailAlwaysVerified">
+            Email is always verified
+            <Toggle
+              active={this.state.emailAlwaysVerified}
+              onToggle={async active => this.setState({ emailAlwaysVerified: active })}
+            />
+          </label>
+          <p className="info">
+            Enable only if this provider never returns an email its users haven't confirmed, as GitHub or GitLab.
+            Otherwise emails are trusted only when the profile has <strong>email_verified</strong> set to true.
+          </p>
+        </div>
+        {!isNew && (
+          <p className="info">
+            Callback URL: <strong>{callbackURL}</strong>
+          </p>
+        )}
+        <div className="field">
+          <Button color="positive" onClick={this.onSave}>
+            Save
+          </Button>
+          <Button onClick={async () => this.props.onCancel()}>Cancel</Button>
+        </div>
+      </div>
+    );
+  }
+}
@@ -1,6 +1,6 @@
 import * as React from "react";
 import { CurrentUser } from "@fider/models";
-import { classSet, page } from "@fider/services";
+import { classSet, page, can } from "@fider/services";
 import { FiderVersion } from "@fider/components";
 
 interface SiteMenuProps {
@@ -43,17 +43,51 @@ export const SideMenu = (props: SiteMenuProps) => {
       <div className="ui vertical menu fluid">
         <SideMenuItem name="general" title="General" href="/admin" isActive={activeItem === "general"} />
         <SideMenuItem name="privacy" title="Privacy" href="/admin/privacy" isActive={activeItem === "privacy"} />
+        <SideMenuItem name="theme" title="Theme" href="/admin/theme" isActive={activeItem === "theme"} />
         <SideMenuItem name="members" title="Members" href="/admin/members" isActive={activeItem === "members"} />
         <SideMenuItem name="tags" title="Tags" href="/admin/tags" isActive={activeItem === "tags"} />
-        <SideMenuItem
-          name="invitations"
-          title="Invitations"
-          href="/admin/invitations"
-          isActive={activeItem === "invitations"}
-        />
-        {props.user.isAdministrator && (
+        {can(props.user, "manage_members") && (
+          <SideMenuItem name="roles" title="Roles" href="/admin/roles" isActive={activeItem === "roles"} />
+        )}
+        {can(props.user, "invite") && (
+          <SideMenuItem
+            name="invitations"
+            title="Invitations"
+            href="/admin/invitations"
+            isActive={activeItem === "invitations"}
+          />
+        )}
+        {can(props.user, "export") && (
           <SideMenuItem name="export" title="Export" href="/admin/export" isActive={activeItem === "export"} />
         )}
+        {can(props.user, "manage_settings") && (
+          <SideMenuItem
+            name="authentication"
+            title="Authentication"
+            href="/admin/authentication"
+            isActive={activeItem === "authentication"}
+          />
+        )}
+        {can(props.user, "manage_settings") && (
+          <SideMenuItem name="sso" title="Single Sign-On" href="/admin/sso" isActive={activeItem === "sso"} />
+        )}
+        {can(props.user, "manage_settings") && (
+          <SideMenuItem name="pages" title="Pages" href="/admin/pages" isActive={activeItem === "pages"} />
+        )}
+        {can(props.user, "manage_settings") && (
+          <SideMenuItem name="widget" title="Widget" href="/admin/widget" isActive={activeItem === "widget"} />
+        )}
+        {can(props.user, "manage_settings") && (
+          <SideMenuItem name="email" title="Email" href="/admin/email" isActive={activeItem === "email"} />
+        )}
+        {can(props.user, "manage_settings") && (
+          <SideMenuItem
+            name="email-log"
+            title="Email Log"
+            href="/admin/email-log"
+            isActive={activeItem === "email-log"}
+          />
+        )}
       </div>
       <FiderVersion />
     </>
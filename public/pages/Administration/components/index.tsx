@@ -1,3 +1,4 @@
 export * from "./AdminBasePage";
 export * from "./SideMenu";
 export * from "./TagForm";
+export * from "./OAuthForm";
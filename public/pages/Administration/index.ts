@@ -1,6 +1,14 @@
 export * from "./pages/GeneralSettings.page";
 export * from "./pages/PrivacySettings.page";
+export * from "./pages/ThemeSettings.page";
 export * from "./pages/ManageTags.page";
+export * from "./pages/ManagePages.page";
 export * from "./pages/Export.page";
 export * from "./pages/Invitations.page";
 export * from "./pages/ManageMembers.page";
+export * from "./pages/ManageRoles.page";
+export * from "./pages/EmailLog.page";
+export * from "./pages/EmailSettings.page";
+export * from "./pages/ManageAuthentication.page";
+export * from "./pages/SSOSettings.page";
+export * from "./pages/WidgetSettings.page";
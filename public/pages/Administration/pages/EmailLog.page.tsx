@@ -0,0 +1,107 @@
+import * as React from "react";
+
+import { CurrentUser, EmailDelivery, EmailDeliveryStatus, EmailSuppression } from "@fider/models";
+import { Button, Moment } from "@fider/components/common";
+import { actions } from "@fider/services";
+import { AdminBasePage } from "../components";
+
+interface EmailLogPageProps {
+  user: CurrentUser;
+  deliveries: EmailDelivery[];
+  suppressions: EmailSuppression[];
+}
+
+interface EmailLogPageState {
+  suppressions: EmailSuppression[];
+}
+
+const statusNames = {
+  [EmailDeliveryStatus.Sent]: "Sent",
+  [EmailDeliveryStatus.Failed]: "Failed",
+  [EmailDeliveryStatus.Suppressed]: "Suppressed",
+  [EmailDeliveryStatus.Bounced]: "Bounced",
+  [EmailDeliveryStatus.Complained]: "Complained"
+};
+
+export class EmailLogPage extends AdminBasePage<EmailLogPageProps, EmailLogPageState> {
+  public id = "p-admin-email-log";
+  public name = "email-log";
+  public icon = "mail";
+  public title = "Email Log";
+  public subtitle = "See the emails sent by this site";
+
+  constructor(props: EmailLogPageProps) {
+    super(props);
+    this.state = {
+      suppressions: this.props.suppressions
+    };
+  }
+
+  private async removeSuppression(suppression: EmailSuppression): Promise<void> {
+    const result = await actions.removeEmailSuppression(suppression.address);
+    if (result.ok) {
+      this.setState({
+        suppressions: this.state.suppressions.filter(x => x.address !== suppression.address)
+      });
+    }
+  }
+
+  public content() {
+    return (
+      <div className="ui form">
+        <div className="field">
+          <label>Suppression List</label>
+          <p className="info">
+            Emails are not sent to addresses that bounced or marked an email as spam. Remove an address from this list
+            to send emails to it again.
+          </p>
+          {this.state.suppressions.length === 0 && <p className="info">No suppressed addresses.</p>}
+          <div className="ui middle aligned very relaxed selection list">
+            {this.state.suppressions.map(x => (
+              <div key={x.address} className="item">
+                <div className="content">
+                  {x.address} <span className="info">· {x.reason} · <Moment date={x.createdOn} /></span>
+                </div>
+                <div className="right floated content">
+                  <Button size="tiny" color="danger" onClick={() => this.removeSuppression(x)} className="showover">
+                    <i className="remove icon" />Remove
+                  </Button>
+                </div>
+              </div>
+            ))}
+          </div>
+        </div>
+        <div className="field">
+          <label>Recent Emails</label>
+          {this.props.deliveries.length === 0 && <p className="info">No emails have been sent yet.</p>}
+          {this.props.deliveries.length > 0 && (
+            <table className="ui very basic compact table">
+              <thead>
+                <tr>
+                  <th>Date</th>
+                  <th>Template</th>
+                  <th>Recipient</th>
+                  <th>Status</th>
+                  <th>Response</th>
+                </tr>
+              </thead>
+              <tbody>
+                {this.props.deliveries.map(x => (
+                  <tr key={x.id}>
+                    <td>
+                      <Moment date={x.createdOn} />
+                    </td>
+                    <td>{x.templateName}</td>
+                    <td>{x.address}</td>
+                    <td>{statusNames[x.status]}</td>
+                    <td className="info">{x.response}</td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          )}
+        </div>
+      </div>
+    );
+  }
+}
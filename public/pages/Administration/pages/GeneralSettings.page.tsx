@@ -2,9 +2,9 @@ import "./GeneralSettings.page.scss";
 
 import * as React from "react";
 
-import { SystemSettings, CurrentUser, Tenant } from "@fider/models";
+import { SystemSettings, CurrentUser, Tenant, TenantAlias, CertificateInfo } from "@fider/models";
 import { Button, ButtonClickEvent, Textarea, DisplayError, Logo } from "@fider/components/common";
-import { actions, page, Failure, fileToBase64 } from "@fider/services";
+import { actions, can, page, Failure, fileToBase64, formatDate } from "@fider/services";
 import { AdminBasePage } from "../components";
 
 interface GeneralSettingsPageProps {
@@ -12,6 +12,10 @@ interface GeneralSettingsPageProps {
   tenant: Tenant;
   system: SystemSettings;
   publicIP: string;
+  deletionScheduledOn?: string;
+  aliases: TenantAlias[];
+  isAutoSSL: boolean;
+  certificate?: CertificateInfo;
 }
 
 interface GeneralSettingsPageState {
@@ -25,7 +29,10 @@ interface GeneralSettingsPageState {
   title: string;
   invitation: string;
   welcomeMessage: string;
+  subdomain: string;
   cname: string;
+  aliases: TenantAlias[];
+  deletionScheduledOn?: string;
   error?: Failure;
 }
 
@@ -43,12 +50,135 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
 
     this.state = {
       title: this.props.tenant.name,
+      subdomain: this.props.tenant.subdomain,
       cname: this.props.tenant.cname,
+      aliases: this.props.aliases || [],
       welcomeMessage: this.props.tenant.welcomeMessage,
-      invitation: this.props.tenant.invitation
+      invitation: this.props.tenant.invitation,
+      deletionScheduledOn: this.props.deletionScheduledOn
     };
   }
 
+  private async scheduleDeletion() {
+    const message = `All ideas, comments, users and uploads of ${
+      this.props.tenant.name
+    } will be permanently deleted. Are you sure?`;
+    if (!window.confirm(message)) {
+      return;
+    }
+
+    const result = await actions.scheduleTenantDeletion();
+    if (result.ok) {
+      this.setState({ deletionScheduledOn: result.data.deletionScheduledOn });
+    }
+  }
+
+  private async cancelDeletion() {
+    const result = await actions.cancelTenantDeletion();
+    if (result.ok) {
+      this.setState({ deletionScheduledOn: undefined });
+    }
+  }
+
+  private async removeAlias(alias: TenantAlias) {
+    const message = `${this.aliasHostname(
+      alias
+    )} will stop redirecting to this site and links using it will be broken. Are you sure?`;
+    if (!window.confirm(message)) {
+      return;
+    }
+
+    const result = await actions.removeTenantAlias(alias.id);
+    if (result.ok) {
+      this.setState({ aliases: this.state.aliases.filter(x => x.id !== alias.id) });
+    }
+  }
+
+  private aliasHostname(alias: TenantAlias): string {
+    return alias.isCNAME ? alias.hostname : `${alias.hostname}${this.props.system.domain}`;
+  }
+
+  private renderCertificate() {
+    if (!this.props.isAutoSSL || !this.props.tenant.cname || this.state.cname !== this.props.tenant.cname) {
+      return null;
+    }
+
+    const certificate = this.props.certificate;
+    return certificate ? (
+      <p>
+        The SSL certificate of <strong>{certificate.hostname}</strong> was issued on {formatDate(certificate.issuedOn)}{" "}
+        and is valid until <strong>{formatDate(certificate.expiresOn)}</strong>. It's renewed automatically.
+      </p>
+    ) : (
+      <p>
+        The SSL certificate of <strong>{this.props.tenant.cname}</strong> hasn't been issued yet. It's issued on the
+        first visit once the DNS record is in place.
+      </p>
+    );
+  }
+
+  private renderAliases() {
+    if (this.state.aliases.length === 0) {
+      return null;
+    }
+
+    return (
+      <div className="field">
+        <label>Previous Domains</label>
+        <p className="info">
+          Links to these domains are permanently redirected to this site. Removed domains are kept reserved for 90
+          days before anyone else can use them.
+        </p>
+        <table className="ui very basic table aliases">
+          <tbody>
+            {this.state.aliases.map(x => (
+              <tr key={x.id}>
+                <td>
+                  <strong>{this.aliasHostname(x)}</strong>
+                </td>
+                <td>since {formatDate(x.createdOn)}</td>
+                <td className="right aligned">
+                  {can(this.props.user, "manage_settings") && (
+                    <Button size="mini" onClick={async () => await this.removeAlias(x)}>
+                      Remove
+                    </Button>
+                  )}
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      </div>
+    );
+  }
+
+  private renderDeletion() {
+    return (
+      <div className="field">
+        <label>Delete Site</label>
+        {this.state.deletionScheduledOn ? (
+          <>
+            <p className="info">
+              This site is scheduled to be permanently deleted on{" "}
+              <strong>{formatDate(this.state.deletionScheduledOn)}</strong>. You can cancel the deletion until then.
+            </p>
+            <Button onClick={async () => await this.cancelDeletion()}>Cancel deletion</Button>
+          </>
+        ) : (
+          <>
+            <p className="info">
+              The site and all of its data will be permanently deleted after a grace period of 30 days. Administrators
+              will be notified by email before and after the deletion.
+            </p>
+            <Button color="danger" onClick={async () => await this.scheduleDeletion()}>
+              Delete this site
+            </Button>
+          </>
+        )}
+      </div>
+    );
+  }
+
   private async save(e: ButtonClickEvent) {
     const result = await actions.updateTenantSettings(this.state);
     if (result.ok) {
@@ -122,7 +252,7 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
             id="title"
             type="text"
             maxLength={60}
-            disabled={!this.props.user.isAdministrator}
+            disabled={!can(this.props.user, "manage_settings")}
             value={this.state.title}
             onChange={e => this.setState({ title: e.currentTarget.value })}
           />
@@ -133,7 +263,7 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
           <label htmlFor="welcome-message">Welcome Message</label>
           <Textarea
             id="welcome-message"
-            disabled={!this.props.user.isAdministrator}
+            disabled={!can(this.props.user, "manage_settings")}
             onChange={e => this.setState({ welcomeMessage: e.currentTarget.value })}
             value={this.state.welcomeMessage}
           />
@@ -152,7 +282,7 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
             id="invitation"
             type="text"
             maxLength={60}
-            disabled={!this.props.user.isAdministrator}
+            disabled={!can(this.props.user, "manage_settings")}
             value={this.state.invitation}
             onChange={e => this.setState({ invitation: e.currentTarget.value })}
           />
@@ -167,11 +297,11 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
           {hasFile && <Logo size={200} tenant={this.props.tenant} url={previewUrl} />}
           <input ref={e => (this.fileSelector = e)} type="file" name="logo" onChange={this.fileChanged} />
           <div>
-            <Button size="mini" onClick={this.selectFile} disabled={!this.props.user.isAdministrator}>
+            <Button size="mini" onClick={this.selectFile} disabled={!can(this.props.user, "manage_settings")}>
               {hasFile ? "Change" : "Upload"}
             </Button>
             {hasFile && (
-              <Button onClick={this.removeFile} size="mini" disabled={!this.props.user.isAdministrator}>
+              <Button onClick={this.removeFile} size="mini" disabled={!can(this.props.user, "manage_settings")}>
                 Remove
               </Button>
             )}
@@ -183,6 +313,24 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
         </div>
 
         {!page.isSingleHostMode() && [
+          <DisplayError key={3} fields={["subdomain"]} error={this.state.error} />,
+          <div key={4} className="field">
+            <label htmlFor="subdomain">Subdomain</label>
+            <div className="ui right labeled input">
+              <input
+                id="subdomain"
+                type="text"
+                maxLength={40}
+                disabled={!can(this.props.user, "manage_settings")}
+                value={this.state.subdomain}
+                onChange={e => this.setState({ subdomain: e.currentTarget.value })}
+              />
+              <div className="ui label">{this.props.system.domain}</div>
+            </div>
+            <p className="info">
+              Links to the current subdomain will keep working and are permanently redirected to the new one.
+            </p>
+          </div>,
           <DisplayError key={1} fields={["cname"]} error={this.state.error} />,
           <div key={2} className="field">
             <label htmlFor="cname">Custom Domain</label>
@@ -191,7 +339,7 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
               type="text"
               placeholder="feedback.yourcompany.com"
               maxLength={100}
-              disabled={!this.props.user.isAdministrator}
+              disabled={!can(this.props.user, "manage_settings")}
               value={this.state.cname}
               onChange={e => this.setState({ cname: e.currentTarget.value })}
             />
@@ -203,7 +351,8 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
                   <p key={2}>
                     Please note that it may take up to 72 hours for the change to take effect worldwide due to DNS
                     propagation.
-                  </p>
+                  </p>,
+                  <React.Fragment key={3}>{this.renderCertificate()}</React.Fragment>
                 ]
               ) : (
                 <p>
@@ -214,13 +363,15 @@ export class GeneralSettingsPage extends AdminBasePage<GeneralSettingsPageProps,
             </div>
           </div>
         ]}
-        {this.props.user.isAdministrator && (
+        {can(this.props.user, "manage_settings") && (
           <div className="field">
             <Button color="positive" onClick={async e => await this.save(e)}>
               Save
             </Button>
           </div>
         )}
+        {!page.isSingleHostMode() && this.renderAliases()}
+        {this.props.user.isAdministrator && this.renderDeletion()}
       </div>
     );
   }
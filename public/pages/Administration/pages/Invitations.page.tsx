@@ -1,16 +1,19 @@
 import * as React from "react";
 
-import { SystemSettings, CurrentUser, Tenant } from "@fider/models";
-import { Button, ButtonClickEvent, Textarea, DisplayError } from "@fider/components/common";
-import { actions, notify, Failure } from "@fider/services";
+import { CurrentUser, Tenant, Invitation } from "@fider/models";
+import { Button, ButtonClickEvent, Textarea, DisplayError, Moment } from "@fider/components/common";
+import { actions, notify, Failure, fileToText } from "@fider/services";
 import { AdminBasePage } from "../components";
 
 interface InvitationsPageProps {
   user: CurrentUser;
   tenant: Tenant;
+  invitations: Invitation[];
 }
 
 interface InvitationsPageState {
+  invitations: Invitation[];
+  csv?: File;
   subject: string;
   message: string;
   recipients: string[];
@@ -47,10 +50,57 @@ Regards,
 ${this.props.user.name} (${this.props.tenant.name})`,
       recipients: [],
       numOfRecipients: 0,
-      rawRecipients: ""
+      rawRecipients: "",
+      invitations: this.props.invitations || []
     };
   }
 
+  private refresh = async () => {
+    const result = await actions.listInvitations();
+    if (result.ok) {
+      this.setState({ invitations: result.data });
+    }
+  };
+
+  private csvChanged = (e: React.ChangeEvent<HTMLInputElement>) => {
+    this.setState({ csv: e.target.files && e.target.files[0] ? e.target.files[0] : undefined });
+  };
+
+  private importCSV = async (e: ButtonClickEvent) => {
+    if (!this.state.csv) {
+      return;
+    }
+
+    const csv = await fileToText(this.state.csv);
+    const result = await actions.importInvitations(this.state.subject, this.state.message, csv);
+    if (result.ok) {
+      notify.success(`${result.data.total} invites are being sent.`);
+      this.setState({ csv: undefined, error: undefined });
+    } else {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private resend = async (invitation: Invitation) => {
+    const result = await actions.resendInvitation(invitation.id, this.state.subject, this.state.message);
+    if (result.ok) {
+      notify.success(
+        <span>
+          A new invite is being sent to <strong>{invitation.email}</strong>
+        </span>
+      );
+      await this.refresh();
+    }
+    this.setState({ error: result.error });
+  };
+
+  private revoke = async (invitation: Invitation) => {
+    const result = await actions.revokeInvitation(invitation.id);
+    if (result.ok) {
+      this.setState({ invitations: this.state.invitations.filter(x => x.id !== invitation.id) });
+    }
+  };
+
   private setRecipients = (e: React.ChangeEvent<HTMLTextAreaElement>) => {
     const rawRecipients = e.currentTarget.value;
     const recipients = rawRecipients.split(/\n|;|,|\s/gm).filter(x => !!x);
@@ -160,6 +210,65 @@ ${this.props.user.name} (${this.props.tenant.name})`,
             Send {this.state.numOfRecipients} {this.state.numOfRecipients === 1 ? "invite" : "invites"}
           </Button>
         </div>
+
+        <div className="ui tiny header">Import from CSV</div>
+
+        <DisplayError fields={["csv"]} error={this.state.error} />
+        <div className="field">
+          <p className="info">
+            Invite a large list of contacts with a CSV file of email addresses and optional names, one per line. The
+            invites are sent in the background using the subject and message above.
+          </p>
+          <input id="csv" type="file" accept=".csv,text/csv" onChange={this.csvChanged} />
+        </div>
+        <div className="field">
+          <Button onClick={this.importCSV} color="positive" disabled={!this.state.csv}>
+            Import and send invites
+          </Button>
+        </div>
+
+        <div className="ui tiny header">Sent Invitations</div>
+
+        {this.state.invitations.length === 0 ? (
+          <p className="info">No invitations have been sent yet.</p>
+        ) : (
+          <table className="ui very basic table">
+            <thead>
+              <tr>
+                <th>Email</th>
+                <th>Status</th>
+                <th>Sent</th>
+                <th />
+              </tr>
+            </thead>
+            <tbody>
+              {this.state.invitations.map(x => (
+                <tr key={x.id}>
+                  <td>
+                    {x.email}
+                    {x.name && <span className="info"> ({x.name})</span>}
+                  </td>
+                  <td>{x.status}</td>
+                  <td>
+                    <Moment date={x.createdOn} />
+                  </td>
+                  <td>
+                    {x.status !== "accepted" && (
+                      <>
+                        <Button size="mini" onClick={() => this.resend(x)}>
+                          Resend
+                        </Button>
+                        <Button size="mini" onClick={() => this.revoke(x)}>
+                          Revoke
+                        </Button>
+                      </>
+                    )}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
       </div>
     );
   }
@@ -0,0 +1,141 @@
+import * as React from "react";
+import { Button, Toggle } from "@fider/components";
+import { AdminBasePage, OAuthForm, OAuthFormState } from "../components";
+
+import { OAuthConfig, OAuthConfigStatus, CurrentUser } from "@fider/models";
+import { actions, notify, Failure } from "@fider/services";
+
+interface ManageAuthenticationPageProps {
+  user: CurrentUser;
+  providers: OAuthConfig[];
+  callbackURL: string;
+  isTwoFactorRequired: boolean;
+}
+
+interface ManageAuthenticationPageState {
+  isAdding: boolean;
+  providers: OAuthConfig[];
+  editing?: string;
+  isTwoFactorRequired: boolean;
+}
+
+export class ManageAuthenticationPage extends AdminBasePage<
+  ManageAuthenticationPageProps,
+  ManageAuthenticationPageState
+> {
+  public id = "p-admin-authentication";
+  public name = "authentication";
+  public icon = "sign in";
+  public title = "Authentication";
+  public subtitle = "Manage the identity providers used to sign in";
+
+  constructor(props: ManageAuthenticationPageProps) {
+    super(props);
+    this.state = {
+      isAdding: false,
+      providers: this.props.providers,
+      isTwoFactorRequired: this.props.isTwoFactorRequired
+    };
+  }
+
+  private async save(data: OAuthFormState): Promise<Failure | undefined> {
+    const result = await actions.saveOAuthConfig(data);
+    if (result.ok) {
+      const providers = this.state.providers.filter(p => p.provider !== result.data.provider);
+      this.setState({
+        isAdding: false,
+        editing: undefined,
+        providers: providers.concat(result.data).sort((a, b) => a.id - b.id)
+      });
+      notify.success(`${result.data.displayName} has been saved.`);
+    } else {
+      return result.error;
+    }
+  }
+
+  private toggleTwoFactor = async (active: boolean) => {
+    const result = await actions.updateTwoFactorSettings(active);
+    if (result.ok) {
+      this.setState({ isTwoFactorRequired: active });
+      notify.success("Your two-factor authentication settings have been saved.");
+    }
+  };
+
+  private getProviderList() {
+    return this.state.providers.map(p => {
+      if (this.state.editing === p.provider) {
+        return (
+          <div key={p.provider} className="item">
+            <OAuthForm
+              config={p}
+              callbackURL={this.props.callbackURL}
+              onSave={async data => this.save(data)}
+              onCancel={() => this.setState({ editing: undefined })}
+            />
+          </div>
+        );
+      }
+
+      return (
+        <div key={p.provider} className="item">
+          <Button
+            onClick={async () => this.setState({ isAdding: false, editing: p.provider })}
+            className="right floated"
+          >
+            <i className="edit icon" />Edit
+          </Button>
+          <div className="content">
+            <b>{p.displayName}</b>{" "}
+            {p.status === OAuthConfigStatus.Disabled && <span className="info">(disabled)</span>}
+            <div className="info">{this.props.callbackURL.replace("{provider}", p.provider)}</div>
+          </div>
+        </div>
+      );
+    });
+  }
+
+  public content() {
+    const list = this.getProviderList();
+
+    const form = this.state.isAdding ? (
+      <div className="ui segment">
+        <OAuthForm
+          callbackURL={this.props.callbackURL}
+          onSave={async data => this.save(data)}
+          onCancel={() => this.setState({ isAdding: false })}
+        />
+      </div>
+    ) : (
+      <Button color="positive" onClick={async () => this.setState({ isAdding: true, editing: undefined })}>
+        Add new
+      </Button>
+    );
+
+    return (
+      <>
+        <p className="info">
+          Register any OAuth2 or OpenID Connect provider, such as Okta, Keycloak, GitLab or Azure AD, to let your users
+          sign in with it.
+        </p>
+        {form}
+        <div className="ui segment">
+          <div className="ui middle aligned very relaxed divided list">
+            {list.length ? list : <div className="content">There aren’t any custom providers yet.</div>}
+          </div>
+        </div>
+        <div className="ui form">
+          <div className="field">
+            <label htmlFor="two-factor">
+              Require two-factor authentication for staff members
+              <Toggle active={this.state.isTwoFactorRequired} onToggle={this.toggleTwoFactor} />
+            </label>
+            <p className="info">
+              Collaborators and administrators will need a code from an authenticator app after signing in. Those who
+              haven’t set it up yet will be asked to do so on their next sign in.
+            </p>
+          </div>
+        </div>
+      </>
+    );
+  }
+}
@@ -1,7 +1,7 @@
 import * as React from "react";
 import { Button, Gravatar, UserName } from "@fider/components/common";
-import { User, CurrentUser, UserRole } from "@fider/models";
-import { actions } from "@fider/services";
+import { User, CurrentUser, UserRole, CustomRole } from "@fider/models";
+import { actions, can, notify } from "@fider/services";
 import { AdminBasePage } from "../components";
 
 interface ManageMembersPageState {
@@ -17,6 +17,7 @@ interface ManageMembersPageState {
 interface ManageMembersPageProps {
   user: CurrentUser;
   users: User[];
+  roles: CustomRole[];
 }
 
 export class ManageMembersPage extends AdminBasePage<ManageMembersPageProps, ManageMembersPageState> {
@@ -35,10 +36,31 @@ export class ManageMembersPage extends AdminBasePage<ManageMembersPageProps, Man
     const response = await actions.changeUserRole(user.id, role);
     if (response.ok) {
       user.role = role;
+      user.customRole = undefined;
       this.setState(this.groupUsers());
     }
   }
 
+  private async changeCustomRole(user: User, customRoleId: number): Promise<any> {
+    if (!customRoleId) {
+      return this.changeRole(user, UserRole.Collaborator);
+    }
+
+    const response = await actions.changeUserRole(user.id, UserRole.Collaborator, customRoleId);
+    if (response.ok) {
+      user.role = UserRole.Collaborator;
+      user.customRole = this.props.roles.filter(r => r.id === customRoleId)[0];
+      this.setState(this.groupUsers());
+    }
+  }
+
+  private async revokeSessions(user: User): Promise<any> {
+    const response = await actions.revokeUserSessions(user.id);
+    if (response.ok) {
+      notify.success(`${user.name} has been signed out from all devices.`);
+    }
+  }
+
   private groupUsers(): ManageMembersPageState {
     const usersByRole = this.props.users.reduce<{ [key: number]: User[] }>((groups, x) => {
       groups[x.role] = [x].concat(groups[x.role] || []);
@@ -57,7 +79,7 @@ export class ManageMembersPage extends AdminBasePage<ManageMembersPageProps, Man
   }
 
   private showUser(user: User, role: UserRole, addable: boolean, removable: boolean) {
-    if (user.id === this.props.user.id || this.props.user.role !== UserRole.Administrator) {
+    if (user.id === this.props.user.id || !can(this.props.user, "manage_members")) {
       removable = false;
     }
 
@@ -66,8 +88,32 @@ export class ManageMembersPage extends AdminBasePage<ManageMembersPageProps, Man
         <Gravatar user={user} />
         <div className="content">
           <UserName user={user} />
+          {role === UserRole.Collaborator &&
+            !addable &&
+            !removable &&
+            user.customRole && <div className="info">{user.customRole.name}</div>}
         </div>
         <div className="right floated content">
+          {role === UserRole.Collaborator &&
+            removable &&
+            this.props.roles.length > 0 && (
+              <select
+                value={user.customRole ? user.customRole.id : 0}
+                onChange={e => this.changeCustomRole(user, parseInt(e.currentTarget.value, 10))}
+              >
+                <option value={0}>Collaborator</option>
+                {this.props.roles.map(r => (
+                  <option key={r.id} value={r.id}>
+                    {r.name}
+                  </option>
+                ))}
+              </select>
+            )}
+          {removable && (
+            <Button size="tiny" onClick={() => this.revokeSessions(user)} className="showover">
+              <i className="sign out icon" />Sign out
+            </Button>
+          )}
           {removable && (
             <Button
               size="tiny"
@@ -143,11 +189,14 @@ export class ManageMembersPage extends AdminBasePage<ManageMembersPageProps, Man
         <div className="eight wide computer sixteen wide mobile column">
           <div className="ui segment">
             <h4 className="ui header">Collaborators</h4>
-            <p className="info">Collaborators can edit and manage content, but not permissions and settings.</p>
+            <p className="info">
+              Collaborators can edit and manage content, but not permissions and settings. Assign a custom role to
+              grant them a different set of permissions.
+            </p>
             <div className="ui middle aligned very relaxed selection list">
               {this.state.collaborators.map(x => this.showUser(x, UserRole.Collaborator, false, true))}
             </div>
-            {this.props.user.role === UserRole.Administrator && (
+            {can(this.props.user, "manage_members") && (
               <div className="ui mini form">
                 <p>Add new collaborator</p>
                 <div className="mini field">
@@ -0,0 +1,241 @@
+import * as React from "react";
+
+import { Button, ButtonClickEvent, Textarea, DisplayError } from "@fider/components/common";
+import { CurrentUser, Page, PageVisibility, PagePlacement } from "@fider/models";
+import { actions, Failure } from "@fider/services";
+import { AdminBasePage } from "../components";
+
+interface ManagePagesPageProps {
+  user: CurrentUser;
+  pages: Page[];
+}
+
+interface ManagePagesPageState {
+  pages: Page[];
+  editing?: Page;
+  form?: actions.SavePageRequest;
+  deleting?: number;
+  error?: Failure;
+}
+
+const visibilities = [
+  { value: PageVisibility.Public, label: "Public" },
+  { value: PageVisibility.Members, label: "Signed in users only" },
+  { value: PageVisibility.Draft, label: "Draft (administrators only)" }
+];
+
+const placements = [
+  { value: PagePlacement.None, label: "Not linked" },
+  { value: PagePlacement.Header, label: "Header" },
+  { value: PagePlacement.Footer, label: "Footer" }
+];
+
+export class ManagePagesPage extends AdminBasePage<ManagePagesPageProps, ManagePagesPageState> {
+  public id = "p-admin-pages";
+  public name = "pages";
+  public icon = "file alternate outline";
+  public title = "Pages";
+  public subtitle = "Publish terms of use, privacy policy and other pages";
+
+  constructor(props: ManagePagesPageProps) {
+    super(props);
+    this.state = {
+      pages: this.props.pages
+    };
+  }
+
+  private startEditing(page?: Page) {
+    this.setState({
+      editing: page,
+      deleting: undefined,
+      error: undefined,
+      form: {
+        slug: page ? page.slug : "",
+        title: page ? page.title : "",
+        content: page ? page.content : "",
+        visibility: page ? page.visibility : PageVisibility.Draft,
+        placement: page ? page.placement : PagePlacement.None
+      }
+    });
+  }
+
+  private setForm(changes: Partial<actions.SavePageRequest>) {
+    if (this.state.form) {
+      this.setState({ form: { ...this.state.form, ...changes } });
+    }
+  }
+
+  private save = async (e: ButtonClickEvent) => {
+    if (!this.state.form) {
+      return;
+    }
+
+    const editing = this.state.editing;
+    const result = editing
+      ? await actions.updatePage(editing.slug, this.state.form)
+      : await actions.createPage(this.state.form);
+
+    if (result.ok) {
+      const pages = editing
+        ? this.state.pages.map(p => (p.id === editing.id ? result.data : p))
+        : this.state.pages.concat(result.data);
+      this.setState({ pages, editing: undefined, form: undefined, error: undefined });
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private async deletePage(page: Page) {
+    const result = await actions.deletePage(page.slug);
+    if (result.ok) {
+      this.setState({
+        deleting: undefined,
+        pages: this.state.pages.filter(p => p.id !== page.id)
+      });
+    }
+  }
+
+  private renderForm(form: actions.SavePageRequest) {
+    return (
+      <div className="ui segment form">
+        <DisplayError fields={["title"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="title">Title</label>
+          <input
+            id="title"
+            type="text"
+            maxLength={100}
+            value={form.title}
+            onChange={e => this.setForm({ title: e.currentTarget.value })}
+          />
+        </div>
+        <DisplayError fields={["slug"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="slug">Slug</label>
+          <div className="ui labeled input">
+            <div className="ui label">/pages/</div>
+            <input
+              id="slug"
+              type="text"
+              maxLength={60}
+              placeholder="generated from the title when empty"
+              value={form.slug}
+              onChange={e => this.setForm({ slug: e.currentTarget.value })}
+            />
+          </div>
+        </div>
+        <DisplayError fields={["visibility", "placement"]} error={this.state.error} />
+        <div className="two fields">
+          <div className="field">
+            <label htmlFor="visibility">Visibility</label>
+            <select
+              id="visibility"
+              className="ui dropdown"
+              value={form.visibility}
+              onChange={e => this.setForm({ visibility: parseInt(e.currentTarget.value, 10) })}
+            >
+              {visibilities.map(x => (
+                <option key={x.value} value={x.value}>
+                  {x.label}
+                </option>
+              ))}
+            </select>
+          </div>
+          <div className="field">
+            <label htmlFor="placement">Navigation</label>
+            <select
+              id="placement"
+              className="ui dropdown"
+              value={form.placement}
+              onChange={e => this.setForm({ placement: parseInt(e.currentTarget.value, 10) })}
+            >
+              {placements.map(x => (
+                <option key={x.value} value={x.value}>
+                  {x.label}
+                </option>
+              ))}
+            </select>
+          </div>
+        </div>
+        <DisplayError fields={["content"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="content">Content</label>
+          <Textarea
+            id="content"
+            minRows={10}
+            value={form.content}
+            onChange={e => this.setForm({ content: e.currentTarget.value })}
+          />
+          <p className="info">Markdown is supported. HTML and images are removed when the page is displayed.</p>
+        </div>
+        <Button color="positive" onClick={this.save}>
+          Save
+        </Button>
+        <Button onClick={async () => this.setState({ editing: undefined, form: undefined, error: undefined })}>
+          Cancel
+        </Button>
+      </div>
+    );
+  }
+
+  private renderList() {
+    return this.state.pages.map(p => {
+      if (this.state.deleting === p.id) {
+        return (
+          <div key={p.id} className="item">
+            <div className="content">
+              <b>Are you sure?</b> <span>The page {p.title} will be permanently deleted.</span>
+            </div>
+            <Button className="right floated" onClick={async () => this.setState({ deleting: undefined })}>
+              Cancel
+            </Button>
+            <Button color="danger" className="right floated" onClick={() => this.deletePage(p)}>
+              Delete page
+            </Button>
+          </div>
+        );
+      }
+
+      return (
+        <div key={p.id} className="item">
+          <Button
+            className="right floated"
+            onClick={async () => this.setState({ deleting: p.id, editing: undefined, form: undefined })}
+          >
+            <i className="remove icon" />Remove
+          </Button>
+          <Button className="right floated" onClick={async () => this.startEditing(p)}>
+            <i className="edit icon" />Edit
+          </Button>
+          <div className="content">
+            <a href={`/pages/${p.slug}`}>{p.title}</a>
+            <div className="info">
+              /pages/{p.slug} · {visibilities.filter(x => x.value === p.visibility).map(x => x.label)}
+            </div>
+          </div>
+        </div>
+      );
+    });
+  }
+
+  public content() {
+    const list = this.renderList();
+
+    return (
+      <>
+        {this.state.form ? (
+          this.renderForm(this.state.form)
+        ) : (
+          <Button color="positive" onClick={async () => this.startEditing()}>
+            Add new
+          </Button>
+        )}
+        <div className="ui segment">
+          <div className="ui middle aligned very relaxed divided list">
+            {list.length ? list : <div className="content">There aren’t any pages yet.</div>}
+          </div>
+        </div>
+      </>
+    );
+  }
+}
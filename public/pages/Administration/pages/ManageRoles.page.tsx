@@ -0,0 +1,175 @@
+import * as React from "react";
+import { Button, DisplayError, Toggle } from "@fider/components/common";
+import { AdminBasePage } from "../components";
+
+import { CurrentUser, CustomRole, Permission } from "@fider/models";
+import { actions, can, Failure } from "@fider/services";
+
+const permissionTitles: { [key: string]: string } = {
+  respond: "Respond to ideas",
+  edit_ideas: "Edit ideas",
+  delete_ideas: "Delete ideas",
+  moderate_comments: "Edit and delete comments",
+  assign_tags: "Assign tags",
+  manage_tags: "Manage tags",
+  invite: "Invite users",
+  manage_members: "Manage members and roles",
+  export: "Export data",
+  manage_settings: "Change site settings",
+  view_private: "See private ideas and tags"
+};
+
+interface ManageRolesPageProps {
+  user: CurrentUser;
+  roles: CustomRole[];
+  permissions: Permission[];
+}
+
+interface ManageRolesPageState {
+  roles: CustomRole[];
+  editing?: number;
+  name: string;
+  permissions: Permission[];
+  error?: Failure;
+}
+
+export class ManageRolesPage extends AdminBasePage<ManageRolesPageProps, ManageRolesPageState> {
+  public id = "p-admin-roles";
+  public name = "roles";
+  public icon = "id badge";
+  public title = "Roles";
+  public subtitle = "Define which permissions each staff role has";
+
+  constructor(props: ManageRolesPageProps) {
+    super(props);
+    this.state = {
+      roles: this.props.roles,
+      name: "",
+      permissions: []
+    };
+  }
+
+  private startEdit(role?: CustomRole) {
+    this.setState({
+      editing: role ? role.id : 0,
+      name: role ? role.name : "",
+      permissions: role ? role.permissions : [],
+      error: undefined
+    });
+  }
+
+  private cancelEdit() {
+    this.setState({ editing: undefined, error: undefined });
+  }
+
+  private togglePermission(permission: Permission, active: boolean) {
+    const permissions = this.state.permissions.filter(p => p !== permission);
+    this.setState({ permissions: active ? permissions.concat(permission) : permissions });
+  }
+
+  private async save() {
+    const result = await actions.saveCustomRole(this.state.editing, {
+      name: this.state.name,
+      permissions: this.state.permissions
+    });
+
+    if (result.ok) {
+      const roles = this.state.roles.filter(r => r.id !== result.data.id).concat(result.data);
+      roles.sort((a, b) => a.name.localeCompare(b.name));
+      this.setState({ roles, editing: undefined, error: undefined });
+    } else {
+      this.setState({ error: result.error });
+    }
+  }
+
+  private async delete(role: CustomRole) {
+    if (!window.confirm(`Members with the role ${role.name} will become visitors. Are you sure?`)) {
+      return;
+    }
+
+    const result = await actions.deleteCustomRole(role.id);
+    if (result.ok) {
+      this.setState({ roles: this.state.roles.filter(r => r.id !== role.id) });
+    }
+  }
+
+  private renderForm() {
+    return (
+      <div className="ui segment form">
+        <DisplayError error={this.state.error} />
+        <div className="field">
+          <label htmlFor="name">Name</label>
+          <input
+            id="name"
+            type="text"
+            maxLength={50}
+            value={this.state.name}
+            onChange={e => this.setState({ name: e.currentTarget.value })}
+          />
+        </div>
+        <div className="field">
+          <label>Permissions</label>
+          <DisplayError fields={["permissions"]} error={this.state.error} />
+          {this.props.permissions.map(p => (
+            <div key={p} className="field">
+              <Toggle
+                label={permissionTitles[p] || p}
+                active={this.state.permissions.indexOf(p) >= 0}
+                disabled={!can(this.props.user, p)}
+                onToggle={async active => this.togglePermission(p, active)}
+              />
+            </div>
+          ))}
+        </div>
+        <Button color="positive" onClick={() => this.save()}>
+          Save
+        </Button>
+        <Button onClick={async () => this.cancelEdit()}>Cancel</Button>
+      </div>
+    );
+  }
+
+  public content() {
+    return (
+      <>
+        <p className="info">
+          Custom roles bundle a set of permissions. Members with a custom role are part of the staff, but can only do
+          what their role allows. Administrators always have every permission.
+        </p>
+        <div className="ui middle aligned very relaxed divided list">
+          {this.state.roles.map(
+            r =>
+              this.state.editing === r.id ? (
+                <div key={r.id} className="item">
+                  {this.renderForm()}
+                </div>
+              ) : (
+                <div key={r.id} className="item">
+                  <div className="right floated content">
+                    <Button size="tiny" onClick={async () => this.startEdit(r)}>
+                      <i className="edit icon" />Edit
+                    </Button>
+                    <Button size="tiny" color="danger" onClick={() => this.delete(r)}>
+                      <i className="remove icon" />Delete
+                    </Button>
+                  </div>
+                  <div className="content">
+                    <b>{r.name}</b>
+                    <p className="info">{r.permissions.map(p => permissionTitles[p] || p).join(", ")}</p>
+                  </div>
+                </div>
+              )
+          )}
+        </div>
+        {this.state.roles.length === 0 && <p className="info">No custom roles have been defined yet.</p>}
+        {this.state.editing === 0 ? (
+          this.renderForm()
+        ) : (
+          <Button color="positive" onClick={async () => this.startEdit()}>
+            Add new role
+          </Button>
+        )}
+      </>
+    );
+  }
+}
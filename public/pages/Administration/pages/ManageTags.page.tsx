@@ -3,7 +3,7 @@ import { ShowTag, Button, Gravatar, UserName } from "@fider/components";
 import { AdminBasePage, TagForm, TagFormState } from "../components";
 
 import { Tag, CurrentUser, UserRole } from "@fider/models";
-import { actions, Failure } from "@fider/services";
+import { actions, can, Failure } from "@fider/services";
 
 interface ManageTagsPageProps {
   user: CurrentUser;
@@ -108,7 +108,7 @@ export class ManageTagsPage extends AdminBasePage<ManageTagsPageProps, ManageTag
       return (
         <div key={t.id} className="item">
           <ShowTag tag={t} />
-          {this.props.user.isAdministrator && [
+          {can(this.props.user, "manage_tags") && [
             <Button
               key={0}
               onClick={async () =>
@@ -145,7 +145,7 @@ export class ManageTagsPage extends AdminBasePage<ManageTagsPageProps, ManageTag
     const list = this.getTagList();
 
     const form =
-      this.props.user.isAdministrator &&
+      can(this.props.user, "manage_tags") &&
       (this.state.isAdding ? (
         <div className="ui segment">
           <TagForm onSave={async data => this.saveNewTag(data)} onCancel={() => this.setState({ isAdding: false })} />
@@ -2,18 +2,22 @@ import "./PrivacySettings.page.scss";
 
 import * as React from "react";
 
-import { SystemSettings, CurrentUser, Tenant } from "@fider/models";
+import { CurrentUser, Tenant, TenantAutoJoinSettings, UserRole } from "@fider/models";
 import { Button, ButtonClickEvent, Textarea, DisplayError, Toggle } from "@fider/components/common";
-import { actions, notify, Failure } from "@fider/services";
+import { actions, can, notify, Failure } from "@fider/services";
 import { AdminBasePage } from "../components";
 
 interface PrivacySettingsPageProps {
   user: CurrentUser;
   tenant: Tenant;
+  autoJoin: TenantAutoJoinSettings;
 }
 
 interface PrivacySettingsPageState {
   isPrivate: boolean;
+  domains: string;
+  role: UserRole;
+  error?: Failure;
 }
 
 export class PrivacySettingsPage extends AdminBasePage<PrivacySettingsPageProps, PrivacySettingsPageState> {
@@ -27,10 +31,25 @@ export class PrivacySettingsPage extends AdminBasePage<PrivacySettingsPageProps,
     super(props);
 
     this.state = {
-      isPrivate: this.props.tenant.isPrivate
+      isPrivate: this.props.tenant.isPrivate,
+      domains: (this.props.autoJoin.domains || []).join("\n"),
+      role: this.props.autoJoin.role
     };
   }
 
+  private saveAutoJoin = async (e: ButtonClickEvent) => {
+    const result = await actions.updateAutoJoinSettings({
+      domains: this.state.domains.split(/[\s,]+/).filter(x => x),
+      role: this.state.role
+    });
+    if (result.ok) {
+      this.setState({ domains: result.data.domains.join("\n"), role: result.data.role, error: undefined });
+      notify.success("Your auto-join settings have been saved.");
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
   private toggle = async (active: boolean) => {
     this.setState(
       state => ({
@@ -51,13 +70,49 @@ export class PrivacySettingsPage extends AdminBasePage<PrivacySettingsPageProps,
         <div className="field">
           <label htmlFor="private">
             Private site
-            <Toggle disabled={!this.props.user.isAdministrator} active={this.state.isPrivate} onToggle={this.toggle} />
+            <Toggle disabled={!can(this.props.user, "manage_settings")} active={this.state.isPrivate} onToggle={this.toggle} />
           </label>
           <p className="info">
             A private site prevents unauthenticated users from viewing or interacting with its content. <br /> If
-            enabled, only already registered and invited users will be able to sign in to this site.
+            enabled, only already registered and invited users, or users from an allowed domain, will be able to sign
+            in to this site.
           </p>
         </div>
+
+        <h4 className="ui dividing header">Auto-join</h4>
+        <p className="info">
+          Users with a verified email address from one of these domains can join without an invitation.
+        </p>
+        <DisplayError fields={["domains", "role"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="domains">Allowed domains</label>
+          <Textarea
+            id="domains"
+            disabled={!can(this.props.user, "manage_settings")}
+            placeholder="ourcompany.com"
+            value={this.state.domains}
+            onChange={e => this.setState({ domains: e.currentTarget.value })}
+          />
+          <p className="info">One domain per line.</p>
+        </div>
+        <div className="field">
+          <label htmlFor="role">
+            Join as collaborator
+            <Toggle
+              disabled={!can(this.props.user, "manage_settings")}
+              active={this.state.role === UserRole.Collaborator}
+              onToggle={async active => this.setState({ role: active ? UserRole.Collaborator : UserRole.Visitor })}
+            />
+          </label>
+          <p className="info">When disabled, users from these domains join as visitors.</p>
+        </div>
+        {can(this.props.user, "manage_settings") && (
+          <div className="field">
+            <Button color="positive" onClick={this.saveAutoJoin}>
+              Save
+            </Button>
+          </div>
+        )}
       </div>
     );
   }
@@ -0,0 +1,239 @@
+import * as React from "react";
+
+import { CurrentUser, TenantSAMLSettings, TenantJWTSSOSettings } from "@fider/models";
+import { Button, ButtonClickEvent, Textarea, DisplayError, Toggle } from "@fider/components/common";
+import { actions, notify, Failure } from "@fider/services";
+import { AdminBasePage } from "../components";
+
+interface SSOSettingsPageProps {
+  user: CurrentUser;
+  settings: TenantSAMLSettings;
+  entityId: string;
+  acsUrl: string;
+  metadataUrl: string;
+  jwt: TenantJWTSSOSettings;
+  jwtUrl: string;
+}
+
+interface SSOSettingsPageState extends TenantSAMLSettings {
+  metadata: string;
+  error?: Failure;
+  jwt: TenantJWTSSOSettings;
+  jwtSecret: string;
+  jwtError?: Failure;
+}
+
+interface FieldProps {
+  field: keyof TenantSAMLSettings;
+  label: string;
+  placeholder?: string;
+  info?: string;
+}
+
+export class SSOSettingsPage extends AdminBasePage<SSOSettingsPageProps, SSOSettingsPageState> {
+  public id = "p-admin-sso";
+  public name = "sso";
+  public icon = "key";
+  public title = "Single Sign-On";
+  public subtitle = "Sign in your users with your identity provider or application";
+
+  constructor(props: SSOSettingsPageProps) {
+    super(props);
+    this.state = {
+      ...this.props.settings,
+      metadata: "",
+      jwt: this.props.jwt,
+      jwtSecret: ""
+    };
+  }
+
+  private save = async (e: ButtonClickEvent) => {
+    const result = await actions.updateSSOSettings(this.state);
+    if (result.ok) {
+      this.setState({ ...result.data, metadata: "", error: undefined });
+      notify.success("Your single sign-on settings have been saved.");
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private saveJWT = async (e: ButtonClickEvent) => {
+    const result = await actions.updateJWTSSOSettings({
+      isEnabled: this.state.jwt.isEnabled,
+      secret: this.state.jwtSecret,
+      publicKey: this.state.jwt.publicKey
+    });
+    if (result.ok) {
+      this.setState({ jwt: result.data, jwtSecret: "", jwtError: undefined });
+      notify.success("Your trusted application settings have been saved.");
+    } else if (result.error) {
+      this.setState({ jwtError: result.error });
+    }
+  };
+
+  private setField(field: keyof TenantSAMLSettings, value: string) {
+    this.setState({ [field]: value } as any);
+  }
+
+  private renderField(props: FieldProps) {
+    return (
+      <>
+        <DisplayError fields={[props.field]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor={props.field}>{props.label}</label>
+          <input
+            id={props.field}
+            type="text"
+            maxLength={300}
+            placeholder={props.placeholder}
+            value={this.state[props.field] as string}
+            onChange={e => this.setField(props.field, e.currentTarget.value)}
+          />
+          {props.info && <p className="info">{props.info}</p>}
+        </div>
+      </>
+    );
+  }
+
+  public content() {
+    return (
+      <div className="ui form">
+        <DisplayError error={this.state.error} />
+        <h4 className="ui dividing header">Service Provider</h4>
+        <p className="info">Register your site on your identity provider with the following details.</p>
+        <div className="field">
+          <label>Entity ID</label>
+          <p>{this.props.entityId}</p>
+        </div>
+        <div className="field">
+          <label>Assertion Consumer Service URL</label>
+          <p>{this.props.acsUrl}</p>
+        </div>
+        <div className="field">
+          <label>Metadata</label>
+          <p>
+            <a href={this.props.metadataUrl} target="_blank">
+              {this.props.metadataUrl}
+            </a>
+          </p>
+        </div>
+
+        <h4 className="ui dividing header">Identity Provider</h4>
+        <DisplayError fields={["metadata"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="metadata">Metadata XML</label>
+          <Textarea
+            id="metadata"
+            placeholder={'<md:EntityDescriptor entityID="...">'}
+            value={this.state.metadata}
+            onChange={e => this.setState({ metadata: e.currentTarget.value })}
+          />
+          <p className="info">
+            Optional. Fields below that are left empty are filled from the metadata of your identity provider.
+          </p>
+        </div>
+        {this.renderField({ field: "idpEntityId", label: "Entity ID" })}
+        {this.renderField({ field: "idpSsoUrl", label: "SSO URL", placeholder: "https://idp.yourcompany.com/sso" })}
+        <DisplayError fields={["idpCertificate"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="idpCertificate">Signing certificate</label>
+          <Textarea
+            id="idpCertificate"
+            placeholder="-----BEGIN CERTIFICATE-----"
+            value={this.state.idpCertificate}
+            onChange={e => this.setState({ idpCertificate: e.currentTarget.value })}
+          />
+        </div>
+
+        <h4 className="ui dividing header">Attributes</h4>
+        {this.renderField({
+          field: "emailAttribute",
+          label: "Email",
+          placeholder: "email",
+          info: "When empty, the NameID of the assertion is used as email."
+        })}
+        {this.renderField({ field: "nameAttribute", label: "Name", placeholder: "displayName" })}
+        {this.renderField({
+          field: "roleAttribute",
+          label: "Role",
+          placeholder: "groups",
+          info: "When set, the role of users is updated every time they sign in."
+        })}
+        {this.renderField({ field: "administratorRoleValue", label: "Administrator role value", placeholder: "admins" })}
+        {this.renderField({ field: "collaboratorRoleValue", label: "Collaborator role value" })}
+
+        <h4 className="ui dividing header">Sign in</h4>
+        <div className="field">
+          <label htmlFor="isEnabled">
+            Enabled
+            <Toggle active={this.state.isEnabled} onToggle={async active => this.setState({ isEnabled: active })} />
+          </label>
+        </div>
+        <DisplayError fields={["isEnforced"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="isEnforced">
+            Enforced
+            <Toggle active={this.state.isEnforced} onToggle={async active => this.setState({ isEnforced: active })} />
+          </label>
+          <p className="info">
+            When enforced, users can only sign in with single sign-on. Administrators can still sign in by email.
+          </p>
+        </div>
+
+        <div className="field">
+          <Button color="positive" onClick={this.save}>
+            Save
+          </Button>
+        </div>
+
+        <h4 className="ui dividing header">Trusted Application</h4>
+        <p className="info">
+          Sign in users of your own application by redirecting them to <strong>{this.props.jwtUrl}?token=</strong>{" "}
+          followed by a signed JWT. Tokens must have the <code>sub</code>, <code>email</code>, <code>jti</code> and{" "}
+          <code>exp</code> claims and expire within 10 minutes. Each token can only be used once.
+        </p>
+        <DisplayError error={this.state.jwtError} />
+        <DisplayError fields={["secret"]} error={this.state.jwtError} />
+        <div className="field">
+          <label htmlFor="jwtSecret">Shared secret</label>
+          <input
+            id="jwtSecret"
+            type="password"
+            maxLength={200}
+            placeholder={this.state.jwt.hasSecret ? "Leave empty to keep current secret" : ""}
+            value={this.state.jwtSecret}
+            onChange={e => this.setState({ jwtSecret: e.currentTarget.value })}
+          />
+          <p className="info">Used to verify tokens signed with HS256. Must have at least 32 characters.</p>
+        </div>
+        <DisplayError fields={["publicKey"]} error={this.state.jwtError} />
+        <div className="field">
+          <label htmlFor="jwtPublicKey">Public key</label>
+          <Textarea
+            id="jwtPublicKey"
+            placeholder="-----BEGIN PUBLIC KEY-----"
+            value={this.state.jwt.publicKey}
+            onChange={e => this.setState({ jwt: { ...this.state.jwt, publicKey: e.currentTarget.value } })}
+          />
+          <p className="info">
+            Optional. When set, only tokens signed with RSA or ECDSA are accepted and the shared secret is ignored.
+          </p>
+        </div>
+        <div className="field">
+          <label htmlFor="jwtIsEnabled">
+            Enabled
+            <Toggle
+              active={this.state.jwt.isEnabled}
+              onToggle={async active => this.setState({ jwt: { ...this.state.jwt, isEnabled: active } })}
+            />
+          </label>
+        </div>
+        <div className="field">
+          <Button color="positive" onClick={this.saveJWT}>
+            Save
+          </Button>
+        </div>
+      </div>
+    );
+  }
+}
@@ -0,0 +1,183 @@
+import * as React from "react";
+
+import { CurrentUser, Tenant } from "@fider/models";
+import { Button, ButtonClickEvent, Textarea, DisplayError } from "@fider/components/common";
+import { actions, can, Failure, fileToBase64 } from "@fider/services";
+import { AdminBasePage } from "../components";
+
+interface ThemeSettingsPageProps {
+  user: CurrentUser;
+  tenant: Tenant;
+}
+
+interface ThemeSettingsPageState {
+  primaryColor: string;
+  accentColor: string;
+  customCSS: string;
+  favicon?: actions.ImageUploadRequest;
+  header?: actions.ImageUploadRequest;
+  error?: Failure;
+}
+
+type ThemeImage = "favicon" | "header";
+
+export class ThemeSettingsPage extends AdminBasePage<ThemeSettingsPageProps, ThemeSettingsPageState> {
+  public id = "p-admin-theme";
+  public name = "theme";
+  public icon = "paint brush";
+  public title = "Theme";
+  public subtitle = "Customize the look of your site";
+
+  constructor(props: ThemeSettingsPageProps) {
+    super(props);
+
+    this.state = {
+      primaryColor: this.props.tenant.theme.primaryColor,
+      accentColor: this.props.tenant.theme.accentColor,
+      customCSS: this.props.tenant.theme.customCSS
+    };
+  }
+
+  private save = async (e: ButtonClickEvent) => {
+    const result = await actions.updateTenantTheme(this.state);
+    if (result.ok) {
+      e.preventEnable();
+      location.reload();
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private fileChanged = (image: ThemeImage) => async (e: React.ChangeEvent<HTMLInputElement>) => {
+    if (e.target.files && e.target.files[0]) {
+      const file = e.target.files[0];
+      const base64 = await fileToBase64(file);
+      const request: actions.ImageUploadRequest = {
+        upload: {
+          content: base64,
+          contentType: file.type
+        },
+        remove: false
+      };
+      this.setState(image === "favicon" ? { favicon: request } : { header: request });
+    }
+  };
+
+  private removeFile = (image: ThemeImage) => async () => {
+    const request: actions.ImageUploadRequest = { remove: true };
+    this.setState(image === "favicon" ? { favicon: request } : { header: request });
+  };
+
+  private renderImage(image: ThemeImage, label: string, currentUrl: string | undefined, info: string) {
+    const request = this.state[image];
+    const isRemoving = request ? request.remove : false;
+    const upload = request && request.upload;
+    const previewUrl = upload ? `data:${upload.contentType};base64,${upload.content}` : currentUrl;
+    const hasFile = !isRemoving && !!previewUrl;
+    const disabled = !can(this.props.user, "manage_settings");
+
+    return (
+      <>
+        <DisplayError fields={[image]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor={image}>{label}</label>
+          {hasFile && (
+            <div>
+              <img className={`theme-${image}`} src={previewUrl} />
+            </div>
+          )}
+          <input type="file" id={image} name={image} disabled={disabled} onChange={this.fileChanged(image)} />
+          {hasFile && (
+            <div>
+              <Button size="mini" onClick={this.removeFile(image)} disabled={disabled}>
+                Remove
+              </Button>
+            </div>
+          )}
+          <p className="info">{info}</p>
+        </div>
+      </>
+    );
+  }
+
+  public content() {
+    const theme = this.props.tenant.theme;
+    const disabled = !can(this.props.user, "manage_settings");
+
+    return (
+      <div className="ui form">
+        <DisplayError fields={["primaryColor", "accentColor"]} error={this.state.error} />
+        <div className="two fields">
+          <div className="field">
+            <label htmlFor="primaryColor">Primary color</label>
+            <div className="ui labeled input">
+              <div className="ui label">#</div>
+              <input
+                id="primaryColor"
+                type="text"
+                maxLength={6}
+                placeholder="0069FF"
+                disabled={disabled}
+                value={this.state.primaryColor}
+                onChange={e => this.setState({ primaryColor: e.currentTarget.value })}
+              />
+            </div>
+          </div>
+          <div className="field">
+            <label htmlFor="accentColor">Accent color</label>
+            <div className="ui labeled input">
+              <div className="ui label">#</div>
+              <input
+                id="accentColor"
+                type="text"
+                maxLength={6}
+                placeholder="15CD72"
+                disabled={disabled}
+                value={this.state.accentColor}
+                onChange={e => this.setState({ accentColor: e.currentTarget.value })}
+              />
+            </div>
+          </div>
+        </div>
+        <p className="info">Colors are hexadecimal codes. Leave them empty to use the default colors.</p>
+
+        {this.renderImage(
+          "favicon",
+          "Favicon",
+          theme.faviconId > 0 ? `/favicon/50/${theme.faviconId}` : undefined,
+          "We accept JPG, GIF and PNG images, smaller than 50KB and with an aspect ratio of 1:1 with minimum dimensions of 32x32 pixels. When not set, your logo is used."
+        )}
+
+        {this.renderImage(
+          "header",
+          "Header image",
+          theme.headerId > 0 ? `/header/${theme.headerId}` : undefined,
+          "We accept JPG, GIF and PNG images, smaller than 500KB and at least 600 pixels wide."
+        )}
+
+        <DisplayError fields={["customCSS"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="customCSS">Custom CSS</label>
+          <Textarea
+            id="customCSS"
+            disabled={disabled}
+            placeholder="#c-header { font-weight: bold; }"
+            value={this.state.customCSS}
+            onChange={e => this.setState({ customCSS: e.currentTarget.value })}
+          />
+          <p className="info">
+            Applied on every page of your site. Imports, scripts and escape sequences are removed when saved.
+          </p>
+        </div>
+
+        {can(this.props.user, "manage_settings") && (
+          <div className="field">
+            <Button color="positive" onClick={this.save}>
+              Save
+            </Button>
+          </div>
+        )}
+      </div>
+    );
+  }
+}
@@ -0,0 +1,77 @@
+import * as React from "react";
+
+import { CurrentUser } from "@fider/models";
+import { Button, Textarea, DisplayError } from "@fider/components/common";
+import { actions, can, notify, page, Failure } from "@fider/services";
+import { AdminBasePage } from "../components";
+
+interface WidgetSettingsPageProps {
+  user: CurrentUser;
+  origins: string[];
+}
+
+interface WidgetSettingsPageState {
+  origins: string;
+  error?: Failure;
+}
+
+export class WidgetSettingsPage extends AdminBasePage<WidgetSettingsPageProps, WidgetSettingsPageState> {
+  public id = "p-admin-widget";
+  public name = "widget";
+  public icon = "code";
+  public title = "Widget";
+  public subtitle = "Embed your feedback site on other websites";
+
+  constructor(props: WidgetSettingsPageProps) {
+    super(props);
+
+    this.state = {
+      origins: (this.props.origins || []).join("\n")
+    };
+  }
+
+  private save = async () => {
+    const result = await actions.updateEmbedSettings(this.state.origins.split(/[\s,]+/).filter(x => x));
+    if (result.ok) {
+      this.setState({ origins: result.data.origins.join("\n"), error: undefined });
+      notify.success("Your widget settings have been saved.");
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  public content() {
+    const snippet = `<iframe src="${page.getBaseUrl()}/widget" width="400" height="600" frameborder="0"></iframe>`;
+
+    return (
+      <div className="ui form">
+        <DisplayError fields={["origins"]} error={this.state.error} />
+        <div className="field">
+          <label htmlFor="origins">Allowed websites</label>
+          <Textarea
+            id="origins"
+            disabled={!can(this.props.user, "manage_settings")}
+            placeholder="https://www.ourcompany.com"
+            value={this.state.origins}
+            onChange={e => this.setState({ origins: e.currentTarget.value })}
+          />
+          <p className="info">
+            One address per line. Only these websites are allowed to display the widget, or ideas embedded through
+            oEmbed.
+          </p>
+        </div>
+        {can(this.props.user, "manage_settings") && (
+          <div className="field">
+            <Button color="positive" onClick={this.save}>
+              Save
+            </Button>
+          </div>
+        )}
+
+        <h4 className="ui dividing header">Embed code</h4>
+        <p className="info">Paste this code on any of the allowed websites.</p>
+        <pre>{snippet}</pre>
+      </div>
+    );
+  }
+}
@@ -23,7 +23,7 @@ export class CompleteSignInProfilePage extends React.Component<HomePageProps, Co
   private async submit() {
     const result = await actions.completeProfile(this.key, this.state.name);
     if (result.ok) {
-      location.href = "/";
+      location.href = result.data.redirect || "/";
     } else if (result.error) {
       this.form.setFailure(result.error);
     }
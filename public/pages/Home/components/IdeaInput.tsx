@@ -1,5 +1,5 @@
 import * as React from "react";
-import { DisplayError, Button, ButtonClickEvent, Form, Textarea } from "@fider/components/common";
+import { DisplayError, Button, ButtonClickEvent, Form, Textarea, Toggle } from "@fider/components/common";
 import { SignInModal } from "@fider/components";
 import { page, cache, actions, Failure } from "@fider/services";
 import { CurrentUser } from "@fider/models";
@@ -13,6 +13,7 @@ interface IdeaInputProps {
 interface IdeaInputState {
   title: string;
   description: string;
+  isPrivate: boolean;
   focused: boolean;
   showSignIn: boolean;
 }
@@ -29,6 +30,7 @@ export class IdeaInput extends React.Component<IdeaInputProps, IdeaInputState> {
     this.state = {
       title: (!!this.props.user && cache.get(CACHE_TITLE_KEY)) || "",
       description: (!!this.props.user && cache.get(CACHE_DESCRIPTION_KEY)) || "",
+      isPrivate: false,
       focused: false,
       showSignIn: false
     };
@@ -63,7 +65,7 @@ export class IdeaInput extends React.Component<IdeaInputProps, IdeaInputState> {
 
   private async submit(event: ButtonClickEvent) {
     if (this.state.title) {
-      const result = await actions.createIdea(this.state.title, this.state.description);
+      const result = await actions.createIdea(this.state.title, this.state.description, this.state.isPrivate);
       if (result.ok) {
         if (this.form) {
           this.form.clearFailure();
@@ -87,6 +89,13 @@ export class IdeaInput extends React.Component<IdeaInputProps, IdeaInputState> {
             placeholder="Describe your idea"
           />
         </div>
+        <div className="field">
+          <Toggle
+            label="Only visible to me and the staff"
+            active={this.state.isPrivate}
+            onToggle={async isPrivate => this.setState({ isPrivate })}
+          />
+        </div>
         <Button color="positive" onClick={e => this.submit(e)}>
           Submit
         </Button>
@@ -26,6 +26,7 @@ const ListIdeaItem = (props: { idea: Idea; user?: CurrentUser; tags: Tag[] }) =>
           </div>
         )}
         <a className="title gm-text gm-primary-hover" href={`/ideas/${props.idea.number}/${props.idea.slug}`}>
+          {props.idea.isPrivate && <i className="lock icon" title="Private" />}
           {props.idea.title}
         </a>
         <MultiLineText className="description" text={props.idea.description} style="simple" />
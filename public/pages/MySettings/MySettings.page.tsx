@@ -3,14 +3,15 @@ import "./MySettings.page.scss";
 import * as React from "react";
 
 import { Modal, Form, DisplayError, Button, Gravatar } from "@fider/components/common";
-import { NotificationSettings } from "./";
+import { NotificationSettings, SessionList, TwoFactorSettings } from "./";
 
-import { CurrentUser, UserSettings } from "@fider/models";
+import { CurrentUser, UserSettings, UserSession, UserTwoFactorSettings } from "@fider/models";
 import { Failure, actions } from "@fider/services";
 
 interface MySettingsPageState {
   showModal: boolean;
   name: string;
+  locale: string;
   newEmail: string;
   changingEmail: boolean;
   error?: Failure;
@@ -20,6 +21,10 @@ interface MySettingsPageState {
 interface MySettingsPageProps {
   user: CurrentUser;
   settings: UserSettings;
+  sessions: UserSession[];
+  currentSessionId: string;
+  twoFactor: UserTwoFactorSettings;
+  locales: string[];
 }
 
 export class MySettingsPage extends React.Component<MySettingsPageProps, MySettingsPageState> {
@@ -30,12 +35,13 @@ export class MySettingsPage extends React.Component<MySettingsPageProps, MySetti
       changingEmail: false,
       newEmail: "",
       name: this.props.user.name,
+      locale: this.props.user.locale,
       settings: this.props.settings
     };
   }
 
   private async confirm() {
-    const result = await actions.updateUserSettings(this.state.name, this.state.settings);
+    const result = await actions.updateUserSettings(this.state.name, this.state.locale, this.state.settings);
     if (result.ok) {
       location.reload();
     } else if (result.error) {
@@ -162,6 +168,24 @@ export class MySettingsPage extends React.Component<MySettingsPageProps, MySetti
                 />
               </div>
 
+              <DisplayError fields={["locale"]} error={this.state.error} />
+              <div className="field">
+                <label htmlFor="locale">Language</label>
+                <select
+                  id="locale"
+                  className="ui dropdown"
+                  value={this.state.locale}
+                  onChange={e => this.setState({ locale: e.currentTarget.value })}
+                >
+                  <option value="">Same as this site</option>
+                  {this.props.locales.map(x => (
+                    <option key={x} value={x}>
+                      {x}
+                    </option>
+                  ))}
+                </select>
+              </div>
+
               <NotificationSettings
                 user={this.props.user}
                 settings={this.props.settings}
@@ -173,6 +197,10 @@ export class MySettingsPage extends React.Component<MySettingsPageProps, MySetti
                   Confirm
                 </Button>
               </div>
+
+              <TwoFactorSettings settings={this.props.twoFactor} />
+
+              <SessionList sessions={this.props.sessions} currentSessionId={this.props.currentSessionId} />
             </div>
           </div>
         </div>
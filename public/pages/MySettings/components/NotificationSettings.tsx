@@ -1,6 +1,6 @@
 import * as React from "react";
 
-import { CurrentUser, UserSettings } from "@fider/models";
+import { CurrentUser, UserRole, UserSettings } from "@fider/models";
 import { Toggle } from "@fider/components";
 
 interface NotificationSettingsProps {
@@ -55,7 +55,7 @@ export class NotificationSettings extends React.Component<NotificationSettingsPr
   }
 
   private info(settingsKey: string, aboutForVisitors: string, aboutForCollaborators: string) {
-    const about = this.props.user.isCollaborator ? aboutForCollaborators : aboutForVisitors;
+    const about = this.props.user.role >= UserRole.Collaborator ? aboutForCollaborators : aboutForVisitors;
     const webEnabled = this.isEnabled(settingsKey, WebChannel);
     const emailEnabled = this.isEnabled(settingsKey, EmailChannel);
 
@@ -0,0 +1,73 @@
+import * as React from "react";
+
+import { UserSession } from "@fider/models";
+import { Button, Moment } from "@fider/components";
+import { actions } from "@fider/services";
+
+interface SessionListProps {
+  sessions: UserSession[];
+  currentSessionId: string;
+}
+
+interface SessionListState {
+  sessions: UserSession[];
+}
+
+export class SessionList extends React.Component<SessionListProps, SessionListState> {
+  constructor(props: SessionListProps) {
+    super(props);
+
+    this.state = {
+      sessions: this.props.sessions
+    };
+  }
+
+  private async revoke(session: UserSession) {
+    const result = await actions.revokeSession(session.id);
+    if (result.ok) {
+      this.setState({ sessions: this.state.sessions.filter(x => x.id !== session.id) });
+    }
+  }
+
+  private async signOutEverywhere() {
+    const result = await actions.signOutEverywhere();
+    if (result.ok) {
+      location.href = "/";
+    }
+  }
+
+  public render() {
+    return (
+      <div className="field sessions">
+        <label>Sessions</label>
+        <p className="info">These are the devices where you are currently signed in.</p>
+        <div className="ui middle aligned divided list">
+          {this.state.sessions.map(x => (
+            <div key={x.id} className="item">
+              <div className="right floated content">
+                {x.id === this.props.currentSessionId ? (
+                  <span className="info">This device</span>
+                ) : (
+                  <Button size="mini" onClick={async () => await this.revoke(x)}>
+                    Sign out
+                  </Button>
+                )}
+              </div>
+              <div className="content">
+                <div className="header">{x.userAgent || "Unknown device"}</div>
+                <div className="info">
+                  {x.ipAddress} · last seen <Moment date={x.lastSeenOn} />
+                </div>
+              </div>
+            </div>
+          ))}
+        </div>
+        <p>
+          <Button color="danger" size="mini" onClick={async () => await this.signOutEverywhere()}>
+            Sign out everywhere
+          </Button>
+        </p>
+      </div>
+    );
+  }
+}
@@ -0,0 +1,168 @@
+import * as React from "react";
+
+import { UserTwoFactorSettings, TwoFactorEnrollment } from "@fider/models";
+import { Button, DisplayError, RecoveryCodes } from "@fider/components";
+import { actions, notify, Failure } from "@fider/services";
+
+interface TwoFactorSettingsProps {
+  settings: UserTwoFactorSettings;
+}
+
+interface TwoFactorSettingsState {
+  isEnabled: boolean;
+  enrollment?: TwoFactorEnrollment;
+  isConfirming: boolean;
+  code: string;
+  recoveryCodes?: string[];
+  error?: Failure;
+}
+
+export class TwoFactorSettings extends React.Component<TwoFactorSettingsProps, TwoFactorSettingsState> {
+  constructor(props: TwoFactorSettingsProps) {
+    super(props);
+
+    this.state = {
+      isEnabled: this.props.settings.isEnabled,
+      isConfirming: false,
+      code: ""
+    };
+  }
+
+  private setup = async () => {
+    const result = await actions.setupTwoFactor();
+    if (result.ok) {
+      this.setState({ enrollment: result.data, code: "", error: undefined });
+    }
+  };
+
+  private enable = async () => {
+    const result = await actions.enableTwoFactor(this.state.code);
+    if (result.ok) {
+      this.setState({
+        isEnabled: true,
+        enrollment: undefined,
+        code: "",
+        error: undefined,
+        recoveryCodes: result.data.recoveryCodes
+      });
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private disable = async () => {
+    const result = await actions.disableTwoFactor(this.state.code);
+    if (result.ok) {
+      this.setState({ isEnabled: false, isConfirming: false, code: "", error: undefined, recoveryCodes: undefined });
+      notify.success("Two-factor authentication has been disabled.");
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private regenerate = async () => {
+    const result = await actions.regenerateRecoveryCodes(this.state.code);
+    if (result.ok) {
+      this.setState({ isConfirming: false, code: "", error: undefined, recoveryCodes: result.data.recoveryCodes });
+    } else if (result.error) {
+      this.setState({ error: result.error });
+    }
+  };
+
+  private cancel = async () => {
+    this.setState({ enrollment: undefined, isConfirming: false, code: "", error: undefined });
+  };
+
+  private renderCodeInput(label: string, onConfirm: () => Promise<void>) {
+    return (
+      <p>
+        <input
+          type="text"
+          autoComplete="one-time-code"
+          style={{ maxWidth: "200px", marginRight: "10px" }}
+          maxLength={20}
+          placeholder="Code"
+          value={this.state.code}
+          onChange={e => this.setState({ code: e.currentTarget.value })}
+        />
+        <Button color="positive" size="mini" onClick={onConfirm} disabled={this.state.code === ""}>
+          {label}
+        </Button>
+        <Button size="mini" onClick={this.cancel}>
+          Cancel
+        </Button>
+      </p>
+    );
+  }
+
+  private renderDisabled() {
+    if (this.state.enrollment) {
+      return (
+        <>
+          <p className="info">
+            Add this key to an authenticator app, such as Google Authenticator, Authy or 1Password, and enter the code
+            it shows.
+          </p>
+          <p>
+            <b>{this.state.enrollment.secret}</b> · <a href={this.state.enrollment.uri}>open in authenticator app</a>
+          </p>
+          {this.renderCodeInput("Enable", this.enable)}
+        </>
+      );
+    }
+
+    return (
+      <>
+        <p className="info">
+          {this.props.settings.isRequired
+            ? "This site requires two-factor authentication for staff members. It will be set up on your next sign in."
+            : "Protect your account with a code from an authenticator app when signing in."}
+        </p>
+        <p>
+          <Button size="mini" onClick={this.setup}>
+            Enable two-factor authentication
+          </Button>
+        </p>
+      </>
+    );
+  }
+
+  private renderEnabled() {
+    if (this.state.isConfirming) {
+      return (
+        <>
+          <p className="info">Enter a code from your authenticator app, or one of your recovery codes, to continue.</p>
+          {this.renderCodeInput("Confirm", this.props.settings.isRequired ? this.regenerate : this.disable)}
+        </>
+      );
+    }
+
+    return (
+      <>
+        <p className="info">Two-factor authentication is enabled for your account.</p>
+        <p>
+          {this.props.settings.isRequired ? (
+            <Button size="mini" onClick={async () => this.setState({ isConfirming: true })}>
+              Generate new recovery codes
+            </Button>
+          ) : (
+            <Button color="danger" size="mini" onClick={async () => this.setState({ isConfirming: true })}>
+              Disable
+            </Button>
+          )}
+        </p>
+      </>
+    );
+  }
+
+  public render() {
+    return (
+      <div className="field two-factor">
+        <label>Two-factor authentication</label>
+        <DisplayError fields={["code"]} error={this.state.error} />
+        {this.state.recoveryCodes && <RecoveryCodes codes={this.state.recoveryCodes} />}
+        {this.state.isEnabled ? this.renderEnabled() : this.renderDisabled()}
+      </div>
+    );
+  }
+}
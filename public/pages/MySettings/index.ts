@@ -1,2 +1,4 @@
 export * from "./MySettings.page";
 export * from "./components/NotificationSettings";
+export * from "./components/SessionList";
+export * from "./components/TwoFactorSettings";
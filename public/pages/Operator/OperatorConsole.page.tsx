@@ -0,0 +1,249 @@
+import "./Operator.page.scss";
+
+import * as React from "react";
+import { Button, DisplayError, Moment } from "@fider/components/common";
+import { OperatorAuditEntry, Plan, TenantStatus, TenantSummary } from "@fider/models";
+import { actions, Failure } from "@fider/services";
+
+interface OperatorConsolePageProps {
+  operator: string;
+  tenants: TenantSummary[];
+  plans: Plan[];
+  audit: OperatorAuditEntry[];
+}
+
+interface OperatorConsolePageState {
+  query: string;
+  tenants: TenantSummary[];
+  editing?: number;
+  subdomain: string;
+  error?: Failure;
+}
+
+const statusNames: { [key: number]: string } = {
+  [TenantStatus.Active]: "Active",
+  [TenantStatus.Inactive]: "Pending confirmation",
+  [TenantStatus.Suspended]: "Suspended"
+};
+
+const formatSize = (bytes: number): string => {
+  if (bytes < 1024) {
+    return `${bytes} B`;
+  }
+  if (bytes < 1024 * 1024) {
+    return `${(bytes / 1024).toFixed(1)} KB`;
+  }
+  return `${(bytes / 1024 / 1024).toFixed(1)} MB`;
+};
+
+export class OperatorConsolePage extends React.Component<OperatorConsolePageProps, OperatorConsolePageState> {
+  constructor(props: OperatorConsolePageProps) {
+    super(props);
+    this.state = {
+      query: "",
+      tenants: this.props.tenants,
+      subdomain: ""
+    };
+  }
+
+  private async search(query: string) {
+    this.setState({ query });
+    const result = await actions.searchTenants(query);
+    if (result.ok && this.state.query === query) {
+      this.setState({ tenants: result.data });
+    }
+  }
+
+  private async refresh() {
+    await this.search(this.state.query);
+  }
+
+  private async suspend(tenant: TenantSummary) {
+    if (!window.confirm(`${tenant.name} will be unavailable to everyone. Are you sure?`)) {
+      return;
+    }
+    const result = await actions.suspendTenant(tenant.id);
+    if (result.ok) {
+      await this.refresh();
+    }
+  }
+
+  private async reactivate(tenant: TenantSummary) {
+    const result = await actions.reactivateTenant(tenant.id);
+    if (result.ok) {
+      await this.refresh();
+    }
+  }
+
+  private async impersonate(tenant: TenantSummary) {
+    const result = await actions.impersonateTenantUser(tenant.id);
+    if (result.ok) {
+      window.open(result.data.url, "_blank");
+    } else if (result.error) {
+      window.alert(result.error.messages.join("\n"));
+    }
+  }
+
+  private async changePlan(tenant: TenantSummary, plan: string) {
+    const result = await actions.changeTenantPlan(tenant.id, plan);
+    if (result.ok) {
+      await this.refresh();
+    } else if (result.error) {
+      window.alert(result.error.messages.join("\n"));
+    }
+  }
+
+  private startEdit(tenant: TenantSummary) {
+    this.setState({ editing: tenant.id, subdomain: tenant.subdomain, error: undefined });
+  }
+
+  private async changeSubdomain(tenant: TenantSummary) {
+    const result = await actions.changeTenantSubdomain(tenant.id, this.state.subdomain);
+    if (result.ok) {
+      this.setState({ editing: undefined, error: undefined });
+      await this.refresh();
+    } else {
+      this.setState({ error: result.error });
+    }
+  }
+
+  private renderSubdomain(tenant: TenantSummary) {
+    if (this.state.editing !== tenant.id) {
+      return (
+        <>
+          {tenant.subdomain} {tenant.cname && <div className="info">{tenant.cname}</div>}
+        </>
+      );
+    }
+
+    return (
+      <div className="ui form">
+        <DisplayError error={this.state.error} />
+        <div className="field">
+          <input
+            type="text"
+            maxLength={40}
+            value={this.state.subdomain}
+            onChange={e => this.setState({ subdomain: e.currentTarget.value })}
+          />
+        </div>
+        <Button size="mini" color="positive" onClick={() => this.changeSubdomain(tenant)}>
+          Save
+        </Button>
+        <Button size="mini" onClick={async () => this.setState({ editing: undefined })}>
+          Cancel
+        </Button>
+      </div>
+    );
+  }
+
+  private renderTenant(tenant: TenantSummary) {
+    return (
+      <tr key={tenant.id} className={tenant.status === TenantStatus.Suspended ? "suspended" : ""}>
+        <td>
+          <b>{tenant.name}</b>
+          <div className="info">
+            #{tenant.id} · created <Moment date={tenant.createdOn} />
+          </div>
+        </td>
+        <td>{this.renderSubdomain(tenant)}</td>
+        <td>
+          {statusNames[tenant.status]}
+          {tenant.deletionScheduledOn && (
+            <div className="info">
+              deletion on <Moment date={tenant.deletionScheduledOn} />
+            </div>
+          )}
+        </td>
+        <td>
+          <select value={tenant.plan} onChange={e => this.changePlan(tenant, e.currentTarget.value)}>
+            {this.props.plans.map(p => (
+              <option key={p.name} value={p.name}>
+                {p.name}
+              </option>
+            ))}
+          </select>
+        </td>
+        <td>{tenant.userCount}</td>
+        <td>{tenant.ideaCount}</td>
+        <td>{tenant.lastActivityOn ? <Moment date={tenant.lastActivityOn} /> : "-"}</td>
+        <td>{formatSize(tenant.storageUsage)}</td>
+        <td>
+          <Button size="mini" onClick={async () => this.startEdit(tenant)}>
+            Subdomain
+          </Button>
+          {tenant.status === TenantStatus.Active && (
+            <Button size="mini" onClick={() => this.impersonate(tenant)}>
+              Impersonate
+            </Button>
+          )}
+          {tenant.status === TenantStatus.Active && (
+            <Button size="mini" color="danger" onClick={() => this.suspend(tenant)}>
+              Suspend
+            </Button>
+          )}
+          {tenant.status === TenantStatus.Suspended && (
+            <Button size="mini" color="positive" onClick={() => this.reactivate(tenant)}>
+              Reactivate
+            </Button>
+          )}
+        </td>
+      </tr>
+    );
+  }
+
+  public render() {
+    return (
+      <div id="p-operator-console" className="page ui container">
+        <h2 className="ui header">
+          Tenants
+          <div className="sub header">
+            Signed in as {this.props.operator} · <a href="/operator/signout">Sign out</a>
+          </div>
+        </h2>
+        <div className="ui fluid icon input">
+          <input
+            type="text"
+            placeholder="Search by name, subdomain or custom domain"
+            onChange={e => this.search(e.currentTarget.value)}
+          />
+          <i className="search icon" />
+        </div>
+        <table className="ui very basic compact table">
+          <thead>
+            <tr>
+              <th>Name</th>
+              <th>Domain</th>
+              <th>Status</th>
+              <th>Plan</th>
+              <th>Users</th>
+              <th>Ideas</th>
+              <th>Last activity</th>
+              <th>Storage</th>
+              <th />
+            </tr>
+          </thead>
+          <tbody>{this.state.tenants.map(t => this.renderTenant(t))}</tbody>
+        </table>
+
+        <h3 className="ui header">Audit log</h3>
+        <table className="ui very basic compact table">
+          <tbody>
+            {this.props.audit.map(e => (
+              <tr key={e.id}>
+                <td>
+                  <Moment date={e.createdOn} />
+                </td>
+                <td>{e.operatorEmail}</td>
+                <td>{e.action}</td>
+                <td>#{e.tenantId}</td>
+                <td>{e.details}</td>
+                <td>{e.ipAddress}</td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      </div>
+    );
+  }
+}
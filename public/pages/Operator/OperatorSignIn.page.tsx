@@ -0,0 +1,63 @@
+import "./Operator.page.scss";
+
+import * as React from "react";
+import { Button, DisplayError } from "@fider/components/common";
+import { actions, Failure } from "@fider/services";
+
+const logo = require("@fider/assets/images/logo-small.png");
+
+interface OperatorSignInPageState {
+  email: string;
+  secret: string;
+  error?: Failure;
+}
+
+export class OperatorSignInPage extends React.Component<{}, OperatorSignInPageState> {
+  constructor(props: {}) {
+    super(props);
+    this.state = {
+      email: "",
+      secret: ""
+    };
+  }
+
+  private async signIn() {
+    const result = await actions.operatorSignIn(this.state.email, this.state.secret);
+    if (result.ok) {
+      location.href = "/operator";
+    } else {
+      this.setState({ error: result.error });
+    }
+  }
+
+  public render() {
+    return (
+      <div id="p-operator-signin" className="page ui container">
+        <img className="logo" src={logo} />
+        <h3 className="ui header">Operators</h3>
+        <div className="ui form">
+          <DisplayError error={this.state.error} />
+          <div className="fluid field">
+            <input
+              id="email"
+              type="text"
+              placeholder="yourname@example.com"
+              onChange={e => this.setState({ email: e.currentTarget.value })}
+            />
+          </div>
+          <div className="fluid field">
+            <input
+              id="secret"
+              type="password"
+              placeholder="secret"
+              onChange={e => this.setState({ secret: e.currentTarget.value })}
+            />
+          </div>
+          <Button color="positive" onClick={() => this.signIn()}>
+            Sign in
+          </Button>
+        </div>
+      </div>
+    );
+  }
+}
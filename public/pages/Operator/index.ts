@@ -0,0 +1,2 @@
+export * from "./OperatorSignIn.page";
+export * from "./OperatorConsole.page";
@@ -3,9 +3,9 @@ import "./ShowIdea.page.scss";
 import * as React from "react";
 
 import { CurrentUser, Comment, Idea, Tag } from "@fider/models";
-import { actions, Failure } from "@fider/services";
+import { actions, can, Failure } from "@fider/services";
 
-import { TagsPanel, DiscussionPanel, ResponseForm, NotificationsPanel, ModerationPanel } from "./";
+import { TagsPanel, DiscussionPanel, ResponseForm, NotificationsPanel, PrivacyPanel, ModerationPanel } from "./";
 import {
   SupportCounter,
   ShowIdeaResponse,
@@ -83,7 +83,10 @@ export class ShowIdeaPage extends React.Component<ShowIdeaPageProps, ShowIdeaPag
                     <DisplayError key={0} fields={["title"]} pointing="above" error={this.state.error} />
                   ]
                 ) : (
-                  <h1>{this.props.idea.title}</h1>
+                  <h1>
+                    {this.props.idea.isPrivate && <i className="lock icon" title="Private" />}
+                    {this.props.idea.title}
+                  </h1>
                 )}
 
                 <span className="info">
@@ -115,40 +118,44 @@ export class ShowIdeaPage extends React.Component<ShowIdeaPageProps, ShowIdeaPag
         </div>
 
         <div className="action-col">
-          {this.props.user &&
-            this.props.user.isCollaborator && [
-              <span key={0} className="subtitle">
-                Actions
-              </span>,
-              this.state.editMode ? (
-                <div key={1} className="ui list">
-                  <div className="item">
-                    <Button color="positive" fluid={true} onClick={async () => this.saveChanges()}>
-                      <i className="save icon" /> Save
-                    </Button>
-                  </div>
-                  <div className="item">
-                    <Button fluid={true} onClick={async () => this.setState({ error: undefined, editMode: false })}>
-                      <i className="cancel icon" /> Cancel
-                    </Button>
-                  </div>
+          {(can(this.props.user, "edit_ideas") || can(this.props.user, "respond")) && [
+            <span key={0} className="subtitle">
+              Actions
+            </span>,
+            this.state.editMode ? (
+              <div key={1} className="ui list">
+                <div className="item">
+                  <Button color="positive" fluid={true} onClick={async () => this.saveChanges()}>
+                    <i className="save icon" /> Save
+                  </Button>
                 </div>
-              ) : (
-                <div key={1} className="ui list">
+                <div className="item">
+                  <Button fluid={true} onClick={async () => this.setState({ error: undefined, editMode: false })}>
+                    <i className="cancel icon" /> Cancel
+                  </Button>
+                </div>
+              </div>
+            ) : (
+              <div key={1} className="ui list">
+                {can(this.props.user, "edit_ideas") && (
                   <div className="item">
                     <Button fluid={true} onClick={async () => this.setState({ editMode: true })}>
                       <i className="edit icon" /> Edit
                     </Button>
                   </div>
+                )}
+                {can(this.props.user, "respond") && (
                   <div className="item">
                     <ResponseForm idea={this.props.idea} />
                   </div>
-                </div>
-              )
-            ]}
+                )}
+              </div>
+            )
+          ]}
 
           <TagsPanel user={this.props.user} idea={this.props.idea} tags={this.props.tags} />
           <NotificationsPanel user={this.props.user} idea={this.props.idea} subscribed={this.props.subscribed} />
+          <PrivacyPanel user={this.props.user} idea={this.props.idea} />
           <ModerationPanel user={this.props.user} idea={this.props.idea} />
         </div>
 
@@ -1,6 +1,6 @@
 import * as React from "react";
 import { Idea, Comment, CurrentUser } from "@fider/models";
-import { Failure, actions, formatDate } from "@fider/services";
+import { Failure, actions, can, formatDate } from "@fider/services";
 import { DisplayError, Textarea, Button, UserName, Gravatar, Moment, MultiLineText } from "@fider/components/common";
 
 interface CommentListProps {
@@ -12,6 +12,7 @@ interface CommentListProps {
 }
 
 interface CommentListState {
+  deletedComments: number[];
   editingComment?: Comment;
   editCommentNewContent: string;
   error?: Failure;
@@ -21,6 +22,7 @@ export class CommentList extends React.Component<CommentListProps, CommentListSt
   constructor(props: CommentListProps) {
     super(props);
     this.state = {
+      deletedComments: [],
       editCommentNewContent: ""
     };
   }
@@ -67,15 +69,27 @@ export class CommentList extends React.Component<CommentListProps, CommentListSt
     }
   }
 
+  private async deleteComment(comment: Comment): Promise<void> {
+    if (!window.confirm("Are you sure you want to delete this comment?")) {
+      return;
+    }
+
+    const response = await actions.deleteComment(this.props.idea.number, comment.id);
+    if (response.ok) {
+      this.setState({ deletedComments: this.state.deletedComments.concat(comment.id) });
+    }
+  }
+
   private canEditComment(comment: Comment): boolean {
     if (this.props.user) {
-      return this.props.user.isCollaborator || comment.user.id === this.props.user.id;
+      return can(this.props.user, "moderate_comments") || comment.user.id === this.props.user.id;
     }
     return false;
   }
 
   public render() {
-    return this.props.comments.map(c => {
+    const comments = this.props.comments.filter(c => this.state.deletedComments.indexOf(c.id) === -1);
+    return comments.map(c => {
       return (
         <div key={c.id} className="comment">
           <Gravatar user={c.user} />
@@ -101,6 +115,14 @@ export class CommentList extends React.Component<CommentListProps, CommentListSt
                 </span>
               </div>
             )}
+            {can(this.props.user, "moderate_comments") && (
+              <div className="metadata">
+                ·{" "}
+                <span className="clickable" onClick={() => this.deleteComment(c)}>
+                  delete
+                </span>
+              </div>
+            )}
             <div className="text">
               {c === this.state.editingComment ? (
                 <div className="ui form">
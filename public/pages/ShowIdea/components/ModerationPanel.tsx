@@ -1,6 +1,6 @@
 import * as React from "react";
 import { IdeaStatus, CurrentUser, Idea } from "@fider/models";
-import { page, actions, Failure } from "@fider/services";
+import { page, actions, can, Failure } from "@fider/services";
 import { Form, DisplayError, Textarea, Modal, Button } from "@fider/components";
 
 interface ModerationPanelProps {
@@ -40,7 +40,7 @@ export class ModerationPanel extends React.Component<ModerationPanelProps, Moder
 
   public render() {
     const status = IdeaStatus.Get(this.props.idea.status);
-    if (!this.props.user || !this.props.user.isAdministrator || status.closed) {
+    if (!can(this.props.user, "delete_ideas") || status.closed) {
       return null;
     }
 
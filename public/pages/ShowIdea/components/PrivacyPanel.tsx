@@ -0,0 +1,65 @@
+import * as React from "react";
+import { CurrentUser, Idea } from "@fider/models";
+import { Button } from "@fider/components/common";
+import { actions, can } from "@fider/services";
+
+interface PrivacyPanelProps {
+  user: CurrentUser | undefined;
+  idea: Idea;
+}
+
+interface PrivacyPanelState {
+  isPrivate: boolean;
+}
+
+export class PrivacyPanel extends React.Component<PrivacyPanelProps, PrivacyPanelState> {
+  constructor(props: PrivacyPanelProps) {
+    super(props);
+    this.state = {
+      isPrivate: this.props.idea.isPrivate
+    };
+  }
+
+  private togglePrivacy = async () => {
+    const isPrivate = !this.state.isPrivate;
+    const response = await actions.setIdeaPrivacy(this.props.idea.number, isPrivate);
+    if (response.ok) {
+      this.setState({ isPrivate });
+    }
+  };
+
+  public render() {
+    const user = this.props.user;
+    if (!user || (!can(user, "edit_ideas") && user.id !== this.props.idea.user.id)) {
+      return null;
+    }
+
+    const button = this.state.isPrivate ? (
+      <Button fluid={true} onClick={this.togglePrivacy}>
+        <i className="unlock icon" /> Make public
+      </Button>
+    ) : (
+      <Button fluid={true} onClick={this.togglePrivacy}>
+        <i className="lock icon" /> Make private
+      </Button>
+    );
+
+    const text = this.state.isPrivate ? (
+      <span className="info">Only the author and the staff can see this idea.</span>
+    ) : (
+      <span className="info">Everyone can see this idea.</span>
+    );
+
+    return (
+      <>
+        <span className="subtitle">Privacy</span>
+        <div className="ui list">
+          <div className="item">
+            {button}
+            {text}
+          </div>
+        </div>
+      </>
+    );
+  }
+}
@@ -1,6 +1,6 @@
 import * as React from "react";
 import { CurrentUser, Tag, Idea } from "@fider/models";
-import { actions } from "@fider/services";
+import { actions, can } from "@fider/services";
 import { ShowTag } from "@fider/components";
 
 interface TagsPanelProps {
@@ -19,7 +19,7 @@ export class TagsPanel extends React.Component<TagsPanelProps, TagsPanelState> {
   constructor(props: TagsPanelProps) {
     super(props);
     this.state = {
-      canEdit: !!this.props.user && this.props.user.isCollaborator && this.props.tags.length > 0,
+      canEdit: can(this.props.user, "assign_tags") && this.props.tags.length > 0,
       isEditing: false,
       assignedTags: this.props.tags.filter(t => this.props.idea.tags.indexOf(t.slug) >= 0)
     };
@@ -6,4 +6,5 @@ export * from "./components/IdeaSearch";
 export * from "./components/TagsPanel";
 export * from "./components/ModerationPanel";
 export * from "./components/NotificationsPanel";
+export * from "./components/PrivacyPanel";
 export * from "./components/DiscussionPanel";
@@ -0,0 +1,23 @@
+import * as React from "react";
+
+import { Page } from "@fider/models";
+import { Moment } from "@fider/components";
+
+interface ShowPagePageProps {
+  page: Page;
+  html: string;
+}
+
+export const ShowPagePage = (props: ShowPagePageProps) => {
+  return (
+    <div id="p-show-page" className="page ui container">
+      <h1 className="ui header">
+        {props.page.title}
+        <div className="sub header">
+          Updated <Moment date={props.page.updatedOn} />
+        </div>
+      </h1>
+      <div className="markdown-body" dangerouslySetInnerHTML={{ __html: props.html }} />
+    </div>
+  );
+};
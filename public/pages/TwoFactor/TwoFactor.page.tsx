@@ -0,0 +1,111 @@
+import "./TwoFactor.page.scss";
+
+import * as React from "react";
+import { Logo, Form, Button, RecoveryCodes } from "@fider/components/common";
+import { actions } from "@fider/services";
+import { Tenant, TwoFactorEnrollment } from "@fider/models";
+
+interface TwoFactorPageProps {
+  tenant: Tenant;
+  isEnrolling: boolean;
+  enrollment?: TwoFactorEnrollment;
+}
+
+interface TwoFactorPageState {
+  code: string;
+  redirect?: string;
+  recoveryCodes?: string[];
+}
+
+export class TwoFactorPage extends React.Component<TwoFactorPageProps, TwoFactorPageState> {
+  private form!: Form;
+
+  constructor(props: TwoFactorPageProps) {
+    super(props);
+    this.state = {
+      code: ""
+    };
+  }
+
+  private submit = async () => {
+    const result = await actions.verifyTwoFactor(this.state.code);
+    if (result.ok) {
+      if (result.data.recoveryCodes && result.data.recoveryCodes.length > 0) {
+        this.setState({ redirect: result.data.redirect, recoveryCodes: result.data.recoveryCodes });
+      } else {
+        location.href = result.data.redirect;
+      }
+    } else if (result.error) {
+      this.form.setFailure(result.error);
+    }
+  };
+
+  private continue = async () => {
+    if (this.state.redirect) {
+      location.href = this.state.redirect;
+    }
+  };
+
+  private renderEnrollment() {
+    const enrollment = this.props.enrollment!;
+    return (
+      <>
+        <p>
+          <b>{this.props.tenant.name}</b> requires two-factor authentication for staff members. Add the key below to an
+          authenticator app, such as Google Authenticator, Authy or 1Password, and enter the code it shows.
+        </p>
+        <p className="secret">{enrollment.secret}</p>
+        <p>
+          <a href={enrollment.uri}>Open in authenticator app</a>
+        </p>
+      </>
+    );
+  }
+
+  public render() {
+    if (this.state.recoveryCodes) {
+      return (
+        <div id="p-two-factor" className="page ui container">
+          <RecoveryCodes codes={this.state.recoveryCodes} />
+          <Button color="positive" onClick={this.continue}>
+            Continue
+          </Button>
+        </div>
+      );
+    }
+
+    return (
+      <div id="p-two-factor" className="page ui container">
+        <div className="message">
+          <Logo size={100} tenant={this.props.tenant} />
+          <h3>Two-factor authentication</h3>
+        </div>
+        {this.props.isEnrolling ? (
+          this.renderEnrollment()
+        ) : (
+          <p>Enter the code from your authenticator app, or one of your recovery codes.</p>
+        )}
+        <Form
+          ref={f => {
+            this.form = f!;
+          }}
+          onSubmit={this.submit}
+        >
+          <div className="ui small action fluid input">
+            <input
+              onChange={e => this.setState({ code: e.currentTarget.value })}
+              type="text"
+              autoComplete="one-time-code"
+              autoFocus={true}
+              maxLength={20}
+              placeholder="Code"
+            />
+            <Button onClick={this.submit} color="positive" disabled={this.state.code === ""}>
+              Verify
+            </Button>
+          </div>
+        </Form>
+      </div>
+    );
+  }
+}
@@ -0,0 +1,136 @@
+import "./Widget.page.scss";
+
+import * as React from "react";
+import { Idea, CurrentUser, Tenant } from "@fider/models";
+import { Button, Form } from "@fider/components/common";
+import { actions } from "@fider/services";
+import { widgetSession, WidgetSupportButton } from "./";
+
+interface WidgetPageProps {
+  user?: CurrentUser;
+  tenant: Tenant;
+  ideas: Idea[];
+}
+
+interface WidgetPageState {
+  isSignedIn: boolean;
+  ideas: Idea[];
+  query: string;
+  title: string;
+  description: string;
+}
+
+export class WidgetPage extends React.Component<WidgetPageProps, WidgetPageState> {
+  private form!: Form;
+
+  constructor(props: WidgetPageProps) {
+    super(props);
+    this.state = {
+      isSignedIn: !!props.user,
+      ideas: props.ideas,
+      query: "",
+      title: "",
+      description: ""
+    };
+  }
+
+  public componentDidMount() {
+    if (!this.props.user && widgetSession.restore()) {
+      this.setState({ isSignedIn: true }, this.search);
+    }
+  }
+
+  private signIn = async (): Promise<boolean> => {
+    const ok = await widgetSession.signIn();
+    if (ok) {
+      this.setState({ isSignedIn: true }, this.search);
+    }
+    return ok;
+  };
+
+  private search = async () => {
+    const result = await actions.searchIdeas(this.state.query, "trending", []);
+    if (result.ok) {
+      this.setState({ ideas: result.data });
+    }
+  };
+
+  private onSearchKeyDown = (e: React.KeyboardEvent<HTMLInputElement>) => {
+    if (e.keyCode === 13) {
+      // ENTER
+      this.search();
+      e.preventDefault();
+    }
+  };
+
+  private submit = async () => {
+    if (!this.state.isSignedIn && !(await this.signIn())) {
+      return;
+    }
+
+    const result = await actions.createIdea(this.state.title, this.state.description, false);
+    if (result.ok) {
+      this.form.clearFailure();
+      this.setState({ title: "", description: "", query: "" }, this.search);
+    } else if (result.error) {
+      this.form.setFailure(result.error);
+    }
+  };
+
+  public render() {
+    return (
+      <div id="p-widget">
+        <Form
+          ref={f => {
+            this.form = f!;
+          }}
+        >
+          <div className="field">
+            <input
+              type="text"
+              placeholder={this.props.tenant.invitation || "Enter your suggestion here..."}
+              value={this.state.title}
+              onChange={e => this.setState({ title: e.currentTarget.value })}
+            />
+          </div>
+          {this.state.title && (
+            <>
+              <div className="field">
+                <textarea
+                  rows={3}
+                  placeholder="Describe your suggestion (optional)"
+                  value={this.state.description}
+                  onChange={e => this.setState({ description: e.currentTarget.value })}
+                />
+              </div>
+              <Button color="positive" onClick={this.submit}>
+                Submit
+              </Button>
+            </>
+          )}
+        </Form>
+
+        <div className="ui small fluid icon input">
+          <input
+            type="text"
+            placeholder="Search..."
+            value={this.state.query}
+            onChange={e => this.setState({ query: e.currentTarget.value })}
+            onKeyDown={this.onSearchKeyDown}
+          />
+          <i className="search icon" />
+        </div>
+
+        {this.state.ideas.map(idea => (
+          <div key={idea.id} className="c-widget-idea">
+            <WidgetSupportButton idea={idea} isSignedIn={this.state.isSignedIn} onSignIn={this.signIn} />
+            <a href={`/ideas/${idea.number}/${idea.slug}`} target="_blank">
+              {idea.title}
+            </a>
+          </div>
+        ))}
+        {this.state.ideas.length === 0 && <p className="info">No ideas found.</p>}
+      </div>
+    );
+  }
+}
@@ -0,0 +1,49 @@
+import "./Widget.page.scss";
+
+import * as React from "react";
+import { Idea, CurrentUser, Tenant } from "@fider/models";
+import { MultiLineText } from "@fider/components/common";
+import { widgetSession, WidgetSupportButton } from "./";
+
+interface WidgetIdeaPageProps {
+  user?: CurrentUser;
+  tenant: Tenant;
+  idea: Idea;
+}
+
+interface WidgetIdeaPageState {
+  isSignedIn: boolean;
+}
+
+export class WidgetIdeaPage extends React.Component<WidgetIdeaPageProps, WidgetIdeaPageState> {
+  constructor(props: WidgetIdeaPageProps) {
+    super(props);
+    this.state = {
+      isSignedIn: !!props.user || widgetSession.restore()
+    };
+  }
+
+  private signIn = async (): Promise<boolean> => {
+    const ok = await widgetSession.signIn();
+    this.setState({ isSignedIn: ok });
+    return ok;
+  };
+
+  public render() {
+    const idea = this.props.idea;
+    return (
+      <div id="p-widget-idea">
+        <div className="c-widget-idea">
+          <WidgetSupportButton idea={idea} isSignedIn={this.state.isSignedIn} onSignIn={this.signIn} />
+          <div>
+            <a href={`/ideas/${idea.number}/${idea.slug}`} target="_blank">
+              {idea.title}
+            </a>
+            <MultiLineText text={idea.description} style="simple" />
+            <p className="info">on {this.props.tenant.name}</p>
+          </div>
+        </div>
+      </div>
+    );
+  }
+}
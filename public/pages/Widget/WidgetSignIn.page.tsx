@@ -0,0 +1,58 @@
+import * as React from "react";
+import { Tenant } from "@fider/models";
+import { SignInControl } from "@fider/components/common";
+import { actions } from "@fider/services";
+import { widgetSession } from "./";
+
+interface WidgetSignInPageProps {
+  tenant: Tenant;
+  signedIn: boolean;
+}
+
+interface WidgetSignInPageState {
+  email?: string;
+}
+
+export class WidgetSignInPage extends React.Component<WidgetSignInPageProps, WidgetSignInPageState> {
+  constructor(props: WidgetSignInPageProps) {
+    super(props);
+    this.state = {};
+  }
+
+  public async componentDidMount() {
+    if (this.props.signedIn) {
+      const result = await actions.getWidgetToken();
+      if (result.ok) {
+        widgetSession.complete(result.data.token);
+      }
+    }
+  }
+
+  public render() {
+    if (this.props.signedIn) {
+      return (
+        <div id="p-widget-signin" className="page ui container">
+          <p>You are signed in. You can now close this window.</p>
+        </div>
+      );
+    }
+
+    return (
+      <div id="p-widget-signin" className="page ui container">
+        <h3 className="ui header">Sign in to {this.props.tenant.name}</h3>
+        {this.state.email ? (
+          <p>
+            We have just sent a confirmation link to <b>{this.state.email}</b>. Once confirmed, sign in from the widget
+            again.
+          </p>
+        ) : (
+          <SignInControl
+            useEmail={true}
+            redirectTo={location.href}
+            onEmailSent={email => this.setState({ email })}
+          />
+        )}
+      </div>
+    );
+  }
+}
@@ -0,0 +1,43 @@
+import { http, cache } from "@fider/services";
+
+const CACHE_TOKEN_KEY = "WidgetSession-Token";
+const SIGNIN_MESSAGE_TYPE = "fider:widget:signin";
+
+// Widgets are rendered inside third-party pages, where browsers may block our cookies.
+// Signing in happens on a first-party popup, which hands back a token sent as a Bearer token.
+export const widgetSession = {
+  restore: (): boolean => {
+    const token = cache.get(CACHE_TOKEN_KEY);
+    if (token) {
+      http.setAuthToken(token);
+      return true;
+    }
+    return false;
+  },
+  signIn: (): Promise<boolean> => {
+    return new Promise<boolean>(resolve => {
+      const popup = window.open("/widget/signin", "fider-widget-signin", "width=500,height=600");
+      if (!popup) {
+        resolve(false);
+        return;
+      }
+
+      const onMessage = (e: MessageEvent) => {
+        if (e.origin !== location.origin || !e.data || e.data.type !== SIGNIN_MESSAGE_TYPE) {
+          return;
+        }
+        window.removeEventListener("message", onMessage);
+        cache.set(CACHE_TOKEN_KEY, e.data.token);
+        http.setAuthToken(e.data.token);
+        resolve(true);
+      };
+      window.addEventListener("message", onMessage);
+    });
+  },
+  complete: (token: string): void => {
+    if (window.opener) {
+      window.opener.postMessage({ type: SIGNIN_MESSAGE_TYPE, token }, location.origin);
+      window.close();
+    }
+  }
+};
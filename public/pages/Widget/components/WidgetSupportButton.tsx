@@ -0,0 +1,57 @@
+import * as React from "react";
+import { Idea, IdeaStatus } from "@fider/models";
+import { actions, classSet } from "@fider/services";
+
+interface WidgetSupportButtonProps {
+  idea: Idea;
+  isSignedIn: boolean;
+  onSignIn: () => Promise<boolean>;
+}
+
+interface WidgetSupportButtonState {
+  supported: boolean;
+  total: number;
+}
+
+export class WidgetSupportButton extends React.Component<WidgetSupportButtonProps, WidgetSupportButtonState> {
+  constructor(props: WidgetSupportButtonProps) {
+    super(props);
+    this.state = {
+      supported: props.idea.viewerSupported,
+      total: props.idea.totalSupporters
+    };
+  }
+
+  private supportOrUndo = async () => {
+    if (!this.props.isSignedIn && !(await this.props.onSignIn())) {
+      return;
+    }
+
+    const action = this.state.supported ? actions.removeSupport : actions.addSupport;
+    const response = await action(this.props.idea.number);
+    if (response.ok) {
+      this.setState(state => ({
+        supported: !state.supported,
+        total: state.total + (state.supported ? -1 : 1)
+      }));
+    }
+  };
+
+  public render() {
+    const status = IdeaStatus.Get(this.props.idea.status);
+    const className = classSet({
+      "c-support-counter": true,
+      supported: !status.closed && this.state.supported,
+      disabled: status.closed
+    });
+
+    return (
+      <div className={className}>
+        <button disabled={status.closed} onClick={this.supportOrUndo}>
+          <i className="medium caret up icon" />
+          {this.state.total}
+        </button>
+      </div>
+    );
+  }
+}
@@ -0,0 +1,5 @@
+export * from "./Widget.page";
+export * from "./WidgetIdea.page";
+export * from "./WidgetSignIn.page";
+export * from "./components/WidgetSession";
+export * from "./components/WidgetSupportButton";
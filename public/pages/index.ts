@@ -3,6 +3,10 @@ export * from "./Administration";
 export * from "./SignIn";
 export * from "./SignUp";
 export * from "./CompleteSignInProfile";
+export * from "./TwoFactor";
 export * from "./MySettings";
 export * from "./MyNotifications";
 export * from "./ShowIdea";
+export * from "./ShowPage";
+export * from "./Operator";
+export * from "./Widget";
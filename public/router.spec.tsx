@@ -1,11 +1,13 @@
 import { resolveRootComponent } from "./router";
-import { HomePage, ShowIdeaPage, InvitationsPage, GeneralSettingsPage } from "@fider/pages";
+import { HomePage, ShowIdeaPage, ShowPagePage, InvitationsPage, GeneralSettingsPage } from "@fider/pages";
 
 [
   { path: "", expected: HomePage },
   { path: "/ideas/123", expected: ShowIdeaPage },
   { path: "/ideas/123/the-slug", expected: ShowIdeaPage },
   { path: "/ideas" },
+  { path: "/pages/terms-of-use", expected: ShowPagePage },
+  { path: "/pages" },
   { path: "/admin", expected: GeneralSettingsPage },
   { path: "/admin/invitations", expected: InvitationsPage }
 ].forEach(x => {
@@ -5,15 +5,30 @@ import {
   SignInPage,
   SignUpPage,
   ManageMembersPage,
+  ManageRolesPage,
   CompleteSignInProfilePage,
+  TwoFactorPage,
   PrivacySettingsPage,
+  ThemeSettingsPage,
   InvitationsPage,
   ExportPage,
+  EmailLogPage,
+  EmailSettingsPage,
+  ManageAuthenticationPage,
+  SSOSettingsPage,
   GeneralSettingsPage,
   ManageTagsPage,
   ShowIdeaPage,
+  ShowPagePage,
+  ManagePagesPage,
   MySettingsPage,
-  MyNotificationsPage
+  MyNotificationsPage,
+  OperatorSignInPage,
+  OperatorConsolePage,
+  WidgetPage,
+  WidgetIdeaPage,
+  WidgetSignInPage,
+  WidgetSettingsPage
 } from "@fider/pages";
 
 interface PageConfiguration {
@@ -26,6 +41,7 @@ const route = (path: string, component: any, showHeader: boolean = true): PageCo
   path = path
     .replace("/", "/")
     .replace(":number", "\\d+")
+    .replace(":slug", "[a-z0-9-]+")
     .replace("*", "/?.*");
 
   const regex = new RegExp(`^${path}$`);
@@ -35,18 +51,33 @@ const route = (path: string, component: any, showHeader: boolean = true): PageCo
 const pathRegex = [
   route("", HomePage),
   route("/ideas/:number*", ShowIdeaPage),
+  route("/pages/:slug", ShowPagePage),
   route("/admin/members", ManageMembersPage),
+  route("/admin/roles", ManageRolesPage),
   route("/admin/tags", ManageTagsPage),
+  route("/admin/pages", ManagePagesPage),
   route("/admin/privacy", PrivacySettingsPage),
+  route("/admin/theme", ThemeSettingsPage),
   route("/admin/export", ExportPage),
+  route("/admin/email-log", EmailLogPage),
+  route("/admin/email", EmailSettingsPage),
+  route("/admin/authentication", ManageAuthenticationPage),
+  route("/admin/sso", SSOSettingsPage),
   route("/admin/invitations", InvitationsPage),
+  route("/admin/widget", WidgetSettingsPage),
   route("/admin", GeneralSettingsPage),
   route("/signin", SignInPage, false),
   route("/signup", SignUpPage, false),
   route("/signin/verify", CompleteSignInProfilePage),
+  route("/signin/2fa", TwoFactorPage, false),
   route("/invite/verify", CompleteSignInProfilePage),
   route("/notifications", MyNotificationsPage),
-  route("/settings", MySettingsPage)
+  route("/settings", MySettingsPage),
+  route("/operator/signin", OperatorSignInPage, false),
+  route("/operator", OperatorConsolePage, false),
+  route("/widget", WidgetPage, false),
+  route("/widget/ideas/:number", WidgetIdeaPage, false),
+  route("/widget/signin", WidgetSignInPage, false)
 ];
 
 export const resolveRootComponent = (path: string): PageConfiguration => {
@@ -0,0 +1,36 @@
+import { http, Result } from "@fider/services/http";
+
+export const removeEmailSuppression = async (address: string): Promise<Result> => {
+  return http.delete("/api/admin/email-suppressions", { address }).then(http.event("email", "unsuppress"));
+};
+
+export interface UpdateEmailSettingsRequest {
+  fromAddress: string;
+  replyTo: string;
+  smtpHost: string;
+  smtpPort: string;
+  smtpUsername: string;
+  smtpPassword: string;
+  dkimSelector: string;
+  dkimPrivateKey: string;
+}
+
+export interface UpdateEmailSettingsResponse {
+  domain: string;
+  isVerified: boolean;
+  verificationRecord: string;
+}
+
+export const updateEmailSettings = async (
+  request: UpdateEmailSettingsRequest
+): Promise<Result<UpdateEmailSettingsResponse>> => {
+  return http.post<UpdateEmailSettingsResponse>("/api/admin/settings/email", request);
+};
+
+export const sendSenderVerification = async (): Promise<Result> => {
+  return http.post("/api/admin/settings/email/verify");
+};
+
+export const checkSenderDomain = async (): Promise<Result> => {
+  return http.post("/api/admin/settings/email/check-dns");
+};
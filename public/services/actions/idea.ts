@@ -41,6 +41,10 @@ export const updateComment = async (ideaNumber: number, commentId: number, conte
   return http.post(`/api/ideas/${ideaNumber}/comments/${commentId}`, { content }).then(http.event("comment", "update"));
 };
 
+export const deleteComment = async (ideaNumber: number, commentId: number): Promise<Result> => {
+  return http.delete(`/api/ideas/${ideaNumber}/comments/${commentId}`).then(http.event("comment", "delete"));
+};
+
 interface SetResponseInput {
   status: number;
   text: string;
@@ -57,8 +61,12 @@ export const respond = async (ideaNumber: number, input: SetResponseInput): Prom
     .then(http.event("idea", "respond"));
 };
 
-export const createIdea = async (title: string, description: string): Promise<Result<Idea>> => {
-  return http.post<Idea>(`/api/ideas`, { title, description }).then(http.event("idea", "create"));
+export const createIdea = async (title: string, description: string, isPrivate: boolean): Promise<Result<Idea>> => {
+  return http.post<Idea>(`/api/ideas`, { title, description, isPrivate }).then(http.event("idea", "create"));
+};
+
+export const setIdeaPrivacy = async (ideaNumber: number, isPrivate: boolean): Promise<Result> => {
+  return http.post(`/api/ideas/${ideaNumber}/privacy`, { isPrivate }).then(http.event("idea", "privacy"));
 };
 
 export const updateIdea = async (ideaNumber: number, title: string, description: string): Promise<Result> => {
@@ -5,3 +5,6 @@ export * from "./tenant";
 export * from "./notification";
 export * from "./invite";
 export { Failure } from "@fider/services/http";
+export * from "./email";
+export * from "./operator";
+export * from "./page";
@@ -1,4 +1,5 @@
 import { http, Result } from "@fider/services";
+import { Invitation } from "@fider/models";
 
 export const sendInvites = async (subject: string, message: string, recipients: string[]): Promise<Result> => {
   return http.post("/api/admin/invitations/send", { subject, message, recipients }).then(http.event("invite", "send"));
@@ -7,3 +8,25 @@ export const sendInvites = async (subject: string, message: string, recipients:
 export const sendSampleInvite = async (subject: string, message: string): Promise<Result> => {
   return http.post("/api/admin/invitations/sample", { subject, message }).then(http.event("invite", "sample"));
 };
+
+export const listInvitations = async (): Promise<Result<Invitation[]>> => {
+  return http.get<Invitation[]>("/api/admin/invitations");
+};
+
+export const importInvitations = async (
+  subject: string,
+  message: string,
+  csv: string
+): Promise<Result<{ total: number }>> => {
+  return http
+    .post<{ total: number }>("/api/admin/invitations/import", { subject, message, csv })
+    .then(http.event("invite", "import"));
+};
+
+export const resendInvitation = async (id: number, subject: string, message: string): Promise<Result> => {
+  return http.post(`/api/admin/invitations/${id}/resend`, { subject, message }).then(http.event("invite", "resend"));
+};
+
+export const revokeInvitation = async (id: number): Promise<Result> => {
+  return http.delete(`/api/admin/invitations/${id}`).then(http.event("invite", "revoke"));
+};
@@ -0,0 +1,34 @@
+import { http, Result } from "@fider/services/http";
+import { TenantSummary } from "@fider/models";
+
+export const operatorSignIn = async (email: string, secret: string): Promise<Result> => {
+  return await http.post("/api/operator/signin", { email, secret });
+};
+
+export const searchTenants = async (query: string): Promise<Result<TenantSummary[]>> => {
+  return await http.get<TenantSummary[]>(`/api/operator/tenants?q=${encodeURIComponent(query)}`);
+};
+
+export const suspendTenant = async (id: number): Promise<Result> => {
+  return await http.post(`/api/operator/tenants/${id}/suspend`);
+};
+
+export const reactivateTenant = async (id: number): Promise<Result> => {
+  return await http.post(`/api/operator/tenants/${id}/reactivate`);
+};
+
+export const changeTenantSubdomain = async (id: number, subdomain: string): Promise<Result> => {
+  return await http.post(`/api/operator/tenants/${id}/subdomain`, { subdomain });
+};
+
+export const changeTenantPlan = async (id: number, plan: string): Promise<Result> => {
+  return await http.post(`/api/operator/tenants/${id}/plan`, { plan });
+};
+
+export interface ImpersonateTenantUserResponse {
+  url: string;
+}
+
+export const impersonateTenantUser = async (id: number): Promise<Result<ImpersonateTenantUserResponse>> => {
+  return await http.post<ImpersonateTenantUserResponse>(`/api/operator/tenants/${id}/impersonate`, {});
+};
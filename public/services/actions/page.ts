@@ -0,0 +1,22 @@
+import { http, Result } from "@fider/services/http";
+import { Page, PageVisibility, PagePlacement } from "@fider/models";
+
+export interface SavePageRequest {
+  slug: string;
+  title: string;
+  content: string;
+  visibility: PageVisibility;
+  placement: PagePlacement;
+}
+
+export const createPage = async (request: SavePageRequest): Promise<Result<Page>> => {
+  return http.post<Page>("/api/admin/pages", request).then(http.event("page", "create"));
+};
+
+export const updatePage = async (currentSlug: string, request: SavePageRequest): Promise<Result<Page>> => {
+  return http.post<Page>(`/api/admin/pages/${currentSlug}`, request).then(http.event("page", "update"));
+};
+
+export const deletePage = async (slug: string): Promise<Result> => {
+  return http.delete(`/api/admin/pages/${slug}`).then(http.event("page", "delete"));
+};
@@ -1,5 +1,16 @@
 import { http, Result } from "@fider/services/http";
-import { Tenant, UserRole } from "@fider/models";
+import {
+  Tenant,
+  UserRole,
+  OAuthConfig,
+  OAuthConfigStatus,
+  TenantSAMLSettings,
+  TenantJWTSSOSettings,
+  TenantAutoJoinSettings,
+  CustomRole,
+  Permission,
+  TenantUsage
+} from "@fider/models";
 
 export interface CheckAvailabilityResponse {
   message: string;
@@ -32,6 +43,7 @@ export interface UpdateTenantSettingsRequest {
   title: string;
   invitation: string;
   welcomeMessage: string;
+  subdomain: string;
   cname: string;
 }
 
@@ -39,12 +51,60 @@ export const updateTenantSettings = async (request: UpdateTenantSettingsRequest)
   return await http.post("/api/admin/settings/general", request);
 };
 
+export interface ImageUploadRequest {
+  upload?: {
+    content?: string;
+    contentType?: string;
+  };
+  remove: boolean;
+}
+
+export interface UpdateTenantThemeRequest {
+  primaryColor: string;
+  accentColor: string;
+  customCSS: string;
+  favicon?: ImageUploadRequest;
+  header?: ImageUploadRequest;
+}
+
+export const updateTenantTheme = async (request: UpdateTenantThemeRequest): Promise<Result> => {
+  return await http.post("/api/admin/settings/theme", request);
+};
+
 export const updateTenantPrivacy = async (isPrivate: boolean): Promise<Result> => {
   return await http.post("/api/admin/settings/privacy", {
     isPrivate
   });
 };
 
+export const updateAutoJoinSettings = async (
+  settings: TenantAutoJoinSettings
+): Promise<Result<TenantAutoJoinSettings>> => {
+  return await http.post<TenantAutoJoinSettings>("/api/admin/settings/auto-join", settings);
+};
+
+export const updateTwoFactorSettings = async (isRequired: boolean): Promise<Result> => {
+  return await http.post("/api/admin/settings/two-factor", {
+    isRequired
+  });
+};
+
+export interface ScheduleTenantDeletionResponse {
+  deletionScheduledOn: string;
+}
+
+export const scheduleTenantDeletion = async (): Promise<Result<ScheduleTenantDeletionResponse>> => {
+  return await http.post<ScheduleTenantDeletionResponse>("/api/admin/settings/deletion");
+};
+
+export const cancelTenantDeletion = async (): Promise<Result> => {
+  return await http.delete("/api/admin/settings/deletion");
+};
+
+export const removeTenantAlias = async (id: number): Promise<Result> => {
+  return await http.delete(`/api/admin/settings/aliases/${id}`);
+};
+
 export const checkAvailability = async (subdomain: string): Promise<Result<CheckAvailabilityResponse>> => {
   return await http.get<CheckAvailabilityResponse>(`/api/tenants/${subdomain}/availability`);
 };
@@ -55,15 +115,90 @@ export const signIn = async (email: string): Promise<Result> => {
   });
 };
 
-export const completeProfile = async (key: string, name: string): Promise<Result> => {
-  return await http.post("/api/signin/complete", {
+export interface CompleteProfileResponse {
+  redirect?: string;
+}
+
+export const completeProfile = async (key: string, name: string): Promise<Result<CompleteProfileResponse>> => {
+  return await http.post<CompleteProfileResponse>("/api/signin/complete", {
     key,
     name
   });
 };
 
-export const changeUserRole = async (userId: number, role: UserRole): Promise<Result> => {
+export const changeUserRole = async (userId: number, role: UserRole, customRoleId?: number): Promise<Result> => {
   return await http.post(`/api/admin/users/${userId}/role`, {
-    role
+    role,
+    customRoleId
   });
 };
+
+export interface SaveCustomRoleRequest {
+  name: string;
+  permissions: Permission[];
+}
+
+export const saveCustomRole = async (id: number | undefined, data: SaveCustomRoleRequest): Promise<Result<CustomRole>> => {
+  return await http.post<CustomRole>(id ? `/api/admin/roles/${id}` : "/api/admin/roles", data);
+};
+
+export const deleteCustomRole = async (id: number): Promise<Result> => {
+  return await http.delete(`/api/admin/roles/${id}`);
+};
+
+export const revokeUserSessions = async (userId: number): Promise<Result> => {
+  return await http.delete(`/api/admin/users/${userId}/sessions`);
+};
+
+export interface SaveOAuthConfigRequest {
+  provider: string;
+  displayName: string;
+  status: OAuthConfigStatus;
+  clientId: string;
+  clientSecret: string;
+  discoveryUrl: string;
+  authorizeUrl: string;
+  tokenUrl: string;
+  profileUrl: string;
+  scope: string;
+  jsonUserIdPath: string;
+  jsonUserNamePath: string;
+  jsonUserEmailPath: string;
+  emailAlwaysVerified: boolean;
+}
+
+export const saveOAuthConfig = async (request: SaveOAuthConfigRequest): Promise<Result<OAuthConfig>> => {
+  return await http.post<OAuthConfig>("/api/admin/oauth", request);
+};
+
+export interface UpdateSSOSettingsRequest extends TenantSAMLSettings {
+  metadata: string;
+}
+
+export const updateSSOSettings = async (request: UpdateSSOSettingsRequest): Promise<Result<TenantSAMLSettings>> => {
+  return await http.post<TenantSAMLSettings>("/api/admin/settings/sso", request);
+};
+
+export interface UpdateJWTSSOSettingsRequest {
+  isEnabled: boolean;
+  secret: string;
+  publicKey: string;
+}
+
+export const updateJWTSSOSettings = async (
+  request: UpdateJWTSSOSettingsRequest
+): Promise<Result<TenantJWTSSOSettings>> => {
+  return await http.post<TenantJWTSSOSettings>("/api/admin/settings/sso/jwt", request);
+};
+
+export const getTenantUsage = async (): Promise<Result<TenantUsage>> => {
+  return await http.get<TenantUsage>("/api/admin/usage");
+};
+
+export interface UpdateEmbedSettingsResponse {
+  origins: string[];
+}
+
+export const updateEmbedSettings = async (origins: string[]): Promise<Result<UpdateEmbedSettingsResponse>> => {
+  return await http.post<UpdateEmbedSettingsResponse>("/api/admin/settings/embed", { origins });
+};
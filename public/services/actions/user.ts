@@ -1,9 +1,10 @@
 import { http, Result } from "@fider/services/http";
-import { UserSettings } from "@fider/models";
+import { UserSettings, TwoFactorEnrollment } from "@fider/models";
 
-export const updateUserSettings = async (name: string, settings: UserSettings): Promise<Result> => {
+export const updateUserSettings = async (name: string, locale: string, settings: UserSettings): Promise<Result> => {
   return await http.post("/api/user/settings", {
     name,
+    locale,
     settings
   });
 };
@@ -13,3 +14,47 @@ export const changeUserEmail = async (email: string): Promise<Result> => {
     email
   });
 };
+
+export const revokeSession = async (id: string): Promise<Result> => {
+  return await http.delete(`/api/user/sessions/${id}`);
+};
+
+export const signOutEverywhere = async (): Promise<Result> => {
+  return await http.post("/api/user/sessions/revoke-all");
+};
+
+export interface RecoveryCodesResponse {
+  recoveryCodes: string[];
+}
+
+export interface VerifyTwoFactorResponse extends RecoveryCodesResponse {
+  redirect: string;
+}
+
+export const verifyTwoFactor = async (code: string): Promise<Result<VerifyTwoFactorResponse>> => {
+  return await http.post<VerifyTwoFactorResponse>("/api/signin/2fa", { code });
+};
+
+export const setupTwoFactor = async (): Promise<Result<TwoFactorEnrollment>> => {
+  return await http.post<TwoFactorEnrollment>("/api/user/2fa/setup");
+};
+
+export const enableTwoFactor = async (code: string): Promise<Result<RecoveryCodesResponse>> => {
+  return await http.post<RecoveryCodesResponse>("/api/user/2fa/enable", { code });
+};
+
+export const disableTwoFactor = async (code: string): Promise<Result> => {
+  return await http.post("/api/user/2fa/disable", { code });
+};
+
+export const regenerateRecoveryCodes = async (code: string): Promise<Result<RecoveryCodesResponse>> => {
+  return await http.post<RecoveryCodesResponse>("/api/user/2fa/recovery-codes", { code });
+};
+
+export interface WidgetTokenResponse {
+  token: string;
+}
+
+export const getWidgetToken = async (): Promise<Result<WidgetTokenResponse>> => {
+  return await http.post<WidgetTokenResponse>("/widget/signin");
+};
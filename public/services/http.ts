@@ -31,8 +31,17 @@ async function toResult<T>(response: Response): Promise<Result<T>> {
     };
   }
 }
+let authToken: string | undefined;
+
 async function request<T>(url: string, method: "GET" | "POST" | "DELETE", body?: any): Promise<Result<T>> {
   const headers = [["Accept", "application/json"], ["Content-Type", "application/json"]];
+  if (authToken) {
+    headers.push(["Authorization", `Bearer ${authToken}`]);
+  }
+  const csrfToken = (window as any).props && (window as any).props.csrfToken;
+  if (csrfToken && method !== "GET") {
+    headers.push(["X-CSRF-Token", csrfToken]);
+  }
   const response = await fetch(url, {
     method,
     headers,
@@ -43,6 +52,9 @@ async function request<T>(url: string, method: "GET" | "POST" | "DELETE", body?:
 }
 
 export const http = {
+  setAuthToken: (token: string | undefined): void => {
+    authToken = token;
+  },
   get: async <T = void>(url: string): Promise<Result<T>> => {
     return await request<T>(url, "GET");
   },
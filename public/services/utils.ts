@@ -1,3 +1,5 @@
+import { CurrentUser, Permission } from "@fider/models";
+
 export const delay = (ms: number) => {
   return new Promise(resolve => setTimeout(resolve, ms));
 };
@@ -109,3 +111,16 @@ export const fileToBase64 = async (file: File): Promise<string> => {
     reader.readAsDataURL(file);
   });
 };
+
+export const fileToText = async (file: File): Promise<string> => {
+  return new Promise<string>((resolve, reject) => {
+    const reader = new FileReader();
+    reader.addEventListener("load", () => resolve(reader.result as string), false);
+    reader.addEventListener("error", () => reject(reader.error), false);
+    reader.readAsText(file);
+  });
+};
+
+export const can = (user: CurrentUser | undefined, permission: Permission): boolean => {
+  return !!user && user.permissions.indexOf(permission) >= 0;
+};
@@ -0,0 +1,7 @@
+subject: {{ .tenantName }} has been deleted
+body:
+Hi,
+<br /><br />
+As scheduled, <strong>{{ .tenantName }}</strong> and all of its ideas, comments, users and uploads have been permanently deleted.
+<br /><br />
+Thank you for using Fider.
@@ -0,0 +1,9 @@
+subject: {{ .tenantName }} is scheduled for deletion
+body:
+Hi,
+<br /><br />
+<strong>{{ .tenantName }}</strong> has been scheduled for deletion and all of its ideas, comments, users and uploads will be permanently deleted on <strong>{{ .deletionDate }}</strong>.
+<br /><br />
+Until then, an administrator can cancel the deletion on the settings page.
+<br /><br />
+{{ .link }}
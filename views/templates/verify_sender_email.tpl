@@ -0,0 +1,11 @@
+subject: Confirm {{ .address }} as the sender of {{ .tenantName }}
+body:
+Hi,
+<br /><br />
+Someone has requested to send emails of <strong>{{ .tenantName }}</strong> from {{ .address }}.
+<br />
+Click the link below to confirm that this address belongs to you.
+<br /><br />
+{{ .link }}
+<br /><br />
+<span style="color:#666;font-size:11px">This link will expire in 48 hours and can only be used once.</span>